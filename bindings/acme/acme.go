@@ -0,0 +1,309 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 5 * time.Minute
+	defaultDNSTTL  = 60
+
+	// Values of keyType.
+	keyTypeECDSA = "ecdsa"
+	keyTypeRSA   = "rsa"
+
+	// keys from request's metadata.
+	// The name of the secret of the certificate, the first domain with the "*." prefix replaced by "wildcard." by default.
+	secretNameKey = "secretName"
+	// Passed to the secret store, e.g. for the Kubernetes secret store.
+	namespaceKey = "namespace"
+
+	// keys of the certificate secrets.
+	secretCertificateKey = "tls.crt"
+	secretPrivateKeyKey  = "tls.key"
+
+	OrderOperation     bindings.OperationKind = "order"
+	ChallengeOperation bindings.OperationKind = "challenge"
+	FinalizeOperation  bindings.OperationKind = "finalize"
+	IssueOperation     bindings.OperationKind = "issue"
+)
+
+// ACME is an output binding issuing certificates from an ACME certificate authority such as Let's Encrypt.
+// It fulfills DNS-01 challenges with a DNS binding, and saves the certificates in a secret store which can set secrets.
+type ACME struct {
+	metadata acmeMetadata
+	client   *acme.Client
+	dns      bindings.OutputBinding
+	store    secretstores.SecretSetter
+	logger   logger.Logger
+
+	// registered is true once the account is registered, or known to exist.
+	registered bool
+	lock       sync.Mutex
+}
+
+type acmeMetadata struct {
+	// DirectoryURL is the URL of the directory of the CA, Let's Encrypt production by default.
+	DirectoryURL string `mapstructure:"directoryURL"`
+	// Email is the contact of the account.
+	Email string `mapstructure:"email"`
+	// AccountKey is the PEM private key of the account. A key is generated if empty, and the account only lasts as long as the component.
+	AccountKey string `mapstructure:"accountKey"`
+	// EABKeyID and EABHMACKey are the external account binding required by some CAs, the key encoded in base64url.
+	EABKeyID   string `mapstructure:"eabKeyID"`
+	EABHMACKey string `mapstructure:"eabHMACKey"`
+	// KeyType is the type of the private keys of the certificates, "ecdsa" (P-256) or "rsa" (2048 bits).
+	KeyType string `mapstructure:"keyType"`
+	// DNSZone is the zone metadata of the requests to the DNS binding, if not its default zone.
+	DNSZone string `mapstructure:"dnsZone"`
+	// DNSTTL is the TTL of the challenge records.
+	DNSTTL int64 `mapstructure:"dnsTTL"`
+	// PropagationDelay is the time waited after publishing the challenge records, before asking the CA to check them.
+	PropagationDelay time.Duration `mapstructure:"propagationDelay"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+}
+
+// Request is the data of the requests. Order and issue requests have the domains, challenge and finalize requests the URL of the order.
+type Request struct {
+	Domains  []string `json:"domains,omitempty"`
+	OrderURL string   `json:"orderURL,omitempty"`
+}
+
+// Order is the response of order and challenge requests.
+type Order struct {
+	URL     string    `json:"url"`
+	Status  string    `json:"status"`
+	Domains []string  `json:"domains"`
+	Expires time.Time `json:"expires"`
+	// Challenges are the DNS-01 challenges of the authorizations still pending.
+	Challenges []Challenge `json:"challenges,omitempty"`
+}
+
+// Challenge is a DNS-01 challenge: the TXT record to publish to prove the control of the domain.
+type Challenge struct {
+	Domain      string `json:"domain"`
+	URL         string `json:"url"`
+	RecordName  string `json:"recordName"`
+	RecordValue string `json:"recordValue"`
+}
+
+// Certificate is the response of finalize and issue requests.
+type Certificate struct {
+	Domains []string `json:"domains"`
+	// Certificate is the PEM certificate followed by its chain.
+	Certificate string    `json:"certificate"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	// SecretName is the secret of the certificate and its private key, when saved in the secret store.
+	SecretName string `json:"secretName,omitempty"`
+	// PrivateKey is the PEM private key, returned only without a secret store.
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+// NewACME returns a new ACME binding. dns is the DNS binding publishing the challenge records, and store the secret store saving the certificates;
+// both are optional. Without a DNS binding, the records returned by the order operation must be published before the challenge operation.
+// Without a secret store, the private keys are returned with the certificates.
+func NewACME(logger logger.Logger, dns bindings.OutputBinding, store secretstores.SecretStore) bindings.OutputBinding {
+	a := &ACME{logger: logger, dns: dns}
+	if store != nil {
+		if setter, ok := store.(secretstores.SecretSetter); ok {
+			a.store = setter
+		} else {
+			logger.Warn("ACME binding: the secret store can't set secrets, the certificates won't be saved")
+		}
+	}
+	return a
+}
+
+// Init parses the metadata and creates the ACME client.
+func (a *ACME) Init(meta bindings.Metadata) error {
+	a.metadata = acmeMetadata{
+		DirectoryURL: acme.LetsEncryptURL,
+		KeyType:      keyTypeECDSA,
+		DNSTTL:       defaultDNSTTL,
+		Timeout:      defaultTimeout,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &a.metadata)
+	if err != nil {
+		return fmt.Errorf("acme binding error: %w", err)
+	}
+	if a.metadata.KeyType != keyTypeECDSA && a.metadata.KeyType != keyTypeRSA {
+		return fmt.Errorf("acme binding error: invalid keyType %q: must be %s or %s", a.metadata.KeyType, keyTypeECDSA, keyTypeRSA)
+	}
+	if (a.metadata.EABKeyID == "") != (a.metadata.EABHMACKey == "") {
+		return errors.New("acme binding error: eabKeyID and eabHMACKey must be set together")
+	}
+
+	var key crypto.Signer
+	if a.metadata.AccountKey != "" {
+		key, err = parsePrivateKey(a.metadata.AccountKey)
+		if err != nil {
+			return fmt.Errorf("acme binding error: invalid accountKey: %w", err)
+		}
+	} else {
+		a.logger.Warn("ACME binding: no accountKey, a new account is registered")
+		key, err = newPrivateKey(keyTypeECDSA)
+		if err != nil {
+			return fmt.Errorf("acme binding error: %w", err)
+		}
+	}
+
+	a.client = &acme.Client{
+		Key:          key,
+		DirectoryURL: a.metadata.DirectoryURL,
+		UserAgent:    "dapr",
+	}
+	a.registered = false
+	return nil
+}
+
+// Operations returns the operations supported by the ACME binding.
+func (a *ACME) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{OrderOperation, ChallengeOperation, FinalizeOperation, IssueOperation}
+}
+
+// Invoke runs the operation of the request.
+func (a *ACME) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var r Request
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &r); err != nil {
+			return nil, fmt.Errorf("acme binding error: invalid request: %w", err)
+		}
+	}
+	switch req.Operation { //nolint:exhaustive
+	case OrderOperation, IssueOperation:
+		if len(r.Domains) == 0 {
+			return nil, errors.New("acme binding error: the domains are required")
+		}
+	case ChallengeOperation, FinalizeOperation:
+		if r.OrderURL == "" {
+			return nil, errors.New("acme binding error: the orderURL is required")
+		}
+	default:
+		return nil, fmt.Errorf("acme binding error: unsupported operation %s", req.Operation)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.metadata.Timeout)
+	defer cancel()
+
+	err := a.register(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acme binding error: account registration failed: %w", err)
+	}
+
+	var res interface{}
+	switch req.Operation { //nolint:exhaustive
+	case OrderOperation:
+		res, err = a.order(ctx, r.Domains)
+	case ChallengeOperation:
+		res, err = a.challenge(ctx, r.OrderURL)
+	case FinalizeOperation:
+		res, err = a.finalize(ctx, req, r.OrderURL)
+	case IssueOperation:
+		var order *Order
+		order, err = a.order(ctx, r.Domains)
+		if err == nil {
+			_, err = a.challenge(ctx, order.URL)
+		}
+		if err == nil {
+			res, err = a.finalize(ctx, req, order.URL)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("acme binding error: %s failed: %w", req.Operation, err)
+	}
+
+	resp := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}
+	resp.Data, err = json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("acme binding error: %w", err)
+	}
+	return resp, nil
+}
+
+// register registers the account with the CA the first time, accepting its terms of service.
+func (a *ACME) register(ctx context.Context) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.registered {
+		return nil
+	}
+	account := &acme.Account{}
+	if a.metadata.Email != "" {
+		account.Contact = []string{"mailto:" + a.metadata.Email}
+	}
+	if a.metadata.EABKeyID != "" {
+		hmacKey, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(a.metadata.EABHMACKey, "="))
+		if err != nil {
+			return fmt.Errorf("invalid eabHMACKey: %w", err)
+		}
+		account.ExternalAccountBinding = &acme.ExternalAccountBinding{KID: a.metadata.EABKeyID, Key: hmacKey}
+	}
+	_, err := a.client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return err
+	}
+	a.registered = true
+	return nil
+}
+
+// OperationsMetadata describes the operations of the ACME binding.
+func (a *ACME) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        OrderOperation,
+			Description:      "Creates an order for the domains, and returns it with the DNS-01 challenge records of its pending authorizations.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        ChallengeOperation,
+			Description:      "Publishes the challenge records of the order with the DNS binding, asks the CA to check them and waits for the authorizations.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        FinalizeOperation,
+			Description:      "Waits for the order to be ready, generates the private key, finalizes the order and saves the certificate in the secret store.",
+			RequestMetadata:  []string{secretNameKey, namespaceKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        IssueOperation,
+			Description:      "Runs the order, challenge and finalize operations for the domains.",
+			RequestMetadata:  []string{secretNameKey, namespaceKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/bindings/dns"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/kit/logger"
+)
+
+// fakeCA is a minimal ACME server with a single order. It does not verify the signatures of the requests.
+type fakeCA struct {
+	srv *httptest.Server
+	ca  *x509.Certificate
+	key *ecdsa.PrivateKey
+
+	lock     sync.Mutex
+	domains  []string
+	accepted map[string]bool
+	cert     []byte
+	accounts int
+}
+
+func newFakeCA(t *testing.T) *fakeCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	f := &fakeCA{ca: ca, key: key, accepted: map[string]bool{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeCA) handle(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+	base := f.srv.URL
+	var payload []byte
+	if r.Method == http.MethodPost {
+		var jws struct {
+			Payload string `json:"payload"`
+		}
+		json.NewDecoder(r.Body).Decode(&jws)
+		payload, _ = base64.RawURLEncoding.DecodeString(jws.Payload)
+	}
+
+	switch {
+	case r.URL.Path == "/directory":
+		json.NewEncoder(w).Encode(map[string]string{
+			"newNonce":   base + "/nonce",
+			"newAccount": base + "/account",
+			"newOrder":   base + "/order",
+		})
+	case r.URL.Path == "/nonce":
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Path == "/account":
+		f.accounts++
+		w.Header().Set("Location", base+"/account/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"valid"}`))
+	case r.URL.Path == "/order" && len(payload) > 0:
+		var req struct {
+			Identifiers []struct{ Value string } `json:"identifiers"`
+		}
+		json.Unmarshal(payload, &req)
+		f.domains = nil
+		for _, id := range req.Identifiers {
+			f.domains = append(f.domains, id.Value)
+		}
+		w.Header().Set("Location", base+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+		f.writeOrder(w)
+	case r.URL.Path == "/order/1":
+		f.writeOrder(w)
+	case strings.HasPrefix(r.URL.Path, "/authz/"):
+		domain := strings.TrimPrefix(r.URL.Path, "/authz/")
+		status := "pending"
+		if f.accepted[domain] {
+			status = "valid"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     status,
+			"identifier": map[string]string{"type": "dns", "value": strings.TrimPrefix(domain, "*.")},
+			"wildcard":   strings.HasPrefix(domain, "*."),
+			"challenges": []map[string]string{
+				{"type": "http-01", "url": base + "/http/" + domain, "token": "http-token", "status": "pending"},
+				{"type": "dns-01", "url": base + "/challenge/" + domain, "token": "token-" + domain, "status": "pending"},
+			},
+		})
+	case strings.HasPrefix(r.URL.Path, "/challenge/"):
+		domain := strings.TrimPrefix(r.URL.Path, "/challenge/")
+		f.accepted[domain] = true
+		json.NewEncoder(w).Encode(map[string]string{"type": "dns-01", "url": base + r.URL.Path, "token": "token-" + domain, "status": "processing"})
+	case r.URL.Path == "/finalize":
+		var req struct {
+			CSR string `json:"csr"`
+		}
+		json.Unmarshal(payload, &req)
+		der, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+		csr, err := x509.ParseCertificateRequest(der)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		}
+		f.cert, _ = x509.CreateCertificate(rand.Reader, tmpl, f.ca, csr.PublicKey, f.key)
+		f.writeOrder(w)
+	case r.URL.Path == "/cert":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: f.cert})
+		pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: f.ca.Raw})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeCA) writeOrder(w http.ResponseWriter) {
+	base := f.srv.URL
+	status := "ready"
+	ids := make([]map[string]string, len(f.domains))
+	authzs := make([]string, len(f.domains))
+	for i, d := range f.domains {
+		ids[i] = map[string]string{"type": "dns", "value": d}
+		authzs[i] = base + "/authz/" + d
+		if !f.accepted[d] {
+			status = "pending"
+		}
+	}
+	order := map[string]interface{}{
+		"status":         status,
+		"expires":        time.Now().Add(time.Hour).Format(time.RFC3339),
+		"identifiers":    ids,
+		"authorizations": authzs,
+		"finalize":       base + "/finalize",
+	}
+	if f.cert != nil {
+		order["status"] = "valid"
+		order["certificate"] = base + "/cert"
+	}
+	json.NewEncoder(w).Encode(order)
+}
+
+type fakeDNS struct {
+	bindings.OutputBinding
+
+	lock     sync.Mutex
+	requests []*bindings.InvokeRequest
+}
+
+func (f *fakeDNS) Invoke(_ context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.requests = append(f.requests, req)
+	return &bindings.InvokeResponse{}, nil
+}
+
+type fakeStore struct {
+	secretstores.SecretStore
+
+	secrets map[string]secretstores.SetSecretRequest
+}
+
+func (f *fakeStore) SetSecret(_ context.Context, req secretstores.SetSecretRequest) error {
+	f.secrets[req.Name] = req
+	return nil
+}
+
+func TestInit(t *testing.T) {
+	a := NewACME(logger.NewLogger("test"), nil, nil).(*ACME)
+
+	err := a.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"keyType": "dsa"}}})
+	assert.ErrorContains(t, err, "invalid keyType")
+
+	err = a.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"eabKeyID": "kid"}}})
+	assert.ErrorContains(t, err, "eabKeyID and eabHMACKey must be set together")
+
+	err = a.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"accountKey": "key"}}})
+	assert.ErrorContains(t, err, "invalid accountKey")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	err = a.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"accountKey": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+	}}})
+	require.NoError(t, err)
+	assert.True(t, key.Equal(a.client.Key))
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/directory", a.client.DirectoryURL)
+}
+
+func TestSecretName(t *testing.T) {
+	assert.Equal(t, "wildcard.example.com", secretName("*.example.com"))
+	assert.Equal(t, "www.example.com", secretName("www.example.com"))
+}
+
+func TestIssue(t *testing.T) {
+	ca := newFakeCA(t)
+	dnsBinding := &fakeDNS{}
+	store := &fakeStore{secrets: map[string]secretstores.SetSecretRequest{}}
+	a := NewACME(logger.NewLogger("test"), dnsBinding, store).(*ACME)
+	err := a.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"directoryURL": ca.srv.URL + "/directory",
+		"email":        "admin@example.com",
+		"dnsZone":      "Z1",
+	}}})
+	require.NoError(t, err)
+
+	resp, err := a.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: IssueOperation,
+		Data:      []byte(`{"domains":["example.com","*.example.com"]}`),
+		Metadata:  map[string]string{namespaceKey: "ns1"},
+	})
+	require.NoError(t, err)
+
+	var cert Certificate
+	require.NoError(t, json.Unmarshal(resp.Data, &cert))
+	assert.Equal(t, []string{"example.com", "*.example.com"}, cert.Domains)
+	assert.Equal(t, "example.com", cert.SecretName)
+	assert.Empty(t, cert.PrivateKey)
+	assert.Equal(t, 2, strings.Count(cert.Certificate, "BEGIN CERTIFICATE"))
+	assert.True(t, cert.NotAfter.After(time.Now().Add(80*24*time.Hour)))
+	assert.Equal(t, 1, ca.accounts)
+
+	// Both challenges have the same record, published at once and deleted afterwards.
+	require.Len(t, dnsBinding.requests, 2)
+	assert.Equal(t, dns.UpsertOperation, dnsBinding.requests[0].Operation)
+	assert.Equal(t, "Z1", dnsBinding.requests[0].Metadata["zone"])
+	var record dns.Record
+	require.NoError(t, json.Unmarshal(dnsBinding.requests[0].Data, &record))
+	assert.Equal(t, "_acme-challenge.example.com", record.Name)
+	assert.Equal(t, "TXT", record.Type)
+	assert.Len(t, record.Values, 2)
+	v, err := a.client.DNS01ChallengeRecord("token-example.com")
+	require.NoError(t, err)
+	assert.Contains(t, record.Values, v)
+	assert.Equal(t, dns.DeleteOperation, dnsBinding.requests[1].Operation)
+
+	secret := store.secrets["example.com"]
+	assert.Equal(t, cert.Certificate, secret.Data[secretCertificateKey])
+	assert.Contains(t, secret.Data[secretPrivateKeyKey], "BEGIN PRIVATE KEY")
+	assert.Equal(t, map[string]string{"type": "kubernetes.io/tls", namespaceKey: "ns1"}, secret.Metadata)
+
+	block, _ := pem.Decode([]byte(secret.Data[secretPrivateKeyKey]))
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	_, ok := key.(*ecdsa.PrivateKey)
+	assert.True(t, ok)
+}
+
+func TestOrderWithoutDNS(t *testing.T) {
+	ca := newFakeCA(t)
+	a := NewACME(logger.NewLogger("test"), nil, nil).(*ACME)
+	err := a.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"directoryURL": ca.srv.URL + "/directory",
+		"keyType":      "rsa",
+	}}})
+	require.NoError(t, err)
+
+	resp, err := a.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: OrderOperation,
+		Data:      []byte(`{"domains":["www.example.com"]}`),
+	})
+	require.NoError(t, err)
+	var order Order
+	require.NoError(t, json.Unmarshal(resp.Data, &order))
+	assert.Equal(t, ca.srv.URL+"/order/1", order.URL)
+	assert.Equal(t, "pending", order.Status)
+	require.Len(t, order.Challenges, 1)
+	assert.Equal(t, "_acme-challenge.www.example.com", order.Challenges[0].RecordName)
+	assert.Equal(t, ca.srv.URL+"/challenge/www.example.com", order.Challenges[0].URL)
+
+	resp, err = a.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: ChallengeOperation,
+		Data:      []byte(`{"orderURL":"` + order.URL + `"}`),
+	})
+	require.NoError(t, err)
+	var challenged Order
+	require.NoError(t, json.Unmarshal(resp.Data, &challenged))
+	assert.Equal(t, "ready", challenged.Status)
+	assert.Equal(t, order.URL, challenged.URL)
+	assert.Empty(t, challenged.Challenges)
+
+	resp, err = a.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: FinalizeOperation,
+		Data:      []byte(`{"orderURL":"` + order.URL + `"}`),
+	})
+	require.NoError(t, err)
+	var cert Certificate
+	require.NoError(t, json.Unmarshal(resp.Data, &cert))
+	assert.Empty(t, cert.SecretName)
+	assert.Contains(t, cert.PrivateKey, "BEGIN PRIVATE KEY")
+
+	_, err = a.Invoke(context.Background(), &bindings.InvokeRequest{Operation: FinalizeOperation})
+	assert.ErrorContains(t, err, "the orderURL is required")
+}
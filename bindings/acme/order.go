@@ -0,0 +1,307 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/bindings/dns"
+	"github.com/dapr/components-contrib/secretstores"
+)
+
+const challengeRecordPrefix = "_acme-challenge."
+
+// order creates an order for the domains.
+func (a *ACME) order(ctx context.Context, domains []string) (*Order, error) {
+	o, err := a.client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return nil, err
+	}
+	return a.describe(ctx, o)
+}
+
+// describe returns the order with the DNS-01 challenges of its pending authorizations.
+func (a *ACME) describe(ctx context.Context, o *acme.Order) (*Order, error) {
+	res := &Order{URL: o.URI, Status: o.Status, Domains: orderDomains(o), Expires: o.Expires}
+	challenges, err := a.pendingChallenges(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range challenges {
+		res.Challenges = append(res.Challenges, c.Challenge)
+	}
+	return res, nil
+}
+
+type pendingChallenge struct {
+	Challenge
+
+	authzURL  string
+	challenge *acme.Challenge
+}
+
+func (a *ACME) pendingChallenges(ctx context.Context, o *acme.Order) ([]pendingChallenge, error) {
+	var res []pendingChallenge
+	for _, u := range o.AuthzURLs {
+		authz, err := a.client.GetAuthorization(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if authz.Status != acme.StatusPending {
+			continue
+		}
+
+		domain := authz.Identifier.Value
+		if authz.Wildcard {
+			domain = "*." + domain
+		}
+		var chal *acme.Challenge
+		for _, c := range authz.Challenges {
+			if c.Type == "dns-01" {
+				chal = c
+				break
+			}
+		}
+		if chal == nil {
+			return nil, fmt.Errorf("the CA offers no dns-01 challenge for %s", domain)
+		}
+		value, err := a.client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pendingChallenge{
+			Challenge: Challenge{
+				Domain:      domain,
+				URL:         chal.URI,
+				RecordName:  challengeRecordPrefix + authz.Identifier.Value,
+				RecordValue: value,
+			},
+			authzURL:  authz.URI,
+			challenge: chal,
+		})
+	}
+	return res, nil
+}
+
+// challenge publishes the records of the pending challenges of the order with the DNS binding,
+// accepts the challenges and waits for the authorizations. The records are deleted afterwards.
+func (a *ACME) challenge(ctx context.Context, orderURL string) (*Order, error) {
+	o, err := a.client.GetOrder(ctx, orderURL)
+	if err != nil {
+		return nil, err
+	}
+	challenges, err := a.pendingChallenges(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.dns != nil && len(challenges) > 0 {
+		// A domain and its wildcard have challenges with the same record name, so the records are grouped by name.
+		var names []string
+		values := make(map[string][]string)
+		for _, c := range challenges {
+			if _, ok := values[c.RecordName]; !ok {
+				names = append(names, c.RecordName)
+			}
+			values[c.RecordName] = append(values[c.RecordName], c.RecordValue)
+		}
+		defer func() {
+			for _, name := range names {
+				// The context may be done, yet the records should still be cleaned up.
+				cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				err := a.invokeDNS(cleanupCtx, dns.DeleteOperation, dns.Record{Name: name, Type: "TXT"})
+				cancel()
+				if err != nil {
+					a.logger.Warnf("ACME binding: failed to delete the challenge record %s: %v", name, err)
+				}
+			}
+		}()
+		for _, name := range names {
+			err = a.invokeDNS(ctx, dns.UpsertOperation, dns.Record{Name: name, Type: "TXT", TTL: a.metadata.DNSTTL, Values: values[name]})
+			if err != nil {
+				return nil, fmt.Errorf("failed to publish the challenge record %s: %w", name, err)
+			}
+		}
+		if a.metadata.PropagationDelay > 0 {
+			select {
+			case <-time.After(a.metadata.PropagationDelay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	for _, c := range challenges {
+		if _, err = a.client.Accept(ctx, c.challenge); err != nil {
+			return nil, fmt.Errorf("failed to accept the challenge of %s: %w", c.Domain, err)
+		}
+	}
+	for _, c := range challenges {
+		if _, err = a.client.WaitAuthorization(ctx, c.authzURL); err != nil {
+			return nil, fmt.Errorf("authorization of %s failed: %w", c.Domain, err)
+		}
+	}
+
+	o, err = a.client.GetOrder(ctx, orderURL)
+	if err != nil {
+		return nil, err
+	}
+	// CAs may not return the URL when getting orders.
+	return &Order{URL: orderURL, Status: o.Status, Domains: orderDomains(o), Expires: o.Expires}, nil
+}
+
+func (a *ACME) invokeDNS(ctx context.Context, operation bindings.OperationKind, record dns.Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req := &bindings.InvokeRequest{Operation: operation, Data: data, Metadata: map[string]string{}}
+	if a.metadata.DNSZone != "" {
+		req.Metadata["zone"] = a.metadata.DNSZone
+	}
+	_, err = a.dns.Invoke(ctx, req)
+	return err
+}
+
+// finalize waits for the order to be ready, and finalizes it with a new private key.
+func (a *ACME) finalize(ctx context.Context, req *bindings.InvokeRequest, orderURL string) (*Certificate, error) {
+	o, err := a.client.WaitOrder(ctx, orderURL)
+	if err != nil {
+		return nil, err
+	}
+	domains := orderDomains(o)
+
+	key, err := newPrivateKey(a.metadata.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	ders, _, err := a.client.CreateOrderCert(ctx, o.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(ders[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	var chain strings.Builder
+	for _, der := range ders {
+		pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+
+	res := &Certificate{
+		Domains:     domains,
+		Certificate: chain.String(),
+		NotBefore:   leaf.NotBefore,
+		NotAfter:    leaf.NotAfter,
+	}
+	if a.store == nil {
+		res.PrivateKey = keyPEM
+		return res, nil
+	}
+
+	res.SecretName = req.Metadata[secretNameKey]
+	if res.SecretName == "" {
+		res.SecretName = secretName(domains[0])
+	}
+	setReq := secretstores.SetSecretRequest{
+		Name: res.SecretName,
+		Data: map[string]string{
+			secretCertificateKey: res.Certificate,
+			secretPrivateKeyKey:  keyPEM,
+		},
+		Metadata: map[string]string{"type": "kubernetes.io/tls"},
+	}
+	if ns := req.Metadata[namespaceKey]; ns != "" {
+		setReq.Metadata[namespaceKey] = ns
+	}
+	if err = a.store.SetSecret(ctx, setReq); err != nil {
+		return nil, fmt.Errorf("failed to save the certificate in secret %s: %w", res.SecretName, err)
+	}
+	return res, nil
+}
+
+func orderDomains(o *acme.Order) []string {
+	domains := make([]string, len(o.Identifiers))
+	for i, id := range o.Identifiers {
+		domains[i] = id.Value
+	}
+	return domains
+}
+
+// secretName returns the default name of the secret of a certificate.
+func secretName(domain string) string {
+	if strings.HasPrefix(domain, "*.") {
+		return "wildcard." + domain[2:]
+	}
+	return domain
+}
+
+func newPrivateKey(keyType string) (crypto.Signer, error) {
+	if keyType == keyTypeRSA {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// parsePrivateKey parses a PEM PKCS#8, PKCS#1 or SEC 1 private key.
+func parsePrivateKey(s string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dapr/components-contrib/internal/utils"
+)
+
+// Keys of the request metadata building the message. Without msgType, the request data is sent as is.
+const (
+	msgTypeKey   = "msgType"
+	titleKey     = "title"
+	urlKey       = "url"
+	atMobilesKey = "atMobiles"
+	atAllKey     = "atAll"
+)
+
+// Types of the messages built from the request data.
+const (
+	MsgTypeText     = "text"
+	MsgTypeMarkdown = "markdown"
+	MsgTypeCard     = "card"
+)
+
+type at struct {
+	AtMobiles []string `json:"atMobiles,omitempty"`
+	IsAtAll   bool     `json:"isAtAll,omitempty"`
+}
+
+// buildMessage returns the body posted to the robot: the request data is the content of a message of the type set in the "msgType" metadata.
+func buildMessage(data []byte, md map[string]string) ([]byte, error) {
+	msgType := md[msgTypeKey]
+	if msgType == "" {
+		return data, nil
+	}
+
+	mentions := &at{
+		IsAtAll: utils.IsTruthy(md[atAllKey]),
+	}
+	for _, m := range strings.Split(md[atMobilesKey], ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			mentions.AtMobiles = append(mentions.AtMobiles, m)
+		}
+	}
+	if len(mentions.AtMobiles) == 0 && !mentions.IsAtAll {
+		mentions = nil
+	}
+
+	title := md[titleKey]
+	var msg map[string]interface{}
+	switch msgType {
+	case MsgTypeText:
+		msg = map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": string(data)},
+		}
+	case MsgTypeMarkdown:
+		if title == "" {
+			return nil, fmt.Errorf("required metadata %q not set", titleKey)
+		}
+		msg = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"title": title, "text": string(data)},
+		}
+	case MsgTypeCard:
+		if title == "" || md[urlKey] == "" {
+			return nil, fmt.Errorf("required metadata %q and %q not set", titleKey, urlKey)
+		}
+		card := map[string]string{
+			"title":       title,
+			"text":        string(data),
+			"singleTitle": title,
+			"singleURL":   md[urlKey],
+		}
+		msg = map[string]interface{}{
+			"msgtype":    "actionCard",
+			"actionCard": card,
+		}
+	default:
+		return nil, fmt.Errorf("msgType %q is not one of: text, markdown, card", msgType)
+	}
+	if mentions != nil {
+		msg["at"] = mentions
+	}
+
+	return json.Marshal(msg)
+}
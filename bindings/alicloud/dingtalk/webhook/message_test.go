@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) { //nolint:paralleltest
+	raw := []byte(`{"msgtype": "text", "text": {"content": "hello"}}`)
+	msg, err := buildMessage(raw, map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, raw, msg)
+
+	msg, err = buildMessage([]byte("hello"), map[string]string{msgTypeKey: MsgTypeText, atMobilesKey: "138, 139"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"msgtype": "text", "text": {"content": "hello"}, "at": {"atMobiles": ["138", "139"]}}`, string(msg))
+
+	msg, err = buildMessage([]byte("# Alert"), map[string]string{msgTypeKey: MsgTypeMarkdown, titleKey: "Alert", atAllKey: "true"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"msgtype": "markdown", "markdown": {"title": "Alert", "text": "# Alert"}, "at": {"isAtAll": true}}`, string(msg))
+
+	msg, err = buildMessage([]byte("Build failed"), map[string]string{msgTypeKey: MsgTypeCard, titleKey: "CI", urlKey: "https://ci.example.com/1"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"msgtype": "actionCard", "actionCard": {"title": "CI", "text": "Build failed", "singleTitle": "CI", "singleURL": "https://ci.example.com/1"}}`, string(msg))
+
+	_, err = buildMessage([]byte("hello"), map[string]string{msgTypeKey: MsgTypeMarkdown})
+	assert.Error(t, err)
+	_, err = buildMessage([]byte("hello"), map[string]string{msgTypeKey: "image"})
+	assert.Error(t, err)
+}
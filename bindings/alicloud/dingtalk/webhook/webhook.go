@@ -152,7 +152,10 @@ func (t *DingTalkWebhook) receivedMessage(ctx context.Context, req *bindings.Inv
 }
 
 func (t *DingTalkWebhook) sendMessage(ctx context.Context, req *bindings.InvokeRequest) error {
-	msg := req.Data
+	msg, err := buildMessage(req.Data, req.Metadata)
+	if err != nil {
+		return fmt.Errorf("dingtalk webhook error: %w", err)
+	}
 
 	postURL, err := getPostURL(t.settings.URL, t.settings.Secret)
 	if err != nil {
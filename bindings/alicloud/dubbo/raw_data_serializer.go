@@ -54,7 +54,7 @@ func marshalRequest(encoder *hessian.Encoder, p dubboImpl.DubboPackage) ([]byte,
 	_ = encoder.Encode(service.Version)
 	args, ok := request.Params.([]interface{})
 	if !ok {
-		logger.Infof("request args are: %+v", request.Params)
+		logger.Infof("request args are of type %T", request.Params)
 		return nil, perrors.Errorf("@params is not of type: []interface{}")
 	}
 	_ = encoder.Encode(args[0].(string))
@@ -0,0 +1,282 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	as2component "github.com/dapr/components-contrib/internal/component/as2"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	subjectKey     = "subject"
+	fileNameKey    = "fileName"
+	contentTypeKey = "contentType"
+	messageIDKey   = "messageId"
+)
+
+// maxBodySize limits the size of inbound AS2 requests.
+const maxBodySize = 64 << 20
+
+// AS2 is an input and output binding that exchanges signed and encrypted messages with an AS2 trading partner.
+type AS2 struct {
+	metadata   as2Metadata
+	config     *as2component.Config
+	httpClient *http.Client
+	logger     logger.Logger
+}
+
+// NewAS2 returns a new AS2 binding instance.
+func NewAS2(logger logger.Logger) bindings.InputOutputBinding {
+	return &AS2{logger: logger}
+}
+
+// Init performs metadata parsing.
+func (a *AS2) Init(metadata bindings.Metadata) error {
+	m, config, err := parseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("as2 binding error: %w", err)
+	}
+	a.metadata = m
+	a.config = config
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	a.httpClient = &http.Client{
+		Timeout: m.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+
+	return nil
+}
+
+// Operations returns the supported operations.
+func (a *AS2) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation}
+}
+
+// Invoke sends the request data to the partner and validates the synchronous MDN, if one was requested.
+func (a *AS2) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != bindings.CreateOperation {
+		return nil, fmt.Errorf("as2 binding error: unsupported operation %s", req.Operation)
+	}
+	if a.metadata.URL == "" {
+		return nil, errors.New("as2 binding error: as2Url is required to send messages")
+	}
+
+	msg := &as2component.Message{
+		ID:          req.Metadata[messageIDKey],
+		Subject:     a.metadata.Subject,
+		ContentType: a.metadata.ContentType,
+		FileName:    req.Metadata[fileNameKey],
+		Data:        req.Data,
+	}
+	if val := req.Metadata[subjectKey]; val != "" {
+		msg.Subject = val
+	}
+	if val := req.Metadata[contentTypeKey]; val != "" {
+		msg.ContentType = val
+	}
+
+	result, err := a.config.Send(ctx, a.httpClient, a.metadata.URL, msg)
+	if err != nil {
+		return nil, fmt.Errorf("as2 binding error: %w", err)
+	}
+
+	resp := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			messageIDKey: result.MessageID,
+			"mic":        result.MIC,
+		},
+	}
+	if result.MDN != nil {
+		resp.Metadata["mdnMessageId"] = result.MDN.MessageID
+		resp.Metadata["mdnDisposition"] = result.MDN.Disposition
+		resp.Metadata["mdnSigned"] = strconv.FormatBool(result.MDN.Signed)
+	}
+
+	return resp, nil
+}
+
+// Read starts the HTTP endpoint that receives messages and asynchronous MDNs from the partner.
+func (a *AS2) Read(ctx context.Context, handler bindings.Handler) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(a.metadata.Path, a.serve(ctx, handler))
+
+	srv := &http.Server{
+		Addr:              ":" + a.metadata.Port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Run the server in background
+	go func() {
+		a.logger.Debugf("About to start listening for AS2 messages at http://localhost:%s%s", a.metadata.Port, a.metadata.Path)
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.logger.Errorf("Error starting server: %v", err)
+		}
+	}()
+
+	// Close the server when context is canceled
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := srv.Shutdown(shutdownCtx)
+		if err != nil {
+			a.logger.Errorf("Error shutting down server: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (a *AS2) serve(ctx context.Context, handler bindings.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		in, err := a.config.Unpack(r.Header, body)
+		var procErr *as2component.ProcessingError
+		if err != nil && !errors.As(err, &procErr) {
+			a.logger.Warnf("as2 binding: rejected request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err == nil && in.MDN != nil {
+			a.handleMDN(r.Context(), handler, in.MDN)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if err == nil {
+			_, err = handler(r.Context(), a.readResponse(in))
+			if err != nil {
+				err = &as2component.ProcessingError{Modifier: as2component.ErrorUnexpectedProcessing, Err: err}
+			}
+		}
+		if err != nil {
+			a.logger.Errorf("as2 binding: failed to process message %s: %v", in.MessageID, err)
+		}
+
+		if !in.MDNRequested {
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			} else {
+				w.WriteHeader(http.StatusOK)
+			}
+			return
+		}
+
+		mdn, mdnErr := a.config.NewMDN(in, err)
+		if mdnErr != nil {
+			a.logger.Errorf("as2 binding: failed to create MDN for message %s: %v", in.MessageID, mdnErr)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if in.AsyncMDNURL != "" {
+			w.WriteHeader(http.StatusOK)
+			go a.sendAsyncMDN(ctx, in.AsyncMDNURL, mdn)
+			return
+		}
+
+		for k, v := range mdn.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(mdn.Body)
+	}
+}
+
+func (a *AS2) readResponse(in *as2component.Inbound) *bindings.ReadResponse {
+	contentType := in.ContentType
+
+	return &bindings.ReadResponse{
+		Data:        in.Data,
+		ContentType: &contentType,
+		Metadata: map[string]string{
+			"as2Type":    "message",
+			messageIDKey: in.MessageID,
+			"as2From":    in.From,
+			"as2To":      in.To,
+			subjectKey:   in.Subject,
+			fileNameKey:  in.FileName,
+			"signed":     strconv.FormatBool(in.Signed),
+			"encrypted":  strconv.FormatBool(in.Encrypted),
+		},
+	}
+}
+
+func (a *AS2) handleMDN(ctx context.Context, handler bindings.Handler, mdn *as2component.MDN) {
+	_, err := handler(ctx, &bindings.ReadResponse{
+		Data: []byte(mdn.Text),
+		Metadata: map[string]string{
+			"as2Type":           "mdn",
+			messageIDKey:        mdn.MessageID,
+			"originalMessageId": mdn.OriginalMessageID,
+			"mdnDisposition":    mdn.Disposition,
+			"mic":               mdn.MIC,
+			"mdnSigned":         strconv.FormatBool(mdn.Signed),
+		},
+	})
+	if err != nil {
+		a.logger.Errorf("as2 binding: failed to process MDN for message %s: %v", mdn.OriginalMessageID, err)
+	}
+}
+
+func (a *AS2) sendAsyncMDN(ctx context.Context, url string, mdn *as2component.Outbound) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(mdn.Body))
+	if err != nil {
+		a.logger.Errorf("as2 binding: invalid async MDN URL %s: %v", url, err)
+		return
+	}
+	req.Header = mdn.Header
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		a.logger.Errorf("as2 binding: failed to send async MDN to %s: %v", url, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		a.logger.Errorf("as2 binding: async MDN to %s returned status %d", url, resp.StatusCode)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func newKeyPair(t *testing.T, name string) (certPEM, keyPEM string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func newBinding(t *testing.T, props map[string]string) *AS2 {
+	t.Helper()
+
+	a := NewAS2(logger.NewLogger("test")).(*AS2)
+	require.NoError(t, a.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+
+	return a
+}
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, config, err := parseMetadata(map[string]string{
+			"as2From":    "ALPHA",
+			"as2To":      "BETA",
+			"as2Sign":    "false",
+			"as2Encrypt": "false",
+			"as2Mdn":     "none",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, defaultContentType, m.ContentType)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+		assert.Equal(t, defaultPort, m.Port)
+		assert.Equal(t, defaultPath, m.Path)
+		assert.False(t, config.Sign)
+		assert.False(t, config.Encrypt)
+	})
+
+	t.Run("signing requires keys by default", func(t *testing.T) {
+		_, _, err := parseMetadata(map[string]string{
+			"as2From": "ALPHA",
+			"as2To":   "BETA",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		_, _, err := parseMetadata(map[string]string{
+			"as2From":        "ALPHA",
+			"as2To":          "BETA",
+			"as2Certificate": "nope",
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestExchange(t *testing.T) {
+	alphaCert, alphaKey := newKeyPair(t, "alpha")
+	betaCert, betaKey := newKeyPair(t, "beta")
+
+	receiver := newBinding(t, map[string]string{
+		"as2From":               "BETA",
+		"as2To":                 "ALPHA",
+		"as2Certificate":        betaCert,
+		"as2PrivateKey":         betaKey,
+		"as2PartnerCertificate": alphaCert,
+	})
+
+	var received *bindings.ReadResponse
+	handlerErr := error(nil)
+	srv := httptest.NewServer(receiver.serve(context.Background(), func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+		received = r
+		return nil, handlerErr
+	}))
+	defer srv.Close()
+
+	sender := newBinding(t, map[string]string{
+		"as2Url":                srv.URL,
+		"as2From":               "ALPHA",
+		"as2To":                 "BETA",
+		"as2Certificate":        alphaCert,
+		"as2PrivateKey":         alphaKey,
+		"as2PartnerCertificate": betaCert,
+		"subject":               "orders",
+	})
+
+	t.Run("sync MDN", func(t *testing.T) {
+		resp, err := sender.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("ISA*00*~IEA*1*000000001~"),
+			Metadata:  map[string]string{"fileName": "po.x12"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, resp.Metadata["mdnDisposition"], "processed")
+		assert.Equal(t, "true", resp.Metadata["mdnSigned"])
+
+		require.NotNil(t, received)
+		assert.Equal(t, "ISA*00*~IEA*1*000000001~", string(received.Data))
+		assert.Equal(t, "application/edi-x12", *received.ContentType)
+		assert.Equal(t, "po.x12", received.Metadata["fileName"])
+		assert.Equal(t, "orders", received.Metadata["subject"])
+		assert.Equal(t, resp.Metadata["messageId"], received.Metadata["messageId"])
+		assert.Equal(t, "true", received.Metadata["signed"])
+		assert.Equal(t, "true", received.Metadata["encrypted"])
+	})
+
+	t.Run("handler error is reported in MDN", func(t *testing.T) {
+		handlerErr = errors.New("boom")
+		defer func() { handlerErr = nil }()
+
+		_, err := sender.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected-processing-error")
+	})
+
+	t.Run("async MDN", func(t *testing.T) {
+		mdns := make(chan *bindings.ReadResponse, 1)
+		asyncSender := newBinding(t, map[string]string{
+			"as2From":               "ALPHA",
+			"as2To":                 "BETA",
+			"as2Certificate":        alphaCert,
+			"as2PrivateKey":         alphaKey,
+			"as2PartnerCertificate": betaCert,
+		})
+		mdnSrv := httptest.NewServer(asyncSender.serve(context.Background(), func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+			mdns <- r
+			return nil, nil
+		}))
+		defer mdnSrv.Close()
+
+		asyncSender = newBinding(t, map[string]string{
+			"as2Url":                srv.URL,
+			"as2From":               "ALPHA",
+			"as2To":                 "BETA",
+			"as2Certificate":        alphaCert,
+			"as2PrivateKey":         alphaKey,
+			"as2PartnerCertificate": betaCert,
+			"as2Mdn":                "async",
+			"as2AsyncMdnUrl":        mdnSrv.URL,
+		})
+
+		resp, err := asyncSender.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("data"),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Metadata["mdnDisposition"])
+
+		select {
+		case mdn := <-mdns:
+			assert.Equal(t, "mdn", mdn.Metadata["as2Type"])
+			assert.Equal(t, resp.Metadata["messageId"], mdn.Metadata["originalMessageId"])
+			assert.Contains(t, mdn.Metadata["mdnDisposition"], "processed")
+			assert.Equal(t, resp.Metadata["mic"], mdn.Metadata["mic"])
+			assert.Equal(t, "true", mdn.Metadata["mdnSigned"])
+		case <-time.After(5 * time.Second):
+			t.Fatal("async MDN not received")
+		}
+	})
+
+	t.Run("unsupported operation", func(t *testing.T) {
+		_, err := sender.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"fmt"
+	"time"
+
+	as2component "github.com/dapr/components-contrib/internal/component/as2"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	defaultContentType = "application/edi-x12"
+	defaultTimeout     = 30 * time.Second
+	defaultPort        = "8080"
+	defaultPath        = "/as2"
+)
+
+type as2Metadata struct {
+	as2component.Settings `mapstructure:",squash"`
+
+	// Defaults for outbound messages; can be overridden per request.
+	Subject     string        `mapstructure:"subject"`
+	ContentType string        `mapstructure:"contentType"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+
+	// Port and Path of the endpoint that receives inbound messages and asynchronous MDNs.
+	Port string `mapstructure:"port"`
+	Path string `mapstructure:"path"`
+}
+
+func parseMetadata(meta map[string]string) (as2Metadata, *as2component.Config, error) {
+	m := as2Metadata{
+		Settings:    as2component.NewSettings(),
+		ContentType: defaultContentType,
+		Timeout:     defaultTimeout,
+		Port:        defaultPort,
+		Path:        defaultPath,
+	}
+	if err := metadata.DecodeMetadata(meta, &m); err != nil {
+		return m, nil, err
+	}
+
+	if m.Timeout <= 0 {
+		return m, nil, fmt.Errorf("invalid timeout '%s'", m.Timeout)
+	}
+
+	config, err := m.Settings.Config()
+	if err != nil {
+		return m, nil, err
+	}
+
+	return m, config, nil
+}
@@ -35,12 +35,16 @@ type DynamoDB struct {
 }
 
 type dynamoDBMetadata struct {
-	Region       string `json:"region"`
-	Endpoint     string `json:"endpoint"`
-	AccessKey    string `json:"accessKey"`
-	SecretKey    string `json:"secretKey"`
-	SessionToken string `json:"sessionToken"`
-	Table        string `json:"table"`
+	Region               string `json:"region"`
+	Endpoint             string `json:"endpoint"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	Table                string `json:"table"`
 }
 
 // NewDynamoDB returns a new DynamoDB instance.
@@ -104,7 +108,17 @@ func (d *DynamoDB) getDynamoDBMetadata(spec bindings.Metadata) (*dynamoDBMetadat
 }
 
 func (d *DynamoDB) getClient(metadata *dynamoDBMetadata) (*dynamodb.DynamoDB, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -16,6 +16,7 @@ package kinesis
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -48,14 +49,28 @@ type AWSKinesis struct {
 }
 
 type kinesisMetadata struct {
-	StreamName          string `json:"streamName"`
-	ConsumerName        string `json:"consumerName"`
-	Region              string `json:"region"`
-	Endpoint            string `json:"endpoint"`
-	AccessKey           string `json:"accessKey"`
-	SecretKey           string `json:"secretKey"`
-	SessionToken        string `json:"sessionToken"`
-	KinesisConsumerMode string `json:"mode" mapstructure:"mode"`
+	StreamName           string `json:"streamName"`
+	ConsumerName         string `json:"consumerName"`
+	Region               string `json:"region"`
+	Endpoint             string `json:"endpoint"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	KinesisConsumerMode  string `json:"mode" mapstructure:"mode"`
+
+	// DynamoDB table storing the shard leases and checkpoints. Defaults to the consumer name in shared mode.
+	// In extended mode, setting it makes the Enhanced Fan-Out consumer checkpoint its progress and balance the shards across replicas.
+	CheckpointTable     string        `json:"checkpointTable"`
+	DynamoDBEndpoint    string        `json:"dynamoDBEndpoint"`
+	InitialPosition     string        `json:"initialPosition"`
+	MaxLeasesForWorker  int           `json:"maxLeasesForWorker"`
+	EnableLeaseStealing bool          `json:"enableLeaseStealing"`
+	FailoverTime        time.Duration `json:"failoverTime"`
+	ShardSyncInterval   time.Duration `json:"shardSyncInterval"`
 }
 
 const (
@@ -66,6 +81,9 @@ const (
 	SharedThroughput = "shared"
 
 	partitionKeyName = "partitionKey"
+
+	initialPositionLatest      = "LATEST"
+	initialPositionTrimHorizon = "TRIM_HORIZON"
 )
 
 // recordProcessorFactory.
@@ -114,11 +132,11 @@ func (a *AWSKinesis) Init(metadata bindings.Metadata) error {
 		return err
 	}
 
-	if m.KinesisConsumerMode == SharedThroughput {
-		kclConfig := config.NewKinesisClientLibConfigWithCredential(m.ConsumerName,
-			m.StreamName, m.Region, m.ConsumerName,
-			credentials.NewStaticCredentials(m.AccessKey, m.SecretKey, ""))
-		a.workerConfig = kclConfig
+	if m.KinesisConsumerMode == SharedThroughput || m.CheckpointTable != "" {
+		a.workerConfig, err = a.kclConfig(m)
+		if err != nil {
+			return err
+		}
 	}
 
 	a.streamARN = stream.StreamDescription.StreamARN
@@ -128,6 +146,47 @@ func (a *AWSKinesis) Init(metadata bindings.Metadata) error {
 	return nil
 }
 
+// kclConfig returns the configuration of the KCL worker, which stores the shard leases and checkpoints in DynamoDB.
+func (a *AWSKinesis) kclConfig(m *kinesisMetadata) (*config.KinesisClientLibConfiguration, error) {
+	// Each replica needs its own worker ID for the shards to be balanced across them
+	kclConfig := config.NewKinesisClientLibConfigWithCredential(m.ConsumerName,
+		m.StreamName, m.Region, uuid.New().String(),
+		credentials.NewStaticCredentials(m.AccessKey, m.SecretKey, m.SessionToken))
+
+	if m.CheckpointTable != "" {
+		kclConfig.WithTableName(m.CheckpointTable)
+	}
+	if m.Endpoint != "" {
+		kclConfig.WithKinesisEndpoint(m.Endpoint)
+	}
+	if m.DynamoDBEndpoint != "" {
+		kclConfig.WithDynamoDBEndpoint(m.DynamoDBEndpoint)
+	}
+	switch strings.ToUpper(m.InitialPosition) {
+	case "", initialPositionLatest:
+		kclConfig.WithInitialPositionInStream(config.LATEST)
+	case initialPositionTrimHorizon:
+		kclConfig.WithInitialPositionInStream(config.TRIM_HORIZON)
+	default:
+		return nil, fmt.Errorf("%s invalid \"initialPosition\" field %s", "aws.kinesis", m.InitialPosition)
+	}
+	if m.MaxLeasesForWorker > 0 {
+		kclConfig.WithMaxLeasesForWorker(m.MaxLeasesForWorker)
+	}
+	if m.FailoverTime > 0 {
+		kclConfig.WithFailoverTimeMillis(int(m.FailoverTime.Milliseconds()))
+	}
+	if m.ShardSyncInterval > 0 {
+		kclConfig.WithShardSyncIntervalMillis(int(m.ShardSyncInterval.Milliseconds()))
+	}
+	kclConfig.WithLeaseStealing(m.EnableLeaseStealing)
+	if m.KinesisConsumerMode == ExtendedFanout {
+		kclConfig.WithEnhancedFanOutConsumerName(m.ConsumerName)
+	}
+
+	return kclConfig, nil
+}
+
 func (a *AWSKinesis) Operations() []bindings.OperationKind {
 	return []bindings.OperationKind{bindings.CreateOperation}
 }
@@ -147,13 +206,13 @@ func (a *AWSKinesis) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*
 }
 
 func (a *AWSKinesis) Read(ctx context.Context, handler bindings.Handler) (err error) {
-	if a.metadata.KinesisConsumerMode == SharedThroughput {
+	if a.workerConfig != nil {
 		a.worker = worker.NewWorker(a.recordProcessorFactory(ctx, handler), a.workerConfig)
 		err = a.worker.Start()
 		if err != nil {
 			return err
 		}
-	} else if a.metadata.KinesisConsumerMode == ExtendedFanout {
+	} else {
 		var stream *kinesis.DescribeStreamOutput
 		stream, err = a.client.DescribeStream(&kinesis.DescribeStreamInput{StreamName: &a.metadata.StreamName})
 		if err != nil {
@@ -168,9 +227,9 @@ func (a *AWSKinesis) Read(ctx context.Context, handler bindings.Handler) (err er
 	// Wait for context cancelation then stop
 	go func() {
 		<-ctx.Done()
-		if a.metadata.KinesisConsumerMode == SharedThroughput {
+		if a.worker != nil {
 			a.worker.Shutdown()
-		} else if a.metadata.KinesisConsumerMode == ExtendedFanout {
+		} else {
 			a.deregisterConsumer(a.streamARN, a.consumerARN)
 		}
 	}()
@@ -194,12 +253,15 @@ func (a *AWSKinesis) Subscribe(ctx context.Context, streamDesc kinesis.StreamDes
 			bo := backoff.NewExponentialBackOff()
 			bo.InitialInterval = 2 * time.Second
 
+			// Subscriptions expire after 5 minutes: resume after the last sequence number received
+			var continuation *string
+
 			// Repeat until context is canceled
 			for ctx.Err() == nil {
 				sub, err := a.client.SubscribeToShardWithContext(ctx, &kinesis.SubscribeToShardInput{
 					ConsumerARN:      consumerARN,
 					ShardId:          s.ShardId,
-					StartingPosition: &kinesis.StartingPosition{Type: aws.String(kinesis.ShardIteratorTypeLatest)},
+					StartingPosition: startingPosition(continuation),
 				})
 				if err != nil {
 					wait := bo.NextBackOff()
@@ -212,6 +274,7 @@ func (a *AWSKinesis) Subscribe(ctx context.Context, streamDesc kinesis.StreamDes
 				bo.Reset()
 
 				// Process events
+				closed := false
 				for event := range sub.EventStream.Events() {
 					switch e := event.(type) {
 					case *kinesis.SubscribeToShardEvent:
@@ -220,8 +283,19 @@ func (a *AWSKinesis) Subscribe(ctx context.Context, streamDesc kinesis.StreamDes
 								Data: rec.Data,
 							})
 						}
+						// The continuation sequence number is nil once the shard has been closed
+						if e.ContinuationSequenceNumber == nil {
+							closed = true
+						} else {
+							continuation = e.ContinuationSequenceNumber
+						}
 					}
 				}
+
+				if closed {
+					a.logger.Infof("Shard %v has been closed", aws.StringValue(s.ShardId))
+					return nil
+				}
 			}
 			return nil
 		}(i, shard)
@@ -230,6 +304,16 @@ func (a *AWSKinesis) Subscribe(ctx context.Context, streamDesc kinesis.StreamDes
 	return nil
 }
 
+func startingPosition(continuation *string) *kinesis.StartingPosition {
+	if continuation == nil {
+		return &kinesis.StartingPosition{Type: aws.String(kinesis.ShardIteratorTypeLatest)}
+	}
+	return &kinesis.StartingPosition{
+		Type:           aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber),
+		SequenceNumber: continuation,
+	}
+}
+
 func (a *AWSKinesis) ensureConsumer(parentCtx context.Context, streamARN *string) (*string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	consumer, err := a.client.DescribeStreamConsumerWithContext(ctx, &kinesis.DescribeStreamConsumerInput{
@@ -313,7 +397,17 @@ func (a *AWSKinesis) waitUntilConsumerExists(ctx aws.Context, input *kinesis.Des
 }
 
 func (a *AWSKinesis) getClient(metadata *kinesisMetadata) (*kinesis.Kinesis, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -15,8 +15,11 @@ package kinesis
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vmware-go-kcl/clientlibrary/config"
 
 	"github.com/dapr/components-contrib/bindings"
 )
@@ -32,6 +35,10 @@ func TestParseMetadata(t *testing.T) {
 		"mode":         "extended",
 		"endpoint":     "endpoint",
 		"sessionToken": "token",
+
+		"checkpointTable":   "checkpoints",
+		"initialPosition":   "TRIM_HORIZON",
+		"shardSyncInterval": "30s",
 	}
 	kinesis := AWSKinesis{}
 	meta, err := kinesis.parseMetadata(m)
@@ -44,4 +51,72 @@ func TestParseMetadata(t *testing.T) {
 	assert.Equal(t, "endpoint", meta.Endpoint)
 	assert.Equal(t, "token", meta.SessionToken)
 	assert.Equal(t, "extended", meta.KinesisConsumerMode)
+	assert.Equal(t, "checkpoints", meta.CheckpointTable)
+	assert.Equal(t, "TRIM_HORIZON", meta.InitialPosition)
+	assert.Equal(t, 30*time.Second, meta.ShardSyncInterval)
+}
+
+func TestKCLConfig(t *testing.T) {
+	kinesis := AWSKinesis{}
+
+	t.Run("shared defaults", func(t *testing.T) {
+		cfg, err := kinesis.kclConfig(&kinesisMetadata{
+			ConsumerName:        "consumer",
+			StreamName:          "stream",
+			Region:              "region",
+			KinesisConsumerMode: SharedThroughput,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "consumer", cfg.TableName)
+		assert.Equal(t, config.LATEST, cfg.InitialPositionInStream)
+		assert.False(t, cfg.EnableEnhancedFanOutConsumer)
+		assert.NotEqual(t, "consumer", cfg.WorkerID)
+	})
+
+	t.Run("extended with checkpointing", func(t *testing.T) {
+		cfg, err := kinesis.kclConfig(&kinesisMetadata{
+			ConsumerName:        "consumer",
+			StreamName:          "stream",
+			Region:              "region",
+			KinesisConsumerMode: ExtendedFanout,
+			CheckpointTable:     "checkpoints",
+			DynamoDBEndpoint:    "http://localhost:8000",
+			InitialPosition:     "trim_horizon",
+			MaxLeasesForWorker:  4,
+			EnableLeaseStealing: true,
+			FailoverTime:        20 * time.Second,
+			ShardSyncInterval:   30 * time.Second,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "checkpoints", cfg.TableName)
+		assert.Equal(t, "http://localhost:8000", cfg.DynamoDBEndpoint)
+		assert.Equal(t, config.TRIM_HORIZON, cfg.InitialPositionInStream)
+		assert.Equal(t, 4, cfg.MaxLeasesForWorker)
+		assert.True(t, cfg.EnableLeaseStealing)
+		assert.Equal(t, 20000, cfg.FailoverTimeMillis)
+		assert.Equal(t, 30000, cfg.ShardSyncIntervalMillis)
+		assert.True(t, cfg.EnableEnhancedFanOutConsumer)
+		assert.Equal(t, "consumer", cfg.EnhancedFanOutConsumerName)
+	})
+
+	t.Run("invalid initial position", func(t *testing.T) {
+		_, err := kinesis.kclConfig(&kinesisMetadata{
+			ConsumerName:    "consumer",
+			StreamName:      "stream",
+			Region:          "region",
+			InitialPosition: "earliest",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestStartingPosition(t *testing.T) {
+	pos := startingPosition(nil)
+	assert.Equal(t, "LATEST", *pos.Type)
+	assert.Nil(t, pos.SequenceNumber)
+
+	seq := "49590338271490256608559692538361571095921575989136588898"
+	pos = startingPosition(&seq)
+	assert.Equal(t, "AFTER_SEQUENCE_NUMBER", *pos.Type)
+	assert.Equal(t, seq, *pos.SequenceNumber)
 }
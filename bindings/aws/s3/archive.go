@@ -0,0 +1,503 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3control"
+	"github.com/google/uuid"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	// Restore status of an archived object.
+	restoreStatusAvailable  = "available"
+	restoreStatusArchived   = "archived"
+	restoreStatusStarted    = "started"
+	restoreStatusInProgress = "inProgress"
+	restoreStatusRestored   = "restored"
+
+	errCodeRestoreAlreadyInProgress = "RestoreAlreadyInProgress"
+
+	defaultRestoreDays        = 1
+	defaultRestoreJobPriority = 10
+	defaultManifestPrefix     = "restore-manifests/"
+)
+
+type restoreResponse struct {
+	Status string `json:"status"`
+}
+
+type restoreStatusResponse struct {
+	Status        string     `json:"status"`
+	StorageClass  string     `json:"storageClass,omitempty"`
+	ArchiveStatus string     `json:"archiveStatus,omitempty"`
+	Expiry        *time.Time `json:"expiry,omitempty"`
+}
+
+type createRestoreJobPayload struct {
+	// The keys to restore, written to a manifest under manifestPrefix.
+	Keys           []string `json:"keys"`
+	ManifestPrefix string   `json:"manifestPrefix"`
+	// An existing CSV manifest of bucket,key lines, in the bucket of the binding.
+	ManifestKey  string `json:"manifestKey"`
+	Description  string `json:"description"`
+	Priority     *int64 `json:"priority"`
+	ReportPrefix string `json:"reportPrefix"`
+}
+
+type createRestoreJobResponse struct {
+	JobID       string `json:"jobID"`
+	ManifestKey string `json:"manifestKey"`
+}
+
+type listRestoreJobsPayload struct {
+	Statuses   []string `json:"statuses"`
+	MaxResults int64    `json:"maxResults"`
+	NextToken  string   `json:"nextToken"`
+}
+
+type restoreJob struct {
+	JobID           string     `json:"jobID"`
+	Status          string     `json:"status"`
+	Description     string     `json:"description,omitempty"`
+	CreationTime    *time.Time `json:"creationTime,omitempty"`
+	TerminationDate *time.Time `json:"terminationDate,omitempty"`
+	TotalTasks      int64      `json:"totalTasks"`
+	SucceededTasks  int64      `json:"succeededTasks"`
+	FailedTasks     int64      `json:"failedTasks"`
+	FailureReasons  []string   `json:"failureReasons,omitempty"`
+}
+
+type listRestoreJobsResponse struct {
+	Jobs []restoreJob `json:"jobs"`
+}
+
+// restore initiates the restore of an archived object, which is readable once restoreStatus reports it restored.
+func (s *AWSS3) restore(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	if key == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataKey)
+	}
+	days, err := restoreDays(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	tier, err := restoreTier(req.Metadata[metadataRestoreTier], s3.Tier_Values())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		requestID  requestIDCollector
+		statusCode int
+	)
+	captureStatus := func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			if r.HTTPResponse != nil {
+				statusCode = r.HTTPResponse.StatusCode
+			}
+		})
+	}
+	_, err = s.s3Client.RestoreObjectWithContext(ctx, &s3.RestoreObjectInput{
+		Bucket: ptr.Of(s.metadata.Bucket),
+		Key:    ptr.Of(key),
+		RestoreRequest: &s3.RestoreRequest{
+			Days:                 ptr.Of(days),
+			GlacierJobParameters: &s3.GlacierJobParameters{Tier: ptr.Of(tier)},
+		},
+	}, requestID.option(), captureStatus)
+
+	status := restoreStatusStarted
+	var aerr awserr.Error
+	switch {
+	case errors.As(err, &aerr) && aerr.Code() == errCodeRestoreAlreadyInProgress:
+		status = restoreStatusInProgress
+	case err != nil:
+		return nil, fmt.Errorf("s3 binding error: restore operation failed: %w", err)
+	case statusCode == http.StatusOK:
+		// S3 answers 200 rather than 202 when the object is already restored: this extends the restore period.
+		status = restoreStatusRestored
+	}
+
+	return s.jsonResponse(req.Operation, requestID.get(), restoreResponse{Status: status})
+}
+
+// restoreStatus reports whether an object is archived, being restored, or readable.
+func (s *AWSS3) restoreStatus(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	if key == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataKey)
+	}
+
+	var requestID requestIDCollector
+	head, err := s.s3Client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: ptr.Of(s.metadata.Bucket),
+		Key:    ptr.Of(key),
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: restoreStatus operation failed: %w", err)
+	}
+
+	res := restoreStatusResponse{
+		StorageClass:  aws.StringValue(head.StorageClass),
+		ArchiveStatus: aws.StringValue(head.ArchiveStatus),
+	}
+	res.Status, res.Expiry = parseRestoreHeader(head.Restore)
+	if res.Status == "" {
+		res.Status = restoreStatusAvailable
+		if res.ArchiveStatus != "" || res.StorageClass == s3.StorageClassGlacier || res.StorageClass == s3.StorageClassDeepArchive {
+			res.Status = restoreStatusArchived
+		}
+	}
+
+	return s.jsonResponse(req.Operation, requestID.get(), res)
+}
+
+// parseRestoreHeader parses the x-amz-restore header, e.g. `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`.
+func parseRestoreHeader(header *string) (string, *time.Time) {
+	if header == nil || *header == "" {
+		return "", nil
+	}
+
+	var (
+		ongoing bool
+		expiry  *time.Time
+	)
+	rest := *header
+	for rest != "" {
+		var name, value string
+		name, rest, _ = strings.Cut(rest, "=")
+		name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), ","))
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		switch name {
+		case "ongoing-request":
+			ongoing = value == "true"
+		case "expiry-date":
+			if t, err := time.Parse(http.TimeFormat, value); err == nil {
+				expiry = &t
+			}
+		}
+	}
+
+	if ongoing {
+		return restoreStatusInProgress, nil
+	}
+	return restoreStatusRestored, expiry
+}
+
+// createRestoreJob creates an S3 Batch Operations job restoring the objects of a manifest.
+func (s *AWSS3) createRestoreJob(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if s.metadata.AccountID == "" || s.metadata.BatchOperationsRoleArn == "" {
+		return nil, fmt.Errorf("s3 binding error: metadata 'accountId' and 'batchOperationsRoleArn' are required for %s", req.Operation)
+	}
+	var payload createRestoreJobPayload
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &payload); err != nil {
+			return nil, fmt.Errorf("s3 binding error: invalid %s payload: %w", req.Operation, err)
+		}
+	}
+	if (len(payload.Keys) == 0) == (payload.ManifestKey == "") {
+		return nil, errors.New("s3 binding error: exactly one of 'keys' and 'manifestKey' is required")
+	}
+	days, err := restoreDays(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	tier, err := restoreTier(req.Metadata[metadataRestoreTier], s3control.S3GlacierJobTier_Values())
+	if err != nil {
+		return nil, err
+	}
+
+	var requestID requestIDCollector
+	manifestKey, etag, err := s.restoreManifest(ctx, &payload, &requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &s3control.JobReport{Enabled: ptr.Of(false)}
+	if payload.ReportPrefix != "" {
+		report = &s3control.JobReport{
+			Enabled:     ptr.Of(true),
+			Bucket:      ptr.Of(s.arn(s.metadata.Bucket)),
+			Prefix:      ptr.Of(payload.ReportPrefix),
+			Format:      ptr.Of(s3control.JobReportFormatReportCsv20180820),
+			ReportScope: ptr.Of(s3control.JobReportScopeAllTasks),
+		}
+	}
+	priority := int64(defaultRestoreJobPriority)
+	if payload.Priority != nil {
+		priority = *payload.Priority
+	}
+	input := &s3control.CreateJobInput{
+		AccountId:            ptr.Of(s.metadata.AccountID),
+		ClientRequestToken:   ptr.Of(uuid.NewString()),
+		ConfirmationRequired: ptr.Of(false),
+		Operation: &s3control.JobOperation{
+			S3InitiateRestoreObject: &s3control.S3InitiateRestoreObjectOperation{
+				ExpirationInDays: ptr.Of(days),
+				GlacierJobTier:   ptr.Of(tier),
+			},
+		},
+		Manifest: &s3control.JobManifest{
+			Spec: &s3control.JobManifestSpec{
+				Format: ptr.Of(s3control.JobManifestFormatS3batchOperationsCsv20180820),
+				Fields: []*string{ptr.Of(s3control.JobManifestFieldNameBucket), ptr.Of(s3control.JobManifestFieldNameKey)},
+			},
+			Location: &s3control.JobManifestLocation{
+				ObjectArn: ptr.Of(s.arn(s.metadata.Bucket + "/" + manifestKey)),
+				ETag:      ptr.Of(etag),
+			},
+		},
+		Priority: ptr.Of(priority),
+		Report:   report,
+		RoleArn:  ptr.Of(s.metadata.BatchOperationsRoleArn),
+	}
+	if payload.Description != "" {
+		input.Description = ptr.Of(payload.Description)
+	}
+	out, err := s.s3ControlClient.CreateJobWithContext(ctx, input, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: %s operation failed: %w", req.Operation, err)
+	}
+
+	return s.jsonResponse(req.Operation, requestID.get(), createRestoreJobResponse{
+		JobID:       aws.StringValue(out.JobId),
+		ManifestKey: manifestKey,
+	})
+}
+
+// restoreManifest returns the key and the ETag of the manifest of a restore job, uploading it if keys are given.
+func (s *AWSS3) restoreManifest(ctx context.Context, payload *createRestoreJobPayload, requestID *requestIDCollector) (string, string, error) {
+	if payload.ManifestKey != "" {
+		head, err := s.s3Client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: ptr.Of(s.metadata.Bucket),
+			Key:    ptr.Of(payload.ManifestKey),
+		}, requestID.option())
+		if err != nil {
+			return "", "", fmt.Errorf("s3 binding error: failed to read manifest %s: %w", payload.ManifestKey, err)
+		}
+		return payload.ManifestKey, strings.Trim(aws.StringValue(head.ETag), `"`), nil
+	}
+
+	// Keys must be URL-encoded in the manifest.
+	var manifest bytes.Buffer
+	for _, key := range payload.Keys {
+		manifest.WriteString(s.metadata.Bucket + "," + url.PathEscape(key) + "\n")
+	}
+	prefix := payload.ManifestPrefix
+	if prefix == "" {
+		prefix = defaultManifestPrefix
+	}
+	manifestKey := prefix + uuid.NewString() + ".csv"
+	out, err := s.s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      ptr.Of(s.metadata.Bucket),
+		Key:         ptr.Of(manifestKey),
+		Body:        bytes.NewReader(manifest.Bytes()),
+		ContentType: ptr.Of("text/csv"),
+	}, requestID.option())
+	if err != nil {
+		return "", "", fmt.Errorf("s3 binding error: failed to upload manifest: %w", err)
+	}
+	return manifestKey, strings.Trim(aws.StringValue(out.ETag), `"`), nil
+}
+
+func (s *AWSS3) getRestoreJob(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	jobID, err := s.restoreJobID(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var requestID requestIDCollector
+	out, err := s.s3ControlClient.DescribeJobWithContext(ctx, &s3control.DescribeJobInput{
+		AccountId: ptr.Of(s.metadata.AccountID),
+		JobId:     ptr.Of(jobID),
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: %s operation failed: %w", req.Operation, err)
+	}
+
+	job := restoreJob{}
+	if d := out.Job; d != nil {
+		job = newRestoreJob(d.JobId, d.Status, d.Description, d.CreationTime, d.TerminationDate, d.ProgressSummary)
+		for _, f := range d.FailureReasons {
+			job.FailureReasons = append(job.FailureReasons, aws.StringValue(f.FailureCode)+": "+aws.StringValue(f.FailureReason))
+		}
+	}
+	return s.jsonResponse(req.Operation, requestID.get(), job)
+}
+
+// listRestoreJobs lists the restore jobs of the account, filtering out the jobs running other operations.
+func (s *AWSS3) listRestoreJobs(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if s.metadata.AccountID == "" {
+		return nil, fmt.Errorf("s3 binding error: metadata 'accountId' is required for %s", req.Operation)
+	}
+	var payload listRestoreJobsPayload
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &payload); err != nil {
+			return nil, fmt.Errorf("s3 binding error: invalid %s payload: %w", req.Operation, err)
+		}
+	}
+
+	input := &s3control.ListJobsInput{
+		AccountId: ptr.Of(s.metadata.AccountID),
+	}
+	for _, status := range payload.Statuses {
+		input.JobStatuses = append(input.JobStatuses, ptr.Of(status))
+	}
+	if payload.MaxResults > 0 {
+		input.MaxResults = ptr.Of(payload.MaxResults)
+	}
+	if payload.NextToken != "" {
+		input.NextToken = ptr.Of(payload.NextToken)
+	}
+
+	var requestID requestIDCollector
+	out, err := s.s3ControlClient.ListJobsWithContext(ctx, input, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: %s operation failed: %w", req.Operation, err)
+	}
+
+	res := listRestoreJobsResponse{Jobs: []restoreJob{}}
+	for _, d := range out.Jobs {
+		if aws.StringValue(d.Operation) != s3control.OperationNameS3initiateRestoreObject {
+			continue
+		}
+		res.Jobs = append(res.Jobs, newRestoreJob(d.JobId, d.Status, d.Description, d.CreationTime, d.TerminationDate, d.ProgressSummary))
+	}
+
+	resp, err := s.jsonResponse(req.Operation, requestID.get(), res)
+	if err != nil {
+		return nil, err
+	}
+	if out.NextToken != nil {
+		resp.Metadata[bindings.ResponseMetadataNextCursor] = *out.NextToken
+	}
+	return resp, nil
+}
+
+func (s *AWSS3) cancelRestoreJob(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	jobID, err := s.restoreJobID(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var requestID requestIDCollector
+	out, err := s.s3ControlClient.UpdateJobStatusWithContext(ctx, &s3control.UpdateJobStatusInput{
+		AccountId:          ptr.Of(s.metadata.AccountID),
+		JobId:              ptr.Of(jobID),
+		RequestedJobStatus: ptr.Of(s3control.RequestedJobStatusCancelled),
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: %s operation failed: %w", req.Operation, err)
+	}
+
+	return s.jsonResponse(req.Operation, requestID.get(), restoreJob{
+		JobID:  jobID,
+		Status: aws.StringValue(out.Status),
+	})
+}
+
+func (s *AWSS3) restoreJobID(req *bindings.InvokeRequest) (string, error) {
+	if s.metadata.AccountID == "" {
+		return "", fmt.Errorf("s3 binding error: metadata 'accountId' is required for %s", req.Operation)
+	}
+	jobID := req.Metadata[metadataJobID]
+	if jobID == "" {
+		return "", fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataJobID)
+	}
+	return jobID, nil
+}
+
+func newRestoreJob(id, status, description *string, created, terminated *time.Time, progress *s3control.JobProgressSummary) restoreJob {
+	job := restoreJob{
+		JobID:           aws.StringValue(id),
+		Status:          aws.StringValue(status),
+		Description:     aws.StringValue(description),
+		CreationTime:    created,
+		TerminationDate: terminated,
+	}
+	if progress != nil {
+		job.TotalTasks = aws.Int64Value(progress.TotalNumberOfTasks)
+		job.SucceededTasks = aws.Int64Value(progress.NumberOfTasksSucceeded)
+		job.FailedTasks = aws.Int64Value(progress.NumberOfTasksFailed)
+	}
+	return job
+}
+
+// arn returns the ARN of a bucket or an object, in the partition of the region of the binding.
+func (s *AWSS3) arn(resource string) string {
+	partition := endpoints.AwsPartitionID
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), s.metadata.Region); ok {
+		partition = p.ID()
+	}
+	return "arn:" + partition + ":s3:::" + resource
+}
+
+func (s *AWSS3) jsonResponse(operation bindings.OperationKind, requestID string, v interface{}) (*bindings.InvokeResponse, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: error marshalling %s response: %w", operation, err)
+	}
+	return &bindings.InvokeResponse{
+		Data:     data,
+		Metadata: responseMetadata(operation, requestID),
+	}, nil
+}
+
+func restoreDays(md map[string]string) (int64, error) {
+	val := md[metadataRestoreDays]
+	if val == "" {
+		return defaultRestoreDays, nil
+	}
+	days, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("s3 binding error: invalid metadata '%s': must be a positive number of days", metadataRestoreDays)
+	}
+	return days, nil
+}
+
+// restoreTier returns the retrieval tier matching val, case-insensitively, among the allowed ones; Standard by default.
+func restoreTier(val string, allowed []string) (string, error) {
+	if val == "" {
+		val = s3.TierStandard
+	}
+	for _, v := range allowed {
+		if strings.EqualFold(v, val) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("s3 binding error: invalid metadata '%s' %s; allowed: %s", metadataRestoreTier, val, strings.Join(allowed, ", "))
+}
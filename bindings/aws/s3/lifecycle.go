@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/utils"
+	"github.com/dapr/kit/ptr"
+)
+
+type copyResponse struct {
+	VersionID *string `json:"versionID"`
+}
+
+// copy copies the object with the sourceKey to the key, and deletes the source object when moving it.
+func (s *AWSS3) copy(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	sourceKey := req.Metadata[metadataSourceKey]
+	if key == "" || sourceKey == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' and '%s' missing", metadataKey, metadataSourceKey)
+	}
+	sourceBucket := s.metadata.Bucket
+	if val := req.Metadata[metadataSourceBucket]; val != "" {
+		sourceBucket = val
+	}
+
+	var requestID requestIDCollector
+	result, err := s.s3Client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:     ptr.Of(s.metadata.Bucket),
+		Key:        ptr.Of(key),
+		CopySource: ptr.Of(copySource(sourceBucket, sourceKey)),
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: %s operation failed: %w", req.Operation, err)
+	}
+
+	if req.Operation == moveOperation {
+		_, err = s.s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+			Bucket: ptr.Of(sourceBucket),
+			Key:    ptr.Of(sourceKey),
+		}, requestID.option())
+		if err != nil {
+			return nil, fmt.Errorf("s3 binding error: object copied to %s, but deleting the source object failed: %w", key, err)
+		}
+	}
+
+	jsonResponse, err := json.Marshal(copyResponse{VersionID: result.VersionId})
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: error marshalling %s response: %w", req.Operation, err)
+	}
+	return &bindings.InvokeResponse{
+		Data:     jsonResponse,
+		Metadata: responseMetadata(req.Operation, requestID.get()),
+	}, nil
+}
+
+// setTier changes the storage class of the object, which S3 does by copying the object in place.
+func (s *AWSS3) setTier(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	storageClass := req.Metadata[metadataStorageClass]
+	if key == "" || storageClass == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' and '%s' missing", metadataKey, metadataStorageClass)
+	}
+	if !isValidStorageClass(storageClass) {
+		return nil, fmt.Errorf("s3 binding error: invalid storage class %s; allowed: %s", storageClass, strings.Join(s3.StorageClass_Values(), ", "))
+	}
+
+	var requestID requestIDCollector
+	_, err := s.s3Client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:            ptr.Of(s.metadata.Bucket),
+		Key:               ptr.Of(key),
+		CopySource:        ptr.Of(copySource(s.metadata.Bucket, key)),
+		StorageClass:      ptr.Of(storageClass),
+		MetadataDirective: ptr.Of(s3.MetadataDirectiveCopy),
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: setTier operation failed: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, requestID.get()),
+	}, nil
+}
+
+func (s *AWSS3) setLegalHold(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	legalHold := req.Metadata[metadataLegalHold]
+	if key == "" || legalHold == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' and '%s' missing", metadataKey, metadataLegalHold)
+	}
+	status := s3.ObjectLockLegalHoldStatusOff
+	if utils.IsTruthy(legalHold) {
+		status = s3.ObjectLockLegalHoldStatusOn
+	}
+
+	var requestID requestIDCollector
+	_, err := s.s3Client.PutObjectLegalHoldWithContext(ctx, &s3.PutObjectLegalHoldInput{
+		Bucket:    ptr.Of(s.metadata.Bucket),
+		Key:       ptr.Of(key),
+		LegalHold: &s3.ObjectLockLegalHold{Status: ptr.Of(status)},
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: setLegalHold operation failed: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, requestID.get()),
+	}, nil
+}
+
+func (s *AWSS3) setRetention(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	mode := strings.ToUpper(req.Metadata[metadataRetentionMode])
+	if key == "" || mode == "" || req.Metadata[metadataRetainUntil] == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s', '%s' and '%s' missing", metadataKey, metadataRetentionMode, metadataRetainUntil)
+	}
+	if mode != s3.ObjectLockRetentionModeGovernance && mode != s3.ObjectLockRetentionModeCompliance {
+		return nil, fmt.Errorf("s3 binding error: invalid retention mode %s; allowed: %s", mode, strings.Join(s3.ObjectLockRetentionMode_Values(), ", "))
+	}
+	retainUntil, err := time.Parse(time.RFC3339, req.Metadata[metadataRetainUntil])
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: invalid metadata '%s': %w", metadataRetainUntil, err)
+	}
+
+	var requestID requestIDCollector
+	_, err = s.s3Client.PutObjectRetentionWithContext(ctx, &s3.PutObjectRetentionInput{
+		Bucket: ptr.Of(s.metadata.Bucket),
+		Key:    ptr.Of(key),
+		Retention: &s3.ObjectLockRetention{
+			Mode:            ptr.Of(mode),
+			RetainUntilDate: ptr.Of(retainUntil),
+		},
+		BypassGovernanceRetention: ptr.Of(utils.IsTruthy(req.Metadata[metadataBypassGovernanceRetention])),
+	}, requestID.option())
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: setRetention operation failed: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, requestID.get()),
+	}, nil
+}
+
+// copySource returns the URL-encoded source of a copy.
+func copySource(bucket, key string) string {
+	return (&url.URL{Path: bucket + "/" + key}).EscapedPath()
+}
+
+func isValidStorageClass(storageClass string) bool {
+	for _, v := range s3.StorageClass_Values() {
+		if v == storageClass {
+			return true
+		}
+	}
+	return false
+}
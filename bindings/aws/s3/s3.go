@@ -23,12 +23,15 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3control"
 	"github.com/google/uuid"
 
 	"github.com/dapr/components-contrib/bindings"
@@ -46,34 +49,71 @@ const (
 	metadataPresignTTL   = "presignTTL"
 
 	metadataKey = "key"
-
-	defaultMaxResults = 1000
-	presignOperation  = "presign"
+	// The object copied or moved, and its bucket if not the bucket of the binding.
+	metadataSourceKey    = "sourceKey"
+	metadataSourceBucket = "sourceBucket"
+	// The storage class set by setTier, e.g. STANDARD_IA or GLACIER.
+	metadataStorageClass = "storageClass"
+	// "true" or "false", for setLegalHold.
+	metadataLegalHold = "legalHold"
+	// The Object Lock mode and the RFC 3339 time until which the object is retained, for setRetention.
+	metadataRetentionMode             = "retentionMode"
+	metadataRetainUntil               = "retainUntil"
+	metadataBypassGovernanceRetention = "bypassGovernanceRetention"
+	// The number of days a restored copy of an archived object is kept, and the retrieval tier, for restore and createRestoreJob.
+	metadataRestoreDays = "restoreDays"
+	metadataRestoreTier = "restoreTier"
+	// The ID of an S3 Batch Operations job.
+	metadataJobID = "jobID"
+
+	defaultMaxResults      = 1000
+	presignOperation       = "presign"
+	presignUploadOperation = "presignUpload"
+	copyOperation          = "copy"
+	moveOperation          = "move"
+	setTierOperation       = "setTier"
+	setLegalHoldOperation  = "setLegalHold"
+	setRetentionOperation  = "setRetention"
+
+	restoreOperation          = "restore"
+	restoreStatusOperation    = "restoreStatus"
+	createRestoreJobOperation = "createRestoreJob"
+	getRestoreJobOperation    = "getRestoreJob"
+	listRestoreJobsOperation  = "listRestoreJobs"
+	cancelRestoreJobOperation = "cancelRestoreJob"
 )
 
 // AWSS3 is a binding for an AWS S3 storage bucket.
 type AWSS3 struct {
-	metadata   *s3Metadata
-	s3Client   *s3.S3
-	uploader   *s3manager.Uploader
-	downloader *s3manager.Downloader
-	logger     logger.Logger
+	metadata        *s3Metadata
+	s3Client        *s3.S3
+	s3ControlClient *s3control.S3Control
+	uploader        *s3manager.Uploader
+	downloader      *s3manager.Downloader
+	logger          logger.Logger
 }
 
 type s3Metadata struct {
-	Region         string `json:"region"`
-	Endpoint       string `json:"endpoint"`
-	AccessKey      string `json:"accessKey"`
-	SecretKey      string `json:"secretKey"`
-	SessionToken   string `json:"sessionToken"`
-	Bucket         string `json:"bucket"`
-	DecodeBase64   bool   `json:"decodeBase64,string"`
-	EncodeBase64   bool   `json:"encodeBase64,string"`
-	ForcePathStyle bool   `json:"forcePathStyle,string"`
-	DisableSSL     bool   `json:"disableSSL,string"`
-	InsecureSSL    bool   `json:"insecureSSL,string"`
-	FilePath       string
-	PresignTTL     string
+	Region               string `json:"region"`
+	Endpoint             string `json:"endpoint"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	Bucket               string `json:"bucket"`
+	DecodeBase64         bool   `json:"decodeBase64,string"`
+	EncodeBase64         bool   `json:"encodeBase64,string"`
+	ForcePathStyle       bool   `json:"forcePathStyle,string"`
+	DisableSSL           bool   `json:"disableSSL,string"`
+	InsecureSSL          bool   `json:"insecureSSL,string"`
+	// The account and the IAM role of the S3 Batch Operations restore jobs.
+	AccountID              string `json:"accountId"`
+	BatchOperationsRoleArn string `json:"batchOperationsRoleArn"`
+	FilePath               string
+	PresignTTL             string
 }
 
 type createResponse struct {
@@ -128,6 +168,8 @@ func (s *AWSS3) Init(metadata bindings.Metadata) error {
 
 	s.metadata = m
 	s.s3Client = s3.New(session, cfg)
+	// Custom endpoints, e.g. of S3-compatible services, don't resolve the account-prefixed host names of S3 Control.
+	s.s3ControlClient = s3control.New(session, cfg.Copy().WithDisableEndpointHostPrefix(m.Endpoint != ""))
 	s.downloader = s3manager.NewDownloaderWithClient(s.s3Client)
 	s.uploader = s3manager.NewUploaderWithClient(s.s3Client)
 
@@ -145,6 +187,126 @@ func (s *AWSS3) Operations() []bindings.OperationKind {
 		bindings.DeleteOperation,
 		bindings.ListOperation,
 		presignOperation,
+		presignUploadOperation,
+		copyOperation,
+		moveOperation,
+		setTierOperation,
+		setLegalHoldOperation,
+		setRetentionOperation,
+		restoreOperation,
+		restoreStatusOperation,
+		createRestoreJobOperation,
+		getRestoreJobOperation,
+		listRestoreJobsOperation,
+		cancelRestoreJobOperation,
+	}
+}
+
+// OperationsMetadata returns the description of the operations of the binding.
+func (s *AWSS3) OperationsMetadata() []bindings.OperationMetadata {
+	common := []string{bindings.ResponseMetadataOperation, bindings.ResponseMetadataRequestID}
+	return []bindings.OperationMetadata{
+		{
+			Operation:        bindings.CreateOperation,
+			Description:      "Upload an object",
+			RequestMetadata:  []string{metadataKey, metadataDecodeBase64, metadataFilePath, metadataPresignTTL},
+			ResponseMetadata: append([]string{metadataKey}, common...),
+		},
+		{
+			Operation:        bindings.GetOperation,
+			Description:      "Download an object",
+			RequestMetadata:  []string{metadataKey, metadataEncodeBase64},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        bindings.DeleteOperation,
+			Description:      "Delete an object",
+			RequestMetadata:  []string{metadataKey},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        bindings.ListOperation,
+			Description:      "List the objects; pass the cursor as the marker of the next request",
+			ResponseMetadata: append([]string{bindings.ResponseMetadataNextCursor}, common...),
+			Paginated:        true,
+		},
+		{
+			Operation:        presignOperation,
+			Description:      "Generate a pre-signed URL for an object",
+			RequestMetadata:  []string{metadataKey, metadataPresignTTL},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        presignUploadOperation,
+			Description:      "Generate a pre-signed URL to upload an object with a PUT request",
+			RequestMetadata:  []string{metadataKey, metadataPresignTTL},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        copyOperation,
+			Description:      "Copy an object of up to 5 GB, keeping its metadata",
+			RequestMetadata:  []string{metadataKey, metadataSourceKey, metadataSourceBucket},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        moveOperation,
+			Description:      "Copy an object of up to 5 GB, then delete the source object",
+			RequestMetadata:  []string{metadataKey, metadataSourceKey, metadataSourceBucket},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        setTierOperation,
+			Description:      "Change the storage class of an object by copying it in place",
+			RequestMetadata:  []string{metadataKey, metadataStorageClass},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        setLegalHoldOperation,
+			Description:      "Place or remove the Object Lock legal hold of an object",
+			RequestMetadata:  []string{metadataKey, metadataLegalHold},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        setRetentionOperation,
+			Description:      "Set the Object Lock retention mode and period of an object",
+			RequestMetadata:  []string{metadataKey, metadataRetentionMode, metadataRetainUntil, metadataBypassGovernanceRetention},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        restoreOperation,
+			Description:      "Initiate the restore of an archived object",
+			RequestMetadata:  []string{metadataKey, metadataRestoreDays, metadataRestoreTier},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        restoreStatusOperation,
+			Description:      "Report whether an object is archived, being restored or restored",
+			RequestMetadata:  []string{metadataKey},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        createRestoreJobOperation,
+			Description:      "Create an S3 Batch Operations job restoring a list of keys or the objects of a manifest",
+			RequestMetadata:  []string{metadataRestoreDays, metadataRestoreTier},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        getRestoreJobOperation,
+			Description:      "Get the status and progress of a restore job",
+			RequestMetadata:  []string{metadataJobID},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        listRestoreJobsOperation,
+			Description:      "List the restore jobs of the account",
+			ResponseMetadata: append([]string{bindings.ResponseMetadataNextCursor}, common...),
+		},
+		{
+			Operation:        cancelRestoreJobOperation,
+			Description:      "Cancel a restore job",
+			RequestMetadata:  []string{metadataJobID},
+			ResponseMetadata: common,
+		},
 	}
 }
 
@@ -179,11 +341,12 @@ func (s *AWSS3) create(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		r = b64.NewDecoder(b64.StdEncoding, r)
 	}
 
+	var requestID requestIDCollector
 	resultUpload, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 		Bucket: ptr.Of(metadata.Bucket),
 		Key:    ptr.Of(key),
 		Body:   r,
-	})
+	}, s3manager.WithUploaderRequestOptions(requestID.option()))
 	if err != nil {
 		return nil, fmt.Errorf("s3 binding error: uploading failed: %w", err)
 	}
@@ -207,8 +370,11 @@ func (s *AWSS3) create(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		return nil, fmt.Errorf("s3 binding error: error marshalling create response: %w", err)
 	}
 
+	respMetadata := responseMetadata(req.Operation, requestID.get())
+	respMetadata[metadataKey] = key
 	return &bindings.InvokeResponse{
-		Data: jsonResponse,
+		Data:     jsonResponse,
+		Metadata: respMetadata,
 	}, nil
 }
 
@@ -240,7 +406,49 @@ func (s *AWSS3) presign(ctx context.Context, req *bindings.InvokeRequest) (*bind
 	}
 
 	return &bindings.InvokeResponse{
-		Data: jsonResponse,
+		Data:     jsonResponse,
+		Metadata: responseMetadata(req.Operation, ""),
+	}, nil
+}
+
+func (s *AWSS3) presignUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	metadata, err := s.metadata.mergeWithRequestMetadata(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: error merging metadata: %w", err)
+	}
+
+	key := req.Metadata[metadataKey]
+	if key == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataKey)
+	}
+
+	if metadata.PresignTTL == "" {
+		return nil, fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataPresignTTL)
+	}
+
+	d, err := time.ParseDuration(metadata.PresignTTL)
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: cannot parse duration %s: %w", metadata.PresignTTL, err)
+	}
+	objReq, _ := s.s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: ptr.Of(metadata.Bucket),
+		Key:    ptr.Of(key),
+	})
+	url, err := objReq.Presign(d)
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: failed to presign URL: %w", err)
+	}
+
+	jsonResponse, err := json.Marshal(presignResponse{
+		PresignURL: url,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 binding error: error marshalling presign response: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     jsonResponse,
+		Metadata: responseMetadata(req.Operation, ""),
 	}, nil
 }
 
@@ -275,12 +483,14 @@ func (s *AWSS3) get(ctx context.Context, req *bindings.InvokeRequest) (*bindings
 
 	buff := &aws.WriteAtBuffer{}
 
+	var requestID requestIDCollector
 	_, err = s.downloader.DownloadWithContext(ctx,
 		buff,
 		&s3.GetObjectInput{
 			Bucket: ptr.Of(s.metadata.Bucket),
 			Key:    ptr.Of(key),
 		},
+		s3manager.WithDownloaderRequestOptions(requestID.option()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("s3 binding error: error downloading S3 object: %w", err)
@@ -296,7 +506,7 @@ func (s *AWSS3) get(ctx context.Context, req *bindings.InvokeRequest) (*bindings
 
 	return &bindings.InvokeResponse{
 		Data:     data,
-		Metadata: nil,
+		Metadata: responseMetadata(req.Operation, requestID.get()),
 	}, nil
 }
 
@@ -306,18 +516,22 @@ func (s *AWSS3) delete(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		return nil, fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataKey)
 	}
 
+	var requestID requestIDCollector
 	_, err := s.s3Client.DeleteObjectWithContext(
 		ctx,
 		&s3.DeleteObjectInput{
 			Bucket: ptr.Of(s.metadata.Bucket),
 			Key:    ptr.Of(key),
 		},
+		requestID.option(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("s3 binding error: delete operation failed: %w", err)
 	}
 
-	return nil, nil
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, requestID.get()),
+	}, nil
 }
 
 func (s *AWSS3) list(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
@@ -331,13 +545,14 @@ func (s *AWSS3) list(ctx context.Context, req *bindings.InvokeRequest) (*binding
 		payload.MaxResults = defaultMaxResults
 	}
 
+	var requestID requestIDCollector
 	result, err := s.s3Client.ListObjectsWithContext(ctx, &s3.ListObjectsInput{
 		Bucket:    ptr.Of(s.metadata.Bucket),
 		MaxKeys:   ptr.Of(int64(payload.MaxResults)),
 		Marker:    ptr.Of(payload.Marker),
 		Prefix:    ptr.Of(payload.Prefix),
 		Delimiter: ptr.Of(payload.Delimiter),
-	})
+	}, requestID.option())
 	if err != nil {
 		return nil, fmt.Errorf("s3 binding error: list operation failed: %w", err)
 	}
@@ -347,11 +562,65 @@ func (s *AWSS3) list(ctx context.Context, req *bindings.InvokeRequest) (*binding
 		return nil, fmt.Errorf("s3 binding error: list operation: cannot marshal list to json: %w", err)
 	}
 
+	respMetadata := responseMetadata(req.Operation, requestID.get())
+	respMetadata[bindings.ResponseMetadataNextCursor] = nextMarker(result)
 	return &bindings.InvokeResponse{
-		Data: jsonResponse,
+		Data:     jsonResponse,
+		Metadata: respMetadata,
 	}, nil
 }
 
+// nextMarker returns the marker to list the next objects, or an empty string if the list is complete.
+// S3 only returns NextMarker when a delimiter is set: otherwise, the listing continues after the last key.
+func nextMarker(result *s3.ListObjectsOutput) string {
+	if !aws.BoolValue(result.IsTruncated) {
+		return ""
+	}
+	if result.NextMarker != nil {
+		return *result.NextMarker
+	}
+	if len(result.Contents) > 0 {
+		return aws.StringValue(result.Contents[len(result.Contents)-1].Key)
+	}
+	return ""
+}
+
+func responseMetadata(operation bindings.OperationKind, requestID string) map[string]string {
+	m := map[string]string{
+		bindings.ResponseMetadataOperation: string(operation),
+	}
+	if requestID != "" {
+		m[bindings.ResponseMetadataRequestID] = requestID
+	}
+	return m
+}
+
+// requestIDCollector records the ID of the requests sent to S3.
+// Uploads and downloads can be split into multiple requests: the ID of the last one is kept.
+type requestIDCollector struct {
+	lock sync.Mutex
+	id   string
+}
+
+func (c *requestIDCollector) option() request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			if r.RequestID == "" {
+				return
+			}
+			c.lock.Lock()
+			c.id = r.RequestID
+			c.lock.Unlock()
+		})
+	}
+}
+
+func (c *requestIDCollector) get() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.id
+}
+
 func (s *AWSS3) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	switch req.Operation {
 	case bindings.CreateOperation:
@@ -364,6 +633,28 @@ func (s *AWSS3) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		return s.list(ctx, req)
 	case presignOperation:
 		return s.presign(ctx, req)
+	case presignUploadOperation:
+		return s.presignUpload(ctx, req)
+	case copyOperation, moveOperation:
+		return s.copy(ctx, req)
+	case setTierOperation:
+		return s.setTier(ctx, req)
+	case setLegalHoldOperation:
+		return s.setLegalHold(ctx, req)
+	case setRetentionOperation:
+		return s.setRetention(ctx, req)
+	case restoreOperation:
+		return s.restore(ctx, req)
+	case restoreStatusOperation:
+		return s.restoreStatus(ctx, req)
+	case createRestoreJobOperation:
+		return s.createRestoreJob(ctx, req)
+	case getRestoreJobOperation:
+		return s.getRestoreJob(ctx, req)
+	case listRestoreJobsOperation:
+		return s.listRestoreJobs(ctx, req)
+	case cancelRestoreJobOperation:
+		return s.cancelRestoreJob(ctx, req)
 	default:
 		return nil, fmt.Errorf("s3 binding error: unsupported operation %s", req.Operation)
 	}
@@ -379,7 +670,17 @@ func (s *AWSS3) parseMetadata(md bindings.Metadata) (*s3Metadata, error) {
 }
 
 func (s *AWSS3) getSession(metadata *s3Metadata) (*session.Session, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -15,12 +15,21 @@ package s3
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 func TestParseMetadata(t *testing.T) {
@@ -188,3 +197,309 @@ func TestDeleteOption(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestListResponseMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bucket", r.URL.Path)
+		w.Header().Set("x-amz-request-id", "req-1")
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+	<Name>bucket</Name>
+	<IsTruncated>true</IsTruncated>
+	<Contents><Key>a.txt</Key></Contents>
+	<Contents><Key>b.txt</Key></Contents>
+</ListBucketResult>`))
+	}))
+	defer srv.Close()
+
+	s3 := NewAWSS3(logger.NewLogger("s3")).(*AWSS3)
+	err := s3.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"bucket":         "bucket",
+		"region":         "us-east-1",
+		"endpoint":       srv.URL,
+		"accessKey":      "key",
+		"secretKey":      "secret",
+		"forcePathStyle": "true",
+		"disableSSL":     "true",
+	}}})
+	require.NoError(t, err)
+
+	res, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.ListOperation,
+		Data:      []byte(`{"maxResults": 2}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "list", res.Metadata[bindings.ResponseMetadataOperation])
+	assert.Equal(t, "req-1", res.Metadata[bindings.ResponseMetadataRequestID])
+	assert.Equal(t, "b.txt", res.Metadata[bindings.ResponseMetadataNextCursor])
+}
+
+func TestNextMarker(t *testing.T) {
+	assert.Equal(t, "", nextMarker(&s3.ListObjectsOutput{IsTruncated: ptr.Of(false)}))
+	assert.Equal(t, "next", nextMarker(&s3.ListObjectsOutput{
+		IsTruncated: ptr.Of(true),
+		NextMarker:  ptr.Of("next"),
+		Contents:    []*s3.Object{{Key: ptr.Of("a")}},
+	}))
+	assert.Equal(t, "a", nextMarker(&s3.ListObjectsOutput{
+		IsTruncated: ptr.Of(true),
+		Contents:    []*s3.Object{{Key: ptr.Of("a")}},
+	}))
+}
+
+func TestLifecycleOperations(t *testing.T) {
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("x-amz-request-id", "req-1")
+		if r.Header.Get("x-amz-copy-source") != "" {
+			w.Header().Set("x-amz-version-id", "v2")
+			w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+		}
+	}))
+	defer srv.Close()
+
+	s3 := NewAWSS3(logger.NewLogger("s3")).(*AWSS3)
+	err := s3.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"bucket":         "bucket",
+		"region":         "us-east-1",
+		"endpoint":       srv.URL,
+		"accessKey":      "key",
+		"secretKey":      "secret",
+		"forcePathStyle": "true",
+		"disableSSL":     "true",
+	}}})
+	require.NoError(t, err)
+
+	t.Run("move", func(t *testing.T) {
+		requests = nil
+		res, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: moveOperation,
+			Metadata:  map[string]string{metadataKey: "dst/b.txt", metadataSourceKey: "src/a b.txt", metadataSourceBucket: "other"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"versionID":"v2"}`, string(res.Data))
+		assert.Equal(t, "req-1", res.Metadata[bindings.ResponseMetadataRequestID])
+		require.Len(t, requests, 2)
+		assert.Equal(t, http.MethodPut, requests[0].Method)
+		assert.Equal(t, "/bucket/dst/b.txt", requests[0].URL.Path)
+		assert.Equal(t, "other/src/a%20b.txt", requests[0].Header.Get("x-amz-copy-source"))
+		assert.Equal(t, http.MethodDelete, requests[1].Method)
+		assert.Equal(t, "/other/src/a b.txt", requests[1].URL.Path)
+	})
+
+	t.Run("copy requires the source", func(t *testing.T) {
+		_, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: copyOperation,
+			Metadata:  map[string]string{metadataKey: "b.txt"},
+		})
+		assert.ErrorContains(t, err, "sourceKey")
+	})
+
+	t.Run("setTier", func(t *testing.T) {
+		requests = nil
+		_, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setTierOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataStorageClass: "GLACIER"},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "bucket/a.txt", requests[0].Header.Get("x-amz-copy-source"))
+		assert.Equal(t, "GLACIER", requests[0].Header.Get("x-amz-storage-class"))
+		assert.Equal(t, "COPY", requests[0].Header.Get("x-amz-metadata-directive"))
+
+		_, err = s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setTierOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataStorageClass: "COLD"},
+		})
+		assert.ErrorContains(t, err, "invalid storage class")
+	})
+
+	t.Run("setLegalHold and setRetention", func(t *testing.T) {
+		requests = nil
+		_, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setLegalHoldOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataLegalHold: "true"},
+		})
+		require.NoError(t, err)
+		_, err = s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setRetentionOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataRetentionMode: "governance", metadataRetainUntil: "2030-01-01T00:00:00Z"},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		assert.Contains(t, requests[0].URL.RawQuery, "legal-hold")
+		assert.Contains(t, requests[1].URL.RawQuery, "retention")
+
+		_, err = s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setRetentionOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataRetentionMode: "GOVERNANCE", metadataRetainUntil: "tomorrow"},
+		})
+		assert.ErrorContains(t, err, "invalid metadata 'retainUntil'")
+	})
+
+	t.Run("presignUpload", func(t *testing.T) {
+		res, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: presignUploadOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataPresignTTL: "15m"},
+		})
+		require.NoError(t, err)
+		var presign presignResponse
+		require.NoError(t, json.Unmarshal(res.Data, &presign))
+		assert.Contains(t, presign.PresignURL, "/bucket/a.txt?")
+		assert.Contains(t, presign.PresignURL, "X-Amz-Signature=")
+	})
+}
+
+func TestArchiveOperations(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		w.Header().Set("x-amz-request-id", "req-1")
+		switch {
+		case r.URL.Path == "/bucket/restoring.txt" && r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`<Error><Code>RestoreAlreadyInProgress</Code><Message>in progress</Message></Error>`))
+		case r.URL.Path == "/bucket/restored.txt" && r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/bucket/") && r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/bucket/restored.txt" && r.Method == http.MethodHead:
+			w.Header().Set("x-amz-storage-class", "GLACIER")
+			w.Header().Set("x-amz-restore", `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`)
+		case r.URL.Path == "/bucket/archived.txt" && r.Method == http.MethodHead:
+			w.Header().Set("x-amz-storage-class", "DEEP_ARCHIVE")
+		case strings.HasPrefix(r.URL.Path, "/bucket/restore-manifests/") && r.Method == http.MethodPut:
+			w.Header().Set("ETag", `"manifest-etag"`)
+		case r.URL.Path == "/v20180820/jobs" && r.Method == http.MethodPost:
+			w.Write([]byte(`<CreateJobResult><JobId>job-1</JobId></CreateJobResult>`))
+		case r.URL.Path == "/v20180820/jobs/job-1" && r.Method == http.MethodGet:
+			w.Write([]byte(`<DescribeJobResult><Job><JobId>job-1</JobId><Status>Active</Status>
+<ProgressSummary><TotalNumberOfTasks>2</TotalNumberOfTasks><NumberOfTasksSucceeded>1</NumberOfTasksSucceeded></ProgressSummary>
+</Job></DescribeJobResult>`))
+		case r.URL.Path == "/v20180820/jobs" && r.Method == http.MethodGet:
+			w.Write([]byte(`<ListJobsResult><Jobs>
+<member><JobId>job-1</JobId><Operation>S3InitiateRestoreObject</Operation><Status>Active</Status></member>
+<member><JobId>job-2</JobId><Operation>S3PutObjectTagging</Operation><Status>Active</Status></member>
+</Jobs><NextToken>next</NextToken></ListJobsResult>`))
+		case r.URL.Path == "/v20180820/jobs/job-1/status" && r.Method == http.MethodPost:
+			w.Write([]byte(`<UpdateJobStatusResult><JobId>job-1</JobId><Status>Cancelling</Status></UpdateJobStatusResult>`))
+		}
+	}))
+	defer srv.Close()
+
+	s3 := NewAWSS3(logger.NewLogger("s3")).(*AWSS3)
+	err := s3.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"bucket":                 "bucket",
+		"region":                 "us-east-1",
+		"endpoint":               srv.URL,
+		"accessKey":              "key",
+		"secretKey":              "secret",
+		"forcePathStyle":         "true",
+		"disableSSL":             "true",
+		"accountId":              "123456789012",
+		"batchOperationsRoleArn": "arn:aws:iam::123456789012:role/batch",
+	}}})
+	require.NoError(t, err)
+
+	invoke := func(operation bindings.OperationKind, md map[string]string, data string) (*bindings.InvokeResponse, error) {
+		requests, bodies = nil, nil
+		return s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: operation,
+			Metadata:  md,
+			Data:      []byte(data),
+		})
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		res, err := invoke(restoreOperation, map[string]string{metadataKey: "a.txt", metadataRestoreDays: "3", metadataRestoreTier: "bulk"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"started"}`, string(res.Data))
+		assert.Equal(t, "req-1", res.Metadata[bindings.ResponseMetadataRequestID])
+		require.Len(t, requests, 1)
+		assert.Contains(t, requests[0].URL.RawQuery, "restore")
+		assert.Contains(t, bodies[0], "<Days>3</Days>")
+		assert.Contains(t, bodies[0], "<Tier>Bulk</Tier>")
+
+		res, err = invoke(restoreOperation, map[string]string{metadataKey: "restoring.txt"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"inProgress"}`, string(res.Data))
+
+		res, err = invoke(restoreOperation, map[string]string{metadataKey: "restored.txt"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"restored"}`, string(res.Data))
+
+		_, err = invoke(restoreOperation, map[string]string{metadataKey: "a.txt", metadataRestoreTier: "fast"}, "")
+		assert.ErrorContains(t, err, "invalid metadata 'restoreTier'")
+	})
+
+	t.Run("restoreStatus", func(t *testing.T) {
+		res, err := invoke(restoreStatusOperation, map[string]string{metadataKey: "restored.txt"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"restored","storageClass":"GLACIER","expiry":"2012-12-21T00:00:00Z"}`, string(res.Data))
+
+		res, err = invoke(restoreStatusOperation, map[string]string{metadataKey: "archived.txt"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"archived","storageClass":"DEEP_ARCHIVE"}`, string(res.Data))
+	})
+
+	t.Run("createRestoreJob", func(t *testing.T) {
+		res, err := invoke(createRestoreJobOperation, map[string]string{metadataRestoreDays: "2"}, `{"keys":["a.txt","dir/b c.txt"]}`)
+		require.NoError(t, err)
+		var created createRestoreJobResponse
+		require.NoError(t, json.Unmarshal(res.Data, &created))
+		assert.Equal(t, "job-1", created.JobID)
+		assert.True(t, strings.HasPrefix(created.ManifestKey, "restore-manifests/"))
+
+		require.Len(t, requests, 2)
+		assert.Equal(t, "bucket,a.txt\nbucket,dir%2Fb%20c.txt\n", bodies[0])
+		assert.Equal(t, "123456789012", requests[1].Header.Get("x-amz-account-id"))
+		assert.Contains(t, bodies[1], "<ObjectArn>arn:aws:s3:::bucket/"+created.ManifestKey+"</ObjectArn>")
+		assert.Contains(t, bodies[1], "<ETag>manifest-etag</ETag>")
+		assert.Contains(t, bodies[1], "<ExpirationInDays>2</ExpirationInDays>")
+		assert.Contains(t, bodies[1], "<GlacierJobTier>STANDARD</GlacierJobTier>")
+
+		_, err = invoke(createRestoreJobOperation, nil, `{}`)
+		assert.ErrorContains(t, err, "exactly one of")
+	})
+
+	t.Run("getRestoreJob", func(t *testing.T) {
+		res, err := invoke(getRestoreJobOperation, map[string]string{metadataJobID: "job-1"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jobID":"job-1","status":"Active","totalTasks":2,"succeededTasks":1,"failedTasks":0}`, string(res.Data))
+
+		_, err = invoke(getRestoreJobOperation, nil, "")
+		assert.ErrorContains(t, err, "jobID")
+	})
+
+	t.Run("listRestoreJobs", func(t *testing.T) {
+		res, err := invoke(listRestoreJobsOperation, nil, `{"statuses":["Active"]}`)
+		require.NoError(t, err)
+		var list listRestoreJobsResponse
+		require.NoError(t, json.Unmarshal(res.Data, &list))
+		require.Len(t, list.Jobs, 1)
+		assert.Equal(t, "job-1", list.Jobs[0].JobID)
+		assert.Equal(t, "next", res.Metadata[bindings.ResponseMetadataNextCursor])
+		assert.Equal(t, "Active", requests[0].URL.Query().Get("jobStatuses"))
+	})
+
+	t.Run("cancelRestoreJob", func(t *testing.T) {
+		res, err := invoke(cancelRestoreJobOperation, map[string]string{metadataJobID: "job-1"}, "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jobID":"job-1","status":"Cancelling","totalTasks":0,"succeededTasks":0,"failedTasks":0}`, string(res.Data))
+		assert.Equal(t, "Cancelled", requests[0].URL.Query().Get("requestedJobStatus"))
+	})
+}
+
+func TestParseRestoreHeader(t *testing.T) {
+	status, expiry := parseRestoreHeader(ptr.Of(`ongoing-request="true"`))
+	assert.Equal(t, "inProgress", status)
+	assert.Nil(t, expiry)
+
+	status, _ = parseRestoreHeader(nil)
+	assert.Equal(t, "", status)
+}
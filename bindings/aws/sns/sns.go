@@ -34,12 +34,16 @@ type AWSSNS struct {
 }
 
 type snsMetadata struct {
-	TopicArn     string `json:"topicArn"`
-	Region       string `json:"region"`
-	Endpoint     string `json:"endpoint"`
-	AccessKey    string `json:"accessKey"`
-	SecretKey    string `json:"secretKey"`
-	SessionToken string `json:"sessionToken"`
+	TopicArn             string `json:"topicArn"`
+	Region               string `json:"region"`
+	Endpoint             string `json:"endpoint"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
 }
 
 type dataPayload struct {
@@ -84,7 +88,17 @@ func (a *AWSSNS) parseMetadata(metadata bindings.Metadata) (*snsMetadata, error)
 }
 
 func (a *AWSSNS) getClient(metadata *snsMetadata) (*sns.SNS, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
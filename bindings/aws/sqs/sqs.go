@@ -35,12 +35,16 @@ type AWSSQS struct {
 }
 
 type sqsMetadata struct {
-	QueueName    string `json:"queueName"`
-	Region       string `json:"region"`
-	Endpoint     string `json:"endpoint"`
-	AccessKey    string `json:"accessKey"`
-	SecretKey    string `json:"secretKey"`
-	SessionToken string `json:"sessionToken"`
+	QueueName            string `json:"queueName"`
+	Region               string `json:"region"`
+	Endpoint             string `json:"endpoint"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
 }
 
 // NewAWSSQS returns a new AWS SQS instance.
@@ -149,7 +153,17 @@ func (a *AWSSQS) parseSQSMetadata(metadata bindings.Metadata) (*sqsMetadata, err
 }
 
 func (a *AWSSQS) getClient(metadata *sqsMetadata) (*sqs.SQS, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +49,19 @@ const (
 	// Defines the delete snapshots option for the delete operation.
 	// See: https://docs.microsoft.com/en-us/rest/api/storageservices/delete-blob#request-headers
 	metadataKeyDeleteSnapshots = "deleteSnapshots"
+	// Used to reference the source blob of the copy and move operations, relative to the container.
+	metadataKeySourceBlobName = "sourceBlobName"
+	// The access tier to set on the blob in the setTier operation.
+	// See: https://learn.microsoft.com/en-us/rest/api/storageservices/set-blob-tier#request-headers
+	metadataKeyAccessTier = "accessTier"
+	// Defines if the legal hold on the blob is set or cleared in the setLegalHold operation.
+	metadataKeyLegalHold = "legalHold"
+	// The RFC3339 time until which the blob is retained, and the mode of the immutability policy ("Locked" or "Unlocked").
+	// See: https://learn.microsoft.com/en-us/rest/api/storageservices/set-blob-immutability-policy#request-headers
+	metadataKeyRetainUntil   = "retainUntil"
+	metadataKeyRetentionMode = "retentionMode"
+	// The time for which the presigned upload URL is valid, as a Go duration.
+	metadataKeyPresignTTL = "presignTTL"
 	// Specifies the maximum number of blobs to return, including all BlobPrefix elements. If the request does not
 	// specify maxresults the server will return up to 5,000 items.
 	// See: https://docs.microsoft.com/en-us/rest/api/storageservices/list-blobs#uri-parameters
@@ -56,7 +69,19 @@ const (
 	endpointKey       = "endpoint"
 )
 
-var ErrMissingBlobName = errors.New("blobName is a required attribute")
+const (
+	copyOperation          bindings.OperationKind = "copy"
+	moveOperation          bindings.OperationKind = "move"
+	setTierOperation       bindings.OperationKind = "setTier"
+	setLegalHoldOperation  bindings.OperationKind = "setLegalHold"
+	setRetentionOperation  bindings.OperationKind = "setRetention"
+	presignUploadOperation bindings.OperationKind = "presignUpload"
+)
+
+var (
+	ErrMissingBlobName       = errors.New("blobName is a required attribute")
+	ErrMissingSourceBlobName = errors.New("sourceBlobName is a required attribute")
+)
 
 // AzureBlobStorage allows saving blobs to an Azure Blob Storage account.
 type AzureBlobStorage struct {
@@ -107,6 +132,79 @@ func (a *AzureBlobStorage) Operations() []bindings.OperationKind {
 		bindings.GetOperation,
 		bindings.DeleteOperation,
 		bindings.ListOperation,
+		copyOperation,
+		moveOperation,
+		setTierOperation,
+		setLegalHoldOperation,
+		setRetentionOperation,
+		presignUploadOperation,
+	}
+}
+
+// OperationsMetadata returns the description of the operations of the binding.
+func (a *AzureBlobStorage) OperationsMetadata() []bindings.OperationMetadata {
+	common := []string{bindings.ResponseMetadataOperation, bindings.ResponseMetadataRequestID}
+	return []bindings.OperationMetadata{
+		{
+			Operation:        bindings.CreateOperation,
+			Description:      "Upload a blob; the other request metadata are set as the blob metadata or HTTP headers",
+			RequestMetadata:  []string{metadataKeyBlobName},
+			ResponseMetadata: append([]string{metadataKeyBlobName}, common...),
+		},
+		{
+			Operation:        bindings.GetOperation,
+			Description:      "Download a blob",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeyIncludeMetadata},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        bindings.DeleteOperation,
+			Description:      "Delete a blob",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeyDeleteSnapshots},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        bindings.ListOperation,
+			Description:      "List the blobs; pass the cursor as the marker of the next request",
+			ResponseMetadata: append([]string{metadataKeyMarker, metadataKeyNumber, bindings.ResponseMetadataNextCursor}, common...),
+			Paginated:        true,
+		},
+		{
+			Operation:        copyOperation,
+			Description:      "Copy the blob with sourceBlobName to blobName",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeySourceBlobName},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        moveOperation,
+			Description:      "Copy the blob with sourceBlobName to blobName, then delete the source blob",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeySourceBlobName},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        setTierOperation,
+			Description:      "Set the access tier of a blob",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeyAccessTier},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        setLegalHoldOperation,
+			Description:      "Set or clear the legal hold of a blob",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeyLegalHold},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        setRetentionOperation,
+			Description:      "Set the immutability policy of a blob",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeyRetainUntil, metadataKeyRetentionMode},
+			ResponseMetadata: common,
+		},
+		{
+			Operation:        presignUploadOperation,
+			Description:      "Generate a SAS URL to upload a blob; requires the account key",
+			RequestMetadata:  []string{metadataKeyBlobName, metadataKeyPresignTTL},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
 	}
 }
 
@@ -148,7 +246,7 @@ func (a *AzureBlobStorage) create(ctx context.Context, req *bindings.InvokeReque
 	}
 
 	blockBlobClient := a.containerClient.NewBlockBlobClient(blobName)
-	_, err = blockBlobClient.UploadBuffer(ctx, req.Data, &uploadOptions)
+	uploadResp, err := blockBlobClient.UploadBuffer(ctx, req.Data, &uploadOptions)
 
 	if err != nil {
 		return nil, fmt.Errorf("error uploading az blob: %w", err)
@@ -162,9 +260,8 @@ func (a *AzureBlobStorage) create(ctx context.Context, req *bindings.InvokeReque
 		return nil, fmt.Errorf("error marshalling create response for azure blob: %w", err)
 	}
 
-	createResponseMetadata := map[string]string{
-		"blobName": blobName,
-	}
+	createResponseMetadata := responseMetadata(req.Operation, uploadResp.RequestID)
+	createResponseMetadata["blobName"] = blobName
 
 	return &bindings.InvokeResponse{
 		Data:     b,
@@ -195,7 +292,7 @@ func (a *AzureBlobStorage) get(ctx context.Context, req *bindings.InvokeRequest)
 		return nil, fmt.Errorf("error reading az blob: %w", err)
 	}
 
-	var metadata map[string]string
+	metadata := map[string]string{}
 	fetchMetadata, err := req.GetMetadataAsBool(metadataKeyIncludeMetadata)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing metadata: %w", err)
@@ -212,6 +309,12 @@ func (a *AzureBlobStorage) get(ctx context.Context, req *bindings.InvokeRequest)
 		}
 
 		metadata = props.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+	}
+	for k, v := range responseMetadata(req.Operation, blobDownloadResponse.RequestID) {
+		metadata[k] = v
 	}
 
 	return &bindings.InvokeResponse{
@@ -242,9 +345,14 @@ func (a *AzureBlobStorage) delete(ctx context.Context, req *bindings.InvokeReque
 	}
 
 	blockBlobClient = a.containerClient.NewBlockBlobClient(val)
-	_, err := blockBlobClient.Delete(ctx, &deleteOptions)
+	deleteResp, err := blockBlobClient.Delete(ctx, &deleteOptions)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil, err
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, deleteResp.RequestID),
+	}, nil
 }
 
 func (a *AzureBlobStorage) list(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
@@ -285,7 +393,7 @@ func (a *AzureBlobStorage) list(ctx context.Context, req *bindings.InvokeRequest
 	}
 	options.Marker = &initialMarker
 
-	metadata := map[string]string{}
+	metadata := responseMetadata(req.Operation, nil)
 	blobs := []*container.BlobItem{}
 	pager := a.containerClient.NewListBlobsFlatPager(&options)
 
@@ -302,6 +410,13 @@ func (a *AzureBlobStorage) list(ctx context.Context, req *bindings.InvokeRequest
 		if resp.Marker != nil {
 			metadata[metadataKeyMarker] = *resp.Marker
 		}
+		metadata[bindings.ResponseMetadataNextCursor] = ""
+		if resp.NextMarker != nil {
+			metadata[bindings.ResponseMetadataNextCursor] = *resp.NextMarker
+		}
+		if resp.RequestID != nil {
+			metadata[bindings.ResponseMetadataRequestID] = *resp.RequestID
+		}
 
 		if *options.MaxResults-maxResults > 0 {
 			*options.MaxResults -= maxResults
@@ -331,11 +446,31 @@ func (a *AzureBlobStorage) Invoke(ctx context.Context, req *bindings.InvokeReque
 		return a.delete(ctx, req)
 	case bindings.ListOperation:
 		return a.list(ctx, req)
+	case copyOperation, moveOperation:
+		return a.copy(ctx, req)
+	case setTierOperation:
+		return a.setTier(ctx, req)
+	case setLegalHoldOperation:
+		return a.setLegalHold(ctx, req)
+	case setRetentionOperation:
+		return a.setRetention(ctx, req)
+	case presignUploadOperation:
+		return a.presignUpload(ctx, req)
 	default:
 		return nil, fmt.Errorf("unsupported operation %s", req.Operation)
 	}
 }
 
+func responseMetadata(operation bindings.OperationKind, requestID *string) map[string]string {
+	m := map[string]string{
+		bindings.ResponseMetadataOperation: string(operation),
+	}
+	if requestID != nil {
+		m[bindings.ResponseMetadataRequestID] = *requestID
+	}
+	return m
+}
+
 func (a *AzureBlobStorage) isValidDeleteSnapshotsOptionType(accessType azblob.DeleteSnapshotsOptionType) bool {
 	validTypes := azblob.PossibleDeleteSnapshotsOptionTypeValues()
 	for _, item := range validTypes {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/dapr/components-contrib/bindings"
 	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
 )
 
 func TestGetOption(t *testing.T) {
@@ -56,3 +57,14 @@ func TestDeleteOption(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestResponseMetadata(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		bindings.ResponseMetadataOperation: "get",
+	}, responseMetadata(bindings.GetOperation, nil))
+
+	assert.Equal(t, map[string]string{
+		bindings.ResponseMetadataOperation: "delete",
+		bindings.ResponseMetadataRequestID: "req-1",
+	}, responseMetadata(bindings.DeleteOperation, ptr.Of("req-1")))
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/ptr"
+)
+
+// copyPollInterval is the interval between the checks of the status of a pending copy.
+var copyPollInterval = time.Second
+
+type presignResponse struct {
+	PresignURL string `json:"presignURL"`
+}
+
+// copy copies the blob with the sourceBlobName to the blobName, and deletes the source blob when moving it.
+// Copies within a storage account are usually synchronous; otherwise the status of the copy is polled until it completes.
+func (a *AzureBlobStorage) copy(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+	sourceBlobName := req.Metadata[metadataKeySourceBlobName]
+	if sourceBlobName == "" {
+		return nil, ErrMissingSourceBlobName
+	}
+
+	srcClient := a.containerClient.NewBlobClient(sourceBlobName)
+	dstClient := a.containerClient.NewBlobClient(blobName)
+	copyResp, err := dstClient.StartCopyFromURL(ctx, srcClient.URL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the copy of blob %s: %w", sourceBlobName, err)
+	}
+
+	status := copyResp.CopyStatus
+	for status != nil && *status == blob.CopyStatusTypePending {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(copyPollInterval):
+		}
+		props, err := dstClient.GetProperties(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the copy status of blob %s: %w", blobName, err)
+		}
+		status = props.CopyStatus
+	}
+	if status != nil && *status != blob.CopyStatusTypeSuccess {
+		return nil, fmt.Errorf("copy of blob %s to %s did not succeed: %s", sourceBlobName, blobName, *status)
+	}
+
+	if req.Operation == moveOperation {
+		_, err = srcClient.Delete(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("blob copied to %s, but deleting the source blob failed: %w", blobName, err)
+		}
+	}
+
+	b, err := json.Marshal(createResponse{
+		BlobURL:  dstClient.URL(),
+		BlobName: blobName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling %s response: %w", req.Operation, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     b,
+		Metadata: responseMetadata(req.Operation, copyResp.RequestID),
+	}, nil
+}
+
+func (a *AzureBlobStorage) setTier(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+
+	tier := blob.AccessTier(req.Metadata[metadataKeyAccessTier])
+	if !isValidAccessTier(tier) {
+		return nil, fmt.Errorf("invalid access tier: %s; allowed: %s", tier, blob.PossibleAccessTierValues())
+	}
+
+	resp, err := a.containerClient.NewBlobClient(blobName).SetTier(ctx, tier, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error setting the access tier of blob %s: %w", blobName, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, resp.RequestID),
+	}, nil
+}
+
+func (a *AzureBlobStorage) setLegalHold(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+
+	legalHold, err := strconv.ParseBool(req.Metadata[metadataKeyLegalHold])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s metadata: %w", metadataKeyLegalHold, err)
+	}
+
+	resp, err := a.containerClient.NewBlobClient(blobName).SetLegalHold(ctx, legalHold, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error setting the legal hold of blob %s: %w", blobName, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, resp.RequestID),
+	}, nil
+}
+
+func (a *AzureBlobStorage) setRetention(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+
+	retainUntil, err := time.Parse(time.RFC3339, req.Metadata[metadataKeyRetainUntil])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s metadata, expected an RFC3339 timestamp: %w", metadataKeyRetainUntil, err)
+	}
+
+	mode := blob.ImmutabilityPolicySettingUnlocked
+	if val := req.Metadata[metadataKeyRetentionMode]; val != "" {
+		mode = blob.ImmutabilityPolicySetting(val)
+		if mode != blob.ImmutabilityPolicySettingLocked && mode != blob.ImmutabilityPolicySettingUnlocked {
+			return nil, fmt.Errorf("invalid retention mode: %s; allowed: %s", mode,
+				[]blob.ImmutabilityPolicySetting{blob.ImmutabilityPolicySettingLocked, blob.ImmutabilityPolicySettingUnlocked})
+		}
+	}
+
+	resp, err := a.containerClient.NewBlobClient(blobName).SetImmutabilityPolicy(ctx, retainUntil, &blob.SetImmutabilityPolicyOptions{
+		Mode: ptr.Of(mode),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error setting the immutability policy of blob %s: %w", blobName, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: responseMetadata(req.Operation, resp.RequestID),
+	}, nil
+}
+
+// presignUpload returns a SAS URL that allows uploading the blob; the SAS is signed with the account key.
+func (a *AzureBlobStorage) presignUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+
+	ttl, err := time.ParseDuration(req.Metadata[metadataKeyPresignTTL])
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("invalid %s metadata, expected a positive duration: %s", metadataKeyPresignTTL, req.Metadata[metadataKeyPresignTTL])
+	}
+
+	now := time.Now()
+	url, err := a.containerClient.NewBlobClient(blobName).GetSASURL(sas.BlobPermissions{Create: true, Write: true}, now, now.Add(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("error generating the presigned upload URL: %w", err)
+	}
+
+	b, err := json.Marshal(presignResponse{PresignURL: url})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling %s response: %w", req.Operation, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     b,
+		Metadata: responseMetadata(req.Operation, nil),
+	}, nil
+}
+
+func isValidAccessTier(tier blob.AccessTier) bool {
+	for _, item := range blob.PossibleAccessTierValues() {
+		if item == tier {
+			return true
+		}
+	}
+
+	return false
+}
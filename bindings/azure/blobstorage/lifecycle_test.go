@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+func TestLifecycleOperations(t *testing.T) {
+	oldInterval := copyPollInterval
+	copyPollInterval = time.Millisecond
+	defer func() { copyPollInterval = oldInterval }()
+
+	var (
+		lock     sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		comp := r.URL.Query().Get("comp")
+		switch {
+		case r.Header.Get("x-ms-copy-source") != "":
+			requests = append(requests, "copy "+r.URL.Path+" from "+r.Header.Get("x-ms-copy-source"))
+			w.Header().Set("x-ms-copy-status", "pending")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodHead:
+			requests = append(requests, "properties "+r.URL.Path)
+			w.Header().Set("x-ms-copy-status", "success")
+		case r.Method == http.MethodDelete:
+			requests = append(requests, "delete "+r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		case comp == "tier":
+			requests = append(requests, "tier "+r.URL.Path+" "+r.Header.Get("x-ms-access-tier"))
+		case comp == "legalhold":
+			requests = append(requests, "legalhold "+r.URL.Path+" "+r.Header.Get("x-ms-legal-hold"))
+		case comp == "immutabilityPolicies":
+			requests = append(requests, "immutability "+r.URL.Path+" "+r.Header.Get("x-ms-immutability-policy-mode"))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	containerClient, err := container.NewClientWithNoCredential(srv.URL+"/container", nil)
+	require.NoError(t, err)
+	blobStorage := &AzureBlobStorage{containerClient: containerClient, logger: logger.NewLogger("test")}
+
+	invoke := func(op bindings.OperationKind, md map[string]string) (*bindings.InvokeResponse, []string, error) {
+		lock.Lock()
+		requests = nil
+		lock.Unlock()
+		res, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{Operation: op, Metadata: md})
+		lock.Lock()
+		defer lock.Unlock()
+		return res, requests, err
+	}
+
+	t.Run("move waits for the copy to complete", func(t *testing.T) {
+		res, reqs, err := invoke(moveOperation, map[string]string{"blobName": "b.txt", "sourceBlobName": "a.txt"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"copy /container/b.txt from " + srv.URL + "/container/a.txt",
+			"properties /container/b.txt",
+			"delete /container/a.txt",
+		}, reqs)
+		var created createResponse
+		require.NoError(t, json.Unmarshal(res.Data, &created))
+		assert.Equal(t, "b.txt", created.BlobName)
+		assert.Equal(t, string(moveOperation), res.Metadata[bindings.ResponseMetadataOperation])
+	})
+
+	t.Run("copy requires sourceBlobName", func(t *testing.T) {
+		_, _, err := invoke(copyOperation, map[string]string{"blobName": "b.txt"})
+		assert.ErrorIs(t, err, ErrMissingSourceBlobName)
+	})
+
+	t.Run("setTier", func(t *testing.T) {
+		_, reqs, err := invoke(setTierOperation, map[string]string{"blobName": "a.txt", "accessTier": "Cool"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tier /container/a.txt Cool"}, reqs)
+
+		_, _, err = invoke(setTierOperation, map[string]string{"blobName": "a.txt", "accessTier": "Frozen"})
+		assert.ErrorContains(t, err, "invalid access tier")
+	})
+
+	t.Run("setLegalHold", func(t *testing.T) {
+		_, reqs, err := invoke(setLegalHoldOperation, map[string]string{"blobName": "a.txt", "legalHold": "true"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"legalhold /container/a.txt true"}, reqs)
+	})
+
+	t.Run("setRetention", func(t *testing.T) {
+		_, reqs, err := invoke(setRetentionOperation, map[string]string{"blobName": "a.txt", "retainUntil": "2030-01-01T00:00:00Z"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"immutability /container/a.txt Unlocked"}, reqs)
+
+		_, _, err = invoke(setRetentionOperation, map[string]string{"blobName": "a.txt", "retainUntil": "2030-01-01T00:00:00Z", "retentionMode": "Mutable"})
+		assert.ErrorContains(t, err, "invalid retention mode")
+
+		_, _, err = invoke(setRetentionOperation, map[string]string{"blobName": "a.txt", "retainUntil": "tomorrow"})
+		assert.ErrorContains(t, err, "retainUntil")
+	})
+}
+
+func TestPresignUpload(t *testing.T) {
+	cred, err := container.NewSharedKeyCredential("account", base64.StdEncoding.EncodeToString([]byte("secret")))
+	require.NoError(t, err)
+	containerClient, err := container.NewClientWithSharedKeyCredential("https://account.blob.core.windows.net/container", cred, nil)
+	require.NoError(t, err)
+	blobStorage := &AzureBlobStorage{containerClient: containerClient, logger: logger.NewLogger("test")}
+
+	res, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: presignUploadOperation,
+		Metadata:  map[string]string{"blobName": "a.txt", "presignTTL": "15m"},
+	})
+	require.NoError(t, err)
+	var presign presignResponse
+	require.NoError(t, json.Unmarshal(res.Data, &presign))
+	assert.Contains(t, presign.PresignURL, "https://account.blob.core.windows.net/container/a.txt?")
+	assert.Contains(t, presign.PresignURL, "sp=cw")
+	assert.Contains(t, presign.PresignURL, "sig=")
+
+	_, err = blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: presignUploadOperation,
+		Metadata:  map[string]string{"blobName": "a.txt"},
+	})
+	assert.ErrorContains(t, err, "presignTTL")
+}
@@ -32,6 +32,10 @@ import (
 
 const (
 	defaultTTL = time.Minute * 10
+
+	// keys of the metadata of the messages read.
+	messageIDKey    = "MessageId"
+	dequeueCountKey = "DequeueCount"
 )
 
 type consumer struct {
@@ -146,8 +150,11 @@ func (d *AzureQueueHelper) Read(ctx context.Context, consumer *consumer) error {
 	}
 
 	_, err = consumer.callback(ctx, &bindings.ReadResponse{
-		Data:     data,
-		Metadata: map[string]string{},
+		Data: data,
+		Metadata: map[string]string{
+			messageIDKey:    res.Message(0).ID.String(),
+			dequeueCountKey: strconv.FormatInt(res.Message(0).DequeueCount, 10),
+		},
 	})
 	if err != nil {
 		return err
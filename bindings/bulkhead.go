@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/bulkhead"
+)
+
+// bulkheadOutputBinding limits the number of invocations of an output binding running at the same time.
+type bulkheadOutputBinding struct {
+	outputForwarder
+
+	bulkhead *bulkhead.Bulkhead
+}
+
+// NewBulkheadOutputBinding wraps an OutputBinding so that at most "maxConcurrentOperations" invocations run at the same time.
+// The other ones wait for a free slot for up to "maxConcurrentOperationsWait", then fail with bulkhead.ErrBulkheadFull.
+func NewBulkheadOutputBinding(inner OutputBinding) OutputBinding {
+	return exposeOptionalOutput(&bulkheadOutputBinding{outputForwarder: outputForwarder{inner}}, inner)
+}
+
+func (b *bulkheadOutputBinding) Init(metadata Metadata) error {
+	m, err := bulkhead.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("binding bulkhead error: %w", err)
+	}
+	b.bulkhead = bulkhead.New(m)
+
+	return b.OutputBinding.Init(metadata)
+}
+
+func (b *bulkheadOutputBinding) Invoke(ctx context.Context, req *InvokeRequest) (*InvokeResponse, error) {
+	return bulkhead.Run(ctx, b.bulkhead, func(ctx context.Context) (*InvokeResponse, error) {
+		return b.OutputBinding.Invoke(ctx, req)
+	})
+}
+
+// bulkheadInputBinding limits the number of events of an input binding handled at the same time.
+type bulkheadInputBinding struct {
+	inputForwarder
+
+	bulkhead *bulkhead.Bulkhead
+}
+
+// NewBulkheadInputBinding wraps an InputBinding so that at most "maxConcurrentOperations" events are handled at the same time.
+// The other ones wait for a free slot for up to "maxConcurrentOperationsWait", then fail with bulkhead.ErrBulkheadFull,
+// which the binding handles like any other handler error.
+func NewBulkheadInputBinding(inner InputBinding) InputBinding {
+	return exposeOptionalInput(&bulkheadInputBinding{inputForwarder: inputForwarder{inner}}, inner)
+}
+
+func (b *bulkheadInputBinding) Init(metadata Metadata) error {
+	m, err := bulkhead.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("binding bulkhead error: %w", err)
+	}
+	b.bulkhead = bulkhead.New(m)
+
+	return b.InputBinding.Init(metadata)
+}
+
+func (b *bulkheadInputBinding) Read(ctx context.Context, handler Handler) error {
+	return b.InputBinding.Read(ctx, func(ctx context.Context, resp *ReadResponse) ([]byte, error) {
+		return bulkhead.Run(ctx, b.bulkhead, func(ctx context.Context) ([]byte, error) {
+			return handler(ctx, resp)
+		})
+	})
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+// Size of the response body included in the errors.
+const maxErrorBodySize = 512
+
+// calDAV is the provider of CalDAV calendars, whose events are iCalendar resources of the calendar collection.
+type calDAV struct {
+	calendarURL *url.URL
+	username    string
+	password    string
+	token       string
+	client      *http.Client
+}
+
+func newCalDAV(m calendarMetadata) (*calDAV, error) {
+	if m.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	u, err := url.Parse(m.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", m.URL)
+	}
+	// Resources of the collection are resolved relative to it.
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &calDAV{
+		calendarURL: u,
+		username:    m.Username,
+		password:    m.Password,
+		token:       m.Token,
+		client:      &http.Client{Timeout: m.Timeout},
+	}, nil
+}
+
+func (c *calDAV) create(ctx context.Context, req *bindings.InvokeRequest, event *Event) (*Event, map[string]string, error) {
+	created := *event
+	if created.ID == "" {
+		created.ID = uuid.NewString()
+	}
+	return c.put(ctx, &created, "If-None-Match", "*")
+}
+
+func (c *calDAV) update(ctx context.Context, req *bindings.InvokeRequest, event *Event) (*Event, map[string]string, error) {
+	if etag := req.Metadata[etagKey]; etag != "" {
+		return c.put(ctx, event, "If-Match", etag)
+	}
+	return c.put(ctx, event, "", "")
+}
+
+// put writes the event, with the precondition header if any.
+func (c *calDAV) put(ctx context.Context, event *Event, header string, value string) (*Event, map[string]string, error) {
+	u := c.eventURL(event.ID)
+	req, err := c.newRequest(ctx, http.MethodPut, u, strings.NewReader(encodeEvent(event)))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body.Close()
+
+	res := *event
+	res.URL = u
+	md := map[string]string{}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		md[respETagKey] = etag
+	}
+	return &res, md, nil
+}
+
+func (c *calDAV) delete(ctx context.Context, req *bindings.InvokeRequest, id string) error {
+	r, err := c.newRequest(ctx, http.MethodDelete, c.eventURL(id), nil)
+	if err != nil {
+		return err
+	}
+	if etag := req.Metadata[etagKey]; etag != "" {
+		r.Header.Set("If-Match", etag)
+	}
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *calDAV) freeBusy(ctx context.Context, _ *bindings.InvokeRequest, fb *FreeBusyRequest) (*FreeBusyResult, error) {
+	calendars := fb.Calendars
+	if len(calendars) == 0 {
+		calendars = []string{""}
+	}
+	query := `<?xml version="1.0" encoding="utf-8"?>` +
+		`<C:free-busy-query xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+		`<C:time-range start="` + fb.TimeMin.UTC().Format(icalDateTimeLayout) + `" end="` + fb.TimeMax.UTC().Format(icalDateTimeLayout) + `"/>` +
+		`</C:free-busy-query>`
+
+	result := &FreeBusyResult{Calendars: make(map[string][]Period, len(calendars))}
+	for _, cal := range calendars {
+		ref, err := url.Parse(cal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar %q: %w", cal, err)
+		}
+		u := c.calendarURL.ResolveReference(ref).String()
+
+		req, err := c.newRequest(ctx, "REPORT", u, strings.NewReader(query))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+		req.Header.Set("Depth", "1")
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %w", u, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: failed to read the response: %w", u, err)
+		}
+		periods, err := parseFreeBusy(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %w", u, err)
+		}
+		if cal == "" {
+			cal = u
+		}
+		result.Calendars[cal] = periods
+	}
+	return result, nil
+}
+
+func (c *calDAV) eventURL(id string) string {
+	ref, _ := url.Parse("./" + url.PathEscape(id) + ".ics")
+	return c.calendarURL.ResolveReference(ref).String()
+}
+
+func (c *calDAV) newRequest(ctx context.Context, method string, u string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+// do sends the request, and returns an error for the unsuccessful status codes.
+func (c *calDAV) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return nil, errors.New("the event exists or has changed")
+		}
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// Values of provider.
+	providerGoogle = "google"
+	providerCalDAV = "caldav"
+
+	// keys from request's metadata.
+	eventIDKey    = "eventID"
+	calendarIDKey = "calendarID"
+	// Google Calendar: who to notify of the changes, "all", "externalOnly" or "none".
+	sendUpdatesKey = "sendUpdates"
+	// CalDAV: the entity tag of the event, to update or delete it only if it has not changed.
+	etagKey = "etag"
+
+	// keys in the response metadata.
+	respETagKey = "etag"
+
+	CreateOperation   bindings.OperationKind = "create"
+	UpdateOperation   bindings.OperationKind = "update"
+	DeleteOperation   bindings.OperationKind = "delete"
+	FreeBusyOperation bindings.OperationKind = "freeBusy"
+)
+
+// Calendar is an output binding managing the events of a Google Calendar or CalDAV calendar and querying free/busy times.
+type Calendar struct {
+	metadata calendarMetadata
+	provider provider
+	logger   logger.Logger
+}
+
+type calendarMetadata struct {
+	// Provider is "google" or "caldav".
+	Provider string        `mapstructure:"provider"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+
+	// Google Calendar: the default calendar, and the service account key fields, as in the other GCP components.
+	CalendarID          string `mapstructure:"calendarID"`
+	Type                string `mapstructure:"type"`
+	ProjectID           string `mapstructure:"project_id"`
+	PrivateKeyID        string `mapstructure:"private_key_id"`
+	PrivateKey          string `mapstructure:"private_key"`
+	ClientEmail         string `mapstructure:"client_email"`
+	ClientID            string `mapstructure:"client_id"`
+	AuthURI             string `mapstructure:"auth_uri"`
+	TokenURI            string `mapstructure:"token_uri"`
+	AuthProviderCertURL string `mapstructure:"auth_provider_x509_cert_url"`
+	ClientCertURL       string `mapstructure:"client_x509_cert_url"`
+
+	// CalDAV: the URL of the calendar collection, and the basic auth credentials or the bearer token.
+	URL      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"`
+}
+
+// Event is a calendar event. All-day events start and end at midnight, and their end is exclusive.
+type Event struct {
+	ID          string    `json:"id,omitempty"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"allDay,omitempty"`
+	// TimeZone is the IANA time zone of the start and end, for recurring and all-day events.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Attendees are the email addresses of the attendees.
+	Attendees []string `json:"attendees,omitempty"`
+	// URL is the link to the event in the calendar.
+	URL string `json:"url,omitempty"`
+}
+
+// FreeBusyRequest is the data of freeBusy requests.
+type FreeBusyRequest struct {
+	TimeMin time.Time `json:"timeMin"`
+	TimeMax time.Time `json:"timeMax"`
+	// Calendars are the IDs of the Google calendars, or the URLs of the CalDAV calendars relative to url.
+	// The calendar of the metadata if empty.
+	Calendars []string `json:"calendars,omitempty"`
+}
+
+// FreeBusyResult is the response of freeBusy requests: the busy periods by calendar.
+type FreeBusyResult struct {
+	Calendars map[string][]Period `json:"calendars"`
+}
+
+// Period is a time period.
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// provider is the API of a calendar service.
+type provider interface {
+	// create creates the event and returns it with its ID, and the response metadata.
+	create(ctx context.Context, req *bindings.InvokeRequest, event *Event) (*Event, map[string]string, error)
+	// update replaces the event with the ID.
+	update(ctx context.Context, req *bindings.InvokeRequest, event *Event) (*Event, map[string]string, error)
+	delete(ctx context.Context, req *bindings.InvokeRequest, id string) error
+	freeBusy(ctx context.Context, req *bindings.InvokeRequest, fb *FreeBusyRequest) (*FreeBusyResult, error)
+}
+
+// NewCalendar returns a new calendar binding.
+func NewCalendar(logger logger.Logger) bindings.OutputBinding {
+	return &Calendar{logger: logger}
+}
+
+// Init parses the metadata and creates the client of the provider.
+func (c *Calendar) Init(meta bindings.Metadata) error {
+	c.metadata = calendarMetadata{Timeout: defaultTimeout, CalendarID: "primary"}
+	err := metadata.DecodeMetadata(meta.Properties, &c.metadata)
+	if err != nil {
+		return fmt.Errorf("calendar binding error: %w", err)
+	}
+
+	switch c.metadata.Provider {
+	case providerGoogle:
+		c.provider, err = newGoogleCalendar(context.Background(), c.metadata)
+	case providerCalDAV:
+		c.provider, err = newCalDAV(c.metadata)
+	default:
+		return fmt.Errorf("calendar binding error: invalid provider %q: must be %s or %s", c.metadata.Provider, providerGoogle, providerCalDAV)
+	}
+	if err != nil {
+		return fmt.Errorf("calendar binding error: %w", err)
+	}
+	return nil
+}
+
+// Operations returns the operations supported by the calendar binding.
+func (c *Calendar) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{CreateOperation, UpdateOperation, DeleteOperation, FreeBusyOperation}
+}
+
+// Invoke runs the operation of the request.
+func (c *Calendar) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.metadata.Timeout)
+	defer cancel()
+
+	var (
+		res    interface{}
+		respMD map[string]string
+		err    error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case CreateOperation, UpdateOperation:
+		event, perr := parseEvent(req)
+		if perr != nil {
+			return nil, fmt.Errorf("calendar binding error: %w", perr)
+		}
+		if req.Operation == CreateOperation {
+			res, respMD, err = c.provider.create(ctx, req, event)
+		} else {
+			if event.ID == "" {
+				return nil, fmt.Errorf("calendar binding error: the %s metadata or the id of the event is required", eventIDKey)
+			}
+			res, respMD, err = c.provider.update(ctx, req, event)
+		}
+	case DeleteOperation:
+		id := req.Metadata[eventIDKey]
+		if id == "" {
+			return nil, fmt.Errorf("calendar binding error: the %s metadata is required", eventIDKey)
+		}
+		err = c.provider.delete(ctx, req, id)
+	case FreeBusyOperation:
+		var fb FreeBusyRequest
+		if err = json.Unmarshal(req.Data, &fb); err != nil {
+			return nil, fmt.Errorf("calendar binding error: invalid free/busy request: %w", err)
+		}
+		if fb.TimeMin.IsZero() || !fb.TimeMax.After(fb.TimeMin) {
+			return nil, errors.New("calendar binding error: timeMin and timeMax are required, and timeMax must be after timeMin")
+		}
+		res, err = c.provider.freeBusy(ctx, req, &fb)
+	default:
+		return nil, fmt.Errorf("calendar binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("calendar binding error: %s failed: %w", req.Operation, err)
+	}
+
+	resp := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}
+	for k, v := range respMD {
+		resp.Metadata[k] = v
+	}
+	if res != nil {
+		resp.Data, err = json.Marshal(res)
+		if err != nil {
+			return nil, fmt.Errorf("calendar binding error: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// parseEvent parses the event of create and update requests. The eventID metadata sets its ID.
+func parseEvent(req *bindings.InvokeRequest) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(req.Data, &event); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	if id := req.Metadata[eventIDKey]; id != "" {
+		event.ID = id
+	}
+	if event.Start.IsZero() || event.End.Before(event.Start) {
+		return nil, errors.New("the start of the event is required, and its end must not be before its start")
+	}
+	if event.TimeZone != "" {
+		if _, err := time.LoadLocation(event.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid timeZone %q: %w", event.TimeZone, err)
+		}
+	}
+	return &event, nil
+}
+
+// OperationsMetadata describes the operations of the calendar binding.
+func (c *Calendar) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        CreateOperation,
+			Description:      "Creates the event in the data, and returns it with its id.",
+			RequestMetadata:  []string{calendarIDKey, sendUpdatesKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respETagKey},
+		},
+		{
+			Operation:        UpdateOperation,
+			Description:      "Replaces the event with the eventID metadata, or the id in the data, with the event in the data.",
+			RequestMetadata:  []string{eventIDKey, calendarIDKey, sendUpdatesKey, etagKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respETagKey},
+		},
+		{
+			Operation:        DeleteOperation,
+			Description:      "Deletes the event with the eventID metadata.",
+			RequestMetadata:  []string{eventIDKey, calendarIDKey, sendUpdatesKey, etagKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        FreeBusyOperation,
+			Description:      "Returns the busy periods of the calendars between timeMin and timeMax.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
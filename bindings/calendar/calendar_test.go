@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInit(t *testing.T) {
+	c := NewCalendar(logger.NewLogger("test")).(*Calendar)
+	err := c.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"provider": "caldav",
+		"url":      "https://dav.example.com/calendars/alice/work",
+	}}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://dav.example.com/calendars/alice/work/e1.ics", c.provider.(*calDAV).eventURL("e1"))
+
+	err = c.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "caldav"}}})
+	require.ErrorContains(t, err, "url is required")
+
+	err = c.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "outlook"}}})
+	require.ErrorContains(t, err, "invalid provider")
+}
+
+func TestGoogleCalendar(t *testing.T) {
+	type request struct {
+		Method, Path, Query string
+		Body                map[string]interface{}
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+			require.NoError(t, json.Unmarshal(b, &req.Body))
+		}
+		requests = append(requests, req)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /calendars/team@example.com/events", "PUT /calendars/primary/events/ev1":
+			assert.Equal(t, "/calendars/primary/events/ev1" == r.URL.Path, r.Header.Get("If-Match") == `"etag1"`)
+			io.WriteString(w, `{"id":"ev1","etag":"\"etag2\"","summary":"Planning","htmlLink":"https://calendar.google.com/event?eid=ev1",
+				"start":{"dateTime":"2022-10-20T10:00:00+02:00","timeZone":"Europe/Paris"},"end":{"dateTime":"2022-10-20T11:00:00+02:00"},
+				"attendees":[{"email":"bob@example.com"}]}`)
+		case "DELETE /calendars/primary/events/ev1":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /freeBusy":
+			io.WriteString(w, `{"calendars":{"primary":{"busy":[{"start":"2022-10-20T08:00:00Z","end":"2022-10-20T09:00:00Z"}]}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":{"code":404,"message":"Not Found"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := newGoogleCalendar(context.Background(), calendarMetadata{CalendarID: "primary"},
+		option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	c := &Calendar{metadata: calendarMetadata{Timeout: defaultTimeout}, provider: p, logger: logger.NewLogger("test")}
+
+	event := `{"summary":"Planning","start":"2022-10-20T10:00:00+02:00","end":"2022-10-20T11:00:00+02:00","timeZone":"Europe/Paris","attendees":["bob@example.com"]}`
+	res, err := c.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: CreateOperation,
+		Data:      []byte(event),
+		Metadata:  map[string]string{calendarIDKey: "team@example.com", sendUpdatesKey: "all"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `"etag2"`, res.Metadata[respETagKey])
+	assert.Contains(t, requests[0].Query, "sendUpdates=all")
+	assert.Equal(t, map[string]interface{}{"dateTime": "2022-10-20T10:00:00+02:00", "timeZone": "Europe/Paris"}, requests[0].Body["start"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"email": "bob@example.com"}}, requests[0].Body["attendees"])
+	var created Event
+	require.NoError(t, json.Unmarshal(res.Data, &created))
+	assert.Equal(t, "ev1", created.ID)
+	assert.Equal(t, "https://calendar.google.com/event?eid=ev1", created.URL)
+	assert.True(t, created.Start.Equal(time.Date(2022, 10, 20, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"bob@example.com"}, created.Attendees)
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: UpdateOperation,
+		Data:      []byte(`{"summary":"Planning","start":"2022-10-21T00:00:00Z","end":"2022-10-22T00:00:00Z","allDay":true}`),
+		Metadata:  map[string]string{eventIDKey: "ev1", etagKey: `"etag1"`},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"date": "2022-10-21"}, requests[1].Body["start"])
+	assert.Equal(t, map[string]interface{}{"date": "2022-10-22"}, requests[1].Body["end"])
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: DeleteOperation, Metadata: map[string]string{eventIDKey: "ev1"}})
+	require.NoError(t, err)
+
+	res, err = c.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: FreeBusyOperation,
+		Data:      []byte(`{"timeMin":"2022-10-20T00:00:00Z","timeMax":"2022-10-21T00:00:00Z"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "primary"}}, requests[3].Body["items"])
+	assert.JSONEq(t, `{"calendars":{"primary":[{"start":"2022-10-20T08:00:00Z","end":"2022-10-20T09:00:00Z"}]}}`, string(res.Data))
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: DeleteOperation, Metadata: map[string]string{eventIDKey: "missing"}})
+	require.Error(t, err)
+}
+
+func TestCalDAV(t *testing.T) {
+	resources := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		switch r.Method {
+		case http.MethodPut:
+			_, exists := resources[r.URL.Path]
+			if r.Header.Get("If-None-Match") == "*" && exists || r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != `"1"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			assert.Equal(t, "text/calendar; charset=utf-8", r.Header.Get("Content-Type"))
+			resources[r.URL.Path] = string(b)
+			w.Header().Set("ETag", `"1"`)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			if _, ok := resources[r.URL.Path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(resources, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case "REPORT":
+			assert.Equal(t, "/cal/work/", r.URL.Path)
+			assert.Contains(t, string(b), `<C:time-range start="20221020T000000Z" end="20221021T000000Z"/>`)
+			w.Header().Set("Content-Type", "text/calendar")
+			io.WriteString(w, "BEGIN:VCALENDAR\r\nBEGIN:VFREEBUSY\r\nFREEBUSY;FBTYPE=BUSY:20221020T130000Z/PT30M,\r\n 20221020T080000Z/20221020T090000Z\r\n"+
+				"FREEBUSY;FBTYPE=FREE:20221020T100000Z/PT1H\r\nEND:VFREEBUSY\r\nEND:VCALENDAR\r\n")
+		}
+	}))
+	defer srv.Close()
+
+	p, err := newCalDAV(calendarMetadata{URL: srv.URL + "/cal/work", Username: "alice", Password: "secret", Timeout: defaultTimeout})
+	require.NoError(t, err)
+	c := &Calendar{metadata: calendarMetadata{Timeout: defaultTimeout}, provider: p, logger: logger.NewLogger("test")}
+
+	event := `{"id":"standup","summary":"Standup, daily","start":"2022-10-20T09:00:00+02:00","end":"2022-10-20T09:15:00+02:00","attendees":["bob@example.com"]}`
+	res, err := c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: CreateOperation, Data: []byte(event)})
+	require.NoError(t, err)
+	assert.Equal(t, `"1"`, res.Metadata[respETagKey])
+	ics := resources["/cal/work/standup.ics"]
+	assert.Contains(t, ics, "UID:standup\r\n")
+	assert.Contains(t, ics, "DTSTART:20221020T070000Z\r\n")
+	assert.Contains(t, ics, "SUMMARY:Standup\\, daily\r\n")
+	assert.Contains(t, ics, "ATTENDEE:mailto:bob@example.com\r\n")
+	var created Event
+	require.NoError(t, json.Unmarshal(res.Data, &created))
+	assert.Equal(t, srv.URL+"/cal/work/standup.ics", created.URL)
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: CreateOperation, Data: []byte(event)})
+	require.ErrorContains(t, err, "the event exists or has changed")
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: UpdateOperation,
+		Data:      []byte(event),
+		Metadata:  map[string]string{eventIDKey: "standup", etagKey: `"2"`},
+	})
+	require.ErrorContains(t, err, "the event exists or has changed")
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: DeleteOperation, Metadata: map[string]string{eventIDKey: "standup"}})
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+
+	res, err = c.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: FreeBusyOperation,
+		Data:      []byte(`{"timeMin":"2022-10-20T02:00:00+02:00","timeMax":"2022-10-21T00:00:00Z"}`),
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"calendars":{"`+srv.URL+`/cal/work/":[
+		{"start":"2022-10-20T08:00:00Z","end":"2022-10-20T09:00:00Z"},
+		{"start":"2022-10-20T13:00:00Z","end":"2022-10-20T13:30:00Z"}
+	]}}`, string(res.Data))
+}
+
+func TestEncodeEventFoldsLines(t *testing.T) {
+	ics := encodeEvent(&Event{ID: "e1", Summary: strings.Repeat("é", 50), Start: time.Unix(0, 0), End: time.Unix(3600, 0)})
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), icalMaxLineLength+1)
+	}
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("é", 50)+"\r\n")
+}
+
+func TestParseDuration(t *testing.T) {
+	for v, expected := range map[string]time.Duration{
+		"PT30M":     30 * time.Minute,
+		"P1DT2H":    26 * time.Hour,
+		"P1W":       7 * 24 * time.Hour,
+		"PT1H0M10S": time.Hour + 10*time.Second,
+	} {
+		d, err := parseDuration(v)
+		require.NoError(t, err, v)
+		assert.Equal(t, expected, d, v)
+	}
+	for _, v := range []string{"P", "PT", "1H", "PT5", "P1H"} {
+		_, err := parseDuration(v)
+		require.Error(t, err, v)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	gcal "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const googleDateLayout = "2006-01-02"
+
+// googleCalendar is the provider of Google calendars.
+type googleCalendar struct {
+	service    *gcal.Service
+	calendarID string
+}
+
+func newGoogleCalendar(ctx context.Context, m calendarMetadata, opts ...option.ClientOption) (*googleCalendar, error) {
+	if m.PrivateKey != "" {
+		credentials, err := json.Marshal(map[string]string{
+			"type":                        m.Type,
+			"project_id":                  m.ProjectID,
+			"private_key_id":              m.PrivateKeyID,
+			"private_key":                 m.PrivateKey,
+			"client_email":                m.ClientEmail,
+			"client_id":                   m.ClientID,
+			"auth_uri":                    m.AuthURI,
+			"token_uri":                   m.TokenURI,
+			"auth_provider_x509_cert_url": m.AuthProviderCertURL,
+			"client_x509_cert_url":        m.ClientCertURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	}
+	opts = append(opts, option.WithScopes(gcal.CalendarScope))
+	service, err := gcal.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &googleCalendar{service: service, calendarID: m.CalendarID}, nil
+}
+
+// calendar returns the calendar of the request.
+func (g *googleCalendar) calendar(req *bindings.InvokeRequest) string {
+	if id := req.Metadata[calendarIDKey]; id != "" {
+		return id
+	}
+	return g.calendarID
+}
+
+func (g *googleCalendar) create(ctx context.Context, req *bindings.InvokeRequest, event *Event) (*Event, map[string]string, error) {
+	call := g.service.Events.Insert(g.calendar(req), toGoogleEvent(event)).Context(ctx)
+	if v := req.Metadata[sendUpdatesKey]; v != "" {
+		call.SendUpdates(v)
+	}
+	res, err := call.Do()
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromGoogleEvent(res), map[string]string{respETagKey: res.Etag}, nil
+}
+
+func (g *googleCalendar) update(ctx context.Context, req *bindings.InvokeRequest, event *Event) (*Event, map[string]string, error) {
+	call := g.service.Events.Update(g.calendar(req), event.ID, toGoogleEvent(event)).Context(ctx)
+	if v := req.Metadata[sendUpdatesKey]; v != "" {
+		call.SendUpdates(v)
+	}
+	if v := req.Metadata[etagKey]; v != "" {
+		call.Header().Set("If-Match", v)
+	}
+	res, err := call.Do()
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromGoogleEvent(res), map[string]string{respETagKey: res.Etag}, nil
+}
+
+func (g *googleCalendar) delete(ctx context.Context, req *bindings.InvokeRequest, id string) error {
+	call := g.service.Events.Delete(g.calendar(req), id).Context(ctx)
+	if v := req.Metadata[sendUpdatesKey]; v != "" {
+		call.SendUpdates(v)
+	}
+	if v := req.Metadata[etagKey]; v != "" {
+		call.Header().Set("If-Match", v)
+	}
+	return call.Do()
+}
+
+func (g *googleCalendar) freeBusy(ctx context.Context, req *bindings.InvokeRequest, fb *FreeBusyRequest) (*FreeBusyResult, error) {
+	calendars := fb.Calendars
+	if len(calendars) == 0 {
+		calendars = []string{g.calendar(req)}
+	}
+	query := &gcal.FreeBusyRequest{
+		TimeMin: fb.TimeMin.Format(time.RFC3339),
+		TimeMax: fb.TimeMax.Format(time.RFC3339),
+		Items:   make([]*gcal.FreeBusyRequestItem, len(calendars)),
+	}
+	for i, id := range calendars {
+		query.Items[i] = &gcal.FreeBusyRequestItem{Id: id}
+	}
+	res, err := g.service.Freebusy.Query(query).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FreeBusyResult{Calendars: make(map[string][]Period, len(res.Calendars))}
+	for id, cal := range res.Calendars {
+		if len(cal.Errors) > 0 {
+			return nil, fmt.Errorf("calendar %s: %s", id, cal.Errors[0].Reason)
+		}
+		periods := make([]Period, 0, len(cal.Busy))
+		for _, busy := range cal.Busy {
+			var p Period
+			if p.Start, err = time.Parse(time.RFC3339, busy.Start); err != nil {
+				return nil, fmt.Errorf("calendar %s: invalid busy period: %w", id, err)
+			}
+			if p.End, err = time.Parse(time.RFC3339, busy.End); err != nil {
+				return nil, fmt.Errorf("calendar %s: invalid busy period: %w", id, err)
+			}
+			periods = append(periods, p)
+		}
+		result.Calendars[id] = periods
+	}
+	return result, nil
+}
+
+func toGoogleEvent(e *Event) *gcal.Event {
+	ge := &gcal.Event{
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+		Start:       &gcal.EventDateTime{TimeZone: e.TimeZone},
+		End:         &gcal.EventDateTime{TimeZone: e.TimeZone},
+	}
+	if e.AllDay {
+		ge.Start.Date = e.Start.Format(googleDateLayout)
+		ge.End.Date = e.End.Format(googleDateLayout)
+	} else {
+		ge.Start.DateTime = e.Start.Format(time.RFC3339)
+		ge.End.DateTime = e.End.Format(time.RFC3339)
+	}
+	for _, email := range e.Attendees {
+		ge.Attendees = append(ge.Attendees, &gcal.EventAttendee{Email: email})
+	}
+	return ge
+}
+
+func fromGoogleEvent(ge *gcal.Event) *Event {
+	e := &Event{
+		ID:          ge.Id,
+		Summary:     ge.Summary,
+		Description: ge.Description,
+		Location:    ge.Location,
+		URL:         ge.HtmlLink,
+	}
+	if ge.Start != nil {
+		e.Start, e.AllDay = fromGoogleDateTime(ge.Start)
+		e.TimeZone = ge.Start.TimeZone
+	}
+	if ge.End != nil {
+		e.End, _ = fromGoogleDateTime(ge.End)
+	}
+	for _, a := range ge.Attendees {
+		e.Attendees = append(e.Attendees, a.Email)
+	}
+	return e
+}
+
+// fromGoogleDateTime returns the time of a date or date-time, and whether it is a date.
+func fromGoogleDateTime(dt *gcal.EventDateTime) (time.Time, bool) {
+	if dt.Date != "" {
+		t, _ := time.Parse(googleDateLayout, dt.Date)
+		return t, true
+	}
+	t, _ := time.Parse(time.RFC3339, dt.DateTime)
+	return t, false
+}
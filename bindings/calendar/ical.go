@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateTimeLayout = "20060102T150405Z"
+	icalDateLayout     = "20060102"
+
+	// Lines of iCalendar objects are folded at 75 octets.
+	icalMaxLineLength = 75
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// encodeEvent returns the iCalendar object of the event. Times are in UTC, and all-day events are dates.
+func encodeEvent(e *Event) string {
+	var sb strings.Builder
+	writeLine := func(line string) {
+		for len(line) > icalMaxLineLength {
+			// Split at the start of a UTF-8 sequence.
+			n := icalMaxLineLength
+			for n > 0 && line[n]&0xC0 == 0x80 {
+				n--
+			}
+			sb.WriteString(line[:n] + "\r\n ")
+			line = line[n:]
+		}
+		sb.WriteString(line + "\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Dapr//Calendar Binding//EN")
+	writeLine("BEGIN:VEVENT")
+	writeLine("UID:" + e.ID)
+	writeLine("DTSTAMP:" + time.Now().UTC().Format(icalDateTimeLayout))
+	if e.AllDay {
+		writeLine("DTSTART;VALUE=DATE:" + e.Start.Format(icalDateLayout))
+		writeLine("DTEND;VALUE=DATE:" + e.End.Format(icalDateLayout))
+	} else {
+		writeLine("DTSTART:" + e.Start.UTC().Format(icalDateTimeLayout))
+		writeLine("DTEND:" + e.End.UTC().Format(icalDateTimeLayout))
+	}
+	writeLine("SUMMARY:" + icalEscaper.Replace(e.Summary))
+	if e.Description != "" {
+		writeLine("DESCRIPTION:" + icalEscaper.Replace(e.Description))
+	}
+	if e.Location != "" {
+		writeLine("LOCATION:" + icalEscaper.Replace(e.Location))
+	}
+	for _, email := range e.Attendees {
+		writeLine("ATTENDEE:mailto:" + email)
+	}
+	writeLine("END:VEVENT")
+	writeLine("END:VCALENDAR")
+	return sb.String()
+}
+
+// parseFreeBusy returns the busy periods of the VFREEBUSY components of an iCalendar object, sorted by start.
+func parseFreeBusy(ics string) ([]Period, error) {
+	// Unfold the lines.
+	ics = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(ics)
+
+	periods := []Period{}
+	inFreeBusy := false
+	for _, line := range strings.Split(ics, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == "BEGIN:VFREEBUSY":
+			inFreeBusy = true
+		case line == "END:VFREEBUSY":
+			inFreeBusy = false
+		case inFreeBusy && strings.HasPrefix(line, "FREEBUSY"):
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid FREEBUSY property %q", line)
+			}
+			params := strings.Split(name, ";")
+			if params[0] != "FREEBUSY" {
+				continue
+			}
+			free := false
+			for _, p := range params[1:] {
+				if strings.EqualFold(p, "FBTYPE=FREE") {
+					free = true
+				}
+			}
+			if free {
+				continue
+			}
+			for _, v := range strings.Split(value, ",") {
+				p, err := parsePeriod(v)
+				if err != nil {
+					return nil, err
+				}
+				periods = append(periods, p)
+			}
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+	return periods, nil
+}
+
+// parsePeriod parses a period of time in the start/end or start/duration format.
+func parsePeriod(v string) (Period, error) {
+	start, end, ok := strings.Cut(v, "/")
+	if !ok {
+		return Period{}, fmt.Errorf("invalid period %q", v)
+	}
+	var (
+		p   Period
+		err error
+	)
+	if p.Start, err = time.Parse(icalDateTimeLayout, start); err != nil {
+		return Period{}, fmt.Errorf("invalid period %q: %w", v, err)
+	}
+	if strings.HasPrefix(end, "P") || strings.HasPrefix(end, "+P") {
+		d, err := parseDuration(strings.TrimPrefix(end, "+"))
+		if err != nil {
+			return Period{}, fmt.Errorf("invalid period %q: %w", v, err)
+		}
+		p.End = p.Start.Add(d)
+	} else if p.End, err = time.Parse(icalDateTimeLayout, end); err != nil {
+		return Period{}, fmt.Errorf("invalid period %q: %w", v, err)
+	}
+	return p, nil
+}
+
+// parseDuration parses a positive iCalendar duration such as P1W, P1DT2H or PT30M.
+func parseDuration(v string) (time.Duration, error) {
+	if !strings.HasPrefix(v, "P") || len(v) < 3 {
+		return 0, errors.New("invalid duration")
+	}
+	var (
+		d      time.Duration
+		inTime bool
+		num    string
+	)
+	for _, c := range v[1:] {
+		if c >= '0' && c <= '9' {
+			num += string(c)
+			continue
+		}
+		if c == 'T' {
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, errors.New("invalid duration")
+		}
+		num = ""
+		var unit time.Duration
+		switch {
+		case !inTime && c == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && c == 'D':
+			unit = 24 * time.Hour
+		case inTime && c == 'H':
+			unit = time.Hour
+		case inTime && c == 'M':
+			unit = time.Minute
+		case inTime && c == 'S':
+			unit = time.Second
+		default:
+			return 0, errors.New("invalid duration")
+		}
+		d += time.Duration(n) * unit
+	}
+	if num != "" {
+		return 0, errors.New("invalid duration")
+	}
+	return d, nil
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultAddress   = "localhost:3310"
+	defaultTimeout   = 30 * time.Second
+	defaultChunkSize = 64 * 1024
+	unixPrefix       = "unix://"
+
+	// keys from request's metadata.
+	sourceKeyKey     = "sourceKey"
+	quarantineKeyKey = "quarantineKey"
+
+	// keys from response's metadata.
+	respInfectedKey      = "infected"
+	respSignatureKey     = "signature"
+	respQuarantineKeyKey = "quarantineKey"
+
+	ScanOperation    bindings.OperationKind = "scan"
+	PingOperation    bindings.OperationKind = "ping"
+	VersionOperation bindings.OperationKind = "version"
+)
+
+var errNoStorage = errors.New("the sourceKey and quarantineKey metadata require a storage")
+
+// ClamAV is an output binding scanning payloads with a ClamAV daemon.
+type ClamAV struct {
+	metadata clamavMetadata
+	storage  state.OffloadStorage
+	logger   logger.Logger
+}
+
+type clamavMetadata struct {
+	// Address of clamd: host:port for TCP, or unix:///path/to/clamd.sock.
+	Address string        `mapstructure:"address"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Size of the chunks the payloads are streamed in.
+	ChunkSize int `mapstructure:"chunkSize"`
+}
+
+// ScanResult is the verdict of a scan.
+type ScanResult struct {
+	Infected bool `json:"infected"`
+	// Name of the signature matched by infected payloads.
+	Signature string `json:"signature,omitempty"`
+	// Key the infected payload was saved under, when quarantined.
+	QuarantineKey string `json:"quarantineKey,omitempty"`
+}
+
+// NewClamAV returns a new ClamAV binding, scanning the payloads in the request data.
+func NewClamAV(logger logger.Logger) bindings.OutputBinding {
+	return &ClamAV{logger: logger}
+}
+
+// NewClamAVWithStorage returns a new ClamAV binding which also scans the objects named by the "sourceKey" metadata in storage,
+// and saves infected payloads under the "quarantineKey" metadata.
+// Object storage bindings are used as storage with bindings.NewOffloadStorage.
+func NewClamAVWithStorage(logger logger.Logger, storage state.OffloadStorage) bindings.OutputBinding {
+	return &ClamAV{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Init parses the metadata of the binding.
+func (c *ClamAV) Init(meta bindings.Metadata) error {
+	c.metadata = clamavMetadata{
+		Address:   defaultAddress,
+		Timeout:   defaultTimeout,
+		ChunkSize: defaultChunkSize,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &c.metadata)
+	if err != nil {
+		return fmt.Errorf("clamav binding error: %w", err)
+	}
+	if c.metadata.ChunkSize < 1 {
+		return errors.New("clamav binding error: chunkSize must be positive")
+	}
+
+	return nil
+}
+
+// Operations returns the operations supported by the ClamAV binding.
+func (c *ClamAV) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{ScanOperation, PingOperation, VersionOperation}
+}
+
+// Invoke sends the request to clamd.
+func (c *ClamAV) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var (
+		res *bindings.InvokeResponse
+		err error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case ScanOperation:
+		res, err = c.scan(ctx, req)
+	case PingOperation:
+		var reply string
+		reply, err = c.command(ctx, "PING", nil)
+		if err == nil && reply != "PONG" {
+			err = fmt.Errorf("unexpected reply: %s", reply)
+		}
+		res = &bindings.InvokeResponse{}
+	case VersionOperation:
+		var reply string
+		reply, err = c.command(ctx, "VERSION", nil)
+		res = &bindings.InvokeResponse{Data: []byte(reply)}
+	default:
+		return nil, fmt.Errorf("clamav binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clamav binding error: %s failed: %w", req.Operation, err)
+	}
+
+	if res.Metadata == nil {
+		res.Metadata = map[string]string{}
+	}
+	res.Metadata[bindings.ResponseMetadataOperation] = string(req.Operation)
+
+	return res, nil
+}
+
+func (c *ClamAV) scan(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	data := req.Data
+	if key := req.Metadata[sourceKeyKey]; key != "" {
+		if c.storage == nil {
+			return nil, errNoStorage
+		}
+		var err error
+		data, err = c.storage.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+		}
+	}
+
+	reply, err := c.command(ctx, "INSTREAM", data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := parseScanReply(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if key := req.Metadata[quarantineKeyKey]; key != "" && result.Infected {
+		if c.storage == nil {
+			return nil, errNoStorage
+		}
+		if err = c.storage.Put(ctx, key, data); err != nil {
+			return nil, fmt.Errorf("failed to quarantine payload to %s: %w", key, err)
+		}
+		result.QuarantineKey = key
+		c.logger.Infof("clamav: quarantined payload infected by %s to %s", result.Signature, key)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	md := map[string]string{
+		respInfectedKey: strconv.FormatBool(result.Infected),
+	}
+	if result.Signature != "" {
+		md[respSignatureKey] = result.Signature
+	}
+	if result.QuarantineKey != "" {
+		md[respQuarantineKeyKey] = result.QuarantineKey
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     body,
+		Metadata: md,
+	}, nil
+}
+
+// command sends a null-terminated command to clamd and returns its reply.
+// For INSTREAM, the payload is streamed in chunks prefixed by their size, and terminated by an empty chunk.
+func (c *ClamAV) command(ctx context.Context, cmd string, payload []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.metadata.Timeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err = c.writeCommand(conn, cmd, payload); err != nil {
+		// clamd closes the connection when the stream exceeds its StreamMaxLength: read the reply explaining why.
+		if reply, readErr := readReply(conn); readErr == nil && reply != "" {
+			return "", fmt.Errorf("clamd error: %s", strings.TrimSuffix(reply, " ERROR"))
+		}
+		return "", err
+	}
+
+	return readReply(conn)
+}
+
+func (c *ClamAV) writeCommand(conn net.Conn, cmd string, payload []byte) error {
+	w := bufio.NewWriter(conn)
+	if _, err := w.WriteString("z" + cmd + "\x00"); err != nil {
+		return err
+	}
+	if cmd == "INSTREAM" {
+		var size [4]byte
+		for len(payload) > 0 {
+			n := len(payload)
+			if n > c.metadata.ChunkSize {
+				n = c.metadata.ChunkSize
+			}
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, err := w.Write(size[:]); err != nil {
+				return err
+			}
+			if _, err := w.Write(payload[:n]); err != nil {
+				return err
+			}
+			payload = payload[n:]
+		}
+		binary.BigEndian.PutUint32(size[:], 0)
+		if _, err := w.Write(size[:]); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func (c *ClamAV) dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	if strings.HasPrefix(c.metadata.Address, unixPrefix) {
+		return d.DialContext(ctx, "unix", strings.TrimPrefix(c.metadata.Address, unixPrefix))
+	}
+
+	return d.DialContext(ctx, "tcp", c.metadata.Address)
+}
+
+func readReply(r io.Reader) (string, error) {
+	reply, err := bufio.NewReader(r).ReadBytes(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// parseScanReply parses the reply to INSTREAM: "stream: OK", "stream: <signature> FOUND" or "<message> ERROR".
+func parseScanReply(reply string) (*ScanResult, error) {
+	if strings.HasSuffix(reply, " ERROR") {
+		return nil, fmt.Errorf("clamd error: %s", strings.TrimSuffix(reply, " ERROR"))
+	}
+
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(verdict, " FOUND"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply: %s", reply)
+	}
+}
+
+// OperationsMetadata describes the operations of the ClamAV binding.
+func (c *ClamAV) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        ScanOperation,
+			Description:      "Scans the data, or the object named by sourceKey. Returns a JSON object with the verdict; infected payloads are saved under quarantineKey when it's set.",
+			RequestMetadata:  []string{sourceKeyKey, quarantineKeyKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respInfectedKey, respSignatureKey, respQuarantineKeyKey},
+		},
+		{
+			Operation:        PingOperation,
+			Description:      "Checks that clamd is reachable.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        VersionOperation,
+			Description:      "Returns the version of clamd and of its signature database.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+type fakeStorage map[string][]byte
+
+func (s fakeStorage) Put(_ context.Context, name string, data []byte) error {
+	s[name] = data
+	return nil
+}
+
+func (s fakeStorage) Get(_ context.Context, name string) ([]byte, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (s fakeStorage) Delete(_ context.Context, name string) error {
+	delete(s, name)
+	return nil
+}
+
+// startClamd starts a fake clamd flagging the payloads containing the EICAR test string.
+func startClamd(t *testing.T, maxStream int) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveClamd(conn, maxStream)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func serveClamd(conn net.Conn, maxStream int) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	cmd, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+	switch strings.TrimSuffix(cmd, "\x00") {
+	case "zPING":
+		conn.Write([]byte("PONG\x00"))
+	case "zVERSION":
+		conn.Write([]byte("ClamAV 1.0.1/26838/Mon Mar 13 08:21:59 2023\x00"))
+	case "zINSTREAM":
+		var stream bytes.Buffer
+		for {
+			var size [4]byte
+			if _, err = io.ReadFull(r, size[:]); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size[:])
+			if n == 0 {
+				break
+			}
+			if _, err = io.CopyN(&stream, r, int64(n)); err != nil {
+				return
+			}
+			if stream.Len() > maxStream {
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				return
+			}
+		}
+		if bytes.Contains(stream.Bytes(), []byte(eicar)) {
+			conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+		} else {
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	default:
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+func newTestClamAV(t *testing.T, storage fakeStorage, maxStream int) bindings.OutputBinding {
+	t.Helper()
+
+	c := NewClamAVWithStorage(logger.NewLogger("test"), storage)
+	require.NoError(t, c.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"address":   startClamd(t, maxStream),
+		"chunkSize": "16",
+	}}}))
+	return c
+}
+
+func TestScan(t *testing.T) {
+	storage := fakeStorage{"upload/1": []byte("prefix " + eicar)}
+	c := newTestClamAV(t, storage, 1024)
+
+	t.Run("clean", func(t *testing.T) {
+		res, err := c.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ScanOperation,
+			Data:      []byte(strings.Repeat("clean data ", 10)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "false", res.Metadata[respInfectedKey])
+		assert.JSONEq(t, `{"infected": false}`, string(res.Data))
+	})
+
+	t.Run("infected", func(t *testing.T) {
+		res, err := c.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ScanOperation,
+			Data:      []byte(eicar),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "true", res.Metadata[respInfectedKey])
+		assert.Equal(t, "Eicar-Signature", res.Metadata[respSignatureKey])
+	})
+
+	t.Run("quarantine referenced object", func(t *testing.T) {
+		res, err := c.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ScanOperation,
+			Metadata: map[string]string{
+				sourceKeyKey:     "upload/1",
+				quarantineKeyKey: "quarantine/1",
+			},
+		})
+		require.NoError(t, err)
+
+		var result ScanResult
+		require.NoError(t, json.Unmarshal(res.Data, &result))
+		assert.Equal(t, ScanResult{Infected: true, Signature: "Eicar-Signature", QuarantineKey: "quarantine/1"}, result)
+		assert.Equal(t, storage["upload/1"], storage["quarantine/1"])
+	})
+
+	t.Run("clean payloads are not quarantined", func(t *testing.T) {
+		_, err := c.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ScanOperation,
+			Data:      []byte("clean"),
+			Metadata:  map[string]string{quarantineKeyKey: "quarantine/2"},
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, storage, "quarantine/2")
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		_, err := c.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ScanOperation,
+			Data:      bytes.Repeat([]byte("a"), 64*1024),
+		})
+		assert.ErrorContains(t, err, "size limit exceeded")
+	})
+}
+
+func TestPingVersion(t *testing.T) {
+	c := newTestClamAV(t, fakeStorage{}, 1024)
+
+	_, err := c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: PingOperation})
+	require.NoError(t, err)
+
+	res, err := c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: VersionOperation})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(res.Data), "ClamAV 1.0.1"))
+}
+
+func TestUnreachable(t *testing.T) {
+	c := NewClamAV(logger.NewLogger("test"))
+	require.NoError(t, c.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"address": "unix:///nonexistent/clamd.sock",
+	}}}))
+
+	_, err := c.Invoke(context.Background(), &bindings.InvokeRequest{Operation: PingOperation})
+	assert.ErrorContains(t, err, "failed to connect to clamd")
+
+	_, err = c.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: ScanOperation,
+		Metadata:  map[string]string{sourceKeyKey: "upload/1"},
+	})
+	assert.ErrorIs(t, err, errNoStorage)
+}
+
+func TestParseScanReply(t *testing.T) {
+	res, err := parseScanReply("stream: OK")
+	require.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	res, err = parseScanReply("stream: Win.Test.EICAR_HDB-1 FOUND")
+	require.NoError(t, err)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", res.Signature)
+
+	_, err = parseScanReply("INSTREAM size limit exceeded. ERROR")
+	assert.ErrorContains(t, err, "INSTREAM size limit exceeded.")
+
+	_, err = parseScanReply("garbage")
+	assert.Error(t, err)
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+// Event types.
+const (
+	eventTypeKV      = "kv"
+	eventTypeService = "service"
+
+	kvEventPut    = "put"
+	kvEventDelete = "delete"
+)
+
+// KVEvent is emitted when a key under a watched prefix is created, modified or deleted.
+type KVEvent struct {
+	Type        string `json:"type"`
+	Event       string `json:"event"`
+	Key         string `json:"key"`
+	Value       []byte `json:"value,omitempty"`
+	Flags       uint64 `json:"flags,omitempty"`
+	ModifyIndex uint64 `json:"modifyIndex"`
+}
+
+// ServiceEvent is emitted when the instances of a watched service, or their health, change.
+type ServiceEvent struct {
+	Type      string            `json:"type"`
+	Service   string            `json:"service"`
+	Instances []ServiceInstance `json:"instances"`
+}
+
+// ServiceInstance is an instance of a service with its aggregated health status.
+type ServiceInstance struct {
+	ID      string            `json:"id"`
+	Node    string            `json:"node"`
+	Address string            `json:"address"`
+	Port    int               `json:"port"`
+	Tags    []string          `json:"tags,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Status  string            `json:"status"`
+}
+
+// Consul is an input binding watching Consul KV prefixes and service health.
+type Consul struct {
+	metadata consulMetadata
+	client   *consul.Client
+	logger   logger.Logger
+	wg       sync.WaitGroup
+}
+
+// NewConsul returns a new Consul input binding.
+func NewConsul(logger logger.Logger) bindings.InputBinding {
+	return &Consul{logger: logger}
+}
+
+// Init performs metadata parsing and creates the Consul client.
+func (c *Consul) Init(metadata bindings.Metadata) error {
+	m, err := parseMetadata(metadata)
+	if err != nil {
+		return fmt.Errorf("consul binding error: %w", err)
+	}
+	c.metadata = m
+
+	cfg := consul.DefaultConfig()
+	if m.Address != "" {
+		cfg.Address = m.Address
+	}
+	if m.Scheme != "" {
+		cfg.Scheme = m.Scheme
+	}
+	if m.Datacenter != "" {
+		cfg.Datacenter = m.Datacenter
+	}
+	if m.Token != "" {
+		cfg.Token = m.Token
+	}
+	c.client, err = consul.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("consul binding error: failed to create client: %w", err)
+	}
+
+	return nil
+}
+
+// Read starts watching the configured prefixes and services.
+// Changes after the binding starts are emitted: the state found by the first query is only used as the baseline.
+func (c *Consul) Read(ctx context.Context, handler bindings.Handler) error {
+	for _, prefix := range c.metadata.keyPrefixes() {
+		c.wg.Add(1)
+		go c.watchKV(ctx, prefix, handler)
+	}
+	for _, service := range c.metadata.services() {
+		c.wg.Add(1)
+		go c.watchService(ctx, service, handler)
+	}
+
+	return nil
+}
+
+func (c *Consul) watchKV(ctx context.Context, prefix string, handler bindings.Handler) {
+	defer c.wg.Done()
+
+	var (
+		index    uint64
+		previous map[string]*consul.KVPair
+	)
+	for ctx.Err() == nil {
+		pairs, meta, err := c.client.KV().List(prefix, c.queryOptions(ctx, index))
+		if err != nil {
+			if ctx.Err() == nil {
+				c.logger.Errorf("consul binding: error watching prefix %s: %v", prefix, err)
+				c.wait(ctx)
+			}
+			continue
+		}
+
+		index = nextIndex(index, meta.LastIndex)
+		current := make(map[string]*consul.KVPair, len(pairs))
+		for _, p := range pairs {
+			current[p.Key] = p
+		}
+		if previous != nil {
+			for _, e := range diffKV(previous, current) {
+				c.emit(ctx, handler, e, map[string]string{"type": eventTypeKV, "event": e.Event, "key": e.Key})
+			}
+		}
+		previous = current
+	}
+}
+
+func (c *Consul) watchService(ctx context.Context, service string, handler bindings.Handler) {
+	defer c.wg.Done()
+
+	var (
+		index    uint64
+		previous []ServiceInstance
+		started  bool
+	)
+	for ctx.Err() == nil {
+		entries, meta, err := c.client.Health().Service(service, "", c.metadata.PassingOnly, c.queryOptions(ctx, index))
+		if err != nil {
+			if ctx.Err() == nil {
+				c.logger.Errorf("consul binding: error watching service %s: %v", service, err)
+				c.wait(ctx)
+			}
+			continue
+		}
+
+		index = nextIndex(index, meta.LastIndex)
+		current := serviceInstances(entries)
+		if started && !reflect.DeepEqual(previous, current) {
+			c.emit(ctx, handler, ServiceEvent{Type: eventTypeService, Service: service, Instances: current}, map[string]string{"type": eventTypeService, "service": service})
+		}
+		previous = current
+		started = true
+	}
+}
+
+func (c *Consul) queryOptions(ctx context.Context, index uint64) *consul.QueryOptions {
+	opts := &consul.QueryOptions{
+		WaitIndex: index,
+		WaitTime:  c.metadata.WaitTime,
+	}
+	return opts.WithContext(ctx)
+}
+
+func (c *Consul) emit(ctx context.Context, handler bindings.Handler, event interface{}, metadata map[string]string) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		c.logger.Errorf("consul binding: error marshalling event: %v", err)
+		return
+	}
+	_, err = handler(ctx, &bindings.ReadResponse{
+		Data:     data,
+		Metadata: metadata,
+	})
+	if err != nil {
+		c.logger.Errorf("consul binding: error handling event: %v", err)
+	}
+}
+
+func (c *Consul) wait(ctx context.Context) {
+	select {
+	case <-time.After(c.metadata.RetryWait):
+	case <-ctx.Done():
+	}
+}
+
+// nextIndex returns the index of the next blocking query.
+// The index is reset when it goes backwards, as recommended by Consul.
+func nextIndex(previous, last uint64) uint64 {
+	if last < previous {
+		return 0
+	}
+	return last
+}
+
+// diffKV returns the events turning previous into current, sorted by key.
+func diffKV(previous, current map[string]*consul.KVPair) []KVEvent {
+	var events []KVEvent
+	for key, p := range current {
+		if old, ok := previous[key]; !ok || old.ModifyIndex != p.ModifyIndex {
+			events = append(events, KVEvent{
+				Type:        eventTypeKV,
+				Event:       kvEventPut,
+				Key:         key,
+				Value:       p.Value,
+				Flags:       p.Flags,
+				ModifyIndex: p.ModifyIndex,
+			})
+		}
+	}
+	for key, old := range previous {
+		if _, ok := current[key]; !ok {
+			events = append(events, KVEvent{
+				Type:        eventTypeKV,
+				Event:       kvEventDelete,
+				Key:         key,
+				ModifyIndex: old.ModifyIndex,
+			})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
+
+// serviceInstances returns the instances of the entries, sorted by ID.
+func serviceInstances(entries []*consul.ServiceEntry) []ServiceInstance {
+	instances := make([]ServiceInstance, 0, len(entries))
+	for _, e := range entries {
+		address := e.Service.Address
+		if address == "" {
+			address = e.Node.Address
+		}
+		instances = append(instances, ServiceInstance{
+			ID:      e.Service.ID,
+			Node:    e.Node.Node,
+			Address: address,
+			Port:    e.Service.Port,
+			Tags:    e.Service.Tags,
+			Meta:    e.Service.Meta,
+			Status:  e.Checks.AggregatedStatus(),
+		})
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].ID == instances[j].ID {
+			return instances[i].Node < instances[j].Node
+		}
+		return instances[i].ID < instances[j].ID
+	})
+	return instances
+}
+
+// Close waits for the watches to stop once the context passed to Read is canceled.
+func (c *Consul) Close() error {
+	c.wg.Wait()
+	return nil
+}
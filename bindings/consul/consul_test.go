@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"keyPrefixes": "config/, features/ ,",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"config/", "features/"}, m.keyPrefixes())
+		assert.Empty(t, m.services())
+		assert.Equal(t, 5*time.Minute, m.WaitTime)
+		assert.Equal(t, 5*time.Second, m.RetryWait)
+	})
+
+	t.Run("all fields", func(t *testing.T) {
+		m, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"address":     "consul:8500",
+			"token":       "secret",
+			"services":    "web,api",
+			"passingOnly": "true",
+			"waitTime":    "30s",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, "consul:8500", m.Address)
+		assert.Equal(t, "secret", m.Token)
+		assert.Equal(t, []string{"web", "api"}, m.services())
+		assert.True(t, m.PassingOnly)
+		assert.Equal(t, 30*time.Second, m.WaitTime)
+	})
+
+	t.Run("nothing to watch", func(t *testing.T) {
+		_, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"address": "consul:8500",
+		}}})
+		require.Error(t, err)
+	})
+}
+
+func TestDiffKV(t *testing.T) {
+	previous := map[string]*consul.KVPair{
+		"a": {Key: "a", Value: []byte("1"), ModifyIndex: 1},
+		"b": {Key: "b", Value: []byte("2"), ModifyIndex: 2},
+		"c": {Key: "c", Value: []byte("3"), ModifyIndex: 3},
+	}
+	current := map[string]*consul.KVPair{
+		"a": {Key: "a", Value: []byte("1"), ModifyIndex: 1},
+		"b": {Key: "b", Value: []byte("20"), ModifyIndex: 4},
+		"d": {Key: "d", Value: []byte("4"), ModifyIndex: 5},
+	}
+
+	events := diffKV(previous, current)
+	assert.Equal(t, []KVEvent{
+		{Type: eventTypeKV, Event: kvEventPut, Key: "b", Value: []byte("20"), ModifyIndex: 4},
+		{Type: eventTypeKV, Event: kvEventDelete, Key: "c", ModifyIndex: 3},
+		{Type: eventTypeKV, Event: kvEventPut, Key: "d", Value: []byte("4"), ModifyIndex: 5},
+	}, events)
+
+	assert.Empty(t, diffKV(current, current))
+}
+
+func TestNextIndex(t *testing.T) {
+	assert.Equal(t, uint64(10), nextIndex(5, 10))
+	assert.Equal(t, uint64(10), nextIndex(10, 10))
+	assert.Equal(t, uint64(0), nextIndex(10, 3))
+}
+
+func TestServiceInstances(t *testing.T) {
+	instances := serviceInstances([]*consul.ServiceEntry{
+		{
+			Node:    &consul.Node{Node: "node2", Address: "10.0.0.2"},
+			Service: &consul.AgentService{ID: "web-2", Port: 8080},
+			Checks:  consul.HealthChecks{{Status: consul.HealthCritical}},
+		},
+		{
+			Node:    &consul.Node{Node: "node1", Address: "10.0.0.1"},
+			Service: &consul.AgentService{ID: "web-1", Address: "192.168.0.1", Port: 8080},
+			Checks:  consul.HealthChecks{{Status: consul.HealthPassing}},
+		},
+	})
+
+	assert.Equal(t, []ServiceInstance{
+		{ID: "web-1", Node: "node1", Address: "192.168.0.1", Port: 8080, Status: consul.HealthPassing},
+		{ID: "web-2", Node: "node2", Address: "10.0.0.2", Port: 8080, Status: consul.HealthCritical},
+	}, instances)
+}
+
+func TestReadKV(t *testing.T) {
+	var (
+		lock  sync.Mutex
+		index uint64 = 1
+		pairs        = consul.KVPairs{{Key: "config/a", Value: []byte("1"), ModifyIndex: 1}}
+	)
+	changed := make(chan struct{})
+	blocking := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		waitIndex, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+		lock.Lock()
+		current := index
+		lock.Unlock()
+		if waitIndex >= current {
+			select {
+			case blocking <- struct{}{}:
+			default:
+			}
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+		json.NewEncoder(w).Encode(pairs)
+	}))
+	defer srv.Close()
+
+	c := NewConsul(logger.NewLogger("test")).(*Consul)
+	err := c.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"address":     srv.Listener.Addr().String(),
+		"keyPrefixes": "config/",
+	}}})
+	require.NoError(t, err)
+
+	events := make(chan *bindings.ReadResponse, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = c.Read(ctx, func(_ context.Context, r *bindings.ReadResponse) ([]byte, error) {
+		events <- r
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	// Wait for the baseline to be read and the watch to block.
+	select {
+	case <-blocking:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for blocking query")
+	}
+	lock.Lock()
+	index = 2
+	pairs = consul.KVPairs{{Key: "config/b", Value: []byte("2"), ModifyIndex: 2}}
+	lock.Unlock()
+	changed <- struct{}{}
+
+	var received []KVEvent
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-events:
+			var e KVEvent
+			require.NoError(t, json.Unmarshal(r.Data, &e))
+			assert.Equal(t, eventTypeKV, r.Metadata["type"])
+			assert.Equal(t, e.Key, r.Metadata["key"])
+			received = append(received, e)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	assert.Equal(t, []KVEvent{
+		{Type: eventTypeKV, Event: kvEventDelete, Key: "config/a", ModifyIndex: 1},
+		{Type: eventTypeKV, Event: kvEventPut, Key: "config/b", Value: []byte("2"), ModifyIndex: 2},
+	}, received)
+
+	cancel()
+	require.NoError(t, c.Close())
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consul
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+type consulMetadata struct {
+	// Address of the Consul agent; defaults to the CONSUL_HTTP_ADDR environment variable or "127.0.0.1:8500".
+	Address    string `mapstructure:"address"`
+	Scheme     string `mapstructure:"scheme"`
+	Datacenter string `mapstructure:"datacenter"`
+	Token      string `mapstructure:"token"`
+	// Comma-separated KV prefixes to watch.
+	KeyPrefixes string `mapstructure:"keyPrefixes"`
+	// Comma-separated names of the services whose health is watched.
+	Services string `mapstructure:"services"`
+	// Only report the instances passing their health checks.
+	PassingOnly bool `mapstructure:"passingOnly"`
+	// Maximum duration of a blocking query.
+	WaitTime time.Duration `mapstructure:"waitTime"`
+	// Time to wait before retrying after an error.
+	RetryWait time.Duration `mapstructure:"retryWait"`
+}
+
+func parseMetadata(meta bindings.Metadata) (consulMetadata, error) {
+	m := consulMetadata{
+		WaitTime:  5 * time.Minute,
+		RetryWait: 5 * time.Second,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &m)
+	if err != nil {
+		return m, err
+	}
+	if len(m.keyPrefixes()) == 0 && len(m.services()) == 0 {
+		return m, errors.New("at least one of keyPrefixes or services is required")
+	}
+	return m, nil
+}
+
+func (m consulMetadata) keyPrefixes() []string {
+	return splitList(m.KeyPrefixes)
+}
+
+func (m consulMetadata) services() []string {
+	return splitList(m.Services)
+}
+
+func splitList(val string) []string {
+	var res []string
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
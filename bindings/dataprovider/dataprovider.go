@@ -0,0 +1,464 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/ratelimit"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultKeyPrefix = "dataprovider||"
+	defaultKeyName   = "X-API-Key"
+	maxResponseSize  = 10 << 20
+
+	// Values of authType.
+	authNone   = "none"
+	authAPIKey = "apiKey"
+	authBearer = "bearer"
+	authBasic  = "basic"
+
+	// keys from request's metadata.
+	pathKey    = "path"
+	noCacheKey = "noCache"
+
+	// keys of the response's metadata.
+	statusCodeKey = "statusCode"
+	cacheKey      = "cache"
+	fetchedAtKey  = "fetchedAt"
+
+	// Values of the cache response metadata.
+	cacheHit   = "hit"
+	cacheMiss  = "miss"
+	cacheStale = "stale"
+)
+
+// ErrRateLimited is returned when the provider limits the requests and there is no cached response to serve.
+var ErrRateLimited = errors.New("rate limited by the provider")
+
+// DataProvider is an output binding querying an external data API, such as a weather API, and caching its responses
+// in a state store so that the apps don't exceed the quotas of the provider.
+type DataProvider struct {
+	metadata providerMetadata
+	store    state.Store
+	client   *http.Client
+	limiter  ratelimit.Limiter
+	logger   logger.Logger
+
+	// blockedUntil is the end of the Retry-After period of the last rate limited response.
+	blockedLock  sync.Mutex
+	blockedUntil time.Time
+}
+
+type providerMetadata struct {
+	// BaseURL is the URL of the API, the paths are relative to.
+	BaseURL string `mapstructure:"baseURL"`
+	// PathTemplate is the default path and query of the requests, with {name} placeholders replaced by the parameters
+	// of the requests, e.g. "/weather?q={city}&units={units}".
+	PathTemplate string        `mapstructure:"pathTemplate"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+
+	// AuthType is "none", "apiKey", "bearer" or "basic".
+	AuthType string `mapstructure:"authType"`
+	// APIKey is the API key, or the bearer token.
+	APIKey string `mapstructure:"apiKey"`
+	// APIKeyName is the name of the header, or of the query parameter if APIKeyInQuery, with the API key.
+	APIKeyName    string `mapstructure:"apiKeyName"`
+	APIKeyInQuery bool   `mapstructure:"apiKeyInQuery"`
+	Username      string `mapstructure:"username"`
+	Password      string `mapstructure:"password"`
+
+	// CacheTTL is how long the responses are served from the cache. Caching is disabled if zero.
+	CacheTTL time.Duration `mapstructure:"cacheTTL"`
+	// MaxStale is how long after CacheTTL the responses may still be served while the provider is failing or rate limiting.
+	MaxStale  time.Duration `mapstructure:"maxStale"`
+	KeyPrefix string        `mapstructure:"keyPrefix"`
+	// MaxRequestsPerSecond limits the requests sent to the provider; unlimited if zero.
+	MaxRequestsPerSecond int `mapstructure:"maxRequestsPerSecond"`
+}
+
+// cacheEntry is a response saved in the state store.
+type cacheEntry struct {
+	StatusCode  int       `json:"statusCode"`
+	ContentType string    `json:"contentType,omitempty"`
+	Data        []byte    `json:"data"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+}
+
+// NewDataProvider returns a new data provider binding, caching the responses in the store if not nil.
+func NewDataProvider(logger logger.Logger, store state.Store) bindings.OutputBinding {
+	return &DataProvider{logger: logger, store: store}
+}
+
+// Init parses the metadata.
+func (d *DataProvider) Init(meta bindings.Metadata) error {
+	d.metadata = providerMetadata{Timeout: defaultTimeout, AuthType: authNone, APIKeyName: defaultKeyName, KeyPrefix: defaultKeyPrefix}
+	err := metadata.DecodeMetadata(meta.Properties, &d.metadata)
+	if err != nil {
+		return fmt.Errorf("data provider binding error: %w", err)
+	}
+
+	u, err := url.Parse(d.metadata.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("data provider binding error: invalid baseURL %q", d.metadata.BaseURL)
+	}
+	d.metadata.BaseURL = strings.TrimSuffix(d.metadata.BaseURL, "/")
+
+	switch d.metadata.AuthType {
+	case authNone:
+	case authAPIKey, authBearer:
+		if d.metadata.APIKey == "" {
+			return fmt.Errorf("data provider binding error: apiKey is required for authType %s", d.metadata.AuthType)
+		}
+	case authBasic:
+		if d.metadata.Username == "" {
+			return errors.New("data provider binding error: username is required for authType basic")
+		}
+	default:
+		return fmt.Errorf("data provider binding error: invalid authType %q", d.metadata.AuthType)
+	}
+
+	if d.metadata.CacheTTL < 0 || d.metadata.MaxStale < 0 {
+		return errors.New("data provider binding error: cacheTTL and maxStale must not be negative")
+	}
+	if d.metadata.CacheTTL > 0 && d.store == nil {
+		return errors.New("data provider binding error: cacheTTL requires a state store")
+	}
+
+	if d.metadata.MaxRequestsPerSecond > 0 {
+		d.limiter = ratelimit.New(d.metadata.MaxRequestsPerSecond)
+	} else {
+		d.limiter = ratelimit.NewUnlimited()
+	}
+	d.client = &http.Client{Timeout: d.metadata.Timeout}
+	return nil
+}
+
+// Operations returns the operations supported by the data provider binding.
+func (d *DataProvider) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.GetOperation}
+}
+
+// OperationsMetadata describes the operations of the data provider binding.
+func (d *DataProvider) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        bindings.GetOperation,
+			Description:      "Returns the response of the API for the path, with the parameters in the data and the metadata. Served from the cache while fresh, unless noCache is set.",
+			RequestMetadata:  []string{pathKey, noCacheKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, statusCodeKey, cacheKey, fetchedAtKey},
+		},
+	}
+}
+
+// Invoke runs the operation of the request.
+func (d *DataProvider) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != bindings.GetOperation {
+		return nil, fmt.Errorf("data provider binding error: unsupported operation %s", req.Operation)
+	}
+
+	params, err := requestParams(req)
+	if err != nil {
+		return nil, fmt.Errorf("data provider binding error: %w", err)
+	}
+	path := d.metadata.PathTemplate
+	if p := req.Metadata[pathKey]; p != "" {
+		path = p
+	}
+	target, err := expandTemplate(path, params)
+	if err != nil {
+		return nil, fmt.Errorf("data provider binding error: %w", err)
+	}
+	target = d.metadata.BaseURL + "/" + strings.TrimPrefix(target, "/")
+
+	caching := d.metadata.CacheTTL > 0
+	noCache, err := req.GetMetadataAsBool(noCacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("data provider binding error: %w", err)
+	}
+
+	// The key is a hash of the URL, which is before adding the credentials.
+	key := d.metadata.KeyPrefix + hashURL(target)
+	var cached *cacheEntry
+	if caching {
+		cached, err = d.getCached(ctx, key)
+		if err != nil {
+			d.logger.Warnf("data provider binding: error reading the cached response: %v", err)
+		}
+		if cached != nil && !noCache && time.Since(cached.FetchedAt) < d.metadata.CacheTTL {
+			return d.response(req, cached, cacheHit), nil
+		}
+		if cached != nil && time.Since(cached.FetchedAt) >= d.metadata.CacheTTL+d.metadata.MaxStale {
+			cached = nil
+		}
+	}
+
+	if until := d.blocked(); !until.IsZero() {
+		if cached != nil {
+			return d.response(req, cached, cacheStale), nil
+		}
+		return nil, fmt.Errorf("data provider binding error: %w until %s", ErrRateLimited, until.Format(time.RFC3339))
+	}
+
+	d.limiter.Take()
+	entry, err := d.fetch(ctx, target)
+	if err != nil {
+		if cached != nil {
+			d.logger.Warnf("data provider binding: serving a stale response: %v", err)
+			return d.response(req, cached, cacheStale), nil
+		}
+		return nil, fmt.Errorf("data provider binding error: %w", err)
+	}
+
+	if caching {
+		if err = d.setCached(ctx, key, entry); err != nil {
+			d.logger.Warnf("data provider binding: error caching the response: %v", err)
+		}
+	}
+	return d.response(req, entry, cacheMiss), nil
+}
+
+// fetch sends the request to the provider. Non-2xx responses are errors, so that they are never cached.
+func (d *DataProvider) fetch(ctx context.Context, target string) (*cacheEntry, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch d.metadata.AuthType {
+	case authAPIKey:
+		if d.metadata.APIKeyInQuery {
+			q := httpReq.URL.Query()
+			q.Set(d.metadata.APIKeyName, d.metadata.APIKey)
+			httpReq.URL.RawQuery = q.Encode()
+		} else {
+			httpReq.Header.Set(d.metadata.APIKeyName, d.metadata.APIKey)
+		}
+	case authBearer:
+		httpReq.Header.Set("Authorization", "Bearer "+d.metadata.APIKey)
+	case authBasic:
+		httpReq.SetBasicAuth(d.metadata.Username, d.metadata.Password)
+	}
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		// The URL may have the API key in the query string.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if until, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			d.block(until)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, ErrRateLimited
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResponseSize {
+		return nil, fmt.Errorf("response larger than %d bytes", maxResponseSize)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if len(data) > 1024 {
+			data = data[:1024]
+		}
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return &cacheEntry{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Data:        data,
+		FetchedAt:   time.Now().UTC(),
+	}, nil
+}
+
+func (d *DataProvider) getCached(ctx context.Context, key string) (*cacheEntry, error) {
+	res, err := d.store.Get(ctx, &state.GetRequest{Key: key})
+	if err != nil || res == nil || len(res.Data) == 0 {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal(res.Data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// setCached saves the entry with a TTL, for the stores supporting it, covering the period it may be served stale.
+func (d *DataProvider) setCached(ctx context.Context, key string, entry *cacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	ttl := d.metadata.CacheTTL + d.metadata.MaxStale
+	return d.store.Set(ctx, &state.SetRequest{
+		Key:   key,
+		Value: b,
+		Metadata: map[string]string{
+			metadata.TTLMetadataKey: strconv.FormatInt(int64((ttl+time.Second-1)/time.Second), 10),
+		},
+	})
+}
+
+func (d *DataProvider) response(req *bindings.InvokeRequest, entry *cacheEntry, cache string) *bindings.InvokeResponse {
+	res := &bindings.InvokeResponse{
+		Data: entry.Data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			statusCodeKey:                      strconv.Itoa(entry.StatusCode),
+			cacheKey:                           cache,
+			fetchedAtKey:                       entry.FetchedAt.Format(time.RFC3339),
+		},
+	}
+	if entry.ContentType != "" {
+		res.ContentType = &entry.ContentType
+	}
+	return res
+}
+
+// blocked returns the end of the Retry-After period, or zero if it has passed.
+func (d *DataProvider) blocked() time.Time {
+	d.blockedLock.Lock()
+	defer d.blockedLock.Unlock()
+	if time.Now().Before(d.blockedUntil) {
+		return d.blockedUntil
+	}
+	return time.Time{}
+}
+
+func (d *DataProvider) block(until time.Time) {
+	d.blockedLock.Lock()
+	defer d.blockedLock.Unlock()
+	if until.After(d.blockedUntil) {
+		d.blockedUntil = until
+	}
+}
+
+// requestParams returns the parameters of the template: the request metadata, overridden by the fields of the data
+// if it's a JSON object.
+func requestParams(req *bindings.InvokeRequest) (map[string]string, error) {
+	params := make(map[string]string, len(req.Metadata))
+	for k, v := range req.Metadata {
+		params[k] = v
+	}
+	if len(req.Data) == 0 {
+		return params, nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(req.Data, &data); err != nil {
+		return nil, fmt.Errorf("the data must be a JSON object with the parameters: %w", err)
+	}
+	for k, v := range data {
+		switch val := v.(type) {
+		case string:
+			params[k] = val
+		case float64:
+			params[k] = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			params[k] = strconv.FormatBool(val)
+		case nil:
+			params[k] = ""
+		default:
+			return nil, fmt.Errorf("the parameter %s must be a string, a number or a boolean", k)
+		}
+	}
+	return params, nil
+}
+
+// expandTemplate replaces the {name} placeholders with the parameters, escaped as path segments before the query
+// and as query values after it.
+func expandTemplate(tmpl string, params map[string]string) (string, error) {
+	var (
+		b       strings.Builder
+		inQuery bool
+	)
+	for {
+		start := strings.IndexAny(tmpl, "{?")
+		if start < 0 {
+			b.WriteString(tmpl)
+			return b.String(), nil
+		}
+		b.WriteString(tmpl[:start])
+		if tmpl[start] == '?' {
+			inQuery = true
+			b.WriteByte('?')
+			tmpl = tmpl[start+1:]
+			continue
+		}
+
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %q", tmpl)
+		}
+		name := tmpl[start+1 : start+end]
+		val, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("missing parameter %s", name)
+		}
+		if inQuery {
+			b.WriteString(url.QueryEscape(val))
+		} else {
+			b.WriteString(url.PathEscape(val))
+		}
+		tmpl = tmpl[start+end+1:]
+	}
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(val string) (time.Time, bool) {
+	if val == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(val); err == nil && seconds >= 0 {
+		return time.Now().Add(time.Duration(seconds) * time.Second), true
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func hashURL(u string) string {
+	h := sha256.Sum256([]byte(u))
+	return hex.EncodeToString(h[:])
+}
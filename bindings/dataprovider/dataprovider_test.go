@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+)
+
+func newTestProvider(t *testing.T, store state.Store, properties map[string]string) *DataProvider {
+	t.Helper()
+	d := NewDataProvider(logger.NewLogger("test"), store).(*DataProvider)
+	require.NoError(t, d.Init(bindings.Metadata{Base: metadata.Base{Properties: properties}}))
+	return d
+}
+
+func newTestStore(t *testing.T) state.Store {
+	t.Helper()
+	store := inmemory.NewInMemoryStateStore(logger.NewLogger("test"))
+	require.NoError(t, store.Init(state.Metadata{}))
+	return store
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties map[string]string
+		store      state.Store
+		err        string
+	}{
+		{"missing baseURL", map[string]string{}, nil, "invalid baseURL"},
+		{"invalid authType", map[string]string{"baseURL": "https://api.example.com", "authType": "oauth"}, nil, "invalid authType"},
+		{"apiKey without key", map[string]string{"baseURL": "https://api.example.com", "authType": "apiKey"}, nil, "apiKey is required"},
+		{"cache without store", map[string]string{"baseURL": "https://api.example.com", "cacheTTL": "5m"}, nil, "requires a state store"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDataProvider(logger.NewLogger("test"), tt.store).(*DataProvider)
+			err := d.Init(bindings.Metadata{Base: metadata.Base{Properties: tt.properties}})
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	res, err := expandTemplate("/cities/{city}/weather?q={query}&units={units}", map[string]string{
+		"city":  "São Paulo/BR",
+		"query": "a&b=c",
+		"units": "metric",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/cities/S%C3%A3o%20Paulo%2FBR/weather?q=a%26b%3Dc&units=metric", res)
+
+	_, err = expandTemplate("/weather?q={city}", map[string]string{})
+	assert.ErrorContains(t, err, "missing parameter city")
+
+	_, err = expandTemplate("/weather?q={city", map[string]string{})
+	assert.ErrorContains(t, err, "unterminated placeholder")
+}
+
+func TestInvoke(t *testing.T) {
+	var (
+		calls  atomic.Int32
+		status atomic.Int32
+	)
+	status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "/v1/weather", r.URL.Path)
+		assert.Equal(t, "key1", r.URL.Query().Get("appid"))
+		switch s := int(status.Load()); s {
+		case http.StatusOK:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"city":"` + r.URL.Query().Get("q") + `","temp":21.5}`))
+		case http.StatusTooManyRequests:
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(s)
+		default:
+			w.WriteHeader(s)
+		}
+	}))
+	defer srv.Close()
+
+	d := newTestProvider(t, newTestStore(t), map[string]string{
+		"baseURL":       srv.URL + "/v1/",
+		"pathTemplate":  "/weather?q={city}",
+		"authType":      "apiKey",
+		"apiKey":        "key1",
+		"apiKeyName":    "appid",
+		"apiKeyInQuery": "true",
+		"cacheTTL":      "1h",
+		"maxStale":      "1h",
+	})
+	get := func(t *testing.T, data string, md map[string]string) *bindings.InvokeResponse {
+		t.Helper()
+		res, err := d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation, Data: []byte(data), Metadata: md})
+		require.NoError(t, err)
+		return res
+	}
+
+	res := get(t, `{"city":"Paris"}`, nil)
+	assert.JSONEq(t, `{"city":"Paris","temp":21.5}`, string(res.Data))
+	assert.Equal(t, cacheMiss, res.Metadata[cacheKey])
+	assert.Equal(t, "200", res.Metadata[statusCodeKey])
+	assert.Equal(t, "application/json", *res.ContentType)
+
+	res = get(t, `{"city":"Paris"}`, nil)
+	assert.Equal(t, cacheHit, res.Metadata[cacheKey])
+	assert.JSONEq(t, `{"city":"Paris","temp":21.5}`, string(res.Data))
+	assert.Equal(t, int32(1), calls.Load())
+
+	res = get(t, `{"city":"Lyon"}`, nil)
+	assert.Equal(t, cacheMiss, res.Metadata[cacheKey])
+	assert.Equal(t, int32(2), calls.Load())
+
+	t.Run("stale response while the provider fails", func(t *testing.T) {
+		status.Store(http.StatusInternalServerError)
+		res := get(t, `{"city":"Paris"}`, map[string]string{noCacheKey: "true"})
+		assert.Equal(t, cacheStale, res.Metadata[cacheKey])
+		assert.JSONEq(t, `{"city":"Paris","temp":21.5}`, string(res.Data))
+
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation, Data: []byte(`{"city":"Nice"}`)})
+		assert.ErrorContains(t, err, "status 500")
+	})
+
+	t.Run("rate limited by the provider", func(t *testing.T) {
+		status.Store(http.StatusTooManyRequests)
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation, Data: []byte(`{"city":"Nice"}`)})
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.False(t, d.blocked().IsZero())
+
+		// During the Retry-After period, the provider is not called.
+		status.Store(http.StatusOK)
+		before := calls.Load()
+		_, err = d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation, Data: []byte(`{"city":"Nice"}`)})
+		assert.ErrorIs(t, err, ErrRateLimited)
+		res := get(t, `{"city":"Lyon"}`, map[string]string{noCacheKey: "true"})
+		assert.Equal(t, cacheStale, res.Metadata[cacheKey])
+		assert.Equal(t, before, calls.Load())
+	})
+}
+
+func TestInvokeWithoutCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Write([]byte(r.URL.RequestURI()))
+	}))
+	defer srv.Close()
+
+	d := newTestProvider(t, nil, map[string]string{"baseURL": srv.URL, "authType": "bearer", "apiKey": "token1"})
+	res, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.GetOperation,
+		Data:      []byte(`{"lat":48.85,"lon":2.35}`),
+		Metadata:  map[string]string{pathKey: "/forecast?lat={lat}&lon={lon}"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/forecast?lat=48.85&lon=2.35", string(res.Data))
+	assert.Equal(t, cacheMiss, res.Metadata[cacheKey])
+}
+
+func TestRetryAfter(t *testing.T) {
+	until, ok := retryAfter("120")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), until, time.Second)
+
+	until, ok = retryAfter("Wed, 21 Oct 2043 07:28:00 GMT")
+	require.True(t, ok)
+	assert.Equal(t, 2043, until.Year())
+
+	_, ok = retryAfter("soon")
+	assert.False(t, ok)
+}
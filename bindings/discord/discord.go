@@ -0,0 +1,299 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// Events received by the input binding.
+	EventMessage  = "message"
+	EventReaction = "reaction"
+
+	// keys from request's metadata.
+	channelIDKey = "channelID"
+
+	// keys from response's metadata.
+	respEventKey     = "event"
+	respGuildIDKey   = "guildID"
+	respChannelIDKey = "channelID"
+	respMessageIDKey = "messageID"
+)
+
+// Discord is a binding posting messages with a bot, and receiving the messages and reactions of the channels the bot has access to.
+type Discord struct {
+	metadata discordMetadata
+	session  *discordgo.Session
+	logger   logger.Logger
+}
+
+type discordMetadata struct {
+	BotToken string `mapstructure:"botToken"`
+	// Channel the messages are posted to, unless overridden by the request.
+	ChannelID string `mapstructure:"channelID"`
+	// Comma-separated lists of the guilds and channels events are received from. Events of all the guilds and channels are received if empty.
+	GuildIDs   string `mapstructure:"guildIDs"`
+	ChannelIDs string `mapstructure:"channelIDs"`
+	// Comma-separated list of the events received: message, reaction.
+	Events string `mapstructure:"events"`
+}
+
+// Message is the payload of the create operation. Requests with a payload that isn't a JSON object post it as the content of the message.
+type Message struct {
+	Content string                    `json:"content,omitempty"`
+	Embeds  []*discordgo.MessageEmbed `json:"embeds,omitempty"`
+	TTS     bool                      `json:"tts,omitempty"`
+}
+
+// NewDiscord returns a new Discord binding.
+func NewDiscord(logger logger.Logger) bindings.InputOutputBinding {
+	return &Discord{logger: logger}
+}
+
+// Init performs metadata parsing and creates the session.
+// The gateway, which delivers the events, is only connected to by Read.
+func (d *Discord) Init(meta bindings.Metadata) error {
+	m := discordMetadata{
+		Events: EventMessage + "," + EventReaction,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &m)
+	if err != nil {
+		return fmt.Errorf("discord binding error: %w", err)
+	}
+	if m.BotToken == "" {
+		return errors.New("discord binding error: missing botToken")
+	}
+	for _, e := range splitList(m.Events) {
+		if e != EventMessage && e != EventReaction {
+			return fmt.Errorf("discord binding error: event %q is not one of: message, reaction", e)
+		}
+	}
+	d.metadata = m
+
+	d.session, err = discordgo.New("Bot " + m.BotToken)
+	if err != nil {
+		return fmt.Errorf("discord binding error: %w", err)
+	}
+	d.session.Identify.Intents = m.intents()
+
+	return nil
+}
+
+// intents returns the gateway intents required to receive the configured events.
+func (m discordMetadata) intents() discordgo.Intent {
+	var intents discordgo.Intent
+	for _, e := range splitList(m.Events) {
+		switch e {
+		case EventMessage:
+			intents |= discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages | discordgo.IntentMessageContent
+		case EventReaction:
+			intents |= discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessageReactions
+		}
+	}
+	return intents
+}
+
+// Operations returns list of operations supported by the Discord binding.
+func (d *Discord) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation}
+}
+
+// Invoke posts a message to a channel.
+func (d *Discord) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != bindings.CreateOperation {
+		return nil, fmt.Errorf("discord binding error: unsupported operation %s", req.Operation)
+	}
+
+	channelID := d.metadata.ChannelID
+	if c := req.Metadata[channelIDKey]; c != "" {
+		channelID = c
+	}
+	if channelID == "" {
+		return nil, fmt.Errorf("discord binding error: required metadata %q not set", channelIDKey)
+	}
+
+	msg, err := parseMessage(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("discord binding error: %w", err)
+	}
+
+	sent, err := d.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: msg.Content,
+		Embeds:  msg.Embeds,
+		TTS:     msg.TTS,
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("discord binding error: failed to post message to channel %s: %w", channelID, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			respChannelIDKey:                   sent.ChannelID,
+			respMessageIDKey:                   sent.ID,
+		},
+	}, nil
+}
+
+// OperationsMetadata describes the operations of the Discord binding.
+func (d *Discord) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        bindings.CreateOperation,
+			Description:      "Posts a message to a channel. The data is either the content of the message, or a JSON object with content, embeds and tts.",
+			RequestMetadata:  []string{channelIDKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respChannelIDKey, respMessageIDKey},
+		},
+	}
+}
+
+// parseMessage parses the payload of a create request.
+func parseMessage(data []byte) (Message, error) {
+	var msg Message
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return msg, fmt.Errorf("invalid message: %w", err)
+		}
+	} else {
+		msg.Content = string(data)
+	}
+	if msg.Content == "" && len(msg.Embeds) == 0 {
+		return msg, errors.New("message has no content nor embeds")
+	}
+	return msg, nil
+}
+
+// Read connects to the gateway and receives the events of the configured guilds and channels.
+// Messages posted by the bot itself are ignored.
+func (d *Discord) Read(ctx context.Context, handler bindings.Handler) error {
+	filter := newEventFilter(d.metadata)
+
+	for _, e := range splitList(d.metadata.Events) {
+		switch e {
+		case EventMessage:
+			d.session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+				if m.Author != nil && s.State != nil && s.State.User != nil && m.Author.ID == s.State.User.ID {
+					return
+				}
+				if filter.match(m.GuildID, m.ChannelID) {
+					d.emit(ctx, handler, EventMessage, m.GuildID, m.ChannelID, m.ID, m.Message)
+				}
+			})
+		case EventReaction:
+			d.session.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+				if filter.match(r.GuildID, r.ChannelID) {
+					d.emit(ctx, handler, EventReaction, r.GuildID, r.ChannelID, r.MessageID, r)
+				}
+			})
+		}
+	}
+
+	err := d.session.Open()
+	if err != nil {
+		return fmt.Errorf("discord binding error: failed to connect to the gateway: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		d.session.Close()
+	}()
+
+	return nil
+}
+
+func (d *Discord) emit(ctx context.Context, handler bindings.Handler, event, guildID, channelID, messageID string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		d.logger.Errorf("discord binding error: failed to serialize %s event: %v", event, err)
+		return
+	}
+
+	_, err = handler(ctx, &bindings.ReadResponse{
+		Data: data,
+		Metadata: map[string]string{
+			respEventKey:     event,
+			respGuildIDKey:   guildID,
+			respChannelIDKey: channelID,
+			respMessageIDKey: messageID,
+		},
+	})
+	if err != nil {
+		d.logger.Errorf("discord binding error: failed to handle %s event: %v", event, err)
+	}
+}
+
+// eventFilter matches the events of the configured guilds and channels.
+type eventFilter struct {
+	guilds   map[string]struct{}
+	channels map[string]struct{}
+}
+
+func newEventFilter(m discordMetadata) eventFilter {
+	return eventFilter{
+		guilds:   toSet(splitList(m.GuildIDs)),
+		channels: toSet(splitList(m.ChannelIDs)),
+	}
+}
+
+func (f eventFilter) match(guildID, channelID string) bool {
+	if len(f.guilds) > 0 {
+		if _, ok := f.guilds[guildID]; !ok {
+			return false
+		}
+	}
+	if len(f.channels) > 0 {
+		if _, ok := f.channels[channelID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Close disconnects from the gateway.
+func (d *Discord) Close() error {
+	if d.session == nil {
+		return nil
+	}
+	return d.session.Close()
+}
+
+func splitList(val string) []string {
+	var res []string
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
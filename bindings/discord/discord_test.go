@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func initDiscord(t *testing.T, props map[string]string) (*Discord, error) {
+	t.Helper()
+
+	d := NewDiscord(logger.NewLogger("test")).(*Discord)
+	err := d.Init(bindings.Metadata{Base: metadata.Base{Properties: props}})
+	return d, err
+}
+
+func TestInit(t *testing.T) {
+	d, err := initDiscord(t, map[string]string{"botToken": "token"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bot token", d.session.Token)
+	assert.NotZero(t, d.session.Identify.Intents&discordgo.IntentsGuildMessages)
+	assert.NotZero(t, d.session.Identify.Intents&discordgo.IntentsGuildMessageReactions)
+
+	d, err = initDiscord(t, map[string]string{"botToken": "token", "events": "reaction"})
+	require.NoError(t, err)
+	assert.Zero(t, d.session.Identify.Intents&discordgo.IntentsGuildMessages)
+
+	_, err = initDiscord(t, map[string]string{})
+	assert.Error(t, err)
+	_, err = initDiscord(t, map[string]string{"botToken": "token", "events": "typing"})
+	assert.Error(t, err)
+}
+
+func TestParseMessage(t *testing.T) {
+	msg, err := parseMessage([]byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", msg.Content)
+
+	msg, err = parseMessage([]byte(`{"embeds": [{"title": "Build failed"}]}`))
+	require.NoError(t, err)
+	require.Len(t, msg.Embeds, 1)
+	assert.Equal(t, "Build failed", msg.Embeds[0].Title)
+
+	_, err = parseMessage([]byte(`{"content": `))
+	assert.Error(t, err)
+	_, err = parseMessage(nil)
+	assert.Error(t, err)
+}
+
+func TestEventFilter(t *testing.T) {
+	f := newEventFilter(discordMetadata{})
+	assert.True(t, f.match("g1", "c1"))
+
+	f = newEventFilter(discordMetadata{GuildIDs: "g1, g2", ChannelIDs: "c1"})
+	assert.True(t, f.match("g2", "c1"))
+	assert.False(t, f.match("g3", "c1"))
+	assert.False(t, f.match("g1", "c2"))
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (r rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestInvoke(t *testing.T) {
+	var received discordgo.MessageSend
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v9/channels/c2/messages", r.URL.Path)
+		assert.Equal(t, "Bot token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "m1", "channel_id": "c2"}`))
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+
+	d, err := initDiscord(t, map[string]string{"botToken": "token", "channelID": "c1"})
+	require.NoError(t, err)
+	d.session.Client = &http.Client{Transport: rewriteTransport{target: target}}
+
+	res, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("hello"),
+		Metadata:  map[string]string{channelIDKey: "c2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", received.Content)
+	assert.Equal(t, "m1", res.Metadata[respMessageIDKey])
+	assert.Equal(t, "c2", res.Metadata[respChannelIDKey])
+
+	_, err = d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.DeleteOperation})
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	gdns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// cloudDNS is the provider of Google Cloud DNS managed zones.
+type cloudDNS struct {
+	service *gdns.Service
+	project string
+}
+
+func newCloudDNS(ctx context.Context, m dnsMetadata, opts ...option.ClientOption) (*cloudDNS, error) {
+	if m.ProjectID == "" {
+		return nil, errors.New("project_id is required")
+	}
+	if m.PrivateKey != "" {
+		credentials, err := json.Marshal(map[string]string{
+			"type":                        m.Type,
+			"project_id":                  m.ProjectID,
+			"private_key_id":              m.PrivateKeyID,
+			"private_key":                 m.PrivateKey,
+			"client_email":                m.ClientEmail,
+			"client_id":                   m.ClientID,
+			"auth_uri":                    m.AuthURI,
+			"token_uri":                   m.TokenURI,
+			"auth_provider_x509_cert_url": m.AuthProviderCertURL,
+			"client_x509_cert_url":        m.ClientCertURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	}
+	opts = append(opts, option.WithScopes(gdns.NdevClouddnsReadwriteScope))
+	service, err := gdns.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudDNS{service: service, project: m.ProjectID}, nil
+}
+
+func (c *cloudDNS) create(ctx context.Context, zone string, record *Record) error {
+	_, err := c.service.ResourceRecordSets.Create(c.project, zone, toCloudDNS(record)).Context(ctx).Do()
+	return err
+}
+
+// upsert patches the record set, and creates it if it does not exist.
+func (c *cloudDNS) upsert(ctx context.Context, zone string, record *Record) error {
+	_, err := c.service.ResourceRecordSets.Patch(c.project, zone, fqdn(record.Name), record.Type, toCloudDNS(record)).Context(ctx).Do()
+	if isGoogleNotFound(err) {
+		return c.create(ctx, zone, record)
+	}
+	return err
+}
+
+func (c *cloudDNS) delete(ctx context.Context, zone string, record *Record) error {
+	_, err := c.service.ResourceRecordSets.Delete(c.project, zone, fqdn(record.Name), record.Type).Context(ctx).Do()
+	if isGoogleNotFound(err) {
+		return errRecordNotFound
+	}
+	return err
+}
+
+func (c *cloudDNS) list(ctx context.Context, zone string, name string, typ string) ([]Record, error) {
+	call := c.service.ResourceRecordSets.List(c.project, zone)
+	if name != "" {
+		call.Name(fqdn(name))
+		if typ != "" {
+			call.Type(typ)
+		}
+	}
+
+	var records []Record
+	err := call.Pages(ctx, func(res *gdns.ResourceRecordSetsListResponse) error {
+		for _, rrs := range res.Rrsets {
+			// Cloud DNS filters by type only with a name.
+			if typ != "" && rrs.Type != typ {
+				continue
+			}
+			records = append(records, Record{
+				Name:   strings.TrimSuffix(rrs.Name, "."),
+				Type:   rrs.Type,
+				TTL:    rrs.Ttl,
+				Values: unquoteTXT(rrs.Type, rrs.Rrdatas),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func toCloudDNS(record *Record) *gdns.ResourceRecordSet {
+	return &gdns.ResourceRecordSet{
+		Name:    fqdn(record.Name),
+		Type:    record.Type,
+		Ttl:     record.TTL,
+		Rrdatas: quoteTXT(record.Type, record.Values),
+	}
+}
+
+func isGoogleNotFound(err error) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == http.StatusNotFound
+}
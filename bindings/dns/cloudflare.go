@@ -0,0 +1,254 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	cloudflareBaseURL = "https://api.cloudflare.com/client/v4"
+	cloudflarePerPage = 100
+)
+
+// cloudflare is the provider of Cloudflare zones.
+// Cloudflare has a record by value, so the record sets are the records with the same name and type.
+type cloudflare struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+type cloudflareRecord struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	TTL     int64  `json:"ttl"`
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo struct {
+		Page       int `json:"page"`
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
+}
+
+func newCloudflare(m dnsMetadata) (*cloudflare, error) {
+	if m.APIToken == "" {
+		return nil, errors.New("apiToken is required")
+	}
+	return &cloudflare{baseURL: cloudflareBaseURL, token: m.APIToken, client: &http.Client{}}, nil
+}
+
+func (c *cloudflare) create(ctx context.Context, zone string, record *Record) error {
+	existing, err := c.records(ctx, zone, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("records %s %s exist", record.Type, record.Name)
+	}
+	for _, v := range record.Values {
+		if err = c.do(ctx, http.MethodPost, "/zones/"+url.PathEscape(zone)+"/dns_records", c.record(record, v), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsert keeps the records having one of the values, replaces the content of the others with the new values,
+// and creates or deletes records for the remaining values or records.
+func (c *cloudflare) upsert(ctx context.Context, zone string, record *Record) error {
+	existing, err := c.records(ctx, zone, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+
+	path := "/zones/" + url.PathEscape(zone) + "/dns_records"
+	var (
+		unused []cloudflareRecord
+		added  []string
+		kept   = make(map[string]bool, len(existing))
+	)
+	for _, v := range record.Values {
+		kept[v] = false
+	}
+	for _, r := range existing {
+		if done, ok := kept[r.Content]; ok && !done {
+			kept[r.Content] = true
+			if r.TTL != record.TTL {
+				if err = c.do(ctx, http.MethodPatch, path+"/"+url.PathEscape(r.ID), map[string]int64{"ttl": record.TTL}, nil); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		unused = append(unused, r)
+	}
+	for _, v := range record.Values {
+		if !kept[v] {
+			kept[v] = true
+			added = append(added, v)
+		}
+	}
+
+	for _, v := range added {
+		if len(unused) > 0 {
+			err = c.do(ctx, http.MethodPut, path+"/"+url.PathEscape(unused[0].ID), c.record(record, v), nil)
+			unused = unused[1:]
+		} else {
+			err = c.do(ctx, http.MethodPost, path, c.record(record, v), nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range unused {
+		if err = c.do(ctx, http.MethodDelete, path+"/"+url.PathEscape(r.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cloudflare) delete(ctx context.Context, zone string, record *Record) error {
+	existing, err := c.records(ctx, zone, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return errRecordNotFound
+	}
+	for _, r := range existing {
+		if err = c.do(ctx, http.MethodDelete, "/zones/"+url.PathEscape(zone)+"/dns_records/"+url.PathEscape(r.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list groups the records by name and type, in the order of the first record of each group.
+func (c *cloudflare) list(ctx context.Context, zone string, name string, typ string) ([]Record, error) {
+	existing, err := c.records(ctx, zone, name, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	index := make(map[string]int)
+	for _, r := range existing {
+		key := r.Type + " " + r.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(records)
+			index[key] = i
+			records = append(records, Record{Name: r.Name, Type: r.Type, TTL: r.TTL})
+		}
+		records[i].Values = append(records[i].Values, r.Content)
+	}
+	return records, nil
+}
+
+// records returns the records of the zone with the name and the type, if not empty.
+func (c *cloudflare) records(ctx context.Context, zone string, name string, typ string) ([]cloudflareRecord, error) {
+	query := url.Values{"per_page": []string{strconv.Itoa(cloudflarePerPage)}}
+	if name != "" {
+		query.Set("name", name)
+	}
+	if typ != "" {
+		query.Set("type", typ)
+	}
+
+	var records []cloudflareRecord
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var (
+			res   []cloudflareRecord
+			total int
+		)
+		err := c.do(ctx, http.MethodGet, "/zones/"+url.PathEscape(zone)+"/dns_records?"+query.Encode(), nil, func(r *cloudflareResponse) error {
+			total = r.ResultInfo.TotalPages
+			return json.Unmarshal(r.Result, &res)
+		})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, res...)
+		if page >= total {
+			return records, nil
+		}
+	}
+}
+
+func (c *cloudflare) record(record *Record, value string) cloudflareRecord {
+	return cloudflareRecord{Name: record.Name, Type: record.Type, Content: value, TTL: record.TTL}
+}
+
+// do sends the request with the body encoded as JSON, and passes the response to parse if not nil.
+func (c *cloudflare) do(ctx context.Context, method string, path string, body interface{}, parse func(*cloudflareResponse) error) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res cloudflareResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("invalid response with status %d: %w", resp.StatusCode, err)
+	}
+	if !res.Success || resp.StatusCode >= http.StatusBadRequest {
+		msgs := make([]string, len(res.Errors))
+		for i, e := range res.Errors {
+			msgs[i] = fmt.Sprintf("%s (%d)", e.Message, e.Code)
+		}
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.Join(msgs, ", "))
+	}
+	if parse != nil {
+		return parse(&res)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	defaultTTL     = 300
+
+	// Values of provider.
+	providerRoute53    = "route53"
+	providerCloudDNS   = "clouddns"
+	providerCloudflare = "cloudflare"
+
+	// keys from request's metadata.
+	zoneKey = "zone"
+
+	CreateOperation bindings.OperationKind = "create"
+	UpsertOperation bindings.OperationKind = "upsert"
+	DeleteOperation bindings.OperationKind = "delete"
+	ListOperation   bindings.OperationKind = "list"
+)
+
+// DNS is an output binding managing the records of a DNS zone hosted by Route53, Google Cloud DNS or Cloudflare.
+type DNS struct {
+	metadata dnsMetadata
+	provider provider
+	logger   logger.Logger
+}
+
+type dnsMetadata struct {
+	// Provider is "route53", "clouddns" or "cloudflare".
+	Provider string        `mapstructure:"provider"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	// Zone is the default zone: the ID of the Route53 hosted zone, the name of the Cloud DNS managed zone or the ID of the Cloudflare zone.
+	Zone string `mapstructure:"zone"`
+	// TTL is the TTL in seconds of the records without one.
+	TTL int64 `mapstructure:"ttl"`
+
+	// Route53: the AWS credentials, as in the other AWS components.
+	Region       string `mapstructure:"region"`
+	AccessKey    string `mapstructure:"accessKey"`
+	SecretKey    string `mapstructure:"secretKey"`
+	SessionToken string `mapstructure:"sessionToken"`
+	Endpoint     string `mapstructure:"endpoint"`
+
+	// Cloud DNS: the project of the managed zones, and the service account key fields, as in the other GCP components.
+	ProjectID           string `mapstructure:"project_id"`
+	Type                string `mapstructure:"type"`
+	PrivateKeyID        string `mapstructure:"private_key_id"`
+	PrivateKey          string `mapstructure:"private_key"`
+	ClientEmail         string `mapstructure:"client_email"`
+	ClientID            string `mapstructure:"client_id"`
+	AuthURI             string `mapstructure:"auth_uri"`
+	TokenURI            string `mapstructure:"token_uri"`
+	AuthProviderCertURL string `mapstructure:"auth_provider_x509_cert_url"`
+	ClientCertURL       string `mapstructure:"client_x509_cert_url"`
+
+	// Cloudflare: the API token, with the DNS edit permission on the zone.
+	APIToken string `mapstructure:"apiToken"`
+}
+
+// Record is a DNS record set: all the values of the records with a name and a type.
+type Record struct {
+	// Name is the fully qualified domain name of the records, with or without the trailing dot.
+	Name string `json:"name"`
+	// Type is the type of the records, such as A, AAAA, CNAME or TXT.
+	Type string `json:"type"`
+	// TTL is the TTL in seconds. The ttl metadata if zero.
+	TTL int64 `json:"ttl,omitempty"`
+	// Values are the data of the records. TXT values are the text, without the quotes.
+	Values []string `json:"values,omitempty"`
+}
+
+// provider is the API of a DNS service.
+type provider interface {
+	// create creates the records, and fails if records with the name and the type exist.
+	create(ctx context.Context, zone string, record *Record) error
+	// upsert creates the records, or replaces the existing ones with the name and the type.
+	upsert(ctx context.Context, zone string, record *Record) error
+	// delete deletes the records with the name and the type.
+	delete(ctx context.Context, zone string, record *Record) error
+	// list returns the records of the zone, filtered by the name and the type if not empty.
+	list(ctx context.Context, zone string, name string, typ string) ([]Record, error)
+}
+
+// NewDNS returns a new DNS binding.
+func NewDNS(logger logger.Logger) bindings.OutputBinding {
+	return &DNS{logger: logger}
+}
+
+// Init parses the metadata and creates the client of the provider.
+func (d *DNS) Init(meta bindings.Metadata) error {
+	d.metadata = dnsMetadata{Timeout: defaultTimeout, TTL: defaultTTL}
+	err := metadata.DecodeMetadata(meta.Properties, &d.metadata)
+	if err != nil {
+		return fmt.Errorf("dns binding error: %w", err)
+	}
+	if d.metadata.TTL <= 0 {
+		return errors.New("dns binding error: ttl must be positive")
+	}
+
+	switch d.metadata.Provider {
+	case providerRoute53:
+		d.provider, err = newRoute53(d.metadata)
+	case providerCloudDNS:
+		d.provider, err = newCloudDNS(context.Background(), d.metadata)
+	case providerCloudflare:
+		d.provider, err = newCloudflare(d.metadata)
+	default:
+		return fmt.Errorf("dns binding error: invalid provider %q: must be %s, %s or %s", d.metadata.Provider, providerRoute53, providerCloudDNS, providerCloudflare)
+	}
+	if err != nil {
+		return fmt.Errorf("dns binding error: %w", err)
+	}
+	return nil
+}
+
+// Operations returns the operations supported by the DNS binding.
+func (d *DNS) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{CreateOperation, UpsertOperation, DeleteOperation, ListOperation}
+}
+
+// Invoke runs the operation of the request.
+func (d *DNS) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	zone := d.metadata.Zone
+	if z := req.Metadata[zoneKey]; z != "" {
+		zone = z
+	}
+	if zone == "" {
+		return nil, fmt.Errorf("dns binding error: the %s metadata is required", zoneKey)
+	}
+
+	var record Record
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &record); err != nil {
+			return nil, fmt.Errorf("dns binding error: invalid record: %w", err)
+		}
+	}
+	record.Name = strings.TrimSuffix(record.Name, ".")
+	record.Type = strings.ToUpper(record.Type)
+	if record.TTL == 0 {
+		record.TTL = d.metadata.TTL
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.metadata.Timeout)
+	defer cancel()
+
+	var (
+		res interface{}
+		err error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case CreateOperation, UpsertOperation:
+		if record.Name == "" || record.Type == "" || len(record.Values) == 0 {
+			return nil, errors.New("dns binding error: the name, type and values of the record are required")
+		}
+		if req.Operation == CreateOperation {
+			err = d.provider.create(ctx, zone, &record)
+		} else {
+			err = d.provider.upsert(ctx, zone, &record)
+		}
+		res = record
+	case DeleteOperation:
+		if record.Name == "" || record.Type == "" {
+			return nil, errors.New("dns binding error: the name and type of the record are required")
+		}
+		err = d.provider.delete(ctx, zone, &record)
+	case ListOperation:
+		var records []Record
+		records, err = d.provider.list(ctx, zone, record.Name, record.Type)
+		if records == nil {
+			records = []Record{}
+		}
+		res = records
+	default:
+		return nil, fmt.Errorf("dns binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dns binding error: %s failed: %w", req.Operation, err)
+	}
+
+	resp := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}
+	if res != nil {
+		resp.Data, err = json.Marshal(res)
+		if err != nil {
+			return nil, fmt.Errorf("dns binding error: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// OperationsMetadata describes the operations of the DNS binding.
+func (d *DNS) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        CreateOperation,
+			Description:      "Creates the record set in the data. Fails if records with its name and type exist.",
+			RequestMetadata:  []string{zoneKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        UpsertOperation,
+			Description:      "Creates the record set in the data, or replaces the records with its name and type.",
+			RequestMetadata:  []string{zoneKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        DeleteOperation,
+			Description:      "Deletes the records with the name and type in the data.",
+			RequestMetadata:  []string{zoneKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        ListOperation,
+			Description:      "Returns the record sets of the zone, filtered by the name and type in the data if set.",
+			RequestMetadata:  []string{zoneKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
+
+// fqdn returns the name with the trailing dot.
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+// quoteTXT returns the TXT values as the quoted strings of the zone file format, used by Route53 and Cloud DNS.
+// Values longer than 255 characters are split in several strings.
+func quoteTXT(typ string, values []string) []string {
+	if typ != "TXT" {
+		return values
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		var b strings.Builder
+		for {
+			chunk := v
+			if len(chunk) > 255 {
+				chunk = chunk[:255]
+			}
+			v = v[len(chunk):]
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteByte('"')
+			for _, c := range []byte(chunk) {
+				if c == '"' || c == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteByte(c)
+			}
+			b.WriteByte('"')
+			if v == "" {
+				break
+			}
+		}
+		quoted[i] = b.String()
+	}
+	return quoted
+}
+
+// unquoteTXT returns the text of TXT values in the zone file format, concatenating their strings.
+func unquoteTXT(typ string, values []string) []string {
+	if typ != "TXT" {
+		return values
+	}
+	texts := make([]string, len(values))
+	for i, v := range values {
+		var (
+			b       strings.Builder
+			quoted  bool
+			escaped bool
+		)
+		for _, c := range []byte(v) {
+			switch {
+			case escaped:
+				b.WriteByte(c)
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				quoted = !quoted
+			case quoted || c != ' ':
+				b.WriteByte(c)
+			}
+		}
+		texts[i] = b.String()
+	}
+	return texts
+}
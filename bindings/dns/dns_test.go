@@ -0,0 +1,294 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsrequest "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInit(t *testing.T) {
+	t.Run("invalid provider", func(t *testing.T) {
+		d := NewDNS(logger.NewLogger("test")).(*DNS)
+		err := d.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "bind"}}})
+		assert.ErrorContains(t, err, "invalid provider")
+	})
+
+	t.Run("cloudflare without token", func(t *testing.T) {
+		d := NewDNS(logger.NewLogger("test")).(*DNS)
+		err := d.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "cloudflare"}}})
+		assert.ErrorContains(t, err, "apiToken is required")
+	})
+
+	t.Run("cloudflare", func(t *testing.T) {
+		d := NewDNS(logger.NewLogger("test")).(*DNS)
+		err := d.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"provider": "cloudflare",
+			"apiToken": "token",
+			"zone":     "zone1",
+			"ttl":      "60",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, "zone1", d.metadata.Zone)
+		assert.Equal(t, int64(60), d.metadata.TTL)
+	})
+}
+
+func TestInvokeValidation(t *testing.T) {
+	d := &DNS{metadata: dnsMetadata{TTL: defaultTTL, Timeout: defaultTimeout}, provider: &route53Provider{client: &fakeRoute53{}}}
+
+	_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: ListOperation})
+	assert.ErrorContains(t, err, "the zone metadata is required")
+
+	_, err = d.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: CreateOperation,
+		Data:      []byte(`{"name":"www.example.com","type":"A"}`),
+		Metadata:  map[string]string{zoneKey: "Z1"},
+	})
+	assert.ErrorContains(t, err, "the name, type and values of the record are required")
+
+	_, err = d.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: DeleteOperation,
+		Data:      []byte(`{"name":"www.example.com"}`),
+		Metadata:  map[string]string{zoneKey: "Z1"},
+	})
+	assert.ErrorContains(t, err, "the name and type of the record are required")
+}
+
+func TestTXT(t *testing.T) {
+	long := string(make([]byte, 300))
+	quoted := quoteTXT("TXT", []string{`v=spf1 include:"x" \ -all`, long})
+	assert.Equal(t, `"v=spf1 include:\"x\" \\ -all"`, quoted[0])
+	assert.Len(t, quoted[1], 300+5)
+	assert.Equal(t, []string{`v=spf1 include:"x" \ -all`, long}, unquoteTXT("TXT", quoted))
+
+	assert.Equal(t, []string{"1.2.3.4"}, quoteTXT("A", []string{"1.2.3.4"}))
+}
+
+func TestRoute53Name(t *testing.T) {
+	assert.Equal(t, "*.example.com", route53Name(`\052.example.com.`))
+	assert.Equal(t, "www.example.com", route53Name("www.example.com."))
+}
+
+type fakeRoute53 struct {
+	route53iface.Route53API
+
+	changes []*route53.Change
+	sets    []*route53.ResourceRecordSet
+}
+
+func (f *fakeRoute53) ChangeResourceRecordSetsWithContext(_ aws.Context, input *route53.ChangeResourceRecordSetsInput, _ ...awsrequest.Option) (*route53.ChangeResourceRecordSetsOutput, error) {
+	f.changes = append(f.changes, input.ChangeBatch.Changes...)
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func (f *fakeRoute53) ListResourceRecordSetsWithContext(_ aws.Context, input *route53.ListResourceRecordSetsInput, _ ...awsrequest.Option) (*route53.ListResourceRecordSetsOutput, error) {
+	var sets []*route53.ResourceRecordSet
+	for _, s := range f.sets {
+		if aws.StringValue(s.Name) >= aws.StringValue(input.StartRecordName) {
+			sets = append(sets, s)
+		}
+	}
+	return &route53.ListResourceRecordSetsOutput{ResourceRecordSets: sets}, nil
+}
+
+func TestRoute53(t *testing.T) {
+	fake := &fakeRoute53{sets: []*route53.ResourceRecordSet{
+		{Name: aws.String("a.example.com."), Type: aws.String("A"), TTL: aws.Int64(60), ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("1.1.1.1")}}},
+		{Name: aws.String("txt.example.com."), Type: aws.String("A"), TTL: aws.Int64(60), ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("2.2.2.2")}}},
+		{Name: aws.String("txt.example.com."), Type: aws.String("TXT"), TTL: aws.Int64(120), ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(`"token"`)}}},
+		{Name: aws.String("z.example.com."), Type: aws.String("TXT"), TTL: aws.Int64(300), ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(`"other"`)}}},
+	}}
+	d := &DNS{metadata: dnsMetadata{Zone: "Z1", TTL: defaultTTL, Timeout: defaultTimeout}, provider: &route53Provider{client: fake}}
+
+	t.Run("upsert", func(t *testing.T) {
+		resp, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: UpsertOperation,
+			Data:      []byte(`{"name":"_acme-challenge.example.com","type":"txt","values":["abc"]}`),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"_acme-challenge.example.com","type":"TXT","ttl":300,"values":["abc"]}`, string(resp.Data))
+		require.Len(t, fake.changes, 1)
+		assert.Equal(t, route53.ChangeActionUpsert, *fake.changes[0].Action)
+		assert.Equal(t, "_acme-challenge.example.com.", *fake.changes[0].ResourceRecordSet.Name)
+		assert.Equal(t, `"abc"`, *fake.changes[0].ResourceRecordSet.ResourceRecords[0].Value)
+	})
+
+	t.Run("list by name and type", func(t *testing.T) {
+		resp, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ListOperation,
+			Data:      []byte(`{"name":"txt.example.com.","type":"TXT"}`),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"name":"txt.example.com","type":"TXT","ttl":120,"values":["token"]}]`, string(resp.Data))
+	})
+
+	t.Run("delete reads the record", func(t *testing.T) {
+		fake.changes = nil
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: DeleteOperation,
+			Data:      []byte(`{"name":"txt.example.com","type":"TXT"}`),
+		})
+		require.NoError(t, err)
+		require.Len(t, fake.changes, 1)
+		assert.Equal(t, route53.ChangeActionDelete, *fake.changes[0].Action)
+		assert.Equal(t, int64(120), *fake.changes[0].ResourceRecordSet.TTL)
+		assert.Equal(t, `"token"`, *fake.changes[0].ResourceRecordSet.ResourceRecords[0].Value)
+	})
+
+	t.Run("delete missing record", func(t *testing.T) {
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: DeleteOperation,
+			Data:      []byte(`{"name":"missing.example.com","type":"A"}`),
+		})
+		assert.ErrorIs(t, err, errRecordNotFound)
+	})
+}
+
+func TestCloudDNS(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPatch:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"rrsets":[{"name":"www.example.com.","type":"A","ttl":60,"rrdatas":["1.2.3.4"]},{"name":"www.example.com.","type":"TXT","ttl":60,"rrdatas":["\"a\" \"b\""]}]}`))
+		default:
+			w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	provider, err := newCloudDNS(context.Background(), dnsMetadata{ProjectID: "project1"}, option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	d := &DNS{metadata: dnsMetadata{Zone: "zone1", TTL: defaultTTL, Timeout: defaultTimeout}, provider: provider}
+
+	_, err = d.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: UpsertOperation,
+		Data:      []byte(`{"name":"www.example.com","type":"TXT","values":["abc"]}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Contains(t, requests[0], "PATCH /dns/v1/projects/project1/managedZones/zone1/rrsets/www.example.com./TXT")
+	assert.Contains(t, requests[1], "POST /dns/v1/projects/project1/managedZones/zone1/rrsets")
+	assert.Contains(t, requests[1], `"rrdatas":["\"abc\""]`)
+
+	resp, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: ListOperation,
+		Data:      []byte(`{"type":"TXT"}`),
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name":"www.example.com","type":"TXT","ttl":60,"values":["ab"]}]`, string(resp.Data))
+}
+
+func TestCloudflare(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		lock.Unlock()
+		if r.Method == http.MethodGet {
+			records := []cloudflareRecord{
+				{ID: "1", Name: "www.example.com", Type: "A", Content: "1.1.1.1", TTL: 300},
+				{ID: "2", Name: "www.example.com", Type: "A", Content: "2.2.2.2", TTL: 300},
+				{ID: "3", Name: "www.example.com", Type: "A", Content: "3.3.3.3", TTL: 300},
+			}
+			if r.URL.Query().Get("page") == "2" {
+				records = records[2:]
+			} else {
+				records = records[:2]
+			}
+			b, _ := json.Marshal(records)
+			w.Write([]byte(`{"success":true,"errors":[],"result":` + string(b) + `,"result_info":{"page":1,"total_pages":2}}`))
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success":false,"errors":[{"code":81057,"message":"Record already exists."}],"result":null}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"errors":[],"result":{}}`))
+	}))
+	defer srv.Close()
+
+	d := &DNS{
+		metadata: dnsMetadata{Zone: "zone1", TTL: defaultTTL, Timeout: defaultTimeout},
+		provider: &cloudflare{baseURL: srv.URL, token: "token", client: srv.Client()},
+	}
+
+	t.Run("list groups the records", func(t *testing.T) {
+		requests = nil
+		resp, err := d.Invoke(context.Background(), &bindings.InvokeRequest{Operation: ListOperation})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"name":"www.example.com","type":"A","ttl":300,"values":["1.1.1.1","2.2.2.2","3.3.3.3"]}]`, string(resp.Data))
+		assert.Len(t, requests, 2)
+	})
+
+	t.Run("upsert keeps, replaces and deletes", func(t *testing.T) {
+		requests = nil
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: UpsertOperation,
+			Data:      []byte(`{"name":"www.example.com","type":"A","values":["2.2.2.2","4.4.4.4"]}`),
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 4)
+		assert.Equal(t, `PUT /zones/zone1/dns_records/1 {"name":"www.example.com","type":"A","content":"4.4.4.4","ttl":300}`, requests[2])
+		assert.Equal(t, `DELETE /zones/zone1/dns_records/3 `, requests[3])
+	})
+
+	t.Run("create fails if the records exist", func(t *testing.T) {
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CreateOperation,
+			Data:      []byte(`{"name":"www.example.com","type":"A","values":["4.4.4.4"]}`),
+		})
+		assert.ErrorContains(t, err, "records A www.example.com exist")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c := d.provider.(*cloudflare)
+		err := c.do(context.Background(), http.MethodPost, "/zones/zone1/dns_records", c.record(&Record{Name: "a", Type: "A", TTL: 1}, "1.1.1.1"), nil)
+		assert.ErrorContains(t, err, "request failed with status 400: Record already exists. (81057)")
+	})
+}
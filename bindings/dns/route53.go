@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+
+	awsAuth "github.com/dapr/components-contrib/internal/authentication/aws"
+)
+
+// Route53 is a global service, signed with the us-east-1 region.
+const route53DefaultRegion = "us-east-1"
+
+// errRecordNotFound is returned when deleting records which do not exist.
+var errRecordNotFound = errors.New("the record does not exist")
+
+// route53Provider is the provider of Route53 hosted zones.
+type route53Provider struct {
+	client route53iface.Route53API
+}
+
+func newRoute53(m dnsMetadata) (*route53Provider, error) {
+	region := m.Region
+	if region == "" {
+		region = route53DefaultRegion
+	}
+	sess, err := awsAuth.GetClient(m.AccessKey, m.SecretKey, m.SessionToken, region, m.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &route53Provider{client: route53.New(sess)}, nil
+}
+
+func (r *route53Provider) create(ctx context.Context, zone string, record *Record) error {
+	return r.change(ctx, zone, route53.ChangeActionCreate, record)
+}
+
+func (r *route53Provider) upsert(ctx context.Context, zone string, record *Record) error {
+	return r.change(ctx, zone, route53.ChangeActionUpsert, record)
+}
+
+// delete deletes the records. Route53 deletes a record set only if its TTL and values match, so they are read first.
+func (r *route53Provider) delete(ctx context.Context, zone string, record *Record) error {
+	existing, err := r.list(ctx, zone, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return errRecordNotFound
+	}
+	return r.change(ctx, zone, route53.ChangeActionDelete, &existing[0])
+}
+
+func (r *route53Provider) change(ctx context.Context, zone string, action string, record *Record) error {
+	values := quoteTXT(record.Type, record.Values)
+	rrs := make([]*route53.ResourceRecord, len(values))
+	for i, v := range values {
+		rrs[i] = &route53.ResourceRecord{Value: aws.String(v)}
+	}
+	_, err := r.client.ChangeResourceRecordSetsWithContext(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zone),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{{
+				Action: aws.String(action),
+				ResourceRecordSet: &route53.ResourceRecordSet{
+					Name:            aws.String(fqdn(record.Name)),
+					Type:            aws.String(record.Type),
+					TTL:             aws.Int64(record.TTL),
+					ResourceRecords: rrs,
+				},
+			}},
+		},
+	})
+	return err
+}
+
+// list returns the records of the zone. Route53 lists the record sets in the order of their names and types from a start,
+// so the listing stops after the records with the name and the type.
+func (r *route53Provider) list(ctx context.Context, zone string, name string, typ string) ([]Record, error) {
+	input := &route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(zone)}
+	if name != "" {
+		input.StartRecordName = aws.String(fqdn(name))
+		if typ != "" {
+			input.StartRecordType = aws.String(typ)
+		}
+	}
+
+	var (
+		records []Record
+		done    bool
+	)
+	for !done {
+		res, err := r.client.ListResourceRecordSetsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, rrs := range res.ResourceRecordSets {
+			record := Record{
+				Name: route53Name(aws.StringValue(rrs.Name)),
+				Type: aws.StringValue(rrs.Type),
+				TTL:  aws.Int64Value(rrs.TTL),
+			}
+			if name != "" && !strings.EqualFold(record.Name, strings.TrimSuffix(name, ".")) {
+				done = true
+				break
+			}
+			if typ != "" && record.Type != typ {
+				continue
+			}
+			values := make([]string, 0, len(rrs.ResourceRecords))
+			for _, rr := range rrs.ResourceRecords {
+				values = append(values, aws.StringValue(rr.Value))
+			}
+			record.Values = unquoteTXT(record.Type, values)
+			records = append(records, record)
+		}
+		if !aws.BoolValue(res.IsTruncated) {
+			break
+		}
+		input.StartRecordName = res.NextRecordName
+		input.StartRecordType = res.NextRecordType
+		input.StartRecordIdentifier = res.NextRecordIdentifier
+	}
+	return records, nil
+}
+
+// route53Name returns the name of a Route53 record set without the trailing dot,
+// and with the characters escaped as octal codes by Route53, such as the wildcard \052, unescaped.
+func route53Name(name string) string {
+	name = strings.TrimSuffix(name, ".")
+	if !strings.Contains(name, `\`) {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+4 <= len(name) {
+			if c, err := strconv.ParseUint(name[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
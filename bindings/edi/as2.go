@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	as2component "github.com/dapr/components-contrib/internal/component/as2"
+)
+
+type as2Deliverer struct {
+	url         string
+	contentType string
+	config      *as2component.Config
+	client      *http.Client
+}
+
+func newAS2Deliverer(m ediMetadata) (*as2Deliverer, error) {
+	config, err := m.AS2.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := "application/edi-x12"
+	if m.Standard == standardEDIFACT {
+		contentType = "application/edifact"
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+
+	return &as2Deliverer{
+		url:         m.AS2.URL,
+		contentType: contentType,
+		config:      config,
+		client: &http.Client{
+			Timeout: m.Timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+			},
+		},
+	}, nil
+}
+
+// Deliver sends the interchange as an AS2 message and returns its Message-ID.
+// When a synchronous MDN is configured, delivery only succeeds once the partner's receipt has been validated.
+func (a *as2Deliverer) Deliver(ctx context.Context, fileName string, data []byte) (string, error) {
+	result, err := a.config.Send(ctx, a.client, a.url, &as2component.Message{
+		ContentType: a.contentType,
+		FileName:    fileName,
+		Data:        data,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return result.MessageID, nil
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	standardX12     = "x12"
+	standardEDIFACT = "edifact"
+)
+
+var segmentIDRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,2}$`)
+
+// document is the JSON representation of a single EDI transaction set (X12) or message (EDIFACT).
+// Segments exclude the envelope (ISA/GS/ST and UNB/UNH), which is generated by the binding.
+type document struct {
+	// TransactionSet is the X12 transaction set identifier (e.g. "850", "810") or the EDIFACT message type (e.g. "ORDERS", "INVOIC").
+	TransactionSet string `json:"transactionSet"`
+	// ControlNumber is an optional interchange control number; one is generated when empty.
+	ControlNumber string    `json:"controlNumber,omitempty"`
+	Segments      []segment `json:"segments"`
+}
+
+// segment is an EDI segment. Each element is either a string or, for composite elements, an array of strings.
+type segment struct {
+	ID       string        `json:"id"`
+	Elements []interface{} `json:"elements"`
+}
+
+// Segments that must be present in well-known X12 transaction sets.
+var x12RequiredSegments = map[string][]string{ //nolint:gochecknoglobals
+	"810": {"BIG", "IT1", "TDS"},
+	"850": {"BEG", "PO1"},
+	"855": {"BAK"},
+	"856": {"BSN", "HL"},
+	"997": {"AK1", "AK9"},
+}
+
+// X12 functional identifier codes (GS01) for well-known transaction sets.
+var x12FunctionalGroups = map[string]string{ //nolint:gochecknoglobals
+	"810": "IN",
+	"820": "RA",
+	"846": "IB",
+	"850": "PO",
+	"855": "PR",
+	"856": "SH",
+	"940": "OW",
+	"945": "SW",
+	"997": "FA",
+}
+
+// Segments that must be present in well-known EDIFACT messages.
+var edifactRequiredSegments = map[string][]string{ //nolint:gochecknoglobals
+	"DESADV": {"BGM", "DTM"},
+	"INVOIC": {"BGM", "DTM", "MOA"},
+	"ORDERS": {"BGM", "DTM", "LIN"},
+}
+
+// envelope contains the interchange settings used to wrap a document.
+type envelope struct {
+	senderID          string
+	senderQualifier   string
+	receiverID        string
+	receiverQualifier string
+	functionalGroup   string
+	version           string
+	test              bool
+	controlNumber     int64
+	now               time.Time
+}
+
+func (d *document) validate(standard string) error {
+	if d.TransactionSet == "" {
+		return errors.New("transactionSet is required")
+	}
+	if len(d.Segments) == 0 {
+		return errors.New("at least one segment is required")
+	}
+
+	present := make(map[string]bool, len(d.Segments))
+	for i, s := range d.Segments {
+		if !segmentIDRegex.MatchString(s.ID) {
+			return fmt.Errorf("segments[%d]: invalid segment id '%s'", i, s.ID)
+		}
+		for j, e := range s.Elements {
+			switch v := e.(type) {
+			case string, nil:
+			case []interface{}:
+				for _, c := range v {
+					if _, ok := c.(string); !ok {
+						return fmt.Errorf("segments[%d].elements[%d]: composite components must be strings", i, j)
+					}
+				}
+			default:
+				return fmt.Errorf("segments[%d].elements[%d]: elements must be strings or arrays of strings", i, j)
+			}
+		}
+		present[s.ID] = true
+	}
+
+	required := x12RequiredSegments[d.TransactionSet]
+	if standard == standardEDIFACT {
+		required = edifactRequiredSegments[d.TransactionSet]
+	}
+	for _, id := range required {
+		if !present[id] {
+			return fmt.Errorf("transaction set %s requires segment %s", d.TransactionSet, id)
+		}
+	}
+
+	return nil
+}
+
+// x12 delimiters.
+const (
+	x12ElementSep   = "*"
+	x12ComponentSep = ":"
+	x12RepeatSep    = "^"
+	x12SegmentTerm  = "~"
+)
+
+// toX12 renders the document as an X12 interchange with a single functional group and transaction set.
+func (d *document) toX12(env envelope) (string, error) {
+	functionalGroup := env.functionalGroup
+	if functionalGroup == "" {
+		functionalGroup = x12FunctionalGroups[d.TransactionSet]
+	}
+	if functionalGroup == "" {
+		return "", fmt.Errorf("functionalGroup must be configured for transaction set %s", d.TransactionSet)
+	}
+
+	usage := "P"
+	if env.test {
+		usage = "T"
+	}
+	icn := fmt.Sprintf("%09d", env.controlNumber%1_000_000_000)
+	gcn := strconv.FormatInt(env.controlNumber%1_000_000_000, 10)
+	stcn := fmt.Sprintf("%04d", env.controlNumber%10_000)
+
+	var b strings.Builder
+	writeSegment := func(id string, elements ...string) {
+		b.WriteString(id)
+		for _, e := range elements {
+			b.WriteString(x12ElementSep)
+			b.WriteString(e)
+		}
+		b.WriteString(x12SegmentTerm)
+	}
+
+	writeSegment("ISA",
+		"00", pad("", 10),
+		"00", pad("", 10),
+		pad(env.senderQualifier, 2), pad(env.senderID, 15),
+		pad(env.receiverQualifier, 2), pad(env.receiverID, 15),
+		env.now.Format("060102"), env.now.Format("1504"),
+		x12RepeatSep, "00501", icn, "0", usage, x12ComponentSep,
+	)
+	writeSegment("GS",
+		functionalGroup, env.senderID, env.receiverID,
+		env.now.Format("20060102"), env.now.Format("1504"),
+		gcn, "X", env.version,
+	)
+	writeSegment("ST", d.TransactionSet, stcn)
+	for i, s := range d.Segments {
+		elements, err := renderElements(s.Elements, x12ComponentSep, func(v string) (string, error) {
+			if strings.ContainsAny(v, x12ElementSep+x12ComponentSep+x12RepeatSep+x12SegmentTerm) {
+				return "", fmt.Errorf("segments[%d]: value '%s' contains a reserved delimiter", i, v)
+			}
+			return v, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		writeSegment(s.ID, elements...)
+	}
+	// The segment count includes the ST and SE segments
+	writeSegment("SE", strconv.Itoa(len(d.Segments)+2), stcn)
+	writeSegment("GE", "1", gcn)
+	writeSegment("IEA", "1", icn)
+
+	return b.String(), nil
+}
+
+// EDIFACT delimiters (UNA service string advice).
+const (
+	edifactComponentSep = ":"
+	edifactElementSep   = "+"
+	edifactDecimal      = "."
+	edifactRelease      = "?"
+	edifactSegmentTerm  = "'"
+	edifactUNA          = "UNA" + edifactComponentSep + edifactElementSep + edifactDecimal + edifactRelease + " " + edifactSegmentTerm
+)
+
+var edifactEscaper = strings.NewReplacer( //nolint:gochecknoglobals
+	edifactRelease, edifactRelease+edifactRelease,
+	edifactComponentSep, edifactRelease+edifactComponentSep,
+	edifactElementSep, edifactRelease+edifactElementSep,
+	edifactSegmentTerm, edifactRelease+edifactSegmentTerm,
+)
+
+// toEDIFACT renders the document as an EDIFACT interchange with a single message.
+func (d *document) toEDIFACT(env envelope) (string, error) {
+	icn := strconv.FormatInt(env.controlNumber%100_000_000_000_000, 10)
+	// The message version is "D" (draft directory) unless given explicitly, as in "D:01B"
+	version, release, ok := strings.Cut(env.version, ":")
+	if !ok {
+		version, release = "D", env.version
+	}
+
+	var b strings.Builder
+	writeSegment := func(id string, elements ...string) {
+		b.WriteString(id)
+		for _, e := range elements {
+			b.WriteString(edifactElementSep)
+			b.WriteString(e)
+		}
+		b.WriteString(edifactSegmentTerm)
+	}
+
+	b.WriteString(edifactUNA)
+	unb := []string{
+		"UNOC:3",
+		qualified(env.senderID, env.senderQualifier),
+		qualified(env.receiverID, env.receiverQualifier),
+		env.now.Format("060102") + edifactComponentSep + env.now.Format("1504"),
+		icn,
+	}
+	if env.test {
+		// UNB0035 test indicator, after the empty recipient reference, application reference, priority, acknowledgement request and agreement ID
+		unb = append(unb, "", "", "", "", "", "1")
+	}
+	writeSegment("UNB", unb...)
+	writeSegment("UNH", "1", strings.Join([]string{d.TransactionSet, version, release, "UN"}, edifactComponentSep))
+	for _, s := range d.Segments {
+		elements, err := renderElements(s.Elements, edifactComponentSep, func(v string) (string, error) {
+			return edifactEscaper.Replace(v), nil
+		})
+		if err != nil {
+			return "", err
+		}
+		writeSegment(s.ID, elements...)
+	}
+	// The segment count includes the UNH and UNT segments
+	writeSegment("UNT", strconv.Itoa(len(d.Segments)+2), "1")
+	writeSegment("UNZ", "1", icn)
+
+	return b.String(), nil
+}
+
+func renderElements(elements []interface{}, componentSep string, escape func(string) (string, error)) ([]string, error) {
+	res := make([]string, len(elements))
+	for i, e := range elements {
+		switch v := e.(type) {
+		case nil:
+		case string:
+			s, err := escape(v)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = s
+		case []interface{}:
+			components := make([]string, len(v))
+			for j, c := range v {
+				s, err := escape(c.(string))
+				if err != nil {
+					return nil, err
+				}
+				components[j] = s
+			}
+			res[i] = strings.Join(components, componentSep)
+		}
+	}
+
+	// Trailing empty elements are omitted
+	for len(res) > 0 && res[len(res)-1] == "" {
+		res = res[:len(res)-1]
+	}
+	return res, nil
+}
+
+func qualified(id, qualifier string) string {
+	if qualifier == "" {
+		return id
+	}
+	return id + edifactComponentSep + qualifier
+}
+
+func pad(s string, n int) string {
+	if len(s) >= n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnvelope() envelope {
+	return envelope{
+		senderID:          "SENDER",
+		senderQualifier:   "ZZ",
+		receiverID:        "RECEIVER",
+		receiverQualifier: "01",
+		version:           "005010",
+		controlNumber:     42,
+		now:               time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid 850", func(t *testing.T) {
+		doc := document{
+			TransactionSet: "850",
+			Segments: []segment{
+				{ID: "BEG", Elements: []interface{}{"00", "SA", "PO123"}},
+				{ID: "PO1", Elements: []interface{}{"1", "10", "EA"}},
+			},
+		}
+		assert.NoError(t, doc.validate(standardX12))
+	})
+
+	t.Run("missing required segment", func(t *testing.T) {
+		doc := document{
+			TransactionSet: "850",
+			Segments:       []segment{{ID: "BEG", Elements: []interface{}{"00"}}},
+		}
+		assert.ErrorContains(t, doc.validate(standardX12), "requires segment PO1")
+	})
+
+	t.Run("invalid segment id", func(t *testing.T) {
+		doc := document{TransactionSet: "999", Segments: []segment{{ID: "beg"}}}
+		assert.Error(t, doc.validate(standardX12))
+	})
+
+	t.Run("invalid element type", func(t *testing.T) {
+		doc := document{TransactionSet: "999", Segments: []segment{{ID: "REF", Elements: []interface{}{float64(1)}}}}
+		assert.Error(t, doc.validate(standardX12))
+	})
+
+	t.Run("no segments", func(t *testing.T) {
+		doc := document{TransactionSet: "850"}
+		assert.Error(t, doc.validate(standardX12))
+	})
+}
+
+func TestToX12(t *testing.T) {
+	doc := document{
+		TransactionSet: "850",
+		Segments: []segment{
+			{ID: "BEG", Elements: []interface{}{"00", "SA", "PO123", "", "20230115"}},
+			{ID: "PO1", Elements: []interface{}{"1", "10", "EA", "9.99", "", "VP", "ABC", nil}},
+			{ID: "MEA", Elements: []interface{}{"PD", "WT", "5", []interface{}{"LB", "", "1"}}},
+		},
+	}
+
+	out, err := doc.toX12(testEnvelope())
+	require.NoError(t, err)
+	assert.Equal(t, "ISA*00*          *00*          *ZZ*SENDER         *01*RECEIVER       *230115*1030*^*00501*000000042*0*P*:~"+
+		"GS*PO*SENDER*RECEIVER*20230115*1030*42*X*005010~"+
+		"ST*850*0042~"+
+		"BEG*00*SA*PO123**20230115~"+
+		"PO1*1*10*EA*9.99**VP*ABC~"+
+		"MEA*PD*WT*5*LB::1~"+
+		"SE*5*0042~"+
+		"GE*1*42~"+
+		"IEA*1*000000042~", out)
+
+	t.Run("reserved delimiter", func(t *testing.T) {
+		doc := document{TransactionSet: "850", Segments: []segment{{ID: "BEG", Elements: []interface{}{"a*b"}}}}
+		_, err := doc.toX12(testEnvelope())
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown functional group", func(t *testing.T) {
+		doc := document{TransactionSet: "999", Segments: []segment{{ID: "REF", Elements: []interface{}{"a"}}}}
+		_, err := doc.toX12(testEnvelope())
+		assert.Error(t, err)
+
+		env := testEnvelope()
+		env.functionalGroup = "XX"
+		env.test = true
+		out, err := doc.toX12(env)
+		require.NoError(t, err)
+		assert.Contains(t, out, "*0*T*:~GS*XX*")
+	})
+}
+
+func TestToEDIFACT(t *testing.T) {
+	doc := document{
+		TransactionSet: "ORDERS",
+		Segments: []segment{
+			{ID: "BGM", Elements: []interface{}{"220", "PO123", "9"}},
+			{ID: "DTM", Elements: []interface{}{[]interface{}{"137", "20230115", "102"}}},
+			{ID: "FTX", Elements: []interface{}{"AAI", "", "", "", "Fragile: handle with care?"}},
+			{ID: "LIN", Elements: []interface{}{"1", "", []interface{}{"ABC", "SRV"}}},
+		},
+	}
+
+	env := testEnvelope()
+	env.senderQualifier = "14"
+	env.receiverQualifier = ""
+	env.version = "96A"
+	out, err := doc.toEDIFACT(env)
+	require.NoError(t, err)
+	assert.Equal(t, "UNA:+.? '"+
+		"UNB+UNOC:3+SENDER:14+RECEIVER+230115:1030+42'"+
+		"UNH+1+ORDERS:D:96A:UN'"+
+		"BGM+220+PO123+9'"+
+		"DTM+137:20230115:102'"+
+		"FTX+AAI++++Fragile?: handle with care??'"+
+		"LIN+1++ABC:SRV'"+
+		"UNT+6+1'"+
+		"UNZ+1+42'", out)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// TranslateOperation returns the EDI document without delivering it.
+	TranslateOperation bindings.OperationKind = "translate"
+
+	fileNameKey = "fileName"
+)
+
+// EDI is an output binding that translates JSON documents to X12 or EDIFACT interchanges and delivers them to trading partners.
+type EDI struct {
+	metadata  ediMetadata
+	deliverer deliverer
+	// Last interchange control number used; seeded from the clock so numbers keep increasing across restarts.
+	controlNumber int64
+	logger        logger.Logger
+}
+
+// NewEDI returns a new EDI output binding.
+func NewEDI(logger logger.Logger) bindings.OutputBinding {
+	return &EDI{logger: logger}
+}
+
+// Init performs metadata parsing and configures the delivery transport.
+func (e *EDI) Init(metadata bindings.Metadata) error {
+	m, err := parseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("edi binding error: %w", err)
+	}
+	e.metadata = m
+
+	switch m.Delivery {
+	case deliverySFTP:
+		e.deliverer, err = newSFTPDeliverer(m)
+	case deliveryAS2:
+		e.deliverer, err = newAS2Deliverer(m)
+	}
+	if err != nil {
+		return fmt.Errorf("edi binding error: %w", err)
+	}
+
+	e.controlNumber = time.Now().Unix() % 1_000_000_000
+
+	return nil
+}
+
+// Operations returns list of operations supported by the EDI binding.
+func (e *EDI) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation, TranslateOperation}
+}
+
+// Invoke translates the document and, for the create operation, delivers it.
+func (e *EDI) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	switch req.Operation {
+	case bindings.CreateOperation, TranslateOperation:
+	default:
+		return nil, fmt.Errorf("edi binding error: unsupported operation %s", req.Operation)
+	}
+
+	var doc document
+	if err := json.Unmarshal(req.Data, &doc); err != nil {
+		return nil, fmt.Errorf("edi binding error: failed to parse document: %w", err)
+	}
+	out, controlNumber, err := e.translate(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("edi binding error: %w", err)
+	}
+
+	contentType := "application/edi-x12"
+	if e.metadata.Standard == standardEDIFACT {
+		contentType = "application/edifact"
+	}
+	res := &bindings.InvokeResponse{
+		Data:        []byte(out),
+		ContentType: &contentType,
+		Metadata: map[string]string{
+			"controlNumber": controlNumber,
+		},
+	}
+
+	if req.Operation == TranslateOperation {
+		return res, nil
+	}
+	if e.deliverer == nil {
+		return nil, errors.New("edi binding error: no delivery transport configured")
+	}
+
+	fileName := req.Metadata[fileNameKey]
+	if fileName == "" {
+		fileName = fmt.Sprintf(defaultFileNameFormat, controlNumber)
+	}
+	location, err := e.deliverer.Deliver(ctx, fileName, res.Data)
+	if err != nil {
+		return nil, fmt.Errorf("edi binding error: delivery failed: %w", err)
+	}
+	res.Metadata["location"] = location
+	e.logger.Debugf("edi binding: delivered interchange %s to %s", controlNumber, location)
+
+	return res, nil
+}
+
+func (e *EDI) translate(doc *document) (string, string, error) {
+	if err := doc.validate(e.metadata.Standard); err != nil {
+		return "", "", fmt.Errorf("invalid document: %w", err)
+	}
+
+	env := envelope{
+		senderID:          e.metadata.SenderID,
+		senderQualifier:   e.metadata.SenderQualifier,
+		receiverID:        e.metadata.ReceiverID,
+		receiverQualifier: e.metadata.ReceiverQualifier,
+		functionalGroup:   e.metadata.FunctionalGroup,
+		version:           e.metadata.Version,
+		test:              e.metadata.Test,
+		now:               time.Now().UTC(),
+	}
+	if doc.ControlNumber != "" {
+		n, err := strconv.ParseInt(doc.ControlNumber, 10, 64)
+		if err != nil || n <= 0 {
+			return "", "", fmt.Errorf("invalid controlNumber '%s': must be a positive integer", doc.ControlNumber)
+		}
+		env.controlNumber = n
+	} else {
+		env.controlNumber = atomic.AddInt64(&e.controlNumber, 1)
+	}
+
+	var (
+		out string
+		err error
+	)
+	if e.metadata.Standard == standardEDIFACT {
+		out, err = doc.toEDIFACT(env)
+	} else {
+		out, err = doc.toX12(env)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return out, strconv.FormatInt(env.controlNumber, 10), nil
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/dapr/components-contrib/bindings"
+	as2component "github.com/dapr/components-contrib/internal/component/as2"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+var test850 = []byte(`{
+	"transactionSet": "850",
+	"controlNumber": "1001",
+	"segments": [
+		{"id": "BEG", "elements": ["00", "SA", "PO123", "", "20230115"]},
+		{"id": "PO1", "elements": ["1", "10", "EA", "9.99", "", "VP", "ABC"]}
+	]
+}`)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("x12 defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"standard": "x12", "senderId": "A", "receiverId": "B"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultX12Version, m.Version)
+		assert.Equal(t, defaultQualifier, m.SenderQualifier)
+		assert.Equal(t, deliveryNone, m.Delivery)
+	})
+
+	t.Run("edifact defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"standard": "edifact", "senderId": "A", "receiverId": "B"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultEDIFACTVersion, m.Version)
+		assert.Empty(t, m.SenderQualifier)
+	})
+
+	tests := map[string]map[string]string{
+		"missing standard":        {"senderId": "A", "receiverId": "B"},
+		"invalid standard":        {"standard": "tradacoms", "senderId": "A", "receiverId": "B"},
+		"missing partner ids":     {"standard": "x12"},
+		"x12 id too long":         {"standard": "x12", "senderId": "0123456789ABCDEF", "receiverId": "B"},
+		"sftp without host":       {"standard": "x12", "senderId": "A", "receiverId": "B", "delivery": "sftp"},
+		"sftp without host key":   {"standard": "x12", "senderId": "A", "receiverId": "B", "delivery": "sftp", "sftpHost": "h", "sftpUser": "u", "sftpPassword": "p"},
+		"sftp without credential": {"standard": "x12", "senderId": "A", "receiverId": "B", "delivery": "sftp", "sftpHost": "h", "sftpUser": "u", "sftpInsecureIgnoreHostKey": "true"},
+		"invalid delivery":        {"standard": "x12", "senderId": "A", "receiverId": "B", "delivery": "ftp"},
+		"as2 without url":         {"standard": "x12", "senderId": "A", "receiverId": "B", "delivery": "as2"},
+	}
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseMetadata(props)
+			assert.Error(t, err)
+		})
+	}
+}
+
+type fakeDeliverer struct {
+	fileName string
+	data     []byte
+	err      error
+}
+
+func (f *fakeDeliverer) Deliver(ctx context.Context, fileName string, data []byte) (string, error) {
+	f.fileName = fileName
+	f.data = data
+	return "/outbound/" + fileName, f.err
+}
+
+func newTestBinding(t *testing.T, props map[string]string) *EDI {
+	t.Helper()
+
+	e := NewEDI(logger.NewLogger("test")).(*EDI)
+	require.NoError(t, e.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	return e
+}
+
+func TestInvoke(t *testing.T) {
+	props := map[string]string{"standard": "x12", "senderId": "SENDER", "receiverId": "RECEIVER"}
+
+	t.Run("translate", func(t *testing.T) {
+		e := newTestBinding(t, props)
+		res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: TranslateOperation, Data: test850})
+		require.NoError(t, err)
+		assert.Equal(t, "1001", res.Metadata["controlNumber"])
+		assert.Equal(t, "application/edi-x12", *res.ContentType)
+		assert.Contains(t, string(res.Data), "*000001001*0*P*:~GS*PO*SENDER*RECEIVER*")
+	})
+
+	t.Run("generated control numbers increase", func(t *testing.T) {
+		e := newTestBinding(t, props)
+		doc := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(test850, &doc))
+		delete(doc, "controlNumber")
+		data, _ := json.Marshal(doc)
+
+		res1, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: TranslateOperation, Data: data})
+		require.NoError(t, err)
+		res2, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: TranslateOperation, Data: data})
+		require.NoError(t, err)
+
+		n1, _ := strconv.ParseInt(res1.Metadata["controlNumber"], 10, 64)
+		n2, _ := strconv.ParseInt(res2.Metadata["controlNumber"], 10, 64)
+		assert.Equal(t, n1+1, n2)
+	})
+
+	t.Run("create delivers the document", func(t *testing.T) {
+		e := newTestBinding(t, props)
+		fake := &fakeDeliverer{}
+		e.deliverer = fake
+
+		res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: test850})
+		require.NoError(t, err)
+		assert.Equal(t, "1001.edi", fake.fileName)
+		assert.Equal(t, res.Data, fake.data)
+		assert.Equal(t, "/outbound/1001.edi", res.Metadata["location"])
+
+		_, err = e.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      test850,
+			Metadata:  map[string]string{fileNameKey: "po.x12"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "po.x12", fake.fileName)
+	})
+
+	t.Run("delivery error", func(t *testing.T) {
+		e := newTestBinding(t, props)
+		e.deliverer = &fakeDeliverer{err: errors.New("unreachable")}
+		_, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: test850})
+		assert.ErrorContains(t, err, "unreachable")
+	})
+
+	t.Run("create without delivery", func(t *testing.T) {
+		e := newTestBinding(t, props)
+		_, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: test850})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		e := newTestBinding(t, props)
+		_, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: TranslateOperation, Data: []byte(`{"transactionSet":"850","segments":[{"id":"BEG"}]}`)})
+		assert.ErrorContains(t, err, "requires segment PO1")
+	})
+}
+
+// startSFTPServer starts an in-process SFTP server accepting the given password and returns its address and host public key.
+func startSFTPServer(t *testing.T, password string) (string, string) {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) != password {
+				return nil, errors.New("invalid password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config)
+		}
+	}()
+
+	return listener.Addr().String(), string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						_ = server.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func TestSFTPDelivery(t *testing.T) {
+	addr, hostKey := startSFTPServer(t, "secret")
+	host, port, _ := net.SplitHostPort(addr)
+	dir := t.TempDir()
+
+	e := newTestBinding(t, map[string]string{
+		"standard":          "edifact",
+		"senderId":          "SENDER",
+		"receiverId":        "RECEIVER",
+		"delivery":          "sftp",
+		"sftpHost":          host,
+		"sftpPort":          port,
+		"sftpUser":          "dapr",
+		"sftpPassword":      "secret",
+		"sftpHostPublicKey": hostKey,
+		"sftpDirectory":     dir,
+	})
+
+	res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte(`{"transactionSet":"ORDERS","segments":[{"id":"BGM","elements":["220","PO1"]},{"id":"DTM","elements":[["137","20230115","102"]]},{"id":"LIN","elements":["1"]}]}`),
+		Metadata:  map[string]string{fileNameKey: "orders.edi"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "orders.edi"), res.Metadata["location"])
+
+	written, err := os.ReadFile(filepath.Join(dir, "orders.edi"))
+	require.NoError(t, err)
+	assert.Equal(t, res.Data, written)
+	_, err = os.Stat(filepath.Join(dir, "orders.edi.part"))
+	assert.True(t, os.IsNotExist(err))
+
+	t.Run("host key mismatch", func(t *testing.T) {
+		_, otherKey := startSFTPServer(t, "secret")
+		e := newTestBinding(t, map[string]string{
+			"standard":          "x12",
+			"senderId":          "SENDER",
+			"receiverId":        "RECEIVER",
+			"delivery":          "sftp",
+			"sftpHost":          host,
+			"sftpPort":          port,
+			"sftpUser":          "dapr",
+			"sftpPassword":      "secret",
+			"sftpHostPublicKey": otherKey,
+			"sftpDirectory":     dir,
+		})
+		_, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: test850})
+		assert.ErrorContains(t, err, "handshake")
+	})
+}
+
+func TestAS2Delivery(t *testing.T) {
+	partner := &as2component.Config{From: "RECEIVER", To: "SENDER"}
+	require.NoError(t, partner.Validate())
+
+	var received *as2component.Inbound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		in, err := partner.Unpack(r.Header, body)
+		received = in
+		mdn, mdnErr := partner.NewMDN(in, err)
+		require.NoError(t, mdnErr)
+		for k, v := range mdn.Header {
+			w.Header()[k] = v
+		}
+		_, _ = w.Write(mdn.Body)
+	}))
+	defer srv.Close()
+
+	e := newTestBinding(t, map[string]string{
+		"standard":     "x12",
+		"senderId":     "SENDER",
+		"receiverId":   "RECEIVER",
+		"delivery":     "as2",
+		"as2Url":       srv.URL,
+		"as2From":      "SENDER",
+		"as2To":        "RECEIVER",
+		"as2Sign":      "false",
+		"as2Encrypt":   "false",
+		"as2SignedMdn": "false",
+	})
+
+	res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      test850,
+		Metadata:  map[string]string{fileNameKey: "po.x12"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, received)
+	assert.Equal(t, res.Data, received.Data)
+	assert.Equal(t, "application/edi-x12", received.ContentType)
+	assert.Equal(t, "po.x12", received.FileName)
+	assert.Equal(t, received.MessageID, res.Metadata["location"])
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	as2component "github.com/dapr/components-contrib/internal/component/as2"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	deliveryNone = "none"
+	deliverySFTP = "sftp"
+	deliveryAS2  = "as2"
+
+	defaultX12Version     = "005010"
+	defaultEDIFACTVersion = "96A"
+	defaultQualifier      = "ZZ"
+	defaultSFTPPort       = 22
+	defaultFileNameFormat = "%s.edi"
+	defaultTimeout        = 30 * time.Second
+)
+
+type ediMetadata struct {
+	// Standard is either "x12" or "edifact".
+	Standard string `mapstructure:"standard"`
+	// Interchange sender and receiver.
+	SenderID          string `mapstructure:"senderId"`
+	SenderQualifier   string `mapstructure:"senderQualifier"`
+	ReceiverID        string `mapstructure:"receiverId"`
+	ReceiverQualifier string `mapstructure:"receiverQualifier"`
+	// Version is the X12 version/release code (GS08) or the EDIFACT directory (e.g. "96A", "D:01B").
+	Version string `mapstructure:"version"`
+	// FunctionalGroup overrides the X12 functional identifier code (GS01).
+	FunctionalGroup string `mapstructure:"functionalGroup"`
+	// Test marks interchanges as test data.
+	Test bool `mapstructure:"test"`
+
+	// Delivery is the transport used by the create operation: "none", "sftp" or "as2".
+	Delivery string `mapstructure:"delivery"`
+
+	// SFTP delivery settings.
+	SFTPHost                  string        `mapstructure:"sftpHost"`
+	SFTPPort                  int           `mapstructure:"sftpPort"`
+	SFTPUser                  string        `mapstructure:"sftpUser"`
+	SFTPPassword              string        `mapstructure:"sftpPassword"`
+	SFTPPrivateKey            string        `mapstructure:"sftpPrivateKey"`
+	SFTPHostPublicKey         string        `mapstructure:"sftpHostPublicKey"`
+	SFTPInsecureIgnoreHostKey bool          `mapstructure:"sftpInsecureIgnoreHostKey"`
+	SFTPDirectory             string        `mapstructure:"sftpDirectory"`
+	Timeout                   time.Duration `mapstructure:"timeout"`
+
+	// AS2 delivery settings.
+	AS2 as2component.Settings `mapstructure:",squash"`
+}
+
+func parseMetadata(meta map[string]string) (ediMetadata, error) {
+	m := ediMetadata{
+		SenderQualifier:   defaultQualifier,
+		ReceiverQualifier: defaultQualifier,
+		Delivery:          deliveryNone,
+		SFTPPort:          defaultSFTPPort,
+		Timeout:           defaultTimeout,
+		AS2:               as2component.NewSettings(),
+	}
+	if err := metadata.DecodeMetadata(meta, &m); err != nil {
+		return m, err
+	}
+
+	switch m.Standard {
+	case standardX12:
+		if m.Version == "" {
+			m.Version = defaultX12Version
+		}
+		if len(m.SenderQualifier) != 2 || len(m.ReceiverQualifier) != 2 {
+			return m, errors.New("X12 sender and receiver qualifiers must be 2 characters long")
+		}
+		if len(m.SenderID) > 15 || len(m.ReceiverID) > 15 {
+			return m, errors.New("X12 sender and receiver IDs must be at most 15 characters long")
+		}
+	case standardEDIFACT:
+		if m.Version == "" {
+			m.Version = defaultEDIFACTVersion
+		}
+		if _, ok := meta["senderQualifier"]; !ok {
+			m.SenderQualifier = ""
+		}
+		if _, ok := meta["receiverQualifier"]; !ok {
+			m.ReceiverQualifier = ""
+		}
+	case "":
+		return m, errors.New("missing required metadata property: standard")
+	default:
+		return m, fmt.Errorf("invalid standard '%s': must be one of '%s' or '%s'", m.Standard, standardX12, standardEDIFACT)
+	}
+
+	if m.SenderID == "" || m.ReceiverID == "" {
+		return m, errors.New("senderId and receiverId are required")
+	}
+
+	switch m.Delivery {
+	case deliveryNone:
+	case deliverySFTP:
+		if m.SFTPHost == "" || m.SFTPUser == "" {
+			return m, errors.New("sftpHost and sftpUser are required for sftp delivery")
+		}
+		if m.SFTPPassword == "" && m.SFTPPrivateKey == "" {
+			return m, errors.New("either sftpPassword or sftpPrivateKey is required for sftp delivery")
+		}
+		if m.SFTPHostPublicKey == "" && !m.SFTPInsecureIgnoreHostKey {
+			return m, errors.New("sftpHostPublicKey is required unless sftpInsecureIgnoreHostKey is set")
+		}
+	case deliveryAS2:
+		if m.AS2.URL == "" {
+			return m, errors.New("as2Url is required for as2 delivery")
+		}
+	default:
+		return m, fmt.Errorf("invalid delivery '%s'", m.Delivery)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// deliverer sends a rendered EDI interchange to a trading partner.
+type deliverer interface {
+	Deliver(ctx context.Context, fileName string, data []byte) (location string, err error)
+}
+
+type sftpDeliverer struct {
+	addr      string
+	directory string
+	config    *ssh.ClientConfig
+}
+
+func newSFTPDeliverer(m ediMetadata) (*sftpDeliverer, error) {
+	config := &ssh.ClientConfig{
+		User:    m.SFTPUser,
+		Timeout: m.Timeout,
+	}
+
+	if m.SFTPPrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(m.SFTPPrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftpPrivateKey: %w", err)
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if m.SFTPPassword != "" {
+		config.Auth = append(config.Auth, ssh.Password(m.SFTPPassword))
+	}
+
+	if m.SFTPHostPublicKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(m.SFTPHostPublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftpHostPublicKey: %w", err)
+		}
+		config.HostKeyCallback = ssh.FixedHostKey(hostKey)
+	} else {
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec
+	}
+
+	return &sftpDeliverer{
+		addr:      net.JoinHostPort(m.SFTPHost, strconv.Itoa(m.SFTPPort)),
+		directory: m.SFTPDirectory,
+		config:    config,
+	}, nil
+}
+
+// Deliver uploads the interchange to the configured directory.
+// The file is written under a temporary name and renamed once complete, so partners never pick up partial files.
+func (s *sftpDeliverer) Deliver(ctx context.Context, fileName string, data []byte) (string, error) {
+	dialer := net.Dialer{Timeout: s.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", s.addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, s.addr, s.config)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("ssh handshake with %s failed: %w", s.addr, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	defer sshClient.Close()
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to start sftp session: %w", err)
+	}
+	defer client.Close()
+
+	target := path.Join(s.directory, fileName)
+	tmp := target + ".part"
+	f, err := client.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = client.Remove(tmp)
+		return "", fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err = client.PosixRename(tmp, target); err != nil {
+		return "", fmt.Errorf("failed to rename %s to %s: %w", tmp, target, err)
+	}
+
+	return target, nil
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ethereum
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// This file implements the subset of the contract ABI encoding needed to call functions with static arguments,
+// strings and bytes: arrays and tuples aren't supported.
+
+const wordSize = 32
+
+// abiType is an elementary ABI type.
+type abiType struct {
+	Name string
+	// Kind is "address", "bool", "uint", "int", "bytes" or "string".
+	Kind string
+	// Size is the size in bits of integers, or in bytes of fixed-size byte arrays; it's 0 for dynamic bytes.
+	Size int
+}
+
+func (t abiType) dynamic() bool {
+	return t.Kind == "string" || (t.Kind == "bytes" && t.Size == 0)
+}
+
+func parseABIType(name string) (abiType, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "address", name == "bool", name == "string", name == "bytes":
+		return abiType{Name: name, Kind: name}, nil
+	case name == "uint", name == "int":
+		return abiType{Name: name + "256", Kind: name, Size: 256}, nil
+	case strings.HasPrefix(name, "uint"), strings.HasPrefix(name, "int"):
+		kind := "int"
+		if name[0] == 'u' {
+			kind = "uint"
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(name, kind))
+		if err != nil || size < 8 || size > 256 || size%8 != 0 {
+			return abiType{}, fmt.Errorf("invalid ABI type %s", name)
+		}
+		return abiType{Name: name, Kind: kind, Size: size}, nil
+	case strings.HasPrefix(name, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(name, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return abiType{}, fmt.Errorf("invalid ABI type %s", name)
+		}
+		return abiType{Name: name, Kind: "bytes", Size: size}, nil
+	default:
+		return abiType{}, fmt.Errorf("unsupported ABI type %s", name)
+	}
+}
+
+func parseABITypes(names []string) ([]abiType, error) {
+	types := make([]abiType, len(names))
+	for i, name := range names {
+		t, err := parseABIType(name)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = t
+	}
+	return types, nil
+}
+
+// parseSignature parses a function or event signature such as "transfer(address,uint256)".
+// It returns the canonical signature, with the aliases of types expanded, and the types of the arguments.
+func parseSignature(signature string) (string, []abiType, error) {
+	open := strings.IndexByte(signature, '(')
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return "", nil, fmt.Errorf("invalid signature %s: expected name(type1,type2,...)", signature)
+	}
+	name := strings.TrimSpace(signature[:open])
+	args := strings.TrimSpace(signature[open+1 : len(signature)-1])
+
+	var types []abiType
+	if args != "" {
+		var err error
+		types, err = parseABITypes(strings.Split(args, ","))
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid signature %s: %w", signature, err)
+		}
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+
+	return name + "(" + strings.Join(names, ",") + ")", types, nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// encodeCall returns the call data of a function: the selector followed by the encoded arguments.
+func encodeCall(signature string, args []any) ([]byte, error) {
+	canonical, types, err := parseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != len(types) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", canonical, len(types), len(args))
+	}
+	encoded, err := encodeArgs(types, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(keccak256([]byte(canonical))[:4], encoded...), nil
+}
+
+// encodeArgs encodes the arguments as a tuple: static values and the offsets of the dynamic ones in the head, followed by the dynamic values.
+func encodeArgs(types []abiType, args []any) ([]byte, error) {
+	head := make([]byte, 0, len(types)*wordSize)
+	var tail []byte
+	for i, t := range types {
+		value, err := encodeValue(t, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		if t.dynamic() {
+			head = append(head, word(big.NewInt(int64(len(types)*wordSize+len(tail))))...)
+			tail = append(tail, value...)
+		} else {
+			head = append(head, value...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t abiType, v any) ([]byte, error) {
+	switch t.Kind {
+	case "address":
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("expected an address string")
+		}
+		addr, err := parseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		return leftPad(addr), nil
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("expected a boolean")
+		}
+		if b {
+			return word(big.NewInt(1)), nil
+		}
+		return word(big.NewInt(0)), nil
+	case "uint", "int":
+		n, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		min, max := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+		if t.Kind == "int" {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("%s out of range for %s", n, t.Name)
+		}
+		if n.Sign() < 0 {
+			// Two's complement on 256 bits.
+			n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return word(n), nil
+	case "bytes":
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("expected a hex string")
+		}
+		b, err := decodeHex(s)
+		if err != nil {
+			return nil, err
+		}
+		if t.Size > 0 {
+			if len(b) != t.Size {
+				return nil, fmt.Errorf("expected %d bytes, got %d", t.Size, len(b))
+			}
+			return rightPad(b), nil
+		}
+		return append(word(big.NewInt(int64(len(b)))), rightPad(b)...), nil
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("expected a string")
+		}
+		return append(word(big.NewInt(int64(len(s)))), rightPad([]byte(s))...), nil
+	default:
+		return nil, fmt.Errorf("unsupported ABI type %s", t.Name)
+	}
+}
+
+// decodeValues decodes the values of the given types, returning integers as decimal strings and bytes as hex strings.
+func decodeValues(types []abiType, data []byte) ([]any, error) {
+	values := make([]any, len(types))
+	for i, t := range types {
+		if len(data) < (i+1)*wordSize {
+			return nil, errors.New("ABI data too short")
+		}
+		w := data[i*wordSize : (i+1)*wordSize]
+		if t.dynamic() {
+			offset := new(big.Int).SetBytes(w)
+			if !offset.IsInt64() || offset.Int64()+wordSize > int64(len(data)) {
+				return nil, errors.New("invalid ABI offset")
+			}
+			start := int(offset.Int64())
+			length := new(big.Int).SetBytes(data[start : start+wordSize])
+			if !length.IsInt64() || int64(start+wordSize)+length.Int64() > int64(len(data)) {
+				return nil, errors.New("invalid ABI length")
+			}
+			b := data[start+wordSize : start+wordSize+int(length.Int64())]
+			if t.Kind == "string" {
+				values[i] = string(b)
+			} else {
+				values[i] = encodeHex(b)
+			}
+			continue
+		}
+
+		switch t.Kind {
+		case "address":
+			values[i] = encodeHex(w[12:])
+		case "bool":
+			values[i] = w[wordSize-1] == 1
+		case "uint":
+			values[i] = new(big.Int).SetBytes(w).String()
+		case "int":
+			n := new(big.Int).SetBytes(w)
+			if w[0]&0x80 != 0 {
+				n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+			}
+			values[i] = n.String()
+		case "bytes":
+			values[i] = encodeHex(w[:t.Size])
+		}
+	}
+	return values, nil
+}
+
+// toBigInt converts a JSON number, or a decimal or 0x-prefixed hex string, to an integer.
+func toBigInt(v any) (*big.Int, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, errors.New("expected an integer")
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %s", s)
+	}
+	return n, nil
+}
+
+func word(n *big.Int) []byte {
+	w := make([]byte, wordSize)
+	return n.FillBytes(w)
+}
+
+func leftPad(b []byte) []byte {
+	w := make([]byte, wordSize)
+	copy(w[wordSize-len(b):], b)
+	return w
+}
+
+func rightPad(b []byte) []byte {
+	padded := make([]byte, (len(b)+wordSize-1)/wordSize*wordSize)
+	copy(padded, b)
+	return padded
+}
+
+func parseAddress(s string) ([]byte, error) {
+	b, err := decodeHex(s)
+	if err != nil || len(b) != 20 {
+		return nil, fmt.Errorf("invalid address %s", s)
+	}
+	return b, nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("invalid hex string %s: missing 0x prefix", s)
+	}
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex string %s: %w", s, err)
+	}
+	return b, nil
+}
+
+func encodeHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ethereum
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignature(t *testing.T) {
+	canonical, types, err := parseSignature("transfer(address, uint)")
+	require.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", canonical)
+	assert.Equal(t, []abiType{{Name: "address", Kind: "address"}, {Name: "uint256", Kind: "uint", Size: 256}}, types)
+
+	canonical, types, err = parseSignature("totalSupply()")
+	require.NoError(t, err)
+	assert.Equal(t, "totalSupply()", canonical)
+	assert.Empty(t, types)
+
+	for _, s := range []string{"transfer", "(address)", "f(uint7)", "f(bytes33)", "f(address[])"} {
+		_, _, err = parseSignature(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestEncodeCall(t *testing.T) {
+	t.Run("static arguments", func(t *testing.T) {
+		data, err := encodeCall("transfer(address,uint256)", []any{"0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", "1000"})
+		require.NoError(t, err)
+		assert.Equal(t, "0xa9059cbb"+
+			"0000000000000000000000002c7536e3605d9c16a7a3d7b1898e529396a65c23"+
+			"00000000000000000000000000000000000000000000000000000000000003e8", encodeHex(data))
+	})
+
+	t.Run("dynamic arguments", func(t *testing.T) {
+		data, err := encodeCall("set(string,int8,bytes)", []any{"hi", "-1", "0x0102"})
+		require.NoError(t, err)
+		assert.Equal(t, encodeHex(keccak256([]byte("set(string,int8,bytes)"))[:4])+
+			"0000000000000000000000000000000000000000000000000000000000000060"+
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"+
+			"00000000000000000000000000000000000000000000000000000000000000a0"+
+			"0000000000000000000000000000000000000000000000000000000000000002"+
+			"6869000000000000000000000000000000000000000000000000000000000000"+
+			"0000000000000000000000000000000000000000000000000000000000000002"+
+			"0102000000000000000000000000000000000000000000000000000000000000", encodeHex(data))
+
+		types, err := parseABITypes([]string{"string", "int8", "bytes"})
+		require.NoError(t, err)
+		values, err := decodeValues(types, data[4:])
+		require.NoError(t, err)
+		assert.Equal(t, []any{"hi", "-1", "0x0102"}, values)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		tests := map[string][]any{
+			"f(address)":   {"0x1234"},
+			"f(uint8)":     {"256"},
+			"f(int8)":      {"-129"},
+			"f(bool)":      {"true"},
+			"f(bytes2)":    {"0x01"},
+			"f(uint256)":   {"abc"},
+			"f(uint256,a)": {"1", "2"},
+			"f(string)":    {},
+		}
+		for signature, args := range tests {
+			_, err := encodeCall(signature, args)
+			assert.Error(t, err, signature)
+		}
+	})
+}
+
+func TestDecodeValues(t *testing.T) {
+	types, err := parseABITypes([]string{"uint256", "bool", "address", "bytes4"})
+	require.NoError(t, err)
+	data, err := encodeArgs(types, []any{"0xff", true, "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", "0xdeadbeef"})
+	require.NoError(t, err)
+
+	values, err := decodeValues(types, data)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"255", true, "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", "0xdeadbeef"}, values)
+
+	_, err = decodeValues(types, data[:64])
+	assert.Error(t, err)
+}
+
+func TestRLPEncode(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{[]byte("dog"), "0x83646f67"},
+		{[]any{[]byte("cat"), []byte("dog")}, "0xc88363617483646f67"},
+		{[]byte{}, "0x80"},
+		{[]byte{0x0f}, "0x0f"},
+		{big.NewInt(0), "0x80"},
+		{big.NewInt(1024), "0x820400"},
+		{[]any{}, "0xc0"},
+		{[]byte(strings.Repeat("a", 56)), "0xb838" + strings.Repeat("61", 56)},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, encodeHex(rlpEncode(tt.value)))
+	}
+}
+
+func TestPublicKeyAddress(t *testing.T) {
+	key, err := parsePrivateKey("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	assert.Equal(t, "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", encodeHex(publicKeyAddress(key.PubKey())))
+
+	_, err = parsePrivateKey("0x1234")
+	assert.Error(t, err)
+}
@@ -0,0 +1,576 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	CallOperation                  bindings.OperationKind = "call"
+	SendTransactionOperation       bindings.OperationKind = "sendTransaction"
+	GetBalanceOperation            bindings.OperationKind = "getBalance"
+	GetTransactionReceiptOperation bindings.OperationKind = "getTransactionReceipt"
+	BlockNumberOperation           bindings.OperationKind = "blockNumber"
+)
+
+var errNoKey = errors.New("sending transactions requires privateKey")
+
+// Ethereum is a binding for Ethereum and EVM-compatible nodes: the output binding reads contract state and sends signed transactions
+// through the JSON-RPC API, and the input binding delivers the logs of contract events, subscribed to over websockets.
+type Ethereum struct {
+	metadata ethereumMetadata
+	key      *secp256k1.PrivateKey
+	address  []byte
+	chainID  *big.Int
+	client   *http.Client
+	lastID   uint64
+	// Serializes the transactions, which are numbered by their nonce.
+	sendLock sync.Mutex
+
+	closeCh chan struct{}
+	closed  atomic.Bool
+	wg      sync.WaitGroup
+	logger  logger.Logger
+}
+
+type ethereumMetadata struct {
+	// HTTP endpoint of the JSON-RPC API.
+	RPCURL string `mapstructure:"rpcURL"`
+	// Websocket endpoint of the JSON-RPC API, used by the input binding.
+	WSURL string `mapstructure:"wsURL"`
+	// Hex-encoded key signing the transactions; it's usually a reference to a secret store.
+	PrivateKey string `mapstructure:"privateKey"`
+	// ChainID is read from the node when it's not set.
+	ChainID int64         `mapstructure:"chainID"`
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Default contract of calls and transactions, and contract whose events are delivered by the input binding.
+	ContractAddress string `mapstructure:"contractAddress"`
+	// Signature of the event the input binding subscribes to, such as "Transfer(address,address,uint256)"; all the events of the contract by default.
+	EventSignature string `mapstructure:"eventSignature"`
+}
+
+// CallRequest is the data of call and sendTransaction requests.
+// The call data is either Data, or encoded from Function and Args.
+type CallRequest struct {
+	// To defaults to contractAddress.
+	To       string          `json:"to,omitempty"`
+	Function string          `json:"function,omitempty"`
+	Args     []any           `json:"args,omitempty"`
+	Data     string          `json:"data,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	// Types of the values returned by calls, to decode them.
+	Outputs []string `json:"outputs,omitempty"`
+	// Block of calls; defaults to "latest".
+	Block string `json:"block,omitempty"`
+
+	// Parameters of transactions, estimated by the node when they're not set.
+	Gas                  uint64          `json:"gas,omitempty"`
+	MaxFeePerGas         json.RawMessage `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas json.RawMessage `json:"maxPriorityFeePerGas,omitempty"`
+}
+
+// CallResult is the result of a call.
+type CallResult struct {
+	Result string `json:"result"`
+	Values []any  `json:"values,omitempty"`
+}
+
+// TransactionResult is the result of a sent transaction.
+type TransactionResult struct {
+	Hash  string `json:"hash"`
+	From  string `json:"from"`
+	Nonce uint64 `json:"nonce"`
+}
+
+// rpcError is an error returned by the node.
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("JSON-RPC error %d: %s (%s)", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// NewEthereum returns a new Ethereum binding.
+func NewEthereum(logger logger.Logger) bindings.InputOutputBinding {
+	return &Ethereum{
+		closeCh: make(chan struct{}),
+		logger:  logger,
+	}
+}
+
+// Init parses the metadata of the binding, and reads the chain ID from the node when it isn't set.
+func (e *Ethereum) Init(meta bindings.Metadata) error {
+	e.metadata = ethereumMetadata{Timeout: defaultTimeout}
+	err := metadata.DecodeMetadata(meta.Properties, &e.metadata)
+	if err != nil {
+		return fmt.Errorf("ethereum binding error: %w", err)
+	}
+	if e.metadata.RPCURL == "" && e.metadata.WSURL == "" {
+		return errors.New("ethereum binding error: rpcURL or wsURL is required")
+	}
+	if e.metadata.ContractAddress != "" {
+		if _, err = parseAddress(e.metadata.ContractAddress); err != nil {
+			return fmt.Errorf("ethereum binding error: contractAddress: %w", err)
+		}
+	}
+	if e.metadata.EventSignature != "" {
+		if _, _, err = parseSignature(e.metadata.EventSignature); err != nil {
+			return fmt.Errorf("ethereum binding error: eventSignature: %w", err)
+		}
+	}
+	if e.metadata.PrivateKey != "" {
+		e.key, err = parsePrivateKey(e.metadata.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("ethereum binding error: %w", err)
+		}
+		e.address = publicKeyAddress(e.key.PubKey())
+	}
+	e.client = &http.Client{Timeout: e.metadata.Timeout}
+
+	if e.metadata.ChainID != 0 {
+		e.chainID = big.NewInt(e.metadata.ChainID)
+	} else if e.metadata.RPCURL != "" && e.key != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), e.metadata.Timeout)
+		defer cancel()
+		e.chainID, err = e.quantity(ctx, "eth_chainId")
+		if err != nil {
+			return fmt.Errorf("ethereum binding error: failed to read the chain ID: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Operations returns the operations supported by the Ethereum binding.
+func (e *Ethereum) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		CallOperation,
+		SendTransactionOperation,
+		GetBalanceOperation,
+		GetTransactionReceiptOperation,
+		BlockNumberOperation,
+	}
+}
+
+// Invoke sends the request to the node.
+func (e *Ethereum) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if e.metadata.RPCURL == "" {
+		return nil, errors.New("ethereum binding error: the output binding requires rpcURL")
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case CallOperation:
+		result, err = e.call(ctx, req.Data)
+	case SendTransactionOperation:
+		result, err = e.sendTransaction(ctx, req.Data)
+	case GetBalanceOperation:
+		result, err = e.getBalance(ctx, req.Data)
+	case GetTransactionReceiptOperation:
+		var r struct {
+			Hash string `json:"hash"`
+		}
+		if err = json.Unmarshal(req.Data, &r); err == nil && r.Hash == "" {
+			err = errors.New("hash is required")
+		}
+		if err == nil {
+			var receipt json.RawMessage
+			err = e.rpc(ctx, "eth_getTransactionReceipt", &receipt, r.Hash)
+			result = receipt
+		}
+	case BlockNumberOperation:
+		var n *big.Int
+		n, err = e.quantity(ctx, "eth_blockNumber")
+		if err == nil {
+			result = map[string]any{"blockNumber": n.Uint64()}
+		}
+	default:
+		return nil, fmt.Errorf("ethereum binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ethereum binding error: %s failed: %w", req.Operation, err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}, nil
+}
+
+func parseCallRequest(data []byte) (*CallRequest, error) {
+	var req CallRequest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	return &req, nil
+}
+
+// callData returns the destination and the data of a call or transaction.
+func (e *Ethereum) callData(req *CallRequest) (string, []byte, error) {
+	to := req.To
+	if to == "" {
+		to = e.metadata.ContractAddress
+	}
+
+	switch {
+	case req.Function != "" && req.Data != "":
+		return "", nil, errors.New("function and data are mutually exclusive")
+	case req.Function != "":
+		data, err := encodeCall(req.Function, req.Args)
+		return to, data, err
+	case req.Data != "":
+		data, err := decodeHex(req.Data)
+		return to, data, err
+	default:
+		return to, nil, nil
+	}
+}
+
+func (e *Ethereum) call(ctx context.Context, data []byte) (*CallResult, error) {
+	req, err := parseCallRequest(data)
+	if err != nil {
+		return nil, err
+	}
+	to, callData, err := e.callData(req)
+	if err != nil {
+		return nil, err
+	}
+	if to == "" {
+		return nil, errors.New("to is required when contractAddress isn't set")
+	}
+	outputs, err := parseABITypes(req.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	block := req.Block
+	if block == "" {
+		block = "latest"
+	}
+
+	msg := map[string]string{"to": to, "data": encodeHex(callData)}
+	if e.address != nil {
+		msg["from"] = encodeHex(e.address)
+	}
+	res := &CallResult{}
+	if err = e.rpc(ctx, "eth_call", &res.Result, msg, block); err != nil {
+		return nil, err
+	}
+	if len(outputs) > 0 {
+		raw, err := decodeHex(res.Result)
+		if err != nil {
+			return nil, err
+		}
+		res.Values, err = decodeValues(outputs, raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the result: %w", err)
+		}
+	}
+
+	return res, nil
+}
+
+func (e *Ethereum) sendTransaction(ctx context.Context, data []byte) (*TransactionResult, error) {
+	if e.key == nil {
+		return nil, errNoKey
+	}
+	req, err := parseCallRequest(data)
+	if err != nil {
+		return nil, err
+	}
+	to, callData, err := e.callData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction{
+		ChainID: e.chainID,
+		Gas:     req.Gas,
+		Data:    callData,
+		Value:   big.NewInt(0),
+	}
+	if to != "" {
+		if tx.To, err = parseAddress(to); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Value, err = optionalBigInt(req.Value, "value"); err != nil {
+		return nil, err
+	}
+	if tx.MaxFeePerGas, err = optionalBigInt(req.MaxFeePerGas, "maxFeePerGas"); err != nil {
+		return nil, err
+	}
+	if tx.MaxPriorityFeePerGas, err = optionalBigInt(req.MaxPriorityFeePerGas, "maxPriorityFeePerGas"); err != nil {
+		return nil, err
+	}
+	if err = e.fillFees(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	from := encodeHex(e.address)
+	if tx.Gas == 0 {
+		msg := map[string]string{
+			"from":  from,
+			"data":  encodeHex(tx.Data),
+			"value": toQuantity(tx.Value),
+		}
+		if tx.To != nil {
+			msg["to"] = encodeHex(tx.To)
+		}
+		gas, err := e.quantity(ctx, "eth_estimateGas", msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		tx.Gas = gas.Uint64()
+	}
+
+	e.sendLock.Lock()
+	defer e.sendLock.Unlock()
+
+	nonce, err := e.quantity(ctx, "eth_getTransactionCount", from, "pending")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the nonce: %w", err)
+	}
+	tx.Nonce = nonce.Uint64()
+
+	res := &TransactionResult{From: from, Nonce: tx.Nonce}
+	if err = e.rpc(ctx, "eth_sendRawTransaction", &res.Hash, encodeHex(tx.sign(e.key))); err != nil {
+		return nil, err
+	}
+	e.logger.Debugf("ethereum: sent transaction %s with nonce %d", res.Hash, res.Nonce)
+
+	return res, nil
+}
+
+// fillFees sets the fees of a transaction that aren't set: the priority fee suggested by the node,
+// and a maximum fee allowing the base fee to double.
+func (e *Ethereum) fillFees(ctx context.Context, tx *transaction) error {
+	if tx.MaxPriorityFeePerGas == nil {
+		tip, err := e.quantity(ctx, "eth_maxPriorityFeePerGas")
+		if err != nil {
+			return fmt.Errorf("failed to read the priority fee: %w", err)
+		}
+		tx.MaxPriorityFeePerGas = tip
+	}
+	if tx.MaxFeePerGas == nil {
+		var block struct {
+			BaseFeePerGas string `json:"baseFeePerGas"`
+		}
+		if err := e.rpc(ctx, "eth_getBlockByNumber", &block, "latest", false); err != nil {
+			return fmt.Errorf("failed to read the base fee: %w", err)
+		}
+		baseFee, err := parseQuantity(block.BaseFeePerGas)
+		if err != nil {
+			return fmt.Errorf("failed to read the base fee: %w", err)
+		}
+		tx.MaxFeePerGas = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tx.MaxPriorityFeePerGas)
+	}
+	return nil
+}
+
+func (e *Ethereum) getBalance(ctx context.Context, data []byte) (any, error) {
+	var req struct {
+		Address string `json:"address"`
+		Block   string `json:"block"`
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+	}
+	if req.Address == "" {
+		if e.address == nil {
+			return nil, errors.New("address is required when privateKey isn't set")
+		}
+		req.Address = encodeHex(e.address)
+	}
+	if req.Block == "" {
+		req.Block = "latest"
+	}
+
+	balance, err := e.quantity(ctx, "eth_getBalance", req.Address, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"address": req.Address, "balance": balance.String()}, nil
+}
+
+// rpc calls a method of the JSON-RPC API over HTTP.
+func (e *Ethereum) rpc(ctx context.Context, method string, result any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      atomic.AddUint64(&e.lastID, 1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.metadata.RPCURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var rpcRes struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err = json.Unmarshal(resBody, &rpcRes); err != nil {
+		return fmt.Errorf("%s: unexpected response with status code %d", method, res.StatusCode)
+	}
+	if rpcRes.Error != nil {
+		return fmt.Errorf("%s: %w", method, rpcRes.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcRes.Result, result)
+}
+
+// quantity calls a method returning a hex-encoded quantity.
+func (e *Ethereum) quantity(ctx context.Context, method string, params ...any) (*big.Int, error) {
+	var s string
+	if err := e.rpc(ctx, method, &s, params...); err != nil {
+		return nil, err
+	}
+	return parseQuantity(s)
+}
+
+func parseQuantity(s string) (*big.Int, error) {
+	if len(s) < 3 || s[:2] != "0x" {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	n, ok := new(big.Int).SetString(s[2:], 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	return n, nil
+}
+
+func toQuantity(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
+
+// optionalBigInt parses an optional amount of wei, as a JSON number or a decimal or hex string.
+func optionalBigInt(raw json.RawMessage, name string) (*big.Int, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	n, err := toBigInt(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s: must not be negative", name)
+	}
+	return n, nil
+}
+
+// Close stops the subscriptions of the input binding.
+func (e *Ethereum) Close() error {
+	if e.closed.CompareAndSwap(false, true) {
+		close(e.closeCh)
+	}
+	e.wg.Wait()
+	return nil
+}
+
+// OperationsMetadata describes the operations of the Ethereum binding.
+func (e *Ethereum) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: CallOperation,
+			Description: `Calls a contract without sending a transaction. The data holds "to", which defaults to contractAddress, and either the hex-encoded "data", ` +
+				`or a "function" signature and its "args". Returns the hex-encoded "result", and its "values" decoded with the types in "outputs".`,
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation: SendTransactionOperation,
+			Description: `Signs a transaction with privateKey and sends it. The data is that of call, with an optional "value" in wei, "gas" and fees. ` +
+				`Returns the "hash" and "nonce" of the transaction.`,
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        GetBalanceOperation,
+			Description:      `Returns the balance in wei of "address", which defaults to the address of privateKey.`,
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        GetTransactionReceiptOperation,
+			Description:      `Returns the receipt of the transaction "hash", or null while it's pending.`,
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        BlockNumberOperation,
+			Description:      "Returns the number of the latest block.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
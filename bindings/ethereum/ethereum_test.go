@@ -0,0 +1,343 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	testKey      = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testAddress  = "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	testContract = "0x1111111111111111111111111111111111111111"
+)
+
+type rpcCall struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// fakeNode answers the JSON-RPC methods used by the binding with fixed results.
+type fakeNode struct {
+	lock    sync.Mutex
+	calls   []rpcCall
+	results map[string]any
+}
+
+func startNode(t *testing.T) (*fakeNode, *httptest.Server) {
+	t.Helper()
+
+	n := &fakeNode{results: map[string]any{
+		"eth_chainId":              "0x5",
+		"eth_blockNumber":          "0x10",
+		"eth_getBalance":           "0xde0b6b3a7640000",
+		"eth_maxPriorityFeePerGas": "0x2",
+		"eth_getBlockByNumber":     map[string]string{"baseFeePerGas": "0x64"},
+		"eth_estimateGas":          "0x5208",
+		"eth_getTransactionCount":  "0x7",
+		"eth_sendRawTransaction":   "0xabcd",
+		"eth_call":                 "0x00000000000000000000000000000000000000000000000000000000000003e8",
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var call rpcCall
+		json.NewDecoder(r.Body).Decode(&call)
+		n.lock.Lock()
+		n.calls = append(n.calls, call)
+		result, ok := n.results[call.Method]
+		n.lock.Unlock()
+		if !ok {
+			json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{"code": -32601, "message": "method not found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+
+	return n, srv
+}
+
+func (n *fakeNode) call(method string) *rpcCall {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for i := range n.calls {
+		if n.calls[i].Method == method {
+			return &n.calls[i]
+		}
+	}
+	return nil
+}
+
+func initEthereum(t *testing.T, props map[string]string) *Ethereum {
+	t.Helper()
+
+	e := NewEthereum(logger.NewLogger("test")).(*Ethereum)
+	require.NoError(t, e.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	t.Cleanup(func() { e.Close() })
+
+	return e
+}
+
+// rlpDecode decodes the byte strings and lists of an RLP-encoded value.
+func rlpDecode(t *testing.T, b []byte) (any, []byte) {
+	t.Helper()
+
+	prefix := b[0]
+	switch {
+	case prefix < 0x80:
+		return b[:1], b[1:]
+	case prefix < 0xb8:
+		n := int(prefix - 0x80)
+		return b[1 : 1+n], b[1+n:]
+	case prefix < 0xc0:
+		size := int(prefix - 0xb7)
+		n := int(new(big.Int).SetBytes(b[1 : 1+size]).Int64())
+		return b[1+size : 1+size+n], b[1+size+n:]
+	default:
+		n, start := int(prefix-0xc0), 1
+		if prefix >= 0xf8 {
+			size := int(prefix - 0xf7)
+			n, start = int(new(big.Int).SetBytes(b[1:1+size]).Int64()), 1+size
+		}
+		payload, rest := b[start:start+n], b[start+n:]
+		items := []any{}
+		for len(payload) > 0 {
+			var item any
+			item, payload = rlpDecode(t, payload)
+			items = append(items, item)
+		}
+		return items, rest
+	}
+}
+
+func TestInit(t *testing.T) {
+	_, srv := startNode(t)
+
+	tests := map[string]map[string]string{
+		"no URL":                  {},
+		"invalid key":             {"rpcURL": srv.URL, "privateKey": "0x1234"},
+		"invalid contractAddress": {"rpcURL": srv.URL, "contractAddress": "0x12"},
+		"invalid eventSignature":  {"rpcURL": srv.URL, "eventSignature": "Transfer"},
+	}
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEthereum(logger.NewLogger("test"))
+			assert.Error(t, e.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+		})
+	}
+
+	t.Run("chain ID from the node", func(t *testing.T) {
+		e := initEthereum(t, map[string]string{"rpcURL": srv.URL, "privateKey": testKey})
+		assert.Equal(t, int64(5), e.chainID.Int64())
+		assert.Equal(t, testAddress, encodeHex(e.address))
+	})
+}
+
+func TestCall(t *testing.T) {
+	node, srv := startNode(t)
+	e := initEthereum(t, map[string]string{"rpcURL": srv.URL, "contractAddress": testContract})
+
+	res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: CallOperation,
+		Data:      []byte(`{"function": "balanceOf(address)", "args": ["` + testAddress + `"], "outputs": ["uint256"]}`),
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"result": "0x00000000000000000000000000000000000000000000000000000000000003e8", "values": ["1000"]}`, string(res.Data))
+
+	call := node.call("eth_call")
+	require.NotNil(t, call)
+	require.Len(t, call.Params, 2)
+	assert.JSONEq(t, `{"to": "`+testContract+`", "data": "0x70a08231000000000000000000000000`+testAddress[2:]+`"}`, string(call.Params[0]))
+	assert.Equal(t, `"latest"`, string(call.Params[1]))
+
+	t.Run("node error", func(t *testing.T) {
+		node.lock.Lock()
+		delete(node.results, "eth_call")
+		node.lock.Unlock()
+		_, err := e.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Data:      []byte(`{"data": "0x01"}`),
+		})
+		var rpcErr *rpcError
+		require.True(t, errors.As(err, &rpcErr), err)
+		assert.Equal(t, -32601, rpcErr.Code)
+	})
+}
+
+func TestSendTransaction(t *testing.T) {
+	node, srv := startNode(t)
+	e := initEthereum(t, map[string]string{"rpcURL": srv.URL, "privateKey": testKey, "contractAddress": testContract})
+
+	res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: SendTransactionOperation,
+		Data:      []byte(`{"function": "transfer(address,uint256)", "args": ["` + testAddress + `", 5], "value": "1000"}`),
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hash": "0xabcd", "from": "`+testAddress+`", "nonce": 7}`, string(res.Data))
+
+	assert.Equal(t, `"pending"`, string(node.call("eth_getTransactionCount").Params[1]))
+	var raw string
+	require.NoError(t, json.Unmarshal(node.call("eth_sendRawTransaction").Params[0], &raw))
+	signed, err := decodeHex(raw)
+	require.NoError(t, err)
+	require.Equal(t, byte(dynamicFeeTxType), signed[0])
+
+	decoded, rest := rlpDecode(t, signed[1:])
+	assert.Empty(t, rest)
+	fields := decoded.([]any)
+	require.Len(t, fields, 12)
+	callData, err := encodeCall("transfer(address,uint256)", []any{testAddress, "5"})
+	require.NoError(t, err)
+	tx := &transaction{
+		ChainID:              big.NewInt(5),
+		Nonce:                7,
+		MaxPriorityFeePerGas: big.NewInt(2),
+		MaxFeePerGas:         big.NewInt(202),
+		Gas:                  21000,
+		To:                   fields[5].([]byte),
+		Value:                big.NewInt(1000),
+		Data:                 callData,
+	}
+	assert.Equal(t, testContract, encodeHex(tx.To))
+	assert.Equal(t, rlpEncode(tx.fields()), rlpEncode(fields[:9]))
+
+	// Recover the signer from the signature.
+	sig := make([]byte, 65)
+	sig[0] = 27 + byte(new(big.Int).SetBytes(fields[9].([]byte)).Uint64())
+	new(big.Int).SetBytes(fields[10].([]byte)).FillBytes(sig[1:33])
+	new(big.Int).SetBytes(fields[11].([]byte)).FillBytes(sig[33:65])
+	pub, _, err := ecdsa.RecoverCompact(sig, keccak256([]byte{dynamicFeeTxType}, rlpEncode(tx.fields())))
+	require.NoError(t, err)
+	assert.Equal(t, testAddress, encodeHex(publicKeyAddress(pub)))
+
+	t.Run("no key", func(t *testing.T) {
+		e := initEthereum(t, map[string]string{"rpcURL": srv.URL})
+		_, err := e.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: SendTransactionOperation,
+			Data:      []byte(`{"to": "` + testContract + `"}`),
+		})
+		assert.ErrorIs(t, err, errNoKey)
+	})
+}
+
+func TestQueries(t *testing.T) {
+	_, srv := startNode(t)
+	e := initEthereum(t, map[string]string{"rpcURL": srv.URL, "privateKey": testKey})
+
+	res, err := e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: GetBalanceOperation})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"address": "`+testAddress+`", "balance": "1000000000000000000"}`, string(res.Data))
+
+	res, err = e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: BlockNumberOperation})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"blockNumber": 16}`, string(res.Data))
+
+	_, err = e.Invoke(context.Background(), &bindings.InvokeRequest{Operation: GetTransactionReceiptOperation, Data: []byte(`{}`)})
+	assert.Error(t, err)
+}
+
+func TestRead(t *testing.T) {
+	subscribed := make(chan []any, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var req struct {
+			ID     int   `json:"id"`
+			Params []any `json:"params"`
+		}
+		if conn.ReadJSON(&req) != nil {
+			return
+		}
+		subscribed <- req.Params
+		conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "0xsub"})
+		conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "method": "eth_subscription", "params": map[string]any{
+			"subscription": "0xsub",
+			"result": map[string]any{
+				"address":         testContract,
+				"topics":          []string{"0xddf2"},
+				"data":            "0x",
+				"blockNumber":     "0x1b4",
+				"transactionHash": "0xfeed",
+				"logIndex":        "0x2",
+				"removed":         false,
+			},
+		}})
+		// Wait for the client to close the connection.
+		conn.ReadMessage()
+	}))
+	t.Cleanup(srv.Close)
+
+	e := initEthereum(t, map[string]string{
+		"wsURL":           "ws" + strings.TrimPrefix(srv.URL, "http"),
+		"contractAddress": testContract,
+		"eventSignature":  "Transfer(address,address,uint)",
+	})
+	received := make(chan *bindings.ReadResponse, 1)
+	err := e.Read(context.Background(), func(_ context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		received <- res
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	select {
+	case params := <-subscribed:
+		assert.Equal(t, []any{"logs", map[string]any{
+			"address": testContract,
+			"topics":  []any{encodeHex(keccak256([]byte("Transfer(address,address,uint256)")))},
+		}}, params)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscription")
+	}
+
+	select {
+	case res := <-received:
+		assert.Equal(t, map[string]string{
+			readEventKey:           "Transfer(address,address,uint256)",
+			readAddressKey:         testContract,
+			readBlockNumberKey:     "436",
+			readTransactionHashKey: "0xfeed",
+			readLogIndexKey:        "2",
+			readRemovedKey:         "false",
+		}, res.Metadata)
+		var log eventLog
+		require.NoError(t, json.Unmarshal(res.Data, &log))
+		assert.Equal(t, []string{"0xddf2"}, log.Topics)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no log")
+	}
+
+	assert.NoError(t, e.Close())
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/gorilla/websocket"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	subscribeID = 1
+
+	// keys from read response's metadata.
+	readEventKey           = "event"
+	readAddressKey         = "address"
+	readBlockNumberKey     = "blockNumber"
+	readTransactionHashKey = "transactionHash"
+	readLogIndexKey        = "logIndex"
+	readRemovedKey         = "removed"
+)
+
+// eventLog is a log delivered by a logs subscription.
+type eventLog struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+	// Removed is true when the log was removed by a chain reorganization.
+	Removed bool `json:"removed"`
+}
+
+type wsMessage struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+// Read subscribes to the logs of the events of contractAddress, or of the events eventSignature, and delivers them to the handler.
+// The subscription is reestablished when the connection to the node is lost; logs emitted while disconnected are not delivered.
+func (e *Ethereum) Read(ctx context.Context, handler bindings.Handler) error {
+	if e.metadata.WSURL == "" {
+		return errors.New("ethereum binding error: the input binding requires wsURL")
+	}
+	if e.metadata.ContractAddress == "" && e.metadata.EventSignature == "" {
+		return errors.New("ethereum binding error: the input binding requires contractAddress or eventSignature")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	bo := backoff.NewExponentialBackOff()
+	bo.MaxElapsedTime = 0
+
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		select {
+		case <-ctx.Done():
+		case <-e.closeCh:
+		}
+	}()
+	go func() {
+		defer e.wg.Done()
+		for {
+			err := e.subscribe(ctx, handler, bo.Reset)
+			if ctx.Err() != nil {
+				return
+			}
+			wait := bo.NextBackOff()
+			e.logger.Warnf("ethereum: logs subscription failed, reconnecting in %s: %v", wait, err)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// subscribe connects to the node, subscribes to the logs and delivers them until the connection fails or ctx is done.
+func (e *Ethereum) subscribe(ctx context.Context, handler bindings.Handler, onSubscribed func()) error {
+	dialer := websocket.Dialer{HandshakeTimeout: e.metadata.Timeout}
+	conn, _, err := dialer.DialContext(ctx, e.metadata.WSURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// Closing the connection unblocks the reads.
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	filter := map[string]any{}
+	if e.metadata.ContractAddress != "" {
+		filter["address"] = e.metadata.ContractAddress
+	}
+	event := ""
+	if e.metadata.EventSignature != "" {
+		event, _, _ = parseSignature(e.metadata.EventSignature)
+		filter["topics"] = []string{encodeHex(keccak256([]byte(event)))}
+	}
+	err = conn.WriteJSON(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      subscribeID,
+		"method":  "eth_subscribe",
+		"params":  []any{"logs", filter},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	var subscription string
+	for {
+		var msg wsMessage
+		if err = conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+
+		switch {
+		case msg.ID == subscribeID && msg.Error != nil:
+			return fmt.Errorf("failed to subscribe: %w", msg.Error)
+		case msg.ID == subscribeID:
+			if err = json.Unmarshal(msg.Result, &subscription); err != nil {
+				return fmt.Errorf("invalid subscription: %w", err)
+			}
+			e.logger.Debugf("ethereum: subscribed to logs with subscription %s", subscription)
+			onSubscribed()
+		case msg.Method == "eth_subscription" && msg.Params.Subscription == subscription:
+			e.deliver(ctx, handler, event, msg.Params.Result)
+		}
+	}
+}
+
+func (e *Ethereum) deliver(ctx context.Context, handler bindings.Handler, event string, data json.RawMessage) {
+	var log eventLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		e.logger.Errorf("ethereum: invalid log: %v", err)
+		return
+	}
+
+	md := map[string]string{
+		readAddressKey:         log.Address,
+		readBlockNumberKey:     strconv.FormatUint(quantityUint64(log.BlockNumber), 10),
+		readTransactionHashKey: log.TransactionHash,
+		readLogIndexKey:        strconv.FormatUint(quantityUint64(log.LogIndex), 10),
+		readRemovedKey:         strconv.FormatBool(log.Removed),
+	}
+	if event != "" {
+		md[readEventKey] = event
+	}
+	if _, err := handler(ctx, &bindings.ReadResponse{Data: data, Metadata: md}); err != nil {
+		e.logger.Errorf("ethereum: error handling log %s of transaction %s: %v", log.LogIndex, log.TransactionHash, err)
+	}
+}
+
+func quantityUint64(s string) uint64 {
+	n, err := parseQuantity(s)
+	if err != nil || !n.IsUint64() {
+		return 0
+	}
+	return n.Uint64()
+}
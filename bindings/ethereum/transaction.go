@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// dynamicFeeTxType is the type of EIP-1559 transactions.
+const dynamicFeeTxType = 0x02
+
+// transaction is an EIP-1559 transaction.
+type transaction struct {
+	ChainID              *big.Int
+	Nonce                uint64
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+	Gas                  uint64
+	// To is nil for contract creations.
+	To    []byte
+	Value *big.Int
+	Data  []byte
+}
+
+func (tx *transaction) fields() []any {
+	return []any{
+		tx.ChainID,
+		new(big.Int).SetUint64(tx.Nonce),
+		tx.MaxPriorityFeePerGas,
+		tx.MaxFeePerGas,
+		new(big.Int).SetUint64(tx.Gas),
+		tx.To,
+		tx.Value,
+		tx.Data,
+		// Empty access list.
+		[]any{},
+	}
+}
+
+// sign returns the raw signed transaction: 0x02 || rlp([fields..., yParity, r, s]).
+func (tx *transaction) sign(key *secp256k1.PrivateKey) []byte {
+	hash := keccak256([]byte{dynamicFeeTxType}, rlpEncode(tx.fields()))
+
+	// Compact signatures are <27 + recovery id><R><S> for uncompressed keys.
+	sig := ecdsa.SignCompact(key, hash, false)
+	fields := append(tx.fields(),
+		big.NewInt(int64(sig[0]-27)),
+		new(big.Int).SetBytes(sig[1:33]),
+		new(big.Int).SetBytes(sig[33:65]),
+	)
+
+	return append([]byte{dynamicFeeTxType}, rlpEncode(fields)...)
+}
+
+// parsePrivateKey parses a hex-encoded secp256k1 private key, with or without "0x" prefix.
+func parsePrivateKey(s string) (*secp256k1.PrivateKey, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	b, err := decodeHex(s)
+	if err != nil || len(b) != 32 {
+		return nil, errors.New("invalid privateKey: expected 32 hex-encoded bytes")
+	}
+	return secp256k1.PrivKeyFromBytes(b), nil
+}
+
+// publicKeyAddress returns the address of a public key: the last 20 bytes of the hash of its uncompressed coordinates.
+func publicKeyAddress(pub *secp256k1.PublicKey) []byte {
+	return keccak256(pub.SerializeUncompressed()[1:])[12:]
+}
+
+// rlpEncode encodes byte strings, integers and lists of them with the recursive length prefix encoding.
+func rlpEncode(v any) []byte {
+	switch v := v.(type) {
+	case []byte:
+		if len(v) == 1 && v[0] < 0x80 {
+			return v
+		}
+		return append(rlpLength(len(v), 0x80), v...)
+	case *big.Int:
+		if v == nil {
+			return rlpEncode([]byte{})
+		}
+		return rlpEncode(v.Bytes())
+	case []any:
+		var payload []byte
+		for _, item := range v {
+			payload = append(payload, rlpEncode(item)...)
+		}
+		return append(rlpLength(len(payload), 0xc0), payload...)
+	default:
+		panic(fmt.Sprintf("rlp: unsupported type %T", v))
+	}
+}
+
+func rlpLength(n int, offset byte) []byte {
+	if n < 56 {
+		return []byte{offset + byte(n)}
+	}
+	size := big.NewInt(int64(n)).Bytes()
+	return append([]byte{offset + 55 + byte(len(size))}, size...)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// Values of provider.
+	providerLaunchDarkly = "launchdarkly"
+	providerFlagd        = "flagd"
+	providerUnleash      = "unleash"
+
+	EvaluateOperation bindings.OperationKind = "evaluate"
+
+	// Types of the flags.
+	TypeBoolean = "boolean"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeObject  = "object"
+
+	// TargetingKey is the key of the evaluation context identifying the subject of the evaluation, such as a user ID.
+	TargetingKey = "targetingKey"
+
+	// Reasons of the evaluations, in addition to the ones of the providers.
+	ReasonDisabled = "DISABLED"
+	ReasonError    = "ERROR"
+
+	// Error codes of the evaluations returning the default value.
+	ErrorCodeFlagNotFound = "FLAG_NOT_FOUND"
+	ErrorCodeTypeMismatch = "TYPE_MISMATCH"
+)
+
+var (
+	errFlagNotFound = errors.New("flag not found")
+	errTypeMismatch = errors.New("type mismatch")
+)
+
+// FeatureFlags is an output binding evaluating feature flags with LaunchDarkly, flagd or Unleash.
+type FeatureFlags struct {
+	metadata featureFlagsMetadata
+	provider provider
+	logger   logger.Logger
+}
+
+type featureFlagsMetadata struct {
+	// Provider is "launchdarkly", "flagd" or "unleash".
+	Provider string        `mapstructure:"provider"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	// APIKey is the LaunchDarkly mobile key or the Unleash frontend token. Not used by flagd.
+	APIKey string `mapstructure:"apiKey"`
+	// BaseURL overrides the URL of the API, such as the one of a LaunchDarkly Relay Proxy or of a flagd server.
+	// It is required by Unleash.
+	BaseURL string `mapstructure:"baseURL"`
+	// AppName identifies the application to Unleash.
+	AppName string `mapstructure:"appName"`
+}
+
+// Request is the data of the requests.
+type Request struct {
+	// Flag is the key of the flag to evaluate.
+	Flag string `json:"flag"`
+	// Type is the type of the flag: "boolean", "string", "number" or "object".
+	// The type of the default value if empty, else boolean.
+	Type string `json:"type,omitempty"`
+	// Context is the evaluation context, with the targetingKey and the attributes of the subject.
+	Context map[string]interface{} `json:"context,omitempty"`
+	// DefaultValue is returned when the flag is not found or does not have the type.
+	// The evaluation fails in these cases if there is no default value.
+	DefaultValue interface{} `json:"defaultValue,omitempty"`
+}
+
+// Evaluation is the result of an evaluation.
+type Evaluation struct {
+	Flag    string      `json:"flag"`
+	Value   interface{} `json:"value"`
+	Variant string      `json:"variant,omitempty"`
+	Reason  string      `json:"reason,omitempty"`
+	// ErrorCode is set when the default value is returned, e.g. FLAG_NOT_FOUND.
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// provider is the API of a feature flag service.
+type provider interface {
+	evaluate(ctx context.Context, flag string, flagType string, evalCtx map[string]interface{}) (Evaluation, error)
+}
+
+// NewFeatureFlags returns a new feature flags binding.
+func NewFeatureFlags(logger logger.Logger) bindings.OutputBinding {
+	return &FeatureFlags{logger: logger}
+}
+
+// Init parses the metadata and creates the client of the provider.
+func (f *FeatureFlags) Init(meta bindings.Metadata) error {
+	f.metadata = featureFlagsMetadata{Timeout: defaultTimeout}
+	err := metadata.DecodeMetadata(meta.Properties, &f.metadata)
+	if err != nil {
+		return fmt.Errorf("feature flags binding error: %w", err)
+	}
+
+	client := &http.Client{}
+	switch f.metadata.Provider {
+	case providerLaunchDarkly:
+		f.provider, err = newLaunchDarkly(f.metadata, client)
+	case providerFlagd:
+		f.provider = newFlagd(f.metadata, client)
+	case providerUnleash:
+		f.provider, err = newUnleash(f.metadata, client)
+	default:
+		return fmt.Errorf("feature flags binding error: invalid provider %q: must be %s, %s or %s", f.metadata.Provider, providerLaunchDarkly, providerFlagd, providerUnleash)
+	}
+	if err != nil {
+		return fmt.Errorf("feature flags binding error: %w", err)
+	}
+	return nil
+}
+
+// Operations returns the operations supported by the feature flags binding.
+func (f *FeatureFlags) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{EvaluateOperation}
+}
+
+// Invoke runs the operation of the request.
+func (f *FeatureFlags) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != EvaluateOperation {
+		return nil, fmt.Errorf("feature flags binding error: unsupported operation %s", req.Operation)
+	}
+
+	var r Request
+	if err := json.Unmarshal(req.Data, &r); err != nil {
+		return nil, fmt.Errorf("feature flags binding error: invalid request: %w", err)
+	}
+	if strings.TrimSpace(r.Flag) == "" {
+		return nil, errors.New("feature flags binding error: the flag is required")
+	}
+	flagType, err := requestType(r)
+	if err != nil {
+		return nil, fmt.Errorf("feature flags binding error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.metadata.Timeout)
+	defer cancel()
+
+	res, err := f.provider.evaluate(ctx, r.Flag, flagType, r.Context)
+	if err == nil && !hasType(res.Value, flagType) {
+		err = fmt.Errorf("%w: the flag is not a %s", errTypeMismatch, flagType)
+	}
+	if err != nil {
+		code := errorCode(err)
+		if code == "" || r.DefaultValue == nil {
+			return nil, fmt.Errorf("feature flags binding error: evaluate failed: %w", err)
+		}
+		res = Evaluation{Value: r.DefaultValue, Reason: ReasonError, ErrorCode: code}
+	}
+	res.Flag = r.Flag
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("feature flags binding error: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}, nil
+}
+
+// OperationsMetadata describes the operations of the feature flags binding.
+func (f *FeatureFlags) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        EvaluateOperation,
+			Description:      "Returns the value of the flag in the data for the evaluation context, or the default value if the flag is not found or does not have the type.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
+
+// requestType returns the type of the flag of the request.
+func requestType(r Request) (string, error) {
+	switch r.Type {
+	case TypeBoolean, TypeString, TypeNumber, TypeObject:
+		if r.DefaultValue != nil && !hasType(r.DefaultValue, r.Type) {
+			return "", fmt.Errorf("the default value is not a %s", r.Type)
+		}
+		return r.Type, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid type %q", r.Type)
+	}
+
+	switch r.DefaultValue.(type) {
+	case string:
+		return TypeString, nil
+	case float64:
+		return TypeNumber, nil
+	case map[string]interface{}, []interface{}:
+		return TypeObject, nil
+	default:
+		return TypeBoolean, nil
+	}
+}
+
+// hasType returns true if the JSON value has the type; objects accept arrays too.
+func hasType(value interface{}, flagType string) bool {
+	switch value.(type) {
+	case bool:
+		return flagType == TypeBoolean
+	case string:
+		return flagType == TypeString
+	case float64:
+		return flagType == TypeNumber
+	case map[string]interface{}, []interface{}:
+		return flagType == TypeObject
+	default:
+		return false
+	}
+}
+
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, errFlagNotFound):
+		return ErrorCodeFlagNotFound
+	case errors.Is(err, errTypeMismatch):
+		return ErrorCodeTypeMismatch
+	default:
+		return ""
+	}
+}
+
+// statusError is returned when the API responds with an error status.
+type statusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.statusCode, e.body)
+}
+
+// doJSON sends a request with the JSON body, if any, and decodes the JSON response in out.
+func doJSON(ctx context.Context, client *http.Client, method string, u string, header http.Header, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{statusCode: resp.StatusCode, body: strings.TrimSpace(string(b))}
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func newTestFeatureFlags(t *testing.T, properties map[string]string) *FeatureFlags {
+	t.Helper()
+	f := NewFeatureFlags(logger.NewLogger("test")).(*FeatureFlags)
+	require.NoError(t, f.Init(bindings.Metadata{Base: metadata.Base{Properties: properties}}))
+	return f
+}
+
+func evaluate(t *testing.T, f *FeatureFlags, data string) (Evaluation, error) {
+	t.Helper()
+	res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{Operation: EvaluateOperation, Data: []byte(data)})
+	if err != nil {
+		return Evaluation{}, err
+	}
+	assert.Equal(t, string(EvaluateOperation), res.Metadata[bindings.ResponseMetadataOperation])
+	var e Evaluation
+	require.NoError(t, json.Unmarshal(res.Data, &e))
+	return e, nil
+}
+
+func TestInit(t *testing.T) {
+	t.Run("invalid provider", func(t *testing.T) {
+		f := NewFeatureFlags(logger.NewLogger("test")).(*FeatureFlags)
+		err := f.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "split"}}})
+		assert.ErrorContains(t, err, "invalid provider")
+	})
+
+	t.Run("launchdarkly without api key", func(t *testing.T) {
+		f := NewFeatureFlags(logger.NewLogger("test")).(*FeatureFlags)
+		err := f.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "launchdarkly"}}})
+		assert.ErrorContains(t, err, "apiKey is required")
+	})
+
+	t.Run("unleash without base url", func(t *testing.T) {
+		f := NewFeatureFlags(logger.NewLogger("test")).(*FeatureFlags)
+		err := f.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "unleash"}}})
+		assert.ErrorContains(t, err, "baseURL is required")
+	})
+}
+
+func TestInvokeValidation(t *testing.T) {
+	f := newTestFeatureFlags(t, map[string]string{"provider": "flagd"})
+
+	_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation, Data: []byte(`{"flag":"a"}`)})
+	assert.ErrorContains(t, err, "unsupported operation")
+	_, err = evaluate(t, f, `{}`)
+	assert.ErrorContains(t, err, "the flag is required")
+	_, err = evaluate(t, f, `{"flag":"a","type":"integer"}`)
+	assert.ErrorContains(t, err, "invalid type")
+	_, err = evaluate(t, f, `{"flag":"a","type":"string","defaultValue":true}`)
+	assert.ErrorContains(t, err, "the default value is not a string")
+}
+
+func TestFlagd(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req flagdRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case r.URL.Path == "/schema.v1.Service/ResolveBoolean" && req.FlagKey == "new-checkout":
+			assert.Equal(t, "user-1", req.Context[TargetingKey])
+			w.Write([]byte(`{"value":true,"reason":"TARGETING_MATCH","variant":"on"}`))
+		case r.URL.Path == "/schema.v1.Service/ResolveString" && req.FlagKey == "new-checkout":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"invalid_argument","message":"TYPE_MISMATCH"}`))
+		case r.URL.Path == "/schema.v1.Service/ResolveObject" && req.FlagKey == "theme":
+			w.Write([]byte(`{"value":{"color":"blue"},"reason":"STATIC","variant":"blue"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"FLAG_NOT_FOUND"}`))
+		}
+	}))
+	defer srv.Close()
+
+	f := newTestFeatureFlags(t, map[string]string{"provider": "flagd", "baseURL": srv.URL})
+
+	e, err := evaluate(t, f, `{"flag":"new-checkout","context":{"targetingKey":"user-1"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "new-checkout", Value: true, Variant: "on", Reason: "TARGETING_MATCH"}, e)
+
+	e, err = evaluate(t, f, `{"flag":"theme","type":"object"}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "blue"}, e.Value)
+
+	e, err = evaluate(t, f, `{"flag":"new-checkout","defaultValue":"v1"}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "new-checkout", Value: "v1", Reason: ReasonError, ErrorCode: ErrorCodeTypeMismatch}, e)
+
+	e, err = evaluate(t, f, `{"flag":"missing","defaultValue":false}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "missing", Value: false, Reason: ReasonError, ErrorCode: ErrorCodeFlagNotFound}, e)
+
+	_, err = evaluate(t, f, `{"flag":"missing"}`)
+	assert.ErrorContains(t, err, "flag not found")
+}
+
+func TestLaunchDarkly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "mob-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "true", r.URL.Query().Get("withReasons"))
+		require.True(t, strings.HasPrefix(r.URL.Path, "/msdk/evalx/contexts/"))
+		b, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(r.URL.Path, "/msdk/evalx/contexts/"))
+		require.NoError(t, err)
+		var ldCtx map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &ldCtx))
+		assert.Equal(t, map[string]interface{}{"kind": "user", "key": "user-1", "country": "FR"}, ldCtx)
+
+		w.Write([]byte(`{
+			"new-checkout": {"value": true, "variation": 0, "version": 3, "reason": {"kind": "RULE_MATCH"}},
+			"banner": {"value": "summer", "variation": 2, "version": 1, "reason": {"kind": "FALLTHROUGH"}}
+		}`))
+	}))
+	defer srv.Close()
+
+	f := newTestFeatureFlags(t, map[string]string{"provider": "launchdarkly", "baseURL": srv.URL, "apiKey": "mob-key"})
+
+	e, err := evaluate(t, f, `{"flag":"new-checkout","context":{"targetingKey":"user-1","country":"FR"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "new-checkout", Value: true, Variant: "0", Reason: "RULE_MATCH"}, e)
+
+	e, err = evaluate(t, f, `{"flag":"banner","type":"string","context":{"targetingKey":"user-1","country":"FR"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "summer", e.Value)
+
+	e, err = evaluate(t, f, `{"flag":"banner","defaultValue":false,"context":{"targetingKey":"user-1","country":"FR"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCodeTypeMismatch, e.ErrorCode)
+
+	e, err = evaluate(t, f, `{"flag":"missing","defaultValue":0,"context":{"targetingKey":"user-1","country":"FR"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "missing", Value: float64(0), Reason: ReasonError, ErrorCode: ErrorCodeFlagNotFound}, e)
+
+	_, err = evaluate(t, f, `{"flag":"new-checkout"}`)
+	assert.ErrorContains(t, err, "targetingKey of the context is required")
+}
+
+func TestUnleash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/frontend", r.URL.Path)
+		assert.Equal(t, "frontend-token", r.Header.Get("Authorization"))
+		q := r.URL.Query()
+		assert.Equal(t, "shop", q.Get("appName"))
+		assert.Equal(t, "user-1", q.Get("userId"))
+		assert.Equal(t, "s-1", q.Get("sessionId"))
+		assert.Equal(t, "FR", q.Get("properties[country]"))
+
+		w.Write([]byte(`{"toggles": [
+			{"name": "new-checkout", "enabled": true, "variant": {"name": "disabled", "enabled": false}},
+			{"name": "banner", "enabled": true, "variant": {"name": "summer", "enabled": true}},
+			{"name": "limits", "enabled": true, "variant": {"name": "high", "enabled": true, "payload": {"type": "json", "value": "{\"max\":10}"}}}
+		]}`))
+	}))
+	defer srv.Close()
+
+	f := newTestFeatureFlags(t, map[string]string{"provider": "unleash", "baseURL": srv.URL, "apiKey": "frontend-token", "appName": "shop"})
+	evalCtx := `"context":{"targetingKey":"user-1","sessionId":"s-1","country":"FR"}`
+
+	e, err := evaluate(t, f, `{"flag":"new-checkout",`+evalCtx+`}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "new-checkout", Value: true}, e)
+
+	e, err = evaluate(t, f, `{"flag":"old-checkout",`+evalCtx+`}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "old-checkout", Value: false, Reason: ReasonDisabled}, e)
+
+	e, err = evaluate(t, f, `{"flag":"banner","type":"string",`+evalCtx+`}`)
+	require.NoError(t, err)
+	assert.Equal(t, Evaluation{Flag: "banner", Value: "summer", Variant: "summer"}, e)
+
+	e, err = evaluate(t, f, `{"flag":"limits","type":"object",`+evalCtx+`}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"max": float64(10)}, e.Value)
+
+	e, err = evaluate(t, f, `{"flag":"new-checkout","defaultValue":"none",`+evalCtx+`}`)
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCodeTypeMismatch, e.ErrorCode)
+
+	_, err = evaluate(t, f, `{"flag":"missing","type":"string",`+evalCtx+`}`)
+	assert.ErrorContains(t, err, "flag not found")
+}
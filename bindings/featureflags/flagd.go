@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const flagdBaseURL = "http://localhost:8013"
+
+// flagdMethods are the evaluation methods of the flagd service by type of flag.
+// Numbers are resolved as floats, since the integers are encoded as strings in JSON.
+var flagdMethods = map[string]string{
+	TypeBoolean: "ResolveBoolean",
+	TypeString:  "ResolveString",
+	TypeNumber:  "ResolveFloat",
+	TypeObject:  "ResolveObject",
+}
+
+// flagd is the provider of the OpenFeature flagd evaluation service, over its HTTP JSON API.
+type flagd struct {
+	baseURL string
+	client  *http.Client
+}
+
+type flagdRequest struct {
+	FlagKey string                 `json:"flagKey"`
+	Context map[string]interface{} `json:"context"`
+}
+
+type flagdResponse struct {
+	Value   interface{} `json:"value"`
+	Reason  string      `json:"reason"`
+	Variant string      `json:"variant"`
+}
+
+func newFlagd(m featureFlagsMetadata, client *http.Client) *flagd {
+	baseURL := flagdBaseURL
+	if m.BaseURL != "" {
+		baseURL = strings.TrimSuffix(m.BaseURL, "/")
+	}
+	return &flagd{baseURL: baseURL, client: client}
+}
+
+func (f *flagd) evaluate(ctx context.Context, flag string, flagType string, evalCtx map[string]interface{}) (Evaluation, error) {
+	if evalCtx == nil {
+		evalCtx = map[string]interface{}{}
+	}
+
+	var res flagdResponse
+	u := f.baseURL + "/schema.v1.Service/" + flagdMethods[flagType]
+	err := doJSON(ctx, f.client, http.MethodPost, u, nil, flagdRequest{FlagKey: flag, Context: evalCtx}, &res)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.statusCode == http.StatusNotFound || strings.Contains(statusErr.body, ErrorCodeFlagNotFound):
+			return Evaluation{}, fmt.Errorf("%w: %s", errFlagNotFound, statusErr.body)
+		case strings.Contains(statusErr.body, ErrorCodeTypeMismatch):
+			return Evaluation{}, fmt.Errorf("%w: %s", errTypeMismatch, statusErr.body)
+		}
+	}
+	if err != nil {
+		return Evaluation{}, err
+	}
+
+	return Evaluation{Value: res.Value, Variant: res.Variant, Reason: res.Reason}, nil
+}
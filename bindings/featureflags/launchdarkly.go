@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const launchDarklyBaseURL = "https://clientsdk.launchdarkly.com"
+
+// launchDarkly is the provider of the LaunchDarkly mobile evaluation API, also served by the Relay Proxy.
+// It evaluates all the flags of the environment for the context, and returns the one requested.
+type launchDarkly struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type launchDarklyFlag struct {
+	Value     interface{} `json:"value"`
+	Variation *int        `json:"variation"`
+	Reason    *struct {
+		Kind string `json:"kind"`
+	} `json:"reason"`
+}
+
+func newLaunchDarkly(m featureFlagsMetadata, client *http.Client) (*launchDarkly, error) {
+	if m.APIKey == "" {
+		return nil, errors.New("the apiKey is required by LaunchDarkly")
+	}
+	baseURL := launchDarklyBaseURL
+	if m.BaseURL != "" {
+		baseURL = strings.TrimSuffix(m.BaseURL, "/")
+	}
+	return &launchDarkly{baseURL: baseURL, apiKey: m.APIKey, client: client}, nil
+}
+
+func (l *launchDarkly) evaluate(ctx context.Context, flag string, _ string, evalCtx map[string]interface{}) (Evaluation, error) {
+	ldCtx, err := launchDarklyContext(evalCtx)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	b, err := json.Marshal(ldCtx)
+	if err != nil {
+		return Evaluation{}, err
+	}
+
+	var flags map[string]launchDarklyFlag
+	u := l.baseURL + "/msdk/evalx/contexts/" + base64.URLEncoding.EncodeToString(b) + "?withReasons=true"
+	header := http.Header{"Authorization": []string{l.apiKey}}
+	if err = doJSON(ctx, l.client, http.MethodGet, u, header, nil, &flags); err != nil {
+		return Evaluation{}, err
+	}
+
+	f, ok := flags[flag]
+	if !ok {
+		return Evaluation{}, errFlagNotFound
+	}
+	res := Evaluation{Value: f.Value}
+	if f.Variation != nil {
+		res.Variant = strconv.Itoa(*f.Variation)
+	}
+	if f.Reason != nil {
+		res.Reason = f.Reason.Kind
+	}
+	return res, nil
+}
+
+// launchDarklyContext returns the LaunchDarkly context of the evaluation context: the targeting key is the key of
+// the context, and the kind is "user" unless set.
+func launchDarklyContext(evalCtx map[string]interface{}) (map[string]interface{}, error) {
+	ldCtx := make(map[string]interface{}, len(evalCtx)+1)
+	for k, v := range evalCtx {
+		ldCtx[k] = v
+	}
+	if key, ok := ldCtx[TargetingKey]; ok {
+		delete(ldCtx, TargetingKey)
+		ldCtx["key"] = key
+	}
+	if key, _ := ldCtx["key"].(string); key == "" {
+		return nil, fmt.Errorf("the %s of the context is required by LaunchDarkly", TargetingKey)
+	}
+	if _, ok := ldCtx["kind"]; !ok {
+		ldCtx["kind"] = "user"
+	}
+	return ldCtx, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// unleashContextFields are the fields of the Unleash context; the other attributes of the evaluation context are
+// sent as properties.
+var unleashContextFields = map[string]bool{
+	"userId":        true,
+	"sessionId":     true,
+	"remoteAddress": true,
+	"environment":   true,
+	"currentTime":   true,
+}
+
+// unleash is the provider of the Unleash frontend API, served by Unleash and the Unleash proxy or Edge.
+// The API only returns the enabled toggles, so a boolean flag not returned is disabled.
+// The value of the other types is the payload of the variant, or the name of the variant for strings.
+type unleash struct {
+	baseURL string
+	apiKey  string
+	appName string
+	client  *http.Client
+}
+
+type unleashResponse struct {
+	Toggles []unleashToggle `json:"toggles"`
+}
+
+type unleashToggle struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Variant *struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Payload *struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"payload"`
+	} `json:"variant"`
+}
+
+func newUnleash(m featureFlagsMetadata, client *http.Client) (*unleash, error) {
+	if m.BaseURL == "" {
+		return nil, errors.New("the baseURL is required by Unleash")
+	}
+	return &unleash{
+		baseURL: strings.TrimSuffix(m.BaseURL, "/"),
+		apiKey:  m.APIKey,
+		appName: m.AppName,
+		client:  client,
+	}, nil
+}
+
+func (u *unleash) evaluate(ctx context.Context, flag string, flagType string, evalCtx map[string]interface{}) (Evaluation, error) {
+	query := url.Values{}
+	if u.appName != "" {
+		query.Set("appName", u.appName)
+	}
+	for k, v := range evalCtx {
+		switch {
+		case k == TargetingKey:
+			query.Set("userId", fmt.Sprint(v))
+		case unleashContextFields[k]:
+			query.Set(k, fmt.Sprint(v))
+		default:
+			query.Set("properties["+k+"]", fmt.Sprint(v))
+		}
+	}
+
+	var res unleashResponse
+	header := http.Header{}
+	if u.apiKey != "" {
+		header.Set("Authorization", u.apiKey)
+	}
+	if err := doJSON(ctx, u.client, http.MethodGet, u.baseURL+"/api/frontend?"+query.Encode(), header, nil, &res); err != nil {
+		return Evaluation{}, err
+	}
+
+	for _, t := range res.Toggles {
+		if t.Name == flag {
+			return t.evaluation(flagType)
+		}
+	}
+	if flagType == TypeBoolean {
+		return Evaluation{Value: false, Reason: ReasonDisabled}, nil
+	}
+	return Evaluation{}, errFlagNotFound
+}
+
+func (t unleashToggle) evaluation(flagType string) (Evaluation, error) {
+	if flagType == TypeBoolean {
+		res := Evaluation{Value: t.Enabled}
+		if !t.Enabled {
+			res.Reason = ReasonDisabled
+		}
+		if t.Variant != nil && t.Variant.Enabled {
+			res.Variant = t.Variant.Name
+		}
+		return res, nil
+	}
+
+	if t.Variant == nil || !t.Variant.Enabled {
+		return Evaluation{}, fmt.Errorf("%w: the toggle has no variant", errTypeMismatch)
+	}
+	res := Evaluation{Variant: t.Variant.Name}
+	p := t.Variant.Payload
+	switch {
+	case p == nil:
+		res.Value = t.Variant.Name
+	case p.Type == "number":
+		n, err := strconv.ParseFloat(p.Value, 64)
+		if err != nil {
+			return Evaluation{}, fmt.Errorf("%w: invalid number payload: %v", errTypeMismatch, err)
+		}
+		res.Value = n
+	case p.Type == "json":
+		if err := json.Unmarshal([]byte(p.Value), &res.Value); err != nil {
+			return Evaluation{}, fmt.Errorf("%w: invalid json payload: %v", errTypeMismatch, err)
+		}
+	default:
+		res.Value = p.Value
+	}
+	return res, nil
+}
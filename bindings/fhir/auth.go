@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fhir
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	assertionLifetime   = 5 * time.Minute
+	// Tokens are refreshed ahead of their expiration to account for clock skew and in-flight requests.
+	tokenExpiryBuffer = time.Minute
+)
+
+// smartAuth obtains access tokens using the SMART Backend Services profile:
+// a client_credentials grant authenticated with a signed JWT assertion.
+// See https://hl7.org/fhir/smart-app-launch/backend-services.html
+type smartAuth struct {
+	tokenURL   string
+	clientID   string
+	keyID      string
+	scope      string
+	key        interface{}
+	method     jwt.SigningMethod
+	httpClient *http.Client
+
+	lock      sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func newSMARTAuth(m fhirMetadata, httpClient *http.Client) (*smartAuth, error) {
+	a := &smartAuth{
+		tokenURL:   m.TokenURL,
+		clientID:   m.ClientID,
+		keyID:      m.KeyID,
+		scope:      m.Scope,
+		httpClient: httpClient,
+	}
+
+	// SMART requires either RS384 or ES384 signatures
+	pemKey := []byte(m.PrivateKey)
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey); err == nil {
+		a.key = key
+		a.method = jwt.SigningMethodRS384
+	} else if key, err := jwt.ParseECPrivateKeyFromPEM(pemKey); err == nil {
+		a.key = key
+		a.method = jwt.SigningMethodES384
+	} else {
+		return nil, errors.New("privateKey must be a PEM-encoded RSA or EC private key")
+	}
+
+	return a, nil
+}
+
+// Token returns a valid access token, requesting a new one when the cached token is about to expire.
+func (a *smartAuth) Token(ctx context.Context) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.token != "" && time.Now().Before(a.expiresAt) {
+		return a.token, nil
+	}
+
+	assertion, err := a.clientAssertion()
+	if err != nil {
+		return "", fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"scope":                 {a.scope},
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tr tokenResponse
+	if err = json.Unmarshal(body, &tr); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", errors.New("token response did not contain an access token")
+	}
+
+	lifetime := time.Duration(tr.ExpiresIn) * time.Second
+	if lifetime > 2*tokenExpiryBuffer {
+		lifetime -= tokenExpiryBuffer
+	}
+	a.token = tr.AccessToken
+	a.expiresAt = time.Now().Add(lifetime)
+
+	return a.token, nil
+}
+
+func (a *smartAuth) clientAssertion() (string, error) {
+	jti := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(a.method, jwt.RegisteredClaims{
+		Issuer:    a.clientID,
+		Subject:   a.clientID,
+		Audience:  jwt.ClaimStrings{a.tokenURL},
+		ExpiresAt: jwt.NewNumericDate(now.Add(assertionLifetime)),
+		ID:        hex.EncodeToString(jti),
+	})
+	if a.keyID != "" {
+		token.Header["kid"] = a.keyID
+	}
+
+	return token.SignedString(a.key)
+}
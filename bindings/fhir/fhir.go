@@ -0,0 +1,276 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// SearchOperation searches resources of a type.
+	SearchOperation bindings.OperationKind = "search"
+	// ConvertOperation converts an HL7 v2 message to a FHIR resource without sending it to the server.
+	ConvertOperation bindings.OperationKind = "convert"
+
+	resourceTypeKey = "resourceType"
+	idKey           = "id"
+	queryKey        = "query"
+	contentTypeKey  = "contentType"
+
+	fhirContentType  = "application/fhir+json"
+	hl7v2ContentType = "application/hl7-v2"
+
+	maxResponseSize = 16 << 20
+)
+
+// FHIR is an output binding to create, read and search resources on a FHIR R4 server.
+type FHIR struct {
+	metadata   fhirMetadata
+	httpClient *http.Client
+	auth       *smartAuth
+	logger     logger.Logger
+}
+
+// NewFHIR returns a new FHIR output binding.
+func NewFHIR(logger logger.Logger) bindings.OutputBinding {
+	return &FHIR{logger: logger}
+}
+
+// Init performs metadata parsing and prepares the authorization provider.
+func (f *FHIR) Init(metadata bindings.Metadata) error {
+	m, err := parseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("fhir binding error: %w", err)
+	}
+	f.metadata = m
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	f.httpClient = &http.Client{
+		Timeout: m.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+
+	if m.TokenURL != "" {
+		f.auth, err = newSMARTAuth(m, f.httpClient)
+		if err != nil {
+			return fmt.Errorf("fhir binding error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Operations returns list of operations supported by the FHIR binding.
+func (f *FHIR) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		bindings.CreateOperation,
+		bindings.GetOperation,
+		SearchOperation,
+		ConvertOperation,
+	}
+}
+
+// Invoke performs the requested operation against the FHIR server.
+func (f *FHIR) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var (
+		res *bindings.InvokeResponse
+		err error
+	)
+	switch req.Operation {
+	case bindings.CreateOperation:
+		res, err = f.create(ctx, req)
+	case bindings.GetOperation:
+		res, err = f.read(ctx, req)
+	case SearchOperation:
+		res, err = f.search(ctx, req)
+	case ConvertOperation:
+		res, err = f.convert(req)
+	default:
+		err = fmt.Errorf("unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fhir binding error: %w", err)
+	}
+
+	return res, nil
+}
+
+// resourceFromRequest returns the FHIR resource in the request, converting it from HL7 v2 if needed.
+func resourceFromRequest(req *bindings.InvokeRequest) ([]byte, string, error) {
+	if req.Metadata[contentTypeKey] == hl7v2ContentType {
+		data, err := convertHL7v2(req.Data)
+		return data, "Patient", err
+	}
+
+	var resource struct {
+		ResourceType string `json:"resourceType"`
+	}
+	if err := json.Unmarshal(req.Data, &resource); err != nil {
+		return nil, "", fmt.Errorf("request data must be a FHIR resource in JSON format: %w", err)
+	}
+	if resource.ResourceType == "" {
+		return nil, "", errors.New("resource is missing the resourceType property")
+	}
+
+	return req.Data, resource.ResourceType, nil
+}
+
+func (f *FHIR) create(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	data, resourceType, err := resourceFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.do(ctx, http.MethodPost, f.metadata.BaseURL+"/"+url.PathEscape(resourceType), data)
+}
+
+func (f *FHIR) read(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	resourceType := req.Metadata[resourceTypeKey]
+	id := req.Metadata[idKey]
+	if resourceType == "" || id == "" {
+		return nil, fmt.Errorf("metadata properties %s and %s are required", resourceTypeKey, idKey)
+	}
+
+	return f.do(ctx, http.MethodGet, f.metadata.BaseURL+"/"+url.PathEscape(resourceType)+"/"+url.PathEscape(id), nil)
+}
+
+func (f *FHIR) search(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	resourceType := req.Metadata[resourceTypeKey]
+	if resourceType == "" {
+		return nil, fmt.Errorf("metadata property %s is required", resourceTypeKey)
+	}
+
+	// Search parameters are accepted either as a query string in the metadata or as a JSON object in the data
+	query, err := url.ParseQuery(req.Metadata[queryKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	if len(bytes.TrimSpace(req.Data)) > 0 {
+		var params map[string]string
+		if err = json.Unmarshal(req.Data, &params); err != nil {
+			return nil, fmt.Errorf("search parameters must be a JSON object of strings: %w", err)
+		}
+		for k, v := range params {
+			query.Add(k, v)
+		}
+	}
+
+	u := f.metadata.BaseURL + "/" + url.PathEscape(resourceType)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return f.do(ctx, http.MethodGet, u, nil)
+}
+
+// convertHL7v2 converts an HL7 v2 message to a FHIR Patient resource in JSON format.
+func convertHL7v2(data []byte) ([]byte, error) {
+	msg, err := parseHL7v2(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HL7 v2 message: %w", err)
+	}
+	patient, err := msg.patientResource()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(patient)
+}
+
+func (f *FHIR) convert(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	data, err := convertHL7v2(req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := fhirContentType
+	return &bindings.InvokeResponse{
+		Data:        data,
+		ContentType: &contentType,
+	}, nil
+}
+
+func (f *FHIR) do(ctx context.Context, method string, u string, body []byte) (*bindings.InvokeResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", fhirContentType)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", fhirContentType)
+	}
+	if f.auth != nil {
+		token, tokenErr := f.auth.Token(ctx)
+		if tokenErr != nil {
+			return nil, tokenErr
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := f.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request to FHIR server failed: %w", err)
+	}
+	defer func() {
+		// Drain before closing
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Errors are usually described by an OperationOutcome resource in the body
+		return nil, fmt.Errorf("FHIR server returned status %d: %s", resp.StatusCode, string(data))
+	}
+
+	res := &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			"statusCode": strconv.Itoa(resp.StatusCode),
+		},
+	}
+	for key, header := range map[string]string{"location": "Location", "etag": "ETag", "lastModified": "Last-Modified"} {
+		if v := resp.Header.Get(header); v != "" {
+			res.Metadata[key] = v
+		}
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		res.ContentType = &ct
+	}
+
+	return res, nil
+}
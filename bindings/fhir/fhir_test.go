@@ -0,0 +1,233 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fhir
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"baseUrl": "https://fhir.example.com/r4/"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://fhir.example.com/r4", m.BaseURL)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+		assert.Equal(t, defaultScope, m.Scope)
+	})
+
+	t.Run("missing baseUrl", func(t *testing.T) {
+		_, err := parseMetadata(map[string]string{})
+		assert.Error(t, err)
+	})
+
+	t.Run("incomplete SMART settings", func(t *testing.T) {
+		_, err := parseMetadata(map[string]string{"baseUrl": "https://fhir.example.com", "tokenUrl": "https://auth.example.com/token"})
+		assert.Error(t, err)
+	})
+}
+
+func newTestBinding(t *testing.T, props map[string]string) *FHIR {
+	t.Helper()
+
+	f := NewFHIR(logger.NewLogger("test")).(*FHIR)
+	require.NoError(t, f.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	return f
+}
+
+func TestInvoke(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		lastBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", fhirContentType)
+		switch {
+		case r.Method == http.MethodPost:
+			w.Header().Set("Location", "/Patient/1/_history/1")
+			w.Header().Set("ETag", `W/"1"`)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(lastBody)
+		case r.URL.Path == "/Patient/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"resourceType":"OperationOutcome"}`))
+		default:
+			_, _ = w.Write([]byte(`{"resourceType":"Bundle"}`))
+		}
+	}))
+	defer server.Close()
+
+	f := newTestBinding(t, map[string]string{"baseUrl": server.URL})
+
+	t.Run("create", func(t *testing.T) {
+		res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte(`{"resourceType":"Observation","status":"final"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/Observation", lastRequest.URL.Path)
+		assert.Equal(t, fhirContentType, lastRequest.Header.Get("Content-Type"))
+		assert.Equal(t, "201", res.Metadata["statusCode"])
+		assert.Equal(t, "/Patient/1/_history/1", res.Metadata["location"])
+		assert.Equal(t, `W/"1"`, res.Metadata["etag"])
+	})
+
+	t.Run("create from HL7 v2", func(t *testing.T) {
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte(testADT),
+			Metadata:  map[string]string{contentTypeKey: hl7v2ContentType},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/Patient", lastRequest.URL.Path)
+
+		var patient map[string]interface{}
+		require.NoError(t, json.Unmarshal(lastBody, &patient))
+		assert.Equal(t, "1980-01-02", patient["birthDate"])
+	})
+
+	t.Run("create without resourceType", func(t *testing.T) {
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte(`{"status":"final"}`),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{resourceTypeKey: "Patient", idKey: "1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodGet, lastRequest.Method)
+		assert.Equal(t, "/Patient/1", lastRequest.URL.Path)
+	})
+
+	t.Run("read not found", func(t *testing.T) {
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{resourceTypeKey: "Patient", idKey: "missing"},
+		})
+		assert.ErrorContains(t, err, "status 404")
+	})
+
+	t.Run("search", func(t *testing.T) {
+		res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: SearchOperation,
+			Metadata:  map[string]string{resourceTypeKey: "Patient", queryKey: "family=Doe"},
+			Data:      []byte(`{"birthdate":"1980-01-02"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/Patient", lastRequest.URL.Path)
+		assert.Equal(t, "Doe", lastRequest.URL.Query().Get("family"))
+		assert.Equal(t, "1980-01-02", lastRequest.URL.Query().Get("birthdate"))
+		assert.JSONEq(t, `{"resourceType":"Bundle"}`, string(res.Data))
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: ConvertOperation,
+			Data:      []byte(testADT),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, fhirContentType, *res.ContentType)
+		assert.Contains(t, string(res.Data), `"resourceType":"Patient"`)
+	})
+}
+
+func TestSMARTAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var tokenRequests int32
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, clientAssertionType, r.PostForm.Get("client_assertion_type"))
+		assert.Equal(t, "system/Patient.read", r.PostForm.Get("scope"))
+
+		token, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+			assert.Equal(t, jwt.SigningMethodRS384, token.Method)
+			assert.Equal(t, "key-1", token.Header["kid"])
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		claims := token.Claims.(*jwt.RegisteredClaims)
+		assert.Equal(t, "my-client", claims.Issuer)
+		assert.Equal(t, "my-client", claims.Subject)
+		assert.True(t, claims.VerifyAudience(server.URL+"/token", true))
+
+		_, _ = w.Write([]byte(`{"access_token":"abc","token_type":"bearer","expires_in":300}`))
+	})
+	mux.HandleFunc("/fhir/Patient/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"resourceType":"Patient","id":"1"}`))
+	})
+
+	f := newTestBinding(t, map[string]string{
+		"baseUrl":    server.URL + "/fhir",
+		"tokenUrl":   server.URL + "/token",
+		"clientId":   "my-client",
+		"privateKey": string(keyPEM),
+		"keyId":      "key-1",
+		"scope":      "system/Patient.read",
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err = f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{resourceTypeKey: "Patient", idKey: "1"},
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokenRequests), "access token should be cached")
+}
+
+func TestInitInvalidPrivateKey(t *testing.T) {
+	f := NewFHIR(logger.NewLogger("test"))
+	err := f.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"baseUrl":    "https://fhir.example.com",
+		"tokenUrl":   "https://auth.example.com/token",
+		"clientId":   "my-client",
+		"privateKey": "not a key",
+	}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fhir
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// hl7Message is a parsed HL7 v2 message.
+type hl7Message struct {
+	fieldSep     string
+	componentSep string
+	repeatSep    string
+	segments     map[string][][]string
+}
+
+// parseHL7v2 parses an HL7 v2 message using the delimiters declared in its MSH segment.
+func parseHL7v2(data []byte) (*hl7Message, error) {
+	text := strings.TrimSpace(strings.NewReplacer("\r\n", "\r", "\n", "\r").Replace(string(data)))
+	if !strings.HasPrefix(text, "MSH") || len(text) < 8 {
+		return nil, errors.New("message must start with an MSH segment")
+	}
+
+	// MSH-1 is the field separator and MSH-2 the encoding characters (component, repetition, escape, subcomponent)
+	msg := &hl7Message{
+		fieldSep:     text[3:4],
+		componentSep: text[4:5],
+		repeatSep:    text[5:6],
+		segments:     map[string][][]string{},
+	}
+
+	for _, line := range strings.Split(text, "\r") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, msg.fieldSep)
+		if fields[0] == "MSH" {
+			// Shift the fields so that indexes match the HL7 field numbers, since MSH-1 is the separator itself
+			fields = append([]string{"MSH", msg.fieldSep}, fields[1:]...)
+		}
+		msg.segments[fields[0]] = append(msg.segments[fields[0]], fields)
+	}
+
+	return msg, nil
+}
+
+// field returns the value of a field of the first segment with the given name, or an empty string.
+func (m *hl7Message) field(segment string, n int) string {
+	segs := m.segments[segment]
+	if len(segs) == 0 || len(segs[0]) <= n {
+		return ""
+	}
+	return segs[0][n]
+}
+
+// components splits the first repetition of a field into its components.
+func (m *hl7Message) components(value string) []string {
+	value, _, _ = strings.Cut(value, m.repeatSep)
+	return strings.Split(value, m.componentSep)
+}
+
+func component(parts []string, n int) string {
+	if len(parts) < n {
+		return ""
+	}
+	return parts[n-1]
+}
+
+// patientResource converts the PID segment of an HL7 v2 message (e.g. ADT^A01, ADT^A04, ADT^A08) into a FHIR R4 Patient resource.
+func (m *hl7Message) patientResource() (map[string]interface{}, error) {
+	if len(m.segments["PID"]) == 0 {
+		return nil, errors.New("message does not contain a PID segment")
+	}
+
+	patient := map[string]interface{}{
+		"resourceType": "Patient",
+	}
+
+	// PID-3: patient identifier list (ID^check digit^scheme^assigning authority)
+	var identifiers []interface{}
+	for _, rep := range strings.Split(m.field("PID", 3), m.repeatSep) {
+		parts := strings.Split(rep, m.componentSep)
+		if component(parts, 1) == "" {
+			continue
+		}
+		identifier := map[string]interface{}{"value": component(parts, 1)}
+		if authority := component(parts, 4); authority != "" {
+			identifier["system"] = authority
+		}
+		identifiers = append(identifiers, identifier)
+	}
+	if len(identifiers) > 0 {
+		patient["identifier"] = identifiers
+	}
+
+	// PID-5: patient name (family^given^middle^suffix^prefix)
+	if name := m.components(m.field("PID", 5)); component(name, 1) != "" || component(name, 2) != "" {
+		humanName := map[string]interface{}{}
+		if family := component(name, 1); family != "" {
+			humanName["family"] = family
+		}
+		var given []interface{}
+		for _, g := range []string{component(name, 2), component(name, 3)} {
+			if g != "" {
+				given = append(given, g)
+			}
+		}
+		if len(given) > 0 {
+			humanName["given"] = given
+		}
+		if suffix := component(name, 4); suffix != "" {
+			humanName["suffix"] = []interface{}{suffix}
+		}
+		if prefix := component(name, 5); prefix != "" {
+			humanName["prefix"] = []interface{}{prefix}
+		}
+		patient["name"] = []interface{}{humanName}
+	}
+
+	// PID-7: date of birth (YYYYMMDD[HHMM...])
+	if dob := m.field("PID", 7); dob != "" {
+		if len(dob) < 8 {
+			return nil, fmt.Errorf("invalid date of birth '%s'", dob)
+		}
+		patient["birthDate"] = dob[0:4] + "-" + dob[4:6] + "-" + dob[6:8]
+	}
+
+	// PID-8: administrative sex
+	switch m.field("PID", 8) {
+	case "M":
+		patient["gender"] = "male"
+	case "F":
+		patient["gender"] = "female"
+	case "O", "A":
+		patient["gender"] = "other"
+	case "U":
+		patient["gender"] = "unknown"
+	}
+
+	// PID-11: address (street^other^city^state^zip^country)
+	if addr := m.components(m.field("PID", 11)); strings.Join(addr, "") != "" {
+		address := map[string]interface{}{}
+		var lines []interface{}
+		for _, l := range []string{component(addr, 1), component(addr, 2)} {
+			if l != "" {
+				lines = append(lines, l)
+			}
+		}
+		if len(lines) > 0 {
+			address["line"] = lines
+		}
+		for key, n := range map[string]int{"city": 3, "state": 4, "postalCode": 5, "country": 6} {
+			if v := component(addr, n); v != "" {
+				address[key] = v
+			}
+		}
+		patient["address"] = []interface{}{address}
+	}
+
+	// PID-13: home phone number
+	if phone := component(m.components(m.field("PID", 13)), 1); phone != "" {
+		patient["telecom"] = []interface{}{
+			map[string]interface{}{"system": "phone", "value": phone, "use": "home"},
+		}
+	}
+
+	return patient, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fhir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testADT = "MSH|^~\\&|HIS|HOSP|FHIR|DAPR|202301151030||ADT^A04|MSG00001|P|2.5\r" +
+	"EVN|A04|202301151030\r" +
+	"PID|1||123456^^^HOSP~987^^^NATIONAL||Doe^John^Quincy^Jr^Mr||19800102|M|||1 Main St^Apt 2^Springfield^IL^62701^USA||555-1234\r"
+
+func TestParseHL7v2(t *testing.T) {
+	msg, err := parseHL7v2([]byte(testADT))
+	require.NoError(t, err)
+	assert.Equal(t, "ADT^A04", msg.field("MSH", 9))
+	assert.Equal(t, "2.5", msg.field("MSH", 12))
+	assert.Equal(t, "19800102", msg.field("PID", 7))
+	assert.Equal(t, "", msg.field("PID", 99))
+
+	_, err = parseHL7v2([]byte("PID|1||123"))
+	assert.Error(t, err)
+}
+
+func TestPatientResource(t *testing.T) {
+	t.Run("full PID segment", func(t *testing.T) {
+		msg, err := parseHL7v2([]byte(testADT))
+		require.NoError(t, err)
+
+		patient, err := msg.patientResource()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"resourceType": "Patient",
+			"identifier": []interface{}{
+				map[string]interface{}{"value": "123456", "system": "HOSP"},
+				map[string]interface{}{"value": "987", "system": "NATIONAL"},
+			},
+			"name": []interface{}{
+				map[string]interface{}{
+					"family": "Doe",
+					"given":  []interface{}{"John", "Quincy"},
+					"suffix": []interface{}{"Jr"},
+					"prefix": []interface{}{"Mr"},
+				},
+			},
+			"birthDate": "1980-01-02",
+			"gender":    "male",
+			"address": []interface{}{
+				map[string]interface{}{
+					"line":       []interface{}{"1 Main St", "Apt 2"},
+					"city":       "Springfield",
+					"state":      "IL",
+					"postalCode": "62701",
+					"country":    "USA",
+				},
+			},
+			"telecom": []interface{}{
+				map[string]interface{}{"system": "phone", "value": "555-1234", "use": "home"},
+			},
+		}, patient)
+	})
+
+	t.Run("newline separated segments", func(t *testing.T) {
+		msg, err := parseHL7v2([]byte("MSH|^~\\&|A|B\nPID|1||42||Smith^Jane||||||||\n"))
+		require.NoError(t, err)
+
+		patient, err := msg.patientResource()
+		require.NoError(t, err)
+		assert.Equal(t, "Patient", patient["resourceType"])
+		assert.NotContains(t, patient, "gender")
+		assert.NotContains(t, patient, "address")
+	})
+
+	t.Run("missing PID", func(t *testing.T) {
+		msg, err := parseHL7v2([]byte("MSH|^~\\&|A|B\rEVN|A04"))
+		require.NoError(t, err)
+		_, err = msg.patientResource()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fhir
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	defaultScope   = "system/*.read system/*.write"
+)
+
+type fhirMetadata struct {
+	// BaseURL is the base URL of the FHIR R4 server, such as "https://fhir.example.com/r4".
+	BaseURL string `mapstructure:"baseUrl"`
+	// Timeout for requests to the FHIR server.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Settings for SMART backend services authorization.
+	// When TokenURL is empty, requests are sent without an access token.
+	TokenURL   string `mapstructure:"tokenUrl"`
+	ClientID   string `mapstructure:"clientId"`
+	PrivateKey string `mapstructure:"privateKey"`
+	KeyID      string `mapstructure:"keyId"`
+	Scope      string `mapstructure:"scope"`
+}
+
+func parseMetadata(meta map[string]string) (fhirMetadata, error) {
+	m := fhirMetadata{
+		Timeout: defaultTimeout,
+		Scope:   defaultScope,
+	}
+	if err := metadata.DecodeMetadata(meta, &m); err != nil {
+		return m, err
+	}
+
+	if m.BaseURL == "" {
+		return m, errors.New("missing required metadata property: baseUrl")
+	}
+	m.BaseURL = strings.TrimSuffix(m.BaseURL, "/")
+
+	if m.TokenURL != "" {
+		if m.ClientID == "" || m.PrivateKey == "" {
+			return m, errors.New("clientId and privateKey are required when tokenUrl is set")
+		}
+	}
+
+	return m, nil
+}
@@ -41,6 +41,22 @@ const (
 	metadataKey = "key"
 	maxResults  = 1000
 
+	// The object copied or moved, and its bucket if not the bucket of the binding.
+	metadataSourceKey    = "sourceKey"
+	metadataSourceBucket = "sourceBucket"
+	// The storage class set by setTier, e.g. NEARLINE or ARCHIVE.
+	metadataStorageClass = "storageClass"
+	// "true" or "false", for setLegalHold.
+	metadataLegalHold = "legalHold"
+	// The validity of the pre-signed URLs, e.g. "15m".
+	metadataPresignTTL = "presignTTL"
+
+	copyOperation          bindings.OperationKind = "copy"
+	moveOperation          bindings.OperationKind = "move"
+	setTierOperation       bindings.OperationKind = "setTier"
+	setLegalHoldOperation  bindings.OperationKind = "setLegalHold"
+	presignUploadOperation bindings.OperationKind = "presignUpload"
+
 	metadataKeyBC = "name"
 )
 
@@ -123,6 +139,11 @@ func (g *GCPStorage) Operations() []bindings.OperationKind {
 		bindings.GetOperation,
 		bindings.DeleteOperation,
 		bindings.ListOperation,
+		copyOperation,
+		moveOperation,
+		setTierOperation,
+		setLegalHoldOperation,
+		presignUploadOperation,
 	}
 }
 
@@ -138,6 +159,14 @@ func (g *GCPStorage) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*
 		return g.delete(ctx, req)
 	case bindings.ListOperation:
 		return g.list(ctx, req)
+	case copyOperation, moveOperation:
+		return g.copy(ctx, req)
+	case setTierOperation:
+		return g.setTier(ctx, req)
+	case setLegalHoldOperation:
+		return g.setLegalHold(ctx, req)
+	case presignUploadOperation:
+		return g.presignUpload(ctx, req)
 	default:
 		return nil, fmt.Errorf("unsupported operation %s", req.Operation)
 	}
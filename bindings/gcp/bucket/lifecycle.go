@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/utils"
+)
+
+type presignResponse struct {
+	PresignURL string `json:"presignURL"`
+}
+
+// copy copies the object with the sourceKey to the key, and deletes the source object when moving it.
+func (g *GCPStorage) copy(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	sourceKey := req.Metadata[metadataSourceKey]
+	if key == "" || sourceKey == "" {
+		return nil, fmt.Errorf("gcp bucket binding error: required metadata '%s' and '%s' missing", metadataKey, metadataSourceKey)
+	}
+	sourceBucket := g.metadata.Bucket
+	if val := req.Metadata[metadataSourceBucket]; val != "" {
+		sourceBucket = val
+	}
+
+	src := g.client.Bucket(sourceBucket).Object(sourceKey)
+	_, err := g.client.Bucket(g.metadata.Bucket).Object(key).CopierFrom(src).Run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: %s operation failed: %w", req.Operation, err)
+	}
+	if req.Operation == moveOperation {
+		if err = src.Delete(ctx); err != nil {
+			return nil, fmt.Errorf("gcp bucket binding error: object copied to %s, but deleting the source object failed: %w", key, err)
+		}
+	}
+
+	b, err := json.Marshal(createResponse{ObjectURL: fmt.Sprintf(objectURLBase, g.metadata.Bucket, key)})
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error marshalling %s response: %w", req.Operation, err)
+	}
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// setTier changes the storage class of the object by rewriting it in place.
+// The rewrite replaces the metadata of the object with the ones of the request, so the current ones are copied.
+func (g *GCPStorage) setTier(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	storageClass := req.Metadata[metadataStorageClass]
+	if key == "" || storageClass == "" {
+		return nil, fmt.Errorf("gcp bucket binding error: required metadata '%s' and '%s' missing", metadataKey, metadataStorageClass)
+	}
+
+	object := g.client.Bucket(g.metadata.Bucket).Object(key)
+	attrs, err := object.Attrs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: setTier operation failed: %w", err)
+	}
+
+	copier := object.If(storage.Conditions{GenerationMatch: attrs.Generation}).CopierFrom(object)
+	copier.ContentType = attrs.ContentType
+	copier.ContentLanguage = attrs.ContentLanguage
+	copier.ContentEncoding = attrs.ContentEncoding
+	copier.ContentDisposition = attrs.ContentDisposition
+	copier.CacheControl = attrs.CacheControl
+	copier.Metadata = attrs.Metadata
+	copier.StorageClass = storageClass
+	if _, err = copier.Run(ctx); err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: setTier operation failed: %w", err)
+	}
+
+	return &bindings.InvokeResponse{}, nil
+}
+
+// setLegalHold places or removes the temporary hold of the object, which prevents its deletion and replacement.
+// The retention of the objects is set by the retention policy of the bucket.
+func (g *GCPStorage) setLegalHold(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	legalHold := req.Metadata[metadataLegalHold]
+	if key == "" || legalHold == "" {
+		return nil, fmt.Errorf("gcp bucket binding error: required metadata '%s' and '%s' missing", metadataKey, metadataLegalHold)
+	}
+
+	_, err := g.client.Bucket(g.metadata.Bucket).Object(key).Update(ctx, storage.ObjectAttrsToUpdate{
+		TemporaryHold: utils.IsTruthy(legalHold),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: setLegalHold operation failed: %w", err)
+	}
+
+	return &bindings.InvokeResponse{}, nil
+}
+
+// presignUpload returns a V4 signed URL to upload the object with a PUT request, signed with the service account key.
+func (g *GCPStorage) presignUpload(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	ttl := req.Metadata[metadataPresignTTL]
+	if key == "" || ttl == "" {
+		return nil, fmt.Errorf("gcp bucket binding error: required metadata '%s' and '%s' missing", metadataKey, metadataPresignTTL)
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: cannot parse duration %s: %w", ttl, err)
+	}
+
+	url, err := g.client.Bucket(g.metadata.Bucket).SignedURL(key, &storage.SignedURLOptions{
+		GoogleAccessID: g.metadata.ClientEmail,
+		PrivateKey:     []byte(g.metadata.PrivateKey),
+		Method:         http.MethodPut,
+		Expires:        time.Now().Add(d),
+		Scheme:         storage.SigningSchemeV4,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: failed to presign URL: %w", err)
+	}
+
+	b, err := json.Marshal(presignResponse{PresignURL: url})
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error marshalling presign response: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+func TestLifecycleOperations(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPost:
+			w.Write([]byte(`{"done":true,"resource":{"bucket":"bucket","name":"b.txt"}}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(`{"bucket":"bucket","name":"a.txt","generation":"3","contentType":"text/plain","storageClass":"STANDARD"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	gs := &GCPStorage{metadata: &gcpMetadata{Bucket: "bucket"}, client: client, logger: logger.NewLogger("test")}
+
+	t.Run("move", func(t *testing.T) {
+		requests = nil
+		res, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: moveOperation,
+			Metadata:  map[string]string{metadataKey: "b.txt", metadataSourceKey: "a.txt", metadataSourceBucket: "other"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"objectURL":"https://storage.googleapis.com/bucket/b.txt"}`, string(res.Data))
+		assert.Equal(t, []string{
+			"POST /storage/v1/b/other/o/a.txt/rewriteTo/b/bucket/o/b.txt",
+			"DELETE /storage/v1/b/other/o/a.txt",
+		}, requests)
+	})
+
+	t.Run("setTier", func(t *testing.T) {
+		requests = nil
+		_, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setTierOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataStorageClass: "ARCHIVE"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"GET /storage/v1/b/bucket/o/a.txt",
+			"POST /storage/v1/b/bucket/o/a.txt/rewriteTo/b/bucket/o/a.txt",
+		}, requests)
+	})
+
+	t.Run("setLegalHold", func(t *testing.T) {
+		requests = nil
+		_, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: setLegalHoldOperation,
+			Metadata:  map[string]string{metadataKey: "a.txt", metadataLegalHold: "true"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PATCH /storage/v1/b/bucket/o/a.txt"}, requests)
+	})
+
+	t.Run("missing metadata", func(t *testing.T) {
+		_, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: copyOperation,
+			Metadata:  map[string]string{metadataKey: "b.txt"},
+		})
+		assert.ErrorContains(t, err, "sourceKey")
+	})
+}
+
+func TestPresignUpload(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	client, err := storage.NewClient(context.Background(), option.WithoutAuthentication())
+	require.NoError(t, err)
+	gs := &GCPStorage{
+		metadata: &gcpMetadata{Bucket: "bucket", ClientEmail: "sa@project.iam.gserviceaccount.com", PrivateKey: string(keyPEM)},
+		client:   client,
+		logger:   logger.NewLogger("test"),
+	}
+
+	res, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: presignUploadOperation,
+		Metadata:  map[string]string{metadataKey: "a.txt", metadataPresignTTL: "15m"},
+	})
+	require.NoError(t, err)
+	var presign presignResponse
+	require.NoError(t, json.Unmarshal(res.Data, &presign))
+	assert.Contains(t, presign.PresignURL, "https://storage.googleapis.com/bucket/a.txt?")
+	assert.Contains(t, presign.PresignURL, "X-Goog-Expires=")
+	assert.Contains(t, presign.PresignURL, "X-Goog-Signature=")
+
+	_, err = gs.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: presignUploadOperation,
+		Metadata:  map[string]string{metadataKey: "a.txt"},
+	})
+	assert.ErrorContains(t, err, "presignTTL")
+}
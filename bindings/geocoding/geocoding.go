@@ -0,0 +1,332 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultMode      = "driving"
+	defaultUserAgent = "dapr-geocoding-binding"
+
+	// Values of provider.
+	providerGoogle    = "google"
+	providerMapbox    = "mapbox"
+	providerNominatim = "nominatim"
+
+	// keys from request's metadata.
+	languageKey = "language"
+
+	GeocodeOperation        bindings.OperationKind = "geocode"
+	ReverseGeocodeOperation bindings.OperationKind = "reverseGeocode"
+	DistanceMatrixOperation bindings.OperationKind = "distanceMatrix"
+
+	// Status of the elements of a distance matrix.
+	StatusOK       = "OK"
+	StatusNotFound = "NOT_FOUND"
+
+	earthRadiusMeters = 6371008.8
+)
+
+// Geocoding is an output binding geocoding addresses and computing distances with Google Maps, Mapbox or Nominatim.
+type Geocoding struct {
+	metadata geocodingMetadata
+	provider provider
+	logger   logger.Logger
+}
+
+type geocodingMetadata struct {
+	// Provider is "google", "mapbox" or "nominatim".
+	Provider string        `mapstructure:"provider"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	// APIKey is the Google Maps API key or the Mapbox access token. Not used by Nominatim.
+	APIKey string `mapstructure:"apiKey"`
+	// BaseURL overrides the URL of the API, such as the one of a self-hosted Nominatim server.
+	BaseURL string `mapstructure:"baseURL"`
+	// Language is the default language of the addresses, as an IETF language tag.
+	Language string `mapstructure:"language"`
+	// Mode is the default travel mode of the distance matrices: "driving", "walking" or "cycling".
+	Mode string `mapstructure:"mode"`
+	// UserAgent identifies the application to Nominatim, as required by its usage policy.
+	UserAgent string `mapstructure:"userAgent"`
+}
+
+// Location is a point, in decimal degrees.
+type Location struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+}
+
+// Place is a result of a geocoding.
+type Place struct {
+	// ID is the identifier of the place for the provider.
+	ID       string   `json:"id,omitempty"`
+	Address  string   `json:"address"`
+	Location Location `json:"location"`
+}
+
+// Request is the data of the requests.
+type Request struct {
+	// Address is the address to geocode.
+	Address string `json:"address,omitempty"`
+	// Location is the point to reverse geocode.
+	Location *Location `json:"location,omitempty"`
+	// Limit is the maximum number of places returned; all of them if zero.
+	Limit int `json:"limit,omitempty"`
+	// Origins and Destinations are the points of the distance matrix.
+	Origins      []Location `json:"origins,omitempty"`
+	Destinations []Location `json:"destinations,omitempty"`
+	// Mode is the travel mode of the distance matrix; the mode metadata if empty.
+	Mode string `json:"mode,omitempty"`
+}
+
+// Element is the route from an origin to a destination.
+type Element struct {
+	// Status is OK, or NOT_FOUND if there is no route.
+	Status string `json:"status"`
+	// Distance is the distance in meters.
+	Distance float64 `json:"distance"`
+	// Duration is the travel time in seconds. Zero if the provider doesn't compute routes.
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// DistanceMatrix has a row by origin, with an element by destination.
+type DistanceMatrix struct {
+	Rows [][]Element `json:"rows"`
+}
+
+// provider is the API of a geocoding service.
+type provider interface {
+	geocode(ctx context.Context, address string, language string, limit int) ([]Place, error)
+	reverseGeocode(ctx context.Context, location Location, language string, limit int) ([]Place, error)
+	distanceMatrix(ctx context.Context, origins []Location, destinations []Location, mode string) ([][]Element, error)
+}
+
+// NewGeocoding returns a new geocoding binding.
+func NewGeocoding(logger logger.Logger) bindings.OutputBinding {
+	return &Geocoding{logger: logger}
+}
+
+// Init parses the metadata and creates the client of the provider.
+func (g *Geocoding) Init(meta bindings.Metadata) error {
+	g.metadata = geocodingMetadata{Timeout: defaultTimeout, Mode: defaultMode, UserAgent: defaultUserAgent}
+	err := metadata.DecodeMetadata(meta.Properties, &g.metadata)
+	if err != nil {
+		return fmt.Errorf("geocoding binding error: %w", err)
+	}
+	if !validMode(g.metadata.Mode) {
+		return fmt.Errorf("geocoding binding error: invalid mode %q", g.metadata.Mode)
+	}
+
+	client := &http.Client{}
+	switch g.metadata.Provider {
+	case providerGoogle:
+		g.provider, err = newGoogle(g.metadata, client)
+	case providerMapbox:
+		g.provider, err = newMapbox(g.metadata, client)
+	case providerNominatim:
+		g.provider = newNominatim(g.metadata, client)
+	default:
+		return fmt.Errorf("geocoding binding error: invalid provider %q: must be %s, %s or %s", g.metadata.Provider, providerGoogle, providerMapbox, providerNominatim)
+	}
+	if err != nil {
+		return fmt.Errorf("geocoding binding error: %w", err)
+	}
+	return nil
+}
+
+// Operations returns the operations supported by the geocoding binding.
+func (g *Geocoding) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{GeocodeOperation, ReverseGeocodeOperation, DistanceMatrixOperation}
+}
+
+// Invoke runs the operation of the request.
+func (g *Geocoding) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var r Request
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &r); err != nil {
+			return nil, fmt.Errorf("geocoding binding error: invalid request: %w", err)
+		}
+	}
+	if r.Limit < 0 {
+		return nil, errors.New("geocoding binding error: the limit must not be negative")
+	}
+	language := g.metadata.Language
+	if l := req.Metadata[languageKey]; l != "" {
+		language = l
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.metadata.Timeout)
+	defer cancel()
+
+	var (
+		res interface{}
+		err error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case GeocodeOperation:
+		if strings.TrimSpace(r.Address) == "" {
+			return nil, errors.New("geocoding binding error: the address is required")
+		}
+		var places []Place
+		places, err = g.provider.geocode(ctx, r.Address, language, r.Limit)
+		res = limitPlaces(places, r.Limit)
+	case ReverseGeocodeOperation:
+		if r.Location == nil {
+			return nil, errors.New("geocoding binding error: the location is required")
+		}
+		if err = validateLocation(*r.Location); err != nil {
+			return nil, fmt.Errorf("geocoding binding error: %w", err)
+		}
+		var places []Place
+		places, err = g.provider.reverseGeocode(ctx, *r.Location, language, r.Limit)
+		res = limitPlaces(places, r.Limit)
+	case DistanceMatrixOperation:
+		if len(r.Origins) == 0 || len(r.Destinations) == 0 {
+			return nil, errors.New("geocoding binding error: the origins and destinations are required")
+		}
+		for _, l := range append(append([]Location{}, r.Origins...), r.Destinations...) {
+			if err = validateLocation(l); err != nil {
+				return nil, fmt.Errorf("geocoding binding error: %w", err)
+			}
+		}
+		mode := g.metadata.Mode
+		if r.Mode != "" {
+			if !validMode(r.Mode) {
+				return nil, fmt.Errorf("geocoding binding error: invalid mode %q", r.Mode)
+			}
+			mode = r.Mode
+		}
+		var rows [][]Element
+		rows, err = g.provider.distanceMatrix(ctx, r.Origins, r.Destinations, mode)
+		res = DistanceMatrix{Rows: rows}
+	default:
+		return nil, fmt.Errorf("geocoding binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("geocoding binding error: %s failed: %w", req.Operation, err)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("geocoding binding error: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}, nil
+}
+
+// OperationsMetadata describes the operations of the geocoding binding.
+func (g *Geocoding) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        GeocodeOperation,
+			Description:      "Returns the places matching the address in the data, best match first.",
+			RequestMetadata:  []string{languageKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        ReverseGeocodeOperation,
+			Description:      "Returns the addresses of the location in the data, most precise first.",
+			RequestMetadata:  []string{languageKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        DistanceMatrixOperation,
+			Description:      "Returns the distances and travel times from each origin to each destination in the data. Nominatim returns the great-circle distances.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
+
+func validMode(mode string) bool {
+	return mode == "driving" || mode == "walking" || mode == "cycling"
+}
+
+func validateLocation(l Location) error {
+	if l.Latitude < -90 || l.Latitude > 90 || l.Longitude < -180 || l.Longitude > 180 {
+		return fmt.Errorf("invalid location %v,%v", l.Latitude, l.Longitude)
+	}
+	return nil
+}
+
+func limitPlaces(places []Place, limit int) []Place {
+	if places == nil {
+		return []Place{}
+	}
+	if limit > 0 && len(places) > limit {
+		return places[:limit]
+	}
+	return places
+}
+
+// haversine returns the great-circle distance in meters between the locations.
+func haversine(a Location, b Location) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// getJSON sends a GET request and decodes the JSON response in out.
+func getJSON(ctx context.Context, client *http.Client, u string, header http.Header, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL has the API key in the query string.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
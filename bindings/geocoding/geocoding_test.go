@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func newTestGeocoding(t *testing.T, properties map[string]string) *Geocoding {
+	t.Helper()
+	g := NewGeocoding(logger.NewLogger("test")).(*Geocoding)
+	require.NoError(t, g.Init(bindings.Metadata{Base: metadata.Base{Properties: properties}}))
+	return g
+}
+
+func invoke(t *testing.T, g *Geocoding, op bindings.OperationKind, data string, out interface{}) error {
+	t.Helper()
+	res, err := g.Invoke(context.Background(), &bindings.InvokeRequest{Operation: op, Data: []byte(data)})
+	if err != nil {
+		return err
+	}
+	assert.Equal(t, string(op), res.Metadata[bindings.ResponseMetadataOperation])
+	return json.Unmarshal(res.Data, out)
+}
+
+func TestInit(t *testing.T) {
+	t.Run("invalid provider", func(t *testing.T) {
+		g := NewGeocoding(logger.NewLogger("test")).(*Geocoding)
+		err := g.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "here"}}})
+		assert.ErrorContains(t, err, "invalid provider")
+	})
+
+	t.Run("google without api key", func(t *testing.T) {
+		g := NewGeocoding(logger.NewLogger("test")).(*Geocoding)
+		err := g.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "google"}}})
+		assert.ErrorContains(t, err, "apiKey is required")
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		g := NewGeocoding(logger.NewLogger("test")).(*Geocoding)
+		err := g.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"provider": "nominatim", "mode": "flying"}}})
+		assert.ErrorContains(t, err, "invalid mode")
+	})
+}
+
+func TestInvokeValidation(t *testing.T) {
+	g := newTestGeocoding(t, map[string]string{"provider": "nominatim"})
+	var out interface{}
+
+	assert.ErrorContains(t, invoke(t, g, GeocodeOperation, `{}`, &out), "the address is required")
+	assert.ErrorContains(t, invoke(t, g, ReverseGeocodeOperation, `{}`, &out), "the location is required")
+	assert.ErrorContains(t, invoke(t, g, ReverseGeocodeOperation, `{"location":{"lat":91,"lng":0}}`, &out), "invalid location")
+	assert.ErrorContains(t, invoke(t, g, DistanceMatrixOperation, `{"origins":[{"lat":0,"lng":0}]}`, &out), "the origins and destinations are required")
+	assert.ErrorContains(t, invoke(t, g, DistanceMatrixOperation, `{"origins":[{"lat":0,"lng":0}],"destinations":[{"lat":0,"lng":0}],"mode":"flying"}`, &out), "invalid mode")
+}
+
+func TestGoogle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "key1", q.Get("key"))
+		switch r.URL.Path {
+		case "/geocode/json":
+			if q.Get("address") == "nowhere" {
+				w.Write([]byte(`{"status":"ZERO_RESULTS","results":[]}`))
+				return
+			}
+			if q.Get("address") == "denied" {
+				w.Write([]byte(`{"status":"REQUEST_DENIED","error_message":"The provided API key is invalid."}`))
+				return
+			}
+			assert.Equal(t, "fr", q.Get("language"))
+			w.Write([]byte(`{"status":"OK","results":[
+				{"place_id":"p1","formatted_address":"Tour Eiffel, Paris","geometry":{"location":{"lat":48.8584,"lng":2.2945}}},
+				{"place_id":"p2","formatted_address":"Paris","geometry":{"location":{"lat":48.8566,"lng":2.3522}}}]}`))
+		case "/distancematrix/json":
+			assert.Equal(t, "48.8584,2.2945", q.Get("origins"))
+			assert.Equal(t, "48.8566,2.3522|51.5074,-0.1278", q.Get("destinations"))
+			assert.Equal(t, "bicycling", q.Get("mode"))
+			w.Write([]byte(`{"status":"OK","rows":[{"elements":[
+				{"status":"OK","distance":{"value":4300},"duration":{"value":1080}},
+				{"status":"ZERO_RESULTS"}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	g := newTestGeocoding(t, map[string]string{"provider": "google", "apiKey": "key1", "baseURL": srv.URL, "language": "fr"})
+
+	var places []Place
+	require.NoError(t, invoke(t, g, GeocodeOperation, `{"address":"Tour Eiffel","limit":1}`, &places))
+	assert.Equal(t, []Place{{ID: "p1", Address: "Tour Eiffel, Paris", Location: Location{Latitude: 48.8584, Longitude: 2.2945}}}, places)
+
+	require.NoError(t, invoke(t, g, GeocodeOperation, `{"address":"nowhere"}`, &places))
+	assert.Empty(t, places)
+
+	err := invoke(t, g, GeocodeOperation, `{"address":"denied"}`, &places)
+	assert.ErrorContains(t, err, "REQUEST_DENIED: The provided API key is invalid.")
+
+	var matrix DistanceMatrix
+	require.NoError(t, invoke(t, g, DistanceMatrixOperation, `{
+		"origins":[{"lat":48.8584,"lng":2.2945}],
+		"destinations":[{"lat":48.8566,"lng":2.3522},{"lat":51.5074,"lng":-0.1278}],
+		"mode":"cycling"}`, &matrix))
+	assert.Equal(t, [][]Element{{{Status: StatusOK, Distance: 4300, Duration: 1080}, {Status: StatusNotFound}}}, matrix.Rows)
+}
+
+func TestMapbox(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "token1", q.Get("access_token"))
+		switch r.URL.Path {
+		case "/geocoding/v5/mapbox.places/2.2945,48.8584.json":
+			assert.Empty(t, q.Get("limit"))
+			w.Write([]byte(`{"features":[{"id":"poi.1","place_name":"Tour Eiffel","center":[2.2945,48.8584]}]}`))
+		case "/directions-matrix/v1/mapbox/walking/2.2945,48.8584;2.3522,48.8566":
+			assert.Equal(t, "0", q.Get("sources"))
+			assert.Equal(t, "1", q.Get("destinations"))
+			w.Write([]byte(`{"code":"Ok","distances":[[4712.5]],"durations":[[3400.1]]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	g := newTestGeocoding(t, map[string]string{"provider": "mapbox", "apiKey": "token1", "baseURL": srv.URL, "mode": "walking"})
+
+	var places []Place
+	require.NoError(t, invoke(t, g, ReverseGeocodeOperation, `{"location":{"lat":48.8584,"lng":2.2945},"limit":1}`, &places))
+	assert.Equal(t, []Place{{ID: "poi.1", Address: "Tour Eiffel", Location: Location{Latitude: 48.8584, Longitude: 2.2945}}}, places)
+
+	var matrix DistanceMatrix
+	require.NoError(t, invoke(t, g, DistanceMatrixOperation, `{"origins":[{"lat":48.8584,"lng":2.2945}],"destinations":[{"lat":48.8566,"lng":2.3522}]}`, &matrix))
+	assert.Equal(t, [][]Element{{{Status: StatusOK, Distance: 4712.5, Duration: 3400.1}}}, matrix.Rows)
+
+	err := invoke(t, g, GeocodeOperation, `{"address":"x"}`, &places)
+	assert.ErrorContains(t, err, "status 404")
+	assert.NotContains(t, err.Error(), "token1")
+}
+
+func TestNominatim(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "jsonv2", q.Get("format"))
+		assert.Equal(t, "my-app", r.Header.Get("User-Agent"))
+		switch r.URL.Path {
+		case "/search":
+			assert.Equal(t, "2", q.Get("limit"))
+			w.Write([]byte(`[{"place_id":42,"display_name":"Big Ben, London","lat":"51.5007","lon":"-0.1246"}]`))
+		case "/reverse":
+			w.Write([]byte(`{"error":"Unable to geocode"}`))
+		}
+	}))
+	defer srv.Close()
+
+	g := newTestGeocoding(t, map[string]string{"provider": "nominatim", "baseURL": srv.URL, "userAgent": "my-app"})
+
+	var places []Place
+	require.NoError(t, invoke(t, g, GeocodeOperation, `{"address":"Big Ben","limit":2}`, &places))
+	assert.Equal(t, []Place{{ID: "42", Address: "Big Ben, London", Location: Location{Latitude: 51.5007, Longitude: -0.1246}}}, places)
+
+	require.NoError(t, invoke(t, g, ReverseGeocodeOperation, `{"location":{"lat":0,"lng":0}}`, &places))
+	assert.Empty(t, places)
+
+	var matrix DistanceMatrix
+	require.NoError(t, invoke(t, g, DistanceMatrixOperation, `{"origins":[{"lat":48.8566,"lng":2.3522}],"destinations":[{"lat":51.5074,"lng":-0.1278},{"lat":48.8566,"lng":2.3522}]}`, &matrix))
+	require.Len(t, matrix.Rows, 1)
+	assert.InDelta(t, 343500, matrix.Rows[0][0].Distance, 1000)
+	assert.Equal(t, Element{Status: StatusOK}, matrix.Rows[0][1])
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const googleBaseURL = "https://maps.googleapis.com/maps/api"
+
+// google is the provider of the Google Maps Geocoding and Distance Matrix APIs.
+type google struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type googleGeocodeResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Results      []struct {
+		PlaceID          string `json:"place_id"`
+		FormattedAddress string `json:"formatted_address"`
+		Geometry         struct {
+			Location Location `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+}
+
+type googleDistanceMatrixResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Rows         []struct {
+		Elements []struct {
+			Status   string `json:"status"`
+			Distance struct {
+				Value float64 `json:"value"`
+			} `json:"distance"`
+			Duration struct {
+				Value float64 `json:"value"`
+			} `json:"duration"`
+		} `json:"elements"`
+	} `json:"rows"`
+}
+
+func newGoogle(m geocodingMetadata, client *http.Client) (*google, error) {
+	if m.APIKey == "" {
+		return nil, errors.New("apiKey is required")
+	}
+	baseURL := googleBaseURL
+	if m.BaseURL != "" {
+		baseURL = strings.TrimSuffix(m.BaseURL, "/")
+	}
+	return &google{baseURL: baseURL, apiKey: m.APIKey, client: client}, nil
+}
+
+func (g *google) geocode(ctx context.Context, address string, language string, _ int) ([]Place, error) {
+	return g.places(ctx, url.Values{"address": []string{address}}, language)
+}
+
+func (g *google) reverseGeocode(ctx context.Context, location Location, language string, _ int) ([]Place, error) {
+	return g.places(ctx, url.Values{"latlng": []string{googleLocation(location)}}, language)
+}
+
+func (g *google) places(ctx context.Context, query url.Values, language string) ([]Place, error) {
+	query.Set("key", g.apiKey)
+	if language != "" {
+		query.Set("language", language)
+	}
+
+	var res googleGeocodeResponse
+	if err := getJSON(ctx, g.client, g.baseURL+"/geocode/json?"+query.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+	switch res.Status {
+	case StatusOK:
+	case "ZERO_RESULTS":
+		return nil, nil
+	default:
+		return nil, googleError(res.Status, res.ErrorMessage)
+	}
+
+	places := make([]Place, len(res.Results))
+	for i, r := range res.Results {
+		places[i] = Place{ID: r.PlaceID, Address: r.FormattedAddress, Location: r.Geometry.Location}
+	}
+	return places, nil
+}
+
+func (g *google) distanceMatrix(ctx context.Context, origins []Location, destinations []Location, mode string) ([][]Element, error) {
+	if mode == "cycling" {
+		mode = "bicycling"
+	}
+	query := url.Values{
+		"origins":      []string{googleLocations(origins)},
+		"destinations": []string{googleLocations(destinations)},
+		"mode":         []string{mode},
+		"key":          []string{g.apiKey},
+	}
+
+	var res googleDistanceMatrixResponse
+	if err := getJSON(ctx, g.client, g.baseURL+"/distancematrix/json?"+query.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+	if res.Status != StatusOK {
+		return nil, googleError(res.Status, res.ErrorMessage)
+	}
+	if len(res.Rows) != len(origins) {
+		return nil, fmt.Errorf("invalid response: %d rows for %d origins", len(res.Rows), len(origins))
+	}
+
+	rows := make([][]Element, len(res.Rows))
+	for i, r := range res.Rows {
+		if len(r.Elements) != len(destinations) {
+			return nil, fmt.Errorf("invalid response: %d elements for %d destinations", len(r.Elements), len(destinations))
+		}
+		rows[i] = make([]Element, len(r.Elements))
+		for j, e := range r.Elements {
+			if e.Status != StatusOK {
+				rows[i][j] = Element{Status: StatusNotFound}
+				continue
+			}
+			rows[i][j] = Element{Status: StatusOK, Distance: e.Distance.Value, Duration: e.Duration.Value}
+		}
+	}
+	return rows, nil
+}
+
+func googleError(status string, message string) error {
+	if message != "" {
+		return fmt.Errorf("request failed with status %s: %s", status, message)
+	}
+	return fmt.Errorf("request failed with status %s", status)
+}
+
+func googleLocation(l Location) string {
+	return strconv.FormatFloat(l.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(l.Longitude, 'f', -1, 64)
+}
+
+func googleLocations(locations []Location) string {
+	s := make([]string, len(locations))
+	for i, l := range locations {
+		s[i] = googleLocation(l)
+	}
+	return strings.Join(s, "|")
+}
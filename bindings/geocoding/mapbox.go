@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	mapboxBaseURL = "https://api.mapbox.com"
+	// mapboxMaxCoordinates is the maximum number of points of a matrix request.
+	mapboxMaxCoordinates = 25
+)
+
+// mapbox is the provider of the Mapbox Geocoding and Matrix APIs.
+type mapbox struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+type mapboxGeocodeResponse struct {
+	Features []struct {
+		ID        string `json:"id"`
+		PlaceName string `json:"place_name"`
+		// Center is longitude, latitude.
+		Center []float64 `json:"center"`
+	} `json:"features"`
+}
+
+type mapboxMatrixResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Distances and Durations are null when there is no route.
+	Distances [][]*float64 `json:"distances"`
+	Durations [][]*float64 `json:"durations"`
+}
+
+func newMapbox(m geocodingMetadata, client *http.Client) (*mapbox, error) {
+	if m.APIKey == "" {
+		return nil, errors.New("apiKey is required")
+	}
+	baseURL := mapboxBaseURL
+	if m.BaseURL != "" {
+		baseURL = strings.TrimSuffix(m.BaseURL, "/")
+	}
+	return &mapbox{baseURL: baseURL, token: m.APIKey, client: client}, nil
+}
+
+func (m *mapbox) geocode(ctx context.Context, address string, language string, limit int) ([]Place, error) {
+	return m.places(ctx, address, language, limit)
+}
+
+// reverseGeocode doesn't send the limit, which Mapbox only accepts with a single type of places for reverse queries.
+func (m *mapbox) reverseGeocode(ctx context.Context, location Location, language string, _ int) ([]Place, error) {
+	return m.places(ctx, mapboxLocation(location), language, 0)
+}
+
+func (m *mapbox) places(ctx context.Context, search string, language string, limit int) ([]Place, error) {
+	query := url.Values{"access_token": []string{m.token}}
+	if language != "" {
+		query.Set("language", language)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var res mapboxGeocodeResponse
+	u := m.baseURL + "/geocoding/v5/mapbox.places/" + url.PathEscape(search) + ".json?" + query.Encode()
+	if err := getJSON(ctx, m.client, u, nil, &res); err != nil {
+		return nil, err
+	}
+
+	places := make([]Place, 0, len(res.Features))
+	for _, f := range res.Features {
+		if len(f.Center) != 2 {
+			continue
+		}
+		places = append(places, Place{ID: f.ID, Address: f.PlaceName, Location: Location{Latitude: f.Center[1], Longitude: f.Center[0]}})
+	}
+	return places, nil
+}
+
+// distanceMatrix sends the origins then the destinations as the coordinates, and selects them as the sources and
+// the destinations of the matrix.
+func (m *mapbox) distanceMatrix(ctx context.Context, origins []Location, destinations []Location, mode string) ([][]Element, error) {
+	if len(origins)+len(destinations) > mapboxMaxCoordinates {
+		return nil, fmt.Errorf("mapbox supports up to %d origins and destinations", mapboxMaxCoordinates)
+	}
+
+	coordinates := make([]string, 0, len(origins)+len(destinations))
+	sources := make([]string, len(origins))
+	for i, l := range origins {
+		coordinates = append(coordinates, mapboxLocation(l))
+		sources[i] = strconv.Itoa(i)
+	}
+	targets := make([]string, len(destinations))
+	for i, l := range destinations {
+		coordinates = append(coordinates, mapboxLocation(l))
+		targets[i] = strconv.Itoa(len(origins) + i)
+	}
+	query := url.Values{
+		"sources":      []string{strings.Join(sources, ";")},
+		"destinations": []string{strings.Join(targets, ";")},
+		"annotations":  []string{"distance,duration"},
+		"access_token": []string{m.token},
+	}
+
+	var res mapboxMatrixResponse
+	u := m.baseURL + "/directions-matrix/v1/mapbox/" + mode + "/" + strings.Join(coordinates, ";") + "?" + query.Encode()
+	if err := getJSON(ctx, m.client, u, nil, &res); err != nil {
+		return nil, err
+	}
+	if res.Code != "Ok" {
+		return nil, fmt.Errorf("request failed with code %s: %s", res.Code, res.Message)
+	}
+	if len(res.Distances) != len(origins) || len(res.Durations) != len(origins) {
+		return nil, fmt.Errorf("invalid response: %d rows for %d origins", len(res.Distances), len(origins))
+	}
+
+	rows := make([][]Element, len(origins))
+	for i := range origins {
+		if len(res.Distances[i]) != len(destinations) || len(res.Durations[i]) != len(destinations) {
+			return nil, fmt.Errorf("invalid response: %d elements for %d destinations", len(res.Distances[i]), len(destinations))
+		}
+		rows[i] = make([]Element, len(destinations))
+		for j := range destinations {
+			distance, duration := res.Distances[i][j], res.Durations[i][j]
+			if distance == nil || duration == nil {
+				rows[i][j] = Element{Status: StatusNotFound}
+				continue
+			}
+			rows[i][j] = Element{Status: StatusOK, Distance: *distance, Duration: *duration}
+		}
+	}
+	return rows, nil
+}
+
+// mapboxLocation returns the location as longitude,latitude.
+func mapboxLocation(l Location) string {
+	return strconv.FormatFloat(l.Longitude, 'f', -1, 64) + "," + strconv.FormatFloat(l.Latitude, 'f', -1, 64)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const nominatimBaseURL = "https://nominatim.openstreetmap.org"
+
+// nominatim is the provider of the OpenStreetMap Nominatim API, public or self-hosted.
+// Nominatim doesn't compute routes, so the distance matrices have the great-circle distances.
+type nominatim struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+type nominatimPlace struct {
+	PlaceID     json.Number `json:"place_id"`
+	DisplayName string      `json:"display_name"`
+	Lat         string      `json:"lat"`
+	Lon         string      `json:"lon"`
+	// Error is set by reverse when there is no place at the location.
+	Error string `json:"error"`
+}
+
+func newNominatim(m geocodingMetadata, client *http.Client) *nominatim {
+	baseURL := nominatimBaseURL
+	if m.BaseURL != "" {
+		baseURL = strings.TrimSuffix(m.BaseURL, "/")
+	}
+	return &nominatim{baseURL: baseURL, userAgent: m.UserAgent, client: client}
+}
+
+func (n *nominatim) geocode(ctx context.Context, address string, language string, limit int) ([]Place, error) {
+	query := url.Values{"q": []string{address}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var res []nominatimPlace
+	if err := n.get(ctx, "/search", query, language, &res); err != nil {
+		return nil, err
+	}
+	places := make([]Place, 0, len(res))
+	for _, r := range res {
+		if p, ok := r.place(); ok {
+			places = append(places, p)
+		}
+	}
+	return places, nil
+}
+
+// reverseGeocode returns the place at the location; Nominatim has a single result.
+func (n *nominatim) reverseGeocode(ctx context.Context, location Location, language string, _ int) ([]Place, error) {
+	query := url.Values{
+		"lat": []string{strconv.FormatFloat(location.Latitude, 'f', -1, 64)},
+		"lon": []string{strconv.FormatFloat(location.Longitude, 'f', -1, 64)},
+	}
+
+	var res nominatimPlace
+	if err := n.get(ctx, "/reverse", query, language, &res); err != nil {
+		return nil, err
+	}
+	if p, ok := res.place(); ok {
+		return []Place{p}, nil
+	}
+	return nil, nil
+}
+
+func (n *nominatim) distanceMatrix(_ context.Context, origins []Location, destinations []Location, _ string) ([][]Element, error) {
+	rows := make([][]Element, len(origins))
+	for i, o := range origins {
+		rows[i] = make([]Element, len(destinations))
+		for j, d := range destinations {
+			rows[i][j] = Element{Status: StatusOK, Distance: haversine(o, d)}
+		}
+	}
+	return rows, nil
+}
+
+func (n *nominatim) get(ctx context.Context, path string, query url.Values, language string, out interface{}) error {
+	query.Set("format", "jsonv2")
+	header := http.Header{"User-Agent": []string{n.userAgent}}
+	if language != "" {
+		header.Set("Accept-Language", language)
+	}
+	return getJSON(ctx, n.client, n.baseURL+path+"?"+query.Encode(), header, out)
+}
+
+func (p nominatimPlace) place() (Place, bool) {
+	if p.Error != "" {
+		return Place{}, false
+	}
+	lat, err := strconv.ParseFloat(p.Lat, 64)
+	if err != nil {
+		return Place{}, false
+	}
+	lng, err := strconv.ParseFloat(p.Lon, 64)
+	if err != nil {
+		return Place{}, false
+	}
+	return Place{ID: p.PlaceID.String(), Address: p.DisplayName, Location: Location{Latitude: lat, Longitude: lng}}, true
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultVaultAddress = "https://127.0.0.1:8200"
+	defaultEnginePath   = "database"
+	defaultTimeout      = 30 * time.Second
+
+	vaultHTTPHeader        = "X-Vault-Token"
+	vaultHTTPRequestHeader = "X-Vault-Request"
+
+	// keys from request's metadata.
+	roleKey      = "role"
+	leaseIDKey   = "leaseId"
+	incrementKey = "increment"
+
+	// keys from response's metadata.
+	respLeaseIDKey       = "leaseId"
+	respLeaseDurationKey = "leaseDuration"
+	respRenewableKey     = "renewable"
+
+	IssueOperation  bindings.OperationKind = "issue"
+	RenewOperation  bindings.OperationKind = "renew"
+	RevokeOperation bindings.OperationKind = "revoke"
+)
+
+// Vault is an output binding issuing dynamic credentials with the Vault database secrets engine.
+type Vault struct {
+	metadata vaultMetadata
+	client   *http.Client
+	token    string
+	logger   logger.Logger
+}
+
+type vaultMetadata struct {
+	VaultAddr           string        `mapstructure:"vaultAddr"`
+	VaultToken          string        `mapstructure:"vaultToken"`
+	VaultTokenMountPath string        `mapstructure:"vaultTokenMountPath"`
+	EnginePath          string        `mapstructure:"enginePath"`
+	Role                string        `mapstructure:"role"`
+	CaPem               string        `mapstructure:"caPem"`
+	CaCert              string        `mapstructure:"caCert"`
+	SkipVerify          bool          `mapstructure:"skipVerify"`
+	TLSServerName       string        `mapstructure:"tlsServerName"`
+	Timeout             time.Duration `mapstructure:"timeout"`
+}
+
+// Credentials is the response of the issue operation.
+type Credentials struct {
+	Lease
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Lease is the response of the issue and renew operations.
+type Lease struct {
+	LeaseID       string `json:"leaseId"`
+	LeaseDuration int    `json:"leaseDuration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// vaultSecretResponse is the response returned by Vault when reading credentials or renewing a lease.
+type vaultSecretResponse struct {
+	LeaseID       string            `json:"lease_id"`
+	LeaseDuration int               `json:"lease_duration"`
+	Renewable     bool              `json:"renewable"`
+	Data          map[string]string `json:"data"`
+}
+
+// vaultErrorResponse is the response returned by Vault on errors.
+type vaultErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// renewRequest is the body of the renew and revoke operations.
+type renewRequest struct {
+	LeaseID   string `json:"lease_id"`
+	Increment int    `json:"increment,omitempty"`
+}
+
+// NewVault returns a new Vault database credentials binding.
+func NewVault(logger logger.Logger) bindings.OutputBinding {
+	return &Vault{logger: logger}
+}
+
+// Init performs metadata parsing and creates the HTTP client.
+func (v *Vault) Init(meta bindings.Metadata) error {
+	m := vaultMetadata{
+		VaultAddr:  defaultVaultAddress,
+		EnginePath: defaultEnginePath,
+		Timeout:    defaultTimeout,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &m)
+	if err != nil {
+		return fmt.Errorf("vault binding error: %w", err)
+	}
+	m.VaultAddr = strings.TrimSuffix(m.VaultAddr, "/")
+	m.EnginePath = strings.Trim(m.EnginePath, "/")
+	v.metadata = m
+
+	v.token, err = readToken(m.VaultToken, m.VaultTokenMountPath)
+	if err != nil {
+		return fmt.Errorf("vault binding error: %w", err)
+	}
+
+	v.client, err = newHTTPClient(m)
+	if err != nil {
+		return fmt.Errorf("vault binding error: %w", err)
+	}
+
+	return nil
+}
+
+// Operations returns list of operations supported by the Vault binding.
+func (v *Vault) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		IssueOperation,
+		RenewOperation,
+		RevokeOperation,
+	}
+}
+
+// Invoke issues, renews or revokes credentials.
+func (v *Vault) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	switch req.Operation { //nolint:exhaustive
+	case IssueOperation:
+		return v.issue(ctx, req)
+	case RenewOperation:
+		return v.renew(ctx, req)
+	case RevokeOperation:
+		return v.revoke(ctx, req)
+	default:
+		return nil, fmt.Errorf("vault binding error: unsupported operation %s", req.Operation)
+	}
+}
+
+func (v *Vault) issue(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	role := v.metadata.Role
+	if r := req.Metadata[roleKey]; r != "" {
+		role = r
+	}
+	if role == "" {
+		return nil, fmt.Errorf("vault binding error: required metadata %q not set", roleKey)
+	}
+
+	var res vaultSecretResponse
+	err := v.do(ctx, http.MethodGet, v.metadata.EnginePath+"/creds/"+url.PathEscape(role), nil, &res)
+	if err != nil {
+		return nil, fmt.Errorf("vault binding error: failed to issue credentials for role %s: %w", role, err)
+	}
+
+	creds := Credentials{
+		Lease:    leaseFromResponse(res),
+		Username: res.Data["username"],
+		Password: res.Data["password"],
+	}
+	return leaseResponse(creds, creds.Lease)
+}
+
+func (v *Vault) renew(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	body, err := leaseRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault binding error: %w", err)
+	}
+
+	var res vaultSecretResponse
+	err = v.do(ctx, http.MethodPut, "sys/leases/renew", body, &res)
+	if err != nil {
+		return nil, fmt.Errorf("vault binding error: failed to renew lease %s: %w", body.LeaseID, err)
+	}
+
+	lease := leaseFromResponse(res)
+	return leaseResponse(lease, lease)
+}
+
+func (v *Vault) revoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	body, err := leaseRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault binding error: %w", err)
+	}
+	body.Increment = 0
+
+	err = v.do(ctx, http.MethodPut, "sys/leases/revoke", body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault binding error: failed to revoke lease %s: %w", body.LeaseID, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			respLeaseIDKey: body.LeaseID,
+		},
+	}, nil
+}
+
+// leaseRequest returns the lease ID and increment, read from the request's metadata or from its JSON data.
+func leaseRequest(req *bindings.InvokeRequest) (renewRequest, error) {
+	var body struct {
+		LeaseID   string `json:"leaseId"`
+		Increment int    `json:"increment"`
+	}
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &body); err != nil {
+			return renewRequest{}, fmt.Errorf("failed to parse request data: %w", err)
+		}
+	}
+	if id := req.Metadata[leaseIDKey]; id != "" {
+		body.LeaseID = id
+	}
+	if inc := req.Metadata[incrementKey]; inc != "" {
+		d, err := parseIncrement(inc)
+		if err != nil {
+			return renewRequest{}, err
+		}
+		body.Increment = d
+	}
+	if body.LeaseID == "" {
+		return renewRequest{}, fmt.Errorf("required %q not set", leaseIDKey)
+	}
+	return renewRequest{LeaseID: body.LeaseID, Increment: body.Increment}, nil
+}
+
+// parseIncrement parses an increment expressed either in seconds or as a Go duration.
+func parseIncrement(val string) (int, error) {
+	if s, err := strconv.Atoi(val); err == nil {
+		return s, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %q: %s", incrementKey, val)
+	}
+	return int(d.Seconds()), nil
+}
+
+func leaseFromResponse(res vaultSecretResponse) Lease {
+	return Lease{
+		LeaseID:       res.LeaseID,
+		LeaseDuration: res.LeaseDuration,
+		Renewable:     res.Renewable,
+	}
+}
+
+func leaseResponse(data interface{}, lease Lease) (*bindings.InvokeResponse, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("vault binding error: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: b,
+		Metadata: map[string]string{
+			respLeaseIDKey:       lease.LeaseID,
+			respLeaseDurationKey: strconv.Itoa(lease.LeaseDuration),
+			respRenewableKey:     strconv.FormatBool(lease.Renewable),
+		},
+	}, nil
+}
+
+// do sends a request to the Vault API and decodes the response into res, if not nil.
+func (v *Vault) do(ctx context.Context, method, path string, body interface{}, res interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, v.metadata.VaultAddr+"/v1/"+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("couldn't generate request: %w", err)
+	}
+	httpReq.Header.Set(vaultHTTPHeader, v.token)
+	httpReq.Header.Set(vaultHTTPRequestHeader, "true")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	httpRes, err := v.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode < 200 || httpRes.StatusCode > 299 {
+		b, _ := io.ReadAll(httpRes.Body)
+		var errRes vaultErrorResponse
+		if json.Unmarshal(b, &errRes) == nil && len(errRes.Errors) > 0 {
+			return fmt.Errorf("status code %d: %s", httpRes.StatusCode, strings.Join(errRes.Errors, "; "))
+		}
+		return fmt.Errorf("status code %d: %s", httpRes.StatusCode, string(b))
+	}
+
+	if res == nil {
+		return nil
+	}
+	if err = json.NewDecoder(httpRes.Body).Decode(res); err != nil {
+		return fmt.Errorf("couldn't decode response body: %w", err)
+	}
+	return nil
+}
+
+// readToken returns the token, reading it from the mount path if needed.
+func readToken(token, mountPath string) (string, error) {
+	if token == "" && mountPath == "" {
+		return "", errors.New("token mount path and token not set")
+	}
+	if token != "" && mountPath != "" {
+		return "", errors.New("token mount path and token both set")
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	data, err := os.ReadFile(mountPath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read vault token from mount path %s: %w", mountPath, err)
+	}
+	return string(bytes.TrimSpace(data)), nil
+}
+
+func newHTTPClient(m vaultMetadata) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: m.SkipVerify, //nolint:gosec
+		ServerName:         m.TLSServerName,
+	}
+
+	if !m.SkipVerify && (m.CaPem != "" || m.CaCert != "") {
+		pem := []byte(m.CaPem)
+		if len(pem) == 0 {
+			var err error
+			pem, err = os.ReadFile(m.CaCert)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't read CA file from disk: %w", err)
+			}
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("couldn't read PEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	return &http.Client{
+		Timeout: m.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			TLSClientConfig:     tlsConfig,
+		},
+	}, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func newTestVault(t *testing.T, handler http.HandlerFunc) *Vault {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	v := NewVault(logger.NewLogger("test")).(*Vault)
+	err := v.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"vaultAddr":  srv.URL,
+		"vaultToken": "token",
+		"role":       "readonly",
+	}}})
+	require.NoError(t, err)
+	return v
+}
+
+func TestInit(t *testing.T) {
+	v := NewVault(logger.NewLogger("test"))
+
+	t.Run("missing token", func(t *testing.T) {
+		err := v.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{}}})
+		require.Error(t, err)
+	})
+
+	t.Run("token and mount path", func(t *testing.T) {
+		err := v.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"vaultToken":          "token",
+			"vaultTokenMountPath": "/tmp/token",
+		}}})
+		require.Error(t, err)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		err := v.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"vaultToken": "token",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, defaultVaultAddress, v.(*Vault).metadata.VaultAddr)
+		assert.Equal(t, defaultEnginePath, v.(*Vault).metadata.EnginePath)
+	})
+}
+
+func TestIssue(t *testing.T) {
+	v := newTestVault(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "token", r.Header.Get(vaultHTTPHeader))
+		switch r.URL.Path {
+		case "/v1/database/creds/readonly":
+			w.Write([]byte(`{"lease_id":"database/creds/readonly/abc","lease_duration":3600,"renewable":true,"data":{"username":"v-user","password":"secret"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["unknown role"]}`))
+		}
+	})
+
+	t.Run("default role", func(t *testing.T) {
+		res, err := v.Invoke(context.Background(), &bindings.InvokeRequest{Operation: IssueOperation})
+		require.NoError(t, err)
+
+		var creds Credentials
+		require.NoError(t, json.Unmarshal(res.Data, &creds))
+		assert.Equal(t, Credentials{
+			Lease:    Lease{LeaseID: "database/creds/readonly/abc", LeaseDuration: 3600, Renewable: true},
+			Username: "v-user",
+			Password: "secret",
+		}, creds)
+		assert.Equal(t, "database/creds/readonly/abc", res.Metadata[respLeaseIDKey])
+		assert.Equal(t, "3600", res.Metadata[respLeaseDurationKey])
+		assert.Equal(t, "true", res.Metadata[respRenewableKey])
+	})
+
+	t.Run("vault error", func(t *testing.T) {
+		_, err := v.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: IssueOperation,
+			Metadata:  map[string]string{roleKey: "other"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown role")
+	})
+}
+
+func TestRenewRevoke(t *testing.T) {
+	var (
+		path string
+		body renewRequest
+	)
+	v := newTestVault(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.Path
+		body = renewRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if path == "/v1/sys/leases/renew" {
+			w.Write([]byte(`{"lease_id":"` + body.LeaseID + `","lease_duration":7200,"renewable":true}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		res, err := v.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: RenewOperation,
+			Metadata:  map[string]string{leaseIDKey: "lease1", incrementKey: "2h"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/sys/leases/renew", path)
+		assert.Equal(t, renewRequest{LeaseID: "lease1", Increment: 7200}, body)
+		assert.JSONEq(t, `{"leaseId":"lease1","leaseDuration":7200,"renewable":true}`, string(res.Data))
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		res, err := v.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: RevokeOperation,
+			Data:      []byte(`{"leaseId":"lease2"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/sys/leases/revoke", path)
+		assert.Equal(t, renewRequest{LeaseID: "lease2"}, body)
+		assert.Equal(t, "lease2", res.Metadata[respLeaseIDKey])
+	})
+
+	t.Run("missing lease", func(t *testing.T) {
+		_, err := v.Invoke(context.Background(), &bindings.InvokeRequest{Operation: RevokeOperation})
+		require.Error(t, err)
+	})
+}
+
+func TestParseIncrement(t *testing.T) {
+	d, err := parseIncrement("60")
+	require.NoError(t, err)
+	assert.Equal(t, 60, d)
+
+	d, err = parseIncrement("1m30s")
+	require.NoError(t, err)
+	assert.Equal(t, 90, d)
+
+	_, err = parseIncrement("abc")
+	require.Error(t, err)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/baggage"
 	"github.com/dapr/components-contrib/internal/utils"
 	"github.com/dapr/kit/logger"
 )
@@ -44,6 +45,14 @@ const (
 	MTLSClientKey  = "MTLSClientKey"
 )
 
+// requestIDHeaders are the response headers checked, in order, for the ID of the request.
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"X-Correlation-Id",
+	"X-Amzn-Requestid",
+	"X-Ms-Request-Id",
+}
+
 // HTTPSource is a binding for an http url endpoint invocation
 //
 //revive:disable-next-line
@@ -182,6 +191,33 @@ func (h *HTTPSource) Operations() []bindings.OperationKind {
 	}
 }
 
+// OperationsMetadata returns the description of the operations of the binding.
+func (h *HTTPSource) OperationsMetadata() []bindings.OperationMetadata {
+	ops := h.Operations()
+	res := make([]bindings.OperationMetadata, len(ops))
+	for i, op := range ops {
+		method := strings.ToUpper(string(op))
+		if op == bindings.CreateOperation {
+			method = http.MethodPost
+		}
+		res[i] = bindings.OperationMetadata{
+			Operation:   op,
+			Description: "Send a " + method + " request; request metadata starting with an uppercase letter are sent as headers, and the response headers are returned as metadata",
+			RequestMetadata: []string{
+				"path",
+				"errorIfNot2XX",
+			},
+			ResponseMetadata: []string{
+				bindings.ResponseMetadataStatusCode,
+				"status",
+				bindings.ResponseMetadataOperation,
+				bindings.ResponseMetadataRequestID,
+			},
+		}
+	}
+	return res
+}
+
 // Invoke performs an HTTP request to the configured HTTP endpoint.
 func (h *HTTPSource) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	u := h.metadata.URL
@@ -243,6 +279,12 @@ func (h *HTTPSource) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*
 		}
 	}
 
+	// Propagate the baggage of the request, such as the one set by the baggage middleware
+	if len(baggage.FromContext(ctx)) > 0 {
+		md := baggage.Metadata(ctx, map[string]string{baggage.Header: request.Header.Get(baggage.Header)})
+		request.Header.Set(baggage.Header, md[baggage.Header])
+	}
+
 	// Send the question
 	resp, err := h.client.Do(request)
 	if err != nil {
@@ -257,10 +299,11 @@ func (h *HTTPSource) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*
 		return nil, err
 	}
 
-	metadata := make(map[string]string, len(resp.Header)+2)
+	metadata := make(map[string]string, len(resp.Header)+4)
 	// Include status code & desc
-	metadata["statusCode"] = strconv.Itoa(resp.StatusCode)
+	metadata[bindings.ResponseMetadataStatusCode] = strconv.Itoa(resp.StatusCode)
 	metadata["status"] = resp.Status
+	metadata[bindings.ResponseMetadataOperation] = string(req.Operation)
 
 	// Response headers are mapped from `map[string][]string` to `map[string]string`
 	// where headers with multiple values are delimited with ", ".
@@ -268,6 +311,14 @@ func (h *HTTPSource) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*
 		metadata[key] = strings.Join(values, ", ")
 	}
 
+	// Expose the request ID under the well-known key, from the first header commonly used for it.
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			metadata[bindings.ResponseMetadataRequestID] = id
+			break
+		}
+	}
+
 	// Create an error for non-200 status codes unless suppressed.
 	if errorIfNot2XX && resp.StatusCode/100 != 2 {
 		err = fmt.Errorf("received status code %d", resp.StatusCode)
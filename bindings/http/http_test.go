@@ -51,6 +51,31 @@ func TestOperations(t *testing.T) {
 	}, opers)
 }
 
+func TestOperationsMetadata(t *testing.T) {
+	ops := bindings.GetOperationsMetadata((*bindingHttp.HTTPSource)(nil))
+	require.Len(t, ops, 9)
+	assert.Equal(t, bindings.CreateOperation, ops[0].Operation)
+	assert.Contains(t, ops[0].Description, "POST")
+	assert.Contains(t, ops[0].ResponseMetadata, bindings.ResponseMetadataStatusCode)
+}
+
+func TestResponseMetadata(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post"})
+	require.NoError(t, err)
+	assert.Equal(t, "202", res.Metadata[bindings.ResponseMetadataStatusCode])
+	assert.Equal(t, "post", res.Metadata[bindings.ResponseMetadataOperation])
+	assert.Equal(t, "req-1", res.Metadata[bindings.ResponseMetadataRequestID])
+}
+
 type TestCase struct {
 	input      string
 	operation  string
@@ -0,0 +1,406 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// Methods of the gateway.Gateway service.
+	evaluateMethod     = "/gateway.Gateway/Evaluate"
+	endorseMethod      = "/gateway.Gateway/Endorse"
+	submitMethod       = "/gateway.Gateway/Submit"
+	commitStatusMethod = "/gateway.Gateway/CommitStatus"
+
+	// keys from request's metadata.
+	functionKey               = "function"
+	channelKey                = "channel"
+	chaincodeKey              = "chaincode"
+	contractKey               = "contract"
+	endorsingOrganizationsKey = "endorsingOrganizations"
+
+	// keys from response's metadata.
+	respTransactionIDKey = "transactionID"
+	respBlockNumberKey   = "blockNumber"
+	respStatusKey        = "status"
+
+	EvaluateOperation bindings.OperationKind = "evaluate"
+	SubmitOperation   bindings.OperationKind = "submit"
+)
+
+// Fabric is an output binding evaluating and submitting transactions through a Hyperledger Fabric gateway peer.
+type Fabric struct {
+	metadata fabricMetadata
+	identity []byte
+	key      *ecdsa.PrivateKey
+	conn     *grpc.ClientConn
+	logger   logger.Logger
+}
+
+type fabricMetadata struct {
+	// Address of the gateway peer, as host:port.
+	PeerEndpoint string `mapstructure:"peerEndpoint"`
+	// PEM-encoded CA certificate of the peer's TLS certificate; the system roots are used when it's not set.
+	TLSCACert string `mapstructure:"tlsCACert"`
+	// Overrides the server name verified in the peer's TLS certificate.
+	ServerNameOverride string `mapstructure:"serverNameOverride"`
+	// Insecure connects without TLS, for development networks.
+	Insecure bool `mapstructure:"insecure"`
+
+	// MSP identity: the PEM-encoded certificate and private key are usually references to a secret store.
+	MSPID       string `mapstructure:"mspID"`
+	Certificate string `mapstructure:"certificate"`
+	PrivateKey  string `mapstructure:"privateKey"`
+
+	// Default channel, chaincode and contract of the transactions.
+	Channel   string        `mapstructure:"channel"`
+	Chaincode string        `mapstructure:"chaincode"`
+	Contract  string        `mapstructure:"contract"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// TransactionRequest is the data of requests: the arguments of the function, and its transient data for private data collections.
+type TransactionRequest struct {
+	Args      []string          `json:"args"`
+	Transient map[string]string `json:"transient,omitempty"`
+}
+
+// rawCodec passes the messages encoded by this package through to gRPC.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// NewFabric returns a new Fabric binding.
+func NewFabric(logger logger.Logger) bindings.OutputBinding {
+	return &Fabric{logger: logger}
+}
+
+// Init parses the identity and connects to the gateway peer.
+func (f *Fabric) Init(meta bindings.Metadata) error {
+	f.metadata = fabricMetadata{Timeout: defaultTimeout}
+	err := metadata.DecodeMetadata(meta.Properties, &f.metadata)
+	if err != nil {
+		return fmt.Errorf("fabric binding error: %w", err)
+	}
+	if f.metadata.PeerEndpoint == "" || f.metadata.MSPID == "" {
+		return errors.New("fabric binding error: peerEndpoint and mspID are required")
+	}
+
+	f.key, err = parseIdentity(f.metadata.Certificate, f.metadata.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("fabric binding error: %w", err)
+	}
+	f.identity = serializedIdentity(f.metadata.MSPID, []byte(f.metadata.Certificate))
+
+	creds := insecure.NewCredentials()
+	if !f.metadata.Insecure {
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: f.metadata.ServerNameOverride,
+		}
+		if f.metadata.TLSCACert != "" {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(f.metadata.TLSCACert)) {
+				return errors.New("fabric binding error: invalid tlsCACert")
+			}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	// The connection is established in the background, and reestablished by gRPC when it's lost.
+	f.conn, err = grpc.Dial(f.metadata.PeerEndpoint,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		return fmt.Errorf("fabric binding error: failed to connect to %s: %w", f.metadata.PeerEndpoint, err)
+	}
+
+	return nil
+}
+
+// parseIdentity checks that the private key matches the certificate.
+func parseIdentity(certPEM, keyPEM string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("certificate is not PEM-encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	block, _ = pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("privateKey is not PEM-encoded")
+	}
+	var key any
+	key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid privateKey: %w", err)
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("privateKey must be an ECDSA key")
+	}
+	if pub, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok || !pub.Equal(&ecKey.PublicKey) {
+		return nil, errors.New("privateKey doesn't match the key of the certificate")
+	}
+
+	return ecKey, nil
+}
+
+// sign returns the DER-encoded ECDSA signature of the SHA-256 hash of msg, with the low S value Fabric requires.
+func (f *Fabric) sign(msg []byte) ([]byte, error) {
+	hash := sha256.Sum256(msg)
+	r, s, err := ecdsa.Sign(rand.Reader, f.key, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	n := f.key.Curve.Params().N
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+// Operations returns the operations supported by the Fabric binding.
+func (f *Fabric) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{EvaluateOperation, SubmitOperation}
+}
+
+// Invoke evaluates or submits a transaction calling the chaincode function named by the "function" metadata.
+func (f *Fabric) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != EvaluateOperation && req.Operation != SubmitOperation {
+		return nil, fmt.Errorf("fabric binding error: unsupported operation %s", req.Operation)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.metadata.Timeout)
+	defer cancel()
+	txID, channel, signedProposal, err := f.newProposal(req)
+	if err != nil {
+		return nil, fmt.Errorf("fabric binding error: %w", err)
+	}
+	var orgs []string
+	if v := req.Metadata[endorsingOrganizationsKey]; v != "" {
+		orgs = strings.Split(v, ",")
+	}
+	proposalReq := proposalRequest(txID, channel, signedProposal, orgs)
+
+	res := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			respTransactionIDKey:               txID,
+		},
+	}
+	var chaincodeRes *chaincodeResponse
+	if req.Operation == EvaluateOperation {
+		var evaluateRes []byte
+		if err = f.conn.Invoke(ctx, evaluateMethod, &proposalReq, &evaluateRes); err == nil {
+			chaincodeRes, err = evaluateResult(evaluateRes)
+		}
+	} else {
+		var blockNumber uint64
+		chaincodeRes, blockNumber, err = f.submit(ctx, txID, channel, proposalReq)
+		res.Metadata[respBlockNumberKey] = strconv.FormatUint(blockNumber, 10)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fabric binding error: %s of transaction %s failed: %w", req.Operation, txID, err)
+	}
+
+	res.Data = chaincodeRes.Payload
+	res.Metadata[respStatusKey] = strconv.Itoa(int(chaincodeRes.Status))
+	return res, nil
+}
+
+// newProposal returns the ID of a new transaction, its channel, and its signed proposal.
+func (f *Fabric) newProposal(req *bindings.InvokeRequest) (string, string, []byte, error) {
+	function := req.Metadata[functionKey]
+	if function == "" {
+		return "", "", nil, fmt.Errorf("the %s metadata is required", functionKey)
+	}
+	p := proposalParams{
+		Channel:   metadataOrDefault(req.Metadata, channelKey, f.metadata.Channel),
+		Chaincode: metadataOrDefault(req.Metadata, chaincodeKey, f.metadata.Chaincode),
+		Creator:   f.identity,
+		Nonce:     make([]byte, 24),
+		Timestamp: time.Now(),
+	}
+	if p.Channel == "" || p.Chaincode == "" {
+		return "", "", nil, errors.New("the channel and the chaincode are required")
+	}
+	if contract := metadataOrDefault(req.Metadata, contractKey, f.metadata.Contract); contract != "" {
+		function = contract + ":" + function
+	}
+
+	var data TransactionRequest
+	if len(req.Data) > 0 {
+		if err := json.Unmarshal(req.Data, &data); err != nil {
+			return "", "", nil, fmt.Errorf("invalid request data: %w", err)
+		}
+	}
+	p.Args = append(p.Args, []byte(function))
+	for _, arg := range data.Args {
+		p.Args = append(p.Args, []byte(arg))
+	}
+	if len(data.Transient) > 0 {
+		p.Transient = make(map[string][]byte, len(data.Transient))
+		for k, v := range data.Transient {
+			p.Transient[k] = []byte(v)
+		}
+	}
+
+	if _, err := rand.Read(p.Nonce); err != nil {
+		return "", "", nil, err
+	}
+	// The ID of transactions is the hash of their nonce and creator.
+	txHash := sha256.Sum256(append(append([]byte{}, p.Nonce...), p.Creator...))
+	p.TxID = hex.EncodeToString(txHash[:])
+
+	prop := proposal(p)
+	signature, err := f.sign(prop)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to sign the proposal: %w", err)
+	}
+
+	return p.TxID, p.Channel, signedMessage(prop, signature), nil
+}
+
+// submit endorses a transaction, signs and submits the endorsed transaction to the orderers, and waits for its commit.
+func (f *Fabric) submit(ctx context.Context, txID, channel string, endorseReq []byte) (*chaincodeResponse, uint64, error) {
+	var endorseRes []byte
+	if err := f.conn.Invoke(ctx, endorseMethod, &endorseReq, &endorseRes); err != nil {
+		return nil, 0, fmt.Errorf("endorsement failed: %w", err)
+	}
+	envelope, chaincodeRes, err := preparedTransaction(endorseRes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	payload, err := field(envelope, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	signature, err := f.sign(payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to sign the transaction: %w", err)
+	}
+	submitReq := submitRequest(txID, channel, signedMessage(payload, signature))
+	var submitRes []byte
+	if err = f.conn.Invoke(ctx, submitMethod, &submitReq, &submitRes); err != nil {
+		return nil, 0, fmt.Errorf("submit failed: %w", err)
+	}
+
+	statusReq := commitStatusRequest(txID, channel, f.identity)
+	signature, err = f.sign(statusReq)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to sign the commit status request: %w", err)
+	}
+	signedStatusReq := signedMessage(statusReq, signature)
+	var statusRes []byte
+	if err = f.conn.Invoke(ctx, commitStatusMethod, &signedStatusReq, &statusRes); err != nil {
+		return nil, 0, fmt.Errorf("failed to read the commit status: %w", err)
+	}
+	code, blockNumber, err := commitStatus(statusRes)
+	if err != nil {
+		return nil, 0, err
+	}
+	if code != txValidationCodeValid {
+		return nil, blockNumber, fmt.Errorf("transaction invalidated with validation code %d in block %d", code, blockNumber)
+	}
+
+	return chaincodeRes, blockNumber, nil
+}
+
+func metadataOrDefault(md map[string]string, key, defaultValue string) string {
+	if v := md[key]; v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// Close closes the connection to the gateway peer.
+func (f *Fabric) Close() error {
+	if f.conn == nil {
+		return nil
+	}
+	return f.conn.Close()
+}
+
+// OperationsMetadata describes the operations of the Fabric binding.
+func (f *Fabric) OperationsMetadata() []bindings.OperationMetadata {
+	requestMetadata := []string{functionKey, channelKey, chaincodeKey, contractKey, endorsingOrganizationsKey}
+	return []bindings.OperationMetadata{
+		{
+			Operation: EvaluateOperation,
+			Description: `Queries the ledger with the chaincode function named by the function metadata, without ordering a transaction. ` +
+				`The data holds the "args" of the function, and its "transient" data. Returns the payload of the chaincode response.`,
+			RequestMetadata:  requestMetadata,
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respTransactionIDKey, respStatusKey},
+		},
+		{
+			Operation:        SubmitOperation,
+			Description:      "Endorses the transaction like evaluate, then submits it to the orderers and waits for it to be committed. Returns the payload of the chaincode response.",
+			RequestMetadata:  requestMetadata,
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respTransactionIDKey, respStatusKey, respBlockNumberKey},
+		},
+	}
+}
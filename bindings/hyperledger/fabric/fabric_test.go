@@ -0,0 +1,285 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// fakeGateway implements the gateway.Gateway service, verifying the signatures of the requests with the client's key.
+type fakeGateway struct {
+	t          *testing.T
+	key        *ecdsa.PublicKey
+	lock       sync.Mutex
+	requests   map[string][]byte
+	commitCode uint64
+}
+
+func (g *fakeGateway) handle(_ any, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	var req []byte
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	g.lock.Lock()
+	g.requests[method] = req
+	g.lock.Unlock()
+
+	var res []byte
+	switch method {
+	case evaluateMethod, endorseMethod:
+		signedProposal := g.fields(req).first(3)
+		if !g.verify(signedProposal) {
+			return status.Error(codes.PermissionDenied, "invalid proposal signature")
+		}
+		chaincodeRes := appendVarintField(nil, 1, chaincodeStatusOK)
+		chaincodeRes = appendBytesField(chaincodeRes, 3, []byte(`{"owner":"alice"}`))
+		if method == evaluateMethod {
+			res = appendBytesField(nil, 1, chaincodeRes)
+		} else {
+			// Wrap the response in a prepared transaction.
+			msg := chaincodeRes
+			for _, num := range []protowire.Number{3, 2, 1, 2, 2, 1, 2} {
+				msg = appendBytesField(nil, num, msg)
+			}
+			res = appendBytesField(nil, 1, signedMessage(msg, nil))
+		}
+	case submitMethod:
+		if !g.verify(g.fields(req).first(3)) {
+			return status.Error(codes.PermissionDenied, "invalid transaction signature")
+		}
+	case commitStatusMethod:
+		if !g.verify(req) {
+			return status.Error(codes.PermissionDenied, "invalid commit status signature")
+		}
+		res = appendVarintField(nil, 1, g.commitCode)
+		res = appendVarintField(res, 2, 9)
+	default:
+		return status.Error(codes.Unimplemented, method)
+	}
+
+	return stream.SendMsg(&res)
+}
+
+func (g *fakeGateway) fields(msg []byte) *protoFields {
+	f, err := parseFields(msg)
+	require.NoError(g.t, err)
+	return f
+}
+
+// verify verifies a message of the form {1: message bytes, 2: signature}.
+func (g *fakeGateway) verify(signed []byte) bool {
+	f := g.fields(signed)
+	hash := sha256.Sum256(f.first(1))
+	return ecdsa.VerifyASN1(g.key, hash[:], f.first(2))
+}
+
+func (g *fakeGateway) request(method string) []byte {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.requests[method]
+}
+
+func newIdentity(t *testing.T) (*ecdsa.PrivateKey, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return key,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func startGateway(t *testing.T) (*fakeGateway, *Fabric) {
+	t.Helper()
+
+	key, cert, keyPEM := newIdentity(t)
+	g := &fakeGateway{t: t, key: &key.PublicKey, requests: map[string][]byte{}}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(g.handle), grpc.ForceServerCodec(rawCodec{}))
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	f := NewFabric(logger.NewLogger("test")).(*Fabric)
+	err = f.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"peerEndpoint": l.Addr().String(),
+		"insecure":     "true",
+		"mspID":        "Org1MSP",
+		"certificate":  cert,
+		"privateKey":   keyPEM,
+		"channel":      "mychannel",
+		"chaincode":    "basic",
+	}}})
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+
+	return g, f
+}
+
+func TestInit(t *testing.T) {
+	_, cert, keyPEM := newIdentity(t)
+	_, _, otherKey := newIdentity(t)
+
+	tests := map[string]map[string]string{
+		"no endpoint":     {"mspID": "Org1MSP", "certificate": cert, "privateKey": keyPEM},
+		"no mspID":        {"peerEndpoint": "localhost:7051", "certificate": cert, "privateKey": keyPEM},
+		"no certificate":  {"peerEndpoint": "localhost:7051", "mspID": "Org1MSP", "privateKey": keyPEM},
+		"mismatched key":  {"peerEndpoint": "localhost:7051", "mspID": "Org1MSP", "certificate": cert, "privateKey": otherKey},
+		"invalid TLS CAs": {"peerEndpoint": "localhost:7051", "mspID": "Org1MSP", "certificate": cert, "privateKey": keyPEM, "tlsCACert": "x"},
+	}
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := NewFabric(logger.NewLogger("test"))
+			assert.Error(t, f.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	g, f := startGateway(t)
+
+	res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: EvaluateOperation,
+		Data:      []byte(`{"args": ["asset1"], "transient": {"price": "10"}}`),
+		Metadata: map[string]string{
+			functionKey:               "ReadAsset",
+			contractKey:               "assets",
+			endorsingOrganizationsKey: "Org1MSP,Org2MSP",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"owner":"alice"}`, string(res.Data))
+	assert.Equal(t, "200", res.Metadata[respStatusKey])
+	txID := res.Metadata[respTransactionIDKey]
+
+	req := g.fields(g.request(evaluateMethod))
+	assert.Equal(t, txID, string(req.first(1)))
+	assert.Equal(t, "mychannel", string(req.first(2)))
+	assert.Equal(t, [][]byte{[]byte("Org1MSP"), []byte("Org2MSP")}, req.bytes[4])
+
+	prop, err := field(req.first(3), 1)
+	require.NoError(t, err)
+	channelHeader, err := field(prop, 1, 1)
+	require.NoError(t, err)
+	ch := g.fields(channelHeader)
+	assert.Equal(t, uint64(endorserTransactionType), ch.varints[1])
+	assert.Equal(t, "mychannel", string(ch.first(4)))
+	assert.Equal(t, txID, string(ch.first(5)))
+	chaincode, err := field(ch.first(7), 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "basic", string(chaincode))
+
+	// The transaction ID is the hash of the nonce and the creator.
+	signatureHeader, err := field(prop, 1, 2)
+	require.NoError(t, err)
+	sh := g.fields(signatureHeader)
+	creator := g.fields(sh.first(1))
+	assert.Equal(t, "Org1MSP", string(creator.first(1)))
+	txHash := sha256.Sum256(append(sh.first(2), sh.first(1)...))
+	assert.Equal(t, hex.EncodeToString(txHash[:]), txID)
+
+	input, err := field(prop, 2, 1, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("assets:ReadAsset"), []byte("asset1")}, g.fields(input).bytes[1])
+	transient := g.fields(g.fields(mustField(t, prop, 2)).first(2))
+	assert.Equal(t, "price", string(transient.first(1)))
+	assert.Equal(t, "10", string(transient.first(2)))
+
+	t.Run("missing function", func(t *testing.T) {
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{Operation: EvaluateOperation})
+		assert.Error(t, err)
+	})
+}
+
+func mustField(t *testing.T, msg []byte, path ...protowire.Number) []byte {
+	t.Helper()
+
+	b, err := field(msg, path...)
+	require.NoError(t, err)
+	return b
+}
+
+func TestSubmit(t *testing.T) {
+	g, f := startGateway(t)
+
+	res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: SubmitOperation,
+		Data:      []byte(`{"args": ["asset1", "bob"]}`),
+		Metadata:  map[string]string{functionKey: "TransferAsset"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"owner":"alice"}`, string(res.Data))
+	assert.Equal(t, "9", res.Metadata[respBlockNumberKey])
+	txID := res.Metadata[respTransactionIDKey]
+
+	submit := g.fields(g.request(submitMethod))
+	assert.Equal(t, txID, string(submit.first(1)))
+	statusReq := g.fields(mustField(t, g.request(commitStatusMethod), 1))
+	assert.Equal(t, txID, string(statusReq.first(1)))
+	assert.Equal(t, "mychannel", string(statusReq.first(2)))
+
+	t.Run("invalidated transaction", func(t *testing.T) {
+		g.lock.Lock()
+		g.commitCode = 11
+		g.lock.Unlock()
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: SubmitOperation,
+			Metadata:  map[string]string{functionKey: "TransferAsset"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation code 11")
+	})
+}
+
+func TestChaincodeError(t *testing.T) {
+	_, err := parseChaincodeResponse(appendStringField(appendVarintField(nil, 1, 500), 2, "asset not found"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "asset not found")
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// This file encodes and decodes the messages of the Fabric Gateway API and of the Fabric protos it embeds,
+// with their field numbers from github.com/hyperledger/fabric-protos.
+
+const (
+	// common.HeaderType ENDORSER_TRANSACTION.
+	endorserTransactionType = 3
+	// peer.TxValidationCode VALID.
+	txValidationCodeValid = 0
+	// Status of successful chaincode responses.
+	chaincodeStatusOK = 200
+	// Chaincode responses with a status from this one are errors.
+	chaincodeStatusError = 400
+)
+
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendStringField(b []byte, num protowire.Number, s string) []byte {
+	return appendBytesField(b, num, []byte(s))
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// protoFields holds the bytes and varint fields of a message by field number.
+type protoFields struct {
+	bytes   map[protowire.Number][][]byte
+	varints map[protowire.Number]uint64
+}
+
+func parseFields(msg []byte) (*protoFields, error) {
+	f := &protoFields{
+		bytes:   map[protowire.Number][][]byte{},
+		varints: map[protowire.Number]uint64{},
+	}
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			f.bytes[num] = append(f.bytes[num], v)
+			msg = msg[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			f.varints[num] = v
+			msg = msg[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			msg = msg[n:]
+		}
+	}
+	return f, nil
+}
+
+func (f *protoFields) first(num protowire.Number) []byte {
+	if v := f.bytes[num]; len(v) > 0 {
+		return v[0]
+	}
+	return nil
+}
+
+// field returns the bytes field at the end of a path of embedded messages.
+func field(msg []byte, path ...protowire.Number) ([]byte, error) {
+	for _, num := range path {
+		f, err := parseFields(msg)
+		if err != nil {
+			return nil, err
+		}
+		msg = f.first(num)
+	}
+	return msg, nil
+}
+
+// msp.SerializedIdentity.
+func serializedIdentity(mspID string, certPEM []byte) []byte {
+	b := appendStringField(nil, 1, mspID)
+	return appendBytesField(b, 2, certPEM)
+}
+
+// proposalParams are the parameters of a chaincode proposal.
+type proposalParams struct {
+	Channel   string
+	Chaincode string
+	TxID      string
+	Creator   []byte
+	Nonce     []byte
+	Args      [][]byte
+	Transient map[string][]byte
+	Timestamp time.Time
+}
+
+// proposal returns a peer.Proposal invoking a chaincode.
+func proposal(p proposalParams) []byte {
+	// google.protobuf.Timestamp.
+	ts := appendVarintField(nil, 1, uint64(p.Timestamp.Unix()))
+	ts = appendVarintField(ts, 2, uint64(p.Timestamp.Nanosecond()))
+	// peer.ChaincodeID.
+	chaincodeID := appendStringField(nil, 2, p.Chaincode)
+	// peer.ChaincodeHeaderExtension.
+	extension := appendBytesField(nil, 2, chaincodeID)
+	// common.ChannelHeader.
+	channelHeader := appendVarintField(nil, 1, endorserTransactionType)
+	channelHeader = appendBytesField(channelHeader, 3, ts)
+	channelHeader = appendStringField(channelHeader, 4, p.Channel)
+	channelHeader = appendStringField(channelHeader, 5, p.TxID)
+	channelHeader = appendBytesField(channelHeader, 7, extension)
+	// common.SignatureHeader.
+	signatureHeader := appendBytesField(nil, 1, p.Creator)
+	signatureHeader = appendBytesField(signatureHeader, 2, p.Nonce)
+	// common.Header.
+	header := appendBytesField(nil, 1, channelHeader)
+	header = appendBytesField(header, 2, signatureHeader)
+
+	// peer.ChaincodeInput.
+	var input []byte
+	for _, arg := range p.Args {
+		input = protowire.AppendTag(input, 1, protowire.BytesType)
+		input = protowire.AppendBytes(input, arg)
+	}
+	// peer.ChaincodeSpec.
+	spec := appendBytesField(nil, 2, chaincodeID)
+	spec = appendBytesField(spec, 3, input)
+	// peer.ChaincodeInvocationSpec.
+	invocation := appendBytesField(nil, 1, spec)
+	// peer.ChaincodeProposalPayload, with the transient map sorted for deterministic proposals.
+	payload := appendBytesField(nil, 1, invocation)
+	keys := make([]string, 0, len(p.Transient))
+	for k := range p.Transient {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		entry := appendStringField(nil, 1, k)
+		entry = appendBytesField(entry, 2, p.Transient[k])
+		payload = appendBytesField(payload, 2, entry)
+	}
+
+	// peer.Proposal.
+	prop := appendBytesField(nil, 1, header)
+	return appendBytesField(prop, 2, payload)
+}
+
+// signedMessage returns a message of the form {1: message bytes, 2: signature}: peer.SignedProposal, common.Envelope and gateway.SignedCommitStatusRequest.
+func signedMessage(msg, signature []byte) []byte {
+	b := appendBytesField(nil, 1, msg)
+	return appendBytesField(b, 2, signature)
+}
+
+// proposalRequest returns a gateway.EvaluateRequest or gateway.EndorseRequest.
+func proposalRequest(txID, channel string, signedProposal []byte, orgs []string) []byte {
+	b := appendStringField(nil, 1, txID)
+	b = appendStringField(b, 2, channel)
+	b = appendBytesField(b, 3, signedProposal)
+	for _, org := range orgs {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, org)
+	}
+	return b
+}
+
+// submitRequest returns a gateway.SubmitRequest.
+func submitRequest(txID, channel string, envelope []byte) []byte {
+	b := appendStringField(nil, 1, txID)
+	b = appendStringField(b, 2, channel)
+	return appendBytesField(b, 3, envelope)
+}
+
+// commitStatusRequest returns a gateway.CommitStatusRequest.
+func commitStatusRequest(txID, channel string, identity []byte) []byte {
+	b := appendStringField(nil, 1, txID)
+	b = appendStringField(b, 2, channel)
+	return appendBytesField(b, 3, identity)
+}
+
+// chaincodeResponse is a peer.Response.
+type chaincodeResponse struct {
+	Status  int32
+	Message string
+	Payload []byte
+}
+
+func parseChaincodeResponse(msg []byte) (*chaincodeResponse, error) {
+	f, err := parseFields(msg)
+	if err != nil {
+		return nil, err
+	}
+	res := &chaincodeResponse{
+		Status:  int32(f.varints[1]),
+		Message: string(f.first(2)),
+		Payload: f.first(3),
+	}
+	if res.Status >= chaincodeStatusError {
+		return nil, fmt.Errorf("chaincode error %d: %s", res.Status, res.Message)
+	}
+	return res, nil
+}
+
+// evaluateResult returns the chaincode response of a gateway.EvaluateResponse.
+func evaluateResult(msg []byte) (*chaincodeResponse, error) {
+	res, err := field(msg, 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evaluate response: %w", err)
+	}
+	return parseChaincodeResponse(res)
+}
+
+// preparedTransaction returns the common.Envelope of a gateway.EndorseResponse, and the chaincode response it holds:
+// Envelope.payload > Payload.data > Transaction.actions[0] > TransactionAction.payload > ChaincodeActionPayload.action >
+// ChaincodeEndorsedAction.proposal_response_payload > ProposalResponsePayload.extension > ChaincodeAction.response.
+func preparedTransaction(msg []byte) ([]byte, *chaincodeResponse, error) {
+	envelope, err := field(msg, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid endorse response: %w", err)
+	}
+	if len(envelope) == 0 {
+		return nil, nil, errors.New("invalid endorse response: no prepared transaction")
+	}
+	res, err := field(envelope, 1, 2, 1, 2, 2, 1, 2, 3)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid prepared transaction: %w", err)
+	}
+	chaincodeRes, err := parseChaincodeResponse(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	return envelope, chaincodeRes, nil
+}
+
+// commitStatus returns the validation code and the block number of a gateway.CommitStatusResponse.
+func commitStatus(msg []byte) (int32, uint64, error) {
+	f, err := parseFields(msg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid commit status response: %w", err)
+	}
+	return int32(f.varints[1]), f.varints[2], nil
+}
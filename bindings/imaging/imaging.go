@@ -0,0 +1,422 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	// Registers the WebP decoder: WebP images can be read, and are written as PNG by default.
+	_ "golang.org/x/image/webp"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultQuality       = 85
+	defaultThumbnailSize = 128
+	// defaultMaxPixels rejects images whose decoding would take an unreasonable amount of memory.
+	defaultMaxPixels = 50_000_000
+
+	// keys from request's metadata.
+	widthKey     = "width"
+	heightKey    = "height"
+	xKey         = "x"
+	yKey         = "y"
+	modeKey      = "mode"
+	formatKey    = "format"
+	qualityKey   = "quality"
+	sourceKeyKey = "sourceKey"
+	targetKeyKey = "targetKey"
+
+	// keys from response's metadata.
+	respWidthKey     = "width"
+	respHeightKey    = "height"
+	respFormatKey    = "format"
+	respTargetKeyKey = "targetKey"
+
+	ResizeOperation    bindings.OperationKind = "resize"
+	ThumbnailOperation bindings.OperationKind = "thumbnail"
+	CropOperation      bindings.OperationKind = "crop"
+	ConvertOperation   bindings.OperationKind = "convert"
+
+	// ModeFit scales the image to fit in the requested size, keeping its aspect ratio.
+	ModeFit = "fit"
+	// ModeFill scales the image to cover the requested size, keeping its aspect ratio, and crops the overflow around the center.
+	ModeFill = "fill"
+	// ModeStretch scales the image to the requested size, ignoring its aspect ratio.
+	ModeStretch = "stretch"
+)
+
+var errNoStorage = errors.New("the sourceKey and targetKey metadata require a storage")
+
+// Imaging is an output binding resizing, cropping and converting images.
+type Imaging struct {
+	metadata imagingMetadata
+	storage  state.OffloadStorage
+	logger   logger.Logger
+}
+
+type imagingMetadata struct {
+	// Default quality of the JPEG images written, from 1 to 100.
+	Quality int `mapstructure:"quality"`
+	// Default size of the thumbnails, in pixels.
+	ThumbnailSize int `mapstructure:"thumbnailSize"`
+	// Maximum number of pixels of the images read.
+	MaxPixels int `mapstructure:"maxPixels"`
+}
+
+// NewImaging returns a new imaging binding, processing the images in the request data.
+func NewImaging(logger logger.Logger) bindings.OutputBinding {
+	return &Imaging{logger: logger}
+}
+
+// NewImagingWithStorage returns a new imaging binding which also reads and writes the images named by the "sourceKey" and "targetKey" metadata in storage.
+// Object storage bindings are used as storage with bindings.NewOffloadStorage.
+func NewImagingWithStorage(logger logger.Logger, storage state.OffloadStorage) bindings.OutputBinding {
+	return &Imaging{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Init parses the metadata of the binding.
+func (i *Imaging) Init(meta bindings.Metadata) error {
+	i.metadata = imagingMetadata{
+		Quality:       defaultQuality,
+		ThumbnailSize: defaultThumbnailSize,
+		MaxPixels:     defaultMaxPixels,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &i.metadata)
+	if err != nil {
+		return fmt.Errorf("imaging binding error: %w", err)
+	}
+	if i.metadata.Quality < 1 || i.metadata.Quality > 100 {
+		return errors.New("imaging binding error: quality must be between 1 and 100")
+	}
+	if i.metadata.ThumbnailSize < 1 {
+		return errors.New("imaging binding error: thumbnailSize must be positive")
+	}
+	if i.metadata.MaxPixels < 1 {
+		return errors.New("imaging binding error: maxPixels must be positive")
+	}
+
+	return nil
+}
+
+// Operations returns the operations supported by the imaging binding.
+func (i *Imaging) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		ResizeOperation,
+		ThumbnailOperation,
+		CropOperation,
+		ConvertOperation,
+	}
+}
+
+// Invoke processes the image in the request data, or the one named by the "sourceKey" metadata.
+// The resulting image is returned, or saved under the "targetKey" metadata.
+func (i *Imaging) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	res, err := i.invoke(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("imaging binding error: %s failed: %w", req.Operation, err)
+	}
+
+	return res, nil
+}
+
+func (i *Imaging) invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	data := req.Data
+	if key := req.Metadata[sourceKeyKey]; key != "" {
+		if i.storage == nil {
+			return nil, errNoStorage
+		}
+		var err error
+		data, err = i.storage.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", key, err)
+		}
+	}
+
+	src, format, err := i.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst image.Image
+	switch req.Operation { //nolint:exhaustive
+	case ResizeOperation:
+		dst, err = resizeRequest(src, req.Metadata, 0, ModeFit)
+	case ThumbnailOperation:
+		dst, err = resizeRequest(src, req.Metadata, i.metadata.ThumbnailSize, ModeFill)
+	case CropOperation:
+		dst, err = cropRequest(src, req.Metadata)
+	case ConvertOperation:
+		dst = src
+	default:
+		return nil, errors.New("unsupported operation")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if f := req.Metadata[formatKey]; f != "" {
+		format = strings.ToLower(f)
+	} else if format == "webp" {
+		format = "png"
+	}
+	quality := i.metadata.Quality
+	if q, ok, qErr := intMetadata(req.Metadata, qualityKey); qErr != nil {
+		return nil, qErr
+	} else if ok {
+		quality = q
+	}
+
+	out, contentType, err := encode(dst, format, quality)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			respWidthKey:                       strconv.Itoa(dst.Bounds().Dx()),
+			respHeightKey:                      strconv.Itoa(dst.Bounds().Dy()),
+			respFormatKey:                      format,
+		},
+		ContentType: &contentType,
+	}
+	if key := req.Metadata[targetKeyKey]; key != "" {
+		if i.storage == nil {
+			return nil, errNoStorage
+		}
+		if err = i.storage.Put(ctx, key, out); err != nil {
+			return nil, fmt.Errorf("failed to write image %s: %w", key, err)
+		}
+		res.Metadata[respTargetKeyKey] = key
+	} else {
+		res.Data = out
+	}
+
+	return res, nil
+}
+
+func (i *Imaging) decode(data []byte) (image.Image, string, error) {
+	if len(data) == 0 {
+		return nil, "", errors.New("no image in the request")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	if cfg.Width*cfg.Height > i.metadata.MaxPixels {
+		return nil, "", fmt.Errorf("image of %dx%d pixels exceeds the maximum of %d pixels", cfg.Width, cfg.Height, i.metadata.MaxPixels)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return img, format, nil
+}
+
+func encode(img image.Image, format string, quality int) ([]byte, string, error) {
+	var (
+		buf         bytes.Buffer
+		err         error
+		contentType string
+	)
+	switch format {
+	case "jpeg", "jpg":
+		if quality < 1 || quality > 100 {
+			return nil, "", errors.New("quality must be between 1 and 100")
+		}
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case "png":
+		contentType = "image/png"
+		err = png.Encode(&buf, img)
+	case "gif":
+		contentType = "image/gif"
+		err = gif.Encode(&buf, img, nil)
+	default:
+		return nil, "", fmt.Errorf("format %q is not one of: jpeg, png, gif", format)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+// resizeRequest resizes src to the "width" and "height" of the request, which default to defaultSize when it's set.
+func resizeRequest(src image.Image, md map[string]string, defaultSize int, defaultMode string) (image.Image, error) {
+	width, _, err := intMetadata(md, widthKey)
+	if err != nil {
+		return nil, err
+	}
+	height, _, err := intMetadata(md, heightKey)
+	if err != nil {
+		return nil, err
+	}
+	if width <= 0 && height <= 0 {
+		width, height = defaultSize, defaultSize
+	}
+	mode := md[modeKey]
+	if mode == "" {
+		mode = defaultMode
+	}
+
+	return resize(src, width, height, mode)
+}
+
+// resize scales src to width x height according to mode.
+// When only one of width and height is set, the other one is computed to keep the aspect ratio.
+func resize(src image.Image, width, height int, mode string) (image.Image, error) {
+	if width < 0 || height < 0 || (width == 0 && height == 0) {
+		return nil, errors.New("width or height must be set to positive values")
+	}
+
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	srcRect := bounds
+	switch {
+	case width == 0:
+		width = max(1, sw*height/sh)
+	case height == 0:
+		height = max(1, sh*width/sw)
+	default:
+		switch mode {
+		case ModeFit:
+			// Scale by the smallest ratio, comparing sw/width and sh/height without rounding.
+			if sw*height > sh*width {
+				height = max(1, sh*width/sw)
+			} else {
+				width = max(1, sw*height/sh)
+			}
+		case ModeFill:
+			// Keep the centered part of src with the aspect ratio of the target.
+			if sw*height > sh*width {
+				cw := sh * width / height
+				srcRect.Min.X += (sw - cw) / 2
+				srcRect.Max.X = srcRect.Min.X + cw
+			} else {
+				ch := sw * height / width
+				srcRect.Min.Y += (sh - ch) / 2
+				srcRect.Max.Y = srcRect.Min.Y + ch
+			}
+		case ModeStretch:
+		default:
+			return nil, fmt.Errorf("mode %q is not one of: fit, fill, stretch", mode)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Src, nil)
+
+	return dst, nil
+}
+
+// cropRequest crops the rectangle of src at "x" and "y", of "width" and "height" pixels.
+func cropRequest(src image.Image, md map[string]string) (image.Image, error) {
+	var vals [4]int
+	for n, key := range []string{xKey, yKey, widthKey, heightKey} {
+		v, ok, err := intMetadata(md, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok && (key == widthKey || key == heightKey) {
+			return nil, fmt.Errorf("required metadata %q not set", key)
+		}
+		vals[n] = v
+	}
+
+	bounds := src.Bounds()
+	rect := image.Rect(vals[0], vals[1], vals[0]+vals[2], vals[1]+vals[3]).Add(bounds.Min)
+	if vals[2] <= 0 || vals[3] <= 0 || !rect.In(bounds) {
+		return nil, fmt.Errorf("crop rectangle %v is not inside the image of %dx%d pixels", rect.Sub(bounds.Min), bounds.Dx(), bounds.Dy())
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, rect.Min, draw.Src)
+
+	return dst, nil
+}
+
+func intMetadata(md map[string]string, key string) (int, bool, error) {
+	s, ok := md[key]
+	if !ok || s == "" {
+		return 0, false, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value for metadata %q: %s", key, s)
+	}
+
+	return v, true, nil
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// OperationsMetadata describes the operations of the imaging binding.
+func (i *Imaging) OperationsMetadata() []bindings.OperationMetadata {
+	common := []string{formatKey, qualityKey, sourceKeyKey, targetKeyKey}
+	response := []string{bindings.ResponseMetadataOperation, respWidthKey, respHeightKey, respFormatKey, respTargetKeyKey}
+	return []bindings.OperationMetadata{
+		{
+			Operation:        ResizeOperation,
+			Description:      "Resizes the image to the given width and height. The mode is one of fit (default), fill or stretch.",
+			RequestMetadata:  append([]string{widthKey, heightKey, modeKey}, common...),
+			ResponseMetadata: response,
+		},
+		{
+			Operation:        ThumbnailOperation,
+			Description:      "Resizes the image to a thumbnail, square of the thumbnailSize of the binding unless width or height are set. The mode defaults to fill.",
+			RequestMetadata:  append([]string{widthKey, heightKey, modeKey}, common...),
+			ResponseMetadata: response,
+		},
+		{
+			Operation:        CropOperation,
+			Description:      "Crops the rectangle of the image at x and y, of the given width and height.",
+			RequestMetadata:  append([]string{xKey, yKey, widthKey, heightKey}, common...),
+			ResponseMetadata: response,
+		},
+		{
+			Operation:        ConvertOperation,
+			Description:      "Converts the image to the given format: jpeg, png or gif.",
+			RequestMetadata:  common,
+			ResponseMetadata: response,
+		},
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+type fakeStorage map[string][]byte
+
+func (s fakeStorage) Put(_ context.Context, name string, data []byte) error {
+	s[name] = data
+	return nil
+}
+
+func (s fakeStorage) Get(_ context.Context, name string) ([]byte, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (s fakeStorage) Delete(_ context.Context, name string) error {
+	delete(s, name)
+	return nil
+}
+
+func testImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func newTestImaging(t *testing.T, storage fakeStorage, props map[string]string) bindings.OutputBinding {
+	t.Helper()
+
+	b := NewImagingWithStorage(logger.NewLogger("test"), storage)
+	require.NoError(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	return b
+}
+
+func TestInit(t *testing.T) {
+	b := NewImaging(logger.NewLogger("test")).(*Imaging)
+	require.NoError(t, b.Init(bindings.Metadata{}))
+	assert.Equal(t, defaultQuality, b.metadata.Quality)
+	assert.Equal(t, defaultThumbnailSize, b.metadata.ThumbnailSize)
+
+	for _, props := range []map[string]string{
+		{"quality": "0"},
+		{"thumbnailSize": "-1"},
+		{"maxPixels": "0"},
+	} {
+		assert.Error(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	src := testImage(t, 40, 20)
+	b := newTestImaging(t, fakeStorage{}, map[string]string{"thumbnailSize": "16"})
+
+	tests := []struct {
+		name           string
+		operation      bindings.OperationKind
+		md             map[string]string
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{"resize fit", ResizeOperation, map[string]string{widthKey: "10", heightKey: "10"}, 10, 5},
+		{"resize fill", ResizeOperation, map[string]string{widthKey: "10", heightKey: "10", modeKey: ModeFill}, 10, 10},
+		{"resize stretch", ResizeOperation, map[string]string{widthKey: "10", heightKey: "10", modeKey: ModeStretch}, 10, 10},
+		{"resize width only", ResizeOperation, map[string]string{widthKey: "20"}, 20, 10},
+		{"resize height only", ResizeOperation, map[string]string{heightKey: "40"}, 80, 40},
+		{"thumbnail", ThumbnailOperation, nil, 16, 16},
+		{"thumbnail with size", ThumbnailOperation, map[string]string{widthKey: "8", heightKey: "4"}, 8, 4},
+		{"crop", CropOperation, map[string]string{xKey: "5", yKey: "5", widthKey: "30", heightKey: "10"}, 30, 10},
+		{"convert", ConvertOperation, nil, 40, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: tt.operation,
+				Data:      src,
+				Metadata:  tt.md,
+			})
+			require.NoError(t, err)
+
+			img, format, err := image.Decode(bytes.NewReader(res.Data))
+			require.NoError(t, err)
+			assert.Equal(t, "png", format)
+			assert.Equal(t, "image/png", *res.ContentType)
+			assert.Equal(t, tt.expectedWidth, img.Bounds().Dx())
+			assert.Equal(t, tt.expectedHeight, img.Bounds().Dy())
+			assert.Equal(t, string(tt.operation), res.Metadata[bindings.ResponseMetadataOperation])
+		})
+	}
+}
+
+func TestInvokeCropPixels(t *testing.T) {
+	src := testImage(t, 40, 20)
+	b := newTestImaging(t, fakeStorage{}, nil)
+
+	res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: CropOperation,
+		Data:      src,
+		Metadata:  map[string]string{xKey: "10", yKey: "4", widthKey: "2", heightKey: "2"},
+	})
+	require.NoError(t, err)
+
+	orig, err := png.Decode(bytes.NewReader(src))
+	require.NoError(t, err)
+	cropped, err := png.Decode(bytes.NewReader(res.Data))
+	require.NoError(t, err)
+	assert.Equal(t, orig.At(10, 4), cropped.At(0, 0))
+	assert.Equal(t, orig.At(11, 5), cropped.At(1, 1))
+}
+
+func TestInvokeStorage(t *testing.T) {
+	storage := fakeStorage{"in.png": testImage(t, 40, 20)}
+	b := newTestImaging(t, storage, nil)
+
+	res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: ThumbnailOperation,
+		Metadata: map[string]string{
+			sourceKeyKey: "in.png",
+			targetKeyKey: "thumb.jpg",
+			formatKey:    "jpeg",
+			qualityKey:   "50",
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res.Data)
+	assert.Equal(t, "thumb.jpg", res.Metadata[respTargetKeyKey])
+	assert.Equal(t, "jpeg", res.Metadata[respFormatKey])
+	assert.Equal(t, "image/jpeg", *res.ContentType)
+
+	img, err := jpeg.Decode(bytes.NewReader(storage["thumb.jpg"]))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, defaultThumbnailSize, defaultThumbnailSize), img.Bounds())
+}
+
+func TestInvokeErrors(t *testing.T) {
+	src := testImage(t, 40, 20)
+	b := newTestImaging(t, fakeStorage{}, map[string]string{"maxPixels": "1000"})
+	noStorage := NewImaging(logger.NewLogger("test"))
+	require.NoError(t, noStorage.Init(bindings.Metadata{}))
+
+	tests := []struct {
+		name      string
+		binding   bindings.OutputBinding
+		operation bindings.OperationKind
+		data      []byte
+		md        map[string]string
+	}{
+		{"unsupported operation", b, bindings.CreateOperation, src, nil},
+		{"no image", b, ConvertOperation, nil, nil},
+		{"not an image", b, ConvertOperation, []byte("hello"), nil},
+		{"too many pixels", b, ConvertOperation, testImage(t, 50, 50), nil},
+		{"invalid width", b, ResizeOperation, src, map[string]string{widthKey: "ten"}},
+		{"no size", b, ResizeOperation, src, nil},
+		{"invalid mode", b, ResizeOperation, src, map[string]string{widthKey: "10", heightKey: "10", modeKey: "zoom"}},
+		{"crop outside", b, CropOperation, src, map[string]string{xKey: "30", widthKey: "20", heightKey: "10"}},
+		{"crop without size", b, CropOperation, src, map[string]string{xKey: "1"}},
+		{"invalid format", b, ConvertOperation, src, map[string]string{formatKey: "bmp"}},
+		{"invalid quality", b, ConvertOperation, src, map[string]string{formatKey: "jpeg", qualityKey: "101"}},
+		{"missing source", b, ConvertOperation, nil, map[string]string{sourceKeyKey: "missing.png"}},
+		{"no storage", noStorage, ConvertOperation, src, map[string]string{targetKeyKey: "out.png"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.binding.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: tt.operation,
+				Data:      tt.data,
+				Metadata:  tt.md,
+			})
+			assert.Error(t, err)
+		})
+	}
+}
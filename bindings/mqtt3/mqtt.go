@@ -27,6 +27,7 @@ import (
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/internal/utils"
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/retry"
@@ -200,7 +201,7 @@ func (m *MQTT) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindin
 			// If user does not specify a topic, publish via the component's default topic.
 			topic = m.metadata.topic
 		}
-		m.logger.Debugf("mqtt publishing topic %s with data: %v", topic, req.Data)
+		m.logger.Debugf("mqtt publishing topic %s with data: %s", topic, redact.Body(req.Data))
 		token := m.producer.Publish(topic, m.metadata.qos, m.metadata.retain, req.Data)
 		if !token.WaitTimeout(defaultWait) || token.Error() != nil {
 			return fmt.Errorf("mqtt error from publish: %v", token.Error())
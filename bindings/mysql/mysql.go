@@ -14,6 +14,7 @@ limitations under the License.
 package mysql
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -22,22 +23,30 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/bulkdata"
+	mysqlinternal "github.com/dapr/components-contrib/internal/component/mysql"
+	"github.com/dapr/components-contrib/internal/dialer"
 	"github.com/dapr/kit/logger"
 )
 
 const (
 	// list of operations.
-	execOperation  bindings.OperationKind = "exec"
-	queryOperation bindings.OperationKind = "query"
-	closeOperation bindings.OperationKind = "close"
+	execOperation       bindings.OperationKind = "exec"
+	queryOperation      bindings.OperationKind = "query"
+	closeOperation      bindings.OperationKind = "close"
+	bulkInsertOperation bindings.OperationKind = "bulkInsert"
+	exportOperation     bindings.OperationKind = "export"
 
 	// configurations to connect to Mysql, either a data source name represent by URL.
 	connectionURLKey = "url"
@@ -61,14 +70,18 @@ const (
 	commandSQLKey = "sql"
 
 	// keys from response's metadata.
-	respOpKey           = "operation"
+	respOpKey           = bindings.ResponseMetadataOperation
 	respSQLKey          = "sql"
 	respStartTimeKey    = "start-time"
 	respRowsAffectedKey = "rows-affected"
+	respRowsReturnedKey = "rows-returned"
 	respEndTimeKey      = "end-time"
 	respDurationKey     = "duration"
 )
 
+// readerHandlerSeq numbers the reader handlers registered for the bulk inserts, as they are shared by all the connections.
+var readerHandlerSeq atomic.Uint64
+
 // Mysql represents MySQL output bindings.
 type Mysql struct {
 	db     *sql.DB
@@ -90,6 +103,11 @@ func (m *Mysql) Init(metadata bindings.Metadata) error {
 		return fmt.Errorf("missing MySql connection string")
 	}
 
+	url, err := mysqlinternal.DSNWithDialAddress(url, p[dialer.MetadataKey])
+	if err != nil {
+		return fmt.Errorf("illegal Data Source Name (DSN) or %s: %w", dialer.MetadataKey, err)
+	}
+
 	db, err := initDB(url, metadata.Properties[pemPathKey])
 	if err != nil {
 		return err
@@ -140,21 +158,26 @@ func (m *Mysql) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 	}
 	m.logger.Debugf("operation: %v", req.Operation)
 
-	s, ok := req.Metadata[commandSQLKey]
-	if !ok || s == "" {
-		return nil, fmt.Errorf("required metadata not set: %s", commandSQLKey)
-	}
-
 	startTime := time.Now()
 
 	resp := &bindings.InvokeResponse{
 		Metadata: map[string]string{
 			respOpKey:        string(req.Operation),
-			respSQLKey:       s,
 			respStartTimeKey: startTime.Format(time.RFC3339Nano),
 		},
 	}
 
+	// The rows of bulk inserts come from the request data
+	var s string
+	if req.Operation != bulkInsertOperation {
+		var ok bool
+		s, ok = req.Metadata[commandSQLKey]
+		if !ok || s == "" {
+			return nil, fmt.Errorf("required metadata not set: %s", commandSQLKey)
+		}
+		resp.Metadata[respSQLKey] = s
+	}
+
 	switch req.Operation { //nolint:exhaustive
 	case execOperation:
 		r, err := m.exec(ctx, s)
@@ -164,15 +187,31 @@ func (m *Mysql) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		resp.Metadata[respRowsAffectedKey] = strconv.FormatInt(r, 10)
 
 	case queryOperation:
-		d, err := m.query(ctx, s)
+		d, n, err := m.query(ctx, s)
 		if err != nil {
 			return nil, err
 		}
 		resp.Data = d
+		resp.Metadata[respRowsReturnedKey] = strconv.Itoa(n)
+
+	case bulkInsertOperation:
+		r, err := m.bulkInsert(ctx, req.Data, req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		resp.Metadata[respRowsAffectedKey] = strconv.FormatInt(r, 10)
+
+	case exportOperation:
+		d, n, err := m.export(ctx, s, req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		resp.Data = d
+		resp.Metadata[respRowsReturnedKey] = strconv.Itoa(n)
 
 	default:
-		return nil, fmt.Errorf("invalid operation type: %s. Expected %s, %s, or %s",
-			req.Operation, execOperation, queryOperation, closeOperation)
+		return nil, fmt.Errorf("invalid operation type: %s. Expected %s, %s, %s, %s, or %s",
+			req.Operation, execOperation, queryOperation, bulkInsertOperation, exportOperation, closeOperation)
 	}
 
 	endTime := time.Now()
@@ -182,12 +221,49 @@ func (m *Mysql) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 	return resp, nil
 }
 
+// OperationsMetadata returns the description of the operations of the binding.
+func (m *Mysql) OperationsMetadata() []bindings.OperationMetadata {
+	common := []string{respOpKey, respSQLKey, respStartTimeKey, respEndTimeKey, respDurationKey}
+	return []bindings.OperationMetadata{
+		{
+			Operation:        execOperation,
+			Description:      "Execute a statement, returning the number of affected rows",
+			RequestMetadata:  []string{commandSQLKey},
+			ResponseMetadata: append([]string{respRowsAffectedKey}, common...),
+		},
+		{
+			Operation:        queryOperation,
+			Description:      "Execute a query, returning the rows as a JSON array",
+			RequestMetadata:  []string{commandSQLKey},
+			ResponseMetadata: append([]string{respRowsReturnedKey}, common...),
+		},
+		{
+			Operation:   closeOperation,
+			Description: "Close the connection to the database",
+		},
+		{
+			Operation:        bulkInsertOperation,
+			Description:      "Load the CSV or JSON lines rows of the request data into a table with LOAD DATA LOCAL INFILE",
+			RequestMetadata:  []string{bulkdata.TableKey, bulkdata.ColumnsKey, bulkdata.FormatKey},
+			ResponseMetadata: []string{respRowsAffectedKey, respOpKey, respStartTimeKey, respEndTimeKey, respDurationKey},
+		},
+		{
+			Operation:        exportOperation,
+			Description:      "Execute a query, returning the rows as CSV or JSON lines",
+			RequestMetadata:  []string{commandSQLKey, bulkdata.FormatKey},
+			ResponseMetadata: append([]string{respRowsReturnedKey}, common...),
+		},
+	}
+}
+
 // Operations returns list of operations supported by Mysql binding.
 func (m *Mysql) Operations() []bindings.OperationKind {
 	return []bindings.OperationKind{
 		execOperation,
 		queryOperation,
 		closeOperation,
+		bulkInsertOperation,
+		exportOperation,
 	}
 }
 
@@ -200,10 +276,10 @@ func (m *Mysql) Close() error {
 	return nil
 }
 
-func (m *Mysql) query(ctx context.Context, sql string) ([]byte, error) {
+func (m *Mysql) query(ctx context.Context, sql string) ([]byte, int, error) {
 	rows, err := m.db.QueryContext(ctx, sql)
 	if err != nil {
-		return nil, fmt.Errorf("error executing query: %w", err)
+		return nil, 0, fmt.Errorf("error executing query: %w", err)
 	}
 
 	defer func() {
@@ -211,12 +287,12 @@ func (m *Mysql) query(ctx context.Context, sql string) ([]byte, error) {
 		_ = rows.Err()
 	}()
 
-	result, err := m.jsonify(rows)
+	result, n, err := m.jsonify(rows)
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling query result for query: %w", err)
+		return nil, 0, fmt.Errorf("error marshalling query result for query: %w", err)
 	}
 
-	return result, nil
+	return result, n, nil
 }
 
 func (m *Mysql) exec(ctx context.Context, sql string) (int64, error) {
@@ -230,6 +306,106 @@ func (m *Mysql) exec(ctx context.Context, sql string) (int64, error) {
 	return res.RowsAffected()
 }
 
+// bulkInsert streams the rows of data to LOAD DATA LOCAL INFILE, returning the number of rows loaded.
+// The server must allow loading local data, with the local_infile system variable.
+func (m *Mysql) bulkInsert(ctx context.Context, data []byte, md map[string]string) (int64, error) {
+	table := md[bulkdata.TableKey]
+	if table == "" {
+		return 0, fmt.Errorf("required metadata not set: %s", bulkdata.TableKey)
+	}
+	format, err := bulkdata.ParseFormat(md[bulkdata.FormatKey])
+	if err != nil {
+		return 0, err
+	}
+	rows, err := bulkdata.NewReader(bytes.NewReader(data), format, bulkdata.ParseColumns(md[bulkdata.ColumnsKey]))
+	if err != nil {
+		return 0, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	src := bulkdata.CSV(rows)
+	defer src.Close()
+
+	name := "dapr-bulk-insert-" + strconv.FormatUint(readerHandlerSeq.Add(1), 10)
+	mysql.RegisterReaderHandler(name, func() io.Reader { return src })
+	defer mysql.DeregisterReaderHandler(name)
+
+	columns := make([]string, len(rows.Columns()))
+	for i, c := range rows.Columns() {
+		columns[i] = quoteIdentifier(c)
+	}
+	tableParts := strings.Split(table, ".")
+	for i, t := range tableParts {
+		tableParts[i] = quoteIdentifier(t)
+	}
+	s := fmt.Sprintf(`LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY '' LINES TERMINATED BY '\n' (%s)`,
+		name, strings.Join(tableParts, "."), strings.Join(columns, ", "))
+	m.logger.Debugf("bulk insert: %s", s)
+
+	res, err := m.db.ExecContext(ctx, s)
+	if err != nil {
+		return 0, fmt.Errorf("error loading data: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// export returns the rows of the query in the requested format, and their number.
+func (m *Mysql) export(ctx context.Context, sql string, md map[string]string) ([]byte, int, error) {
+	format, err := bulkdata.ParseFormat(md[bulkdata.FormatKey])
+	if err != nil {
+		return nil, 0, err
+	}
+	m.logger.Debugf("export: %s", sql)
+
+	rows, err := m.db.QueryContext(ctx, sql)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error executing query: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, 0, err
+	}
+	columns := make([]string, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = ct.Name()
+	}
+
+	var buf bytes.Buffer
+	w, err := bulkdata.NewWriter(&buf, format, columns)
+	if err != nil {
+		return nil, 0, err
+	}
+	for rows.Next() {
+		values := prepareValues(columnTypes)
+		if err = rows.Scan(values...); err != nil {
+			return nil, 0, err
+		}
+		for i, ct := range columnTypes {
+			values[i] = m.convertValue(ct, values[i])
+		}
+		if err = w.Write(values); err != nil {
+			return nil, 0, fmt.Errorf("error serializing query result: %w", err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if err = w.Flush(); err != nil {
+		return nil, 0, err
+	}
+
+	return buf.Bytes(), w.Rows(), nil
+}
+
+// quoteIdentifier quotes a table or column name with backticks.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
 func propertyToInt(props map[string]string, key string, setter func(int)) error {
 	if v, ok := props[key]; ok {
 		if i, err := strconv.Atoi(v); err == nil {
@@ -285,10 +461,11 @@ func initDB(url, pemPath string) (*sql.DB, error) {
 	return db, nil
 }
 
-func (m *Mysql) jsonify(rows *sql.Rows) ([]byte, error) {
+// jsonify returns the rows serialized as JSON, and their number.
+func (m *Mysql) jsonify(rows *sql.Rows) ([]byte, int, error) {
 	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	var ret []interface{}
@@ -296,14 +473,15 @@ func (m *Mysql) jsonify(rows *sql.Rows) ([]byte, error) {
 		values := prepareValues(columnTypes)
 		err := rows.Scan(values...)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		r := m.convert(columnTypes, values)
 		ret = append(ret, r)
 	}
 
-	return json.Marshal(ret)
+	b, err := json.Marshal(ret)
+	return b, len(ret), err
 }
 
 func prepareValues(columnTypes []*sql.ColumnType) []interface{} {
@@ -324,27 +502,39 @@ func (m *Mysql) convert(columnTypes []*sql.ColumnType, values []interface{}) map
 	r := map[string]interface{}{}
 
 	for i, ct := range columnTypes {
-		value := values[i]
-
-		switch v := values[i].(type) {
-		case driver.Valuer:
-			if vv, err := v.Value(); err == nil {
-				value = interface{}(vv)
-			} else {
-				m.logger.Warnf("error to convert value: %v", err)
-			}
-		case *sql.RawBytes:
-			// special case for sql.RawBytes, see https://github.com/go-sql-driver/mysql/blob/master/fields.go#L178
-			switch ct.DatabaseTypeName() {
-			case "VARCHAR", "CHAR", "TEXT", "LONGTEXT":
-				value = string(*v)
-			}
-		}
-
-		if value != nil {
+		if value := m.convertValue(ct, values[i]); value != nil {
 			r[ct.Name()] = value
 		}
 	}
 
 	return r
 }
+
+// convertValue dereferences a value scanned into a value prepared with prepareValues.
+func (m *Mysql) convertValue(ct *sql.ColumnType, value interface{}) interface{} {
+	switch v := value.(type) {
+	case driver.Valuer:
+		if vv, err := v.Value(); err == nil {
+			value = interface{}(vv)
+		} else {
+			m.logger.Warnf("error to convert value: %v", err)
+		}
+	case *sql.RawBytes:
+		// special case for sql.RawBytes, see https://github.com/go-sql-driver/mysql/blob/master/fields.go#L178
+		switch ct.DatabaseTypeName() {
+		case "VARCHAR", "CHAR", "TEXT", "LONGTEXT":
+			value = string(*v)
+		default:
+			value = append([]byte(nil), *v...)
+		}
+	default:
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return nil
+			}
+			value = rv.Elem().Interface()
+		}
+	}
+
+	return value
+}
@@ -15,6 +15,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -38,8 +39,9 @@ func TestQuery(t *testing.T) {
 			AddRow(3, "value-3", time.Now().Add(2000))
 
 		mock.ExpectQuery("SELECT \\* FROM foo WHERE id < 4").WillReturnRows(rows)
-		ret, err := m.query(context.Background(), `SELECT * FROM foo WHERE id < 4`)
+		ret, n, err := m.query(context.Background(), `SELECT * FROM foo WHERE id < 4`)
 		assert.Nil(t, err)
+		assert.Equal(t, 3, n)
 		t.Logf("query result: %s", ret)
 		assert.Contains(t, string(ret), "\"id\":1")
 		var result []interface{}
@@ -57,8 +59,9 @@ func TestQuery(t *testing.T) {
 			AddRow(2, 2.2, time.Now().Add(1000)).
 			AddRow(3, 3.3, time.Now().Add(2000))
 		mock.ExpectQuery("SELECT \\* FROM foo WHERE id < 4").WillReturnRows(rows)
-		ret, err := m.query(context.Background(), "SELECT * FROM foo WHERE id < 4")
+		ret, n, err := m.query(context.Background(), "SELECT * FROM foo WHERE id < 4")
 		assert.Nil(t, err)
+		assert.Equal(t, 3, n)
 		t.Logf("query result: %s", ret)
 
 		// verify number
@@ -174,6 +177,94 @@ func TestInvoke(t *testing.T) {
 	})
 }
 
+func TestBulkInsert(t *testing.T) {
+	m, mock, _ := mockDatabase(t)
+	defer m.Close()
+
+	t.Run("csv", func(t *testing.T) {
+		mock.ExpectExec("LOAD DATA LOCAL INFILE 'Reader::dapr-bulk-insert-[0-9]+' INTO TABLE `db`\\.`foo` .* \\(`id`, `v1`\\)").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		req := &bindings.InvokeRequest{
+			Operation: bulkInsertOperation,
+			Metadata:  map[string]string{"table": "db.foo"},
+			Data:      []byte("id,v1\n1,a\n2,b\n"),
+		}
+		resp, err := m.Invoke(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, "2", resp.Metadata[respRowsAffectedKey])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("jsonl with columns", func(t *testing.T) {
+		mock.ExpectExec("LOAD DATA LOCAL INFILE .* INTO TABLE `foo` .* \\(`v1`, `id`\\)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		req := &bindings.InvokeRequest{
+			Operation: bulkInsertOperation,
+			Metadata:  map[string]string{"table": "foo", "format": "jsonl", "columns": "v1,id"},
+			Data:      []byte(`{"id":1,"v1":"a"}`),
+		}
+		resp, err := m.Invoke(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, "1", resp.Metadata[respRowsAffectedKey])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("table is required", func(t *testing.T) {
+		req := &bindings.InvokeRequest{
+			Operation: bulkInsertOperation,
+			Metadata:  map[string]string{},
+			Data:      []byte("id\n1\n"),
+		}
+		_, err := m.Invoke(context.Background(), req)
+		assert.ErrorContains(t, err, "required metadata not set: table")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		req := &bindings.InvokeRequest{
+			Operation: bulkInsertOperation,
+			Metadata:  map[string]string{"table": "foo", "format": "xml"},
+		}
+		_, err := m.Invoke(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
+
+func TestExport(t *testing.T) {
+	m, mock, _ := mockDatabase(t)
+	defer m.Close()
+
+	newRows := func() *sqlmock.Rows {
+		col1 := sqlmock.NewColumn("id").OfType("BIGINT", 1)
+		col2 := sqlmock.NewColumn("value").OfType("VARCHAR", sql.NullString{})
+		return sqlmock.NewRowsWithColumnDefinition(col1, col2).
+			AddRow(1, "a,b").
+			AddRow(2, nil)
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, value FROM foo").WillReturnRows(newRows())
+		req := &bindings.InvokeRequest{
+			Operation: exportOperation,
+			Metadata:  map[string]string{commandSQLKey: "SELECT id, value FROM foo"},
+		}
+		resp, err := m.Invoke(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, "id,value\n1,\"a,b\"\n2,\n", string(resp.Data))
+		assert.Equal(t, "2", resp.Metadata[respRowsReturnedKey])
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, value FROM foo").WillReturnRows(newRows())
+		req := &bindings.InvokeRequest{
+			Operation: exportOperation,
+			Metadata:  map[string]string{commandSQLKey: "SELECT id, value FROM foo", "format": "jsonl"},
+		}
+		resp, err := m.Invoke(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"id":1,"value":"a,b"}`+"\n"+`{"id":2,"value":null}`+"\n", string(resp.Data))
+	})
+}
+
 func mockDatabase(t *testing.T) (*Mysql, sqlmock.Sqlmock, error) {
 	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
 	if err != nil {
@@ -0,0 +1,269 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultName    = "dapr.io - bindings.nats"
+	defaultTimeout = 5 * time.Second
+
+	// keys from request's metadata.
+	subjectKey = "subject"
+	timeoutKey = "timeout"
+
+	// headers set by the services written with the NATS micro framework when returning errors.
+	serviceErrorHeader     = "Nats-Service-Error"
+	serviceErrorCodeHeader = "Nats-Service-Error-Code"
+
+	RequestOperation bindings.OperationKind = "request"
+)
+
+// NATS is an output binding publishing messages and sending requests to NATS subjects.
+type NATS struct {
+	metadata natsMetadata
+	nc       *nats.Conn
+	logger   logger.Logger
+}
+
+type natsMetadata struct {
+	NatsURL       string        `mapstructure:"natsURL"`
+	JWT           string        `mapstructure:"jwt"`
+	SeedKey       string        `mapstructure:"seedKey"`
+	Token         string        `mapstructure:"token"`
+	TLSClientCert string        `mapstructure:"tls_client_cert"`
+	TLSClientKey  string        `mapstructure:"tls_client_key"`
+	Name          string        `mapstructure:"name"`
+	Subject       string        `mapstructure:"subject"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+}
+
+// NewNATS returns a new NATS output binding.
+func NewNATS(logger logger.Logger) bindings.OutputBinding {
+	return &NATS{logger: logger}
+}
+
+// Init performs metadata parsing and connects to NATS.
+func (n *NATS) Init(meta bindings.Metadata) error {
+	m, err := parseMetadata(meta)
+	if err != nil {
+		return err
+	}
+	n.metadata = m
+
+	opts := []nats.Option{nats.Name(m.Name)}
+	switch {
+	case m.JWT != "":
+		opts = append(opts, nats.UserJWT(func() (string, error) {
+			return m.JWT, nil
+		}, func(nonce []byte) ([]byte, error) {
+			return sigHandler(m.SeedKey, nonce)
+		}))
+	case m.TLSClientCert != "":
+		opts = append(opts, nats.ClientCert(m.TLSClientCert, m.TLSClientKey))
+	case m.Token != "":
+		opts = append(opts, nats.Token(m.Token))
+	}
+
+	n.nc, err = nats.Connect(m.NatsURL, opts...)
+	if err != nil {
+		return fmt.Errorf("nats binding error: failed to connect to %s: %w", m.NatsURL, err)
+	}
+	n.logger.Debugf("Connected to nats at %s", m.NatsURL)
+
+	return nil
+}
+
+func parseMetadata(meta bindings.Metadata) (natsMetadata, error) {
+	m := natsMetadata{
+		Name:    defaultName,
+		Timeout: defaultTimeout,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &m)
+	if err != nil {
+		return m, fmt.Errorf("nats binding error: %w", err)
+	}
+
+	if m.NatsURL == "" {
+		return m, errors.New("nats binding error: missing nats URL")
+	}
+	if (m.JWT == "") != (m.SeedKey == "") {
+		return m, errors.New("nats binding error: jwt and seedKey must be set together")
+	}
+	if (m.TLSClientCert == "") != (m.TLSClientKey == "") {
+		return m, errors.New("nats binding error: tls_client_cert and tls_client_key must be set together")
+	}
+	if m.Timeout <= 0 {
+		return m, errors.New("nats binding error: timeout must be positive")
+	}
+
+	return m, nil
+}
+
+// Operations returns list of operations supported by the NATS binding.
+func (n *NATS) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		bindings.CreateOperation,
+		RequestOperation,
+	}
+}
+
+// Invoke publishes a message, or sends a request and waits for the reply.
+func (n *NATS) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	subject := n.metadata.Subject
+	if s := req.Metadata[subjectKey]; s != "" {
+		subject = s
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("nats binding error: required metadata %q not set", subjectKey)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = req.Data
+	for k, v := range req.Metadata {
+		switch k {
+		case subjectKey, timeoutKey:
+			continue
+		}
+		msg.Header.Set(k, v)
+	}
+
+	switch req.Operation { //nolint:exhaustive
+	case bindings.CreateOperation:
+		err := n.nc.PublishMsg(msg)
+		if err != nil {
+			return nil, fmt.Errorf("nats binding error: failed to publish to %s: %w", subject, err)
+		}
+		return &bindings.InvokeResponse{
+			Metadata: map[string]string{
+				bindings.ResponseMetadataOperation: string(req.Operation),
+				subjectKey:                         subject,
+			},
+		}, nil
+	case RequestOperation:
+		return n.request(ctx, req, msg)
+	default:
+		return nil, fmt.Errorf("nats binding error: unsupported operation %s", req.Operation)
+	}
+}
+
+func (n *NATS) request(ctx context.Context, req *bindings.InvokeRequest, msg *nats.Msg) (*bindings.InvokeResponse, error) {
+	timeout := n.metadata.Timeout
+	if t := req.Metadata[timeoutKey]; t != "" {
+		var err error
+		timeout, err = parseTimeout(t)
+		if err != nil {
+			return nil, fmt.Errorf("nats binding error: invalid %s %q: %w", timeoutKey, t, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reply, err := n.nc.RequestMsgWithContext(ctx, msg)
+	if err != nil {
+		if errors.Is(err, nats.ErrNoResponders) {
+			return nil, fmt.Errorf("nats binding error: no responders for subject %s", msg.Subject)
+		}
+		return nil, fmt.Errorf("nats binding error: request to %s failed: %w", msg.Subject, err)
+	}
+
+	md := make(map[string]string, len(reply.Header)+2)
+	for k, v := range reply.Header {
+		if len(v) > 0 {
+			md[k] = v[0]
+		}
+	}
+	md[bindings.ResponseMetadataOperation] = string(req.Operation)
+	md[subjectKey] = msg.Subject
+
+	res := &bindings.InvokeResponse{
+		Data:     reply.Data,
+		Metadata: md,
+	}
+	if desc := reply.Header.Get(serviceErrorHeader); desc != "" {
+		return res, fmt.Errorf("nats binding error: service error %s: %s", reply.Header.Get(serviceErrorCodeHeader), desc)
+	}
+
+	return res, nil
+}
+
+// parseTimeout parses a timeout expressed either as a duration or in milliseconds.
+func parseTimeout(val string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(val, 10, 64); err == nil {
+		if ms <= 0 {
+			return 0, errors.New("must be positive")
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return d, nil
+}
+
+// OperationsMetadata describes the operations of the NATS binding.
+func (n *NATS) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        bindings.CreateOperation,
+			Description:      "Publishes the data to a subject. The request metadata, other than subject, are sent as headers.",
+			RequestMetadata:  []string{subjectKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, subjectKey},
+		},
+		{
+			Operation:        RequestOperation,
+			Description:      "Sends the data to a subject and returns the reply. The request metadata, other than subject and timeout, are sent as headers, and the headers of the reply are returned as metadata.",
+			RequestMetadata:  []string{subjectKey, timeoutKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, subjectKey},
+		},
+	}
+}
+
+// Close closes the connection to NATS.
+func (n *NATS) Close() error {
+	if n.nc != nil {
+		n.nc.Close()
+	}
+	return nil
+}
+
+func sigHandler(seedKey string, nonce []byte) ([]byte, error) {
+	kp, err := nkeys.FromSeed([]byte(seedKey))
+	if err != nil {
+		return nil, err
+	}
+	// Wipe our key on exit.
+	defer kp.Wipe()
+
+	sig, _ := kp.Sign(nonce)
+	return sig, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"natsURL": "nats://localhost:4222",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, defaultName, m.Name)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, props := range map[string]map[string]string{
+			"missing url":      {},
+			"missing seed key": {"natsURL": "nats://localhost:4222", "jwt": "jwt"},
+			"missing tls key":  {"natsURL": "nats://localhost:4222", "tls_client_cert": "cert.pem"},
+			"invalid timeout":  {"natsURL": "nats://localhost:4222", "timeout": "-1s"},
+		} {
+			_, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: props}})
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestParseTimeout(t *testing.T) {
+	d, err := parseTimeout("250")
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, d)
+
+	d, err = parseTimeout("2s")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, d)
+
+	_, err = parseTimeout("0")
+	assert.Error(t, err)
+	_, err = parseTimeout("abc")
+	assert.Error(t, err)
+}
+
+func TestInvoke(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	srv := natsserver.RunServer(&opts)
+	defer srv.Shutdown()
+
+	nc, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+
+	_, err = nc.Subscribe("echo", func(msg *nats.Msg) {
+		reply := nats.NewMsg(msg.Reply)
+		reply.Data = append([]byte("echo: "), msg.Data...)
+		reply.Header.Set("Traceid", msg.Header.Get("traceid"))
+		_ = msg.RespondMsg(reply)
+	})
+	require.NoError(t, err)
+	_, err = nc.Subscribe("failing", func(msg *nats.Msg) {
+		reply := nats.NewMsg(msg.Reply)
+		reply.Header.Set(serviceErrorHeader, "bad request")
+		reply.Header.Set(serviceErrorCodeHeader, "400")
+		_ = msg.RespondMsg(reply)
+	})
+	require.NoError(t, err)
+	published, err := nc.SubscribeSync("events")
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	n := NewNATS(logger.NewLogger("test")).(*NATS)
+	err = n.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"natsURL": srv.ClientURL(),
+		"subject": "echo",
+		"timeout": "1s",
+	}}})
+	require.NoError(t, err)
+	defer n.Close()
+
+	t.Run("request", func(t *testing.T) {
+		res, err := n.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: RequestOperation,
+			Data:      []byte("hello"),
+			Metadata:  map[string]string{"traceid": "abc"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "echo: hello", string(res.Data))
+		assert.Equal(t, "abc", res.Metadata["Traceid"])
+		assert.Equal(t, "echo", res.Metadata[subjectKey])
+		assert.Equal(t, string(RequestOperation), res.Metadata[bindings.ResponseMetadataOperation])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		res, err := n.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: RequestOperation,
+			Metadata:  map[string]string{subjectKey: "failing"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "400")
+		assert.Equal(t, "bad request", res.Metadata[serviceErrorHeader])
+	})
+
+	t.Run("no responders", func(t *testing.T) {
+		_, err := n.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: RequestOperation,
+			Metadata:  map[string]string{subjectKey: "nobody", timeoutKey: "100"},
+		})
+		assert.ErrorContains(t, err, "no responders")
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		_, err := n.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("event"),
+			Metadata:  map[string]string{subjectKey: "events", "source": "test"},
+		})
+		require.NoError(t, err)
+
+		msg, err := published.NextMsg(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "event", string(msg.Data))
+		assert.Equal(t, "test", msg.Header.Get("source"))
+		assert.Empty(t, msg.Header.Get(subjectKey))
+	})
+}
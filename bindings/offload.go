@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindings
+
+import (
+	"context"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// offloadStorage saves the values offloaded from a state store with the create, get and delete operations of an output binding.
+type offloadStorage struct {
+	binding     OutputBinding
+	keyMetadata string
+}
+
+// NewOffloadStorage returns a state.OffloadStorage backed by an output binding, usually an object storage binding.
+// keyMetadata is the request metadata holding the name of the objects, e.g. "key" for AWS S3 or "blobName" for Azure Blob Storage.
+func NewOffloadStorage(binding OutputBinding, keyMetadata string) state.OffloadStorage {
+	return &offloadStorage{
+		binding:     binding,
+		keyMetadata: keyMetadata,
+	}
+}
+
+func (s *offloadStorage) Put(ctx context.Context, name string, data []byte) error {
+	_, err := s.invoke(ctx, CreateOperation, name, data)
+	return err
+}
+
+func (s *offloadStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	res, err := s.invoke(ctx, GetOperation, name, nil)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (s *offloadStorage) Delete(ctx context.Context, name string) error {
+	_, err := s.invoke(ctx, DeleteOperation, name, nil)
+	return err
+}
+
+func (s *offloadStorage) invoke(ctx context.Context, operation OperationKind, name string, data []byte) (*InvokeResponse, error) {
+	return s.binding.Invoke(ctx, &InvokeRequest{
+		Operation: operation,
+		Data:      data,
+		Metadata:  map[string]string{s.keyMetadata: name},
+	})
+}
@@ -27,6 +27,38 @@ type OutputBinding interface {
 	Operations() []OperationKind
 }
 
+// OperationsDescriber is implemented by output bindings that describe their operations in detail.
+type OperationsDescriber interface {
+	OperationsMetadata() []OperationMetadata
+}
+
+// OperationMetadata describes an operation of an output binding.
+type OperationMetadata struct {
+	Operation   OperationKind `json:"operation"`
+	Description string        `json:"description,omitempty"`
+	// Keys of the request metadata read by the operation.
+	RequestMetadata []string `json:"requestMetadata,omitempty"`
+	// Keys of the response metadata set by the operation.
+	ResponseMetadata []string `json:"responseMetadata,omitempty"`
+	// Paginated operations return a ResponseMetadataNextCursor to request the next page of results.
+	Paginated bool `json:"paginated,omitempty"`
+}
+
+// GetOperationsMetadata returns the description of the operations of the output binding.
+// For bindings that don't implement OperationsDescriber, only the names of the operations are returned.
+func GetOperationsMetadata(outputBinding OutputBinding) []OperationMetadata {
+	if describer, ok := outputBinding.(OperationsDescriber); ok {
+		return describer.OperationsMetadata()
+	}
+
+	ops := outputBinding.Operations()
+	res := make([]OperationMetadata, len(ops))
+	for i, op := range ops {
+		res[i] = OperationMetadata{Operation: op}
+	}
+	return res
+}
+
 func PingOutBinding(outputBinding OutputBinding) error {
 	// checks if this output binding has the ping option then executes
 	if outputBindingWithPing, ok := outputBinding.(health.Pinger); ok {
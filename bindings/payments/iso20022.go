@@ -0,0 +1,274 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+	dateFormat       = "2006-01-02"
+	dateTimeFormat   = "2006-01-02T15:04:05"
+)
+
+var (
+	currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+	bicRegex      = regexp.MustCompile(`^[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3})?$`)
+	ibanRegex     = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{1,30}$`)
+	amountRegex   = regexp.MustCompile(`^[0-9]{1,13}(\.[0-9]{1,5})?$`)
+	text35Regex   = regexp.MustCompile(`^[^\s].{0,34}$`)
+)
+
+// creditTransfer is the JSON representation of a customer credit transfer initiation (pain.001).
+type creditTransfer struct {
+	MessageID              string        `json:"messageId"`
+	CreationDateTime       *time.Time    `json:"creationDateTime,omitempty"`
+	InitiatingParty        string        `json:"initiatingParty"`
+	PaymentInformationID   string        `json:"paymentInformationId"`
+	RequestedExecutionDate string        `json:"requestedExecutionDate"`
+	Debtor                 party         `json:"debtor"`
+	Transactions           []transaction `json:"transactions"`
+}
+
+type party struct {
+	Name string `json:"name"`
+	IBAN string `json:"iban"`
+	BIC  string `json:"bic,omitempty"`
+}
+
+type transaction struct {
+	EndToEndID            string `json:"endToEndId"`
+	Amount                string `json:"amount"`
+	Currency              string `json:"currency"`
+	Creditor              party  `json:"creditor"`
+	RemittanceInformation string `json:"remittanceInformation,omitempty"`
+}
+
+// validate checks the credit transfer against the constraints of the pain.001.001.03 schema.
+func (c *creditTransfer) validate() error {
+	var errs []string
+	if !text35Regex.MatchString(c.MessageID) {
+		errs = append(errs, "messageId is required and must be at most 35 characters")
+	}
+	if c.InitiatingParty == "" || len(c.InitiatingParty) > 140 {
+		errs = append(errs, "initiatingParty is required and must be at most 140 characters")
+	}
+	if !text35Regex.MatchString(c.PaymentInformationID) {
+		errs = append(errs, "paymentInformationId is required and must be at most 35 characters")
+	}
+	if _, err := time.Parse(dateFormat, c.RequestedExecutionDate); err != nil {
+		errs = append(errs, "requestedExecutionDate must be a date in the format YYYY-MM-DD")
+	}
+	errs = append(errs, c.Debtor.validate("debtor")...)
+	if len(c.Transactions) == 0 {
+		errs = append(errs, "at least one transaction is required")
+	}
+	for i, tx := range c.Transactions {
+		prefix := fmt.Sprintf("transactions[%d]", i)
+		if !text35Regex.MatchString(tx.EndToEndID) {
+			errs = append(errs, prefix+".endToEndId is required and must be at most 35 characters")
+		}
+		if !amountRegex.MatchString(tx.Amount) {
+			errs = append(errs, prefix+".amount must be a positive decimal number with at most 5 fraction digits")
+		}
+		if !currencyRegex.MatchString(tx.Currency) {
+			errs = append(errs, prefix+".currency must be an ISO 4217 currency code")
+		}
+		if len(tx.RemittanceInformation) > 140 {
+			errs = append(errs, prefix+".remittanceInformation must be at most 140 characters")
+		}
+		errs = append(errs, tx.Creditor.validate(prefix+".creditor")...)
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid credit transfer: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (p party) validate(name string) []string {
+	var errs []string
+	if p.Name == "" || len(p.Name) > 140 {
+		errs = append(errs, name+".name is required and must be at most 140 characters")
+	}
+	if !validIBAN(p.IBAN) {
+		errs = append(errs, name+".iban is not a valid IBAN")
+	}
+	if p.BIC != "" && !bicRegex.MatchString(p.BIC) {
+		errs = append(errs, name+".bic is not a valid BIC")
+	}
+	return errs
+}
+
+// validIBAN checks the format and the ISO 7064 mod 97-10 check digits of an IBAN.
+func validIBAN(iban string) bool {
+	if !ibanRegex.MatchString(iban) {
+		return false
+	}
+
+	// Move the first four characters to the end and convert letters to numbers (A=10 ... Z=35)
+	rearranged := iban[4:] + iban[:4]
+	var digits strings.Builder
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// XML structure of a pain.001.001.03 document.
+type pain001Document struct {
+	XMLName xml.Name       `xml:"Document"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Content pain001Content `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001Content struct {
+	GroupHeader groupHeader `xml:"GrpHdr"`
+	PaymentInfo paymentInfo `xml:"PmtInf"`
+}
+
+type groupHeader struct {
+	MessageID        string    `xml:"MsgId"`
+	CreationDateTime string    `xml:"CreDtTm"`
+	NumberOfTxs      int       `xml:"NbOfTxs"`
+	ControlSum       string    `xml:"CtrlSum"`
+	InitiatingParty  partyName `xml:"InitgPty"`
+}
+
+type partyName struct {
+	Name string `xml:"Nm"`
+}
+
+type paymentInfo struct {
+	PaymentInfoID          string             `xml:"PmtInfId"`
+	PaymentMethod          string             `xml:"PmtMtd"`
+	NumberOfTxs            int                `xml:"NbOfTxs"`
+	ControlSum             string             `xml:"CtrlSum"`
+	RequestedExecutionDate string             `xml:"ReqdExctnDt"`
+	Debtor                 partyName          `xml:"Dbtr"`
+	DebtorAccount          account            `xml:"DbtrAcct"`
+	DebtorAgent            agent              `xml:"DbtrAgt"`
+	Transactions           []creditTransferTx `xml:"CdtTrfTxInf"`
+}
+
+type account struct {
+	IBAN string `xml:"Id>IBAN"`
+}
+
+type agent struct {
+	BIC   string `xml:"FinInstnId>BIC,omitempty"`
+	Other string `xml:"FinInstnId>Othr>Id,omitempty"`
+}
+
+type creditTransferTx struct {
+	EndToEndID      string           `xml:"PmtId>EndToEndId"`
+	Amount          instructedAmount `xml:"Amt>InstdAmt"`
+	CreditorAgent   *agent           `xml:"CdtrAgt,omitempty"`
+	Creditor        partyName        `xml:"Cdtr"`
+	CreditorAccount account          `xml:"CdtrAcct"`
+	Remittance      string           `xml:"RmtInf>Ustrd,omitempty"`
+}
+
+type instructedAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+func newAgent(bic string) agent {
+	if bic == "" {
+		// BIC is optional; when missing the schema requires an explicit "not provided" marker
+		return agent{Other: "NOTPROVIDED"}
+	}
+	return agent{BIC: bic}
+}
+
+// toPain001 converts a validated credit transfer into a pain.001.001.03 XML document.
+func (c *creditTransfer) toPain001(now time.Time) ([]byte, error) {
+	created := now
+	if c.CreationDateTime != nil {
+		created = *c.CreationDateTime
+	}
+
+	sum := new(big.Rat)
+	txs := make([]creditTransferTx, len(c.Transactions))
+	for i, tx := range c.Transactions {
+		amount, ok := new(big.Rat).SetString(tx.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount: %s", tx.Amount)
+		}
+		sum.Add(sum, amount)
+
+		txs[i] = creditTransferTx{
+			EndToEndID: tx.EndToEndID,
+			Amount: instructedAmount{
+				Currency: tx.Currency,
+				Value:    tx.Amount,
+			},
+			Creditor:        partyName{Name: tx.Creditor.Name},
+			CreditorAccount: account{IBAN: tx.Creditor.IBAN},
+			Remittance:      tx.RemittanceInformation,
+		}
+		if tx.Creditor.BIC != "" {
+			creditorAgent := newAgent(tx.Creditor.BIC)
+			txs[i].CreditorAgent = &creditorAgent
+		}
+	}
+	controlSum := sum.FloatString(5)
+	controlSum = strings.TrimRight(strings.TrimRight(controlSum, "0"), ".")
+
+	doc := pain001Document{
+		Xmlns: pain001Namespace,
+		Content: pain001Content{
+			GroupHeader: groupHeader{
+				MessageID:        c.MessageID,
+				CreationDateTime: created.UTC().Format(dateTimeFormat),
+				NumberOfTxs:      len(txs),
+				ControlSum:       controlSum,
+				InitiatingParty:  partyName{Name: c.InitiatingParty},
+			},
+			PaymentInfo: paymentInfo{
+				PaymentInfoID:          c.PaymentInformationID,
+				PaymentMethod:          "TRF",
+				NumberOfTxs:            len(txs),
+				ControlSum:             controlSum,
+				RequestedExecutionDate: c.RequestedExecutionDate,
+				Debtor:                 partyName{Name: c.Debtor.Name},
+				DebtorAccount:          account{IBAN: c.Debtor.IBAN},
+				DebtorAgent:            newAgent(c.Debtor.BIC),
+				Transactions:           txs,
+			},
+		},
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validCreditTransfer() creditTransfer {
+	return creditTransfer{
+		MessageID:              "MSG-0001",
+		InitiatingParty:        "Contoso Ltd",
+		PaymentInformationID:   "PMT-0001",
+		RequestedExecutionDate: "2023-02-01",
+		Debtor: party{
+			Name: "Contoso Ltd",
+			IBAN: "DE89370400440532013000",
+			BIC:  "COBADEFFXXX",
+		},
+		Transactions: []transaction{
+			{
+				EndToEndID: "E2E-1",
+				Amount:     "100.25",
+				Currency:   "EUR",
+				Creditor: party{
+					Name: "Fabrikam GmbH",
+					IBAN: "GB82WEST12345698765432",
+				},
+				RemittanceInformation: "Invoice 42",
+			},
+			{
+				EndToEndID: "E2E-2",
+				Amount:     "0.75",
+				Currency:   "EUR",
+				Creditor: party{
+					Name: "Northwind",
+					IBAN: "FR1420041010050500013M02606",
+					BIC:  "PSSTFRPPPAR",
+				},
+			},
+		},
+	}
+}
+
+func TestValidIBAN(t *testing.T) {
+	assert.True(t, validIBAN("DE89370400440532013000"))
+	assert.True(t, validIBAN("GB82WEST12345698765432"))
+	assert.False(t, validIBAN("DE89370400440532013001"))
+	assert.False(t, validIBAN("de89370400440532013000"))
+	assert.False(t, validIBAN(""))
+}
+
+func TestCreditTransferValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		ct := validCreditTransfer()
+		assert.NoError(t, ct.validate())
+	})
+
+	t.Run("invalid fields are all reported", func(t *testing.T) {
+		ct := validCreditTransfer()
+		ct.MessageID = ""
+		ct.RequestedExecutionDate = "01/02/2023"
+		ct.Transactions[0].Currency = "eur"
+		ct.Transactions[1].Amount = "-1"
+		ct.Transactions[1].Creditor.BIC = "INVALID"
+
+		err := ct.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "messageId")
+		assert.Contains(t, err.Error(), "requestedExecutionDate")
+		assert.Contains(t, err.Error(), "transactions[0].currency")
+		assert.Contains(t, err.Error(), "transactions[1].amount")
+		assert.Contains(t, err.Error(), "transactions[1].creditor.bic")
+	})
+
+	t.Run("no transactions", func(t *testing.T) {
+		ct := validCreditTransfer()
+		ct.Transactions = nil
+		assert.ErrorContains(t, ct.validate(), "at least one transaction")
+	})
+}
+
+func TestToPain001(t *testing.T) {
+	ct := validCreditTransfer()
+	now := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	out, err := ct.toPain001(now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), xml.Header))
+
+	var doc pain001Document
+	require.NoError(t, xml.Unmarshal(out, &doc))
+	assert.Equal(t, "MSG-0001", doc.Content.GroupHeader.MessageID)
+	assert.Equal(t, "2023-01-15T10:30:00", doc.Content.GroupHeader.CreationDateTime)
+	assert.Equal(t, 2, doc.Content.GroupHeader.NumberOfTxs)
+	assert.Equal(t, "101", doc.Content.GroupHeader.ControlSum)
+	assert.Equal(t, "TRF", doc.Content.PaymentInfo.PaymentMethod)
+	assert.Equal(t, "COBADEFFXXX", doc.Content.PaymentInfo.DebtorAgent.BIC)
+	require.Len(t, doc.Content.PaymentInfo.Transactions, 2)
+	assert.Nil(t, doc.Content.PaymentInfo.Transactions[0].CreditorAgent)
+	assert.Equal(t, "PSSTFRPPPAR", doc.Content.PaymentInfo.Transactions[1].CreditorAgent.BIC)
+	assert.Equal(t, "EUR", doc.Content.PaymentInfo.Transactions[0].Amount.Currency)
+	assert.Equal(t, "100.25", doc.Content.PaymentInfo.Transactions[0].Amount.Value)
+	assert.Contains(t, string(out), `xmlns="`+pain001Namespace+`"`)
+}
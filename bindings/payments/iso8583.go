@@ -0,0 +1,266 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type fieldEncoding int
+
+const (
+	// Fixed-length field; numeric values are left-padded with zeros, others right-padded with spaces.
+	encodingFixed fieldEncoding = iota
+	// Variable-length field with a 2-digit length prefix.
+	encodingLLVar
+	// Variable-length field with a 3-digit length prefix.
+	encodingLLLVar
+)
+
+// fieldSpec describes a data element of an ISO 8583:1987 message using ASCII encoding.
+// Binary fields are exchanged as hex strings in the JSON payload.
+type fieldSpec struct {
+	encoding fieldEncoding
+	length   int
+	numeric  bool
+	binary   bool
+}
+
+var mtiRegex = regexp.MustCompile(`^[0-9]{4}$`)
+
+// Data elements supported by the binding, keyed by field number.
+var iso8583Fields = map[int]fieldSpec{ //nolint:gochecknoglobals
+	2:   {encoding: encodingLLVar, length: 19, numeric: true},  // Primary account number
+	3:   {encoding: encodingFixed, length: 6, numeric: true},   // Processing code
+	4:   {encoding: encodingFixed, length: 12, numeric: true},  // Transaction amount
+	7:   {encoding: encodingFixed, length: 10, numeric: true},  // Transmission date & time
+	11:  {encoding: encodingFixed, length: 6, numeric: true},   // System trace audit number
+	12:  {encoding: encodingFixed, length: 6, numeric: true},   // Local transaction time
+	13:  {encoding: encodingFixed, length: 4, numeric: true},   // Local transaction date
+	14:  {encoding: encodingFixed, length: 4, numeric: true},   // Expiration date
+	18:  {encoding: encodingFixed, length: 4, numeric: true},   // Merchant type
+	22:  {encoding: encodingFixed, length: 3, numeric: true},   // POS entry mode
+	25:  {encoding: encodingFixed, length: 2, numeric: true},   // POS condition code
+	32:  {encoding: encodingLLVar, length: 11, numeric: true},  // Acquiring institution ID
+	35:  {encoding: encodingLLVar, length: 37},                 // Track 2 data
+	37:  {encoding: encodingFixed, length: 12},                 // Retrieval reference number
+	38:  {encoding: encodingFixed, length: 6},                  // Authorization ID response
+	39:  {encoding: encodingFixed, length: 2},                  // Response code
+	41:  {encoding: encodingFixed, length: 8},                  // Card acceptor terminal ID
+	42:  {encoding: encodingFixed, length: 15},                 // Card acceptor ID code
+	43:  {encoding: encodingFixed, length: 40},                 // Card acceptor name/location
+	49:  {encoding: encodingFixed, length: 3, numeric: true},   // Transaction currency code
+	52:  {encoding: encodingFixed, length: 8, binary: true},    // PIN data
+	54:  {encoding: encodingLLLVar, length: 120},               // Additional amounts
+	55:  {encoding: encodingLLLVar, length: 999, binary: true}, // ICC data
+	70:  {encoding: encodingFixed, length: 3, numeric: true},   // Network management code
+	90:  {encoding: encodingFixed, length: 42, numeric: true},  // Original data elements
+	102: {encoding: encodingLLVar, length: 28},                 // Account identification 1
+	103: {encoding: encodingLLVar, length: 28},                 // Account identification 2
+	123: {encoding: encodingLLLVar, length: 999},               // Reserved for private use
+	128: {encoding: encodingFixed, length: 8, binary: true},    // Message authentication code
+}
+
+// iso8583Message is the JSON representation of an ISO 8583 message.
+// Fields are keyed by their data element number.
+type iso8583Message struct {
+	MTI    string            `json:"mti"`
+	Fields map[string]string `json:"fields"`
+}
+
+// pack encodes the message as an ASCII ISO 8583 frame, without the length header.
+func (m *iso8583Message) pack() ([]byte, error) {
+	if !mtiRegex.MatchString(m.MTI) {
+		return nil, fmt.Errorf("invalid mti '%s': must be 4 digits", m.MTI)
+	}
+
+	numbers := make([]int, 0, len(m.Fields))
+	values := make(map[int]string, len(m.Fields))
+	for k, v := range m.Fields {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field number '%s'", k)
+		}
+		if _, ok := iso8583Fields[n]; !ok {
+			return nil, fmt.Errorf("unsupported field %d", n)
+		}
+		numbers = append(numbers, n)
+		values[n] = v
+	}
+	sort.Ints(numbers)
+
+	bitmap := make([]byte, 8, 16)
+	for _, n := range numbers {
+		if n > 64 && len(bitmap) == 8 {
+			bitmap = bitmap[:16]
+			// Bit 1 signals the presence of the secondary bitmap
+			bitmap[0] |= 0x80
+		}
+		bitmap[(n-1)/8] |= 0x80 >> ((n - 1) % 8)
+	}
+
+	var b strings.Builder
+	b.WriteString(m.MTI)
+	b.WriteString(strings.ToUpper(hex.EncodeToString(bitmap)))
+	for _, n := range numbers {
+		encoded, err := iso8583Fields[n].encode(values[n])
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", n, err)
+		}
+		b.WriteString(encoded)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// unpackISO8583 decodes an ASCII ISO 8583 frame, without the length header.
+func unpackISO8583(data []byte) (*iso8583Message, error) {
+	if len(data) < 20 {
+		return nil, errors.New("message too short")
+	}
+	msg := &iso8583Message{
+		MTI:    string(data[:4]),
+		Fields: map[string]string{},
+	}
+	if !mtiRegex.MatchString(msg.MTI) {
+		return nil, fmt.Errorf("invalid mti '%s'", msg.MTI)
+	}
+
+	bitmap, err := hex.DecodeString(string(data[4:20]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid primary bitmap: %w", err)
+	}
+	pos := 20
+	if bitmap[0]&0x80 != 0 {
+		if len(data) < 36 {
+			return nil, errors.New("message too short for secondary bitmap")
+		}
+		secondary, err := hex.DecodeString(string(data[20:36]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid secondary bitmap: %w", err)
+		}
+		bitmap = append(bitmap, secondary...)
+		pos = 36
+	}
+
+	for n := 2; n <= len(bitmap)*8; n++ {
+		if bitmap[(n-1)/8]&(0x80>>((n-1)%8)) == 0 {
+			continue
+		}
+		spec, ok := iso8583Fields[n]
+		if !ok {
+			return nil, fmt.Errorf("unsupported field %d in message", n)
+		}
+		value, read, err := spec.decode(data[pos:])
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", n, err)
+		}
+		msg.Fields[strconv.Itoa(n)] = value
+		pos += read
+	}
+
+	if pos != len(data) {
+		return nil, fmt.Errorf("unexpected %d trailing bytes in message", len(data)-pos)
+	}
+
+	return msg, nil
+}
+
+func (f fieldSpec) encode(value string) (string, error) {
+	if f.binary {
+		raw, err := hex.DecodeString(value)
+		if err != nil {
+			return "", errors.New("binary fields must be hex-encoded")
+		}
+		if f.encoding == encodingFixed && len(raw) != f.length {
+			return "", fmt.Errorf("value must be exactly %d bytes", f.length)
+		}
+		if len(raw) > f.length {
+			return "", fmt.Errorf("value exceeds maximum length of %d bytes", f.length)
+		}
+		// Binary data is transmitted as uppercase hex in ASCII messages
+		value = strings.ToUpper(value)
+		return f.prefix(len(raw)) + value, nil
+	}
+
+	if f.numeric {
+		for _, r := range value {
+			if r < '0' || r > '9' {
+				return "", errors.New("value must be numeric")
+			}
+		}
+	}
+	if len(value) > f.length {
+		return "", fmt.Errorf("value exceeds maximum length of %d", f.length)
+	}
+
+	if f.encoding == encodingFixed {
+		if f.numeric {
+			return strings.Repeat("0", f.length-len(value)) + value, nil
+		}
+		return value + strings.Repeat(" ", f.length-len(value)), nil
+	}
+	return f.prefix(len(value)) + value, nil
+}
+
+func (f fieldSpec) prefix(length int) string {
+	switch f.encoding {
+	case encodingLLVar:
+		return fmt.Sprintf("%02d", length)
+	case encodingLLLVar:
+		return fmt.Sprintf("%03d", length)
+	default:
+		return ""
+	}
+}
+
+func (f fieldSpec) decode(data []byte) (value string, read int, err error) {
+	length := f.length
+	switch f.encoding {
+	case encodingLLVar, encodingLLLVar:
+		digits := 2
+		if f.encoding == encodingLLLVar {
+			digits = 3
+		}
+		if len(data) < digits {
+			return "", 0, errors.New("missing length prefix")
+		}
+		length, err = strconv.Atoi(string(data[:digits]))
+		if err != nil || length > f.length {
+			return "", 0, fmt.Errorf("invalid length prefix '%s'", data[:digits])
+		}
+		read = digits
+	}
+
+	size := length
+	if f.binary {
+		size = length * 2
+	}
+	if len(data) < read+size {
+		return "", 0, errors.New("message truncated")
+	}
+	value = string(data[read : read+size])
+	read += size
+
+	if f.encoding == encodingFixed && !f.numeric && !f.binary {
+		value = strings.TrimRight(value, " ")
+	}
+	return value, read, nil
+}
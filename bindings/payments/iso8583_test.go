@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestISO8583PackUnpack(t *testing.T) {
+	t.Run("primary bitmap only", func(t *testing.T) {
+		msg := &iso8583Message{
+			MTI: "0200",
+			Fields: map[string]string{
+				"2":  "4111111111111111",
+				"3":  "0",
+				"4":  "1250",
+				"41": "TERM01",
+				"49": "978",
+			},
+		}
+
+		packed, err := msg.pack()
+		require.NoError(t, err)
+		assert.Equal(t, "0200"+"7000000000808000"+"164111111111111111"+"000000"+"000000001250"+"TERM01  "+"978", string(packed))
+
+		unpacked, err := unpackISO8583(packed)
+		require.NoError(t, err)
+		assert.Equal(t, "0200", unpacked.MTI)
+		assert.Equal(t, map[string]string{
+			"2":  "4111111111111111",
+			"3":  "000000",
+			"4":  "000000001250",
+			"41": "TERM01",
+			"49": "978",
+		}, unpacked.Fields)
+	})
+
+	t.Run("secondary bitmap and binary fields", func(t *testing.T) {
+		msg := &iso8583Message{
+			MTI: "0800",
+			Fields: map[string]string{
+				"7":   "0115103000",
+				"11":  "123456",
+				"55":  "9f2608abcd",
+				"70":  "301",
+				"128": "0102030405060708",
+			},
+		}
+
+		packed, err := msg.pack()
+		require.NoError(t, err)
+		assert.Equal(t, "0800", string(packed[:4]))
+		// Bit 1 is set to signal the secondary bitmap
+		assert.Equal(t, "82200000000002000400000000000001", string(packed[4:36]))
+
+		unpacked, err := unpackISO8583(packed)
+		require.NoError(t, err)
+		assert.Equal(t, "9F2608ABCD", unpacked.Fields["55"])
+		assert.Equal(t, "0102030405060708", unpacked.Fields["128"])
+		assert.Equal(t, "301", unpacked.Fields["70"])
+	})
+}
+
+func TestISO8583PackErrors(t *testing.T) {
+	tests := map[string]iso8583Message{
+		"invalid mti":       {MTI: "20", Fields: map[string]string{}},
+		"unsupported field": {MTI: "0200", Fields: map[string]string{"5": "1"}},
+		"invalid number":    {MTI: "0200", Fields: map[string]string{"abc": "1"}},
+		"non numeric value": {MTI: "0200", Fields: map[string]string{"3": "12a"}},
+		"value too long":    {MTI: "0200", Fields: map[string]string{"2": "41111111111111111111"}},
+		"binary not hex":    {MTI: "0200", Fields: map[string]string{"52": "zz"}},
+		"binary wrong size": {MTI: "0200", Fields: map[string]string{"52": "0102"}},
+	}
+	for name, msg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := msg.pack()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestISO8583UnpackErrors(t *testing.T) {
+	_, err := unpackISO8583([]byte("0200"))
+	assert.Error(t, err)
+
+	_, err = unpackISO8583([]byte("0200400000000000000012"))
+	assert.ErrorContains(t, err, "truncated")
+
+	_, err = unpackISO8583([]byte("0200200000000000000012345699"))
+	assert.ErrorContains(t, err, "trailing")
+}
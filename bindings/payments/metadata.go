@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	formatISO20022 = "iso20022"
+	formatISO8583  = "iso8583"
+
+	defaultTimeout          = 30 * time.Second
+	defaultLengthHeaderSize = 2
+)
+
+type paymentsMetadata struct {
+	// Format is the message standard used to encode payloads: "iso20022" or "iso8583".
+	Format string `mapstructure:"format"`
+	// Endpoint is where formatted messages are submitted.
+	// For ISO 20022 this is an HTTP(S) URL, for ISO 8583 a TCP "host:port" address.
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout for submitting a message and waiting for the response.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// AuthHeader is an optional value sent in the Authorization header of ISO 20022 requests.
+	AuthHeader string `mapstructure:"authHeader"`
+	// LengthHeaderSize is the size in bytes of the big-endian length prefix of ISO 8583 frames (0, 2 or 4).
+	LengthHeaderSize *int `mapstructure:"lengthHeaderSize"`
+}
+
+func parseMetadata(meta map[string]string) (paymentsMetadata, error) {
+	m := paymentsMetadata{
+		Timeout: defaultTimeout,
+	}
+	if err := metadata.DecodeMetadata(meta, &m); err != nil {
+		return m, err
+	}
+
+	switch m.Format {
+	case formatISO20022, formatISO8583:
+	case "":
+		return m, errors.New("missing required metadata property: format")
+	default:
+		return m, fmt.Errorf("invalid format '%s': must be one of '%s' or '%s'", m.Format, formatISO20022, formatISO8583)
+	}
+
+	if m.LengthHeaderSize == nil {
+		size := defaultLengthHeaderSize
+		m.LengthHeaderSize = &size
+	}
+	switch *m.LengthHeaderSize {
+	case 0, 2, 4:
+	default:
+		return m, fmt.Errorf("invalid lengthHeaderSize %d: must be 0, 2 or 4", *m.LengthHeaderSize)
+	}
+
+	if m.Timeout <= 0 {
+		return m, errors.New("timeout must be greater than zero")
+	}
+
+	return m, nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// FormatOperation returns the formatted message without submitting it.
+	FormatOperation bindings.OperationKind = "format"
+
+	contentTypeXML    = "application/xml"
+	contentTypeJSON   = "application/json"
+	contentTypeBinary = "application/octet-stream"
+
+	maxResponseSize = 4 << 20
+)
+
+// Payments is an output binding that converts JSON payloads to ISO 20022 XML documents
+// or ISO 8583 frames and submits them to a configured endpoint.
+type Payments struct {
+	metadata   paymentsMetadata
+	httpClient *http.Client
+	dialer     *net.Dialer
+	logger     logger.Logger
+}
+
+// NewPayments returns a new payments output binding.
+func NewPayments(logger logger.Logger) bindings.OutputBinding {
+	return &Payments{logger: logger}
+}
+
+// Init performs metadata parsing.
+func (p *Payments) Init(metadata bindings.Metadata) error {
+	m, err := parseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("payments binding error: %w", err)
+	}
+	p.metadata = m
+
+	p.dialer = &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	p.httpClient = &http.Client{
+		Timeout: m.Timeout,
+		Transport: &http.Transport{
+			DialContext:         p.dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+
+	return nil
+}
+
+// Operations returns list of operations supported by the payments binding.
+func (p *Payments) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation, FormatOperation}
+}
+
+// Invoke formats the payload and, for the create operation, submits it to the endpoint.
+func (p *Payments) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	switch req.Operation {
+	case bindings.CreateOperation, FormatOperation:
+	default:
+		return nil, fmt.Errorf("payments binding error: unsupported operation %s", req.Operation)
+	}
+
+	var (
+		msg []byte
+		err error
+	)
+	switch p.metadata.Format {
+	case formatISO20022:
+		msg, err = formatCreditTransfer(req.Data)
+	case formatISO8583:
+		msg, err = formatISO8583Message(req.Data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("payments binding error: %w", err)
+	}
+
+	if req.Operation == FormatOperation {
+		return p.formatResponse(msg), nil
+	}
+
+	if p.metadata.Endpoint == "" {
+		return nil, errors.New("payments binding error: no endpoint configured")
+	}
+
+	var res *bindings.InvokeResponse
+	switch p.metadata.Format {
+	case formatISO20022:
+		res, err = p.submitHTTP(ctx, msg)
+	case formatISO8583:
+		res, err = p.submitTCP(ctx, msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("payments binding error: %w", err)
+	}
+
+	return res, nil
+}
+
+func (p *Payments) formatResponse(msg []byte) *bindings.InvokeResponse {
+	contentType := contentTypeXML
+	if p.metadata.Format == formatISO8583 {
+		// ISO 8583 messages have binary bitmaps
+		contentType = contentTypeBinary
+	}
+	return &bindings.InvokeResponse{
+		Data:        msg,
+		ContentType: &contentType,
+	}
+}
+
+func formatCreditTransfer(data []byte) ([]byte, error) {
+	var ct creditTransfer
+	if err := json.Unmarshal(data, &ct); err != nil {
+		return nil, fmt.Errorf("failed to parse credit transfer: %w", err)
+	}
+	if err := ct.validate(); err != nil {
+		return nil, err
+	}
+	return ct.toPain001(time.Now())
+}
+
+func formatISO8583Message(data []byte) ([]byte, error) {
+	var msg iso8583Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse ISO 8583 message: %w", err)
+	}
+	return msg.pack()
+}
+
+func (p *Payments) submitHTTP(ctx context.Context, msg []byte) (*bindings.InvokeResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.Endpoint, bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", contentTypeXML)
+	if p.metadata.AuthHeader != "" {
+		httpReq.Header.Set("Authorization", p.metadata.AuthHeader)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit message: %w", err)
+	}
+	defer func() {
+		// Drain before closing
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	res := &bindings.InvokeResponse{
+		Data: body,
+		Metadata: map[string]string{
+			"statusCode": strconv.Itoa(resp.StatusCode),
+		},
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		res.ContentType = &ct
+	}
+	return res, nil
+}
+
+func (p *Payments) submitTCP(ctx context.Context, msg []byte) (*bindings.InvokeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.metadata.Timeout)
+	defer cancel()
+
+	conn, err := p.dialer.DialContext(ctx, "tcp", p.metadata.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", p.metadata.Endpoint, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err = writeFrame(conn, msg, *p.metadata.LengthHeaderSize); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	var reply []byte
+	if *p.metadata.LengthHeaderSize == 0 {
+		// Without a length header the server signals the end of the reply by closing the connection
+		reply, err = io.ReadAll(io.LimitReader(conn, maxResponseSize))
+	} else {
+		reply, err = readFrame(conn, *p.metadata.LengthHeaderSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reply: %w", err)
+	}
+
+	decoded, err := unpackISO8583(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reply: %w", err)
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := contentTypeJSON
+	return &bindings.InvokeResponse{
+		Data:        data,
+		ContentType: &contentType,
+		Metadata: map[string]string{
+			"mti": decoded.MTI,
+		},
+	}, nil
+}
+
+func writeFrame(w io.Writer, msg []byte, headerSize int) error {
+	if headerSize > 0 && uint64(len(msg)) > 1<<(8*headerSize)-1 {
+		return fmt.Errorf("message of %d bytes too large for a %d-byte length header", len(msg), headerSize)
+	}
+	header := make([]byte, headerSize)
+	switch headerSize {
+	case 2:
+		binary.BigEndian.PutUint16(header, uint16(len(msg)))
+	case 4:
+		binary.BigEndian.PutUint32(header, uint32(len(msg)))
+	}
+	_, err := w.Write(append(header, msg...))
+	return err
+}
+
+func readFrame(r io.Reader, headerSize int) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	var size int
+	if headerSize == 2 {
+		size = int(binary.BigEndian.Uint16(header))
+	} else {
+		size = int(binary.BigEndian.Uint32(header))
+	}
+	if size > maxResponseSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds maximum size", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
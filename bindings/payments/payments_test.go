@@ -0,0 +1,215 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"format": "iso8583"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+		assert.Equal(t, 2, *m.LengthHeaderSize)
+	})
+
+	t.Run("all properties", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{
+			"format":           "iso20022",
+			"endpoint":         "https://bank.example.com/payments",
+			"timeout":          "5s",
+			"authHeader":       "Bearer abc",
+			"lengthHeaderSize": "4",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://bank.example.com/payments", m.Endpoint)
+		assert.Equal(t, 5*time.Second, m.Timeout)
+		assert.Equal(t, "Bearer abc", m.AuthHeader)
+		assert.Equal(t, 4, *m.LengthHeaderSize)
+	})
+
+	t.Run("missing format", func(t *testing.T) {
+		_, err := parseMetadata(map[string]string{})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := parseMetadata(map[string]string{"format": "swift"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid length header", func(t *testing.T) {
+		_, err := parseMetadata(map[string]string{"format": "iso8583", "lengthHeaderSize": "3"})
+		assert.Error(t, err)
+	})
+}
+
+func TestInvokeISO20022(t *testing.T) {
+	payload, err := json.Marshal(validCreditTransfer())
+	require.NoError(t, err)
+
+	t.Run("format only", func(t *testing.T) {
+		p := NewPayments(logger.NewLogger("test"))
+		require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"format": "iso20022"}}}))
+
+		res, err := p.Invoke(context.Background(), &bindings.InvokeRequest{Operation: FormatOperation, Data: payload})
+		require.NoError(t, err)
+		assert.Equal(t, contentTypeXML, *res.ContentType)
+		assert.Contains(t, string(res.Data), "<CstmrCdtTrfInitn>")
+	})
+
+	t.Run("submit", func(t *testing.T) {
+		var received []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, contentTypeXML, r.Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+			received, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("ACCP"))
+		}))
+		defer server.Close()
+
+		p := NewPayments(logger.NewLogger("test"))
+		require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"format":     "iso20022",
+			"endpoint":   server.URL,
+			"authHeader": "Bearer abc",
+		}}}))
+
+		res, err := p.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: payload})
+		require.NoError(t, err)
+		assert.Equal(t, "ACCP", string(res.Data))
+		assert.Equal(t, "202", res.Metadata["statusCode"])
+		assert.Contains(t, string(received), "<MsgId>MSG-0001</MsgId>")
+	})
+
+	t.Run("rejected by endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		p := NewPayments(logger.NewLogger("test"))
+		require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"format": "iso20022", "endpoint": server.URL}}}))
+
+		_, err := p.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: payload})
+		assert.ErrorContains(t, err, "status 400")
+	})
+
+	t.Run("invalid payload is not submitted", func(t *testing.T) {
+		p := NewPayments(logger.NewLogger("test"))
+		require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"format": "iso20022", "endpoint": "http://localhost:1"}}}))
+
+		_, err := p.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: []byte(`{"messageId":"x"}`)})
+		assert.ErrorContains(t, err, "invalid credit transfer")
+	})
+}
+
+func TestInvokeISO8583(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req, err := readFrame(conn, 2)
+		if err != nil {
+			return
+		}
+		// Reply with an authorization response echoing the STAN
+		in, err := unpackISO8583(req)
+		if err != nil {
+			return
+		}
+		reply := &iso8583Message{
+			MTI:    "0210",
+			Fields: map[string]string{"11": in.Fields["11"], "39": "00"},
+		}
+		out, _ := reply.pack()
+		_ = writeFrame(conn, out, 2)
+	}()
+
+	p := NewPayments(logger.NewLogger("test"))
+	require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"format":   "iso8583",
+		"endpoint": listener.Addr().String(),
+		"timeout":  "5s",
+	}}}))
+
+	res, err := p.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte(`{"mti":"0200","fields":{"3":"000000","4":"1000","11":"654321"}}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0210", res.Metadata["mti"])
+
+	var reply iso8583Message
+	require.NoError(t, json.Unmarshal(res.Data, &reply))
+	assert.Equal(t, "654321", reply.Fields["11"])
+	assert.Equal(t, "00", reply.Fields["39"])
+}
+
+func TestInvokeISO8583FormatOnly(t *testing.T) {
+	p := NewPayments(logger.NewLogger("test"))
+	require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"format": "iso8583"}}}))
+
+	res, err := p.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: FormatOperation,
+		Data:      []byte(`{"mti":"0200","fields":{"3":"000000","4":"1000","11":"654321"}}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeBinary, *res.ContentType)
+}
+
+func TestWriteFrame(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeFrame(&buf, []byte("abc"), 2))
+	assert.Equal(t, []byte{0, 3, 'a', 'b', 'c'}, buf.Bytes())
+
+	buf.Reset()
+	require.NoError(t, writeFrame(&buf, []byte("abc"), 4))
+	assert.Equal(t, []byte{0, 0, 0, 3, 'a', 'b', 'c'}, buf.Bytes())
+
+	buf.Reset()
+	assert.Error(t, writeFrame(&buf, make([]byte, 0x10000), 2))
+	assert.Zero(t, buf.Len())
+}
+
+func TestInvokeUnsupportedOperation(t *testing.T) {
+	p := NewPayments(logger.NewLogger("test"))
+	require.NoError(t, p.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"format": "iso8583"}}}))
+
+	_, err := p.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.DeleteOperation})
+	assert.Error(t, err)
+}
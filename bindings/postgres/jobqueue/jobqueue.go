@@ -0,0 +1,429 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTable             = "dapr_jobs"
+	defaultQueue             = "default"
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultMaxAttempts       = 5
+	defaultRetryDelay        = time.Second
+	defaultMaxRetryDelay     = 5 * time.Minute
+	defaultPollInterval      = time.Second
+
+	// keys from request's metadata.
+	queueKey = "queue"
+	delayKey = "delay"
+
+	// keys from the metadata of the jobs delivered to the input binding.
+	jobIDKey   = "jobID"
+	attemptKey = "attempt"
+
+	EnqueueOperation bindings.OperationKind = "enqueue"
+)
+
+var tableNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// pgxPoolConn is the subset of pgxpool.Pool used by the binding.
+type pgxPoolConn interface {
+	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(context.Context, string, ...interface{}) pgx.Row
+	Close()
+}
+
+// JobQueue is a work queue stored in a PostgreSQL table: the output binding enqueues jobs, and the input binding
+// delivers them to competing consumers, which claim jobs with SELECT ... FOR UPDATE SKIP LOCKED.
+// A claimed job is invisible to the other consumers for the visibility timeout, extended while its handler runs,
+// so jobs of consumers which crashed are delivered again: processing is at-least-once.
+// Jobs are deleted once handled; failed jobs are retried with an exponential backoff until maxAttempts,
+// after which they are kept in the table with their failure time and last error.
+type JobQueue struct {
+	metadata jobQueueMetadata
+	db       pgxPoolConn
+	logger   logger.Logger
+
+	closeCh chan struct{}
+	closed  sync.Once
+	wg      sync.WaitGroup
+}
+
+type jobQueueMetadata struct {
+	URL   string `mapstructure:"url"`
+	Table string `mapstructure:"table"`
+	// Queue is the queue consumed by the input binding, and the default queue of the enqueued jobs.
+	Queue string `mapstructure:"queue"`
+
+	VisibilityTimeout time.Duration `mapstructure:"visibilityTimeout"`
+	MaxAttempts       int           `mapstructure:"maxAttempts"`
+	RetryDelay        time.Duration `mapstructure:"retryDelay"`
+	MaxRetryDelay     time.Duration `mapstructure:"maxRetryDelay"`
+	PollInterval      time.Duration `mapstructure:"pollInterval"`
+	// Concurrency is the number of jobs handled at the same time by the input binding.
+	Concurrency int `mapstructure:"concurrency"`
+}
+
+// job is a job claimed by a consumer.
+type job struct {
+	id       int64
+	payload  []byte
+	metadata map[string]string
+	attempts int
+}
+
+// NewJobQueue returns a new PostgreSQL job queue binding.
+func NewJobQueue(logger logger.Logger) bindings.InputOutputBinding {
+	return &JobQueue{logger: logger, closeCh: make(chan struct{})}
+}
+
+// Init connects to the database and creates the table of the jobs.
+func (q *JobQueue) Init(meta bindings.Metadata) error {
+	err := q.parseMetadata(meta.Properties)
+	if err != nil {
+		return err
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(q.metadata.URL)
+	if err != nil {
+		return fmt.Errorf("postgres job queue binding error: invalid url: %w", err)
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return fmt.Errorf("postgres job queue binding error: failed to connect: %w", err)
+	}
+	q.db = pool
+
+	return q.ensureTable(context.Background())
+}
+
+func (q *JobQueue) parseMetadata(properties map[string]string) error {
+	q.metadata = jobQueueMetadata{
+		Table:             defaultTable,
+		Queue:             defaultQueue,
+		VisibilityTimeout: defaultVisibilityTimeout,
+		MaxAttempts:       defaultMaxAttempts,
+		RetryDelay:        defaultRetryDelay,
+		MaxRetryDelay:     defaultMaxRetryDelay,
+		PollInterval:      defaultPollInterval,
+		Concurrency:       1,
+	}
+	err := metadata.DecodeMetadata(properties, &q.metadata)
+	if err != nil {
+		return fmt.Errorf("postgres job queue binding error: %w", err)
+	}
+	switch {
+	case q.metadata.URL == "":
+		return errors.New("postgres job queue binding error: url is required")
+	case !tableNameRegex.MatchString(q.metadata.Table):
+		return fmt.Errorf("postgres job queue binding error: invalid table name %s", q.metadata.Table)
+	case q.metadata.VisibilityTimeout < time.Second:
+		return errors.New("postgres job queue binding error: visibilityTimeout must be at least 1s")
+	case q.metadata.MaxAttempts < 1:
+		return errors.New("postgres job queue binding error: maxAttempts must be at least 1")
+	case q.metadata.Concurrency < 1:
+		return errors.New("postgres job queue binding error: concurrency must be at least 1")
+	case q.metadata.PollInterval <= 0:
+		return errors.New("postgres job queue binding error: pollInterval must be positive")
+	}
+	return nil
+}
+
+func (q *JobQueue) ensureTable(ctx context.Context) error {
+	t := q.metadata.Table
+	_, err := q.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+t+` (
+	id BIGSERIAL PRIMARY KEY,
+	queue TEXT NOT NULL,
+	payload BYTEA NOT NULL,
+	metadata JSONB,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	visible_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	failed_at TIMESTAMPTZ,
+	last_error TEXT
+)`)
+	if err != nil {
+		return fmt.Errorf("postgres job queue binding error: failed to create the table %s: %w", t, err)
+	}
+	_, err = q.db.Exec(ctx, `CREATE INDEX IF NOT EXISTS `+indexName(t)+` ON `+t+` (queue, visible_at) WHERE failed_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("postgres job queue binding error: failed to create the index of %s: %w", t, err)
+	}
+	return nil
+}
+
+// indexName returns the name of the index of the pending jobs of the table.
+func indexName(table string) string {
+	for i := len(table) - 1; i >= 0; i-- {
+		if table[i] == '.' {
+			return table[i+1:] + "_pending_idx"
+		}
+	}
+	return table + "_pending_idx"
+}
+
+// Operations returns the operations supported by the job queue binding.
+func (q *JobQueue) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{EnqueueOperation}
+}
+
+// Invoke enqueues the data of the request as a job.
+func (q *JobQueue) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != EnqueueOperation {
+		return nil, fmt.Errorf("postgres job queue binding error: unsupported operation %s", req.Operation)
+	}
+
+	queue := q.metadata.Queue
+	jobMetadata := map[string]string{}
+	var delay time.Duration
+	for k, v := range req.Metadata {
+		switch k {
+		case queueKey:
+			if v != "" {
+				queue = v
+			}
+		case delayKey:
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("postgres job queue binding error: invalid %s %q", delayKey, v)
+			}
+			delay = d
+		default:
+			jobMetadata[k] = v
+		}
+	}
+	metadataJSON, err := json.Marshal(jobMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("postgres job queue binding error: %w", err)
+	}
+	payload := req.Data
+	if payload == nil {
+		payload = []byte{}
+	}
+
+	var id int64
+	err = q.db.QueryRow(ctx,
+		`INSERT INTO `+q.metadata.Table+` (queue, payload, metadata, visible_at)
+VALUES ($1, $2, $3, now() + $4 * interval '1 millisecond') RETURNING id`,
+		queue, payload, string(metadataJSON), delay.Milliseconds(),
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("postgres job queue binding error: failed to enqueue the job: %w", err)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("postgres job queue binding error: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			jobIDKey:                           strconv.FormatInt(id, 10),
+		},
+	}, nil
+}
+
+// Read starts the consumers of the jobs of the queue, until ctx is done or the binding is closed.
+func (q *JobQueue) Read(ctx context.Context, handler bindings.Handler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-q.closeCh:
+		}
+		cancel()
+	}()
+
+	q.wg.Add(q.metadata.Concurrency)
+	for i := 0; i < q.metadata.Concurrency; i++ {
+		go func() {
+			defer q.wg.Done()
+			q.consume(ctx, handler)
+		}()
+	}
+	return nil
+}
+
+// consume claims and handles jobs until ctx is done, waiting for the poll interval when the queue is empty.
+func (q *JobQueue) consume(ctx context.Context, handler bindings.Handler) {
+	for ctx.Err() == nil {
+		j, err := q.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			q.logger.Errorf("postgres job queue binding: failed to claim a job from %s: %v", q.metadata.Queue, err)
+		}
+		if j == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(q.metadata.PollInterval):
+			}
+			continue
+		}
+		q.handle(ctx, j, handler)
+	}
+}
+
+// claim makes the next visible job of the queue invisible for the visibility timeout, and returns it; nil if there is none.
+func (q *JobQueue) claim(ctx context.Context) (*job, error) {
+	t := q.metadata.Table
+	var (
+		j            job
+		metadataJSON []byte
+	)
+	err := q.db.QueryRow(ctx,
+		`UPDATE `+t+` SET attempts = attempts + 1, visible_at = now() + $2 * interval '1 millisecond'
+WHERE id = (
+	SELECT id FROM `+t+`
+	WHERE queue = $1 AND failed_at IS NULL AND visible_at <= now()
+	ORDER BY visible_at, id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, payload, metadata, attempts`,
+		q.metadata.Queue, q.metadata.VisibilityTimeout.Milliseconds(),
+	).Scan(&j.id, &j.payload, &metadataJSON, &j.attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(metadataJSON) > 0 {
+		if err = json.Unmarshal(metadataJSON, &j.metadata); err != nil {
+			q.logger.Warnf("postgres job queue binding: invalid metadata of job %d: %v", j.id, err)
+		}
+	}
+	return &j, nil
+}
+
+// handle delivers a claimed job to the handler, extending its visibility timeout until the handler returns.
+func (q *JobQueue) handle(ctx context.Context, j *job, handler bindings.Handler) {
+	extendCtx, stopExtending := context.WithCancel(ctx)
+	extended := make(chan struct{})
+	go func() {
+		defer close(extended)
+		q.extendVisibility(extendCtx, j)
+	}()
+
+	meta := make(map[string]string, len(j.metadata)+3)
+	for k, v := range j.metadata {
+		meta[k] = v
+	}
+	meta[jobIDKey] = strconv.FormatInt(j.id, 10)
+	meta[attemptKey] = strconv.Itoa(j.attempts)
+	meta[queueKey] = q.metadata.Queue
+	_, handlerErr := handler(ctx, &bindings.ReadResponse{Data: j.payload, Metadata: meta})
+	stopExtending()
+	<-extended
+
+	// Jobs are released even if ctx is done, so they don't wait for the visibility timeout.
+	releaseCtx, cancel := context.WithTimeout(context.Background(), q.metadata.VisibilityTimeout)
+	defer cancel()
+	if err := q.release(releaseCtx, j, handlerErr); err != nil {
+		q.logger.Errorf("postgres job queue binding: failed to release job %d: %v", j.id, err)
+	}
+}
+
+// extendVisibility keeps the job invisible to the other consumers until ctx is done.
+func (q *JobQueue) extendVisibility(ctx context.Context, j *job) {
+	ticker := time.NewTicker(q.metadata.VisibilityTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// The attempts count identifies the claim: a job claimed again by another consumer isn't extended.
+			_, err := q.db.Exec(ctx,
+				`UPDATE `+q.metadata.Table+` SET visible_at = now() + $3 * interval '1 millisecond' WHERE id = $1 AND attempts = $2`,
+				j.id, j.attempts, q.metadata.VisibilityTimeout.Milliseconds())
+			if err != nil && ctx.Err() == nil {
+				q.logger.Warnf("postgres job queue binding: failed to extend the visibility timeout of job %d: %v", j.id, err)
+			}
+		}
+	}
+}
+
+// release deletes a handled job, or schedules the retry of a failed job.
+func (q *JobQueue) release(ctx context.Context, j *job, handlerErr error) error {
+	t := q.metadata.Table
+	if handlerErr == nil {
+		_, err := q.db.Exec(ctx, `DELETE FROM `+t+` WHERE id = $1`, j.id)
+		return err
+	}
+
+	if j.attempts >= q.metadata.MaxAttempts {
+		q.logger.Warnf("postgres job queue binding: job %d failed after %d attempts: %v", j.id, j.attempts, handlerErr)
+		_, err := q.db.Exec(ctx, `UPDATE `+t+` SET failed_at = now(), last_error = $2 WHERE id = $1`, j.id, handlerErr.Error())
+		return err
+	}
+	_, err := q.db.Exec(ctx,
+		`UPDATE `+t+` SET visible_at = now() + $2 * interval '1 millisecond', last_error = $3 WHERE id = $1 AND attempts = $4`,
+		j.id, q.retryDelay(j.attempts).Milliseconds(), handlerErr.Error(), j.attempts)
+	return err
+}
+
+// retryDelay returns the delay before the attempt following the given one: retryDelay doubled after every attempt, up to maxRetryDelay.
+func (q *JobQueue) retryDelay(attempt int) time.Duration {
+	d := q.metadata.RetryDelay
+	for i := 1; i < attempt && d < q.metadata.MaxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > q.metadata.MaxRetryDelay {
+		d = q.metadata.MaxRetryDelay
+	}
+	return d
+}
+
+// OperationsMetadata describes the operations of the job queue binding.
+func (q *JobQueue) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: EnqueueOperation,
+			Description: "Enqueues the data as a job of the queue named by the queue metadata, or of the queue of the component. " +
+				"The job is delivered after the delay metadata, a Go duration, if any. The other metadata is delivered with the job. Returns the id of the job.",
+			RequestMetadata:  []string{queueKey, delayKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, jobIDKey},
+		},
+	}
+}
+
+// Close stops the consumers and closes the connections to the database.
+func (q *JobQueue) Close() error {
+	q.closed.Do(func() {
+		close(q.closeCh)
+	})
+	q.wg.Wait()
+	if q.db != nil {
+		q.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+func newMockJobQueue(t *testing.T, properties map[string]string) (*JobQueue, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	db, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	q := NewJobQueue(logger.NewLogger("test")).(*JobQueue)
+	props := map[string]string{"url": "postgres://localhost/db"}
+	for k, v := range properties {
+		props[k] = v
+	}
+	require.NoError(t, q.parseMetadata(props))
+	q.db = db
+	return q, db
+}
+
+func TestParseMetadata(t *testing.T) {
+	q, _ := newMockJobQueue(t, nil)
+	assert.Equal(t, jobQueueMetadata{
+		URL:               "postgres://localhost/db",
+		Table:             defaultTable,
+		Queue:             defaultQueue,
+		VisibilityTimeout: defaultVisibilityTimeout,
+		MaxAttempts:       defaultMaxAttempts,
+		RetryDelay:        defaultRetryDelay,
+		MaxRetryDelay:     defaultMaxRetryDelay,
+		PollInterval:      defaultPollInterval,
+		Concurrency:       1,
+	}, q.metadata)
+
+	for name, props := range map[string]map[string]string{
+		"no url":              {},
+		"invalid table":       {"url": "postgres://", "table": "jobs; DROP TABLE jobs"},
+		"short visibility":    {"url": "postgres://", "visibilityTimeout": "10ms"},
+		"no attempts":         {"url": "postgres://", "maxAttempts": "0"},
+		"invalid concurrency": {"url": "postgres://", "concurrency": "0"},
+	} {
+		assert.Error(t, (&JobQueue{}).parseMetadata(props), name)
+	}
+	assert.Equal(t, "jobs_pending_idx", indexName("app.jobs"))
+}
+
+func TestEnqueue(t *testing.T) {
+	q, db := newMockJobQueue(t, nil)
+	db.ExpectQuery("INSERT INTO dapr_jobs").
+		WithArgs("emails", []byte(`{"to":"ada"}`), `{"traceID":"t1"}`, int64(60000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	res, err := q.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: EnqueueOperation,
+		Data:      []byte(`{"to":"ada"}`),
+		Metadata:  map[string]string{queueKey: "emails", delayKey: "1m", "traceID": "t1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":42}`, string(res.Data))
+	assert.Equal(t, "42", res.Metadata[jobIDKey])
+	assert.NoError(t, db.ExpectationsWereMet())
+
+	_, err = q.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: EnqueueOperation,
+		Metadata:  map[string]string{delayKey: "soon"},
+	})
+	assert.Error(t, err)
+}
+
+func TestHandle(t *testing.T) {
+	claim := func(db pgxmock.PgxPoolIface, attempts int) {
+		db.ExpectQuery("UPDATE dapr_jobs SET attempts = attempts \\+ 1").
+			WithArgs("default", int64(30000)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "payload", "metadata", "attempts"}).
+				AddRow(int64(7), []byte("job"), []byte(`{"traceID":"t1"}`), attempts))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		q, db := newMockJobQueue(t, nil)
+		claim(db, 1)
+		db.ExpectExec("DELETE FROM dapr_jobs WHERE id = \\$1").
+			WithArgs(int64(7)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		j, err := q.claim(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, j)
+		var received *bindings.ReadResponse
+		q.handle(context.Background(), j, func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+			received = r
+			return nil, nil
+		})
+		assert.Equal(t, "job", string(received.Data))
+		assert.Equal(t, map[string]string{"traceID": "t1", jobIDKey: "7", attemptKey: "1", queueKey: "default"}, received.Metadata)
+		assert.NoError(t, db.ExpectationsWereMet())
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		q, db := newMockJobQueue(t, nil)
+		claim(db, 2)
+		db.ExpectExec("UPDATE dapr_jobs SET visible_at").
+			WithArgs(int64(7), int64(2000), "handler error", 2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		j, err := q.claim(context.Background())
+		require.NoError(t, err)
+		q.handle(context.Background(), j, func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+			return nil, errors.New("handler error")
+		})
+		assert.NoError(t, db.ExpectationsWereMet())
+	})
+
+	t.Run("last attempt", func(t *testing.T) {
+		q, db := newMockJobQueue(t, map[string]string{"maxAttempts": "3"})
+		claim(db, 3)
+		db.ExpectExec("UPDATE dapr_jobs SET failed_at = now\\(\\)").
+			WithArgs(int64(7), "handler error").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		j, err := q.claim(context.Background())
+		require.NoError(t, err)
+		q.handle(context.Background(), j, func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+			return nil, errors.New("handler error")
+		})
+		assert.NoError(t, db.ExpectationsWereMet())
+	})
+
+	t.Run("empty queue", func(t *testing.T) {
+		q, db := newMockJobQueue(t, nil)
+		db.ExpectQuery("UPDATE dapr_jobs SET attempts").
+			WithArgs("default", int64(30000)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "payload", "metadata", "attempts"}))
+
+		j, err := q.claim(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, j)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	q, _ := newMockJobQueue(t, map[string]string{"retryDelay": "1s", "maxRetryDelay": "10s"})
+	assert.Equal(t, time.Second, q.retryDelay(1))
+	assert.Equal(t, 4*time.Second, q.retryDelay(3))
+	assert.Equal(t, 10*time.Second, q.retryDelay(5))
+	assert.Equal(t, 10*time.Second, q.retryDelay(100))
+}
@@ -14,26 +14,42 @@ limitations under the License.
 package postgres
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pkg/errors"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/component/bulkdata"
+	"github.com/dapr/components-contrib/internal/dialer"
 	"github.com/dapr/kit/logger"
 )
 
 // List of operations.
 const (
-	execOperation  bindings.OperationKind = "exec"
-	queryOperation bindings.OperationKind = "query"
-	closeOperation bindings.OperationKind = "close"
+	execOperation       bindings.OperationKind = "exec"
+	queryOperation      bindings.OperationKind = "query"
+	closeOperation      bindings.OperationKind = "close"
+	bulkInsertOperation bindings.OperationKind = "bulkInsert"
+	exportOperation     bindings.OperationKind = "export"
 
 	connectionURLKey = "url"
 	commandSQLKey    = "sql"
+
+	// keys from response's metadata.
+	respSQLKey          = "sql"
+	respStartTimeKey    = "start-time"
+	respRowsAffectedKey = "rows-affected"
+	respRowsReturnedKey = "rows-returned"
+	respEndTimeKey      = "end-time"
+	respDurationKey     = "duration"
 )
 
 // Postgres represents PostgreSQL output binding.
@@ -59,6 +75,15 @@ func (p *Postgres) Init(metadata bindings.Metadata) error {
 		return errors.Wrap(err, "error opening DB connection")
 	}
 
+	// The connections can be dialed to another address than the host of the URL, e.g. a Unix domain socket.
+	if addr := metadata.Properties[dialer.MetadataKey]; addr != "" {
+		dial, err := dialer.New(addr, poolConfig.ConnConfig.ConnectTimeout)
+		if err != nil {
+			return errors.Wrap(err, "invalid "+dialer.MetadataKey)
+		}
+		poolConfig.ConnConfig.DialFunc = pgconn.DialFunc(dial)
+	}
+
 	p.db, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return errors.Wrap(err, "unable to ping the DB")
@@ -73,6 +98,43 @@ func (p *Postgres) Operations() []bindings.OperationKind {
 		execOperation,
 		queryOperation,
 		closeOperation,
+		bulkInsertOperation,
+		exportOperation,
+	}
+}
+
+// OperationsMetadata returns the description of the operations of the binding.
+func (p *Postgres) OperationsMetadata() []bindings.OperationMetadata {
+	common := []string{bindings.ResponseMetadataOperation, respSQLKey, respStartTimeKey, respEndTimeKey, respDurationKey}
+	return []bindings.OperationMetadata{
+		{
+			Operation:        execOperation,
+			Description:      "Execute a statement, returning the number of affected rows",
+			RequestMetadata:  []string{commandSQLKey},
+			ResponseMetadata: append([]string{respRowsAffectedKey}, common...),
+		},
+		{
+			Operation:        queryOperation,
+			Description:      "Execute a query, returning the rows as a JSON array",
+			RequestMetadata:  []string{commandSQLKey},
+			ResponseMetadata: append([]string{respRowsReturnedKey}, common...),
+		},
+		{
+			Operation:   closeOperation,
+			Description: "Close the connection to the database",
+		},
+		{
+			Operation:        bulkInsertOperation,
+			Description:      "Copy the CSV or JSON lines rows of the request data into a table with COPY FROM STDIN",
+			RequestMetadata:  []string{bulkdata.TableKey, bulkdata.ColumnsKey, bulkdata.FormatKey},
+			ResponseMetadata: []string{respRowsAffectedKey, bindings.ResponseMetadataOperation, respStartTimeKey, respEndTimeKey, respDurationKey},
+		},
+		{
+			Operation:        exportOperation,
+			Description:      "Execute a query, returning the rows as CSV (with COPY TO STDOUT) or JSON lines",
+			RequestMetadata:  []string{commandSQLKey, bulkdata.FormatKey},
+			ResponseMetadata: append([]string{respRowsReturnedKey}, common...),
+		},
 	}
 }
 
@@ -93,45 +155,66 @@ func (p *Postgres) Invoke(ctx context.Context, req *bindings.InvokeRequest) (res
 	}
 	p.logger.Debugf("operation: %v", req.Operation)
 
-	sql, ok := req.Metadata[commandSQLKey]
-	if !ok || sql == "" {
-		return nil, errors.Errorf("required metadata not set: %s", commandSQLKey)
-	}
-
 	startTime := time.Now().UTC()
 	resp = &bindings.InvokeResponse{
 		Metadata: map[string]string{
-			"operation":  string(req.Operation),
-			"sql":        sql,
-			"start-time": startTime.Format(time.RFC3339Nano),
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			respStartTimeKey:                   startTime.Format(time.RFC3339Nano),
 		},
 	}
 
+	// The rows of bulk inserts come from the request data
+	var sql string
+	if req.Operation != bulkInsertOperation {
+		var ok bool
+		sql, ok = req.Metadata[commandSQLKey]
+		if !ok || sql == "" {
+			return nil, errors.Errorf("required metadata not set: %s", commandSQLKey)
+		}
+		resp.Metadata[respSQLKey] = sql
+	}
+
 	switch req.Operation { //nolint:exhaustive
 	case execOperation:
 		r, err := p.exec(ctx, sql)
 		if err != nil {
 			return nil, errors.Wrapf(err, "error executing %s with %v", sql, err)
 		}
-		resp.Metadata["rows-affected"] = strconv.FormatInt(r, 10) // 0 if error
+		resp.Metadata[respRowsAffectedKey] = strconv.FormatInt(r, 10) // 0 if error
 
 	case queryOperation:
-		d, err := p.query(ctx, sql)
+		d, n, err := p.query(ctx, sql)
 		if err != nil {
 			return nil, errors.Wrapf(err, "error executing %s with %v", sql, err)
 		}
 		resp.Data = d
+		resp.Metadata[respRowsReturnedKey] = strconv.Itoa(n)
+
+	case bulkInsertOperation:
+		r, err := p.bulkInsert(ctx, req.Data, req.Metadata)
+		if err != nil {
+			return nil, errors.Wrap(err, "error bulk inserting rows")
+		}
+		resp.Metadata[respRowsAffectedKey] = strconv.FormatInt(r, 10)
+
+	case exportOperation:
+		d, n, err := p.export(ctx, sql, req.Metadata)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error exporting %s", sql)
+		}
+		resp.Data = d
+		resp.Metadata[respRowsReturnedKey] = strconv.Itoa(n)
 
 	default:
 		return nil, errors.Errorf(
-			"invalid operation type: %s. Expected %s, %s, or %s",
-			req.Operation, execOperation, queryOperation, closeOperation,
+			"invalid operation type: %s. Expected %s, %s, %s, %s, or %s",
+			req.Operation, execOperation, queryOperation, bulkInsertOperation, exportOperation, closeOperation,
 		)
 	}
 
 	endTime := time.Now().UTC()
-	resp.Metadata["end-time"] = endTime.Format(time.RFC3339Nano)
-	resp.Metadata["duration"] = endTime.Sub(startTime).String()
+	resp.Metadata[respEndTimeKey] = endTime.Format(time.RFC3339Nano)
+	resp.Metadata[respDurationKey] = endTime.Sub(startTime).String()
 
 	return resp, nil
 }
@@ -146,19 +229,20 @@ func (p *Postgres) Close() error {
 	return nil
 }
 
-func (p *Postgres) query(ctx context.Context, sql string) (result []byte, err error) {
+// query returns the rows serialized as JSON, and their number.
+func (p *Postgres) query(ctx context.Context, sql string) (result []byte, n int, err error) {
 	p.logger.Debugf("query: %s", sql)
 
 	rows, err := p.db.Query(ctx, sql)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error executing %s", sql)
+		return nil, 0, errors.Wrapf(err, "error executing %s", sql)
 	}
 
 	rs := make([]any, 0)
 	for rows.Next() {
 		val, rowErr := rows.Values()
 		if rowErr != nil {
-			return nil, errors.Wrapf(rowErr, "error parsing result: %v", rows.Err())
+			return nil, 0, errors.Wrapf(rowErr, "error parsing result: %v", rows.Err())
 		}
 		rs = append(rs, val) //nolint:asasalint
 	}
@@ -167,7 +251,7 @@ func (p *Postgres) query(ctx context.Context, sql string) (result []byte, err er
 		err = errors.Wrap(err, "error serializing results")
 	}
 
-	return
+	return result, len(rs), err
 }
 
 func (p *Postgres) exec(ctx context.Context, sql string) (result int64, err error) {
@@ -182,3 +266,101 @@ func (p *Postgres) exec(ctx context.Context, sql string) (result int64, err erro
 
 	return
 }
+
+// bulkInsert streams the rows of data to COPY FROM STDIN, returning the number of rows copied.
+func (p *Postgres) bulkInsert(ctx context.Context, data []byte, md map[string]string) (int64, error) {
+	table := md[bulkdata.TableKey]
+	if table == "" {
+		return 0, errors.Errorf("required metadata not set: %s", bulkdata.TableKey)
+	}
+	format, err := bulkdata.ParseFormat(md[bulkdata.FormatKey])
+	if err != nil {
+		return 0, err
+	}
+	rows, err := bulkdata.NewReader(bytes.NewReader(data), format, bulkdata.ParseColumns(md[bulkdata.ColumnsKey]))
+	if err != nil {
+		return 0, err
+	}
+
+	columns := make([]string, len(rows.Columns()))
+	for i, c := range rows.Columns() {
+		columns[i] = pgx.Identifier{c}.Sanitize()
+	}
+	sql := "COPY " + pgx.Identifier(strings.Split(table, ".")).Sanitize() + " (" + strings.Join(columns, ", ") + ") FROM STDIN WITH (FORMAT csv, NULL 'NULL')"
+	p.logger.Debugf("bulk insert: %s", sql)
+
+	conn, err := p.db.Acquire(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "error acquiring a connection")
+	}
+	defer conn.Release()
+
+	src := bulkdata.CSV(rows)
+	defer src.Close()
+
+	tag, err := conn.Conn().PgConn().CopyFrom(ctx, src, sql)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// export returns the rows of the query in the requested format, and their number.
+// CSV exports are produced by the server with COPY TO STDOUT.
+func (p *Postgres) export(ctx context.Context, sql string, md map[string]string) ([]byte, int, error) {
+	format, err := bulkdata.ParseFormat(md[bulkdata.FormatKey])
+	if err != nil {
+		return nil, 0, err
+	}
+	p.logger.Debugf("export: %s", sql)
+
+	var buf bytes.Buffer
+	if format == bulkdata.FormatCSV {
+		conn, err := p.db.Acquire(ctx)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "error acquiring a connection")
+		}
+		defer conn.Release()
+
+		query := strings.TrimRight(strings.TrimSpace(sql), ";")
+		tag, err := conn.Conn().PgConn().CopyTo(ctx, &buf, "COPY ("+query+") TO STDOUT WITH (FORMAT csv, HEADER)")
+		if err != nil {
+			return nil, 0, err
+		}
+		return buf.Bytes(), int(tag.RowsAffected()), nil
+	}
+
+	rows, err := p.db.Query(ctx, sql)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+	w, err := bulkdata.NewWriter(&buf, format, columns)
+	if err != nil {
+		return nil, 0, err
+	}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "error parsing result")
+		}
+		if err = w.Write(values); err != nil {
+			return nil, 0, errors.Wrap(err, "error serializing results")
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if err = w.Flush(); err != nil {
+		return nil, 0, err
+	}
+
+	return buf.Bytes(), w.Rows(), nil
+}
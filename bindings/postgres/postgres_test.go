@@ -44,7 +44,7 @@ func TestOperations(t *testing.T) {
 		b := NewPostgres(nil)
 		assert.NotNil(t, b)
 		l := b.Operations()
-		assert.Equal(t, 3, len(l))
+		assert.Equal(t, 5, len(l))
 	})
 }
 
@@ -108,6 +108,41 @@ func TestPostgresIntegration(t *testing.T) {
 		assertResponse(t, res, err)
 	})
 
+	t.Run("Invoke bulk insert", func(t *testing.T) {
+		res, err := b.Invoke(ctx, &bindings.InvokeRequest{
+			Operation: bulkInsertOperation,
+			Metadata:  map[string]string{"table": "foo"},
+			Data:      []byte("id,v1,ts\n100,\"a,b\",2023-01-02T03:04:05Z\n101,c,\n"),
+		})
+		assertResponse(t, res, err)
+		assert.Equal(t, "2", res.Metadata[respRowsAffectedKey])
+
+		res, err = b.Invoke(ctx, &bindings.InvokeRequest{
+			Operation: bulkInsertOperation,
+			Metadata:  map[string]string{"table": "foo", "format": "jsonl"},
+			Data:      []byte(`{"id":102,"v1":"d"}` + "\n" + `{"id":103,"v1":"e","ts":null}`),
+		})
+		assertResponse(t, res, err)
+		assert.Equal(t, "2", res.Metadata[respRowsAffectedKey])
+	})
+
+	t.Run("Invoke export", func(t *testing.T) {
+		res, err := b.Invoke(ctx, &bindings.InvokeRequest{
+			Operation: exportOperation,
+			Metadata:  map[string]string{commandSQLKey: "SELECT id, v1 FROM foo WHERE id IN (100, 101) ORDER BY id;"},
+		})
+		assertResponse(t, res, err)
+		assert.Equal(t, "id,v1\n100,\"a,b\"\n101,c\n", string(res.Data))
+
+		res, err = b.Invoke(ctx, &bindings.InvokeRequest{
+			Operation: exportOperation,
+			Metadata:  map[string]string{commandSQLKey: "SELECT id, v1 FROM foo WHERE id = 102", "format": "jsonl"},
+		})
+		assertResponse(t, res, err)
+		assert.Equal(t, `{"id":102,"v1":"d"}`+"\n", string(res.Data))
+		assert.Equal(t, "1", res.Metadata[respRowsReturnedKey])
+	})
+
 	t.Run("Invoke delete", func(t *testing.T) {
 		req.Operation = execOperation
 		req.Metadata[commandSQLKey] = testDelete
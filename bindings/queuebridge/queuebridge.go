@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuebridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout              = 30 * time.Second
+	defaultContentType          = "application/json"
+	defaultIdempotencyKeyHeader = "Idempotency-Key"
+	defaultIdempotencyKeyField  = "MessageId"
+	defaultDedupTTL             = 24 * time.Hour
+	defaultKeyPrefix            = "queuebridge||"
+	maxErrorBodySize            = 1 << 10
+)
+
+// QueueBridge is an input binding forwarding the messages read by a queue binding, such as Azure Storage Queues,
+// to an HTTP endpoint. A message is acknowledged once the endpoint answers with a 2xx status, and left in the queue
+// to be delivered again when the endpoint fails.
+// Every request carries an idempotency key, stable across the deliveries of a message, and when a state store is
+// given the keys of the forwarded messages are recorded so that redelivered messages aren't forwarded twice.
+type QueueBridge struct {
+	metadata bridgeMetadata
+	source   bindings.InputBinding
+	store    state.Store
+	client   *http.Client
+	logger   logger.Logger
+}
+
+type bridgeMetadata struct {
+	// URL is the endpoint the messages are sent to.
+	URL    string `mapstructure:"url"`
+	Method string `mapstructure:"method"`
+	// ContentType is the content type of the requests, for the messages without one.
+	ContentType string        `mapstructure:"contentType"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+
+	// IdempotencyKeyHeader is the header of the requests with the idempotency key.
+	IdempotencyKeyHeader string `mapstructure:"idempotencyKeyHeader"`
+	// IdempotencyKeyMetadata is the metadata of the messages used as idempotency key, usually their ID.
+	// The key is a hash of the message data for the messages without this metadata.
+	IdempotencyKeyMetadata string `mapstructure:"idempotencyKeyMetadata"`
+
+	// DedupTTL is how long the keys of the forwarded messages are kept in the state store.
+	DedupTTL  time.Duration `mapstructure:"dedupTTL"`
+	KeyPrefix string        `mapstructure:"keyPrefix"`
+
+	// RetryClientErrors leaves the messages rejected with a 4xx status in the queue. By default they are dropped,
+	// as sending them again would fail the same. 408 and 429 are always retried.
+	RetryClientErrors bool `mapstructure:"retryClientErrors"`
+}
+
+// deliveryRecord is saved in the state store for the forwarded messages.
+type deliveryRecord struct {
+	StatusCode  int       `json:"statusCode"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+}
+
+// NewQueueBridge returns a new bridge forwarding the messages of the source, an initialized input binding, and
+// recording the forwarded messages in the store if not nil.
+func NewQueueBridge(logger logger.Logger, source bindings.InputBinding, store state.Store) bindings.InputBinding {
+	return &QueueBridge{logger: logger, source: source, store: store}
+}
+
+// Init parses the metadata.
+func (b *QueueBridge) Init(meta bindings.Metadata) error {
+	if b.source == nil {
+		return errors.New("queue bridge binding error: a source queue binding is required")
+	}
+
+	b.metadata = bridgeMetadata{
+		Method:                 http.MethodPost,
+		ContentType:            defaultContentType,
+		Timeout:                defaultTimeout,
+		IdempotencyKeyHeader:   defaultIdempotencyKeyHeader,
+		IdempotencyKeyMetadata: defaultIdempotencyKeyField,
+		DedupTTL:               defaultDedupTTL,
+		KeyPrefix:              defaultKeyPrefix,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &b.metadata)
+	if err != nil {
+		return fmt.Errorf("queue bridge binding error: %w", err)
+	}
+
+	u, err := url.Parse(b.metadata.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("queue bridge binding error: invalid url %q", b.metadata.URL)
+	}
+	if b.metadata.DedupTTL < time.Second {
+		return errors.New("queue bridge binding error: dedupTTL must be at least 1s")
+	}
+
+	b.client = &http.Client{Timeout: b.metadata.Timeout}
+	return nil
+}
+
+// Read forwards the messages of the source queue. The handler isn't called: the messages are delivered to the
+// endpoint instead of the app.
+func (b *QueueBridge) Read(ctx context.Context, _ bindings.Handler) error {
+	return b.source.Read(ctx, b.forward)
+}
+
+// forward sends a message to the endpoint. Returning an error leaves the message in the queue.
+func (b *QueueBridge) forward(ctx context.Context, msg *bindings.ReadResponse) ([]byte, error) {
+	key := b.idempotencyKey(msg)
+
+	if b.store != nil {
+		res, err := b.store.Get(ctx, &state.GetRequest{Key: b.metadata.KeyPrefix + key})
+		if err != nil {
+			return nil, fmt.Errorf("queue bridge binding error: failed to check message %s: %w", key, err)
+		}
+		if res != nil && len(res.Data) > 0 {
+			b.logger.Debugf("queue bridge binding: message %s already forwarded, acknowledging it", key)
+			return nil, nil
+		}
+	}
+
+	statusCode, err := b.send(ctx, msg, key)
+	if err != nil {
+		return nil, fmt.Errorf("queue bridge binding error: failed to forward message %s: %w", key, err)
+	}
+
+	if statusCode < 200 || statusCode > 299 {
+		if b.metadata.RetryClientErrors || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500 {
+			return nil, fmt.Errorf("queue bridge binding error: endpoint answered %d to message %s", statusCode, key)
+		}
+		b.logger.Warnf("queue bridge binding: endpoint rejected message %s with status %d, dropping it", key, statusCode)
+	}
+
+	if b.store != nil {
+		if err = b.record(ctx, key, statusCode); err != nil {
+			// The message is acknowledged anyway: the endpoint can still detect a redelivery with the idempotency key.
+			b.logger.Warnf("queue bridge binding: failed to record message %s: %v", key, err)
+		}
+	}
+	return nil, nil
+}
+
+func (b *QueueBridge) send(ctx context.Context, msg *bindings.ReadResponse, key string) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, b.metadata.Method, b.metadata.URL, bytes.NewReader(msg.Data))
+	if err != nil {
+		return 0, err
+	}
+	contentType := b.metadata.ContentType
+	if msg.ContentType != nil && *msg.ContentType != "" {
+		contentType = *msg.ContentType
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set(b.metadata.IdempotencyKeyHeader, key)
+
+	res, err := b.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+		b.logger.Debugf("queue bridge binding: endpoint answered %d to message %s: %s", res.StatusCode, key, body)
+	} else {
+		// Drain the body so that the connection is reused.
+		_, _ = io.Copy(io.Discard, res.Body)
+	}
+	return res.StatusCode, nil
+}
+
+func (b *QueueBridge) record(ctx context.Context, key string, statusCode int) error {
+	data, err := json.Marshal(deliveryRecord{StatusCode: statusCode, DeliveredAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	return b.store.Set(ctx, &state.SetRequest{
+		Key:   b.metadata.KeyPrefix + key,
+		Value: data,
+		Metadata: map[string]string{
+			metadata.TTLMetadataKey: strconv.FormatInt(int64(b.metadata.DedupTTL/time.Second), 10),
+		},
+	})
+}
+
+// idempotencyKey returns the ID of the message if the source reports it, or a hash of its data.
+func (b *QueueBridge) idempotencyKey(msg *bindings.ReadResponse) string {
+	if id := msg.Metadata[b.metadata.IdempotencyKeyMetadata]; id != "" {
+		return id
+	}
+	sum := sha256.Sum256(msg.Data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuebridge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/state"
+	inmemory "github.com/dapr/components-contrib/state/in-memory"
+	"github.com/dapr/kit/logger"
+)
+
+// fakeQueue keeps the handler passed to Read, for the tests to deliver messages.
+type fakeQueue struct {
+	handler bindings.Handler
+}
+
+func (q *fakeQueue) Init(metadata bindings.Metadata) error { return nil }
+
+func (q *fakeQueue) Read(ctx context.Context, handler bindings.Handler) error {
+	q.handler = handler
+	return nil
+}
+
+// deliver returns true if the message is acknowledged.
+func (q *fakeQueue) deliver(t *testing.T, msg *bindings.ReadResponse) bool {
+	t.Helper()
+	_, err := q.handler(context.Background(), msg)
+	return err == nil
+}
+
+func newBridge(t *testing.T, endpoint string, store state.Store, props map[string]string) *fakeQueue {
+	t.Helper()
+	queue := &fakeQueue{}
+	b := NewQueueBridge(logger.NewLogger("test"), queue, store)
+	all := map[string]string{"url": endpoint}
+	for k, v := range props {
+		all[k] = v
+	}
+	require.NoError(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: all}}))
+	require.NoError(t, b.Read(context.Background(), nil))
+	return queue
+}
+
+func newTestStore(t *testing.T) state.Store {
+	t.Helper()
+	store := inmemory.NewInMemoryStateStore(logger.NewLogger("test"))
+	require.NoError(t, store.Init(state.Metadata{}))
+	return store
+}
+
+func TestInit(t *testing.T) {
+	for name, props := range map[string]map[string]string{
+		"missing url":  {},
+		"invalid url":  {"url": "ftp://example.com"},
+		"bad dedupTTL": {"url": "http://example.com", "dedupTTL": "1ms"},
+	} {
+		props := props
+		t.Run(name, func(t *testing.T) {
+			b := NewQueueBridge(logger.NewLogger("test"), &fakeQueue{}, nil)
+			assert.Error(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+		})
+	}
+
+	t.Run("missing source", func(t *testing.T) {
+		b := NewQueueBridge(logger.NewLogger("test"), nil, nil)
+		assert.Error(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"url": "http://example.com"}}}))
+	})
+}
+
+func TestForward(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests []*http.Request
+		status   = http.StatusOK
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests = append(requests, r)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	reset := func(code int) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests = nil
+		status = code
+	}
+
+	t.Run("acknowledges delivered messages", func(t *testing.T) {
+		reset(http.StatusAccepted)
+		queue := newBridge(t, srv.URL, nil, nil)
+
+		assert.True(t, queue.deliver(t, &bindings.ReadResponse{
+			Data:     []byte(`{"a":1}`),
+			Metadata: map[string]string{"MessageId": "m1"},
+		}))
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Equal(t, "m1", requests[0].Header.Get("Idempotency-Key"))
+		assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+	})
+
+	t.Run("idempotency key defaults to a hash of the data", func(t *testing.T) {
+		reset(http.StatusOK)
+		queue := newBridge(t, srv.URL, nil, map[string]string{"idempotencyKeyHeader": "X-Request-Id"})
+
+		contentType := "text/plain"
+		assert.True(t, queue.deliver(t, &bindings.ReadResponse{Data: []byte("hello"), ContentType: &contentType}))
+		assert.True(t, queue.deliver(t, &bindings.ReadResponse{Data: []byte("hello")}))
+		require.Len(t, requests, 2)
+		assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", requests[0].Header.Get("X-Request-Id"))
+		assert.Equal(t, requests[0].Header.Get("X-Request-Id"), requests[1].Header.Get("X-Request-Id"))
+		assert.Equal(t, "text/plain", requests[0].Header.Get("Content-Type"))
+	})
+
+	t.Run("server errors leave the message in the queue", func(t *testing.T) {
+		for _, code := range []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusRequestTimeout} {
+			reset(code)
+			queue := newBridge(t, srv.URL, nil, nil)
+			assert.False(t, queue.deliver(t, &bindings.ReadResponse{Data: []byte("x")}), code)
+		}
+	})
+
+	t.Run("client errors are dropped unless retried", func(t *testing.T) {
+		reset(http.StatusBadRequest)
+		queue := newBridge(t, srv.URL, nil, nil)
+		assert.True(t, queue.deliver(t, &bindings.ReadResponse{Data: []byte("x")}))
+
+		queue = newBridge(t, srv.URL, nil, map[string]string{"retryClientErrors": "true"})
+		assert.False(t, queue.deliver(t, &bindings.ReadResponse{Data: []byte("x")}))
+	})
+
+	t.Run("unreachable endpoint", func(t *testing.T) {
+		queue := newBridge(t, "http://127.0.0.1:1", nil, nil)
+		assert.False(t, queue.deliver(t, &bindings.ReadResponse{Data: []byte("x")}))
+	})
+
+	t.Run("redelivered messages are forwarded once", func(t *testing.T) {
+		reset(http.StatusOK)
+		store := newTestStore(t)
+		queue := newBridge(t, srv.URL, store, nil)
+
+		msg := &bindings.ReadResponse{Data: []byte("x"), Metadata: map[string]string{"MessageId": "m2"}}
+		assert.True(t, queue.deliver(t, msg))
+		assert.True(t, queue.deliver(t, msg))
+		assert.Len(t, requests, 1)
+
+		res, err := store.Get(context.Background(), &state.GetRequest{Key: "queuebridge||m2"})
+		require.NoError(t, err)
+		assert.Contains(t, string(res.Data), `"statusCode":200`)
+	})
+
+	t.Run("failed deliveries aren't recorded", func(t *testing.T) {
+		reset(http.StatusServiceUnavailable)
+		store := newTestStore(t)
+		queue := newBridge(t, srv.URL, store, nil)
+
+		msg := &bindings.ReadResponse{Data: []byte("x"), Metadata: map[string]string{"MessageId": "m3"}}
+		assert.False(t, queue.deliver(t, msg))
+		reset(http.StatusOK)
+		assert.True(t, queue.deliver(t, msg))
+		assert.Len(t, requests, 1)
+	})
+}
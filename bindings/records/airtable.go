@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	airtableURL = "https://api.airtable.com/v0"
+
+	// Airtable creates at most 10 records per request.
+	airtableMaxBatch = 10
+)
+
+// airtable is the provider of Airtable tables.
+type airtable struct {
+	api      *httpAPI
+	baseID   string
+	table    string
+	typecast bool
+}
+
+type airtableRecord struct {
+	ID          string                 `json:"id,omitempty"`
+	CreatedTime string                 `json:"createdTime,omitempty"`
+	Fields      map[string]interface{} `json:"fields"`
+}
+
+type airtableRecords struct {
+	Records []airtableRecord `json:"records"`
+	Offset  string           `json:"offset,omitempty"`
+}
+
+func newAirtable(baseURL string, md recordsMetadata, client *http.Client) *airtable {
+	return &airtable{
+		api: &httpAPI{
+			baseURL: baseURL,
+			headers: map[string]string{
+				"Authorization": "Bearer " + md.Token,
+			},
+			client:       client,
+			errorMessage: airtableErrorMessage,
+		},
+		baseID:   md.BaseID,
+		table:    md.Table,
+		typecast: md.Typecast,
+	}
+}
+
+// tablePath returns the path of the table of the request.
+func (a *airtable) tablePath(req *bindings.InvokeRequest) (string, error) {
+	table := a.table
+	if v := req.Metadata[tableKey]; v != "" {
+		table = v
+	}
+	if table == "" {
+		return "", fmt.Errorf("the %s metadata is required", tableKey)
+	}
+	return "/" + url.PathEscape(a.baseID) + "/" + url.PathEscape(table), nil
+}
+
+func (a *airtable) create(ctx context.Context, req *bindings.InvokeRequest, records []map[string]interface{}) ([]Record, error) {
+	path, err := a.tablePath(req)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]Record, 0, len(records))
+	for start := 0; start < len(records); start += airtableMaxBatch {
+		end := start + airtableMaxBatch
+		if end > len(records) {
+			end = len(records)
+		}
+		body := struct {
+			Records  []airtableRecord `json:"records"`
+			Typecast bool             `json:"typecast,omitempty"`
+		}{
+			Records:  make([]airtableRecord, 0, end-start),
+			Typecast: a.typecast,
+		}
+		for _, fields := range records[start:end] {
+			body.Records = append(body.Records, airtableRecord{Fields: fields})
+		}
+		var res airtableRecords
+		if err = a.api.do(ctx, http.MethodPost, path, body, &res); err != nil {
+			return nil, err
+		}
+		for _, r := range res.Records {
+			created = append(created, r.record())
+		}
+	}
+	return created, nil
+}
+
+func (a *airtable) get(ctx context.Context, req *bindings.InvokeRequest, id string) (*Record, error) {
+	path, err := a.tablePath(req)
+	if err != nil {
+		return nil, err
+	}
+	var res airtableRecord
+	if err = a.api.do(ctx, http.MethodGet, path+"/"+url.PathEscape(id), nil, &res); err != nil {
+		return nil, err
+	}
+	r := res.record()
+	return &r, nil
+}
+
+func (a *airtable) query(ctx context.Context, req *bindings.InvokeRequest) (*QueryResult, error) {
+	path, err := a.tablePath(req)
+	if err != nil {
+		return nil, err
+	}
+	size, err := pageSize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	if size > 0 {
+		q.Set("pageSize", strconv.Itoa(size))
+	}
+	for _, k := range []string{offsetKey, filterByFormulaKey, viewKey, maxRecordsKey} {
+		if v := req.Metadata[k]; v != "" {
+			q.Set(k, v)
+		}
+	}
+	if v := req.Metadata[sortFieldKey]; v != "" {
+		q.Set("sort[0][field]", v)
+		if d := req.Metadata[sortDirectionKey]; d != "" {
+			if d != "asc" && d != "desc" {
+				return nil, fmt.Errorf("invalid %s %q: must be asc or desc", sortDirectionKey, d)
+			}
+			q.Set("sort[0][direction]", d)
+		}
+	}
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var res airtableRecords
+	if err = a.api.do(ctx, http.MethodGet, path, nil, &res); err != nil {
+		return nil, err
+	}
+	result := &QueryResult{
+		Records: make([]Record, len(res.Records)),
+		Offset:  res.Offset,
+	}
+	for i, r := range res.Records {
+		result.Records[i] = r.record()
+	}
+	return result, nil
+}
+
+func (r airtableRecord) record() Record {
+	fields := r.Fields
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return Record{
+		ID:          r.ID,
+		CreatedTime: r.CreatedTime,
+		Fields:      fields,
+	}
+}
+
+// airtableErrorMessage returns the message of an Airtable error response, whose error is an object or a string.
+func airtableErrorMessage(body []byte) string {
+	var res struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &res) != nil || len(res.Error) == 0 {
+		return ""
+	}
+	var e struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(res.Error, &e) == nil {
+		if e.Message != "" {
+			return e.Type + ": " + e.Message
+		}
+		return e.Type
+	}
+	var s string
+	if json.Unmarshal(res.Error, &s) == nil {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	notionURL     = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
+)
+
+// notion is the provider of Notion databases, whose records are pages.
+type notion struct {
+	api        *httpAPI
+	databaseID string
+
+	// Types of the properties of the database, loaded on the first create.
+	lock  sync.Mutex
+	types map[string]string
+}
+
+type notionPage struct {
+	ID          string                     `json:"id"`
+	CreatedTime string                     `json:"created_time"`
+	URL         string                     `json:"url"`
+	Properties  map[string]json.RawMessage `json:"properties"`
+}
+
+func newNotion(baseURL string, md recordsMetadata, client *http.Client) *notion {
+	return &notion{
+		api: &httpAPI{
+			baseURL: baseURL,
+			headers: map[string]string{
+				"Authorization":  "Bearer " + md.Token,
+				"Notion-Version": notionVersion,
+			},
+			client:       client,
+			errorMessage: notionErrorMessage,
+		},
+		databaseID: md.DatabaseID,
+	}
+}
+
+func (n *notion) create(ctx context.Context, _ *bindings.InvokeRequest, records []map[string]interface{}) ([]Record, error) {
+	types, err := n.propertyTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]Record, 0, len(records))
+	for _, fields := range records {
+		props := make(map[string]interface{}, len(fields))
+		for name, v := range fields {
+			props[name], err = notionProperty(types[name], v)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", name, err)
+			}
+		}
+		body := map[string]interface{}{
+			"parent":     map[string]string{"database_id": n.databaseID},
+			"properties": props,
+		}
+		var page notionPage
+		if err = n.api.do(ctx, http.MethodPost, "/pages", body, &page); err != nil {
+			return nil, err
+		}
+		created = append(created, page.record())
+	}
+	return created, nil
+}
+
+func (n *notion) get(ctx context.Context, _ *bindings.InvokeRequest, id string) (*Record, error) {
+	var page notionPage
+	if err := n.api.do(ctx, http.MethodGet, "/pages/"+url.PathEscape(id), nil, &page); err != nil {
+		return nil, err
+	}
+	r := page.record()
+	return &r, nil
+}
+
+func (n *notion) query(ctx context.Context, req *bindings.InvokeRequest) (*QueryResult, error) {
+	size, err := pageSize(req)
+	if err != nil {
+		return nil, err
+	}
+	// The data holds the filter and sorts of the query, in the format of the Notion API.
+	body := map[string]interface{}{}
+	if len(strings.TrimSpace(string(req.Data))) > 0 {
+		if err = json.Unmarshal(req.Data, &body); err != nil {
+			return nil, fmt.Errorf("the data must be a JSON object with the filter and sorts of the query: %w", err)
+		}
+	}
+	if size > 0 {
+		body["page_size"] = size
+	}
+	if v := req.Metadata[offsetKey]; v != "" {
+		body["start_cursor"] = v
+	}
+
+	var res struct {
+		Results    []notionPage `json:"results"`
+		NextCursor *string      `json:"next_cursor"`
+		HasMore    bool         `json:"has_more"`
+	}
+	if err = n.api.do(ctx, http.MethodPost, "/databases/"+url.PathEscape(n.databaseID)+"/query", body, &res); err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Records: make([]Record, len(res.Results))}
+	for i, page := range res.Results {
+		result.Records[i] = page.record()
+	}
+	if res.HasMore && res.NextCursor != nil {
+		result.Offset = *res.NextCursor
+	}
+	return result, nil
+}
+
+// propertyTypes returns the types of the properties of the database by name.
+func (n *notion) propertyTypes(ctx context.Context) (map[string]string, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.types != nil {
+		return n.types, nil
+	}
+	var res struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := n.api.do(ctx, http.MethodGet, "/databases/"+url.PathEscape(n.databaseID), nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the database: %w", err)
+	}
+	n.types = make(map[string]string, len(res.Properties))
+	for name, p := range res.Properties {
+		n.types[name] = p.Type
+	}
+	return n.types, nil
+}
+
+// notionProperty converts a plain value to the property value of its type.
+// Objects are property values already, and are sent as they are.
+func notionProperty(typ string, v interface{}) (interface{}, error) {
+	if _, ok := v.(map[string]interface{}); ok {
+		return v, nil
+	}
+	switch typ {
+	case "title", "rich_text":
+		return map[string]interface{}{typ: []interface{}{
+			map[string]interface{}{"text": map[string]interface{}{"content": fmt.Sprint(v)}},
+		}}, nil
+	case "number", "checkbox", "url", "email", "phone_number":
+		return map[string]interface{}{typ: v}, nil
+	case "select", "status":
+		return map[string]interface{}{typ: map[string]interface{}{"name": fmt.Sprint(v)}}, nil
+	case "multi_select":
+		values, ok := v.([]interface{})
+		if !ok {
+			values = []interface{}{v}
+		}
+		options := make([]interface{}, len(values))
+		for i, o := range values {
+			options[i] = map[string]interface{}{"name": fmt.Sprint(o)}
+		}
+		return map[string]interface{}{typ: options}, nil
+	case "date":
+		return map[string]interface{}{typ: map[string]interface{}{"start": fmt.Sprint(v)}}, nil
+	case "":
+		return nil, fmt.Errorf("the database has no such property")
+	default:
+		return nil, fmt.Errorf("values of %s properties must be property value objects", typ)
+	}
+}
+
+func (p notionPage) record() Record {
+	fields := make(map[string]interface{}, len(p.Properties))
+	for name, raw := range p.Properties {
+		fields[name] = plainValue(raw)
+	}
+	return Record{
+		ID:          p.ID,
+		CreatedTime: p.CreatedTime,
+		URL:         p.URL,
+		Fields:      fields,
+	}
+}
+
+// plainValue returns the plain value of a property value, or the property value itself for types without one.
+func plainValue(raw json.RawMessage) interface{} {
+	var prop map[string]json.RawMessage
+	if json.Unmarshal(raw, &prop) != nil {
+		return raw
+	}
+	var typ string
+	if json.Unmarshal(prop["type"], &typ) != nil {
+		return raw
+	}
+	value := prop[typ]
+
+	switch typ {
+	case "title", "rich_text":
+		var texts []struct {
+			PlainText string `json:"plain_text"`
+		}
+		if json.Unmarshal(value, &texts) == nil {
+			var sb strings.Builder
+			for _, t := range texts {
+				sb.WriteString(t.PlainText)
+			}
+			return sb.String()
+		}
+	case "select", "status":
+		var option *struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(value, &option) == nil {
+			if option == nil {
+				return nil
+			}
+			return option.Name
+		}
+	case "multi_select":
+		var options []struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(value, &options) == nil {
+			names := make([]string, len(options))
+			for i, o := range options {
+				names[i] = o.Name
+			}
+			return names
+		}
+	case "date":
+		var date *struct {
+			Start string `json:"start"`
+		}
+		if json.Unmarshal(value, &date) == nil {
+			if date == nil {
+				return nil
+			}
+			return date.Start
+		}
+	case "formula":
+		var formula map[string]json.RawMessage
+		if json.Unmarshal(value, &formula) == nil {
+			var ftyp string
+			if json.Unmarshal(formula["type"], &ftyp) == nil {
+				var v interface{}
+				if json.Unmarshal(formula[ftyp], &v) == nil {
+					return v
+				}
+			}
+		}
+	case "number", "checkbox", "url", "email", "phone_number", "created_time", "last_edited_time":
+		var v interface{}
+		if json.Unmarshal(value, &v) == nil {
+			return v
+		}
+	}
+	var v interface{}
+	if json.Unmarshal(raw, &v) != nil {
+		return raw
+	}
+	return v
+}
+
+// notionErrorMessage returns the message of a Notion error response.
+func notionErrorMessage(body []byte) string {
+	var res struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &res) != nil || res.Message == "" {
+		return ""
+	}
+	return res.Code + ": " + res.Message
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package records
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// Values of provider.
+	providerAirtable = "airtable"
+	providerNotion   = "notion"
+
+	// Size of the response body included in the errors.
+	maxErrorBodySize = 512
+
+	// keys from request's metadata.
+	recordIDKey = "recordID"
+	tableKey    = "table"
+	pageSizeKey = "pageSize"
+	offsetKey   = "offset"
+	// Airtable query parameters.
+	filterByFormulaKey = "filterByFormula"
+	viewKey            = "view"
+	maxRecordsKey      = "maxRecords"
+	sortFieldKey       = "sortField"
+	sortDirectionKey   = "sortDirection"
+
+	CreateOperation bindings.OperationKind = "create"
+	GetOperation    bindings.OperationKind = "get"
+	QueryOperation  bindings.OperationKind = "query"
+)
+
+// Records is an output binding creating and querying the records of Airtable tables and Notion databases.
+type Records struct {
+	metadata recordsMetadata
+	provider provider
+	logger   logger.Logger
+}
+
+type recordsMetadata struct {
+	// Provider is "airtable" or "notion".
+	Provider string `mapstructure:"provider"`
+	// Token is the Airtable personal access token or the Notion integration token.
+	Token   string        `mapstructure:"token"`
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Airtable: the base, and the default table, which requests can override with the table metadata.
+	BaseID string `mapstructure:"baseID"`
+	Table  string `mapstructure:"table"`
+	// Typecast lets Airtable convert the string values of the created records to the types of the fields.
+	Typecast bool `mapstructure:"typecast"`
+
+	// Notion: the database.
+	DatabaseID string `mapstructure:"databaseID"`
+}
+
+// Record is a record of a table or a page of a database.
+type Record struct {
+	ID          string                 `json:"id"`
+	CreatedTime string                 `json:"createdTime,omitempty"`
+	URL         string                 `json:"url,omitempty"`
+	Fields      map[string]interface{} `json:"fields"`
+}
+
+// QueryResult is the response of queries: a page of records, and the offset of the next page if there are more.
+type QueryResult struct {
+	Records []Record `json:"records"`
+	Offset  string   `json:"offset,omitempty"`
+}
+
+// provider is the API of a service.
+type provider interface {
+	create(ctx context.Context, req *bindings.InvokeRequest, records []map[string]interface{}) ([]Record, error)
+	get(ctx context.Context, req *bindings.InvokeRequest, id string) (*Record, error)
+	query(ctx context.Context, req *bindings.InvokeRequest) (*QueryResult, error)
+}
+
+// httpAPI sends the requests of the providers.
+type httpAPI struct {
+	baseURL string
+	headers map[string]string
+	client  *http.Client
+	// errorMessage extracts the message of the errors returned by the API.
+	errorMessage func(body []byte) string
+}
+
+// NewRecords returns a new records binding.
+func NewRecords(logger logger.Logger) bindings.OutputBinding {
+	return &Records{logger: logger}
+}
+
+// Init parses the metadata and creates the client of the provider.
+func (r *Records) Init(meta bindings.Metadata) error {
+	r.metadata = recordsMetadata{Timeout: defaultTimeout}
+	err := metadata.DecodeMetadata(meta.Properties, &r.metadata)
+	if err != nil {
+		return fmt.Errorf("records binding error: %w", err)
+	}
+	if r.metadata.Token == "" {
+		return errors.New("records binding error: token is required")
+	}
+	client := &http.Client{Timeout: r.metadata.Timeout}
+
+	switch r.metadata.Provider {
+	case providerAirtable:
+		if r.metadata.BaseID == "" {
+			return errors.New("records binding error: baseID is required")
+		}
+		r.provider = newAirtable(airtableURL, r.metadata, client)
+	case providerNotion:
+		if r.metadata.DatabaseID == "" {
+			return errors.New("records binding error: databaseID is required")
+		}
+		r.provider = newNotion(notionURL, r.metadata, client)
+	default:
+		return fmt.Errorf("records binding error: invalid provider %q: must be %s or %s", r.metadata.Provider, providerAirtable, providerNotion)
+	}
+	return nil
+}
+
+// Operations returns the operations supported by the records binding.
+func (r *Records) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{CreateOperation, GetOperation, QueryOperation}
+}
+
+// Invoke runs the operation of the request.
+func (r *Records) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var (
+		res interface{}
+		err error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case CreateOperation:
+		records, single, perr := parseRecords(req.Data)
+		if perr != nil {
+			return nil, fmt.Errorf("records binding error: %w", perr)
+		}
+		var created []Record
+		created, err = r.provider.create(ctx, req, records)
+		res = created
+		if err == nil && single {
+			res = created[0]
+		}
+	case GetOperation:
+		id := req.Metadata[recordIDKey]
+		if id == "" {
+			return nil, fmt.Errorf("records binding error: the %s metadata is required", recordIDKey)
+		}
+		res, err = r.provider.get(ctx, req, id)
+	case QueryOperation:
+		res, err = r.provider.query(ctx, req)
+	default:
+		return nil, fmt.Errorf("records binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("records binding error: %s failed: %w", req.Operation, err)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("records binding error: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}, nil
+}
+
+// parseRecords parses the fields of the records to create: a JSON object, or an array of objects.
+// single reports whether the data is an object.
+func parseRecords(data []byte) (records []map[string]interface{}, single bool, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var record map[string]interface{}
+		if err = json.Unmarshal(data, &record); err != nil {
+			return nil, false, fmt.Errorf("invalid record: %w", err)
+		}
+		return []map[string]interface{}{record}, true, nil
+	}
+	if err = json.Unmarshal(data, &records); err != nil || len(records) == 0 {
+		return nil, false, errors.New("the data must be the fields of a record as a JSON object, or a non-empty array of objects")
+	}
+	return records, false, nil
+}
+
+// pageSize returns the pageSize metadata of the request, 0 if there is none.
+func pageSize(req *bindings.InvokeRequest) (int, error) {
+	v := req.Metadata[pageSizeKey]
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q", pageSizeKey, v)
+	}
+	return n, nil
+}
+
+// do sends a request to the API and decodes the JSON response into res.
+func (a *httpAPI) do(ctx context.Context, method string, path string, body interface{}, res interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	for k, v := range a.headers {
+		req.Header.Set(k, v)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if msg := a.errorMessage(b); msg != "" {
+			return fmt.Errorf("status code %d: %s", resp.StatusCode, msg)
+		}
+		if len(b) > maxErrorBodySize {
+			b = b[:maxErrorBodySize]
+		}
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, b)
+	}
+	return json.Unmarshal(b, res)
+}
+
+// OperationsMetadata describes the operations of the records binding.
+func (r *Records) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: CreateOperation,
+			Description: "Creates the record with the fields of the JSON object in the data, or a record per object of an array. " +
+				"Notion property values may be plain values, converted according to the types of the properties of the database. Returns the created records.",
+			RequestMetadata:  []string{tableKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        GetOperation,
+			Description:      "Returns the record with the recordID metadata.",
+			RequestMetadata:  []string{recordIDKey, tableKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation: QueryOperation,
+			Description: "Returns a page of records, and the offset of the next page. With Airtable, records are filtered and sorted with the metadata; " +
+				"with Notion, the data holds the filter and sorts of the query.",
+			RequestMetadata:  []string{tableKey, pageSizeKey, offsetKey, filterByFormulaKey, viewKey, maxRecordsKey, sortFieldKey, sortDirectionKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
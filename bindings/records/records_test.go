@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestInit(t *testing.T) {
+	tests := map[string]struct {
+		props map[string]string
+		err   string
+	}{
+		"airtable": {
+			props: map[string]string{"provider": "airtable", "token": "t", "baseID": "app1"},
+		},
+		"notion": {
+			props: map[string]string{"provider": "notion", "token": "t", "databaseID": "db1"},
+		},
+		"missing token": {
+			props: map[string]string{"provider": "airtable", "baseID": "app1"},
+			err:   "token is required",
+		},
+		"missing baseID": {
+			props: map[string]string{"provider": "airtable", "token": "t"},
+			err:   "baseID is required",
+		},
+		"missing databaseID": {
+			props: map[string]string{"provider": "notion", "token": "t"},
+			err:   "databaseID is required",
+		},
+		"invalid provider": {
+			props: map[string]string{"provider": "sheets", "token": "t"},
+			err:   "invalid provider",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := NewRecords(logger.NewLogger("test")).(*Records)
+			err := r.Init(bindings.Metadata{Base: metadata.Base{Properties: tt.props}})
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func newTestRecords(t *testing.T, provider string, handler http.HandlerFunc) *Records {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	md := recordsMetadata{Provider: provider, Token: "secret", BaseID: "app1", Table: "Tasks", DatabaseID: "db1"}
+	r := &Records{metadata: md, logger: logger.NewLogger("test")}
+	if provider == providerAirtable {
+		r.provider = newAirtable(srv.URL, md, srv.Client())
+	} else {
+		r.provider = newNotion(srv.URL, md, srv.Client())
+	}
+	return r
+}
+
+func TestAirtable(t *testing.T) {
+	t.Run("create in batches", func(t *testing.T) {
+		var batches []int
+		r := newTestRecords(t, providerAirtable, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "/app1/Projects", req.URL.Path)
+			assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+			var body airtableRecords
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			batches = append(batches, len(body.Records))
+			for i := range body.Records {
+				body.Records[i].ID = "rec" + body.Records[i].Fields["Name"].(string)
+			}
+			json.NewEncoder(w).Encode(body)
+		})
+
+		records := make([]map[string]string, 12)
+		for i := range records {
+			records[i] = map[string]string{"Name": string(rune('a' + i))}
+		}
+		data, _ := json.Marshal(records)
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CreateOperation,
+			Data:      data,
+			Metadata:  map[string]string{tableKey: "Projects"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{10, 2}, batches)
+		var created []Record
+		require.NoError(t, json.Unmarshal(res.Data, &created))
+		require.Len(t, created, 12)
+		assert.Equal(t, "recl", created[11].ID)
+	})
+
+	t.Run("create a record", func(t *testing.T) {
+		r := newTestRecords(t, providerAirtable, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "/app1/Tasks", req.URL.Path)
+			io.WriteString(w, `{"records":[{"id":"rec1","createdTime":"2022-10-01T00:00:00.000Z","fields":{"Name":"a"}}]}`)
+		})
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CreateOperation,
+			Data:      []byte(` {"Name":"a"}`),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"rec1","createdTime":"2022-10-01T00:00:00.000Z","fields":{"Name":"a"}}`, string(res.Data))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		r := newTestRecords(t, providerAirtable, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, http.MethodGet, req.Method)
+			q := req.URL.Query()
+			assert.Equal(t, "{Done}=1", q.Get("filterByFormula"))
+			assert.Equal(t, "5", q.Get("pageSize"))
+			assert.Equal(t, "itr1", q.Get("offset"))
+			assert.Equal(t, "Name", q.Get("sort[0][field]"))
+			assert.Equal(t, "desc", q.Get("sort[0][direction]"))
+			io.WriteString(w, `{"records":[{"id":"rec1","fields":{"Name":"a"}}],"offset":"itr2"}`)
+		})
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: QueryOperation,
+			Metadata: map[string]string{
+				filterByFormulaKey: "{Done}=1",
+				pageSizeKey:        "5",
+				offsetKey:          "itr1",
+				sortFieldKey:       "Name",
+				sortDirectionKey:   "desc",
+			},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"records":[{"id":"rec1","fields":{"Name":"a"}}],"offset":"itr2"}`, string(res.Data))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r := newTestRecords(t, providerAirtable, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "/app1/Tasks/rec404", req.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"NOT_FOUND"}`)
+		})
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: GetOperation,
+			Metadata:  map[string]string{recordIDKey: "rec404"},
+		})
+		require.ErrorContains(t, err, "status code 404: NOT_FOUND")
+	})
+}
+
+func TestNotion(t *testing.T) {
+	t.Run("create converts plain values", func(t *testing.T) {
+		var retrieved int
+		r := newTestRecords(t, providerNotion, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, notionVersion, req.Header.Get("Notion-Version"))
+			switch req.URL.Path {
+			case "/databases/db1":
+				retrieved++
+				io.WriteString(w, `{"properties":{"Name":{"type":"title"},"Points":{"type":"number"},"Tags":{"type":"multi_select"},"Status":{"type":"select"}}}`)
+			case "/pages":
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+				b, _ := json.Marshal(body)
+				assert.JSONEq(t, `{
+					"parent": {"database_id": "db1"},
+					"properties": {
+						"Name": {"title": [{"text": {"content": "Write docs"}}]},
+						"Points": {"number": 3},
+						"Tags": {"multi_select": [{"name": "docs"}]},
+						"Status": {"select": {"name": "Todo"}}
+					}
+				}`, string(b))
+				io.WriteString(w, `{"id":"p1","created_time":"2022-10-01T00:00:00.000Z","url":"https://www.notion.so/p1","properties":{
+					"Name":{"type":"title","title":[{"plain_text":"Write "},{"plain_text":"docs"}]},
+					"Points":{"type":"number","number":3},
+					"Tags":{"type":"multi_select","multi_select":[{"name":"docs"}]},
+					"Status":{"type":"select","select":{"name":"Todo"}}
+				}}`)
+			default:
+				t.Errorf("unexpected path %s", req.URL.Path)
+			}
+		})
+
+		for i := 0; i < 2; i++ {
+			res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: CreateOperation,
+				Data:      []byte(`{"Name":"Write docs","Points":3,"Tags":["docs"],"Status":"Todo"}`),
+			})
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"id":"p1","createdTime":"2022-10-01T00:00:00.000Z","url":"https://www.notion.so/p1",
+				"fields":{"Name":"Write docs","Points":3,"Tags":["docs"],"Status":"Todo"}}`, string(res.Data))
+		}
+		assert.Equal(t, 1, retrieved)
+	})
+
+	t.Run("create with an unknown property", func(t *testing.T) {
+		r := newTestRecords(t, providerNotion, func(w http.ResponseWriter, req *http.Request) {
+			io.WriteString(w, `{"properties":{"Name":{"type":"title"}}}`)
+		})
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CreateOperation,
+			Data:      []byte(`{"Owner":"me"}`),
+		})
+		require.ErrorContains(t, err, "property Owner: the database has no such property")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		r := newTestRecords(t, providerNotion, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "/databases/db1/query", req.URL.Path)
+			b, _ := io.ReadAll(req.Body)
+			assert.JSONEq(t, `{"filter":{"property":"Done","checkbox":{"equals":true}},"page_size":2,"start_cursor":"c1"}`, string(b))
+			io.WriteString(w, `{"results":[{"id":"p1","properties":{"Done":{"type":"checkbox","checkbox":true}}}],"next_cursor":"c2","has_more":true}`)
+		})
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: QueryOperation,
+			Data:      []byte(`{"filter":{"property":"Done","checkbox":{"equals":true}}}`),
+			Metadata:  map[string]string{pageSizeKey: "2", offsetKey: "c1"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"records":[{"id":"p1","fields":{"Done":true}}],"offset":"c2"}`, string(res.Data))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r := newTestRecords(t, providerNotion, func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"object":"error","status":401,"code":"unauthorized","message":"API token is invalid."}`)
+		})
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: GetOperation,
+			Metadata:  map[string]string{recordIDKey: "p1"},
+		})
+		require.ErrorContains(t, err, "status code 401: unauthorized: API token is invalid.")
+	})
+}
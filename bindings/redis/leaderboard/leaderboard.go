@@ -0,0 +1,458 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dapr/components-contrib/bindings"
+	rediscomponent "github.com/dapr/components-contrib/internal/component/redis"
+	"github.com/dapr/components-contrib/internal/redact"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// Operations of the leaderboard binding.
+const (
+	ZAddOperation   bindings.OperationKind = "zadd"
+	ZRangeOperation bindings.OperationKind = "zrange"
+	RankOperation   bindings.OperationKind = "rank"
+	TrimOperation   bindings.OperationKind = "trim"
+)
+
+// Request metadata of the leaderboard binding.
+const (
+	leaderboardKey = "leaderboard"
+	memberKey      = "member"
+	orderKey       = "order"
+	updateModeKey  = "updateMode"
+	startKey       = "start"
+	stopKey        = "stop"
+	keepKey        = "keep"
+
+	removedKey = "removed"
+)
+
+// Orders of the leaderboards.
+const (
+	// OrderDesc ranks the highest scores first.
+	OrderDesc = "desc"
+	// OrderAsc ranks the lowest scores first, e.g. for completion times.
+	OrderAsc = "asc"
+)
+
+// Update modes of the zadd operation.
+const (
+	// UpdateModeSet sets the score of the members.
+	UpdateModeSet = "set"
+	// UpdateModeBest only updates the score of the members when it ranks better, e.g. to keep high scores.
+	UpdateModeBest = "best"
+	// UpdateModeIncr increments the score of the members.
+	UpdateModeIncr = "incr"
+)
+
+const (
+	defaultStart = 0
+	defaultStop  = 9
+)
+
+// Entry is a member of a leaderboard with its score and rank.
+// The rank starts at 0 for the first member in the order of the leaderboard.
+type Entry struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+	Rank   *int64  `json:"rank,omitempty"`
+}
+
+type leaderboardMetadata struct {
+	// Leaderboard is the name of the leaderboard used when the requests do not set one.
+	Leaderboard string `mapstructure:"leaderboard"`
+	// KeyPrefix is prepended to the names of the leaderboards to get their Redis keys.
+	KeyPrefix string `mapstructure:"keyPrefix"`
+	// Order is the order of the leaderboards, desc by default.
+	Order string `mapstructure:"order"`
+}
+
+// Leaderboard is an output binding exposing leaderboards stored in Redis sorted sets.
+type Leaderboard struct {
+	client         rediscomponent.RedisClient
+	clientSettings *rediscomponent.Settings
+	metadata       leaderboardMetadata
+	logger         logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewLeaderboard returns a new Redis leaderboard binding instance.
+func NewLeaderboard(logger logger.Logger) bindings.OutputBinding {
+	return &Leaderboard{logger: logger}
+}
+
+// Init performs metadata parsing and connection creation.
+func (l *Leaderboard) Init(meta bindings.Metadata) (err error) {
+	l.metadata = leaderboardMetadata{Order: OrderDesc}
+	if err = metadata.DecodeMetadata(meta.Properties, &l.metadata); err != nil {
+		return fmt.Errorf("redis leaderboard binding: %w", err)
+	}
+	if err = validateOrder(l.metadata.Order); err != nil {
+		return fmt.Errorf("redis leaderboard binding: %w", err)
+	}
+
+	l.client, l.clientSettings, err = rediscomponent.ParseClientFromProperties(meta.Properties, nil)
+	if err != nil {
+		return err
+	}
+
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+
+	_, err = l.client.PingResult(l.ctx)
+	if err != nil {
+		return fmt.Errorf("redis leaderboard binding: error connecting to redis at %s: %s", redact.ConnectionString(l.clientSettings.Host), err)
+	}
+
+	return nil
+}
+
+func (l *Leaderboard) Ping() error {
+	if _, err := l.client.PingResult(l.ctx); err != nil {
+		return fmt.Errorf("redis leaderboard binding: error connecting to redis at %s: %s", redact.ConnectionString(l.clientSettings.Host), err)
+	}
+
+	return nil
+}
+
+func (l *Leaderboard) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		ZAddOperation,
+		ZRangeOperation,
+		RankOperation,
+		TrimOperation,
+	}
+}
+
+// OperationsMetadata returns the description of the operations of the binding.
+func (l *Leaderboard) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:       ZAddOperation,
+			Description:     "Add members with their scores, an entry or a list of entries in the data, to a leaderboard",
+			RequestMetadata: []string{leaderboardKey, orderKey, updateModeKey},
+		},
+		{
+			Operation:       ZRangeOperation,
+			Description:     "Return the entries of a leaderboard between two ranks, both inclusive",
+			RequestMetadata: []string{leaderboardKey, orderKey, startKey, stopKey},
+		},
+		{
+			Operation:       RankOperation,
+			Description:     "Return the entry of a member of a leaderboard, or no data if the member is not in the leaderboard",
+			RequestMetadata: []string{leaderboardKey, orderKey, memberKey},
+		},
+		{
+			Operation:        TrimOperation,
+			Description:      "Remove the members of a leaderboard ranked after a number of members to keep",
+			RequestMetadata:  []string{leaderboardKey, orderKey, keepKey},
+			ResponseMetadata: []string{removedKey},
+		},
+	}
+}
+
+func (l *Leaderboard) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	name := req.Metadata[leaderboardKey]
+	if name == "" {
+		name = l.metadata.Leaderboard
+	}
+	if name == "" {
+		return nil, errors.New("redis leaderboard binding: missing leaderboard in request metadata")
+	}
+	key := l.metadata.KeyPrefix + name
+
+	order := req.Metadata[orderKey]
+	if order == "" {
+		order = l.metadata.Order
+	}
+	if err := validateOrder(order); err != nil {
+		return nil, fmt.Errorf("redis leaderboard binding: %w", err)
+	}
+
+	var (
+		res *bindings.InvokeResponse
+		err error
+	)
+	switch req.Operation {
+	case ZAddOperation:
+		res, err = l.zadd(ctx, key, order, req)
+	case ZRangeOperation:
+		res, err = l.zrange(ctx, key, order, req)
+	case RankOperation:
+		res, err = l.rank(ctx, key, order, req)
+	case TrimOperation:
+		res, err = l.trim(ctx, key, order, req)
+	default:
+		return nil, fmt.Errorf("invalid operation type: %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis leaderboard binding: %w", err)
+	}
+
+	return res, nil
+}
+
+// zadd adds the entries of the request data, a single entry or a list of entries, to the leaderboard.
+func (l *Leaderboard) zadd(ctx context.Context, key, order string, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var entries []Entry
+	if err := json.Unmarshal(req.Data, &entries); err != nil {
+		var entry Entry
+		if err = json.Unmarshal(req.Data, &entry); err != nil {
+			return nil, fmt.Errorf("invalid entries: %w", err)
+		}
+		entries = []Entry{entry}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no entries to add")
+	}
+	for _, e := range entries {
+		if e.Member == "" {
+			return nil, errors.New("missing member in entry")
+		}
+	}
+
+	args := []interface{}{"ZADD", key}
+	switch mode := req.Metadata[updateModeKey]; mode {
+	case "", UpdateModeSet:
+	case UpdateModeBest:
+		if order == OrderDesc {
+			args = append(args, "GT")
+		} else {
+			args = append(args, "LT")
+		}
+	case UpdateModeIncr:
+		if len(entries) != 1 {
+			return nil, errors.New("the incr update mode supports a single entry")
+		}
+		args = append(args, "INCR")
+	default:
+		return nil, fmt.Errorf("invalid update mode: %s", mode)
+	}
+	for _, e := range entries {
+		args = append(args, e.Score, e.Member)
+	}
+
+	return nil, l.client.DoWrite(ctx, args...)
+}
+
+// zrange returns the entries of the leaderboard between the start and stop ranks, both inclusive.
+func (l *Leaderboard) zrange(ctx context.Context, key, order string, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	start, err := intMetadata(req.Metadata, startKey, defaultStart)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := intMetadata(req.Metadata, stopKey, defaultStop)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := "ZREVRANGE"
+	if order == OrderAsc {
+		cmd = "ZRANGE"
+	}
+	res, err := l.client.DoRead(ctx, cmd, key, start, stop, "WITHSCORES")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseEntries(res)
+	if err != nil {
+		return nil, err
+	}
+
+	// Negative ranks count from the last member, the first rank is known only when they are not used
+	for i := range entries {
+		if start >= 0 {
+			rank := start + int64(i)
+			entries[i].Rank = &rank
+		}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{Data: data}, nil
+}
+
+// rank returns the entry of a member. The response has no data when the member is not in the leaderboard.
+func (l *Leaderboard) rank(ctx context.Context, key, order string, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	member := req.Metadata[memberKey]
+	if member == "" {
+		return nil, errors.New("missing member in request metadata")
+	}
+
+	cmd := "ZREVRANK"
+	if order == OrderAsc {
+		cmd = "ZRANK"
+	}
+	res, err := l.client.DoRead(ctx, cmd, key, member)
+	if l.isNil(err) {
+		return &bindings.InvokeResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	rank, err := toInt(res)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = l.client.DoRead(ctx, "ZSCORE", key, member)
+	if l.isNil(err) {
+		// removed in between
+		return &bindings.InvokeResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	score, err := toFloat(res)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(Entry{Member: member, Score: score, Rank: &rank})
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{Data: data}, nil
+}
+
+// trim removes the members ranked after the number of members to keep.
+func (l *Leaderboard) trim(ctx context.Context, key, order string, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Metadata[keepKey] == "" {
+		return nil, errors.New("missing keep in request metadata")
+	}
+	keep, err := intMetadata(req.Metadata, keepKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	if keep < 0 {
+		return nil, fmt.Errorf("invalid keep: %d", keep)
+	}
+
+	// Ranks of ZREMRANGEBYRANK are in ascending order
+	start, stop := keep, int64(-1)
+	if order == OrderDesc {
+		start, stop = 0, -keep-1
+	}
+	res, err := l.client.DoRead(ctx, "ZREMRANGEBYRANK", key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := toInt(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{removedKey: strconv.FormatInt(removed, 10)},
+	}, nil
+}
+
+func (l *Leaderboard) isNil(err error) bool {
+	return err != nil && err.Error() == l.client.GetNilValueError().Error()
+}
+
+func (l *Leaderboard) Close() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+
+	return l.client.Close()
+}
+
+func validateOrder(order string) error {
+	if order != OrderDesc && order != OrderAsc {
+		return fmt.Errorf("invalid order: %s", order)
+	}
+
+	return nil
+}
+
+func intMetadata(md map[string]string, key string, defaultValue int64) (int64, error) {
+	val, ok := md[key]
+	if !ok || val == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return n, nil
+}
+
+// parseEntries parses the reply of a range with scores, either flat (RESP2) or made of pairs (RESP3).
+func parseEntries(res interface{}) ([]Entry, error) {
+	items, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply type %T", res)
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for i := 0; i < len(items); i++ {
+		var member, score interface{}
+		if pair, ok := items[i].([]interface{}); ok && len(pair) == 2 {
+			member, score = pair[0], pair[1]
+		} else if i+1 < len(items) {
+			member, score = items[i], items[i+1]
+			i++
+		} else {
+			return nil, errors.New("unexpected reply: missing score")
+		}
+
+		s, err := toFloat(score)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Member: fmt.Sprint(member), Score: s})
+	}
+
+	return entries, nil
+}
+
+func toInt(res interface{}) (int64, error) {
+	switch v := res.(type) {
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected reply type %T", res)
+	}
+}
+
+func toFloat(res interface{}) (float64, error) {
+	switch v := res.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("unexpected reply type %T", res)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	internalredis "github.com/dapr/components-contrib/internal/component/redis"
+	"github.com/dapr/kit/logger"
+)
+
+func TestLeaderboard(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	bind := &Leaderboard{
+		client:   c,
+		metadata: leaderboardMetadata{Order: OrderDesc, KeyPrefix: "lb:"},
+		logger:   logger.NewLogger("test"),
+	}
+	bind.ctx, bind.cancel = context.WithCancel(context.Background())
+
+	invoke := func(op bindings.OperationKind, data string, md map[string]string) (*bindings.InvokeResponse, error) {
+		return bind.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: op,
+			Data:      []byte(data),
+			Metadata:  md,
+		})
+	}
+	board := map[string]string{"leaderboard": "season1"}
+
+	_, err := invoke(ZAddOperation, `[{"member":"alice","score":100},{"member":"bob","score":80},{"member":"carol","score":120}]`, board)
+	require.NoError(t, err)
+	_, err = invoke(ZAddOperation, `{"member":"dave","score":90}`, board)
+	require.NoError(t, err)
+
+	t.Run("zrange", func(t *testing.T) {
+		res, err := invoke(ZRangeOperation, "", map[string]string{"leaderboard": "season1", "start": "0", "stop": "2"})
+		require.NoError(t, err)
+		var entries []Entry
+		require.NoError(t, json.Unmarshal(res.Data, &entries))
+		require.Len(t, entries, 3)
+		assert.Equal(t, "carol", entries[0].Member)
+		assert.Equal(t, float64(120), entries[0].Score)
+		assert.Equal(t, int64(0), *entries[0].Rank)
+		assert.Equal(t, "dave", entries[2].Member)
+		assert.Equal(t, int64(2), *entries[2].Rank)
+	})
+
+	t.Run("zrange in ascending order", func(t *testing.T) {
+		res, err := invoke(ZRangeOperation, "", map[string]string{"leaderboard": "season1", "order": "asc", "stop": "0"})
+		require.NoError(t, err)
+		var entries []Entry
+		require.NoError(t, json.Unmarshal(res.Data, &entries))
+		require.Len(t, entries, 1)
+		assert.Equal(t, "bob", entries[0].Member)
+	})
+
+	t.Run("best update mode keeps the high score", func(t *testing.T) {
+		_, err := invoke(ZAddOperation, `{"member":"alice","score":50}`, map[string]string{"leaderboard": "season1", "updateMode": "best"})
+		require.NoError(t, err)
+		score, err := s.ZScore("lb:season1", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, float64(100), score)
+	})
+
+	t.Run("incr update mode", func(t *testing.T) {
+		_, err := invoke(ZAddOperation, `{"member":"bob","score":5}`, map[string]string{"leaderboard": "season1", "updateMode": "incr"})
+		require.NoError(t, err)
+		score, err := s.ZScore("lb:season1", "bob")
+		require.NoError(t, err)
+		assert.Equal(t, float64(85), score)
+	})
+
+	t.Run("rank", func(t *testing.T) {
+		res, err := invoke(RankOperation, "", map[string]string{"leaderboard": "season1", "member": "alice"})
+		require.NoError(t, err)
+		var entry Entry
+		require.NoError(t, json.Unmarshal(res.Data, &entry))
+		assert.Equal(t, "alice", entry.Member)
+		assert.Equal(t, float64(100), entry.Score)
+		assert.Equal(t, int64(1), *entry.Rank)
+
+		res, err = invoke(RankOperation, "", map[string]string{"leaderboard": "season1", "member": "nobody"})
+		require.NoError(t, err)
+		assert.Empty(t, res.Data)
+	})
+
+	t.Run("trim", func(t *testing.T) {
+		res, err := invoke(TrimOperation, "", map[string]string{"leaderboard": "season1", "keep": "2"})
+		require.NoError(t, err)
+		assert.Equal(t, "2", res.Metadata["removed"])
+		members, err := s.ZMembers("lb:season1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"alice", "carol"}, members)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := invoke(ZRangeOperation, "", nil)
+		assert.Error(t, err)
+		_, err = invoke(ZAddOperation, `{"score":1}`, board)
+		assert.Error(t, err)
+		_, err = invoke(ZAddOperation, `{"member":"a","score":1}`, map[string]string{"leaderboard": "season1", "updateMode": "max"})
+		assert.Error(t, err)
+		_, err = invoke(RankOperation, "", board)
+		assert.Error(t, err)
+		_, err = invoke(TrimOperation, "", board)
+		assert.Error(t, err)
+		_, err = invoke(ZRangeOperation, "", map[string]string{"leaderboard": "season1", "order": "random"})
+		assert.Error(t, err)
+		_, err = invoke(bindings.GetOperation, "", board)
+		assert.Error(t, err)
+	})
+}
+
+func setupMiniredis() (*miniredis.Miniredis, internalredis.RedisClient) {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	opts := &redis.Options{
+		Addr: s.Addr(),
+		DB:   0,
+	}
+
+	return s, internalredis.ClientFromV8Client(redis.NewClient(opts))
+}
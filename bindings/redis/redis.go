@@ -20,6 +20,7 @@ import (
 
 	"github.com/dapr/components-contrib/bindings"
 	rediscomponent "github.com/dapr/components-contrib/internal/component/redis"
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/kit/logger"
 )
 
@@ -49,7 +50,7 @@ func (r *Redis) Init(meta bindings.Metadata) (err error) {
 
 	_, err = r.client.PingResult(r.ctx)
 	if err != nil {
-		return fmt.Errorf("redis binding: error connecting to redis at %s: %s", r.clientSettings.Host, err)
+		return fmt.Errorf("redis binding: error connecting to redis at %s: %s", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 
 	return err
@@ -57,7 +58,7 @@ func (r *Redis) Init(meta bindings.Metadata) (err error) {
 
 func (r *Redis) Ping() error {
 	if _, err := r.client.PingResult(r.ctx); err != nil {
-		return fmt.Errorf("redis binding: error connecting to redis at %s: %s", r.clientSettings.Host, err)
+		return fmt.Errorf("redis binding: error connecting to redis at %s: %s", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 
 	return nil
@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/resiliency"
+)
+
+// resilientOutputBinding invokes an output binding with the timeout, retries and circuit breaker set in the component metadata.
+type resilientOutputBinding struct {
+	outputForwarder
+
+	policy *resiliency.Policy
+}
+
+// NewResilientOutputBinding wraps an OutputBinding so that Invoke is run with the resiliency settings in its metadata:
+// "resiliencyTimeout", "resiliencyRetry*" and "resiliencyCircuitBreaker*".
+// Only enable retries for bindings whose operations are idempotent.
+func NewResilientOutputBinding(inner OutputBinding) OutputBinding {
+	return exposeOptionalOutput(&resilientOutputBinding{outputForwarder: outputForwarder{inner}}, inner)
+}
+
+func (b *resilientOutputBinding) Init(metadata Metadata) error {
+	m, err := resiliency.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("binding resiliency error: %w", err)
+	}
+	b.policy = resiliency.NewPolicy(m, nil)
+
+	return b.OutputBinding.Init(metadata)
+}
+
+func (b *resilientOutputBinding) Invoke(ctx context.Context, req *InvokeRequest) (*InvokeResponse, error) {
+	return resiliency.Run(ctx, b.policy, func(ctx context.Context) (*InvokeResponse, error) {
+		return b.OutputBinding.Invoke(ctx, req)
+	})
+}
@@ -39,3 +39,17 @@ type InvokeResponse struct {
 	Metadata    map[string]string `json:"metadata"`
 	ContentType *string           `json:"contentType,omitempty"`
 }
+
+// Well-known keys of the InvokeResponse metadata.
+// Output bindings set them when the information is available, so that apps can read them in the same way for every binding.
+const (
+	// ResponseMetadataOperation is the operation that was invoked.
+	ResponseMetadataOperation = "operation"
+	// ResponseMetadataStatusCode is the status code returned by the remote system.
+	ResponseMetadataStatusCode = "statusCode"
+	// ResponseMetadataRequestID is the ID the remote system assigned to the request, to correlate the result with its logs.
+	ResponseMetadataRequestID = "requestId"
+	// ResponseMetadataNextCursor is the opaque cursor to pass in the next request to get the next page of results.
+	// It is empty or absent when there are no more results.
+	ResponseMetadataNextCursor = "nextCursor"
+)
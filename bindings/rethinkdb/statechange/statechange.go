@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/internal/utils"
 	"github.com/dapr/kit/logger"
 )
@@ -94,7 +95,7 @@ func (b *Binding) Read(ctx context.Context, handler bindings.Handler) error {
 			if err != nil {
 				b.logger.Errorf("error marshalling change handler: %v", err)
 			}
-			b.logger.Debugf("event: %s", string(data))
+			b.logger.Debugf("event: %s", redact.Body(data))
 
 			resp := &bindings.ReadResponse{
 				Data: data,
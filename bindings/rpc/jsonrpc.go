@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	jsonrpcContentType = "application/json"
+
+	// Code of the errors of the calls of a batch the server didn't respond to.
+	jsonrpcInternalErrorCode = -32603
+)
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	// ID is nil for notifications.
+	ID *uint64 `json:"id,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+func (r *RPC) newJSONRPCRequest(method string, params json.RawMessage, notify bool) (*jsonrpcRequest, error) {
+	params = bytes.TrimSpace(params)
+	if len(params) > 0 && params[0] != '[' && params[0] != '{' {
+		return nil, errors.New("the params of JSON-RPC calls must be an array or an object")
+	}
+	req := &jsonrpcRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+	if !notify {
+		id := r.nextID()
+		req.ID = &id
+	}
+	return req, nil
+}
+
+func (r *RPC) callJSONRPC(ctx context.Context, method string, params []byte, notify bool) ([]byte, error) {
+	req, err := r.newJSONRPCRequest(method, params, notify)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, ok, err := r.post(ctx, jsonrpcContentType, body)
+	if err != nil {
+		return nil, err
+	}
+	if notify {
+		if !ok {
+			return nil, statusError(resBody)
+		}
+		return nil, nil
+	}
+
+	// Servers may return errors with an error status code: parse the response first.
+	var res jsonrpcResponse
+	if err = json.Unmarshal(resBody, &res); err != nil || (res.Result == nil && res.Error == nil) {
+		if !ok {
+			return nil, statusError(resBody)
+		}
+		return nil, fmt.Errorf("invalid JSON-RPC response: %s", resBody)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return res.Result, nil
+}
+
+func (r *RPC) batchJSONRPC(ctx context.Context, calls []BatchCall) ([]*BatchResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("empty batch")
+	}
+
+	reqs := make([]*jsonrpcRequest, len(calls))
+	indexes := make(map[uint64]int, len(calls))
+	for i, call := range calls {
+		req, err := r.newJSONRPCRequest(call.Method, call.Params, call.Notification)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		reqs[i] = req
+		if req.ID != nil {
+			indexes[*req.ID] = i
+		}
+	}
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, ok, err := r.post(ctx, jsonrpcContentType, body)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*BatchResult, len(calls))
+	if len(indexes) == 0 {
+		// Batches of notifications have no response.
+		if !ok {
+			return nil, statusError(resBody)
+		}
+		return results, nil
+	}
+
+	var responses []jsonrpcResponse
+	if err = json.Unmarshal(resBody, &responses); err != nil {
+		// Invalid batches are rejected with a single error.
+		var res jsonrpcResponse
+		if json.Unmarshal(resBody, &res) == nil && res.Error != nil {
+			return nil, res.Error
+		}
+		if !ok {
+			return nil, statusError(resBody)
+		}
+		return nil, fmt.Errorf("invalid JSON-RPC batch response: %s", resBody)
+	}
+
+	for _, res := range responses {
+		if res.ID == nil {
+			continue
+		}
+		i, found := indexes[*res.ID]
+		if !found {
+			continue
+		}
+		results[i] = &BatchResult{Result: res.Result, Error: res.Error}
+		delete(indexes, *res.ID)
+	}
+	for _, i := range indexes {
+		results[i] = &BatchResult{Error: &Error{Code: jsonrpcInternalErrorCode, Message: "no response to the call"}}
+	}
+
+	return results, nil
+}
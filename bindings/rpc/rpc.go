@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// Size of the response body included in the errors of non-RPC responses.
+	maxErrorBodySize = 512
+
+	// Values of protocol.
+	protocolJSONRPC = "jsonrpc"
+	protocolXMLRPC  = "xmlrpc"
+
+	// keys from request's metadata.
+	methodKey = "method"
+
+	CallOperation   bindings.OperationKind = "call"
+	NotifyOperation bindings.OperationKind = "notify"
+	BatchOperation  bindings.OperationKind = "batch"
+)
+
+// RPC is an output binding calling the methods of a JSON-RPC 2.0 or XML-RPC server over HTTP.
+type RPC struct {
+	metadata rpcMetadata
+	headers  http.Header
+	client   *http.Client
+	lastID   uint64
+	logger   logger.Logger
+}
+
+type rpcMetadata struct {
+	URL string `mapstructure:"url"`
+	// Protocol is "jsonrpc" or "xmlrpc".
+	Protocol string        `mapstructure:"protocol"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+
+	// Headers added to the requests, as a comma-separated list of "Name: value".
+	Headers     []string `mapstructure:"headers"`
+	Username    string   `mapstructure:"username"`
+	Password    string   `mapstructure:"password"`
+	BearerToken string   `mapstructure:"bearerToken"`
+}
+
+// Error is an error returned by the server: a JSON-RPC error object or an XML-RPC fault.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// BatchCall is an entry of the data of batch requests.
+type BatchCall struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	// Notification calls have no result, and are only supported with JSON-RPC.
+	Notification bool `json:"notification,omitempty"`
+}
+
+// BatchResult is the result of a call of a batch; the results of notifications are null.
+type BatchResult struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// NewRPC returns a new RPC binding.
+func NewRPC(logger logger.Logger) bindings.OutputBinding {
+	return &RPC{logger: logger}
+}
+
+// Init parses the metadata of the binding.
+func (r *RPC) Init(meta bindings.Metadata) error {
+	r.metadata = rpcMetadata{
+		Protocol: protocolJSONRPC,
+		Timeout:  defaultTimeout,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &r.metadata)
+	if err != nil {
+		return fmt.Errorf("rpc binding error: %w", err)
+	}
+	if r.metadata.URL == "" {
+		return errors.New("rpc binding error: url is required")
+	}
+	if r.metadata.Protocol != protocolJSONRPC && r.metadata.Protocol != protocolXMLRPC {
+		return fmt.Errorf("rpc binding error: invalid protocol %s: must be %s or %s", r.metadata.Protocol, protocolJSONRPC, protocolXMLRPC)
+	}
+	if r.metadata.BearerToken != "" && r.metadata.Username != "" {
+		return errors.New("rpc binding error: bearerToken and username are mutually exclusive")
+	}
+
+	r.headers = http.Header{}
+	for _, h := range r.metadata.Headers {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("rpc binding error: invalid header %q: must be \"Name: value\"", h)
+		}
+		r.headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	r.client = &http.Client{Timeout: r.metadata.Timeout}
+
+	return nil
+}
+
+// Operations returns the operations supported by the RPC binding.
+func (r *RPC) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{CallOperation, NotifyOperation, BatchOperation}
+}
+
+// Invoke calls the method named by the "method" metadata with the params in the data, or sends a batch of calls.
+func (r *RPC) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case CallOperation, NotifyOperation:
+		method := req.Metadata[methodKey]
+		if method == "" {
+			return nil, fmt.Errorf("rpc binding error: the %s metadata is required", methodKey)
+		}
+		notify := req.Operation == NotifyOperation
+		if r.metadata.Protocol == protocolXMLRPC {
+			if notify {
+				return nil, errors.New("rpc binding error: XML-RPC doesn't support notifications")
+			}
+			data, err = r.callXMLRPC(ctx, method, req.Data)
+		} else {
+			data, err = r.callJSONRPC(ctx, method, req.Data, notify)
+		}
+	case BatchOperation:
+		var calls []BatchCall
+		if err = json.Unmarshal(req.Data, &calls); err != nil {
+			return nil, fmt.Errorf("rpc binding error: the data of batches must be an array of calls: %w", err)
+		}
+		var results []*BatchResult
+		if r.metadata.Protocol == protocolXMLRPC {
+			results, err = r.batchXMLRPC(ctx, calls)
+		} else {
+			results, err = r.batchJSONRPC(ctx, calls)
+		}
+		if err == nil {
+			data, err = json.Marshal(results)
+		}
+	default:
+		return nil, fmt.Errorf("rpc binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rpc binding error: %s failed: %w", req.Operation, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}, nil
+}
+
+func (r *RPC) nextID() uint64 {
+	return atomic.AddUint64(&r.lastID, 1)
+}
+
+// post sends a request to the server and returns the response body, and whether its status code is a success.
+func (r *RPC) post(ctx context.Context, contentType string, body []byte) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.metadata.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	for name, values := range r.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+	switch {
+	case r.metadata.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+r.metadata.BearerToken)
+	case r.metadata.Username != "":
+		req.SetBasicAuth(r.metadata.Username, r.metadata.Password)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read the response: %w", err)
+	}
+
+	return resBody, res.StatusCode >= 200 && res.StatusCode < 300, nil
+}
+
+func statusError(body []byte) error {
+	if len(body) > maxErrorBodySize {
+		body = body[:maxErrorBodySize]
+	}
+	return fmt.Errorf("unexpected response: %s", body)
+}
+
+// OperationsMetadata describes the operations of the RPC binding.
+func (r *RPC) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: CallOperation,
+			Description: "Calls the method named by the method metadata. The data holds the params: a JSON array or object for JSON-RPC, a JSON array for XML-RPC. " +
+				"Returns the result, or the error returned by the server.",
+			RequestMetadata:  []string{methodKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation:        NotifyOperation,
+			Description:      "Sends a JSON-RPC notification to the method named by the method metadata, without waiting for a result.",
+			RequestMetadata:  []string{methodKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+		{
+			Operation: BatchOperation,
+			Description: `Sends the array of calls in the data, each with a "method", "params" and "notification" flag, in a single request: ` +
+				"a JSON-RPC batch, or system.multicall for XML-RPC. Returns the array of results, each with a result or an error.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
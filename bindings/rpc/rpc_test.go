@@ -0,0 +1,309 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+type rpcServer struct {
+	*httptest.Server
+	requests chan *http.Request
+	bodies   chan string
+	status   int
+	response string
+}
+
+func startServer(t *testing.T) *rpcServer {
+	t.Helper()
+
+	s := &rpcServer{
+		requests: make(chan *http.Request, 1),
+		bodies:   make(chan string, 1),
+		status:   http.StatusOK,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.requests <- r
+		s.bodies <- string(body)
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.response))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func initRPC(t *testing.T, props map[string]string) *RPC {
+	t.Helper()
+
+	r := NewRPC(logger.NewLogger("test")).(*RPC)
+	require.NoError(t, r.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+
+	return r
+}
+
+func TestInit(t *testing.T) {
+	tests := map[string]map[string]string{
+		"no url":           {},
+		"invalid protocol": {"url": "http://localhost", "protocol": "grpc"},
+		"invalid header":   {"url": "http://localhost", "headers": "X-Key"},
+		"bearer and basic": {"url": "http://localhost", "bearerToken": "t", "username": "u"},
+	}
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRPC(logger.NewLogger("test"))
+			assert.Error(t, r.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+		})
+	}
+}
+
+func TestJSONRPC(t *testing.T) {
+	srv := startServer(t)
+	r := initRPC(t, map[string]string{
+		"url":         srv.URL,
+		"headers":     "X-Api-Key: k1, X-Tenant: acme",
+		"bearerToken": "token",
+	})
+
+	t.Run("call", func(t *testing.T) {
+		srv.response = `{"jsonrpc": "2.0", "result": {"sum": 3}, "id": 1}`
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Data:      []byte(`[1, 2]`),
+			Metadata:  map[string]string{methodKey: "add"},
+		})
+		require.NoError(t, err)
+
+		req := <-srv.requests
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		assert.Equal(t, "k1", req.Header.Get("X-Api-Key"))
+		assert.Equal(t, "acme", req.Header.Get("X-Tenant"))
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "add", "params": [1, 2], "id": 1}`, <-srv.bodies)
+		assert.JSONEq(t, `{"sum": 3}`, string(res.Data))
+		assert.Equal(t, "call", res.Metadata[bindings.ResponseMetadataOperation])
+	})
+
+	t.Run("null result", func(t *testing.T) {
+		srv.response = `{"jsonrpc": "2.0", "result": null, "id": 2}`
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Metadata:  map[string]string{methodKey: "ping"},
+		})
+		require.NoError(t, err)
+		<-srv.requests
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "ping", "id": 2}`, <-srv.bodies)
+		assert.Equal(t, "null", string(res.Data))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		srv.response, srv.status = `{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found", "data": "nope"}, "id": 3}`, http.StatusNotFound
+		defer func() { srv.status = http.StatusOK }()
+
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Metadata:  map[string]string{methodKey: "nope"},
+		})
+		<-srv.requests
+		<-srv.bodies
+		var rpcErr *Error
+		require.True(t, errors.As(err, &rpcErr), err)
+		assert.Equal(t, -32601, rpcErr.Code)
+		assert.Equal(t, "Method not found", rpcErr.Message)
+		assert.Equal(t, `"nope"`, string(rpcErr.Data))
+	})
+
+	t.Run("HTTP error", func(t *testing.T) {
+		srv.response, srv.status = "bad gateway", http.StatusBadGateway
+		defer func() { srv.status = http.StatusOK }()
+
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Metadata:  map[string]string{methodKey: "add"},
+		})
+		<-srv.requests
+		<-srv.bodies
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bad gateway")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Data:      []byte(`42`),
+			Metadata:  map[string]string{methodKey: "add"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		srv.response, srv.status = "", http.StatusNoContent
+		defer func() { srv.status = http.StatusOK }()
+
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: NotifyOperation,
+			Data:      []byte(`{"event": "started"}`),
+			Metadata:  map[string]string{methodKey: "log"},
+		})
+		require.NoError(t, err)
+		<-srv.requests
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "log", "params": {"event": "started"}}`, <-srv.bodies)
+		assert.Nil(t, res.Data)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		// Responses may come in any order, and calls without a response report an error.
+		srv.response = `[{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params"}, "id": 6},` +
+			`{"jsonrpc": "2.0", "result": 3, "id": 5}]`
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: BatchOperation,
+			Data: []byte(`[{"method": "add", "params": [1, 2]}, {"method": "log", "params": ["x"], "notification": true},` +
+				`{"method": "add", "params": ["a"]}, {"method": "slow"}]`),
+		})
+		require.NoError(t, err)
+		<-srv.requests
+		assert.JSONEq(t, `[{"jsonrpc": "2.0", "method": "add", "params": [1, 2], "id": 5}, {"jsonrpc": "2.0", "method": "log", "params": ["x"]},`+
+			`{"jsonrpc": "2.0", "method": "add", "params": ["a"], "id": 6}, {"jsonrpc": "2.0", "method": "slow", "id": 7}]`, <-srv.bodies)
+
+		var results []*BatchResult
+		require.NoError(t, json.Unmarshal(res.Data, &results))
+		require.Len(t, results, 4)
+		assert.Equal(t, "3", string(results[0].Result))
+		assert.Nil(t, results[1])
+		assert.Equal(t, -32602, results[2].Error.Code)
+		assert.Equal(t, jsonrpcInternalErrorCode, results[3].Error.Code)
+	})
+
+	t.Run("rejected batch", func(t *testing.T) {
+		srv.response = `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: BatchOperation,
+			Data:      []byte(`[{"method": "add"}]`),
+		})
+		<-srv.requests
+		<-srv.bodies
+		var rpcErr *Error
+		require.True(t, errors.As(err, &rpcErr), err)
+		assert.Equal(t, -32600, rpcErr.Code)
+	})
+}
+
+func TestXMLRPC(t *testing.T) {
+	srv := startServer(t)
+	r := initRPC(t, map[string]string{
+		"url":      srv.URL,
+		"protocol": "xmlrpc",
+		"username": "user",
+		"password": "pass",
+	})
+
+	t.Run("call", func(t *testing.T) {
+		srv.response = `<?xml version="1.0"?><methodResponse><params><param><value><struct>
+			<member><name>id</name><value><i4>7</i4></value></member>
+			<member><name>name</name><value>untyped</value></member>
+			<member><name>price</name><value><double>1.5</double></value></member>
+			<member><name>active</name><value><boolean>1</boolean></value></member>
+			<member><name>tags</name><value><array><data><value><string>a</string></value><value><nil/></value></data></array></value></member>
+			<member><name>created</name><value><dateTime.iso8601>20230102T03:04:05</dateTime.iso8601></value></member>
+		</struct></value></param></params></methodResponse>`
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Data:      []byte(`["a&b", 42, 10000000000, 1.5, true, null, {"$base64": "aGk="}, {"k": [1]}]`),
+			Metadata:  map[string]string{methodKey: "items.get"},
+		})
+		require.NoError(t, err)
+
+		req := <-srv.requests
+		assert.Equal(t, "text/xml", req.Header.Get("Content-Type"))
+		user, pass, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", pass)
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>items.get</methodName><params>`+
+			`<param><value><string>a&amp;b</string></value></param>`+
+			`<param><value><int>42</int></value></param>`+
+			`<param><value><i8>10000000000</i8></value></param>`+
+			`<param><value><double>1.5</double></value></param>`+
+			`<param><value><boolean>1</boolean></value></param>`+
+			`<param><value><nil/></value></param>`+
+			`<param><value><base64>aGk=</base64></value></param>`+
+			`<param><value><struct><member><name>k</name><value><array><data><value><int>1</int></value></data></array></value></member></struct></value></param>`+
+			`</params></methodCall>`, <-srv.bodies)
+		assert.JSONEq(t, `{"id": 7, "name": "untyped", "price": 1.5, "active": true, "tags": ["a", null], "created": {"$dateTime": "20230102T03:04:05"}}`, string(res.Data))
+	})
+
+	t.Run("fault", func(t *testing.T) {
+		srv.response = `<methodResponse><fault><value><struct>
+			<member><name>faultCode</name><value><int>4</int></value></member>
+			<member><name>faultString</name><value><string>Too many parameters.</string></value></member>
+		</struct></value></fault></methodResponse>`
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: CallOperation,
+			Metadata:  map[string]string{methodKey: "items.get"},
+		})
+		<-srv.requests
+		<-srv.bodies
+		var rpcErr *Error
+		require.True(t, errors.As(err, &rpcErr), err)
+		assert.Equal(t, 4, rpcErr.Code)
+		assert.Equal(t, "Too many parameters.", rpcErr.Message)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		srv.response = `<methodResponse><params><param><value><array><data>
+			<value><array><data><value><int>3</int></value></data></array></value>
+			<value><struct><member><name>faultCode</name><value><int>1</int></value></member><member><name>faultString</name><value>boom</value></member></struct></value>
+		</data></array></value></param></params></methodResponse>`
+		res, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: BatchOperation,
+			Data:      []byte(`[{"method": "add", "params": [1, 2]}, {"method": "fail"}]`),
+		})
+		require.NoError(t, err)
+		<-srv.requests
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>system.multicall</methodName><params><param><value><array><data>`+
+			`<value><struct><member><name>methodName</name><value><string>add</string></value></member>`+
+			`<member><name>params</name><value><array><data><value><int>1</int></value><value><int>2</int></value></data></array></value></member></struct></value>`+
+			`<value><struct><member><name>methodName</name><value><string>fail</string></value></member>`+
+			`<member><name>params</name><value><array><data></data></array></value></member></struct></value>`+
+			`</data></array></value></param></params></methodCall>`, <-srv.bodies)
+
+		var results []*BatchResult
+		require.NoError(t, json.Unmarshal(res.Data, &results))
+		require.Len(t, results, 2)
+		assert.Equal(t, "3", string(results[0].Result))
+		assert.Equal(t, &Error{Code: 1, Message: "boom"}, results[1].Error)
+	})
+
+	t.Run("notifications are not supported", func(t *testing.T) {
+		_, err := r.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: NotifyOperation,
+			Metadata:  map[string]string{methodKey: "log"},
+		})
+		assert.Error(t, err)
+	})
+}
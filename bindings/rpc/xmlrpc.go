@@ -0,0 +1,328 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	xmlrpcContentType = "text/xml"
+
+	// Keys of the JSON objects mapped to the XML-RPC types with no JSON equivalent.
+	base64Key   = "$base64"
+	dateTimeKey = "$dateTime"
+)
+
+// xmlrpcValue is an XML-RPC value; values with no type element are strings.
+type xmlrpcValue struct {
+	String   *string       `xml:"string"`
+	Int      *string       `xml:"int"`
+	I4       *string       `xml:"i4"`
+	I8       *string       `xml:"i8"`
+	Boolean  *string       `xml:"boolean"`
+	Double   *string       `xml:"double"`
+	DateTime *string       `xml:"dateTime.iso8601"`
+	Base64   *string       `xml:"base64"`
+	Nil      *struct{}     `xml:"nil"`
+	Array    *xmlrpcArray  `xml:"array"`
+	Struct   *xmlrpcStruct `xml:"struct"`
+	Text     string        `xml:",chardata"`
+}
+
+type xmlrpcArray struct {
+	Values []xmlrpcValue `xml:"data>value"`
+}
+
+type xmlrpcStruct struct {
+	Members []xmlrpcMember `xml:"member"`
+}
+
+type xmlrpcMember struct {
+	Name  string      `xml:"name"`
+	Value xmlrpcValue `xml:"value"`
+}
+
+type xmlrpcResponse struct {
+	XMLName xml.Name      `xml:"methodResponse"`
+	Params  []xmlrpcValue `xml:"params>param>value"`
+	Fault   *xmlrpcValue  `xml:"fault>value"`
+}
+
+func (r *RPC) callXMLRPC(ctx context.Context, method string, params []byte) ([]byte, error) {
+	var args []any
+	if len(bytes.TrimSpace(params)) > 0 {
+		if err := decodeJSON(params, &args); err != nil {
+			return nil, fmt.Errorf("the params of XML-RPC calls must be a JSON array: %w", err)
+		}
+	}
+
+	result, err := r.doXMLRPC(ctx, method, args)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// batchXMLRPC sends the calls with system.multicall, which returns the result of each call wrapped in an array, or a fault.
+func (r *RPC) batchXMLRPC(ctx context.Context, calls []BatchCall) ([]*BatchResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("empty batch")
+	}
+
+	multicall := make([]any, len(calls))
+	for i, call := range calls {
+		if call.Notification {
+			return nil, fmt.Errorf("call %d: XML-RPC doesn't support notifications", i)
+		}
+		var args []any
+		if len(call.Params) > 0 {
+			if err := decodeJSON(call.Params, &args); err != nil {
+				return nil, fmt.Errorf("call %d: the params of XML-RPC calls must be a JSON array: %w", i, err)
+			}
+		}
+		if args == nil {
+			args = []any{}
+		}
+		multicall[i] = map[string]any{"methodName": call.Method, "params": args}
+	}
+
+	result, err := r.doXMLRPC(ctx, "system.multicall", []any{multicall})
+	if err != nil {
+		return nil, err
+	}
+	items, ok := result.([]any)
+	if !ok || len(items) != len(calls) {
+		return nil, errors.New("invalid system.multicall response: expected an array with the result of each call")
+	}
+
+	results := make([]*BatchResult, len(calls))
+	for i, item := range items {
+		switch item := item.(type) {
+		case []any:
+			if len(item) != 1 {
+				return nil, fmt.Errorf("invalid system.multicall response for call %d", i)
+			}
+			raw, err := json.Marshal(item[0])
+			if err != nil {
+				return nil, err
+			}
+			results[i] = &BatchResult{Result: raw}
+		case map[string]any:
+			results[i] = &BatchResult{Error: faultError(item)}
+		default:
+			return nil, fmt.Errorf("invalid system.multicall response for call %d", i)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *RPC) doXMLRPC(ctx context.Context, method string, args []any) (any, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>`)
+	xml.EscapeText(&body, []byte(method))
+	body.WriteString("</methodName><params>")
+	for i, arg := range args {
+		body.WriteString("<param>")
+		if err := writeXMLRPCValue(&body, arg); err != nil {
+			return nil, fmt.Errorf("param %d: %w", i, err)
+		}
+		body.WriteString("</param>")
+	}
+	body.WriteString("</params></methodCall>")
+
+	resBody, ok, err := r.post(ctx, xmlrpcContentType, body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, statusError(resBody)
+	}
+
+	var res xmlrpcResponse
+	if err = xml.Unmarshal(resBody, &res); err != nil {
+		return nil, fmt.Errorf("invalid XML-RPC response: %w", err)
+	}
+	if res.Fault != nil {
+		fault, err := res.Fault.toJSON()
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML-RPC fault: %w", err)
+		}
+		members, _ := fault.(map[string]any)
+		return nil, faultError(members)
+	}
+	if len(res.Params) != 1 {
+		return nil, errors.New("invalid XML-RPC response: expected a single param")
+	}
+
+	return res.Params[0].toJSON()
+}
+
+// faultError maps the faultCode and faultString members of an XML-RPC fault to an Error.
+func faultError(members map[string]any) *Error {
+	e := &Error{}
+	switch code := members["faultCode"].(type) {
+	case int64:
+		e.Code = int(code)
+	case float64:
+		e.Code = int(code)
+	}
+	e.Message, _ = members["faultString"].(string)
+	return e
+}
+
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// writeXMLRPCValue writes the value element of a JSON value decoded with numbers as json.Number.
+// Objects with a single "$base64" or "$dateTime" member are mapped to base64 and dateTime.iso8601 values.
+func writeXMLRPCValue(buf *bytes.Buffer, v any) error {
+	buf.WriteString("<value>")
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("<nil/>")
+	case string:
+		buf.WriteString("<string>")
+		xml.EscapeText(buf, []byte(v))
+		buf.WriteString("</string>")
+	case bool:
+		if v {
+			buf.WriteString("<boolean>1</boolean>")
+		} else {
+			buf.WriteString("<boolean>0</boolean>")
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				buf.WriteString("<int>" + v.String() + "</int>")
+			} else {
+				buf.WriteString("<i8>" + v.String() + "</i8>")
+			}
+		} else {
+			buf.WriteString("<double>" + v.String() + "</double>")
+		}
+	case []any:
+		buf.WriteString("<array><data>")
+		for _, item := range v {
+			if err := writeXMLRPCValue(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("</data></array>")
+	case map[string]any:
+		if s, ok := v[base64Key].(string); ok && len(v) == 1 {
+			buf.WriteString("<base64>")
+			xml.EscapeText(buf, []byte(s))
+			buf.WriteString("</base64>")
+			break
+		}
+		if s, ok := v[dateTimeKey].(string); ok && len(v) == 1 {
+			buf.WriteString("<dateTime.iso8601>")
+			xml.EscapeText(buf, []byte(s))
+			buf.WriteString("</dateTime.iso8601>")
+			break
+		}
+		// Sort the members for deterministic requests.
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		buf.WriteString("<struct>")
+		for _, name := range names {
+			buf.WriteString("<member><name>")
+			xml.EscapeText(buf, []byte(name))
+			buf.WriteString("</name>")
+			if err := writeXMLRPCValue(buf, v[name]); err != nil {
+				return err
+			}
+			buf.WriteString("</member>")
+		}
+		buf.WriteString("</struct>")
+	default:
+		return fmt.Errorf("unsupported value of type %T", v)
+	}
+	buf.WriteString("</value>")
+
+	return nil
+}
+
+// toJSON maps an XML-RPC value to a JSON value, the reverse of writeXMLRPCValue.
+func (v *xmlrpcValue) toJSON() (any, error) {
+	switch {
+	case v.String != nil:
+		return *v.String, nil
+	case v.Int != nil, v.I4 != nil, v.I8 != nil:
+		s := v.Int
+		if s == nil {
+			s = v.I4
+		}
+		if s == nil {
+			s = v.I8
+		}
+		return strconv.ParseInt(strings.TrimSpace(*s), 10, 64)
+	case v.Boolean != nil:
+		switch strings.TrimSpace(*v.Boolean) {
+		case "1":
+			return true, nil
+		case "0":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid boolean %s", *v.Boolean)
+		}
+	case v.Double != nil:
+		return strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+	case v.DateTime != nil:
+		return map[string]any{dateTimeKey: strings.TrimSpace(*v.DateTime)}, nil
+	case v.Base64 != nil:
+		return map[string]any{base64Key: strings.TrimSpace(*v.Base64)}, nil
+	case v.Nil != nil:
+		return nil, nil
+	case v.Array != nil:
+		items := make([]any, len(v.Array.Values))
+		for i := range v.Array.Values {
+			item, err := v.Array.Values[i].toJSON()
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case v.Struct != nil:
+		members := make(map[string]any, len(v.Struct.Members))
+		for i := range v.Struct.Members {
+			value, err := v.Struct.Members[i].Value.toJSON()
+			if err != nil {
+				return nil, err
+			}
+			members[v.Struct.Members[i].Name] = value
+		}
+		return members, nil
+	default:
+		return v.Text, nil
+	}
+}
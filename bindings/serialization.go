@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/serialization"
+)
+
+// serializingOutputBinding converts JSON request payloads to Avro or Protobuf before invoking an output binding.
+type serializingOutputBinding struct {
+	outputForwarder
+
+	serializer serialization.Serializer
+}
+
+// NewSerializingOutputBinding wraps an OutputBinding so that JSON request payloads are converted to the format set in the "serializationFormat" metadata.
+// The schema is set inline, or for Avro fetched from a Confluent Schema Registry.
+// Requests without data aren't converted. Serialization is disabled when no format is set.
+func NewSerializingOutputBinding(inner OutputBinding) OutputBinding {
+	return exposeOptionalOutput(&serializingOutputBinding{
+		outputForwarder: outputForwarder{inner},
+	}, inner)
+}
+
+func (b *serializingOutputBinding) Init(metadata Metadata) error {
+	m, err := serialization.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("binding serialization error: %w", err)
+	}
+	b.serializer, err = serialization.NewSerializer(context.Background(), m)
+	if err != nil {
+		return fmt.Errorf("binding serialization error: %w", err)
+	}
+
+	return b.OutputBinding.Init(metadata)
+}
+
+func (b *serializingOutputBinding) Invoke(ctx context.Context, req *InvokeRequest) (*InvokeResponse, error) {
+	if b.serializer != nil && len(req.Data) > 0 {
+		data, err := b.serializer.Serialize(req.Data)
+		if err != nil {
+			return nil, fmt.Errorf("binding serialization error: %w", err)
+		}
+		serialized := *req
+		serialized.Data = data
+		req = &serialized
+	}
+
+	return b.OutputBinding.Invoke(ctx, req)
+}
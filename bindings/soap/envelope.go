@@ -0,0 +1,426 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package soap
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	xsiNS = "http://www.w3.org/2001/XMLSchema-instance"
+
+	// Prefixes of the keys of JSON objects mapped to XML attributes and text.
+	attrPrefix = "@"
+	textKey    = "#text"
+)
+
+// Fault is a SOAP fault returned by the service.
+type Fault struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+	// Detail is the content of the detail element, mapped to JSON like responses.
+	Detail any `json:"detail,omitempty"`
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("SOAP fault %s: %s", f.Code, f.Reason)
+}
+
+// jsonField is a member of a JSON object, which are kept in order as XML sequences are ordered.
+type jsonField struct {
+	Key   string
+	Value any
+}
+
+type jsonObject []jsonField
+
+// decodeJSON decodes a JSON document into jsonObject, []any, json.Number, string, bool and nil values.
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid JSON: unexpected data after the top-level value")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+			v, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonField{Key: keyTok.(string), Value: v})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			v, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = dec.Token()
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
+
+// xmlWriter writes XML in its exclusive canonical form (without comments), so that the elements it writes can be signed as is.
+// Callers write the namespace declarations of an element first, sorted by prefix, then its attributes, sorted by namespace and name.
+type xmlWriter struct {
+	bytes.Buffer
+}
+
+func (w *xmlWriter) start(name string, attrs ...string) {
+	w.WriteByte('<')
+	w.WriteString(name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		w.WriteByte(' ')
+		w.WriteString(attrs[i])
+		w.WriteString(`="`)
+		w.WriteString(attrEscaper.Replace(attrs[i+1]))
+		w.WriteByte('"')
+	}
+	w.WriteByte('>')
+}
+
+func (w *xmlWriter) end(name string) {
+	w.WriteString("</")
+	w.WriteString(name)
+	w.WriteByte('>')
+}
+
+func (w *xmlWriter) text(s string) {
+	w.WriteString(textEscaper.Replace(s))
+}
+
+// element writes an element with only text content.
+func (w *xmlWriter) element(name, text string, attrs ...string) {
+	w.start(name, attrs...)
+	w.text(text)
+	w.end(name)
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+// writeRequest writes the element wrapping the request of op, with the content mapped from the JSON value data:
+// members of objects are mapped to child elements, arrays to repeated elements, null to nil elements,
+// and members whose key is prefixed by "@", or is "#text", to the attributes and the text of the element.
+func writeRequest(w *xmlWriter, op *operation, data []byte) error {
+	var v any = jsonObject{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		var err error
+		v, err = decodeJSON(data)
+		if err != nil {
+			return err
+		}
+	}
+	obj, ok := v.(jsonObject)
+	if !ok {
+		return errors.New("the request data must be a JSON object")
+	}
+
+	name, nsAttrs := op.Wrapper.Local, []string{"xmlns", op.Wrapper.Space}
+	if !op.Qualified {
+		// Set a prefix on the wrapper only, so that its children are in no namespace.
+		name, nsAttrs = "ns0:"+op.Wrapper.Local, []string{"xmlns:ns0", op.Wrapper.Space}
+	}
+	return writeJSONElement(w, name, obj, nsAttrs)
+}
+
+func writeJSONElement(w *xmlWriter, name string, v any, nsAttrs []string) error {
+	switch v := v.(type) {
+	case nil:
+		w.start(name, append(nsAttrs, "xmlns:xsi", xsiNS, "xsi:nil", "true")...)
+		w.end(name)
+	case jsonObject:
+		attrs := jsonObject{}
+		for _, f := range v {
+			if strings.HasPrefix(f.Key, attrPrefix) {
+				attrs = append(attrs, jsonField{Key: strings.TrimPrefix(f.Key, attrPrefix), Value: f.Value})
+			}
+		}
+		sort.SliceStable(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+		startAttrs := nsAttrs
+		for _, a := range attrs {
+			if !isXMLName(a.Key) {
+				return fmt.Errorf("invalid attribute name %q", a.Key)
+			}
+			s, err := jsonScalar(a.Value)
+			if err != nil {
+				return fmt.Errorf("attribute %s: %w", a.Key, err)
+			}
+			startAttrs = append(startAttrs, a.Key, s)
+		}
+
+		w.start(name, startAttrs...)
+		for _, f := range v {
+			switch {
+			case strings.HasPrefix(f.Key, attrPrefix):
+				continue
+			case f.Key == textKey:
+				s, err := jsonScalar(f.Value)
+				if err != nil {
+					return fmt.Errorf("%s of %s: %w", textKey, name, err)
+				}
+				w.text(s)
+				continue
+			case !isXMLName(f.Key):
+				return fmt.Errorf("invalid element name %q", f.Key)
+			}
+			items, ok := f.Value.([]any)
+			if !ok {
+				items = []any{f.Value}
+			}
+			for _, item := range items {
+				if _, ok := item.([]any); ok {
+					return fmt.Errorf("element %s: nested arrays can't be mapped to XML", f.Key)
+				}
+				if err := writeJSONElement(w, f.Key, item, nil); err != nil {
+					return err
+				}
+			}
+		}
+		w.end(name)
+	default:
+		s, err := jsonScalar(v)
+		if err != nil {
+			return fmt.Errorf("element %s: %w", name, err)
+		}
+		w.start(name, nsAttrs...)
+		w.text(s)
+		w.end(name)
+	}
+
+	return nil
+}
+
+func jsonScalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case nil:
+		return "", nil
+	default:
+		return "", errors.New("expected a string, number or boolean")
+	}
+}
+
+func isXMLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 0x7f:
+		case i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// xmlNode is an element of a parsed XML document.
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Children []*xmlNode
+	Text     string
+}
+
+func parseXML(r io.Reader) (*xmlNode, error) {
+	dec := xml.NewDecoder(r)
+	var (
+		stack []*xmlNode
+		root  *xmlNode
+	)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{Name: tok.Name, Attrs: tok.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(tok)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("empty XML document")
+	}
+
+	return root, nil
+}
+
+func (n *xmlNode) child(local string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// toJSON maps the content of an element to a JSON value, the reverse of writeJSONElement:
+// elements with only text are mapped to strings, and the others to objects keyed by the local names of their children.
+// Namespaces are dropped, and all the values are strings as the schema of the elements is not known.
+func (n *xmlNode) toJSON() any {
+	obj := map[string]any{}
+	for _, a := range n.Attrs {
+		switch {
+		case a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns"):
+			continue
+		case a.Name.Space == xsiNS && a.Name.Local == "nil" && (a.Value == "true" || a.Value == "1"):
+			return nil
+		case a.Name.Space == xsiNS:
+			continue
+		}
+		obj[attrPrefix+a.Name.Local] = a.Value
+	}
+	text := strings.TrimSpace(n.Text)
+	if len(obj) == 0 && len(n.Children) == 0 {
+		return text
+	}
+
+	for _, c := range n.Children {
+		v := c.toJSON()
+		switch prev := obj[c.Name.Local].(type) {
+		case nil:
+			if _, ok := obj[c.Name.Local]; ok {
+				obj[c.Name.Local] = []any{prev, v}
+			} else {
+				obj[c.Name.Local] = v
+			}
+		case []any:
+			obj[c.Name.Local] = append(prev, v)
+		default:
+			obj[c.Name.Local] = []any{prev, v}
+		}
+	}
+	if text != "" {
+		obj[textKey] = text
+	}
+
+	return obj
+}
+
+// parseEnvelope returns the first element in the body of a SOAP envelope, or nil if the body is empty.
+// Faults are returned as a *Fault error.
+func parseEnvelope(r io.Reader) (*xmlNode, error) {
+	env, err := parseXML(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOAP response: %w", err)
+	}
+	if env.Name.Local != "Envelope" || (env.Name.Space != soap11.envelopeNS() && env.Name.Space != soap12.envelopeNS()) {
+		return nil, fmt.Errorf("invalid SOAP response: unexpected root element %s", env.Name.Local)
+	}
+	body := env.child("Body")
+	if body == nil {
+		return nil, errors.New("invalid SOAP response: no body")
+	}
+	if len(body.Children) == 0 {
+		return nil, nil
+	}
+
+	content := body.Children[0]
+	if content.Name.Local == "Fault" && content.Name.Space == env.Name.Space {
+		return nil, parseFault(content, env.Name.Space == soap12.envelopeNS())
+	}
+
+	return content, nil
+}
+
+func parseFault(n *xmlNode, isSOAP12 bool) *Fault {
+	f := &Fault{}
+	var detail *xmlNode
+	if isSOAP12 {
+		if code := n.child("Code"); code != nil {
+			if value := code.child("Value"); value != nil {
+				f.Code = strings.TrimSpace(value.Text)
+			}
+		}
+		if reason := n.child("Reason"); reason != nil {
+			if text := reason.child("Text"); text != nil {
+				f.Reason = strings.TrimSpace(text.Text)
+			}
+		}
+		detail = n.child("Detail")
+	} else {
+		if code := n.child("faultcode"); code != nil {
+			f.Code = strings.TrimSpace(code.Text)
+		}
+		if reason := n.child("faultstring"); reason != nil {
+			f.Reason = strings.TrimSpace(reason.Text)
+		}
+		detail = n.child("detail")
+	}
+	if detail != nil && (len(detail.Children) > 0 || strings.TrimSpace(detail.Text) != "") {
+		f.Detail = detail.toJSON()
+	}
+
+	return f
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultTimestampTTL = 5 * time.Minute
+
+	// Size of the response body included in the errors of non-SOAP responses.
+	maxErrorBodySize = 512
+
+	// keys from request's metadata.
+	soapOperationKey = "soapOperation"
+
+	// keys from response's metadata.
+	respSOAPOperationKey = "soapOperation"
+	respStatusCodeKey    = "statusCode"
+
+	InvokeOperation   bindings.OperationKind = "invoke"
+	DescribeOperation bindings.OperationKind = "describe"
+)
+
+// SOAP is an output binding invoking the operations of a SOAP service described by a WSDL.
+type SOAP struct {
+	metadata soapMetadata
+	service  *service
+	security *wsSecurity
+	client   *http.Client
+	logger   logger.Logger
+}
+
+type soapMetadata struct {
+	// URL of the WSDL, or path of a local file.
+	WSDLURL string `mapstructure:"wsdlURL"`
+	// Address of the service, overriding the one in the WSDL.
+	Endpoint string `mapstructure:"endpoint"`
+	// Name of the port of the WSDL service to use; defaults to the first SOAP port.
+	Port    string        `mapstructure:"port"`
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// WS-Security username token.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// PasswordType is "text" or "digest".
+	PasswordType string `mapstructure:"passwordType"`
+	// WS-Security signature of the body, with PEM-encoded RSA certificate and key.
+	SigningCertificate string `mapstructure:"signingCertificate"`
+	SigningKey         string `mapstructure:"signingKey"`
+	// TimestampTTL is the lifetime of the requests' WS-Security timestamp.
+	TimestampTTL time.Duration `mapstructure:"timestampTTL"`
+}
+
+// OperationInfo describes an operation of the WSDL.
+type OperationInfo struct {
+	Name       string `json:"name"`
+	SOAPAction string `json:"soapAction,omitempty"`
+	Style      string `json:"style"`
+	Namespace  string `json:"namespace"`
+	Element    string `json:"element"`
+}
+
+// NewSOAP returns a new SOAP binding.
+func NewSOAP(logger logger.Logger) bindings.OutputBinding {
+	return &SOAP{logger: logger}
+}
+
+// Init loads the WSDL and parses the metadata of the binding.
+func (s *SOAP) Init(meta bindings.Metadata) error {
+	s.metadata = soapMetadata{
+		Timeout:      defaultTimeout,
+		TimestampTTL: defaultTimestampTTL,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &s.metadata)
+	if err != nil {
+		return fmt.Errorf("soap binding error: %w", err)
+	}
+	if s.metadata.WSDLURL == "" {
+		return errors.New("soap binding error: wsdlURL is required")
+	}
+
+	s.security, err = newWSSecurity(s.metadata)
+	if err != nil {
+		return fmt.Errorf("soap binding error: %w", err)
+	}
+	s.client = &http.Client{Timeout: s.metadata.Timeout}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.metadata.Timeout)
+	defer cancel()
+	wsdl, err := s.loadWSDL(ctx)
+	if err != nil {
+		return fmt.Errorf("soap binding error: failed to load WSDL: %w", err)
+	}
+	s.service, err = parseWSDL(wsdl, s.metadata.Port)
+	if err != nil {
+		return fmt.Errorf("soap binding error: %w", err)
+	}
+	if s.metadata.Endpoint != "" {
+		s.service.Endpoint = s.metadata.Endpoint
+	}
+	if s.service.Endpoint == "" {
+		return errors.New("soap binding error: the WSDL has no address for the port: endpoint is required")
+	}
+	s.logger.Debugf("soap: loaded WSDL with operations %v", s.service.names())
+
+	return nil
+}
+
+func (s *SOAP) loadWSDL(ctx context.Context) ([]byte, error) {
+	u, err := url.Parse(s.metadata.WSDLURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return os.ReadFile(strings.TrimPrefix(s.metadata.WSDLURL, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.metadata.WSDLURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// Operations returns the operations supported by the SOAP binding.
+func (s *SOAP) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{InvokeOperation, DescribeOperation}
+}
+
+// Invoke calls the operation named by the "soapOperation" metadata, or describes the operations of the WSDL.
+func (s *SOAP) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var (
+		res *bindings.InvokeResponse
+		err error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case InvokeOperation:
+		res, err = s.invoke(ctx, req)
+	case DescribeOperation:
+		res, err = s.describe()
+	default:
+		return nil, fmt.Errorf("soap binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("soap binding error: %s failed: %w", req.Operation, err)
+	}
+
+	if res.Metadata == nil {
+		res.Metadata = map[string]string{}
+	}
+	res.Metadata[bindings.ResponseMetadataOperation] = string(req.Operation)
+
+	return res, nil
+}
+
+func (s *SOAP) invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	name := req.Metadata[soapOperationKey]
+	if name == "" {
+		return nil, fmt.Errorf("the %s metadata is required", soapOperationKey)
+	}
+	op, ok := s.service.Operations[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown SOAP operation %s: the WSDL defines %s", name, strings.Join(s.service.names(), ", "))
+	}
+
+	envelope, err := s.envelope(op, req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.service.Endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return nil, err
+	}
+	if s.service.Version == soap12 {
+		contentType := "application/soap+xml; charset=utf-8"
+		if op.Action != "" {
+			contentType += `; action="` + op.Action + `"`
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+	} else {
+		httpReq.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		httpReq.Header.Set("SOAPAction", `"`+op.Action+`"`)
+	}
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %w", err)
+	}
+
+	// Faults are usually returned with status code 500: parse the envelope first.
+	content, err := parseEnvelope(bytes.NewReader(body))
+	var fault *Fault
+	if errors.As(err, &fault) {
+		return nil, fault
+	}
+	if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
+		if len(body) > maxErrorBodySize {
+			body = body[:maxErrorBodySize]
+		}
+		return nil, fmt.Errorf("unexpected status code %d: %s", httpRes.StatusCode, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			respSOAPOperationKey: op.Name,
+			respStatusCodeKey:    fmt.Sprint(httpRes.StatusCode),
+		},
+	}
+	if content != nil {
+		res.Data, err = json.Marshal(content.toJSON())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// envelope returns the SOAP envelope of a request to op.
+func (s *SOAP) envelope(op *operation, data []byte) ([]byte, error) {
+	envNS := s.service.Version.envelopeNS()
+
+	// The body is written first, as its canonical form is signed in the header.
+	var body xmlWriter
+	if s.security.signing() {
+		body.start("soap:Body", "xmlns:soap", envNS, "xmlns:wsu", wsuNS, "wsu:Id", bodyID)
+	} else {
+		body.start("soap:Body")
+	}
+	if err := writeRequest(&body, op, data); err != nil {
+		return nil, err
+	}
+	body.end("soap:Body")
+
+	var w xmlWriter
+	w.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	w.start("soap:Envelope", "xmlns:soap", envNS)
+	if s.security.enabled() {
+		w.start("soap:Header")
+		if err := s.security.writeHeader(&w, "soap", body.Bytes()); err != nil {
+			return nil, err
+		}
+		w.end("soap:Header")
+	}
+	w.Write(body.Bytes())
+	w.end("soap:Envelope")
+
+	return w.Bytes(), nil
+}
+
+func (s *SOAP) describe() (*bindings.InvokeResponse, error) {
+	ops := make([]OperationInfo, 0, len(s.service.Operations))
+	for _, name := range s.service.names() {
+		op := s.service.Operations[name]
+		ops = append(ops, OperationInfo{
+			Name:       op.Name,
+			SOAPAction: op.Action,
+			Style:      op.Style,
+			Namespace:  op.Wrapper.Space,
+			Element:    op.Wrapper.Local,
+		})
+	}
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bindings.InvokeResponse{Data: data}, nil
+}
+
+// OperationsMetadata describes the operations of the SOAP binding.
+func (s *SOAP) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: InvokeOperation,
+			Description: "Calls the WSDL operation named by soapOperation. The data is a JSON object mapped to the children of the request element: " +
+				`arrays are mapped to repeated elements, and keys prefixed by "@" or "#text" to attributes and text. ` +
+				"Returns the response element mapped the same way, or a SOAP fault as an error.",
+			RequestMetadata:  []string{soapOperationKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respSOAPOperationKey, respStatusCodeKey},
+		},
+		{
+			Operation:        DescribeOperation,
+			Description:      "Returns the operations defined by the WSDL.",
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
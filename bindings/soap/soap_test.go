@@ -0,0 +1,473 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package soap
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const testWSDL = `<?xml version="1.0" encoding="UTF-8"?>
+<wsdl:definitions xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+    xmlns:soap12="http://schemas.xmlsoap.org/wsdl/soap12/"
+    xmlns:xs="http://www.w3.org/2001/XMLSchema"
+    xmlns:tns="http://example.com/orders"
+    targetNamespace="http://example.com/orders">
+  <wsdl:types>
+    <xs:schema targetNamespace="http://example.com/orders" elementFormDefault="qualified">
+      <xs:element name="GetOrder"/>
+      <xs:element name="GetOrderResponse"/>
+    </xs:schema>
+  </wsdl:types>
+  <wsdl:message name="GetOrderRequest"><wsdl:part name="parameters" element="tns:GetOrder"/></wsdl:message>
+  <wsdl:message name="GetOrderResponse"><wsdl:part name="parameters" element="tns:GetOrderResponse"/></wsdl:message>
+  <wsdl:message name="CancelRequest"><wsdl:part name="id" type="xs:string"/></wsdl:message>
+  <wsdl:portType name="OrdersPortType">
+    <wsdl:operation name="GetOrder">
+      <wsdl:input message="tns:GetOrderRequest"/>
+      <wsdl:output message="tns:GetOrderResponse"/>
+    </wsdl:operation>
+    <wsdl:operation name="Cancel">
+      <wsdl:input message="tns:CancelRequest"/>
+    </wsdl:operation>
+  </wsdl:portType>
+  <wsdl:binding name="OrdersSoap" type="tns:OrdersPortType">
+    <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <wsdl:operation name="GetOrder">
+      <soap:operation soapAction="http://example.com/orders/GetOrder"/>
+      <wsdl:input><soap:body use="literal"/></wsdl:input>
+    </wsdl:operation>
+    <wsdl:operation name="Cancel">
+      <soap:operation soapAction="http://example.com/orders/Cancel" style="rpc"/>
+      <wsdl:input><soap:body use="literal" namespace="http://example.com/orders/rpc"/></wsdl:input>
+    </wsdl:operation>
+  </wsdl:binding>
+  <wsdl:binding name="OrdersSoap12" type="tns:OrdersPortType">
+    <soap12:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <wsdl:operation name="GetOrder">
+      <soap12:operation soapAction="http://example.com/orders/GetOrder"/>
+      <wsdl:input><soap12:body use="literal"/></wsdl:input>
+    </wsdl:operation>
+    <wsdl:operation name="Cancel">
+      <soap12:operation soapAction="http://example.com/orders/Cancel" style="rpc"/>
+      <wsdl:input><soap12:body use="literal"/></wsdl:input>
+    </wsdl:operation>
+  </wsdl:binding>
+  <wsdl:service name="Orders">
+    <wsdl:port name="OrdersSoap" binding="tns:OrdersSoap"><soap:address location="http://orders.example.com/soap"/></wsdl:port>
+    <wsdl:port name="OrdersSoap12" binding="tns:OrdersSoap12"><soap12:address location="http://orders.example.com/soap12"/></wsdl:port>
+  </wsdl:service>
+</wsdl:definitions>`
+
+const getOrderResponse = `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+  <s:Body>
+    <GetOrderResponse xmlns="http://example.com/orders" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+      <order id="42">
+        <status>shipped</status>
+        <item>book</item>
+        <item>pen</item>
+        <note xsi:nil="true"/>
+      </order>
+    </GetOrderResponse>
+  </s:Body>
+</s:Envelope>`
+
+const faultResponse = `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+  <s:Body>
+    <s:Fault>
+      <faultcode>s:Client</faultcode>
+      <faultstring>Order not found</faultstring>
+      <detail><orderId>7</orderId></detail>
+    </s:Fault>
+  </s:Body>
+</s:Envelope>`
+
+type soapServer struct {
+	*httptest.Server
+	requests chan *http.Request
+	bodies   chan []byte
+	response string
+	status   int
+}
+
+func startServer(t *testing.T) *soapServer {
+	t.Helper()
+
+	s := &soapServer{
+		requests: make(chan *http.Request, 1),
+		bodies:   make(chan []byte, 1),
+		response: getOrderResponse,
+		status:   http.StatusOK,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(testWSDL))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.requests <- r
+		s.bodies <- body
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.response))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func initSOAP(t *testing.T, props map[string]string) *SOAP {
+	t.Helper()
+
+	s := NewSOAP(logger.NewLogger("test")).(*SOAP)
+	err := s.Init(bindings.Metadata{Base: metadata.Base{Properties: props}})
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestParseWSDL(t *testing.T) {
+	t.Run("first SOAP port", func(t *testing.T) {
+		svc, err := parseWSDL([]byte(testWSDL), "")
+		require.NoError(t, err)
+		assert.Equal(t, "http://orders.example.com/soap", svc.Endpoint)
+		assert.Equal(t, soap11, svc.Version)
+		assert.Equal(t, []string{"Cancel", "GetOrder"}, svc.names())
+
+		getOrder := svc.Operations["GetOrder"]
+		assert.Equal(t, "http://example.com/orders/GetOrder", getOrder.Action)
+		assert.Equal(t, "document", getOrder.Style)
+		assert.Equal(t, "http://example.com/orders", getOrder.Wrapper.Space)
+		assert.Equal(t, "GetOrder", getOrder.Wrapper.Local)
+		assert.True(t, getOrder.Qualified)
+
+		cancel := svc.Operations["Cancel"]
+		assert.Equal(t, "rpc", cancel.Style)
+		assert.Equal(t, "http://example.com/orders/rpc", cancel.Wrapper.Space)
+		assert.Equal(t, "Cancel", cancel.Wrapper.Local)
+		assert.False(t, cancel.Qualified)
+	})
+
+	t.Run("named port", func(t *testing.T) {
+		svc, err := parseWSDL([]byte(testWSDL), "OrdersSoap12")
+		require.NoError(t, err)
+		assert.Equal(t, "http://orders.example.com/soap12", svc.Endpoint)
+		assert.Equal(t, soap12, svc.Version)
+		// Without a namespace on soap:body, rpc wrappers are in the target namespace.
+		assert.Equal(t, "http://example.com/orders", svc.Operations["Cancel"].Wrapper.Space)
+	})
+
+	t.Run("unknown port", func(t *testing.T) {
+		_, err := parseWSDL([]byte(testWSDL), "Nope")
+		assert.Error(t, err)
+	})
+
+	t.Run("not a WSDL", func(t *testing.T) {
+		_, err := parseWSDL([]byte(`<html></html>`), "")
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteRequest(t *testing.T) {
+	op := &operation{
+		Wrapper:   xml.Name{Space: "http://example.com/orders", Local: "GetOrder"},
+		Qualified: true,
+	}
+
+	t.Run("qualified", func(t *testing.T) {
+		var w xmlWriter
+		err := writeRequest(&w, op, []byte(`{"id": 42, "@version": "2", "lines": [{"sku": "a&b"}, {"sku": "<c>"}], "gift": false, "note": null}`))
+		require.NoError(t, err)
+		assert.Equal(t, `<GetOrder xmlns="http://example.com/orders" version="2"><id>42</id>`+
+			`<lines><sku>a&amp;b</sku></lines><lines><sku>&lt;c&gt;</sku></lines><gift>false</gift>`+
+			`<note xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"></note></GetOrder>`, w.String())
+	})
+
+	t.Run("unqualified", func(t *testing.T) {
+		var w xmlWriter
+		err := writeRequest(&w, &operation{Wrapper: op.Wrapper}, []byte(`{"id": {"#text": "42", "@type": "internal"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, `<ns0:GetOrder xmlns:ns0="http://example.com/orders"><id type="internal">42</id></ns0:GetOrder>`, w.String())
+	})
+
+	t.Run("empty data", func(t *testing.T) {
+		var w xmlWriter
+		require.NoError(t, writeRequest(&w, op, nil))
+		assert.Equal(t, `<GetOrder xmlns="http://example.com/orders"></GetOrder>`, w.String())
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		for _, data := range []string{`[1]`, `{"a b": 1}`, `{"a": [[1]]}`, `{"a": 1`, `{"@a": {}}`} {
+			var w xmlWriter
+			assert.Error(t, writeRequest(&w, op, []byte(data)), data)
+		}
+	})
+}
+
+func TestInvoke(t *testing.T) {
+	srv := startServer(t)
+	s := initSOAP(t, map[string]string{
+		"wsdlURL":  srv.URL + "/wsdl",
+		"endpoint": srv.URL + "/soap",
+	})
+
+	t.Run("document operation", func(t *testing.T) {
+		res, err := s.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: InvokeOperation,
+			Data:      []byte(`{"id": "42"}`),
+			Metadata:  map[string]string{soapOperationKey: "GetOrder"},
+		})
+		require.NoError(t, err)
+
+		r := <-srv.requests
+		assert.Equal(t, "/soap", r.URL.Path)
+		assert.Equal(t, `"http://example.com/orders/GetOrder"`, r.Header.Get("SOAPAction"))
+		assert.Equal(t, "text/xml; charset=utf-8", r.Header.Get("Content-Type"))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<soap:Body><GetOrder xmlns="http://example.com/orders"><id>42</id></GetOrder></soap:Body></soap:Envelope>`, string(<-srv.bodies))
+
+		assert.Equal(t, "invoke", res.Metadata[bindings.ResponseMetadataOperation])
+		assert.Equal(t, "GetOrder", res.Metadata[respSOAPOperationKey])
+		assert.Equal(t, "200", res.Metadata[respStatusCodeKey])
+		assert.JSONEq(t, `{"order": {"@id": "42", "status": "shipped", "item": ["book", "pen"], "note": null}}`, string(res.Data))
+	})
+
+	t.Run("fault", func(t *testing.T) {
+		srv.response, srv.status = faultResponse, http.StatusInternalServerError
+		defer func() { srv.response, srv.status = getOrderResponse, http.StatusOK }()
+
+		_, err := s.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: InvokeOperation,
+			Metadata:  map[string]string{soapOperationKey: "GetOrder"},
+		})
+		<-srv.requests
+		<-srv.bodies
+
+		var fault *Fault
+		require.True(t, errors.As(err, &fault), err)
+		assert.Equal(t, "s:Client", fault.Code)
+		assert.Equal(t, "Order not found", fault.Reason)
+		assert.Equal(t, map[string]any{"orderId": "7"}, fault.Detail)
+	})
+
+	t.Run("HTTP error", func(t *testing.T) {
+		srv.response, srv.status = "unavailable", http.StatusServiceUnavailable
+		defer func() { srv.response, srv.status = getOrderResponse, http.StatusOK }()
+
+		_, err := s.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: InvokeOperation,
+			Metadata:  map[string]string{soapOperationKey: "GetOrder"},
+		})
+		<-srv.requests
+		<-srv.bodies
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status code 503: unavailable")
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		_, err := s.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: InvokeOperation,
+			Metadata:  map[string]string{soapOperationKey: "Nope"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Cancel, GetOrder")
+	})
+
+	t.Run("describe", func(t *testing.T) {
+		res, err := s.Invoke(context.Background(), &bindings.InvokeRequest{Operation: DescribeOperation})
+		require.NoError(t, err)
+		var ops []OperationInfo
+		require.NoError(t, json.Unmarshal(res.Data, &ops))
+		require.Len(t, ops, 2)
+		assert.Equal(t, OperationInfo{
+			Name:       "GetOrder",
+			SOAPAction: "http://example.com/orders/GetOrder",
+			Style:      "document",
+			Namespace:  "http://example.com/orders",
+			Element:    "GetOrder",
+		}, ops[1])
+	})
+}
+
+func TestInvokeSOAP12(t *testing.T) {
+	srv := startServer(t)
+	srv.response = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>` +
+		`<env:Fault><env:Code><env:Value>env:Receiver</env:Value></env:Code><env:Reason><env:Text xml:lang="en">Down</env:Text></env:Reason></env:Fault>` +
+		`</env:Body></env:Envelope>`
+	s := initSOAP(t, map[string]string{
+		"wsdlURL":  srv.URL + "/wsdl",
+		"endpoint": srv.URL + "/soap",
+		"port":     "OrdersSoap12",
+	})
+
+	_, err := s.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: InvokeOperation,
+		Data:      []byte(`{"id": "1"}`),
+		Metadata:  map[string]string{soapOperationKey: "Cancel"},
+	})
+	r := <-srv.requests
+	assert.Equal(t, `application/soap+xml; charset=utf-8; action="http://example.com/orders/Cancel"`, r.Header.Get("Content-Type"))
+	assert.Contains(t, string(<-srv.bodies), `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body>`+
+		`<ns0:Cancel xmlns:ns0="http://example.com/orders"><id>1</id></ns0:Cancel>`)
+
+	var fault *Fault
+	require.True(t, errors.As(err, &fault), err)
+	assert.Equal(t, "env:Receiver", fault.Code)
+	assert.Equal(t, "Down", fault.Reason)
+}
+
+func TestUsernameToken(t *testing.T) {
+	srv := startServer(t)
+	s := initSOAP(t, map[string]string{
+		"wsdlURL":      srv.URL + "/wsdl",
+		"endpoint":     srv.URL + "/soap",
+		"username":     "alice",
+		"password":     "secret",
+		"passwordType": "digest",
+	})
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.security.now = func() time.Time { return now }
+
+	_, err := s.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: InvokeOperation,
+		Metadata:  map[string]string{soapOperationKey: "GetOrder"},
+	})
+	require.NoError(t, err)
+	<-srv.requests
+	body := string(<-srv.bodies)
+
+	assert.Contains(t, body, `<wsu:Timestamp><wsu:Created>2023-01-02T03:04:05Z</wsu:Created><wsu:Expires>2023-01-02T03:09:05Z</wsu:Expires></wsu:Timestamp>`)
+	assert.Contains(t, body, `<wsse:Username>alice</wsse:Username>`)
+	nonce := regexp.MustCompile(`<wsse:Nonce [^>]*>([^<]+)</wsse:Nonce>`).FindStringSubmatch(body)
+	require.Len(t, nonce, 2)
+	rawNonce, err := base64.StdEncoding.DecodeString(nonce[1])
+	require.NoError(t, err)
+	assert.Contains(t, body, `<wsse:Password Type="`+passwordDigestType+`">`+passwordDigest(rawNonce, "2023-01-02T03:04:05Z", "secret")+`</wsse:Password>`)
+	assert.NotContains(t, body, "secret")
+}
+
+func TestSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	srv := startServer(t)
+	s := initSOAP(t, map[string]string{
+		"wsdlURL":            srv.URL + "/wsdl",
+		"endpoint":           srv.URL + "/soap",
+		"signingCertificate": string(certPEM),
+		"signingKey":         string(keyPEM),
+	})
+
+	_, err = s.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: InvokeOperation,
+		Data:      []byte(`{"id": "42"}`),
+		Metadata:  map[string]string{soapOperationKey: "GetOrder"},
+	})
+	require.NoError(t, err)
+	<-srv.requests
+	envelope := string(<-srv.bodies)
+
+	// The body and SignedInfo are written in canonical form: verify the digest and signature over their bytes.
+	body := envelope[strings.Index(envelope, "<soap:Body"):]
+	body = body[:strings.Index(body, "</soap:Body>")+len("</soap:Body>")]
+	assert.Equal(t, `<soap:Body xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:wsu="`+wsuNS+`" wsu:Id="id-body">`+
+		`<GetOrder xmlns="http://example.com/orders"><id>42</id></GetOrder></soap:Body>`, body)
+	digest := sha256.Sum256([]byte(body))
+	assert.Contains(t, envelope, `<ds:DigestValue>`+base64.StdEncoding.EncodeToString(digest[:])+`</ds:DigestValue>`)
+
+	signedInfo := envelope[strings.Index(envelope, "<ds:SignedInfo"):]
+	signedInfo = signedInfo[:strings.Index(signedInfo, "</ds:SignedInfo>")+len("</ds:SignedInfo>")]
+	signatureValue := regexp.MustCompile(`<ds:SignatureValue>([^<]+)</ds:SignatureValue>`).FindStringSubmatch(envelope)
+	require.Len(t, signatureValue, 2)
+	signature, err := base64.StdEncoding.DecodeString(signatureValue[1])
+	require.NoError(t, err)
+	hashed := sha256.Sum256([]byte(signedInfo))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hashed[:], signature))
+
+	assert.Contains(t, envelope, base64.StdEncoding.EncodeToString(der))
+	assert.True(t, bytes.Contains([]byte(envelope), []byte(`<wsse:Reference URI="#id-x509"`)))
+
+	t.Run("mismatched key", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		s := NewSOAP(logger.NewLogger("test"))
+		err = s.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"wsdlURL":            srv.URL + "/wsdl",
+			"signingCertificate": string(certPEM),
+			"signingKey":         string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(otherKey)})),
+		}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestInit(t *testing.T) {
+	srv := startServer(t)
+
+	tests := map[string]map[string]string{
+		"no wsdlURL":            {},
+		"missing WSDL":          {"wsdlURL": "/does/not/exist.wsdl"},
+		"password without user": {"wsdlURL": srv.URL, "password": "p"},
+		"invalid passwordType":  {"wsdlURL": srv.URL, "username": "u", "passwordType": "hash"},
+		"certificate alone":     {"wsdlURL": srv.URL, "signingCertificate": "x"},
+	}
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewSOAP(logger.NewLogger("test"))
+			assert.Error(t, s.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+		})
+	}
+
+	t.Run("endpoint from WSDL", func(t *testing.T) {
+		s := initSOAP(t, map[string]string{"wsdlURL": srv.URL})
+		assert.Equal(t, "http://orders.example.com/soap", s.service.Endpoint)
+	})
+}
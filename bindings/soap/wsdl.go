@@ -0,0 +1,323 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package soap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// soapVersion is the version of SOAP a port binds to.
+type soapVersion int
+
+const (
+	soap11 soapVersion = iota
+	soap12
+)
+
+func (v soapVersion) envelopeNS() string {
+	if v == soap12 {
+		return "http://www.w3.org/2003/05/soap-envelope"
+	}
+	return "http://schemas.xmlsoap.org/soap/envelope/"
+}
+
+// service is the port of a WSDL service the binding invokes.
+type service struct {
+	Endpoint   string
+	Version    soapVersion
+	Operations map[string]*operation
+}
+
+// operation is an operation of a WSDL binding.
+type operation struct {
+	Name   string
+	Action string
+	// Style is "document" or "rpc".
+	Style string
+	// Wrapper is the element wrapping the request in the SOAP body.
+	Wrapper xml.Name
+	// Qualified is true when the children of the wrapper are in its namespace.
+	Qualified bool
+}
+
+type wsdlDefinitions struct {
+	XMLName         xml.Name       `xml:"http://schemas.xmlsoap.org/wsdl/ definitions"`
+	TargetNamespace string         `xml:"targetNamespace,attr"`
+	Attrs           []xml.Attr     `xml:",any,attr"`
+	Types           wsdlTypes      `xml:"http://schemas.xmlsoap.org/wsdl/ types"`
+	Messages        []wsdlMessage  `xml:"http://schemas.xmlsoap.org/wsdl/ message"`
+	PortTypes       []wsdlPortType `xml:"http://schemas.xmlsoap.org/wsdl/ portType"`
+	Bindings        []wsdlBinding  `xml:"http://schemas.xmlsoap.org/wsdl/ binding"`
+	Services        []wsdlService  `xml:"http://schemas.xmlsoap.org/wsdl/ service"`
+}
+
+type wsdlTypes struct {
+	Schemas []xsdSchema `xml:"http://www.w3.org/2001/XMLSchema schema"`
+}
+
+type xsdSchema struct {
+	TargetNamespace    string `xml:"targetNamespace,attr"`
+	ElementFormDefault string `xml:"elementFormDefault,attr"`
+}
+
+type wsdlMessage struct {
+	Name  string     `xml:"name,attr"`
+	Parts []wsdlPart `xml:"http://schemas.xmlsoap.org/wsdl/ part"`
+}
+
+type wsdlPart struct {
+	Name    string `xml:"name,attr"`
+	Element string `xml:"element,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type wsdlPortType struct {
+	Name       string                  `xml:"name,attr"`
+	Operations []wsdlPortTypeOperation `xml:"http://schemas.xmlsoap.org/wsdl/ operation"`
+}
+
+type wsdlPortTypeOperation struct {
+	Name  string    `xml:"name,attr"`
+	Input wsdlIORef `xml:"http://schemas.xmlsoap.org/wsdl/ input"`
+}
+
+type wsdlIORef struct {
+	Message string `xml:"message,attr"`
+}
+
+type wsdlBinding struct {
+	Name       string                 `xml:"name,attr"`
+	Type       string                 `xml:"type,attr"`
+	SOAP11     *wsdlSOAPBinding       `xml:"http://schemas.xmlsoap.org/wsdl/soap/ binding"`
+	SOAP12     *wsdlSOAPBinding       `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ binding"`
+	Operations []wsdlBindingOperation `xml:"http://schemas.xmlsoap.org/wsdl/ operation"`
+}
+
+type wsdlSOAPBinding struct {
+	Style string `xml:"style,attr"`
+}
+
+type wsdlBindingOperation struct {
+	Name   string             `xml:"name,attr"`
+	SOAP11 *wsdlSOAPOperation `xml:"http://schemas.xmlsoap.org/wsdl/soap/ operation"`
+	SOAP12 *wsdlSOAPOperation `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ operation"`
+	Input  wsdlBindingInput   `xml:"http://schemas.xmlsoap.org/wsdl/ input"`
+}
+
+type wsdlSOAPOperation struct {
+	SOAPAction string `xml:"soapAction,attr"`
+	Style      string `xml:"style,attr"`
+}
+
+type wsdlBindingInput struct {
+	SOAP11Body *wsdlSOAPBody `xml:"http://schemas.xmlsoap.org/wsdl/soap/ body"`
+	SOAP12Body *wsdlSOAPBody `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ body"`
+}
+
+type wsdlSOAPBody struct {
+	Namespace string `xml:"namespace,attr"`
+}
+
+type wsdlService struct {
+	Name  string     `xml:"name,attr"`
+	Ports []wsdlPort `xml:"http://schemas.xmlsoap.org/wsdl/ port"`
+}
+
+type wsdlPort struct {
+	Name          string       `xml:"name,attr"`
+	Binding       string       `xml:"binding,attr"`
+	SOAP11Address *wsdlAddress `xml:"http://schemas.xmlsoap.org/wsdl/soap/ address"`
+	SOAP12Address *wsdlAddress `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ address"`
+}
+
+type wsdlAddress struct {
+	Location string `xml:"location,attr"`
+}
+
+// parseWSDL parses a WSDL 1.1 document and resolves the operations of the port named portName,
+// or of the first SOAP port of its services when portName is empty.
+func parseWSDL(data []byte, portName string) (*service, error) {
+	var defs wsdlDefinitions
+	if err := xml.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("invalid WSDL: %w", err)
+	}
+
+	for _, svc := range defs.Services {
+		for _, port := range svc.Ports {
+			if portName != "" && port.Name != portName {
+				continue
+			}
+			var s *service
+			switch {
+			case port.SOAP11Address != nil:
+				s = &service{Endpoint: port.SOAP11Address.Location, Version: soap11}
+			case port.SOAP12Address != nil:
+				s = &service{Endpoint: port.SOAP12Address.Location, Version: soap12}
+			default:
+				// Not a SOAP port (e.g. HTTP binding).
+				continue
+			}
+			ops, err := defs.operations(localName(port.Binding), s.Version)
+			if err != nil {
+				return nil, fmt.Errorf("port %s: %w", port.Name, err)
+			}
+			s.Operations = ops
+			return s, nil
+		}
+	}
+
+	if portName != "" {
+		return nil, fmt.Errorf("no SOAP port %s in WSDL", portName)
+	}
+	return nil, errors.New("no SOAP port in WSDL")
+}
+
+func (d *wsdlDefinitions) operations(bindingName string, version soapVersion) (map[string]*operation, error) {
+	var binding *wsdlBinding
+	for i := range d.Bindings {
+		if d.Bindings[i].Name == bindingName {
+			binding = &d.Bindings[i]
+			break
+		}
+	}
+	if binding == nil {
+		return nil, fmt.Errorf("binding %s not found", bindingName)
+	}
+	var portType *wsdlPortType
+	for i := range d.PortTypes {
+		if d.PortTypes[i].Name == localName(binding.Type) {
+			portType = &d.PortTypes[i]
+			break
+		}
+	}
+	if portType == nil {
+		return nil, fmt.Errorf("portType %s not found", binding.Type)
+	}
+
+	defaultStyle := "document"
+	soapBinding := binding.SOAP11
+	if version == soap12 {
+		soapBinding = binding.SOAP12
+	}
+	if soapBinding != nil && soapBinding.Style != "" {
+		defaultStyle = soapBinding.Style
+	}
+
+	ops := make(map[string]*operation, len(binding.Operations))
+	for _, bop := range binding.Operations {
+		op := &operation{
+			Name:  bop.Name,
+			Style: defaultStyle,
+		}
+		soapOp, body := bop.SOAP11, bop.Input.SOAP11Body
+		if version == soap12 {
+			soapOp, body = bop.SOAP12, bop.Input.SOAP12Body
+		}
+		if soapOp != nil {
+			op.Action = soapOp.SOAPAction
+			if soapOp.Style != "" {
+				op.Style = soapOp.Style
+			}
+		}
+
+		if op.Style == "rpc" {
+			// The wrapper is named after the operation, and the parts are unqualified.
+			op.Wrapper = xml.Name{Space: d.TargetNamespace, Local: op.Name}
+			if body != nil && body.Namespace != "" {
+				op.Wrapper.Space = body.Namespace
+			}
+		} else {
+			part, err := d.inputPart(portType, op.Name)
+			if err != nil {
+				return nil, fmt.Errorf("operation %s: %w", op.Name, err)
+			}
+			op.Wrapper = d.resolveQName(part.Element)
+			op.Qualified = d.qualified(op.Wrapper.Space)
+		}
+		ops[op.Name] = op
+	}
+
+	return ops, nil
+}
+
+// inputPart returns the part of the input message of a document-style operation, which names the element in the SOAP body.
+func (d *wsdlDefinitions) inputPart(portType *wsdlPortType, name string) (*wsdlPart, error) {
+	var msgName string
+	for _, op := range portType.Operations {
+		if op.Name == name {
+			msgName = localName(op.Input.Message)
+			break
+		}
+	}
+	for i := range d.Messages {
+		if d.Messages[i].Name != msgName {
+			continue
+		}
+		parts := d.Messages[i].Parts
+		if len(parts) != 1 || parts[0].Element == "" {
+			return nil, fmt.Errorf("input message %s must have a single part referencing an element", msgName)
+		}
+		return &parts[0], nil
+	}
+
+	return nil, fmt.Errorf("input message %s not found", msgName)
+}
+
+// resolveQName resolves a prefixed name with the namespaces declared on the definitions.
+func (d *wsdlDefinitions) resolveQName(qname string) xml.Name {
+	prefix, local := "", qname
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		prefix, local = qname[:i], qname[i+1:]
+	}
+	for _, attr := range d.Attrs {
+		if (prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns") ||
+			(prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix) {
+			return xml.Name{Space: attr.Value, Local: local}
+		}
+	}
+
+	return xml.Name{Space: d.TargetNamespace, Local: local}
+}
+
+// qualified returns whether the local elements of the schema of namespace ns are qualified.
+// Schemas that are imported rather than inlined are assumed to be, as is the case for most document/literal services.
+func (d *wsdlDefinitions) qualified(ns string) bool {
+	for _, schema := range d.Types.Schemas {
+		if schema.TargetNamespace == ns {
+			return schema.ElementFormDefault == "qualified"
+		}
+	}
+	return true
+}
+
+// names returns the sorted names of the operations.
+func (s *service) names() []string {
+	names := make([]string, 0, len(s.Operations))
+	for name := range s.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func localName(qname string) string {
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
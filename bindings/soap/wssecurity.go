@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package soap
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	wsseNS = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	wsuNS  = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	dsNS   = "http://www.w3.org/2000/09/xmldsig#"
+
+	passwordTextType   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordText"
+	passwordDigestType = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+	base64EncodingType = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+	x509TokenType      = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-x509-token-profile-1.0#X509v3"
+
+	excC14NAlgorithm   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	rsaSHA256Algorithm = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	sha256Algorithm    = "http://www.w3.org/2001/04/xmlenc#sha256"
+
+	bodyID  = "id-body"
+	tokenID = "id-x509"
+
+	// Values of passwordType.
+	passwordTypeText   = "text"
+	passwordTypeDigest = "digest"
+)
+
+// wsSecurity writes the WS-Security header of the requests: a timestamp, the username token and the signature of the body.
+type wsSecurity struct {
+	username     string
+	password     string
+	digest       bool
+	timestampTTL time.Duration
+
+	key     *rsa.PrivateKey
+	certDER []byte
+
+	now func() time.Time
+}
+
+func newWSSecurity(md soapMetadata) (*wsSecurity, error) {
+	s := &wsSecurity{
+		username:     md.Username,
+		password:     md.Password,
+		timestampTTL: md.TimestampTTL,
+		now:          time.Now,
+	}
+	switch md.PasswordType {
+	case "", passwordTypeText:
+	case passwordTypeDigest:
+		s.digest = true
+	default:
+		return nil, fmt.Errorf("invalid passwordType %s: must be %s or %s", md.PasswordType, passwordTypeText, passwordTypeDigest)
+	}
+	if s.username == "" && s.password != "" {
+		return nil, errors.New("password requires username")
+	}
+
+	if (md.SigningCertificate == "") != (md.SigningKey == "") {
+		return nil, errors.New("signingCertificate and signingKey must be set together")
+	}
+	if md.SigningCertificate != "" {
+		block, _ := pem.Decode([]byte(md.SigningCertificate))
+		if block == nil {
+			return nil, errors.New("signingCertificate is not PEM-encoded")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signingCertificate: %w", err)
+		}
+		s.certDER = cert.Raw
+
+		s.key, err = parseRSAKey(md.SigningKey)
+		if err != nil {
+			return nil, err
+		}
+		if pub, ok := cert.PublicKey.(*rsa.PublicKey); !ok || !pub.Equal(&s.key.PublicKey) {
+			return nil, errors.New("signingKey doesn't match the key of signingCertificate")
+		}
+	}
+
+	return s, nil
+}
+
+func parseRSAKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("signingKey is not PEM-encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signingKey: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signingKey must be an RSA key")
+	}
+	return rsaKey, nil
+}
+
+func (s *wsSecurity) enabled() bool {
+	return s.username != "" || s.signing()
+}
+
+func (s *wsSecurity) signing() bool {
+	return s.key != nil
+}
+
+// writeHeader writes the Security header; body is the canonical form of the SOAP body, which is signed when a key is set.
+func (s *wsSecurity) writeHeader(w *xmlWriter, envPrefix string, body []byte) error {
+	w.start("wsse:Security", "xmlns:wsse", wsseNS, "xmlns:wsu", wsuNS, envPrefix+":mustUnderstand", "1")
+
+	now := s.now().UTC()
+	created := now.Format(time.RFC3339)
+	w.start("wsu:Timestamp")
+	w.element("wsu:Created", created)
+	w.element("wsu:Expires", now.Add(s.timestampTTL).Format(time.RFC3339))
+	w.end("wsu:Timestamp")
+
+	if s.username != "" {
+		w.start("wsse:UsernameToken")
+		w.element("wsse:Username", s.username)
+		if s.digest {
+			nonce := make([]byte, 16)
+			if _, err := rand.Read(nonce); err != nil {
+				return err
+			}
+			w.element("wsse:Password", passwordDigest(nonce, created, s.password), "Type", passwordDigestType)
+			w.element("wsse:Nonce", base64.StdEncoding.EncodeToString(nonce), "EncodingType", base64EncodingType)
+			w.element("wsu:Created", created)
+		} else {
+			w.element("wsse:Password", s.password, "Type", passwordTextType)
+		}
+		w.end("wsse:UsernameToken")
+	}
+
+	if s.signing() {
+		w.element("wsse:BinarySecurityToken", base64.StdEncoding.EncodeToString(s.certDER),
+			"EncodingType", base64EncodingType, "ValueType", x509TokenType, "wsu:Id", tokenID)
+
+		signedInfo := signedInfo(body)
+		hashed := sha256.Sum256(signedInfo)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hashed[:])
+		if err != nil {
+			return fmt.Errorf("failed to sign the body: %w", err)
+		}
+		w.start("ds:Signature", "xmlns:ds", dsNS)
+		w.Write(signedInfo)
+		w.element("ds:SignatureValue", base64.StdEncoding.EncodeToString(signature))
+		w.start("ds:KeyInfo")
+		w.start("wsse:SecurityTokenReference")
+		w.element("wsse:Reference", "", "URI", "#"+tokenID, "ValueType", x509TokenType)
+		w.end("wsse:SecurityTokenReference")
+		w.end("ds:KeyInfo")
+		w.end("ds:Signature")
+	}
+
+	w.end("wsse:Security")
+
+	return nil
+}
+
+// signedInfo returns the canonical SignedInfo element referencing the body.
+func signedInfo(body []byte) []byte {
+	digest := sha256.Sum256(body)
+
+	var w xmlWriter
+	w.start("ds:SignedInfo", "xmlns:ds", dsNS)
+	w.element("ds:CanonicalizationMethod", "", "Algorithm", excC14NAlgorithm)
+	w.element("ds:SignatureMethod", "", "Algorithm", rsaSHA256Algorithm)
+	w.start("ds:Reference", "URI", "#"+bodyID)
+	w.start("ds:Transforms")
+	w.element("ds:Transform", "", "Algorithm", excC14NAlgorithm)
+	w.end("ds:Transforms")
+	w.element("ds:DigestMethod", "", "Algorithm", sha256Algorithm)
+	w.element("ds:DigestValue", base64.StdEncoding.EncodeToString(digest[:]))
+	w.end("ds:Reference")
+	w.end("ds:SignedInfo")
+
+	return w.Bytes()
+}
+
+// passwordDigest returns Base64(SHA-1(nonce + created + password)), as defined by the username token profile.
+func passwordDigest(nonce []byte, created, password string) string {
+	h := sha1.New() //nolint:gosec
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spreadsheet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	azauth "github.com/dapr/components-contrib/internal/authentication/azure"
+)
+
+// Size of the response body included in the errors.
+const maxErrorBodySize = 512
+
+// excel is the provider of Excel workbooks, through the workbook API of Microsoft Graph.
+type excel struct {
+	// URL of the workbook: https://graph.microsoft.com/v1.0/drives/{driveID}/items/{itemID}/workbook.
+	workbookURL string
+	credential  azcore.TokenCredential
+	scope       string
+	client      *http.Client
+}
+
+// excelRange is a range returned by the API.
+type excelRange struct {
+	Address string          `json:"address"`
+	Values  [][]interface{} `json:"values"`
+}
+
+func newExcel(m spreadsheetMetadata, properties map[string]string) (*excel, error) {
+	settings, err := azauth.NewEnvironmentSettings(azauth.AzureMicrosoftGraphResourceName, properties)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := settings.GetTokenCredential()
+	if err != nil {
+		return nil, err
+	}
+	return &excel{
+		workbookURL: settings.Resource + "/v1.0/drives/" + url.PathEscape(m.DriveID) + "/items/" + url.PathEscape(m.ItemID) + "/workbook",
+		credential:  credential,
+		scope:       settings.Resource + "/.default",
+		client:      &http.Client{Timeout: m.Timeout},
+	}, nil
+}
+
+func (e *excel) appendRows(ctx context.Context, sheet string, table string, rows [][]interface{}) (string, error) {
+	width := 0
+	for _, r := range rows {
+		if len(r) > width {
+			width = len(r)
+		}
+	}
+	// The values of a range must be rectangular.
+	for i, r := range rows {
+		for len(r) < width {
+			r = append(r, "")
+		}
+		rows[i] = r
+	}
+
+	if table != "" {
+		// The rows added to tables have no address in the response.
+		var res struct{}
+		return "", e.do(ctx, http.MethodPost, "/tables"+odataKey(table)+"/rows", map[string]interface{}{"values": rows}, &res)
+	}
+
+	var used excelRange
+	err := e.do(ctx, http.MethodGet, "/worksheets"+odataKey(sheet)+"/usedRange(valuesOnly=true)", nil, &used)
+	if err != nil {
+		return "", err
+	}
+	col, row := 1, 1
+	if !emptyValues(used.Values) {
+		_, address := splitRange(used.Address)
+		first, last, _ := strings.Cut(address, ":")
+		if last == "" {
+			last = first
+		}
+		col, _, err = parseCell(first)
+		if err != nil {
+			return "", fmt.Errorf("unexpected used range %s: %w", used.Address, err)
+		}
+		_, row, err = parseCell(last)
+		if err != nil {
+			return "", fmt.Errorf("unexpected used range %s: %w", used.Address, err)
+		}
+		row++
+	}
+	address := columnName(col) + strconv.Itoa(row) + ":" + columnName(col+width-1) + strconv.Itoa(row+len(rows)-1)
+	return e.updateRange(ctx, quoteSheet(sheet)+"!"+address, rows)
+}
+
+func (e *excel) readRange(ctx context.Context, rng string) (string, [][]interface{}, error) {
+	path, err := rangePath(rng)
+	if err != nil {
+		return "", nil, err
+	}
+	var res excelRange
+	err = e.do(ctx, http.MethodGet, path, nil, &res)
+	if err != nil {
+		return "", nil, err
+	}
+	return res.Address, res.Values, nil
+}
+
+func (e *excel) updateRange(ctx context.Context, rng string, values [][]interface{}) (string, error) {
+	path, err := rangePath(rng)
+	if err != nil {
+		return "", err
+	}
+	var res excelRange
+	err = e.do(ctx, http.MethodPatch, path, map[string]interface{}{"values": values}, &res)
+	if err != nil {
+		return "", err
+	}
+	return res.Address, nil
+}
+
+// do sends a request to the workbook API and decodes the JSON response into res.
+func (e *excel) do(ctx context.Context, method string, path string, body interface{}, res interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.workbookURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	token, err := e.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{e.scope}})
+	if err != nil {
+		return fmt.Errorf("failed to get a token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var graphErr struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(b, &graphErr) == nil && graphErr.Error.Code != "" {
+			return fmt.Errorf("graph error %s: %s", graphErr.Error.Code, graphErr.Error.Message)
+		}
+		if len(b) > maxErrorBodySize {
+			b = b[:maxErrorBodySize]
+		}
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, b)
+	}
+	return json.Unmarshal(b, res)
+}
+
+// rangePath returns the path of the API of the range in A1 notation: the sheet is required.
+func rangePath(rng string) (string, error) {
+	sheet, address := splitRange(rng)
+	if sheet == "" || address == "" {
+		return "", fmt.Errorf("invalid range %q: must be sheet!address", rng)
+	}
+	return "/worksheets" + odataKey(sheet) + "/range(address=" + url.PathEscape(odataString(address)) + ")", nil
+}
+
+// odataKey returns the key of an entity of a collection: ('name').
+func odataKey(name string) string {
+	return "(" + url.PathEscape(odataString(name)) + ")"
+}
+
+func odataString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func emptyValues(values [][]interface{}) bool {
+	for _, r := range values {
+		for _, v := range r {
+			if v != nil && v != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseCell returns the column, from 1, and the row of a cell such as "AB12"; "$" signs are ignored.
+func parseCell(cell string) (int, int, error) {
+	cell = strings.ReplaceAll(strings.ToUpper(cell), "$", "")
+	col, i := 0, 0
+	for ; i < len(cell) && cell[i] >= 'A' && cell[i] <= 'Z'; i++ {
+		col = col*26 + int(cell[i]-'A'+1)
+	}
+	row, err := strconv.Atoi(cell[i:])
+	if col == 0 || err != nil || row < 1 {
+		return 0, 0, errors.New("invalid cell " + cell)
+	}
+	return col, row, nil
+}
+
+// columnName returns the name of the column, from 1: 1 is "A", 27 is "AA".
+func columnName(col int) string {
+	var name []byte
+	for col > 0 {
+		col--
+		name = append([]byte{byte('A' + col%26)}, name...)
+		col /= 26
+	}
+	return string(name)
+}
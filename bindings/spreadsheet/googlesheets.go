@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spreadsheet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// googleSheets is the provider of Google Sheets spreadsheets.
+type googleSheets struct {
+	service       *sheets.Service
+	spreadsheetID string
+}
+
+func newGoogleSheets(ctx context.Context, m spreadsheetMetadata, opts ...option.ClientOption) (*googleSheets, error) {
+	if m.PrivateKey != "" {
+		credentials, err := json.Marshal(map[string]string{
+			"type":                        m.Type,
+			"project_id":                  m.ProjectID,
+			"private_key_id":              m.PrivateKeyID,
+			"private_key":                 m.PrivateKey,
+			"client_email":                m.ClientEmail,
+			"client_id":                   m.ClientID,
+			"auth_uri":                    m.AuthURI,
+			"token_uri":                   m.TokenURI,
+			"auth_provider_x509_cert_url": m.AuthProviderCertURL,
+			"client_x509_cert_url":        m.ClientCertURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	}
+	opts = append(opts, option.WithScopes(sheets.SpreadsheetsScope))
+	service, err := sheets.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &googleSheets{service: service, spreadsheetID: m.SpreadsheetID}, nil
+}
+
+func (g *googleSheets) appendRows(ctx context.Context, sheet string, table string, rows [][]interface{}) (string, error) {
+	if table != "" {
+		return "", errors.New("tables are only supported by Excel")
+	}
+	res, err := g.service.Spreadsheets.Values.Append(g.spreadsheetID, quoteSheet(sheet), &sheets.ValueRange{Values: rows}).
+		ValueInputOption("USER_ENTERED").
+		InsertDataOption("INSERT_ROWS").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", err
+	}
+	if res.Updates == nil {
+		return "", nil
+	}
+	return res.Updates.UpdatedRange, nil
+}
+
+func (g *googleSheets) readRange(ctx context.Context, rng string) (string, [][]interface{}, error) {
+	res, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, rng).
+		ValueRenderOption("UNFORMATTED_VALUE").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", nil, err
+	}
+	return res.Range, res.Values, nil
+}
+
+func (g *googleSheets) updateRange(ctx context.Context, rng string, values [][]interface{}) (string, error) {
+	res, err := g.service.Spreadsheets.Values.Update(g.spreadsheetID, rng, &sheets.ValueRange{Values: values}).
+		ValueInputOption("USER_ENTERED").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", err
+	}
+	return res.UpdatedRange, nil
+}
+
+// quoteSheet returns the sheet name quoted for A1 notation.
+func quoteSheet(sheet string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+}
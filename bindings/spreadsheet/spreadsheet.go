@@ -0,0 +1,267 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spreadsheet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// Values of provider.
+	providerGoogleSheets = "googlesheets"
+	providerExcel        = "excel"
+
+	// keys from request's metadata.
+	rangeKey = "range"
+	sheetKey = "sheet"
+	cellKey  = "cell"
+	tableKey = "table"
+
+	AppendRowOperation  bindings.OperationKind = "appendRow"
+	ReadRangeOperation  bindings.OperationKind = "readRange"
+	UpdateCellOperation bindings.OperationKind = "updateCell"
+)
+
+// Spreadsheet is an output binding appending rows to, reading and updating the cells of a Google Sheets spreadsheet
+// or an Excel workbook stored in OneDrive or SharePoint, through Microsoft Graph.
+type Spreadsheet struct {
+	metadata spreadsheetMetadata
+	provider provider
+	logger   logger.Logger
+}
+
+type spreadsheetMetadata struct {
+	// Provider is "googlesheets" or "excel".
+	Provider string        `mapstructure:"provider"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+
+	// Google Sheets: the spreadsheet, and the service account key fields, as in the other GCP components.
+	SpreadsheetID       string `mapstructure:"spreadsheetID"`
+	Type                string `mapstructure:"type"`
+	ProjectID           string `mapstructure:"project_id"`
+	PrivateKeyID        string `mapstructure:"private_key_id"`
+	PrivateKey          string `mapstructure:"private_key"`
+	ClientEmail         string `mapstructure:"client_email"`
+	ClientID            string `mapstructure:"client_id"`
+	AuthURI             string `mapstructure:"auth_uri"`
+	TokenURI            string `mapstructure:"token_uri"`
+	AuthProviderCertURL string `mapstructure:"auth_provider_x509_cert_url"`
+	ClientCertURL       string `mapstructure:"client_x509_cert_url"`
+
+	// Excel: the drive and the item of the workbook; the Azure AD credentials are read with the common Azure auth metadata.
+	DriveID string `mapstructure:"driveID"`
+	ItemID  string `mapstructure:"itemID"`
+}
+
+// provider is the API of a spreadsheet service. Ranges are in A1 notation, prefixed with the sheet name: "Sheet1!A1:C3".
+type provider interface {
+	// appendRows writes the rows after the last row of the sheet, or of the table, and returns the written range.
+	appendRows(ctx context.Context, sheet string, table string, rows [][]interface{}) (string, error)
+	// readRange returns the range and the values of its cells, by row.
+	readRange(ctx context.Context, rng string) (string, [][]interface{}, error)
+	// updateRange writes the values to the cells of the range, and returns the written range.
+	updateRange(ctx context.Context, rng string, values [][]interface{}) (string, error)
+}
+
+// RangeResult is the response of the operations.
+type RangeResult struct {
+	Range  string          `json:"range"`
+	Rows   int             `json:"rows,omitempty"`
+	Values [][]interface{} `json:"values,omitempty"`
+}
+
+// NewSpreadsheet returns a new spreadsheet binding.
+func NewSpreadsheet(logger logger.Logger) bindings.OutputBinding {
+	return &Spreadsheet{logger: logger}
+}
+
+// Init parses the metadata and creates the client of the provider.
+func (s *Spreadsheet) Init(meta bindings.Metadata) error {
+	s.metadata = spreadsheetMetadata{Timeout: defaultTimeout}
+	err := metadata.DecodeMetadata(meta.Properties, &s.metadata)
+	if err != nil {
+		return fmt.Errorf("spreadsheet binding error: %w", err)
+	}
+
+	switch s.metadata.Provider {
+	case providerGoogleSheets:
+		if s.metadata.SpreadsheetID == "" {
+			return errors.New("spreadsheet binding error: spreadsheetID is required")
+		}
+		s.provider, err = newGoogleSheets(context.Background(), s.metadata)
+	case providerExcel:
+		if s.metadata.DriveID == "" || s.metadata.ItemID == "" {
+			return errors.New("spreadsheet binding error: driveID and itemID are required")
+		}
+		s.provider, err = newExcel(s.metadata, meta.Properties)
+	default:
+		return fmt.Errorf("spreadsheet binding error: invalid provider %q: must be %s or %s", s.metadata.Provider, providerGoogleSheets, providerExcel)
+	}
+	if err != nil {
+		return fmt.Errorf("spreadsheet binding error: %w", err)
+	}
+	return nil
+}
+
+// Operations returns the operations supported by the spreadsheet binding.
+func (s *Spreadsheet) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{AppendRowOperation, ReadRangeOperation, UpdateCellOperation}
+}
+
+// Invoke runs the operation of the request.
+func (s *Spreadsheet) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.metadata.Timeout)
+	defer cancel()
+
+	var (
+		result RangeResult
+		err    error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case AppendRowOperation:
+		sheet := req.Metadata[sheetKey]
+		if sheet == "" {
+			sheet, _ = splitRange(req.Metadata[rangeKey])
+		}
+		table := req.Metadata[tableKey]
+		if sheet == "" && table == "" {
+			return nil, fmt.Errorf("spreadsheet binding error: the %s or %s metadata is required", sheetKey, tableKey)
+		}
+		var rows [][]interface{}
+		rows, err = parseRows(req.Data)
+		if err != nil {
+			return nil, fmt.Errorf("spreadsheet binding error: %w", err)
+		}
+		result.Rows = len(rows)
+		result.Range, err = s.provider.appendRows(ctx, sheet, table, rows)
+	case ReadRangeOperation:
+		rng := req.Metadata[rangeKey]
+		if rng == "" {
+			return nil, fmt.Errorf("spreadsheet binding error: the %s metadata is required", rangeKey)
+		}
+		result.Range, result.Values, err = s.provider.readRange(ctx, rng)
+	case UpdateCellOperation:
+		rng := req.Metadata[cellKey]
+		if rng == "" {
+			rng = req.Metadata[rangeKey]
+		}
+		if rng == "" {
+			return nil, fmt.Errorf("spreadsheet binding error: the %s or %s metadata is required", cellKey, rangeKey)
+		}
+		var values [][]interface{}
+		values, err = parseCells(req.Data)
+		if err != nil {
+			return nil, fmt.Errorf("spreadsheet binding error: %w", err)
+		}
+		result.Range, err = s.provider.updateRange(ctx, rng, values)
+	default:
+		return nil, fmt.Errorf("spreadsheet binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("spreadsheet binding error: %s failed: %w", req.Operation, err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("spreadsheet binding error: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+			rangeKey:                           result.Range,
+		},
+	}, nil
+}
+
+// parseRows parses the rows to append: a JSON array of values for a single row, or an array of rows.
+func parseRows(data []byte) ([][]interface{}, error) {
+	var values []interface{}
+	if err := json.Unmarshal(data, &values); err != nil || len(values) == 0 {
+		return nil, errors.New("the data must be a non-empty JSON array of values, or an array of rows")
+	}
+	if _, ok := values[0].([]interface{}); !ok {
+		return [][]interface{}{values}, nil
+	}
+	rows := make([][]interface{}, len(values))
+	for i, v := range values {
+		row, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.New("the rows must all be JSON arrays")
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// parseCells parses the values to write: a JSON value for a single cell, or an array of rows.
+func parseCells(data []byte) ([][]interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, errors.New("the data must be a JSON value, or an array of rows")
+	}
+	if _, ok := value.([]interface{}); !ok {
+		return [][]interface{}{{value}}, nil
+	}
+	return parseRows(data)
+}
+
+// splitRange returns the sheet name and the address of a range such as "'My sheet'!A1:B2".
+func splitRange(rng string) (string, string) {
+	i := strings.LastIndexByte(rng, '!')
+	if i < 0 {
+		return "", rng
+	}
+	sheet := rng[:i]
+	if len(sheet) >= 2 && sheet[0] == '\'' && sheet[len(sheet)-1] == '\'' {
+		sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+	return sheet, rng[i+1:]
+}
+
+// OperationsMetadata describes the operations of the spreadsheet binding.
+func (s *Spreadsheet) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: AppendRowOperation,
+			Description: "Appends the row of values in the data, or the array of rows, after the last row of the sheet named by the sheet metadata " +
+				"or of the Excel table named by the table metadata. Returns the written range.",
+			RequestMetadata:  []string{sheetKey, tableKey, rangeKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, rangeKey},
+		},
+		{
+			Operation:        ReadRangeOperation,
+			Description:      `Returns the values of the cells of the range metadata, in A1 notation such as "Sheet1!A1:C10", by row.`,
+			RequestMetadata:  []string{rangeKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, rangeKey},
+		},
+		{
+			Operation:        UpdateCellOperation,
+			Description:      "Writes the value in the data to the cell metadata, or the array of rows to the range metadata. Returns the written range.",
+			RequestMetadata:  []string{cellKey, rangeKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, rangeKey},
+		},
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spreadsheet
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// request is a request received by the fake APIs.
+type request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   map[string]interface{}
+}
+
+func fakeAPI(t *testing.T, responses map[string]string) (*httptest.Server, *[]request) {
+	t.Helper()
+
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, Path: r.URL.EscapedPath(), Query: r.URL.RawQuery}
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+			require.NoError(t, json.Unmarshal(b, &req.Body))
+		}
+		requests = append(requests, req)
+		res, ok := responses[r.Method+" "+req.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"itemNotFound","message":"not found"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(res))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func invoke(t *testing.T, s *Spreadsheet, operation bindings.OperationKind, data string, meta map[string]string) RangeResult {
+	t.Helper()
+
+	res, err := s.Invoke(context.Background(), &bindings.InvokeRequest{Operation: operation, Data: []byte(data), Metadata: meta})
+	require.NoError(t, err)
+	var result RangeResult
+	require.NoError(t, json.Unmarshal(res.Data, &result))
+	return result
+}
+
+func TestGoogleSheets(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"POST /v4/spreadsheets/sheet-id/values/%27Sales%27:append": `{"updates":{"updatedRange":"Sales!A5:C5","updatedRows":1}}`,
+		"GET /v4/spreadsheets/sheet-id/values/Sales%21A1%3AB2":     `{"range":"Sales!A1:B2","values":[["Region","Total"],["EU",12]]}`,
+		"PUT /v4/spreadsheets/sheet-id/values/Sales%21B2":          `{"updatedRange":"Sales!B2","updatedCells":1}`,
+	})
+	p, err := newGoogleSheets(context.Background(), spreadsheetMetadata{SpreadsheetID: "sheet-id"},
+		option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	s := &Spreadsheet{metadata: spreadsheetMetadata{Timeout: defaultTimeout}, provider: p, logger: logger.NewLogger("test")}
+
+	result := invoke(t, s, AppendRowOperation, `["EU", 12, "=B5*2"]`, map[string]string{sheetKey: "Sales"})
+	assert.Equal(t, RangeResult{Range: "Sales!A5:C5", Rows: 1}, result)
+	assert.Equal(t, map[string]interface{}{"values": []interface{}{[]interface{}{"EU", float64(12), "=B5*2"}}}, (*requests)[0].Body)
+	assert.Contains(t, (*requests)[0].Query, "valueInputOption=USER_ENTERED")
+
+	result = invoke(t, s, ReadRangeOperation, "", map[string]string{rangeKey: "Sales!A1:B2"})
+	assert.Equal(t, RangeResult{Range: "Sales!A1:B2", Values: [][]interface{}{{"Region", "Total"}, {"EU", float64(12)}}}, result)
+
+	result = invoke(t, s, UpdateCellOperation, `42`, map[string]string{cellKey: "Sales!B2"})
+	assert.Equal(t, RangeResult{Range: "Sales!B2"}, result)
+	assert.Equal(t, map[string]interface{}{"values": []interface{}{[]interface{}{float64(42)}}}, (*requests)[2].Body)
+
+	_, err = s.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: AppendRowOperation, Data: []byte(`["x"]`), Metadata: map[string]string{tableKey: "Table1"},
+	})
+	assert.Error(t, err)
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+func TestExcel(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"GET /workbook/worksheets(%27Sales%27)/usedRange(valuesOnly=true)":            `{"address":"Sales!B1:D4","values":[[1,2,3]]}`,
+		"GET /workbook/worksheets(%27Empty%27)/usedRange(valuesOnly=true)":            `{"address":"Empty!A1","values":[[""]]}`,
+		"PATCH /workbook/worksheets(%27Sales%27)/range(address=%27B5:D6%27)":          `{"address":"Sales!B5:D6"}`,
+		"PATCH /workbook/worksheets(%27Empty%27)/range(address=%27A1:A1%27)":          `{"address":"Empty!A1"}`,
+		"GET /workbook/worksheets(%27Sales%27)/range(address=%27A1:B2%27)":            `{"address":"Sales!A1:B2","values":[["Region","Total"],["EU",12]]}`,
+		"PATCH /workbook/worksheets(%27My%20sheet%27%27s%27)/range(address=%27C3%27)": `{"address":"'My sheet''s'!C3"}`,
+		"POST /workbook/tables(%27Orders%27)/rows":                                    `{"index":3}`,
+	})
+	s := &Spreadsheet{
+		metadata: spreadsheetMetadata{Timeout: defaultTimeout},
+		provider: &excel{workbookURL: srv.URL + "/workbook", credential: fakeCredential{}, client: srv.Client()},
+		logger:   logger.NewLogger("test"),
+	}
+
+	// Rows are written below the used range, padded to the same width.
+	result := invoke(t, s, AppendRowOperation, `[["EU", 12, 24], ["US", 5]]`, map[string]string{sheetKey: "Sales"})
+	assert.Equal(t, RangeResult{Range: "Sales!B5:D6", Rows: 2}, result)
+	assert.Equal(t, map[string]interface{}{"values": []interface{}{
+		[]interface{}{"EU", float64(12), float64(24)},
+		[]interface{}{"US", float64(5), ""},
+	}}, (*requests)[1].Body)
+
+	result = invoke(t, s, AppendRowOperation, `["first"]`, map[string]string{rangeKey: "Empty!A:A"})
+	assert.Equal(t, RangeResult{Range: "Empty!A1", Rows: 1}, result)
+
+	result = invoke(t, s, AppendRowOperation, `["x", 1]`, map[string]string{tableKey: "Orders"})
+	assert.Equal(t, RangeResult{Rows: 1}, result)
+
+	result = invoke(t, s, ReadRangeOperation, "", map[string]string{rangeKey: "Sales!A1:B2"})
+	assert.Equal(t, RangeResult{Range: "Sales!A1:B2", Values: [][]interface{}{{"Region", "Total"}, {"EU", float64(12)}}}, result)
+
+	result = invoke(t, s, UpdateCellOperation, `"done"`, map[string]string{cellKey: "'My sheet''s'!C3"})
+	assert.Equal(t, RangeResult{Range: "'My sheet''s'!C3"}, result)
+
+	_, err := s.Invoke(context.Background(), &bindings.InvokeRequest{Operation: ReadRangeOperation, Metadata: map[string]string{rangeKey: "Other!A1"}})
+	assert.ErrorContains(t, err, "graph error itemNotFound: not found")
+	_, err = s.Invoke(context.Background(), &bindings.InvokeRequest{Operation: ReadRangeOperation, Metadata: map[string]string{rangeKey: "A1"}})
+	assert.ErrorContains(t, err, "must be sheet!address")
+}
+
+func TestInit(t *testing.T) {
+	for name, props := range map[string]map[string]string{
+		"no provider":      {},
+		"no spreadsheet":   {"provider": "googlesheets"},
+		"no workbook":      {"provider": "excel", "driveID": "d"},
+		"invalid provider": {"provider": "numbers"},
+	} {
+		s := NewSpreadsheet(logger.NewLogger("test"))
+		assert.Error(t, s.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}), name)
+	}
+}
+
+func TestCells(t *testing.T) {
+	col, row, err := parseCell("$AB$12")
+	require.NoError(t, err)
+	assert.Equal(t, 28, col)
+	assert.Equal(t, 12, row)
+	_, _, err = parseCell("12")
+	assert.Error(t, err)
+	assert.Equal(t, "A", columnName(1))
+	assert.Equal(t, "Z", columnName(26))
+	assert.Equal(t, "AB", columnName(28))
+
+	sheet, address := splitRange("'It''s'!A1:B2")
+	assert.Equal(t, "It's", sheet)
+	assert.Equal(t, "A1:B2", address)
+
+	_, err = parseRows([]byte(`[["a"], "b"]`))
+	assert.Error(t, err)
+	cells, err := parseCells([]byte(`"x"`))
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{"x"}}, cells)
+}
@@ -0,0 +1,498 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultAPIURL  = "https://api.telegram.org"
+	defaultPath    = "/telegram"
+	defaultTimeout = 30 * time.Second
+
+	// secretTokenHeader holds the secret token set with setWebhook in the requests sent by Telegram.
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// maxBodySize limits the size of inbound updates.
+	maxBodySize = 1 << 20
+
+	// keys from request's metadata.
+	chatIDKey          = "chatID"
+	parseModeKey       = "parseMode"
+	captionKey         = "caption"
+	fileNameKey        = "fileName"
+	callbackQueryIDKey = "callbackQueryID"
+
+	// keys from response's metadata.
+	respMessageIDKey  = "messageID"
+	respUpdateIDKey   = "updateID"
+	respUpdateTypeKey = "updateType"
+	respChatIDKey     = "chatID"
+
+	SendMessageOperation    bindings.OperationKind = "sendMessage"
+	SendPhotoOperation      bindings.OperationKind = "sendPhoto"
+	AnswerCallbackOperation bindings.OperationKind = "answerCallback"
+)
+
+// Telegram is a binding calling the Telegram Bot API, and receiving the updates of the bot with a webhook.
+type Telegram struct {
+	metadata telegramMetadata
+	client   *http.Client
+	logger   logger.Logger
+}
+
+type telegramMetadata struct {
+	BotToken string `mapstructure:"botToken"`
+	APIURL   string `mapstructure:"apiURL"`
+	// Chat the messages are sent to, unless overridden by the request.
+	ChatID  string        `mapstructure:"chatID"`
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Port and path of the webhook receiving the updates.
+	Port string `mapstructure:"port"`
+	Path string `mapstructure:"path"`
+	// Secret token Telegram sends with the updates. Requests without it are rejected.
+	SecretToken string `mapstructure:"secretToken"`
+	// Public URL of the webhook. If set, the webhook is registered with setWebhook when reading starts.
+	WebhookURL string `mapstructure:"webhookURL"`
+	// Comma-separated list of the update types received, e.g. message,callback_query. All types are received if empty.
+	AllowedUpdates string `mapstructure:"allowedUpdates"`
+}
+
+// apiResponse is the response of the Bot API methods.
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+	ErrorCode   int             `json:"error_code"`
+}
+
+// NewTelegram returns a new Telegram binding.
+func NewTelegram(logger logger.Logger) bindings.InputOutputBinding {
+	return &Telegram{logger: logger}
+}
+
+// Init performs metadata parsing.
+func (t *Telegram) Init(meta bindings.Metadata) error {
+	m := telegramMetadata{
+		APIURL:  defaultAPIURL,
+		Path:    defaultPath,
+		Timeout: defaultTimeout,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &m)
+	if err != nil {
+		return fmt.Errorf("telegram binding error: %w", err)
+	}
+	if m.BotToken == "" {
+		return errors.New("telegram binding error: missing botToken")
+	}
+	if m.WebhookURL != "" && m.SecretToken == "" {
+		return errors.New("telegram binding error: secretToken is required to register the webhook")
+	}
+	m.APIURL = strings.TrimSuffix(m.APIURL, "/")
+	if !strings.HasPrefix(m.Path, "/") {
+		m.Path = "/" + m.Path
+	}
+	t.metadata = m
+	t.client = &http.Client{Timeout: m.Timeout}
+
+	return nil
+}
+
+// Operations returns list of operations supported by the Telegram binding.
+func (t *Telegram) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		SendMessageOperation,
+		SendPhotoOperation,
+		AnswerCallbackOperation,
+	}
+}
+
+// Invoke calls the Bot API method of the operation.
+// The data is either a JSON object holding the parameters of the method, or the text of the message, the photo or the text of the callback notification.
+func (t *Telegram) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var (
+		result json.RawMessage
+		err    error
+	)
+	switch req.Operation { //nolint:exhaustive
+	case SendMessageOperation:
+		result, err = t.sendMessage(ctx, req)
+	case SendPhotoOperation:
+		result, err = t.sendPhoto(ctx, req)
+	case AnswerCallbackOperation:
+		result, err = t.answerCallback(ctx, req)
+	default:
+		return nil, fmt.Errorf("telegram binding error: unsupported operation %s", req.Operation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("telegram binding error: %s failed: %w", req.Operation, err)
+	}
+
+	md := map[string]string{
+		bindings.ResponseMetadataOperation: string(req.Operation),
+	}
+	var msg struct {
+		MessageID int64 `json:"message_id"`
+	}
+	if json.Unmarshal(result, &msg) == nil && msg.MessageID != 0 {
+		md[respMessageIDKey] = strconv.FormatInt(msg.MessageID, 10)
+	}
+
+	return &bindings.InvokeResponse{
+		Data:     result,
+		Metadata: md,
+	}, nil
+}
+
+func (t *Telegram) sendMessage(ctx context.Context, req *bindings.InvokeRequest) (json.RawMessage, error) {
+	params, ok, err := parseParams(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		params["text"] = string(req.Data)
+	}
+	if err = t.setChatID(params, req.Metadata); err != nil {
+		return nil, err
+	}
+	setParam(params, "parse_mode", req.Metadata[parseModeKey])
+
+	return t.call(ctx, "sendMessage", params)
+}
+
+// sendPhoto sends a photo given by its URL or file ID in a JSON object, or uploads the data.
+func (t *Telegram) sendPhoto(ctx context.Context, req *bindings.InvokeRequest) (json.RawMessage, error) {
+	params, ok, err := parseParams(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.setChatID(params, req.Metadata); err != nil {
+		return nil, err
+	}
+	setParam(params, "caption", req.Metadata[captionKey])
+	setParam(params, "parse_mode", req.Metadata[parseModeKey])
+
+	if ok {
+		return t.call(ctx, "sendPhoto", params)
+	}
+	if len(req.Data) == 0 {
+		return nil, errors.New("missing photo")
+	}
+
+	fileName := req.Metadata[fileNameKey]
+	if fileName == "" {
+		fileName = "photo"
+	}
+	return t.upload(ctx, "sendPhoto", params, "photo", fileName, req.Data)
+}
+
+func (t *Telegram) answerCallback(ctx context.Context, req *bindings.InvokeRequest) (json.RawMessage, error) {
+	params, ok, err := parseParams(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok && len(req.Data) > 0 {
+		params["text"] = string(req.Data)
+	}
+	setParam(params, "callback_query_id", req.Metadata[callbackQueryIDKey])
+	if params["callback_query_id"] == nil {
+		return nil, fmt.Errorf("required metadata %q not set", callbackQueryIDKey)
+	}
+
+	return t.call(ctx, "answerCallbackQuery", params)
+}
+
+func (t *Telegram) setChatID(params map[string]interface{}, md map[string]string) error {
+	setParam(params, "chat_id", md[chatIDKey])
+	setParam(params, "chat_id", t.metadata.ChatID)
+	if params["chat_id"] == nil {
+		return fmt.Errorf("required metadata %q not set", chatIDKey)
+	}
+	return nil
+}
+
+// parseParams returns the parameters of a method if data is a JSON object.
+func parseParams(data []byte) (map[string]interface{}, bool, error) {
+	params := map[string]interface{}{}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return params, false, nil
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, false, fmt.Errorf("invalid parameters: %w", err)
+	}
+	return params, true, nil
+}
+
+// setParam sets a parameter unless it's already set or val is empty.
+func setParam(params map[string]interface{}, name string, val string) {
+	if _, ok := params[name]; ok || val == "" {
+		return
+	}
+	params[name] = val
+}
+
+func (t *Telegram) call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return t.do(ctx, method, "application/json", bytes.NewReader(body))
+}
+
+func (t *Telegram) upload(ctx context.Context, method string, params map[string]interface{}, field, fileName string, data []byte) (json.RawMessage, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range params {
+		val, ok := v.(string)
+		if !ok {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			val = string(raw)
+		}
+		if err := w.WriteField(k, val); err != nil {
+			return nil, err
+		}
+	}
+	part, err := w.CreateFormFile(field, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return t.do(ctx, method, w.FormDataContentType(), &body)
+}
+
+func (t *Telegram) do(ctx context.Context, method, contentType string, body io.Reader) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.metadata.APIURL+"/bot"+t.metadata.BotToken+"/"+method, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		// The URL of the request holds the bot token, which must not be logged
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var apiRes apiResponse
+	err = json.NewDecoder(res.Body).Decode(&apiRes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response with status code %d: %w", res.StatusCode, err)
+	}
+	if !apiRes.OK {
+		return nil, fmt.Errorf("error code %d: %s", apiRes.ErrorCode, apiRes.Description)
+	}
+	return apiRes.Result, nil
+}
+
+// Read registers the webhook, if its URL is set, and starts the HTTP endpoint receiving the updates.
+func (t *Telegram) Read(ctx context.Context, handler bindings.Handler) error {
+	if t.metadata.Port == "" {
+		return errors.New("telegram binding error: port is required to receive updates")
+	}
+
+	if t.metadata.WebhookURL != "" {
+		params := map[string]interface{}{
+			"url":          t.metadata.WebhookURL,
+			"secret_token": t.metadata.SecretToken,
+		}
+		if updates := splitList(t.metadata.AllowedUpdates); len(updates) > 0 {
+			params["allowed_updates"] = updates
+		}
+		if _, err := t.call(ctx, "setWebhook", params); err != nil {
+			return fmt.Errorf("telegram binding error: failed to register the webhook: %w", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(t.metadata.Path, t.serve(handler))
+
+	srv := &http.Server{
+		Addr:              ":" + t.metadata.Port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Run the server in background
+	go func() {
+		t.logger.Debugf("About to start listening for Telegram updates at http://localhost:%s%s", t.metadata.Port, t.metadata.Path)
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.logger.Errorf("Error starting server: %v", err)
+		}
+	}()
+
+	// Close the server when context is canceled
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := srv.Shutdown(shutdownCtx)
+		if err != nil {
+			t.logger.Errorf("Error shutting down server: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// serve handles the updates sent by Telegram. Failing to process an update makes Telegram send it again later.
+func (t *Telegram) serve(handler bindings.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if t.metadata.SecretToken != "" &&
+			subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(t.metadata.SecretToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		res, err := readResponse(body)
+		if err != nil {
+			t.logger.Warnf("telegram binding: rejected update: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		_, err = handler(r.Context(), res)
+		if err != nil {
+			t.logger.Errorf("telegram binding: failed to process update %s: %v", res.Metadata[respUpdateIDKey], err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// readResponse returns the update, with its ID, type and chat as metadata.
+func readResponse(body []byte) (*bindings.ReadResponse, error) {
+	var update map[string]json.RawMessage
+	err := json.Unmarshal(body, &update)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update: %w", err)
+	}
+	rawID, ok := update["update_id"]
+	if !ok {
+		return nil, errors.New("invalid update: missing update_id")
+	}
+
+	md := map[string]string{
+		respUpdateIDKey: string(rawID),
+	}
+	for k, v := range update {
+		if k == "update_id" {
+			continue
+		}
+		md[respUpdateTypeKey] = k
+
+		var content struct {
+			Chat struct {
+				ID int64 `json:"id"`
+			} `json:"chat"`
+			Message struct {
+				Chat struct {
+					ID int64 `json:"id"`
+				} `json:"chat"`
+			} `json:"message"`
+		}
+		if json.Unmarshal(v, &content) == nil {
+			switch {
+			case content.Chat.ID != 0:
+				md[respChatIDKey] = strconv.FormatInt(content.Chat.ID, 10)
+			case content.Message.Chat.ID != 0:
+				// Callback queries hold the message their button was attached to
+				md[respChatIDKey] = strconv.FormatInt(content.Message.Chat.ID, 10)
+			}
+		}
+		break
+	}
+
+	return &bindings.ReadResponse{
+		Data:     body,
+		Metadata: md,
+	}, nil
+}
+
+// OperationsMetadata describes the operations of the Telegram binding.
+func (t *Telegram) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation:        SendMessageOperation,
+			Description:      "Sends a message. The data is either the text of the message, or a JSON object with the parameters of sendMessage.",
+			RequestMetadata:  []string{chatIDKey, parseModeKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respMessageIDKey},
+		},
+		{
+			Operation:        SendPhotoOperation,
+			Description:      "Sends a photo. The data is either the photo to upload, or a JSON object with the parameters of sendPhoto.",
+			RequestMetadata:  []string{chatIDKey, captionKey, parseModeKey, fileNameKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, respMessageIDKey},
+		},
+		{
+			Operation:        AnswerCallbackOperation,
+			Description:      "Answers a callback query sent by an inline keyboard. The data is either the text of the notification, or a JSON object with the parameters of answerCallbackQuery.",
+			RequestMetadata:  []string{callbackQueryIDKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation},
+		},
+	}
+}
+
+func splitList(val string) []string {
+	var res []string
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
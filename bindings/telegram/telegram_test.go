@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func initTelegram(t *testing.T, props map[string]string) *Telegram {
+	t.Helper()
+
+	tg := NewTelegram(logger.NewLogger("test")).(*Telegram)
+	require.NoError(t, tg.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	return tg
+}
+
+func TestInit(t *testing.T) {
+	tg := NewTelegram(logger.NewLogger("test"))
+	assert.Error(t, tg.Init(bindings.Metadata{}))
+	assert.Error(t, tg.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"botToken":   "token",
+		"webhookURL": "https://example.com/telegram",
+	}}}))
+}
+
+type apiCall struct {
+	path        string
+	contentType string
+	params      map[string]interface{}
+	body        string
+}
+
+func newAPIServer(t *testing.T, result string) (*httptest.Server, *[]apiCall) {
+	t.Helper()
+
+	calls := []apiCall{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		call := apiCall{
+			path:        r.URL.Path,
+			contentType: r.Header.Get("Content-Type"),
+			body:        string(body),
+		}
+		_ = json.Unmarshal(body, &call.params)
+		calls = append(calls, call)
+
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok": true, "result": ` + result + `}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestInvoke(t *testing.T) {
+	srv, calls := newAPIServer(t, `{"message_id": 42}`)
+	tg := initTelegram(t, map[string]string{
+		"botToken": "token",
+		"apiURL":   srv.URL,
+		"chatID":   "100",
+	})
+
+	t.Run("send message", func(t *testing.T) {
+		*calls = nil
+		res, err := tg.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: SendMessageOperation,
+			Data:      []byte("hello"),
+			Metadata:  map[string]string{parseModeKey: "MarkdownV2"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "42", res.Metadata[respMessageIDKey])
+		require.Len(t, *calls, 1)
+		assert.Equal(t, "/bottoken/sendMessage", (*calls)[0].path)
+		assert.Equal(t, map[string]interface{}{
+			"chat_id":    "100",
+			"text":       "hello",
+			"parse_mode": "MarkdownV2",
+		}, (*calls)[0].params)
+	})
+
+	t.Run("send message with parameters", func(t *testing.T) {
+		*calls = nil
+		_, err := tg.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: SendMessageOperation,
+			Data:      []byte(`{"text": "Deploy?", "reply_markup": {"inline_keyboard": [[{"text": "Yes", "callback_data": "yes"}]]}}`),
+			Metadata:  map[string]string{chatIDKey: "200"},
+		})
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		assert.Equal(t, "200", (*calls)[0].params["chat_id"])
+		assert.Contains(t, (*calls)[0].params, "reply_markup")
+	})
+
+	t.Run("upload photo", func(t *testing.T) {
+		*calls = nil
+		_, err := tg.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: SendPhotoOperation,
+			Data:      []byte("\x89PNG"),
+			Metadata:  map[string]string{captionKey: "chart", fileNameKey: "chart.png"},
+		})
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		assert.True(t, strings.HasPrefix((*calls)[0].contentType, "multipart/form-data"))
+		assert.Contains(t, (*calls)[0].body, `filename="chart.png"`)
+		assert.Contains(t, (*calls)[0].body, "chart")
+	})
+
+	t.Run("answer callback", func(t *testing.T) {
+		*calls = nil
+		_, err := tg.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: AnswerCallbackOperation,
+			Data:      []byte("Deploying"),
+			Metadata:  map[string]string{callbackQueryIDKey: "cb1"},
+		})
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		assert.Equal(t, "/bottoken/answerCallbackQuery", (*calls)[0].path)
+		assert.Equal(t, map[string]interface{}{"callback_query_id": "cb1", "text": "Deploying"}, (*calls)[0].params)
+
+		_, err = tg.Invoke(context.Background(), &bindings.InvokeRequest{Operation: AnswerCallbackOperation})
+		assert.Error(t, err)
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	srv, _ := newAPIServer(t, `true`)
+	tg := initTelegram(t, map[string]string{"botToken": "token", "apiURL": srv.URL})
+
+	_, err := tg.call(context.Background(), "fail", map[string]interface{}{})
+	assert.ErrorContains(t, err, "chat not found")
+
+	_, err = tg.Invoke(context.Background(), &bindings.InvokeRequest{Operation: SendMessageOperation, Data: []byte("hello")})
+	assert.ErrorContains(t, err, chatIDKey)
+}
+
+func TestServe(t *testing.T) {
+	tg := initTelegram(t, map[string]string{"botToken": "token", "secretToken": "s3cret"})
+
+	var received *bindings.ReadResponse
+	var handlerErr error
+	serve := tg.serve(func(_ context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		received = res
+		return nil, handlerErr
+	})
+	post := func(secret, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+		req.Header.Set(secretTokenHeader, secret)
+		rec := httptest.NewRecorder()
+		serve(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("wrong", `{"update_id": 1}`))
+	assert.Nil(t, received)
+
+	assert.Equal(t, http.StatusOK, post("s3cret", `{"update_id": 1, "message": {"message_id": 5, "chat": {"id": -100}, "text": "hi"}}`))
+	require.NotNil(t, received)
+	assert.Equal(t, "1", received.Metadata[respUpdateIDKey])
+	assert.Equal(t, "message", received.Metadata[respUpdateTypeKey])
+	assert.Equal(t, "-100", received.Metadata[respChatIDKey])
+
+	assert.Equal(t, http.StatusOK, post("s3cret", `{"update_id": 2, "callback_query": {"id": "cb1", "data": "yes", "message": {"chat": {"id": 7}}}}`))
+	assert.Equal(t, "callback_query", received.Metadata[respUpdateTypeKey])
+	assert.Equal(t, "7", received.Metadata[respChatIDKey])
+
+	assert.Equal(t, http.StatusBadRequest, post("s3cret", `{"message": {}}`))
+
+	handlerErr = errors.New("failed")
+	assert.Equal(t, http.StatusInternalServerError, post("s3cret", `{"update_id": 3, "message": {}}`))
+}
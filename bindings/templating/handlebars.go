@@ -0,0 +1,474 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// handlebars is a parsed Handlebars template.
+// The supported subset covers expressions, with paths such as "a.b", "this" and "../a", triple-stash unescaped
+// expressions, comments, whitespace control, the if, unless, each and with blocks, sections and inverted sections,
+// the @index, @key, @first, @last and @root data variables, and calls to the helpers with literal and path arguments.
+type handlebars struct {
+	nodes []hbNode
+}
+
+type hbNode interface{}
+
+type hbText string
+
+type hbMustache struct {
+	call hbCall
+	raw  bool
+}
+
+type hbBlock struct {
+	call     hbCall
+	inverted bool
+	body     []hbNode
+	inverse  []hbNode
+}
+
+type hbCall struct {
+	name string
+	args []hbArg
+}
+
+type hbArg struct {
+	path    string
+	literal interface{}
+}
+
+type hbParseFrame struct {
+	block  *hbBlock
+	inElse bool
+}
+
+// parseHandlebars parses the Handlebars template src.
+func parseHandlebars(src string) (*handlebars, error) {
+	root := &hbBlock{}
+	stack := []*hbParseFrame{{block: root}}
+	current := func() *[]hbNode {
+		top := stack[len(stack)-1]
+		if top.inElse {
+			return &top.block.inverse
+		}
+		return &top.block.body
+	}
+
+	trimNext := false
+	pos := 0
+	for pos < len(src) {
+		i := strings.Index(src[pos:], "{{")
+		text := src[pos:]
+		if i >= 0 {
+			text = src[pos : pos+i]
+		}
+		if trimNext {
+			text = strings.TrimLeftFunc(text, unicode.IsSpace)
+			trimNext = false
+		}
+		if text != "" {
+			*current() = append(*current(), hbText(text))
+		}
+		if i < 0 {
+			break
+		}
+
+		start := pos + i
+		p := start + 2
+		trimLeft := p < len(src) && src[p] == '~'
+		if trimLeft {
+			p++
+		}
+		raw := false
+		closings := []string{"~}}", "}}"}
+		switch {
+		case strings.HasPrefix(src[p:], "!--"):
+			closings = []string{"--~}}", "--}}"}
+		case strings.HasPrefix(src[p:], "{"):
+			raw = true
+			p++
+			closings = []string{"}~}}", "}}}"}
+		}
+		end, closing := -1, ""
+		for _, c := range closings {
+			if e := strings.Index(src[p:], c); e >= 0 && (end < 0 || e < end) {
+				end, closing = e, c
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed tag at offset %d", start)
+		}
+		content := strings.TrimSpace(src[p : p+end])
+		pos = p + end + len(closing)
+		trimNext = strings.Contains(closing, "~")
+		if trimLeft {
+			nodes := *current()
+			if n := len(nodes); n > 0 {
+				if t, ok := nodes[n-1].(hbText); ok {
+					nodes[n-1] = hbText(strings.TrimRightFunc(string(t), unicode.IsSpace))
+				}
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(content, "!"):
+			// Comment.
+		case raw:
+			call, err := parseCall(content)
+			if err != nil {
+				return nil, err
+			}
+			*current() = append(*current(), &hbMustache{call: call, raw: true})
+		case strings.HasPrefix(content, "&"):
+			call, err := parseCall(content[1:])
+			if err != nil {
+				return nil, err
+			}
+			*current() = append(*current(), &hbMustache{call: call, raw: true})
+		case strings.HasPrefix(content, "#"), strings.HasPrefix(content, "^") && strings.TrimSpace(content[1:]) != "":
+			call, err := parseCall(content[1:])
+			if err != nil {
+				return nil, err
+			}
+			block := &hbBlock{call: call, inverted: content[0] == '^'}
+			*current() = append(*current(), block)
+			stack = append(stack, &hbParseFrame{block: block})
+		case strings.TrimSpace(content) == "else", strings.TrimSpace(content) == "^":
+			top := stack[len(stack)-1]
+			if len(stack) == 1 || top.inElse {
+				return nil, fmt.Errorf("unexpected {{%s}}", strings.TrimSpace(content))
+			}
+			top.inElse = true
+		case strings.HasPrefix(content, "/"):
+			name := strings.TrimSpace(content[1:])
+			top := stack[len(stack)-1]
+			if len(stack) == 1 || top.block.call.name != name {
+				return nil, fmt.Errorf("unexpected {{/%s}}", name)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			call, err := parseCall(content)
+			if err != nil {
+				return nil, err
+			}
+			*current() = append(*current(), &hbMustache{call: call})
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("unclosed block {{#%s}}", stack[len(stack)-1].block.call.name)
+	}
+
+	return &handlebars{nodes: root.body}, nil
+}
+
+// parseCall parses the name and the arguments of an expression.
+func parseCall(content string) (hbCall, error) {
+	var tokens []string
+	s := strings.TrimSpace(content)
+	for s != "" {
+		if s[0] == '"' || s[0] == '\'' {
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				return hbCall{}, fmt.Errorf("unterminated string in {{%s}}", content)
+			}
+			tokens = append(tokens, s[:end+2])
+			s = strings.TrimSpace(s[end+2:])
+			continue
+		}
+		end := strings.IndexFunc(s, unicode.IsSpace)
+		if end < 0 {
+			end = len(s)
+		}
+		tokens = append(tokens, s[:end])
+		s = strings.TrimSpace(s[end:])
+	}
+	if len(tokens) == 0 {
+		return hbCall{}, errors.New("empty expression {{}}")
+	}
+
+	call := hbCall{name: tokens[0]}
+	for _, t := range tokens[1:] {
+		call.args = append(call.args, parseArg(t))
+	}
+	return call, nil
+}
+
+func parseArg(token string) hbArg {
+	switch {
+	case token[0] == '"' || token[0] == '\'':
+		return hbArg{literal: token[1 : len(token)-1]}
+	case token == "true", token == "false":
+		return hbArg{literal: token == "true"}
+	case token == "null", token == "undefined":
+		return hbArg{literal: nil}
+	}
+	if _, err := strconv.ParseFloat(token, 64); err == nil {
+		return hbArg{literal: json.Number(token)}
+	}
+	return hbArg{path: token}
+}
+
+// hbFrame is a context of the evaluation: the current value, and the data variables of each blocks.
+type hbFrame struct {
+	value  interface{}
+	data   map[string]interface{}
+	parent *hbFrame
+}
+
+func (h *handlebars) execute(sb *strings.Builder, model interface{}, helpers map[string]helper) error {
+	return hbRender(sb, h.nodes, &hbFrame{value: model}, helpers)
+}
+
+func hbRender(sb *strings.Builder, nodes []hbNode, frame *hbFrame, helpers map[string]helper) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case hbText:
+			sb.WriteString(string(n))
+		case *hbMustache:
+			v, err := hbEval(n.call, frame, helpers)
+			if err != nil {
+				return err
+			}
+			if n.raw {
+				sb.WriteString(toString(v))
+			} else {
+				sb.WriteString(hbEscaper.Replace(toString(v)))
+			}
+		case *hbBlock:
+			if err := hbRenderBlock(sb, n, frame, helpers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hbRenderBlock(sb *strings.Builder, b *hbBlock, frame *hbFrame, helpers map[string]helper) error {
+	var arg interface{}
+	switch b.call.name {
+	case "if", "unless", "each", "with":
+		if len(b.call.args) != 1 {
+			return fmt.Errorf("{{#%s}} takes one argument", b.call.name)
+		}
+		arg = hbArgValue(b.call.args[0], frame)
+	default:
+		if len(b.call.args) > 0 {
+			return fmt.Errorf("unknown block helper %s", b.call.name)
+		}
+		arg = hbResolve(b.call.name, frame)
+	}
+
+	switch {
+	case b.inverted:
+		if !hbTruthy(arg) {
+			return hbRender(sb, b.body, frame, helpers)
+		}
+		return nil
+	case b.call.name == "if":
+		if hbTruthy(arg) {
+			return hbRender(sb, b.body, frame, helpers)
+		}
+		return hbRender(sb, b.inverse, frame, helpers)
+	case b.call.name == "unless":
+		if !hbTruthy(arg) {
+			return hbRender(sb, b.body, frame, helpers)
+		}
+		return hbRender(sb, b.inverse, frame, helpers)
+	}
+
+	switch v := arg.(type) {
+	case []interface{}:
+		if b.call.name != "with" {
+			if len(v) == 0 {
+				return hbRender(sb, b.inverse, frame, helpers)
+			}
+			for i, e := range v {
+				child := &hbFrame{value: e, parent: frame, data: map[string]interface{}{
+					"index": i, "key": i, "first": i == 0, "last": i == len(v)-1,
+				}}
+				if err := hbRender(sb, b.body, child, helpers); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case map[string]interface{}:
+		if b.call.name == "each" {
+			if len(v) == 0 {
+				return hbRender(sb, b.inverse, frame, helpers)
+			}
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for i, k := range keys {
+				child := &hbFrame{value: v[k], parent: frame, data: map[string]interface{}{
+					"index": i, "key": k, "first": i == 0, "last": i == len(keys)-1,
+				}}
+				if err := hbRender(sb, b.body, child, helpers); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	default:
+		if b.call.name == "each" {
+			return hbRender(sb, b.inverse, frame, helpers)
+		}
+		if b.call.name != "with" && hbTruthy(v) {
+			// Sections of other values keep the context.
+			return hbRender(sb, b.body, frame, helpers)
+		}
+	}
+	if !hbTruthy(arg) {
+		return hbRender(sb, b.inverse, frame, helpers)
+	}
+	return hbRender(sb, b.body, &hbFrame{value: arg, parent: frame}, helpers)
+}
+
+// hbEval returns the value of an expression: the result of the helper it calls, or the value of its path.
+func hbEval(call hbCall, frame *hbFrame, helpers map[string]helper) (interface{}, error) {
+	h, ok := helpers[call.name]
+	if !ok {
+		if len(call.args) > 0 {
+			return nil, fmt.Errorf("unknown helper %s", call.name)
+		}
+		return hbResolve(call.name, frame), nil
+	}
+
+	args := make([]interface{}, len(call.args))
+	for i, a := range call.args {
+		args[i] = hbArgValue(a, frame)
+	}
+	var v interface{}
+	if len(args) > 0 {
+		v, args = args[0], args[1:]
+	}
+	res, err := h(v, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", call.name, err)
+	}
+	return res, nil
+}
+
+func hbArgValue(a hbArg, frame *hbFrame) interface{} {
+	if a.path == "" {
+		return a.literal
+	}
+	return hbResolve(a.path, frame)
+}
+
+// hbResolve returns the value of the path in the frame, nil if there is none.
+func hbResolve(path string, frame *hbFrame) interface{} {
+	if strings.HasPrefix(path, "@") {
+		name, rest, _ := strings.Cut(path[1:], ".")
+		if name == "root" {
+			for frame.parent != nil {
+				frame = frame.parent
+			}
+			if rest == "" {
+				return frame.value
+			}
+			return hbLookup(frame.value, rest)
+		}
+		for f := frame; f != nil; f = f.parent {
+			if v, ok := f.data[name]; ok {
+				return v
+			}
+		}
+		return nil
+	}
+
+	for strings.HasPrefix(path, "../") {
+		path = path[3:]
+		if frame.parent != nil {
+			frame = frame.parent
+		}
+	}
+	if path == ".." {
+		if frame.parent != nil {
+			frame = frame.parent
+		}
+		return frame.value
+	}
+	switch {
+	case path == "this", path == ".":
+		return frame.value
+	case strings.HasPrefix(path, "this."), strings.HasPrefix(path, "this/"):
+		path = path[5:]
+	case strings.HasPrefix(path, "./"):
+		path = path[2:]
+	}
+	return hbLookup(frame.value, path)
+}
+
+func hbLookup(v interface{}, path string) interface{} {
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '/' }) {
+		switch o := v.(type) {
+		case map[string]interface{}:
+			v = o[segment]
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(o) {
+				return nil
+			}
+			v = o[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func hbTruthy(v interface{}) bool {
+	switch o := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return o
+	case string:
+		return o != ""
+	case json.Number:
+		f, err := o.Float64()
+		return err != nil || f != 0
+	case float64:
+		return o != 0
+	case int:
+		return o != 0
+	case []interface{}:
+		return len(o) > 0
+	default:
+		return true
+	}
+}
+
+var hbEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&#x27;",
+	"`", "&#x60;",
+	"=", "&#x3D;",
+)
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderHandlebars(t *testing.T, src string, data string) string {
+	t.Helper()
+
+	h, err := parseHandlebars(src)
+	require.NoError(t, err)
+	var model interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &model))
+	out, err := h.render(model, locale{}.helpers())
+	require.NoError(t, err)
+	return out
+}
+
+func TestHandlebars(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		data     string
+		expected string
+	}{
+		{"expressions", "Hello {{name}}, {{address.city}} {{tags.1}}!", `{"name":"Ada","address":{"city":"London"},"tags":["a","b"]}`, "Hello Ada, London b!"},
+		{"escaping", `{{v}} {{{v}}} {{& v}}`, `{"v":"<b>&"}`, "&lt;b&gt;&amp; <b>& <b>&"},
+		{"missing value", "[{{missing.value}}]", `{}`, "[]"},
+		{"comments", "a{{! comment }}b{{!-- {{x}} --}}c", `{}`, "abc"},
+		{"if else", "{{#if ok}}yes{{else}}no{{/if}} {{#if zero}}yes{{else}}no{{/if}}", `{"ok":true,"zero":0}`, "yes no"},
+		{"unless", "{{#unless ok}}no{{/unless}}", `{"ok":false}`, "no"},
+		{"each array", "{{#each items}}{{@index}}:{{name}}{{#unless @last}}, {{/unless}}{{/each}}", `{"items":[{"name":"a"},{"name":"b"}]}`, "0:a, 1:b"},
+		{"each object", "{{#each m}}{{@key}}={{this}};{{/each}}", `{"m":{"b":2,"a":1}}`, "a=1;b=2;"},
+		{"each empty", "{{#each items}}x{{else}}none{{/each}}", `{"items":[]}`, "none"},
+		{"parent path", "{{#each items}}{{this}}{{../sep}}{{/each}}", `{"items":["a","b"],"sep":"|"}`, "a|b|"},
+		{"root", "{{#each items}}{{@root.sep}}{{/each}}", `{"items":[1,2],"sep":"-"}`, "--"},
+		{"with", "{{#with user}}{{first}} {{last}}{{/with}}", `{"user":{"first":"Ada","last":"Lovelace"}}`, "Ada Lovelace"},
+		{"sections", "{{#items}}{{.}}{{/items}}{{^none}}empty{{/none}}", `{"items":["x","y"]}`, "xyempty"},
+		{"whitespace control", "a  {{~ name ~}}  b", `{"name":"-"}`, "a-b"},
+		{"helpers", `{{upper name}} {{default nickname "friend"}} {{formatNumber n 2}}`, `{"name":"ada","n":3}`, "ADA friend 3.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, renderHandlebars(t, tt.src, tt.data))
+		})
+	}
+}
+
+func TestHandlebarsErrors(t *testing.T) {
+	for _, src := range []string{"{{name", "{{#if a}}x", "{{#if a}}x{{/each}}", "{{/if}}", "{{else}}", `{{upper "x}}`} {
+		_, err := parseHandlebars(src)
+		assert.Error(t, err, src)
+	}
+
+	h, err := parseHandlebars("{{unknown a b}}")
+	require.NoError(t, err)
+	_, err = h.render(nil, locale{}.helpers())
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// helper is a function available in the templates of both engines.
+// v is the first argument, nil for helpers called without arguments in Handlebars templates.
+type helper func(v interface{}, args ...interface{}) (string, error)
+
+// locale holds the settings the formatting helpers use.
+type locale struct {
+	tag      language.Tag
+	currency string
+	location *time.Location
+}
+
+// Languages placing the currency symbol after the amount.
+var symbolAfterAmount = map[string]bool{
+	"cs": true, "da": true, "de": true, "es": true, "fi": true, "fr": true, "it": true,
+	"nb": true, "pl": true, "ru": true, "sk": true, "sv": true, "uk": true,
+}
+
+// helpers returns the helpers formatting values according to l.
+func (l locale) helpers() map[string]helper {
+	p := message.NewPrinter(l.tag)
+	return map[string]helper{
+		"formatNumber": func(v interface{}, args ...interface{}) (string, error) {
+			f, err := toFloat(v)
+			if err != nil {
+				return "", err
+			}
+			if len(args) == 0 {
+				return p.Sprint(number.Decimal(f)), nil
+			}
+			decimals, err := toInt(args[0])
+			if err != nil {
+				return "", err
+			}
+			return p.Sprint(number.Decimal(f, number.Scale(decimals))), nil
+		},
+		"formatPercent": func(v interface{}, args ...interface{}) (string, error) {
+			f, err := toFloat(v)
+			if err != nil {
+				return "", err
+			}
+			decimals := 0
+			if len(args) > 0 {
+				decimals, err = toInt(args[0])
+				if err != nil {
+					return "", err
+				}
+			}
+			return p.Sprint(number.Percent(f, number.Scale(decimals))), nil
+		},
+		"formatCurrency": func(v interface{}, args ...interface{}) (string, error) {
+			f, err := toFloat(v)
+			if err != nil {
+				return "", err
+			}
+			code := l.currency
+			if len(args) > 0 {
+				code = toString(args[0])
+			}
+			return l.formatCurrency(p, f, code)
+		},
+		"formatDate": func(v interface{}, args ...interface{}) (string, error) {
+			t, err := toTime(v)
+			if err != nil {
+				return "", err
+			}
+			layout := time.RFC3339
+			if len(args) > 0 {
+				layout = dateLayout(toString(args[0]))
+			}
+			return t.In(l.location).Format(layout), nil
+		},
+		"upper": func(v interface{}, args ...interface{}) (string, error) {
+			return strings.ToUpper(toString(v)), nil
+		},
+		"lower": func(v interface{}, args ...interface{}) (string, error) {
+			return strings.ToLower(toString(v)), nil
+		},
+		"default": func(v interface{}, args ...interface{}) (string, error) {
+			// Returns the first non-empty argument: {{default name "there"}}.
+			for _, a := range append([]interface{}{v}, args...) {
+				if s := toString(a); s != "" {
+					return s, nil
+				}
+			}
+			return "", nil
+		},
+		"json": func(v interface{}, args ...interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+}
+
+// formatCurrency formats the amount in the currency with the ISO 4217 code, rounded half to even to the standard number
+// of decimals of the currency. Symbols are separated from the amount by a no-break space.
+func (l locale) formatCurrency(p *message.Printer, amount float64, code string) (string, error) {
+	if code == "" {
+		return "", fmt.Errorf("no currency: set the currency metadata or pass the currency code")
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", fmt.Errorf("invalid currency %q: %w", code, err)
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	symbol := strings.TrimSpace(p.Sprint(currency.Symbol(unit)))
+	formatted := p.Sprint(number.Decimal(amount, number.Scale(scale)))
+	if base, _ := l.tag.Base(); symbolAfterAmount[base.String()] {
+		return formatted + "\u00a0" + symbol, nil
+	}
+	if strings.Trim(symbol, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		// Symbols such as "CHF" are separated from the amount in every locale.
+		return symbol + "\u00a0" + formatted, nil
+	}
+	return symbol + formatted, nil
+}
+
+// dateLayout returns the Go layout of the named date formats, or name itself.
+func dateLayout(name string) string {
+	switch name {
+	case "date":
+		return "2006-01-02"
+	case "time":
+		return "15:04"
+	case "datetime":
+		return "2006-01-02 15:04"
+	case "rfc1123":
+		return time.RFC1123
+	case "rfc3339":
+		return time.RFC3339
+	default:
+		return name
+	}
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case json.Number:
+		return n.Float64()
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
+
+func toInt(v interface{}) (int, error) {
+	f, err := toFloat(v)
+	return int(f), err
+}
+
+// toTime converts RFC 3339 strings and numbers of seconds since the epoch.
+func toTime(v interface{}) (time.Time, error) {
+	if s, ok := v.(string); ok {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v is not a time", v)
+	}
+	return time.Unix(0, int64(f*float64(time.Second))), nil
+}
+
+// toString returns the text of v as rendered in templates.
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case json.Number:
+		return s.String()
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(s)
+	case []interface{}:
+		parts := make([]string, len(s))
+		for i, e := range s {
+			parts[i] = toString(e)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
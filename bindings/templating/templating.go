@@ -0,0 +1,409 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultLocale           = "en-US"
+	defaultForwardOperation = "create"
+	defaultDaprHTTPPort     = "3500"
+	defaultTimeout          = 30 * time.Second
+
+	// Values of engine.
+	engineGo         = "go"
+	engineHandlebars = "handlebars"
+
+	// Prefix of the component metadata holding inline templates, followed by the name of the template.
+	inlineTemplatePrefix = "template."
+
+	// Size of the response body included in the errors of failed forwards.
+	maxErrorBodySize = 512
+
+	// keys from request's metadata.
+	templateKey         = "template"
+	localeKey           = "locale"
+	currencyKey         = "currency"
+	timeZoneKey         = "timeZone"
+	forwardToKey        = "forwardTo"
+	forwardOperationKey = "forwardOperation"
+	// Prefix of the request metadata passed to the binding the rendered text is forwarded to.
+	forwardMetadataPrefix = "forward."
+
+	// keys from response's metadata.
+	forwardedToKey = "forwardedTo"
+
+	RenderOperation bindings.OperationKind = "render"
+)
+
+// Templating is an output binding rendering Go templates or Handlebars templates with the data of the requests.
+// The rendered text is returned, or forwarded to another binding through the Dapr HTTP API.
+type Templating struct {
+	metadata  templatingMetadata
+	templates map[string]renderer
+	locale    locale
+	client    *http.Client
+	logger    logger.Logger
+}
+
+type templatingMetadata struct {
+	// Directory of the template files, named after the file names without extension.
+	// Files with the .hbs, .handlebars and .mustache extensions are Handlebars templates, .html and .htm files
+	// rendered with the Go engine are HTML templates escaping the values.
+	TemplatesPath string `mapstructure:"templatesPath"`
+	// Engine of the inline templates and of the other template files: "go" or "handlebars".
+	Engine string `mapstructure:"engine"`
+
+	// Locale is the BCP 47 language tag used to format numbers, percentages and amounts.
+	Locale string `mapstructure:"locale"`
+	// Currency is the ISO 4217 code of the amounts formatted without currency.
+	Currency string `mapstructure:"currency"`
+	// TimeZone is the IANA time zone of the formatted dates.
+	TimeZone string `mapstructure:"timeZone"`
+
+	// ForwardTo is the name of the binding the rendered text is sent to, if any.
+	ForwardTo        string        `mapstructure:"forwardTo"`
+	ForwardOperation string        `mapstructure:"forwardOperation"`
+	DaprHTTPPort     string        `mapstructure:"daprHTTPPort"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+}
+
+// renderer is a parsed template.
+type renderer interface {
+	render(model interface{}, helpers map[string]helper) (string, error)
+}
+
+// NewTemplating returns a new templating binding.
+func NewTemplating(logger logger.Logger) bindings.OutputBinding {
+	return &Templating{logger: logger}
+}
+
+// Init parses the metadata and the templates of the binding.
+func (t *Templating) Init(meta bindings.Metadata) error {
+	t.metadata = templatingMetadata{
+		Engine:           engineGo,
+		Locale:           defaultLocale,
+		TimeZone:         "UTC",
+		ForwardOperation: defaultForwardOperation,
+		DaprHTTPPort:     os.Getenv("DAPR_HTTP_PORT"),
+		Timeout:          defaultTimeout,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &t.metadata)
+	if err != nil {
+		return fmt.Errorf("templating binding error: %w", err)
+	}
+	if t.metadata.Engine != engineGo && t.metadata.Engine != engineHandlebars {
+		return fmt.Errorf("templating binding error: invalid engine %s: must be %s or %s", t.metadata.Engine, engineGo, engineHandlebars)
+	}
+	if t.metadata.DaprHTTPPort == "" {
+		t.metadata.DaprHTTPPort = defaultDaprHTTPPort
+	}
+	t.locale, err = parseLocale(locale{}, t.metadata.Locale, t.metadata.Currency, t.metadata.TimeZone)
+	if err != nil {
+		return fmt.Errorf("templating binding error: %w", err)
+	}
+
+	t.templates = map[string]renderer{}
+	if t.metadata.TemplatesPath != "" {
+		err = t.loadTemplates(t.metadata.TemplatesPath)
+		if err != nil {
+			return fmt.Errorf("templating binding error: %w", err)
+		}
+	}
+	for k, v := range meta.Properties {
+		if name := strings.TrimPrefix(k, inlineTemplatePrefix); name != k && name != "" {
+			t.templates[name], err = parseTemplate(name, t.metadata.Engine, false, v)
+			if err != nil {
+				return fmt.Errorf("templating binding error: %w", err)
+			}
+		}
+	}
+	if len(t.templates) == 0 {
+		return errors.New("templating binding error: no templates: set templatesPath or template.<name> metadata")
+	}
+	t.client = &http.Client{Timeout: t.metadata.Timeout}
+
+	return nil
+}
+
+// loadTemplates parses the template files of dir.
+func (t *Templating) loadTemplates(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the templates: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read the template %s: %w", e.Name(), err)
+		}
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		engine := t.metadata.Engine
+		switch ext {
+		case ".hbs", ".handlebars", ".mustache":
+			engine = engineHandlebars
+		case ".tmpl", ".gotmpl":
+			engine = engineGo
+		}
+		if _, ok := t.templates[name]; ok {
+			return fmt.Errorf("duplicate template %s", name)
+		}
+		t.templates[name], err = parseTemplate(name, engine, ext == ".html" || ext == ".htm", string(src))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseTemplate(name string, engine string, html bool, src string) (renderer, error) {
+	var (
+		r   renderer
+		err error
+	)
+	switch {
+	case engine == engineHandlebars:
+		r, err = parseHandlebars(src)
+	case html:
+		var tmpl *htmltemplate.Template
+		tmpl, err = htmltemplate.New(name).Funcs(funcMap(locale{}.helpers())).Parse(src)
+		r = &htmlTemplate{tmpl}
+	default:
+		var tmpl *texttemplate.Template
+		tmpl, err = texttemplate.New(name).Funcs(funcMap(locale{}.helpers())).Parse(src)
+		r = &textTemplate{tmpl}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the template %s: %w", name, err)
+	}
+	return r, nil
+}
+
+// parseLocale returns base with the non-empty locale, currency and time zone.
+func parseLocale(base locale, tag string, currency string, timeZone string) (locale, error) {
+	l := base
+	if tag != "" {
+		t, err := language.Parse(tag)
+		if err != nil {
+			return l, fmt.Errorf("invalid locale %s: %w", tag, err)
+		}
+		l.tag = t
+	}
+	if currency != "" {
+		l.currency = currency
+	}
+	if timeZone != "" {
+		loc, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return l, fmt.Errorf("invalid time zone %s: %w", timeZone, err)
+		}
+		l.location = loc
+	}
+	return l, nil
+}
+
+// Operations returns the operations supported by the templating binding.
+func (t *Templating) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{RenderOperation}
+}
+
+// Invoke renders the template named by the "template" metadata with the JSON data of the request.
+func (t *Templating) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != RenderOperation {
+		return nil, fmt.Errorf("templating binding error: unsupported operation %s", req.Operation)
+	}
+
+	name := req.Metadata[templateKey]
+	if name == "" && len(t.templates) == 1 {
+		for n := range t.templates {
+			name = n
+		}
+	}
+	tmpl, ok := t.templates[name]
+	if !ok {
+		if name == "" {
+			return nil, fmt.Errorf("templating binding error: the %s metadata is required", templateKey)
+		}
+		return nil, fmt.Errorf("templating binding error: unknown template %s", name)
+	}
+
+	var model interface{}
+	if len(bytes.TrimSpace(req.Data)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(req.Data))
+		// Numbers are kept as written: integers such as identifiers aren't rendered in exponent notation.
+		dec.UseNumber()
+		if err := dec.Decode(&model); err != nil {
+			return nil, fmt.Errorf("templating binding error: the data must be JSON: %w", err)
+		}
+	}
+	l, err := parseLocale(t.locale, req.Metadata[localeKey], req.Metadata[currencyKey], req.Metadata[timeZoneKey])
+	if err != nil {
+		return nil, fmt.Errorf("templating binding error: %w", err)
+	}
+
+	rendered, err := tmpl.render(model, l.helpers())
+	if err != nil {
+		return nil, fmt.Errorf("templating binding error: failed to render the template %s: %w", name, err)
+	}
+
+	res := &bindings.InvokeResponse{
+		Data: []byte(rendered),
+		Metadata: map[string]string{
+			bindings.ResponseMetadataOperation: string(req.Operation),
+		},
+	}
+	forwardTo := t.metadata.ForwardTo
+	if v, ok := req.Metadata[forwardToKey]; ok {
+		forwardTo = v
+	}
+	if forwardTo != "" {
+		operation := t.metadata.ForwardOperation
+		if v := req.Metadata[forwardOperationKey]; v != "" {
+			operation = v
+		}
+		res.Data, err = t.forward(ctx, forwardTo, operation, rendered, req.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("templating binding error: failed to forward to %s: %w", forwardTo, err)
+		}
+		res.Metadata[forwardedToKey] = forwardTo
+	}
+
+	return res, nil
+}
+
+// forward invokes the operation of the binding with the rendered text, and returns the response of the binding.
+func (t *Templating) forward(ctx context.Context, binding string, operation string, rendered string, reqMetadata map[string]string) ([]byte, error) {
+	forwardMetadata := map[string]string{}
+	for k, v := range reqMetadata {
+		if name := strings.TrimPrefix(k, forwardMetadataPrefix); name != k && name != "" {
+			forwardMetadata[name] = v
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"data":      rendered,
+		"metadata":  forwardMetadata,
+		"operation": operation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := "http://localhost:" + t.metadata.DaprHTTPPort + "/v1.0/bindings/" + url.PathEscape(binding)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("DAPR_API_TOKEN"); token != "" {
+		req.Header.Set("dapr-api-token", token)
+	}
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		if len(resBody) > maxErrorBodySize {
+			resBody = resBody[:maxErrorBodySize]
+		}
+		return nil, fmt.Errorf("unexpected status code %d: %s", res.StatusCode, resBody)
+	}
+	return resBody, nil
+}
+
+// OperationsMetadata describes the operations of the templating binding.
+func (t *Templating) OperationsMetadata() []bindings.OperationMetadata {
+	return []bindings.OperationMetadata{
+		{
+			Operation: RenderOperation,
+			Description: "Renders the template named by the template metadata with the JSON data, formatting numbers, amounts and dates " +
+				"with the locale, currency and time zone metadata. Returns the rendered text, or the response of the binding " +
+				"named by the forwardTo metadata it is sent to, with the forward.* metadata.",
+			RequestMetadata:  []string{templateKey, localeKey, currencyKey, timeZoneKey, forwardToKey, forwardOperationKey},
+			ResponseMetadata: []string{bindings.ResponseMetadataOperation, forwardedToKey},
+		},
+	}
+}
+
+type textTemplate struct {
+	tmpl *texttemplate.Template
+}
+
+func (t *textTemplate) render(model interface{}, helpers map[string]helper) (string, error) {
+	// The parsed template is never executed, so that clones can get the helpers of the request's locale.
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = tmpl.Funcs(funcMap(helpers)).Execute(&sb, model)
+	return sb.String(), err
+}
+
+type htmlTemplate struct {
+	tmpl *htmltemplate.Template
+}
+
+func (t *htmlTemplate) render(model interface{}, helpers map[string]helper) (string, error) {
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = tmpl.Funcs(funcMap(helpers)).Execute(&sb, model)
+	return sb.String(), err
+}
+
+func (h *handlebars) render(model interface{}, helpers map[string]helper) (string, error) {
+	var sb strings.Builder
+	err := h.execute(&sb, model, helpers)
+	return sb.String(), err
+}
+
+func funcMap(helpers map[string]helper) map[string]interface{} {
+	m := make(map[string]interface{}, len(helpers))
+	for name, h := range helpers {
+		m[name] = h
+	}
+	return m
+}
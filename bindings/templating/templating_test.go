@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func newTemplating(t *testing.T, props map[string]string) *Templating {
+	t.Helper()
+
+	b := NewTemplating(logger.NewLogger("test")).(*Templating)
+	require.NoError(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}))
+	return b
+}
+
+func render(t *testing.T, b *Templating, data string, reqMetadata map[string]string) string {
+	t.Helper()
+
+	res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: RenderOperation,
+		Data:      []byte(data),
+		Metadata:  reqMetadata,
+	})
+	require.NoError(t, err)
+	return string(res.Data)
+}
+
+func TestInit(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "welcome.hbs"), []byte("Hi {{name}}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "receipt.html"), []byte("<p>{{.name}}</p>"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "subject.txt"), []byte("Order {{.id}}"), 0o600))
+
+	b := newTemplating(t, map[string]string{"templatesPath": dir, "template.inline": "{{.name}}!"})
+	assert.Len(t, b.templates, 4)
+	data := `{"name":"<Ada>","id":12345678}`
+	assert.Equal(t, "Hi &lt;Ada&gt;", render(t, b, data, map[string]string{templateKey: "welcome"}))
+	assert.Equal(t, "<p>&lt;Ada&gt;</p>", render(t, b, data, map[string]string{templateKey: "receipt"}))
+	assert.Equal(t, "Order 12345678", render(t, b, data, map[string]string{templateKey: "subject"}))
+	assert.Equal(t, "<Ada>!", render(t, b, data, map[string]string{templateKey: "inline"}))
+
+	_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{Operation: RenderOperation, Data: []byte(data)})
+	assert.ErrorContains(t, err, "the template metadata is required")
+	_, err = b.Invoke(context.Background(), &bindings.InvokeRequest{Operation: RenderOperation, Metadata: map[string]string{templateKey: "other"}})
+	assert.ErrorContains(t, err, "unknown template other")
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, props := range map[string]map[string]string{
+			"no templates":     {},
+			"invalid engine":   {"template.a": "x", "engine": "jinja"},
+			"invalid locale":   {"template.a": "x", "locale": "not a locale"},
+			"invalid timezone": {"template.a": "x", "timeZone": "Mars/Olympus"},
+			"invalid template": {"template.a": "{{.x"},
+			"missing path":     {"templatesPath": filepath.Join(dir, "missing")},
+		} {
+			b := NewTemplating(logger.NewLogger("test"))
+			assert.Error(t, b.Init(bindings.Metadata{Base: metadata.Base{Properties: props}}), name)
+		}
+	})
+}
+
+func TestLocaleHelpers(t *testing.T) {
+	b := newTemplating(t, map[string]string{
+		"template.go": `{{formatCurrency .total}} {{formatNumber .count}} {{formatPercent .rate 1}} {{formatDate .at "datetime"}}`,
+		"currency":    "USD",
+		"timeZone":    "America/New_York",
+	})
+	data := `{"total":1234.5,"count":1234567,"rate":0.125,"at":"2023-01-01T12:00:00Z"}`
+
+	assert.Equal(t, "$1,234.50 1,234,567 12.5% 2023-01-01 07:00", render(t, b, data, map[string]string{templateKey: "go"}))
+	assert.Equal(t, "1.234,50\u00a0€ 1.234.567 12,5\u00a0% 2023-01-01 13:00", render(t, b, data, map[string]string{
+		templateKey: "go", localeKey: "de-DE", currencyKey: "EUR", timeZoneKey: "Europe/Berlin",
+	}))
+
+	hbs, err := parseTemplate("hbs", engineHandlebars, false, `{{formatCurrency total "JPY"}} {{formatCurrency total "CHF"}}`)
+	require.NoError(t, err)
+	b.templates["hbs"] = hbs
+	assert.Equal(t, "¥1,234 CHF\u00a01,234.50", render(t, b, data, map[string]string{templateKey: "hbs"}))
+
+	_, err = b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: RenderOperation,
+		Data:      []byte(`{"total":"many"}`),
+		Metadata:  map[string]string{templateKey: "go"},
+	})
+	assert.Error(t, err)
+}
+
+func TestForward(t *testing.T) {
+	var received map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1.0/bindings/smtp", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.Write([]byte(`{"sent":true}`))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	b := newTemplating(t, map[string]string{
+		"template.mail": "Hello {{.name}}",
+		"daprHTTPPort":  u.Port(),
+		"forwardTo":     "smtp",
+	})
+	res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: RenderOperation,
+		Data:      []byte(`{"name":"Ada"}`),
+		Metadata:  map[string]string{"forward.emailTo": "ada@example.com", templateKey: "mail"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"sent":true}`, string(res.Data))
+	assert.Equal(t, "smtp", res.Metadata[forwardedToKey])
+	assert.Equal(t, map[string]interface{}{
+		"data":      "Hello Ada",
+		"metadata":  map[string]interface{}{"emailTo": "ada@example.com"},
+		"operation": "create",
+	}, received)
+
+	// The forwardTo request metadata overrides the component's one.
+	res, err = b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: RenderOperation,
+		Data:      []byte(`{"name":"Ada"}`),
+		Metadata:  map[string]string{forwardToKey: ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Ada", string(res.Data))
+	assert.NotContains(t, res.Metadata, forwardedToKey)
+}
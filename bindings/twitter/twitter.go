@@ -89,10 +89,10 @@ func (t *Binding) Read(ctx context.Context, handler bindings.Handler) error {
 
 	demux := twitter.NewSwitchDemux()
 	demux.Tweet = func(tweet *twitter.Tweet) {
-		t.logger.Debugf("raw tweet: %+v", tweet)
+		t.logger.Debugf("raw tweet: %s", tweet.IDStr)
 		data, marshalErr := json.Marshal(tweet)
 		if marshalErr != nil {
-			t.logger.Errorf("error marshaling tweet: %+v", tweet)
+			t.logger.Errorf("error marshaling tweet %s: %v", tweet.IDStr, marshalErr)
 
 			return
 		}
@@ -188,7 +188,7 @@ func (t *Binding) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bin
 		return nil, errors.Wrapf(err, "nil search result from: %+v", sq)
 	}
 
-	t.logger.Debugf("raw response: %+v", search.Statuses)
+	t.logger.Debugf("raw response: %d tweets", len(search.Statuses))
 	data, marshalErr := json.Marshal(search.Statuses)
 	if marshalErr != nil {
 		t.logger.Errorf("error marshaling tweet: %v", marshalErr)
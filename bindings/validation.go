@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/schema"
+	"github.com/dapr/kit/logger"
+)
+
+// validatingInputBinding validates the events read by an input binding against a JSON Schema.
+type validatingInputBinding struct {
+	inputForwarder
+
+	validator *schema.Validator
+	logger    logger.Logger
+}
+
+// NewValidatingInputBinding wraps an InputBinding so that events are validated against the JSON Schema set in the "validationSchema" metadata.
+// Invalid events are not delivered to the app: the handler returns an error, so the binding's own redelivery and dead-letter handling applies.
+// Validation is disabled when no schema is set.
+func NewValidatingInputBinding(inner InputBinding, logger logger.Logger) InputBinding {
+	return exposeOptionalInput(&validatingInputBinding{
+		inputForwarder: inputForwarder{inner},
+		logger:         logger,
+	}, inner)
+}
+
+func (b *validatingInputBinding) Init(metadata Metadata) (err error) {
+	b.validator, err = newValidator(metadata)
+	if err != nil {
+		return err
+	}
+
+	return b.InputBinding.Init(metadata)
+}
+
+func (b *validatingInputBinding) Read(ctx context.Context, handler Handler) error {
+	if b.validator == nil {
+		return b.InputBinding.Read(ctx, handler)
+	}
+
+	return b.InputBinding.Read(ctx, func(ctx context.Context, resp *ReadResponse) ([]byte, error) {
+		if err := b.validator.Validate(resp.Data); err != nil {
+			b.logger.Warnf("Event does not match the schema: %v", err)
+			return nil, fmt.Errorf("binding schema validation error: %w", err)
+		}
+
+		return handler(ctx, resp)
+	})
+}
+
+// validatingOutputBinding validates the payloads sent to an output binding against a JSON Schema.
+type validatingOutputBinding struct {
+	outputForwarder
+
+	validator *schema.Validator
+}
+
+// NewValidatingOutputBinding wraps an OutputBinding so that request payloads are validated against the JSON Schema set in the "validationSchema" metadata.
+// Requests without data aren't validated. Validation is disabled when no schema is set.
+func NewValidatingOutputBinding(inner OutputBinding) OutputBinding {
+	return exposeOptionalOutput(&validatingOutputBinding{
+		outputForwarder: outputForwarder{inner},
+	}, inner)
+}
+
+func (b *validatingOutputBinding) Init(metadata Metadata) (err error) {
+	b.validator, err = newValidator(metadata)
+	if err != nil {
+		return err
+	}
+
+	return b.OutputBinding.Init(metadata)
+}
+
+func (b *validatingOutputBinding) Invoke(ctx context.Context, req *InvokeRequest) (*InvokeResponse, error) {
+	if b.validator != nil && len(req.Data) > 0 {
+		if err := b.validator.Validate(req.Data); err != nil {
+			return nil, fmt.Errorf("binding schema validation error: %w", err)
+		}
+	}
+
+	return b.OutputBinding.Invoke(ctx, req)
+}
+
+func newValidator(metadata Metadata) (*schema.Validator, error) {
+	m, err := schema.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("binding schema validation error: %w", err)
+	}
+	v, err := schema.NewValidator(m.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("binding schema validation error: %w", err)
+	}
+	return v, nil
+}
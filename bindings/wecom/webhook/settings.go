@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// WeCom (WeChat Work) group robots post messages into WeCom group chats
+//
+// See https://developer.work.weixin.qq.com/document/path/91770 for details
+
+package webhook
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const defaultURL = "https://qyapi.weixin.qq.com/cgi-bin/webhook/send"
+
+type Settings struct {
+	// URL of the robot webhook, including its key.
+	URL string `mapstructure:"url"`
+	// Key of the robot, used with the default URL when no URL is set.
+	Key string `mapstructure:"key"`
+}
+
+func (s *Settings) Decode(in interface{}) error {
+	return metadata.DecodeMetadata(in, s)
+}
+
+func (s *Settings) Validate() error {
+	if s.URL == "" && s.Key == "" {
+		return errors.New("webhook error: missing webhook url or key")
+	}
+
+	return nil
+}
+
+// postURL returns the URL messages are posted to.
+func (s *Settings) postURL() string {
+	if s.URL != "" {
+		return s.URL
+	}
+
+	return defaultURL + "?key=" + url.QueryEscape(s.Key)
+}
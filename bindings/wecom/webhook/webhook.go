@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/internal/utils"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	webhookContentType       = "application/json"
+	defaultHTTPClientTimeout = time.Second * 30
+
+	// Keys of the request metadata building the message. Without msgType, the request data is sent as is.
+	msgTypeKey   = "msgType"
+	titleKey     = "title"
+	urlKey       = "url"
+	picURLKey    = "picURL"
+	atMobilesKey = "atMobiles"
+	atAllKey     = "atAll"
+
+	// Types of the messages built from the request data.
+	MsgTypeText     = "text"
+	MsgTypeMarkdown = "markdown"
+	MsgTypeCard     = "card"
+)
+
+type WeComWebhook struct {
+	logger     logger.Logger
+	settings   Settings
+	httpClient *http.Client
+}
+
+type webhookResult struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func NewWeComWebhook(l logger.Logger) bindings.OutputBinding {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	netTransport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+	httpClient := &http.Client{
+		Timeout:   defaultHTTPClientTimeout,
+		Transport: netTransport,
+	}
+
+	return &WeComWebhook{
+		logger:     l,
+		httpClient: httpClient,
+	}
+}
+
+// Init performs metadata parsing.
+func (t *WeComWebhook) Init(metadata bindings.Metadata) error {
+	var err error
+	if err = t.settings.Decode(metadata.Properties); err != nil {
+		return fmt.Errorf("wecom configuration error: %w", err)
+	}
+	if err = t.settings.Validate(); err != nil {
+		return fmt.Errorf("wecom configuration error: %w", err)
+	}
+
+	return nil
+}
+
+// Operations returns list of operations supported by wecom webhook binding.
+func (t *WeComWebhook) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation}
+}
+
+func (t *WeComWebhook) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != bindings.CreateOperation {
+		return nil, fmt.Errorf("wecom webhook error: unsupported operation %s", req.Operation)
+	}
+
+	return nil, t.sendMessage(ctx, req)
+}
+
+func (t *WeComWebhook) sendMessage(ctx context.Context, req *bindings.InvokeRequest) error {
+	msg, err := buildMessage(req.Data, req.Metadata)
+	if err != nil {
+		return fmt.Errorf("wecom webhook error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultHTTPClientTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.settings.postURL(), bytes.NewReader(msg))
+	if err != nil {
+		return fmt.Errorf("wecom webhook error: new request failed. %w", err)
+	}
+
+	httpReq.Header.Add("Accept", webhookContentType)
+	httpReq.Header.Add("Content-Type", webhookContentType)
+
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("wecom webhook error: post failed. %w", err)
+	}
+	defer func() {
+		// Drain before closing
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("wecom webhook error: post failed. status:%d", resp.StatusCode)
+	}
+
+	var rst webhookResult
+	err = json.NewDecoder(resp.Body).Decode(&rst)
+	if err != nil {
+		return fmt.Errorf("wecom webhook error: unmarshal body failed. %w", err)
+	}
+
+	if rst.ErrCode != 0 {
+		return fmt.Errorf("wecom webhook error: send msg failed. %v", rst.ErrMsg)
+	}
+
+	return nil
+}
+
+// buildMessage returns the body posted to the robot: the request data is the content of a message of the type set in the "msgType" metadata.
+// Cards are sent as news messages linking to the "url" metadata.
+func buildMessage(data []byte, md map[string]string) ([]byte, error) {
+	msgType := md[msgTypeKey]
+	if msgType == "" {
+		return data, nil
+	}
+
+	var msg map[string]interface{}
+	switch msgType {
+	case MsgTypeText:
+		text := map[string]interface{}{"content": string(data)}
+		var mentions []string
+		for _, m := range strings.Split(md[atMobilesKey], ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				mentions = append(mentions, m)
+			}
+		}
+		if utils.IsTruthy(md[atAllKey]) {
+			mentions = append(mentions, "@all")
+		}
+		if len(mentions) > 0 {
+			text["mentioned_mobile_list"] = mentions
+		}
+		msg = map[string]interface{}{
+			"msgtype": "text",
+			"text":    text,
+		}
+	case MsgTypeMarkdown:
+		msg = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": string(data)},
+		}
+	case MsgTypeCard:
+		if md[titleKey] == "" || md[urlKey] == "" {
+			return nil, fmt.Errorf("required metadata %q and %q not set", titleKey, urlKey)
+		}
+		article := map[string]string{
+			"title":       md[titleKey],
+			"description": string(data),
+			"url":         md[urlKey],
+		}
+		if md[picURLKey] != "" {
+			article["picurl"] = md[picURLKey]
+		}
+		msg = map[string]interface{}{
+			"msgtype": "news",
+			"news":    map[string]interface{}{"articles": []map[string]string{article}},
+		}
+	default:
+		return nil, fmt.Errorf("msgType %q is not one of: text, markdown, card", msgType)
+	}
+
+	return json.Marshal(msg)
+}
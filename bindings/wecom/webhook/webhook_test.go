@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestSettings(t *testing.T) { //nolint:paralleltest
+	var settings Settings
+	require.NoError(t, settings.Decode(map[string]string{"key": "a b"}))
+	require.NoError(t, settings.Validate())
+	assert.Equal(t, defaultURL+"?key=a+b", settings.postURL())
+
+	settings = Settings{}
+	assert.Error(t, settings.Validate())
+}
+
+func TestPublishMsg(t *testing.T) { //nolint:paralleltest
+	var body string
+	errCode := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "k1", r.URL.Query().Get("key"))
+		raw, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(raw)
+
+		w.WriteHeader(http.StatusOK)
+		if errCode != 0 {
+			w.Write([]byte(`{"errcode": 93000, "errmsg": "invalid webhook url"}`))
+			return
+		}
+		w.Write([]byte(`{"errcode": 0, "errmsg": "ok"}`))
+	}))
+	defer ts.Close()
+
+	d := NewWeComWebhook(logger.NewLogger("test"))
+	err := d.Init(bindings.Metadata{Base: metadata.Base{Name: "test", Properties: map[string]string{
+		"url": ts.URL + "/cgi-bin/webhook/send?key=k1",
+	}}})
+	require.NoError(t, err)
+
+	invoke := func(data string, md map[string]string) error {
+		_, err := d.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte(data),
+			Metadata:  md,
+		})
+		return err
+	}
+
+	require.NoError(t, invoke(`{"msgtype": "text", "text": {"content": "raw"}}`, nil))
+	assert.JSONEq(t, `{"msgtype": "text", "text": {"content": "raw"}}`, body)
+
+	require.NoError(t, invoke("hello", map[string]string{msgTypeKey: MsgTypeText, atMobilesKey: "138", atAllKey: "true"}))
+	assert.JSONEq(t, `{"msgtype": "text", "text": {"content": "hello", "mentioned_mobile_list": ["138", "@all"]}}`, body)
+
+	require.NoError(t, invoke("**Alert**", map[string]string{msgTypeKey: MsgTypeMarkdown}))
+	assert.JSONEq(t, `{"msgtype": "markdown", "markdown": {"content": "**Alert**"}}`, body)
+
+	require.NoError(t, invoke("Build failed", map[string]string{msgTypeKey: MsgTypeCard, titleKey: "CI", urlKey: "https://ci.example.com/1"}))
+	assert.JSONEq(t, `{"msgtype": "news", "news": {"articles": [{"title": "CI", "description": "Build failed", "url": "https://ci.example.com/1"}]}}`, body)
+
+	assert.Error(t, invoke("Build failed", map[string]string{msgTypeKey: MsgTypeCard}))
+
+	errCode = 93000
+	assert.ErrorContains(t, invoke("hello", map[string]string{msgTypeKey: MsgTypeText}), "invalid webhook url")
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindings
+
+import (
+	"io"
+
+	"github.com/dapr/components-contrib/health"
+)
+
+// outputWrapperBase is implemented by all the OutputBinding wrappers.
+type outputWrapperBase interface {
+	OutputBinding
+	OperationsDescriber
+	io.Closer
+}
+
+// outputWrapper is implemented by the OutputBinding wrappers, which support all the optional interfaces of the wrapped binding.
+type outputWrapper interface {
+	outputWrapperBase
+	health.Pinger
+}
+
+// outputForwarder is embedded by the wrappers to forward the optional interfaces to the wrapped OutputBinding.
+// The wrappers override the methods whose behavior they change.
+type outputForwarder struct {
+	OutputBinding
+}
+
+func (f outputForwarder) OperationsMetadata() []OperationMetadata {
+	return GetOperationsMetadata(f.OutputBinding)
+}
+
+func (f outputForwarder) Ping() error {
+	return PingOutBinding(f.OutputBinding)
+}
+
+func (f outputForwarder) Close() error {
+	return closeBinding(f.OutputBinding)
+}
+
+// exposeOptionalOutput returns the wrapper w of inner, implementing health.Pinger only if inner does.
+func exposeOptionalOutput(w outputWrapper, inner OutputBinding) OutputBinding {
+	if _, ok := inner.(health.Pinger); ok {
+		return w
+	}
+	return struct {
+		outputWrapperBase
+	}{w}
+}
+
+// inputWrapperBase is implemented by all the InputBinding wrappers.
+type inputWrapperBase interface {
+	InputBinding
+	io.Closer
+}
+
+// inputWrapper is implemented by the InputBinding wrappers, which support all the optional interfaces of the wrapped binding.
+type inputWrapper interface {
+	inputWrapperBase
+	health.Pinger
+}
+
+// inputForwarder is embedded by the wrappers to forward the optional interfaces to the wrapped InputBinding.
+// The wrappers override the methods whose behavior they change.
+type inputForwarder struct {
+	InputBinding
+}
+
+func (f inputForwarder) Ping() error {
+	return PingInpBinding(f.InputBinding)
+}
+
+func (f inputForwarder) Close() error {
+	return closeBinding(f.InputBinding)
+}
+
+// exposeOptionalInput returns the wrapper w of inner, implementing health.Pinger only if inner does.
+func exposeOptionalInput(w inputWrapper, inner InputBinding) InputBinding {
+	if _, ok := inner.(health.Pinger); ok {
+		return w
+	}
+	return struct {
+		inputWrapperBase
+	}{w}
+}
+
+func closeBinding(binding interface{}) error {
+	if closer, ok := binding.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zeromq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	SocketTypePush = "push"
+	SocketTypePull = "pull"
+	SocketTypePub  = "pub"
+	SocketTypeSub  = "sub"
+
+	defaultDialTimeout = 5 * time.Second
+	defaultDialRetry   = 250 * time.Millisecond
+)
+
+type zeromqMetadata struct {
+	// Endpoint of the socket, e.g. tcp://127.0.0.1:5555.
+	Endpoint string `mapstructure:"endpoint"`
+	// Type of the socket: push or pub for output bindings, pull or sub for input bindings.
+	SocketType string `mapstructure:"socketType"`
+	// If true the socket listens on the endpoint, otherwise it connects to it.
+	Bind bool `mapstructure:"bind"`
+	// Comma-separated list of the topic prefixes a sub socket subscribes to. All messages are received if empty.
+	Topics string `mapstructure:"topics"`
+	// Credentials of the PLAIN security mechanism.
+	// Connecting sockets send them, and listening sockets require them from their peers.
+	Username    string        `mapstructure:"username"`
+	Password    string        `mapstructure:"password"`
+	DialTimeout time.Duration `mapstructure:"dialTimeout"`
+	DialRetry   time.Duration `mapstructure:"dialRetry"`
+}
+
+func parseMetadata(meta bindings.Metadata) (zeromqMetadata, error) {
+	m := zeromqMetadata{
+		DialTimeout: defaultDialTimeout,
+		DialRetry:   defaultDialRetry,
+	}
+	err := metadata.DecodeMetadata(meta.Properties, &m)
+	if err != nil {
+		return m, fmt.Errorf("zeromq binding error: %w", err)
+	}
+
+	if m.Endpoint == "" {
+		return m, errors.New("zeromq binding error: missing endpoint")
+	}
+	m.SocketType = strings.ToLower(m.SocketType)
+	switch m.SocketType {
+	case SocketTypePush, SocketTypePull, SocketTypePub, SocketTypeSub:
+	default:
+		return m, fmt.Errorf("zeromq binding error: socketType %q is not one of: push, pull, pub, sub", m.SocketType)
+	}
+	if m.Topics != "" && m.SocketType != SocketTypeSub {
+		return m, errors.New("zeromq binding error: topics can only be set on sub sockets")
+	}
+	if m.Password != "" && m.Username == "" {
+		return m, errors.New("zeromq binding error: missing username")
+	}
+
+	return m, nil
+}
+
+// topics returns the topic prefixes a sub socket subscribes to.
+func (m zeromqMetadata) topics() []string {
+	if m.Topics == "" {
+		// An empty prefix matches all the messages
+		return []string{""}
+	}
+
+	var topics []string
+	for _, t := range strings.Split(m.Topics, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			topics = append(topics, t)
+		}
+	}
+	return topics
+}
+
+// isOutput reports whether messages are sent by the socket.
+func (m zeromqMetadata) isOutput() bool {
+	return m.SocketType == SocketTypePush || m.SocketType == SocketTypePub
+}
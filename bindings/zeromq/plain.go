@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zeromq
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-zeromq/zmq4"
+)
+
+// plainServerSecurity implements the server side of the ZMTP PLAIN security mechanism, which authenticates the peers with their credentials.
+// See https://rfc.zeromq.org/spec/24/
+type plainServerSecurity struct {
+	username []byte
+	password []byte
+}
+
+func (plainServerSecurity) Type() zmq4.SecurityType {
+	return zmq4.PlainSecurity
+}
+
+// Handshake authenticates the client, which must talk first.
+func (s plainServerSecurity) Handshake(conn *zmq4.Conn, server bool) error {
+	if !server {
+		return errors.New("zeromq: the PLAIN server security mechanism is only supported by listening sockets")
+	}
+
+	cmd, err := conn.RecvCmd()
+	if err != nil {
+		return fmt.Errorf("zeromq: could not receive HELLO from client: %w", err)
+	}
+	if cmd.Name != zmq4.CmdHello {
+		return errors.New("zeromq: expected HELLO command")
+	}
+
+	username, password, err := parseHello(cmd.Body)
+	if err != nil || !s.authenticate(username, password) {
+		_ = conn.SendCmd(zmq4.CmdError, errorReason("invalid credentials"))
+		return errors.New("zeromq: could not authenticate client")
+	}
+
+	err = conn.SendCmd(zmq4.CmdWelcome, nil)
+	if err != nil {
+		return fmt.Errorf("zeromq: could not send WELCOME to client: %w", err)
+	}
+
+	cmd, err = conn.RecvCmd()
+	if err != nil {
+		return fmt.Errorf("zeromq: could not receive INITIATE from client: %w", err)
+	}
+	if cmd.Name != zmq4.CmdInitiate {
+		return errors.New("zeromq: expected INITIATE command")
+	}
+	err = conn.Peer.Meta.UnmarshalZMTP(cmd.Body)
+	if err != nil {
+		return fmt.Errorf("zeromq: could not unmarshal peer metadata: %w", err)
+	}
+
+	raw, err := conn.Meta.MarshalZMTP()
+	if err != nil {
+		return fmt.Errorf("zeromq: could not marshal metadata: %w", err)
+	}
+	err = conn.SendCmd(zmq4.CmdReady, raw)
+	if err != nil {
+		return fmt.Errorf("zeromq: could not send READY to client: %w", err)
+	}
+
+	return nil
+}
+
+func (s plainServerSecurity) authenticate(username, password []byte) bool {
+	userOK := subtle.ConstantTimeCompare(username, s.username) == 1
+	passOK := subtle.ConstantTimeCompare(password, s.password) == 1
+	return userOK && passOK
+}
+
+// Encrypt writes data as is, since PLAIN does not encrypt messages.
+func (plainServerSecurity) Encrypt(w io.Writer, data []byte) (int, error) {
+	return w.Write(data)
+}
+
+// Decrypt writes data as is, since PLAIN does not encrypt messages.
+func (plainServerSecurity) Decrypt(w io.Writer, data []byte) (int, error) {
+	return w.Write(data)
+}
+
+// parseHello returns the credentials of a HELLO command, which are encoded as size-prefixed strings.
+func parseHello(body []byte) (username []byte, password []byte, err error) {
+	username, body, err = readShortString(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	password, body, err = readShortString(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) != 0 {
+		return nil, nil, errors.New("unexpected data after the password")
+	}
+	return username, password, nil
+}
+
+func readShortString(body []byte) (value []byte, rest []byte, err error) {
+	if len(body) == 0 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	n := int(body[0])
+	if len(body) < 1+n {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return body[1 : 1+n], body[1+n:], nil
+}
+
+// errorReason encodes the reason of an ERROR command.
+func errorReason(reason string) []byte {
+	return append([]byte{byte(len(reason))}, reason...)
+}
+
+var _ zmq4.Security = plainServerSecurity{}
@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zeromq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+	"github.com/go-zeromq/zmq4/security/plain"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// keys from request's and response's metadata.
+	topicKey = "topic"
+
+	// time waited before receiving again after an error.
+	recvErrorWait = time.Second
+)
+
+// ZeroMQ is a binding sending messages from a push or pub socket, or receiving messages on a pull or sub socket.
+type ZeroMQ struct {
+	metadata zeromqMetadata
+	socket   zmq4.Socket
+	logger   logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewZeroMQ returns a new ZeroMQ binding.
+func NewZeroMQ(logger logger.Logger) bindings.InputOutputBinding {
+	return &ZeroMQ{logger: logger}
+}
+
+// Init performs metadata parsing and binds or connects the socket.
+func (z *ZeroMQ) Init(meta bindings.Metadata) error {
+	m, err := parseMetadata(meta)
+	if err != nil {
+		return err
+	}
+	z.metadata = m
+
+	z.ctx, z.cancel = context.WithCancel(context.Background())
+	z.socket = newSocket(z.ctx, m)
+
+	if m.SocketType == SocketTypeSub {
+		for _, topic := range m.topics() {
+			err = z.socket.SetOption(zmq4.OptionSubscribe, topic)
+			if err != nil {
+				z.cancel()
+				return fmt.Errorf("zeromq binding error: failed to subscribe to %q: %w", topic, err)
+			}
+		}
+	}
+
+	if m.Bind {
+		err = z.socket.Listen(m.Endpoint)
+	} else {
+		err = z.socket.Dial(m.Endpoint)
+	}
+	if err != nil {
+		z.socket.Close()
+		z.cancel()
+		return fmt.Errorf("zeromq binding error: failed to open %s socket on %s: %w", m.SocketType, m.Endpoint, err)
+	}
+
+	return nil
+}
+
+func newSocket(ctx context.Context, m zeromqMetadata) zmq4.Socket {
+	opts := []zmq4.Option{
+		zmq4.WithDialerTimeout(m.DialTimeout),
+		zmq4.WithDialerRetry(m.DialRetry),
+		// Listening sockets accept the connections again, while connecting sockets have to dial again
+		zmq4.WithAutomaticReconnect(!m.Bind),
+	}
+	if m.Username != "" {
+		if m.Bind {
+			opts = append(opts, zmq4.WithSecurity(plainServerSecurity{
+				username: []byte(m.Username),
+				password: []byte(m.Password),
+			}))
+		} else {
+			opts = append(opts, zmq4.WithSecurity(plain.Security(m.Username, m.Password)))
+		}
+	}
+
+	switch m.SocketType {
+	case SocketTypePush:
+		return zmq4.NewPush(ctx, opts...)
+	case SocketTypePull:
+		return zmq4.NewPull(ctx, opts...)
+	case SocketTypePub:
+		return zmq4.NewPub(ctx, opts...)
+	default:
+		return zmq4.NewSub(ctx, opts...)
+	}
+}
+
+// Operations returns list of operations supported by the ZeroMQ binding.
+func (z *ZeroMQ) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{bindings.CreateOperation}
+}
+
+// Invoke sends a message from the push or pub socket.
+// Messages sent by pub sockets are prefixed with a frame holding their topic, if any.
+func (z *ZeroMQ) Invoke(_ context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if !z.metadata.isOutput() {
+		return nil, fmt.Errorf("zeromq binding error: cannot send messages from a %s socket", z.metadata.SocketType)
+	}
+	if req.Operation != bindings.CreateOperation {
+		return nil, fmt.Errorf("zeromq binding error: unsupported operation %s", req.Operation)
+	}
+
+	var err error
+	topic := req.Metadata[topicKey]
+	if z.metadata.SocketType == SocketTypePub && topic != "" {
+		err = z.socket.SendMulti(zmq4.NewMsgFrom([]byte(topic), req.Data))
+	} else {
+		err = z.socket.Send(zmq4.NewMsg(req.Data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("zeromq binding error: failed to send message: %w", err)
+	}
+
+	return nil, nil
+}
+
+// Read receives messages on the pull or sub socket.
+func (z *ZeroMQ) Read(ctx context.Context, handler bindings.Handler) error {
+	if z.metadata.isOutput() {
+		return fmt.Errorf("zeromq binding error: cannot receive messages on a %s socket", z.metadata.SocketType)
+	}
+
+	z.wg.Add(1)
+	go func() {
+		defer z.wg.Done()
+
+		for {
+			msg, err := z.socket.Recv()
+			if ctx.Err() != nil || z.ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				z.logger.Errorf("zeromq binding error: failed to receive message: %v", err)
+				select {
+				case <-time.After(recvErrorWait):
+					continue
+				case <-ctx.Done():
+					return
+				case <-z.ctx.Done():
+					return
+				}
+			}
+
+			_, err = handler(ctx, readResponse(z.metadata.SocketType, msg))
+			if err != nil {
+				z.logger.Errorf("zeromq binding error: failed to handle message: %v", err)
+			}
+		}
+	}()
+
+	// Closing the socket unblocks Recv when the context is canceled
+	go func() {
+		select {
+		case <-ctx.Done():
+			z.socket.Close()
+		case <-z.ctx.Done():
+		}
+	}()
+
+	return nil
+}
+
+// readResponse converts a received message: the first frame of the messages received by sub sockets holds their topic.
+func readResponse(socketType string, msg zmq4.Msg) *bindings.ReadResponse {
+	frames := msg.Frames
+	res := &bindings.ReadResponse{}
+	if socketType == SocketTypeSub && len(frames) > 1 {
+		res.Metadata = map[string]string{topicKey: string(frames[0])}
+		frames = frames[1:]
+	}
+	if len(frames) == 1 {
+		res.Data = frames[0]
+	} else {
+		res.Data = bytes.Join(frames, nil)
+	}
+	return res
+}
+
+// Close closes the socket.
+func (z *ZeroMQ) Close() error {
+	if z.cancel == nil {
+		return nil
+	}
+
+	z.cancel()
+	err := z.socket.Close()
+	z.wg.Wait()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zeromq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"endpoint":   "tcp://127.0.0.1:5555",
+			"socketType": "SUB",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, SocketTypeSub, m.SocketType)
+		assert.False(t, m.Bind)
+		assert.Equal(t, defaultDialTimeout, m.DialTimeout)
+		assert.Equal(t, []string{""}, m.topics())
+		assert.False(t, m.isOutput())
+	})
+
+	t.Run("topics", func(t *testing.T) {
+		m, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"endpoint":   "tcp://127.0.0.1:5555",
+			"socketType": "sub",
+			"topics":     "prices, trades",
+		}}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"prices", "trades"}, m.topics())
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, props := range map[string]map[string]string{
+			"missing endpoint":    {"socketType": "push"},
+			"invalid socket type": {"endpoint": "tcp://127.0.0.1:5555", "socketType": "router"},
+			"topics on push":      {"endpoint": "tcp://127.0.0.1:5555", "socketType": "push", "topics": "a"},
+			"missing username":    {"endpoint": "tcp://127.0.0.1:5555", "socketType": "push", "password": "secret"},
+		} {
+			_, err := parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: props}})
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestReadResponse(t *testing.T) {
+	res := readResponse(SocketTypeSub, zmq4.NewMsgFrom([]byte("prices"), []byte("42")))
+	assert.Equal(t, "42", string(res.Data))
+	assert.Equal(t, "prices", res.Metadata[topicKey])
+
+	res = readResponse(SocketTypeSub, zmq4.NewMsg([]byte("42")))
+	assert.Equal(t, "42", string(res.Data))
+	assert.Empty(t, res.Metadata)
+
+	res = readResponse(SocketTypePull, zmq4.NewMsgFrom([]byte("a"), []byte("b")))
+	assert.Equal(t, "ab", string(res.Data))
+}
+
+func TestParseHello(t *testing.T) {
+	username, password, err := parseHello([]byte("\x04user\x06secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "user", string(username))
+	assert.Equal(t, "secret", string(password))
+
+	_, _, err = parseHello([]byte("\x04user\x06sec"))
+	assert.Error(t, err)
+	_, _, err = parseHello([]byte("\x04user\x00extra"))
+	assert.Error(t, err)
+}
+
+func newBinding(t *testing.T, props map[string]string) *ZeroMQ {
+	t.Helper()
+
+	z := NewZeroMQ(logger.NewLogger("test")).(*ZeroMQ)
+	err := z.Init(bindings.Metadata{Base: metadata.Base{Properties: props}})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		z.Close()
+	})
+	return z
+}
+
+func read(t *testing.T, z *ZeroMQ) <-chan *bindings.ReadResponse {
+	t.Helper()
+
+	ch := make(chan *bindings.ReadResponse, 10)
+	err := z.Read(context.Background(), func(_ context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		ch <- res
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return ch
+}
+
+func TestPushPull(t *testing.T) {
+	pull := newBinding(t, map[string]string{
+		"endpoint":   "tcp://127.0.0.1:0",
+		"socketType": "pull",
+		"bind":       "true",
+		"username":   "user",
+		"password":   "secret",
+	})
+	endpoint := "tcp://" + pull.socket.Addr().String()
+	received := read(t, pull)
+
+	push := newBinding(t, map[string]string{
+		"endpoint":   endpoint,
+		"socketType": "push",
+		"username":   "user",
+		"password":   "secret",
+	})
+	_, err := push.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("hello"),
+	})
+	require.NoError(t, err)
+
+	select {
+	case res := <-received:
+		assert.Equal(t, "hello", string(res.Data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		z := NewZeroMQ(logger.NewLogger("test")).(*ZeroMQ)
+		err := z.Init(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"endpoint":    endpoint,
+			"socketType":  "push",
+			"username":    "user",
+			"password":    "wrong",
+			"dialTimeout": "1s",
+			"dialRetry":   "10ms",
+		}}})
+		if err == nil {
+			z.Close()
+		}
+		assert.Error(t, err)
+	})
+
+	t.Run("cannot read from push socket", func(t *testing.T) {
+		err := push.Read(context.Background(), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestPubSub(t *testing.T) {
+	pub := newBinding(t, map[string]string{
+		"endpoint":   "tcp://127.0.0.1:0",
+		"socketType": "pub",
+		"bind":       "true",
+	})
+	sub := newBinding(t, map[string]string{
+		"endpoint":   "tcp://" + pub.socket.Addr().String(),
+		"socketType": "sub",
+		"topics":     "prices",
+	})
+	received := read(t, sub)
+
+	// Subscriptions are propagated asynchronously to the publisher, so messages are sent until one is received
+	assert.Eventually(t, func() bool {
+		for _, topic := range []string{"trades", "prices"} {
+			_, err := pub.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: bindings.CreateOperation,
+				Data:      []byte(topic + " update"),
+				Metadata:  map[string]string{topicKey: topic},
+			})
+			require.NoError(t, err)
+		}
+		select {
+		case res := <-received:
+			assert.Equal(t, "prices update", string(res.Data))
+			assert.Equal(t, "prices", res.Metadata[topicKey])
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -29,6 +29,7 @@ import (
 
 	"github.com/dapr/components-contrib/configuration"
 	"github.com/dapr/components-contrib/configuration/redis/internal"
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/kit/logger"
 )
 
@@ -149,7 +150,7 @@ func (r *ConfigurationStore) Init(metadata configuration.Metadata) error {
 	}
 
 	if _, err = r.client.Ping(context.TODO()).Result(); err != nil {
-		return fmt.Errorf("redis store: error connecting to redis at %s: %s", m.Host, err)
+		return fmt.Errorf("redis store: error connecting to redis at %s: %s", redact.ConnectionString(m.Host), err)
 	}
 
 	r.replicas, err = r.getConnectedSlaves()
@@ -18,6 +18,8 @@ import "strings"
 const (
 	// CloudEventContentType is the content type for cloud event.
 	CloudEventContentType = "application/cloudevents+json"
+	// CloudEventProtobufContentType is the content type for cloud event in the protobuf format.
+	CloudEventProtobufContentType = "application/cloudevents+protobuf"
 	// JSONContentType is the content type for JSON.
 	JSONContentType = "application/json"
 )
@@ -27,6 +29,11 @@ func IsCloudEventContentType(contentType string) bool {
 	return isContentType(contentType, CloudEventContentType)
 }
 
+// IsCloudEventProtobufContentType checks for content type.
+func IsCloudEventProtobufContentType(contentType string) bool {
+	return isContentType(contentType, CloudEventProtobufContentType)
+}
+
 // IsJSONContentType checks for content type.
 func IsJSONContentType(contentType string) bool {
 	return isContentType(contentType, JSONContentType)
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Status is the initialization status of a component.
+type Status string
+
+const (
+	// StatusInitializing is reported while the component is not ready yet, e.g. waiting for a broker.
+	StatusInitializing Status = "Initializing"
+	// StatusReady is reported once the component can serve requests.
+	StatusReady Status = "Ready"
+	// StatusFailed is reported when the component failed to initialize and won't recover by itself.
+	StatusFailed Status = "Failed"
+)
+
+// Readiness is the status reported by a component, with the reason why it's not ready.
+type Readiness struct {
+	Status Status
+	Reason string
+	// Time of the last change of Status.
+	Since time.Time
+}
+
+// ReadinessReporter is implemented by components which complete their initialization after Init returned.
+// The runtime can delay its own readiness until they are ready, and surface their status to operators.
+type ReadinessReporter interface {
+	// Readiness returns the current status of the component.
+	Readiness() Readiness
+	// WaitReady blocks until the component is ready, failed, or ctx is done.
+	WaitReady(ctx context.Context) error
+}
+
+// GetReadiness returns the readiness of a component.
+// Components not implementing ReadinessReporter are ready once Init returned.
+func GetReadiness(component any) Readiness {
+	if r, ok := component.(ReadinessReporter); ok {
+		return r.Readiness()
+	}
+
+	return Readiness{Status: StatusReady}
+}
+
+// ReadinessTracker implements ReadinessReporter for the components embedding it.
+// It starts in StatusInitializing.
+type ReadinessTracker struct {
+	lock      sync.Mutex
+	readiness Readiness
+	// changed is closed and replaced on every change of readiness.
+	changed chan struct{}
+}
+
+// NewReadinessTracker returns a ReadinessTracker initializing for the given reason.
+func NewReadinessTracker(reason string) *ReadinessTracker {
+	return &ReadinessTracker{
+		readiness: Readiness{Status: StatusInitializing, Reason: reason, Since: time.Now()},
+		changed:   make(chan struct{}),
+	}
+}
+
+// SetInitializing reports the component as not ready yet, e.g. while it retries to connect.
+func (t *ReadinessTracker) SetInitializing(reason string) {
+	t.set(StatusInitializing, reason)
+}
+
+// SetReady reports the component as ready.
+func (t *ReadinessTracker) SetReady() {
+	t.set(StatusReady, "")
+}
+
+// SetFailed reports the component as failed with err.
+func (t *ReadinessTracker) SetFailed(err error) {
+	reason := "unknown error"
+	if err != nil {
+		reason = err.Error()
+	}
+	t.set(StatusFailed, reason)
+}
+
+func (t *ReadinessTracker) set(status Status, reason string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.readiness.Status == status && t.readiness.Reason == reason {
+		return
+	}
+	since := t.readiness.Since
+	if t.readiness.Status != status {
+		since = time.Now()
+	}
+	t.readiness = Readiness{Status: status, Reason: reason, Since: since}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// Readiness returns the current status of the component.
+func (t *ReadinessTracker) Readiness() Readiness {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.readiness
+}
+
+// WaitReady blocks until the component is ready, failed, or ctx is done.
+func (t *ReadinessTracker) WaitReady(ctx context.Context) error {
+	for {
+		t.lock.Lock()
+		readiness, changed := t.readiness, t.changed
+		t.lock.Unlock()
+
+		switch readiness.Status {
+		case StatusReady:
+			return nil
+		case StatusFailed:
+			return errors.New(readiness.Reason)
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			if readiness.Reason != "" {
+				return errors.New(readiness.Reason + ": " + ctx.Err().Error())
+			}
+			return ctx.Err()
+		}
+	}
+}
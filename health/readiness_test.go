@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessTracker(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		tracker := NewReadinessTracker("connecting")
+		assert.Equal(t, StatusInitializing, tracker.Readiness().Status)
+		assert.Equal(t, "connecting", tracker.Readiness().Reason)
+
+		done := make(chan error)
+		go func() {
+			done <- tracker.WaitReady(context.Background())
+		}()
+
+		tracker.SetInitializing("waiting for broker")
+		assert.Equal(t, "waiting for broker", GetReadiness(tracker).Reason)
+		tracker.SetReady()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("WaitReady did not return")
+		}
+		assert.Equal(t, Readiness{Status: StatusReady, Since: tracker.Readiness().Since}, tracker.Readiness())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		tracker := NewReadinessTracker("connecting")
+		tracker.SetFailed(errors.New("invalid credentials"))
+
+		err := tracker.WaitReady(context.Background())
+		assert.EqualError(t, err, "invalid credentials")
+		assert.Equal(t, StatusFailed, GetReadiness(tracker).Status)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		tracker := NewReadinessTracker("waiting for broker")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := tracker.WaitReady(ctx)
+		assert.ErrorContains(t, err, "waiting for broker: context deadline exceeded")
+	})
+}
+
+func TestGetReadiness(t *testing.T) {
+	assert.Equal(t, StatusReady, GetReadiness(struct{}{}).Status)
+}
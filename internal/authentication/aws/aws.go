@@ -14,27 +14,66 @@ limitations under the License.
 package aws
 
 import (
+	"errors"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 
 	"github.com/dapr/kit/logger"
 )
 
+// Options contains the settings used to create an AWS session.
+type Options struct {
+	Region       string
+	Endpoint     string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// AssumeRoleARN is the ARN of a role to assume.
+	// When WebIdentityTokenFile is set, the role is assumed with the web identity token (IRSA); otherwise it is assumed with the base credentials.
+	AssumeRoleARN string
+	// ExternalID is passed to STS when assuming a role with the base credentials.
+	ExternalID string
+	// SessionName is the name of the assumed role session; if empty, a name is generated.
+	SessionName string
+	// WebIdentityTokenFile is the path to an OIDC token file, such as the one projected by EKS for IAM Roles for Service Accounts.
+	WebIdentityTokenFile string
+}
+
 func GetClient(accessKey string, secretKey string, sessionToken string, region string, endpoint string) (*session.Session, error) {
+	return NewSession(Options{
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		SessionToken: sessionToken,
+		Region:       region,
+		Endpoint:     endpoint,
+	})
+}
+
+// NewSession returns an AWS session for the given options.
+// Credentials are resolved, in order, from:
+// 1. A web identity token, if WebIdentityTokenFile is set
+// 2. An assumed role, if AssumeRoleARN is set, using the credentials below to call STS
+// 3. Static credentials, if AccessKey and SecretKey are set
+// 4. The default AWS credential chain (environment, shared config, IRSA environment variables, instance metadata).
+func NewSession(opts Options) (*session.Session, error) {
 	awsConfig := aws.NewConfig()
 
-	if region != "" {
-		awsConfig = awsConfig.WithRegion(region)
+	if opts.Region != "" {
+		awsConfig = awsConfig.WithRegion(opts.Region)
 	}
 
-	if accessKey != "" && secretKey != "" {
-		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, sessionToken))
+	if opts.AccessKey != "" && opts.SecretKey != "" {
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(opts.AccessKey, opts.SecretKey, opts.SessionToken))
 	}
 
-	if endpoint != "" {
-		awsConfig = awsConfig.WithEndpoint(endpoint)
+	if opts.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(opts.Endpoint)
 	}
 
 	awsSession, err := session.NewSessionWithOptions(session.Options{
@@ -45,6 +84,25 @@ func GetClient(accessKey string, secretKey string, sessionToken string, region s
 		return nil, err
 	}
 
+	switch {
+	case opts.WebIdentityTokenFile != "":
+		if opts.AssumeRoleARN == "" {
+			return nil, errors.New("assumeRoleArn is required when webIdentityTokenFile is set")
+		}
+		provider := stscreds.NewWebIdentityRoleProviderWithOptions(sts.New(awsSession), opts.AssumeRoleARN, opts.SessionName, stscreds.FetchTokenPath(opts.WebIdentityTokenFile))
+		awsSession = awsSession.Copy(aws.NewConfig().WithCredentials(credentials.NewCredentials(provider)))
+	case opts.AssumeRoleARN != "":
+		creds := stscreds.NewCredentials(awsSession, opts.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if opts.SessionName != "" {
+				p.RoleSessionName = opts.SessionName
+			}
+			if opts.ExternalID != "" {
+				p.ExternalID = aws.String(opts.ExternalID)
+			}
+		})
+		awsSession = awsSession.Copy(aws.NewConfig().WithCredentials(creds))
+	}
+
 	userAgentHandler := request.NamedHandler{
 		Name: "UserAgentHandler",
 		Fn:   request.MakeAddToUserAgentHandler("dapr", logger.DaprVersion),
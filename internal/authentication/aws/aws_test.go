@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSession(t *testing.T) {
+	t.Run("static credentials", func(t *testing.T) {
+		sess, err := NewSession(Options{
+			Region:       "us-west-2",
+			Endpoint:     "http://localhost:4566",
+			AccessKey:    "AKID",
+			SecretKey:    "SECRET",
+			SessionToken: "TOKEN",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "us-west-2", *sess.Config.Region)
+		assert.Equal(t, "http://localhost:4566", *sess.Config.Endpoint)
+
+		creds, err := sess.Config.Credentials.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "AKID", creds.AccessKeyID)
+		assert.Equal(t, "SECRET", creds.SecretAccessKey)
+		assert.Equal(t, "TOKEN", creds.SessionToken)
+	})
+
+	t.Run("assume role", func(t *testing.T) {
+		sess, err := NewSession(Options{
+			Region:        "us-west-2",
+			AccessKey:     "AKID",
+			SecretKey:     "SECRET",
+			AssumeRoleARN: "arn:aws:iam::123456789012:role/dapr",
+			ExternalID:    "external",
+		})
+		require.NoError(t, err)
+		assert.True(t, sess.Config.Credentials.IsExpired(), "assumed role credentials are retrieved lazily")
+	})
+
+	t.Run("web identity requires a role", func(t *testing.T) {
+		_, err := NewSession(Options{
+			Region:               "us-west-2",
+			WebIdentityTokenFile: "/var/run/secrets/eks.amazonaws.com/serviceaccount/token",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("web identity", func(t *testing.T) {
+		sess, err := NewSession(Options{
+			Region:               "us-west-2",
+			AssumeRoleARN:        "arn:aws:iam::123456789012:role/dapr",
+			WebIdentityTokenFile: "/var/run/secrets/eks.amazonaws.com/serviceaccount/token",
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, sess.Config.Credentials)
+	})
+}
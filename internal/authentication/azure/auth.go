@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
@@ -32,6 +33,28 @@ import (
 	"github.com/dapr/components-contrib/metadata"
 )
 
+// Resource names accepted by NewEnvironmentSettings.
+const (
+	AzureResourceManagerResourceName string = "azure"
+	AzureKeyVaultResourceName        string = "keyvault"
+	AzureStorageResourceName         string = "storage"
+	AzureCosmosDBResourceName        string = "cosmosdb"
+	AzureServiceBusResourceName      string = "servicebus"
+	AzureEventHubsResourceName       string = "eventhubs"
+	AzureSignalRResourceName         string = "signalr"
+	AzureAppConfigResourceName       string = "appconfig"
+	AzureMicrosoftGraphResourceName  string = "graph"
+	AzureManagedHSMResourceName      string = "managedhsm"
+)
+
+// managedHSMResources are the resources of Azure Key Vault Managed HSM by Azure environment name.
+// Pools are at https://<name>.<resource host>.
+var managedHSMResources = map[string]string{
+	azure.PublicCloud.Name:       "https://managedhsm.azure.net",
+	azure.ChinaCloud.Name:        "https://managedhsm.azure.cn",
+	azure.USGovernmentCloud.Name: "https://managedhsm.usgovcloudapi.net",
+}
+
 // NewEnvironmentSettings returns a new EnvironmentSettings configured for a given Azure resource.
 func NewEnvironmentSettings(resourceName string, values map[string]string) (EnvironmentSettings, error) {
 	es := EnvironmentSettings{
@@ -43,34 +66,45 @@ func NewEnvironmentSettings(resourceName string, values map[string]string) (Envi
 	}
 	es.AzureEnvironment = azureEnv
 	switch resourceName {
-	case "azure":
+	case AzureResourceManagerResourceName:
 		// Azure Resource Manager (management plane)
 		es.Resource = azureEnv.TokenAudience
-	case "keyvault":
+	case AzureKeyVaultResourceName:
 		// Azure Key Vault (data plane)
 		es.Resource = azureEnv.ResourceIdentifiers.KeyVault
-	case "storage":
+	case AzureStorageResourceName:
 		// Azure Storage (data plane)
 		es.Resource = azureEnv.ResourceIdentifiers.Storage
-	case "cosmosdb":
+	case AzureCosmosDBResourceName:
 		// Azure Cosmos DB (data plane)
 		es.Resource = azureEnv.ResourceIdentifiers.CosmosDB
-	case "servicebus":
+	case AzureServiceBusResourceName:
 		es.Resource = azureEnv.ResourceIdentifiers.ServiceBus
-	case "eventhubs":
+	case AzureEventHubsResourceName:
 		// Azure EventHubs (data plane)
 		// For documentation https://docs.microsoft.com/en-us/azure/event-hubs/authorize-access-azure-active-directory#overview
 		// The resource name to request a token is https://eventhubs.azure.net/, and it's the same for all clouds/tenants.
 		// Kafka connection does not factor in here.
 		es.Resource = "https://eventhubs.azure.net"
-	case "signalr":
+	case AzureSignalRResourceName:
 		// Azure SignalR (data plane)
 		es.Resource = "https://signalr.azure.com"
-	case "appconfig":
+	case AzureAppConfigResourceName:
 		// Azure App Configuration (data plane)
 		// For documentation https://docs.microsoft.com/en-us/azure/azure-app-configuration/rest-api-authentication-azure-ad#audience
 		// The resource name to request a token is https://azconfig.io
 		es.Resource = "https://azconfig.io"
+	case AzureMicrosoftGraphResourceName:
+		// Microsoft Graph
+		es.Resource = strings.TrimSuffix(azureEnv.MicrosoftGraphEndpoint, "/")
+	case AzureManagedHSMResourceName:
+		// Azure Key Vault Managed HSM (data plane)
+		// Managed HSM pools have their own token audience, distinct from the one of the vaults.
+		resource, ok := managedHSMResources[azureEnv.Name]
+		if !ok {
+			return es, errors.New("managed HSM is not available in the Azure environment " + azureEnv.Name)
+		}
+		es.Resource = resource
 	default:
 		return es, errors.New("invalid resource name: " + resourceName)
 	}
@@ -17,11 +17,6 @@ import (
 	amqpaad "github.com/Azure/azure-amqp-common-go/v4/aad"
 )
 
-const (
-	AzureServiceBusResourceName string = "servicebus"
-	AzureEventHubsResourceName  string = "eventhubs"
-)
-
 // GetAMQPTokenProvider creates a TokenProvider for AAD for AMQP retrieved from, in order:
 // 1. Client credentials
 // 2. Client certificate
@@ -215,3 +215,38 @@ func getTestCert() []byte {
 
 	return certBytes
 }
+
+func TestNewEnvironmentSettingsResources(t *testing.T) {
+	tests := map[string]string{
+		AzureKeyVaultResourceName:       "https://vault.azure.net",
+		AzureStorageResourceName:        "https://storage.azure.com/",
+		AzureCosmosDBResourceName:       "https://cosmos.azure.com",
+		AzureServiceBusResourceName:     "https://servicebus.azure.net/",
+		AzureEventHubsResourceName:      "https://eventhubs.azure.net",
+		AzureMicrosoftGraphResourceName: "https://graph.microsoft.com",
+		AzureManagedHSMResourceName:     "https://managedhsm.azure.net",
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings, err := NewEnvironmentSettings(name, map[string]string{})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, settings.Resource)
+		})
+	}
+
+	t.Run("managed HSM in the China cloud", func(t *testing.T) {
+		settings, err := NewEnvironmentSettings(AzureManagedHSMResourceName, map[string]string{"azureEnvironment": "AZURECHINACLOUD"})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://managedhsm.azure.cn", settings.Resource)
+	})
+
+	t.Run("managed HSM not available", func(t *testing.T) {
+		_, err := NewEnvironmentSettings(AzureManagedHSMResourceName, map[string]string{"azureEnvironment": "AZUREGERMANCLOUD"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid resource", func(t *testing.T) {
+		_, err := NewEnvironmentSettings("invalid", map[string]string{})
+		assert.Error(t, err)
+	})
+}
@@ -37,7 +37,7 @@ var (
 // GetAzureStorageBlobCredentials returns a azblob.Credential object that can be used to authenticate an Azure Blob Storage SDK pipeline ("track 1").
 // First it tries to authenticate using shared key credentials (using an account key) if present. It falls back to attempting to use Azure AD (via a service principal or MSI).
 func GetAzureStorageBlobCredentials(log logger.Logger, accountName string, metadata map[string]string) (azblob.Credential, *azure.Environment, error) {
-	settings, err := NewEnvironmentSettings("storage", metadata)
+	settings, err := NewEnvironmentSettings(AzureStorageResourceName, metadata)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -46,7 +46,7 @@ func GetAzureStorageBlobCredentials(log logger.Logger, accountName string, metad
 	accountKey, ok := mdutils.GetMetadataProperty(metadata, StorageAccountKeyKeys...)
 	if ok && accountKey != "" {
 		credential, newSharedKeyErr := azblob.NewSharedKeyCredential(accountName, accountKey)
-		if err != nil {
+		if newSharedKeyErr != nil {
 			return nil, nil, fmt.Errorf("invalid credentials with error: %s", newSharedKeyErr.Error())
 		}
 
@@ -81,7 +81,7 @@ func GetAzureStorageBlobCredentials(log logger.Logger, accountName string, metad
 // GetAzureStorageQueueCredentials returns a azqueues.Credential object that can be used to authenticate an Azure Queue Storage SDK pipeline ("track 1").
 // First it tries to authenticate using shared key credentials (using an account key) if present. It falls back to attempting to use Azure AD (via a service principal or MSI).
 func GetAzureStorageQueueCredentials(log logger.Logger, accountName string, metadata map[string]string) (azqueue.Credential, *azure.Environment, error) {
-	settings, err := NewEnvironmentSettings("storage", metadata)
+	settings, err := NewEnvironmentSettings(AzureStorageResourceName, metadata)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -90,7 +90,7 @@ func GetAzureStorageQueueCredentials(log logger.Logger, accountName string, meta
 	accountKey, ok := mdutils.GetMetadataProperty(metadata, StorageAccountKeyKeys...)
 	if ok && accountKey != "" {
 		credential, newSharedKeyErr := azqueue.NewSharedKeyCredential(accountName, accountKey)
-		if err != nil {
+		if newSharedKeyErr != nil {
 			return nil, nil, fmt.Errorf("invalid credentials with error: %s", newSharedKeyErr.Error())
 		}
 
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package baggage carries the business context of a request, such as the tenant or the user,
+// in the context and in the W3C baggage format, so that it propagates to the downstream calls.
+package baggage
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Header is the W3C baggage header, also used as the metadata key of the outgoing messages.
+const Header = "baggage"
+
+type contextKey struct{}
+
+// WithValues returns a copy of the context carrying the values, added to the baggage already in the context.
+func WithValues(ctx context.Context, values map[string]string) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+
+	merged := FromContext(ctx)
+	if merged == nil {
+		merged = make(map[string]string, len(values))
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// FromContext returns a copy of the baggage of the context, or nil.
+func FromContext(ctx context.Context) map[string]string {
+	values, _ := ctx.Value(contextKey{}).(map[string]string)
+	if values == nil {
+		return nil
+	}
+
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		res[k] = v
+	}
+
+	return res
+}
+
+// Parse parses the value of a W3C baggage header. The properties of the members are ignored.
+func Parse(header string) map[string]string {
+	res := map[string]string{}
+	for _, member := range strings.Split(header, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(strings.TrimSpace(value)); err == nil {
+			res[key] = unescaped
+		}
+	}
+
+	return res
+}
+
+// Format formats the values as the value of a W3C baggage header, sorted by key.
+func Format(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	members := make([]string, len(keys))
+	for i, k := range keys {
+		members[i] = k + "=" + url.PathEscape(values[k])
+	}
+
+	return strings.Join(members, ",")
+}
+
+// Metadata returns the metadata of an outgoing message with the baggage of the context, added to the baggage
+// already set in the metadata. The metadata is returned as is when the context carries no baggage.
+func Metadata(ctx context.Context, metadata map[string]string) map[string]string {
+	values := FromContext(ctx)
+	if len(values) == 0 {
+		return metadata
+	}
+
+	md := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		md[k] = v
+	}
+	if existing, ok := md[Header]; ok {
+		for k, v := range Parse(existing) {
+			if _, ok := values[k]; !ok {
+				values[k] = v
+			}
+		}
+	}
+	md[Header] = Format(values)
+
+	return md
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baggage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	values := Parse(" tenant = contoso ,user-id=alice%20smith;ttl=10,invalid, =x")
+	assert.Equal(t, map[string]string{"tenant": "contoso", "user-id": "alice smith"}, values)
+	assert.Equal(t, "tenant=contoso,user-id=alice%20smith", Format(values))
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+
+	ctx = WithValues(ctx, map[string]string{"tenant": "contoso"})
+	ctx = WithValues(ctx, map[string]string{"user-id": "alice"})
+	assert.Equal(t, map[string]string{"tenant": "contoso", "user-id": "alice"}, FromContext(ctx))
+
+	// the baggage of the context cannot be modified by its readers
+	FromContext(ctx)["tenant"] = "fabrikam"
+	assert.Equal(t, "contoso", FromContext(ctx)["tenant"])
+}
+
+func TestMetadata(t *testing.T) {
+	md := map[string]string{"key": "1"}
+	assert.Equal(t, md, Metadata(context.Background(), md))
+
+	ctx := WithValues(context.Background(), map[string]string{"tenant": "contoso"})
+	assert.Equal(t, map[string]string{"key": "1", Header: "tenant=contoso"}, Metadata(ctx, md))
+	assert.Equal(t, map[string]string{"key": "1"}, md)
+
+	md[Header] = "tenant=fabrikam,session=abc"
+	assert.Equal(t, "session=abc,tenant=contoso", Metadata(ctx, md)[Header])
+}
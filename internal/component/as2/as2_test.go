@@ -0,0 +1,246 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type station struct {
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+func newStation(t *testing.T, name string) station {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return station{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	}
+}
+
+// newPair returns the configurations of a sender and a receiver that trust each other.
+func newPair(t *testing.T, sign, encrypt bool) (*Config, *Config) {
+	t.Helper()
+
+	a, b := newStation(t, "alpha"), newStation(t, "beta")
+	sender := &Config{
+		From: "ALPHA CORP", To: "BETA",
+		Certificate: a.cert, PrivateKey: a.key, PartnerCertificate: b.cert,
+		Sign: sign, Encrypt: encrypt, SignedMDN: true, MDN: MDNSync,
+	}
+	receiver := &Config{
+		From: "BETA", To: "ALPHA CORP",
+		Certificate: b.cert, PrivateKey: b.key, PartnerCertificate: a.cert,
+		Sign: sign, Encrypt: encrypt, SignedMDN: true, MDN: MDNSync,
+	}
+	require.NoError(t, sender.Validate())
+	require.NoError(t, receiver.Validate())
+
+	return sender, receiver
+}
+
+func TestParseKeys(t *testing.T) {
+	s := newStation(t, "alpha")
+
+	cert, err := ParseCertificate(s.certPEM)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", cert.Subject.CommonName)
+
+	key, err := ParsePrivateKey(s.keyPEM)
+	require.NoError(t, err)
+	assert.IsType(t, &rsa.PrivateKey{}, key)
+
+	_, err = ParseCertificate("garbage")
+	assert.Error(t, err)
+	_, err = ParsePrivateKey("garbage")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		c := &Config{From: "a", To: "b"}
+		require.NoError(t, c.Validate())
+		assert.Equal(t, AES256CBC, c.EncryptionAlgorithm)
+		assert.Equal(t, "sha-256", c.MICAlgorithm)
+		assert.Equal(t, MDNSync, c.MDN)
+	})
+
+	t.Run("signing requires keys", func(t *testing.T) {
+		c := &Config{From: "a", To: "b", Sign: true}
+		assert.Error(t, c.Validate())
+	})
+
+	t.Run("encryption requires partner certificate", func(t *testing.T) {
+		c := &Config{From: "a", To: "b", Encrypt: true}
+		assert.Error(t, c.Validate())
+	})
+
+	t.Run("async requires url", func(t *testing.T) {
+		c := &Config{From: "a", To: "b", MDN: MDNAsync}
+		assert.Error(t, c.Validate())
+	})
+
+	t.Run("invalid algorithms", func(t *testing.T) {
+		assert.Error(t, (&Config{From: "a", To: "b", EncryptionAlgorithm: "rc2"}).Validate())
+		assert.Error(t, (&Config{From: "a", To: "b", MICAlgorithm: "md5"}).Validate())
+	})
+}
+
+func TestSplitMultipart(t *testing.T) {
+	body := []byte("preamble\r\n--b\r\nContent-Type: text/plain\r\n\r\none\r\n--b\r\n\r\ntwo\r\n--b--\r\nepilogue")
+	parts, err := splitMultipart(body, "b")
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "Content-Type: text/plain\r\n\r\none", string(parts[0]))
+	assert.Equal(t, "\r\ntwo", string(parts[1]))
+
+	_, err = splitMultipart([]byte("--b\r\nno end"), "b")
+	assert.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name          string
+		sign, encrypt bool
+	}{
+		{"plain", false, false},
+		{"signed", true, false},
+		{"encrypted", false, true},
+		{"signed and encrypted", true, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sender, receiver := newPair(t, tc.sign, tc.encrypt)
+
+			out, err := sender.Pack(&Message{
+				Subject:     "invoice",
+				ContentType: "application/edi-x12",
+				FileName:    "invoice.x12",
+				Data:        []byte("ISA*00*~"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, `"ALPHA CORP"`, out.Header.Get("AS2-From"))
+
+			in, err := receiver.Unpack(out.Header, out.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "ISA*00*~", string(in.Data))
+			assert.Equal(t, "ALPHA CORP", in.From)
+			assert.Equal(t, "invoice.x12", in.FileName)
+			assert.Equal(t, "application/edi-x12", in.ContentType)
+			assert.Equal(t, tc.sign, in.Signed)
+			assert.Equal(t, tc.encrypt, in.Encrypted)
+			assert.True(t, in.MDNRequested)
+			assert.True(t, in.SignedMDN)
+			assert.True(t, micEqual(out.MIC, in.MIC), "%s != %s", out.MIC, in.MIC)
+
+			mdnOut, err := receiver.NewMDN(in, nil)
+			require.NoError(t, err)
+
+			mdn, err := sender.ParseMDN(mdnOut.Header, mdnOut.Body)
+			require.NoError(t, err)
+			assert.True(t, mdn.Signed)
+			assert.False(t, mdn.Failed())
+			assert.NoError(t, sender.CheckMDN(mdn, out.MessageID, out.MIC))
+		})
+	}
+}
+
+func TestUnpackFailures(t *testing.T) {
+	t.Run("tampered signature", func(t *testing.T) {
+		sender, receiver := newPair(t, true, false)
+		out, err := sender.Pack(&Message{ContentType: "text/plain", Data: []byte("hello")})
+		require.NoError(t, err)
+
+		tampered := bytes.Replace(out.Body, []byte("hello"), []byte("HELLO"), 1)
+		in, err := receiver.Unpack(out.Header, tampered)
+		var pe *ProcessingError
+		require.True(t, errors.As(err, &pe))
+		assert.Equal(t, ErrorIntegrityCheckFailed, pe.Modifier)
+
+		mdnOut, err := receiver.NewMDN(in, err)
+		require.NoError(t, err)
+		mdn, err := sender.ParseMDN(mdnOut.Header, mdnOut.Body)
+		require.NoError(t, err)
+		assert.True(t, mdn.Failed())
+		assert.Contains(t, mdn.Disposition, ErrorIntegrityCheckFailed)
+		assert.Error(t, sender.CheckMDN(mdn, out.MessageID, out.MIC))
+	})
+
+	t.Run("insufficient security", func(t *testing.T) {
+		sender, receiver := newPair(t, false, false)
+		receiver.Sign = true
+		out, err := sender.Pack(&Message{ContentType: "text/plain", Data: []byte("hello")})
+		require.NoError(t, err)
+
+		_, err = receiver.Unpack(out.Header, out.Body)
+		var pe *ProcessingError
+		require.True(t, errors.As(err, &pe))
+		assert.Equal(t, ErrorInsufficientSecurity, pe.Modifier)
+	})
+
+	t.Run("unknown partner", func(t *testing.T) {
+		sender, receiver := newPair(t, false, false)
+		sender.From = "GAMMA"
+		out, err := sender.Pack(&Message{ContentType: "text/plain", Data: []byte("hello")})
+		require.NoError(t, err)
+
+		_, err = receiver.Unpack(out.Header, out.Body)
+		var pe *ProcessingError
+		require.True(t, errors.As(err, &pe))
+		assert.Equal(t, ErrorAuthenticationFailed, pe.Modifier)
+	})
+}
+
+func TestParseNotificationOptions(t *testing.T) {
+	signed, alg := parseNotificationOptions("signed-receipt-protocol=optional, pkcs7-signature; signed-receipt-micalg=optional, md5, sha256, sha1")
+	assert.True(t, signed)
+	assert.Equal(t, "sha-256", alg)
+
+	signed, alg = parseNotificationOptions("")
+	assert.False(t, signed)
+	assert.Empty(t, alg)
+}
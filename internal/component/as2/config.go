@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package as2 implements the AS2 (RFC 4130) message exchange used by bindings that integrate with AS2 trading partners.
+package as2
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// MDN delivery modes.
+const (
+	MDNSync  = "sync"
+	MDNAsync = "async"
+	MDNNone  = "none"
+)
+
+// Supported content encryption algorithms.
+const (
+	AES128CBC = "aes128-cbc"
+	AES256CBC = "aes256-cbc"
+	AES128GCM = "aes128-gcm"
+	AES256GCM = "aes256-gcm"
+)
+
+const (
+	as2Version  = "1.2"
+	reportingUA = "Dapr AS2"
+)
+
+var encryptionAlgorithms = map[string]int{
+	AES128CBC: pkcs7.EncryptionAlgorithmAES128CBC,
+	AES256CBC: pkcs7.EncryptionAlgorithmAES256CBC,
+	AES128GCM: pkcs7.EncryptionAlgorithmAES128GCM,
+	AES256GCM: pkcs7.EncryptionAlgorithmAES256GCM,
+}
+
+// Config describes the local station and the trading partner it exchanges messages with.
+type Config struct {
+	// From is the local AS2 identifier; To is the partner's.
+	From string
+	To   string
+
+	// Certificate and PrivateKey are the local station's keys, used to sign outbound messages and MDNs and to decrypt inbound messages.
+	Certificate *x509.Certificate
+	PrivateKey  crypto.PrivateKey
+	// PartnerCertificate is used to encrypt outbound messages and to verify the partner's signatures.
+	PartnerCertificate *x509.Certificate
+
+	// Sign and Encrypt apply to outbound messages. They are also enforced on inbound messages.
+	Sign    bool
+	Encrypt bool
+
+	// EncryptionAlgorithm is one of the AES* constants. Defaults to AES256CBC.
+	EncryptionAlgorithm string
+	// MICAlgorithm is the digest used for signatures and the MIC, e.g. "sha-256". Defaults to "sha-256".
+	MICAlgorithm string
+
+	// MDN is the requested receipt mode: MDNSync, MDNAsync or MDNNone.
+	MDN string
+	// SignedMDN requests a signed receipt from the partner.
+	SignedMDN bool
+	// AsyncMDNURL is the URL the partner should post asynchronous receipts to.
+	AsyncMDNURL string
+}
+
+// Validate checks the configuration and applies defaults.
+func (c *Config) Validate() error {
+	if c.From == "" || c.To == "" {
+		return errors.New("both the local and the partner AS2 identifiers are required")
+	}
+
+	if c.EncryptionAlgorithm == "" {
+		c.EncryptionAlgorithm = AES256CBC
+	}
+	c.EncryptionAlgorithm = strings.ToLower(c.EncryptionAlgorithm)
+	if _, ok := encryptionAlgorithms[c.EncryptionAlgorithm]; !ok {
+		return fmt.Errorf("unsupported encryption algorithm '%s'", c.EncryptionAlgorithm)
+	}
+
+	if c.MICAlgorithm == "" {
+		c.MICAlgorithm = "sha-256"
+	}
+	if _, err := micHash(c.MICAlgorithm); err != nil {
+		return err
+	}
+	c.MICAlgorithm = canonicalMICAlgorithm(c.MICAlgorithm)
+
+	if c.MDN == "" {
+		c.MDN = MDNSync
+	}
+	switch c.MDN {
+	case MDNSync, MDNNone:
+	case MDNAsync:
+		if c.AsyncMDNURL == "" {
+			return errors.New("an async MDN URL is required for asynchronous receipts")
+		}
+	default:
+		return fmt.Errorf("invalid MDN mode '%s'", c.MDN)
+	}
+
+	if c.Sign && (c.Certificate == nil || c.PrivateKey == nil) {
+		return errors.New("a certificate and private key are required for signing")
+	}
+	if c.Encrypt && c.PartnerCertificate == nil {
+		return errors.New("the partner certificate is required for encryption")
+	}
+	if c.SignedMDN && c.MDN != MDNNone && c.PartnerCertificate == nil {
+		return errors.New("the partner certificate is required to verify signed MDNs")
+	}
+
+	return nil
+}
+
+// ParseCertificate parses a PEM-encoded X.509 certificate.
+func ParseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// ParsePrivateKey parses a PEM-encoded RSA or EC private key in PKCS#1, SEC 1 or PKCS#8 form.
+func ParsePrivateKey(data string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM-encoded private key found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type '%s'", block.Type)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// MDN is a message disposition notification (RFC 3798) returned by the receiver of an AS2 message.
+type MDN struct {
+	MessageID         string
+	OriginalMessageID string
+	Disposition       string
+	MIC               string
+	Signed            bool
+	Text              string
+}
+
+// Failed reports whether the disposition indicates that the message was not processed.
+func (m *MDN) Failed() bool {
+	d := strings.ToLower(m.Disposition)
+	_, status, _ := strings.Cut(d, ";")
+
+	return !strings.Contains(status, "processed") || strings.Contains(status, "/error") || strings.Contains(status, "/failure")
+}
+
+// ParseMDN parses, and verifies when signed, an MDN received in an HTTP response or request.
+func (c *Config) ParseMDN(h http.Header, body []byte) (*MDN, error) {
+	e := entityFromHTTP(h, body)
+	signed := false
+
+	mediaType, params := e.mediaType()
+	if mediaType == "multipart/signed" {
+		content, err := c.verifyEntity(e, params["boundary"])
+		if err != nil {
+			return nil, err
+		}
+		if e, err = parseEntity(content); err != nil {
+			return nil, err
+		}
+		signed = true
+		mediaType, params = e.mediaType()
+	}
+	if mediaType != "multipart/report" {
+		return nil, fmt.Errorf("unexpected MDN content type '%s'", mediaType)
+	}
+
+	mdn, err := c.parseReport(e, params["boundary"])
+	if err != nil {
+		return nil, err
+	}
+	mdn.MessageID = h.Get("Message-ID")
+	mdn.Signed = signed
+
+	return mdn, nil
+}
+
+func (c *Config) parseReport(e *entity, boundary string) (*MDN, error) {
+	parts, err := splitMultipart(e.body, boundary)
+	if err != nil {
+		return nil, err
+	}
+
+	mdn := &MDN{}
+	found := false
+	for _, raw := range parts {
+		part, err := parseEntity(raw)
+		if err != nil {
+			return nil, err
+		}
+		body, err := part.decodedBody()
+		if err != nil {
+			return nil, err
+		}
+
+		switch mediaType, _ := part.mediaType(); mediaType {
+		case "text/plain":
+			if mdn.Text == "" {
+				mdn.Text = strings.TrimSpace(string(body))
+			}
+		case "message/disposition-notification":
+			fields, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(bytes.TrimSpace(body), "\r\n\r\n"...)))).ReadMIMEHeader()
+			if err != nil {
+				return nil, fmt.Errorf("invalid disposition notification: %w", err)
+			}
+			mdn.OriginalMessageID = fields.Get("Original-Message-ID")
+			mdn.Disposition = fields.Get("Disposition")
+			mdn.MIC = fields.Get("Received-Content-MIC")
+			found = true
+		}
+	}
+	if !found {
+		return nil, errors.New("report does not contain a disposition notification")
+	}
+
+	return mdn, nil
+}
+
+// CheckMDN validates a receipt against the message it acknowledges.
+func (c *Config) CheckMDN(mdn *MDN, messageID, mic string) error {
+	if mdn.OriginalMessageID != messageID {
+		return fmt.Errorf("MDN is for message %s, expected %s", mdn.OriginalMessageID, messageID)
+	}
+	if c.SignedMDN && !mdn.Signed {
+		return errors.New("MDN is not signed")
+	}
+	if mdn.Failed() {
+		return fmt.Errorf("partner reported disposition '%s'", mdn.Disposition)
+	}
+	if (c.Sign || c.Encrypt) && !micEqual(mdn.MIC, mic) {
+		return fmt.Errorf("MDN MIC '%s' does not match '%s'", mdn.MIC, mic)
+	}
+
+	return nil
+}
+
+// NewMDN builds the receipt for an inbound message.
+// A nil procErr reports the message as processed; otherwise the error's disposition modifier is reported.
+func (c *Config) NewMDN(in *Inbound, procErr error) (*Outbound, error) {
+	disposition := "automatic-action/MDN-sent-automatically; processed"
+	text := "The AS2 message has been received and processed."
+	if procErr != nil {
+		modifier := ErrorUnexpectedProcessing
+		var pe *ProcessingError
+		if errors.As(procErr, &pe) {
+			modifier = pe.Modifier
+		}
+		disposition += "/error: " + modifier
+		text = "The AS2 message could not be processed: " + modifier + "."
+	}
+
+	var fields bytes.Buffer
+	fields.WriteString("Reporting-UA: " + reportingUA + "\r\n")
+	fields.WriteString("Original-Recipient: rfc822; " + quoteName(c.From) + "\r\n")
+	fields.WriteString("Final-Recipient: rfc822; " + quoteName(c.From) + "\r\n")
+	fields.WriteString("Original-Message-ID: " + in.MessageID + "\r\n")
+	if in.MIC != "" && procErr == nil {
+		fields.WriteString("Received-Content-MIC: " + in.MIC + "\r\n")
+	}
+	fields.WriteString("Disposition: " + disposition + "\r\n")
+
+	textPart := newEntity("text/plain; charset=us-ascii", []byte(text+"\r\n"))
+	textPart.header.Set("Content-Transfer-Encoding", "7bit")
+	notification := newEntity("message/disposition-notification", fields.Bytes())
+	notification.header.Set("Content-Transfer-Encoding", "7bit")
+
+	boundary := newBoundary()
+	e := newEntity(fmt.Sprintf(`multipart/report; report-type=disposition-notification; boundary="%s"`, boundary),
+		multipart(boundary, textPart.bytes(), notification.bytes()))
+
+	if in.SignedMDN && c.Certificate != nil && c.PrivateKey != nil {
+		signer := *c
+		if in.MDNMICAlgorithm != "" {
+			signer.MICAlgorithm = in.MDNMICAlgorithm
+		}
+		var err error
+		if e, _, err = signer.signEntity(e); err != nil {
+			return nil, err
+		}
+	}
+
+	to := in.From
+	if to == "" {
+		to = c.To
+	}
+	messageID := c.newMessageID()
+	h := c.baseHeader(to, messageID)
+	h.Set("Subject", "Message Disposition Notification")
+
+	return &Outbound{
+		MessageID: messageID,
+		Header:    h,
+		Body:      entityToHTTP(e, h),
+	}, nil
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Disposition modifiers reported in MDNs for failed messages (RFC 4130 section 7.4.3).
+const (
+	ErrorAuthenticationFailed    = "authentication-failed"
+	ErrorDecryptionFailed        = "decryption-failed"
+	ErrorIntegrityCheckFailed    = "integrity-check-failed"
+	ErrorInsufficientSecurity    = "insufficient-message-security"
+	ErrorUnexpectedProcessing    = "unexpected-processing-error"
+	ErrorUnsupportedMICAlgorithm = "unsupported-mic-algorithms"
+)
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Message is an outbound AS2 payload.
+type Message struct {
+	// ID is the Message-ID including angle brackets. Generated when empty.
+	ID          string
+	Subject     string
+	ContentType string
+	FileName    string
+	Data        []byte
+}
+
+// Outbound is a packed AS2 message or MDN, ready to be sent over HTTP.
+type Outbound struct {
+	MessageID string
+	Header    http.Header
+	Body      []byte
+	// MIC is the value the receiver is expected to return in its MDN.
+	MIC string
+}
+
+// ProcessingError is an inbound failure that is reported to the partner in the MDN disposition.
+type ProcessingError struct {
+	Modifier string
+	Err      error
+}
+
+func (e *ProcessingError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Modifier, e.Err)
+}
+
+func (e *ProcessingError) Unwrap() error {
+	return e.Err
+}
+
+// Inbound is a received AS2 message.
+type Inbound struct {
+	MessageID   string
+	From        string
+	To          string
+	Subject     string
+	ContentType string
+	FileName    string
+	Data        []byte
+	Signed      bool
+	Encrypted   bool
+	// MIC is the Received-Content-MIC to report back in the MDN.
+	MIC string
+	// MDN is set when the inbound message is an asynchronous receipt rather than a payload.
+	MDN *MDN
+
+	// Receipt requested by the sender.
+	MDNRequested    bool
+	SignedMDN       bool
+	MDNMICAlgorithm string
+	AsyncMDNURL     string
+}
+
+func quoteName(name string) string {
+	if strings.ContainsAny(name, " \t\"\\") {
+		return `"` + strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), `"`, `\"`) + `"`
+	}
+
+	return name
+}
+
+func unquoteName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		name = strings.ReplaceAll(strings.ReplaceAll(name[1:len(name)-1], `\"`, `"`), `\\`, `\`)
+	}
+
+	return name
+}
+
+func (c *Config) newMessageID() string {
+	return "<" + uuid.NewString() + "@" + unsafeIDChars.ReplaceAllString(c.From, "-") + ">"
+}
+
+func (c *Config) baseHeader(to, messageID string) http.Header {
+	h := http.Header{}
+	h.Set("AS2-Version", as2Version)
+	h.Set("AS2-From", quoteName(c.From))
+	h.Set("AS2-To", quoteName(to))
+	h.Set("Message-ID", messageID)
+	h.Set("Date", time.Now().UTC().Format(time.RFC1123Z))
+	h.Set("MIME-Version", "1.0")
+
+	return h
+}
+
+// entityToHTTP moves the outer entity headers to the HTTP header; the entity body becomes the request body.
+func entityToHTTP(e *entity, h http.Header) []byte {
+	for k, v := range e.header {
+		h[k] = v
+	}
+
+	return e.body
+}
+
+func entityFromHTTP(h http.Header, body []byte) *entity {
+	e := &entity{header: map[string][]string{}, body: body}
+	for _, k := range []string{"Content-Type", "Content-Transfer-Encoding", "Content-Disposition"} {
+		if v := h.Values(k); len(v) > 0 {
+			e.header[k] = v
+		}
+	}
+
+	return e
+}
+
+// Pack builds the HTTP request for a message, signing and encrypting it as configured.
+func (c *Config) Pack(msg *Message) (*Outbound, error) {
+	messageID := msg.ID
+	if messageID == "" {
+		messageID = c.newMessageID()
+	}
+	contentType := msg.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	e := newEntity(contentType, msg.Data)
+	e.header.Set("Content-Transfer-Encoding", "binary")
+	if msg.FileName != "" {
+		e.header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, msg.FileName))
+	}
+
+	// The MIC covers the signed content, or the MIME entity of an encrypted message, or only the payload otherwise.
+	micContent := msg.Data
+	if c.Sign {
+		var err error
+		e, micContent, err = c.signEntity(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.Encrypt {
+		if !c.Sign {
+			micContent = e.bytes()
+		}
+		var err error
+		e, err = c.encryptEntity(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mic, err := computeMIC(micContent, c.MICAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.baseHeader(c.To, messageID)
+	if msg.Subject != "" {
+		h.Set("Subject", msg.Subject)
+	}
+	if c.MDN != MDNNone {
+		h.Set("Disposition-Notification-To", c.From)
+		if c.SignedMDN {
+			h.Set("Disposition-Notification-Options", "signed-receipt-protocol=optional, pkcs7-signature; signed-receipt-micalg=optional, "+c.MICAlgorithm)
+		}
+		if c.MDN == MDNAsync {
+			h.Set("Receipt-Delivery-Option", c.AsyncMDNURL)
+		}
+	}
+
+	return &Outbound{
+		MessageID: messageID,
+		Header:    h,
+		Body:      entityToHTTP(e, h),
+		MIC:       mic,
+	}, nil
+}
+
+// SendResult is the outcome of sending a message.
+type SendResult struct {
+	MessageID string
+	MIC       string
+	// MDN is the synchronous receipt, if one was requested.
+	MDN *MDN
+}
+
+// Send packs the message, posts it to the partner URL and, for synchronous receipts, validates the returned MDN.
+func (c *Config) Send(ctx context.Context, client *http.Client, url string, msg *Message) (*SendResult, error) {
+	out, err := c.Pack(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(out.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = out.Header
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("partner returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	result := &SendResult{MessageID: out.MessageID, MIC: out.MIC}
+	if c.MDN != MDNSync {
+		return result, nil
+	}
+
+	mdn, err := c.ParseMDN(resp.Header, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MDN: %w", err)
+	}
+	result.MDN = mdn
+	if err = c.CheckMDN(mdn, out.MessageID, out.MIC); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Unpack decrypts and verifies an inbound request.
+// The returned Inbound is populated as far as processing got, so that an MDN can be built even when an error is returned.
+func (c *Config) Unpack(h http.Header, body []byte) (*Inbound, error) {
+	in := &Inbound{
+		MessageID: h.Get("Message-ID"),
+		From:      unquoteName(h.Get("AS2-From")),
+		To:        unquoteName(h.Get("AS2-To")),
+		Subject:   h.Get("Subject"),
+	}
+	in.MDNRequested = h.Get("Disposition-Notification-To") != ""
+	in.AsyncMDNURL = h.Get("Receipt-Delivery-Option")
+	in.SignedMDN, in.MDNMICAlgorithm = parseNotificationOptions(h.Get("Disposition-Notification-Options"))
+
+	if in.MessageID == "" || in.From == "" || in.To == "" {
+		return in, errors.New("missing AS2 headers")
+	}
+	if in.From != c.To || in.To != c.From {
+		return in, &ProcessingError{ErrorAuthenticationFailed, fmt.Errorf("unexpected AS2 identifiers from '%s' to '%s'", in.From, in.To)}
+	}
+
+	e := entityFromHTTP(h, body)
+	var micContent []byte
+	var micAlg string
+	for {
+		mediaType, params := e.mediaType()
+		switch mediaType {
+		case "application/pkcs7-mime", "application/x-pkcs7-mime":
+			if in.Encrypted || in.Signed {
+				return in, &ProcessingError{ErrorUnexpectedProcessing, errors.New("unexpected nested encryption")}
+			}
+			if smime := strings.ToLower(params["smime-type"]); smime != "" && smime != "enveloped-data" {
+				return in, &ProcessingError{ErrorUnexpectedProcessing, fmt.Errorf("unsupported smime-type '%s'", smime)}
+			}
+			raw, err := c.decryptEntity(e)
+			if err != nil {
+				return in, &ProcessingError{ErrorDecryptionFailed, err}
+			}
+			if e, err = parseEntity(raw); err != nil {
+				return in, &ProcessingError{ErrorDecryptionFailed, err}
+			}
+			in.Encrypted = true
+			micContent = raw
+			continue
+
+		case "multipart/signed":
+			if in.Signed {
+				return in, &ProcessingError{ErrorUnexpectedProcessing, errors.New("unexpected nested signature")}
+			}
+			signed, err := c.verifyEntity(e, params["boundary"])
+			if err != nil {
+				return in, &ProcessingError{ErrorIntegrityCheckFailed, err}
+			}
+			if e, err = parseEntity(signed); err != nil {
+				return in, &ProcessingError{ErrorIntegrityCheckFailed, err}
+			}
+			in.Signed = true
+			micContent = signed
+			micAlg = params["micalg"]
+			continue
+
+		case "multipart/report":
+			mdn, err := c.parseReport(e, params["boundary"])
+			if err != nil {
+				return in, &ProcessingError{ErrorUnexpectedProcessing, err}
+			}
+			mdn.MessageID = in.MessageID
+			mdn.Signed = in.Signed
+			in.MDN = mdn
+			// Receipts are never acknowledged with another receipt.
+			in.MDNRequested = false
+			return in, nil
+		}
+
+		break
+	}
+
+	if (c.Sign && !in.Signed) || (c.Encrypt && !in.Encrypted) {
+		return in, &ProcessingError{ErrorInsufficientSecurity, errors.New("message does not meet the configured signing and encryption requirements")}
+	}
+
+	data, err := e.decodedBody()
+	if err != nil {
+		return in, &ProcessingError{ErrorUnexpectedProcessing, err}
+	}
+	in.Data = data
+	in.ContentType = e.header.Get("Content-Type")
+	if _, params, perr := mime.ParseMediaType(e.header.Get("Content-Disposition")); perr == nil {
+		in.FileName = params["filename"]
+	}
+
+	if micContent == nil {
+		micContent = data
+	}
+	alg := in.MDNMICAlgorithm
+	if alg == "" {
+		alg = micAlg
+	}
+	if alg == "" {
+		alg = c.MICAlgorithm
+	}
+	if in.MIC, err = computeMIC(micContent, alg); err != nil {
+		return in, &ProcessingError{ErrorUnsupportedMICAlgorithm, err}
+	}
+
+	return in, nil
+}
+
+// parseNotificationOptions parses a Disposition-Notification-Options header, e.g.
+// "signed-receipt-protocol=optional, pkcs7-signature; signed-receipt-micalg=optional, sha-256, sha1".
+// It returns whether a signed receipt was requested and the first supported MIC algorithm.
+func parseNotificationOptions(value string) (signed bool, micAlg string) {
+	for _, opt := range strings.Split(value, ";") {
+		name, values, ok := strings.Cut(opt, "=")
+		if !ok {
+			continue
+		}
+		list := strings.Split(values, ",")
+		// The first value is the importance ("required" or "optional").
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "signed-receipt-protocol":
+			for _, v := range list[1:] {
+				if strings.EqualFold(strings.TrimSpace(v), "pkcs7-signature") {
+					signed = true
+				}
+			}
+		case "signed-receipt-micalg":
+			for _, v := range list[1:] {
+				if _, err := micHash(v); err == nil && micAlg == "" {
+					micAlg = canonicalMICAlgorithm(v)
+				}
+			}
+		}
+	}
+
+	return signed, micAlg
+}
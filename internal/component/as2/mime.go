@@ -0,0 +1,386 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+
+	// Register the digests that may be negotiated as MIC algorithms.
+	_ "crypto/sha1" //nolint:gosec
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// The pkcs7 package selects the content encryption algorithm through a package-level variable.
+var encryptLock sync.Mutex
+
+// entity is a MIME entity: a header block followed by a body.
+type entity struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func newEntity(contentType string, body []byte) *entity {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType)
+
+	return &entity{header: h, body: body}
+}
+
+// bytes serializes the entity with CRLF line endings.
+// Content-Type is written first and the remaining headers in sorted order, so the output is stable.
+func (e *entity) bytes() []byte {
+	keys := make([]string, 0, len(e.header))
+	for k := range e.header {
+		if k != "Content-Type" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := e.header["Content-Type"]; ok {
+		keys = append([]string{"Content-Type"}, keys...)
+	}
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		for _, v := range e.header[k] {
+			buf.WriteString(k + ": " + v + "\r\n")
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(e.body)
+
+	return buf.Bytes()
+}
+
+// decodedBody returns the body with its Content-Transfer-Encoding removed.
+func (e *entity) decodedBody() ([]byte, error) {
+	switch strings.ToLower(e.header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		clean := bytes.Map(func(r rune) rune {
+			if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+				return -1
+			}
+			return r
+		}, e.body)
+		out := make([]byte, base64.StdEncoding.DecodedLen(len(clean)))
+		n, err := base64.StdEncoding.Decode(out, clean)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 body: %w", err)
+		}
+		return out[:n], nil
+	case "quoted-printable":
+		return io.ReadAll(quotedprintable.NewReader(bytes.NewReader(e.body)))
+	default:
+		return e.body, nil
+	}
+}
+
+func (e *entity) mediaType() (string, map[string]string) {
+	mediaType, params, err := mime.ParseMediaType(e.header.Get("Content-Type"))
+	if err != nil {
+		return "", nil
+	}
+
+	return mediaType, params
+}
+
+func parseEntity(raw []byte) (*entity, error) {
+	br := bufio.NewReader(bytes.NewReader(raw))
+	header, err := textproto.NewReader(br).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid MIME header: %w", err)
+	}
+	body, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity{header: header, body: body}, nil
+}
+
+// splitMultipart returns the raw parts, headers included, of a multipart body.
+// Parts are returned byte-for-byte so that signatures over them can be verified.
+func splitMultipart(body []byte, boundary string) ([][]byte, error) {
+	if boundary == "" {
+		return nil, errors.New("multipart entity without boundary")
+	}
+	delim := []byte("--" + boundary)
+
+	next := indexDelimiter(body, delim, 0)
+	if next < 0 {
+		return nil, errors.New("multipart boundary not found")
+	}
+
+	var parts [][]byte
+	for {
+		pos := next + len(delim)
+		if bytes.HasPrefix(body[pos:], []byte("--")) {
+			return parts, nil
+		}
+		nl := bytes.IndexByte(body[pos:], '\n')
+		if nl < 0 {
+			return nil, errors.New("truncated multipart entity")
+		}
+		start := pos + nl + 1
+
+		next = indexDelimiter(body, delim, start)
+		if next < 0 {
+			return nil, errors.New("multipart closing boundary not found")
+		}
+
+		// The line break preceding a delimiter belongs to the delimiter.
+		end := next
+		if end > start && body[end-1] == '\n' {
+			end--
+			if end > start && body[end-1] == '\r' {
+				end--
+			}
+		}
+		parts = append(parts, body[start:end])
+	}
+}
+
+// indexDelimiter finds the next occurrence of delim at the start of a line.
+func indexDelimiter(body, delim []byte, from int) int {
+	for off := from; off < len(body); {
+		i := bytes.Index(body[off:], delim)
+		if i < 0 {
+			return -1
+		}
+		i += off
+		if i == 0 || body[i-1] == '\n' {
+			return i
+		}
+		off = i + 1
+	}
+
+	return -1
+}
+
+func newBoundary() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return "----=_Part_" + hex.EncodeToString(b)
+}
+
+// multipart assembles raw parts into a multipart body.
+func multipart(boundary string, parts ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, p := range parts {
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.Write(p)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes()
+}
+
+// signEntity wraps the entity in a multipart/signed entity with a detached PKCS#7 signature.
+func (c *Config) signEntity(e *entity) (signed *entity, signedContent []byte, err error) {
+	signedContent = e.bytes()
+
+	sd, err := pkcs7.NewSignedData(signedContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	sd.SetDigestAlgorithm(digestOID(c.MICAlgorithm))
+	if err = sd.AddSigner(c.Certificate, c.PrivateKey, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+	sd.Detach()
+	sig, err := sd.Finish()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	sigEntity := newEntity(`application/pkcs7-signature; name="smime.p7s"`, base64Lines(sig))
+	sigEntity.header.Set("Content-Transfer-Encoding", "base64")
+	sigEntity.header.Set("Content-Disposition", `attachment; filename="smime.p7s"`)
+
+	boundary := newBoundary()
+	signed = newEntity(fmt.Sprintf(`multipart/signed; protocol="application/pkcs7-signature"; micalg=%s; boundary="%s"`, c.MICAlgorithm, boundary),
+		multipart(boundary, signedContent, sigEntity.bytes()))
+
+	return signed, signedContent, nil
+}
+
+// verifyEntity checks a multipart/signed entity against the partner certificate and returns the signed content.
+func (c *Config) verifyEntity(e *entity, boundary string) ([]byte, error) {
+	parts, err := splitMultipart(e.body, boundary)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("multipart/signed entity has %d parts, expected 2", len(parts))
+	}
+
+	sigEntity, err := parseEntity(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sigEntity.decodedBody()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.PartnerCertificate == nil {
+		return nil, errors.New("no partner certificate configured to verify the signature")
+	}
+	p7, err := pkcs7.Parse(sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	p7.Content = parts[0]
+	if err = p7.Verify(); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	}
+	if signer := p7.GetOnlySigner(); signer == nil || !bytes.Equal(signer.Raw, c.PartnerCertificate.Raw) {
+		return nil, errors.New("message was not signed with the partner certificate")
+	}
+
+	return parts[0], nil
+}
+
+// encryptEntity wraps the entity in an application/pkcs7-mime enveloped-data entity.
+func (c *Config) encryptEntity(e *entity) (*entity, error) {
+	encryptLock.Lock()
+	pkcs7.ContentEncryptionAlgorithm = encryptionAlgorithms[c.EncryptionAlgorithm]
+	der, err := pkcs7.Encrypt(e.bytes(), []*x509.Certificate{c.PartnerCertificate})
+	encryptLock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt message: %w", err)
+	}
+
+	encrypted := newEntity(`application/pkcs7-mime; smime-type=enveloped-data; name="smime.p7m"`, der)
+	encrypted.header.Set("Content-Transfer-Encoding", "binary")
+	encrypted.header.Set("Content-Disposition", `attachment; filename="smime.p7m"`)
+
+	return encrypted, nil
+}
+
+// decryptEntity decrypts an enveloped-data entity and returns the raw inner entity.
+func (c *Config) decryptEntity(e *entity) ([]byte, error) {
+	if c.Certificate == nil || c.PrivateKey == nil {
+		return nil, errors.New("no certificate and private key configured for decryption")
+	}
+	der, err := e.decodedBody()
+	if err != nil {
+		return nil, err
+	}
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid enveloped data: %w", err)
+	}
+
+	return p7.Decrypt(c.Certificate, c.PrivateKey)
+}
+
+func base64Lines(data []byte) []byte {
+	enc := base64.StdEncoding.EncodeToString(data)
+
+	var buf bytes.Buffer
+	for len(enc) > 76 {
+		buf.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	buf.WriteString(enc)
+
+	return buf.Bytes()
+}
+
+// canonicalMICAlgorithm normalizes a MIC algorithm name to the RFC 5751 form, e.g. "sha256" to "sha-256".
+func canonicalMICAlgorithm(alg string) string {
+	alg = strings.ToLower(strings.TrimSpace(alg))
+	switch alg {
+	case "sha1", "sha-1":
+		return "sha1"
+	case "sha256", "sha-256":
+		return "sha-256"
+	case "sha384", "sha-384":
+		return "sha-384"
+	case "sha512", "sha-512":
+		return "sha-512"
+	default:
+		return alg
+	}
+}
+
+func micHash(alg string) (crypto.Hash, error) {
+	switch canonicalMICAlgorithm(alg) {
+	case "sha1":
+		return crypto.SHA1, nil
+	case "sha-256":
+		return crypto.SHA256, nil
+	case "sha-384":
+		return crypto.SHA384, nil
+	case "sha-512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported MIC algorithm '%s'", alg)
+	}
+}
+
+func digestOID(alg string) asn1.ObjectIdentifier {
+	switch canonicalMICAlgorithm(alg) {
+	case "sha1":
+		return pkcs7.OIDDigestAlgorithmSHA1
+	case "sha-384":
+		return pkcs7.OIDDigestAlgorithmSHA384
+	case "sha-512":
+		return pkcs7.OIDDigestAlgorithmSHA512
+	default:
+		return pkcs7.OIDDigestAlgorithmSHA256
+	}
+}
+
+// computeMIC returns the Received-Content-MIC value for the content, e.g. "<base64>, sha-256".
+func computeMIC(content []byte, alg string) (string, error) {
+	h, err := micHash(alg)
+	if err != nil {
+		return "", err
+	}
+	d := h.New()
+	d.Write(content)
+
+	return base64.StdEncoding.EncodeToString(d.Sum(nil)) + ", " + canonicalMICAlgorithm(alg), nil
+}
+
+// micEqual compares two MIC values, tolerating whitespace and algorithm spelling differences.
+func micEqual(a, b string) bool {
+	av, aalg, _ := strings.Cut(a, ",")
+	bv, balg, _ := strings.Cut(b, ",")
+
+	return strings.TrimSpace(av) == strings.TrimSpace(bv) && canonicalMICAlgorithm(aalg) == canonicalMICAlgorithm(balg)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package as2
+
+import (
+	"fmt"
+)
+
+// Settings is the component metadata for an AS2 trading partner.
+// It is meant to be embedded, squashed, in a component's metadata struct.
+type Settings struct {
+	URL                 string `mapstructure:"as2Url"`
+	From                string `mapstructure:"as2From"`
+	To                  string `mapstructure:"as2To"`
+	Certificate         string `mapstructure:"as2Certificate"`
+	PrivateKey          string `mapstructure:"as2PrivateKey"`
+	PartnerCertificate  string `mapstructure:"as2PartnerCertificate"`
+	Sign                bool   `mapstructure:"as2Sign"`
+	Encrypt             bool   `mapstructure:"as2Encrypt"`
+	EncryptionAlgorithm string `mapstructure:"as2EncryptionAlgorithm"`
+	MICAlgorithm        string `mapstructure:"as2MicAlgorithm"`
+	MDN                 string `mapstructure:"as2Mdn"`
+	SignedMDN           bool   `mapstructure:"as2SignedMdn"`
+	AsyncMDNURL         string `mapstructure:"as2AsyncMdnUrl"`
+}
+
+// NewSettings returns the default settings: signed and encrypted messages with signed synchronous receipts.
+func NewSettings() Settings {
+	return Settings{
+		Sign:      true,
+		Encrypt:   true,
+		MDN:       MDNSync,
+		SignedMDN: true,
+	}
+}
+
+// Config parses the keys and returns a validated Config.
+func (s Settings) Config() (*Config, error) {
+	c := &Config{
+		From:                s.From,
+		To:                  s.To,
+		Sign:                s.Sign,
+		Encrypt:             s.Encrypt,
+		EncryptionAlgorithm: s.EncryptionAlgorithm,
+		MICAlgorithm:        s.MICAlgorithm,
+		MDN:                 s.MDN,
+		SignedMDN:           s.SignedMDN,
+		AsyncMDNURL:         s.AsyncMDNURL,
+	}
+
+	var err error
+	if s.Certificate != "" {
+		if c.Certificate, err = ParseCertificate(s.Certificate); err != nil {
+			return nil, fmt.Errorf("invalid as2Certificate: %w", err)
+		}
+	}
+	if s.PrivateKey != "" {
+		if c.PrivateKey, err = ParsePrivateKey(s.PrivateKey); err != nil {
+			return nil, fmt.Errorf("invalid as2PrivateKey: %w", err)
+		}
+	}
+	if s.PartnerCertificate != "" {
+		if c.PartnerCertificate, err = ParseCertificate(s.PartnerCertificate); err != nil {
+			return nil, fmt.Errorf("invalid as2PartnerCertificate: %w", err)
+		}
+	}
+
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
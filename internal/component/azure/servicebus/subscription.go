@@ -23,6 +23,7 @@ import (
 	azservicebus "github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
 	"go.uber.org/ratelimit"
 
+	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/ptr"
 	"github.com/dapr/kit/retry"
@@ -57,6 +58,11 @@ type Subscription struct {
 	maxBulkSubCount      int
 	retriableErrLimit    ratelimit.Limiter
 	handleChan           chan struct{}
+	inFlight             pubsub.InFlight
+	topic                string
+	deadLetterEntity     string
+	maxDeliveryCount     uint32
+	stats                pubsub.StatsRecorder
 	logger               logger.Logger
 	ctx                  context.Context
 	cancel               context.CancelFunc
@@ -71,8 +77,17 @@ type SubsriptionOptions struct {
 	Entity                string
 	LockRenewalInSec      int
 	RequireSessions       bool
+	// Topic (or queue) the redeliveries and the dead-lettered messages are recorded for, and the recorder, if any.
+	Topic string
+	Stats pubsub.StatsRecorder
+	// Dead-letter queue of the entity, and the number of deliveries after which messages are moved to it (the server default if unset).
+	DeadLetterEntity string
+	MaxDeliveryCount *int32
 }
 
+// defaultMaxDeliveryCount is the maximum number of deliveries of the entities created with the default settings.
+const defaultMaxDeliveryCount = 10
+
 // NewBulkSubscription returns a new Subscription object.
 // Parameter "entity" is usually in the format "topic <topicname>" or "queue <queuename>" and it's only used for logging.
 func NewSubscription(
@@ -98,19 +113,27 @@ func NewSubscription(
 	}
 
 	s := &Subscription{
-		entity:          opts.Entity,
-		activeMessages:  make(map[int64]*azservicebus.ReceivedMessage),
-		timeout:         time.Duration(opts.TimeoutInSec) * time.Second,
-		maxBulkSubCount: *opts.MaxBulkSubCount,
-		requireSessions: opts.RequireSessions,
-		logger:          logger,
-		ctx:             ctx,
-		cancel:          cancel,
+		entity:           opts.Entity,
+		activeMessages:   make(map[int64]*azservicebus.ReceivedMessage),
+		timeout:          time.Duration(opts.TimeoutInSec) * time.Second,
+		maxBulkSubCount:  *opts.MaxBulkSubCount,
+		requireSessions:  opts.RequireSessions,
+		logger:           logger,
+		ctx:              ctx,
+		cancel:           cancel,
+		topic:            opts.Topic,
+		stats:            opts.Stats,
+		deadLetterEntity: opts.DeadLetterEntity,
+		maxDeliveryCount: defaultMaxDeliveryCount,
 		// This is a pessimistic estimate of the number of total operations that can be active at any given time.
 		// In case of a non-bulk subscription, one operation is one message.
 		activeOperationsChan: make(chan struct{}, opts.MaxActiveMessages/(*opts.MaxBulkSubCount)),
 	}
 
+	if opts.MaxDeliveryCount != nil && *opts.MaxDeliveryCount > 0 {
+		s.maxDeliveryCount = uint32(*opts.MaxDeliveryCount)
+	}
+
 	if opts.MaxRetriableEPS > 0 {
 		s.retriableErrLimit = ratelimit.New(opts.MaxRetriableEPS)
 	} else {
@@ -182,6 +205,16 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 	ctx, cancel := context.WithCancel(s.ctx)
 	defer cancel()
 
+	// Stop receiving as soon as the subscription is draining, without canceling the messages being handled
+	draining := s.inFlight.Draining()
+	go func() {
+		select {
+		case <-draining:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	// Receiver loop
 	for {
 		select {
@@ -192,7 +225,7 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 		case <-ctx.Done():
 			// Return if context is canceled
 			s.logger.Debugf("Receive context for %s done", s.entity)
-			return ctx.Err()
+			return s.waitDrained(ctx.Err())
 		}
 
 		// If we require sessions then we must have a timeout to allow
@@ -206,7 +239,7 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 			receiverCtx, receiverCancel = context.WithTimeout(ctx, opts.SessionIdleTimeout)
 			defer receiverCancel()
 		} else {
-			receiverCtx = s.ctx
+			receiverCtx = ctx
 		}
 
 		// This method blocks until we get a message or the context is canceled
@@ -217,7 +250,7 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 			}
 			<-s.activeOperationsChan
 			// Return the error. This will cause the Service Bus component to try and reconnect.
-			return err
+			return s.waitDrained(err)
 		}
 
 		l := len(msgs)
@@ -252,12 +285,25 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 			}
 			s.logger.Debugf("Processing received message: %s", msg.MessageID)
 		}
+		s.recordRedeliveries(msgs)
 
 		if skipProcessing {
 			<-s.activeOperationsChan
 			continue
 		}
 
+		if !s.inFlight.Begin() {
+			// The subscription started draining while receiving: abandon the messages right away for the other receivers
+			finalizeCtx, finalizeCancel := context.WithTimeout(context.Background(), s.timeout)
+			for _, msg := range msgs {
+				s.AbandonMessage(finalizeCtx, receiver, msg)
+				s.removeActiveMessage(msg.MessageID, *msg.SequenceNumber)
+			}
+			finalizeCancel()
+			<-s.activeOperationsChan
+			continue
+		}
+
 		runHandlerFn := func(hctx context.Context) {
 			msg := msgs[0]
 
@@ -273,7 +319,7 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 			if err != nil {
 				// Log the error only, as we're running asynchronously
 				s.logger.Errorf("App handler returned an error for message %s on %s: %s", msg.MessageID, s.entity, err)
-				s.AbandonMessage(finalizeCtx, receiver, msg)
+				s.abandonFailedMessage(finalizeCtx, receiver, msg)
 				return
 			}
 
@@ -296,7 +342,7 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 					if resp.Error != nil {
 						// Log the error only, as we're running asynchronously.
 						s.logger.Errorf("App handler returned an error for message %s on %s: %s", msgs[i].MessageID, s.entity, resp.Error)
-						s.AbandonMessage(finalizeCtx, receiver, msgs[i])
+						s.abandonFailedMessage(finalizeCtx, receiver, msgs[i])
 					} else {
 						s.CompleteMessage(finalizeCtx, receiver, msgs[i])
 					}
@@ -318,6 +364,36 @@ func (s *Subscription) ReceiveBlocking(handler HandlerFn, receiver Receiver, onF
 	}
 }
 
+// waitDrained returns err once the subscription is drained, if it is draining.
+// This keeps the receiver open, so the messages being handled can still be completed or abandoned.
+func (s *Subscription) waitDrained(err error) error {
+	if !s.IsDraining() {
+		return err
+	}
+
+	<-s.ctx.Done()
+	return s.ctx.Err()
+}
+
+// Drain stops receiving messages, waits for the active messages to be completed or abandoned until ctx is done, then closes the subscription.
+func (s *Subscription) Drain(ctx context.Context) error {
+	s.logger.Debugf("Draining subscription to %s", s.entity)
+	err := s.inFlight.Drain(ctx)
+	s.cancel()
+
+	return err
+}
+
+// IsDraining returns true if the subscription is draining or drained.
+func (s *Subscription) IsDraining() bool {
+	select {
+	case <-s.inFlight.Draining():
+		return true
+	default:
+		return false
+	}
+}
+
 // Close the receiver and stops watching for new messages.
 func (s *Subscription) Close(closeCtx context.Context) {
 	s.logger.Debugf("Closing subscription to %s", s.entity)
@@ -417,6 +493,7 @@ func (s *Subscription) handleAsync(ctx context.Context, msgs []*azservicebus.Rec
 
 			// Remove an entry from activeOperationsChan to allow processing more messages
 			<-s.activeOperationsChan
+			s.inFlight.End()
 		}()
 
 		for _, msg := range msgs {
@@ -460,6 +537,27 @@ func (s *Subscription) AbandonMessage(ctx context.Context, receiver Receiver, m
 	s.logger.Debugf("Resumed after pausing for %v", time.Since(before))
 }
 
+// abandonFailedMessage abandons a message the handler failed to process.
+// Service Bus moves the message to the dead-letter queue once its last delivery is abandoned.
+func (s *Subscription) abandonFailedMessage(ctx context.Context, receiver Receiver, m *azservicebus.ReceivedMessage) {
+	s.AbandonMessage(ctx, receiver, m)
+	if s.stats != nil && m.DeliveryCount >= s.maxDeliveryCount {
+		s.stats.RecordDeadLetter(s.topic, s.deadLetterEntity)
+	}
+}
+
+// recordRedeliveries records the messages delivered again after being abandoned or after their lock expired.
+func (s *Subscription) recordRedeliveries(msgs []*azservicebus.ReceivedMessage) {
+	if s.stats == nil {
+		return
+	}
+	for _, m := range msgs {
+		if m.DeliveryCount > 1 {
+			s.stats.RecordRetry(s.topic)
+		}
+	}
+}
+
 // CompleteMessage marks a message as complete.
 func (s *Subscription) CompleteMessage(ctx context.Context, receiver Receiver, m *azservicebus.ReceivedMessage) {
 	s.logger.Debugf("Completing message %s on %s", m.MessageID, s.entity)
@@ -497,3 +595,61 @@ func (s *Subscription) removeActiveMessage(messageID string, messageKey int64) {
 	delete(s.activeMessages, messageKey)
 	s.mu.Unlock()
 }
+
+// Subscriptions is the set of the active subscriptions of a component, so they can be drained together.
+// The zero value is ready to use.
+type Subscriptions struct {
+	lock    sync.Mutex
+	subs    map[*Subscription]struct{}
+	drained bool
+}
+
+// Add a subscription to the set.
+// Subscriptions added once the set is drained are closed right away.
+func (s *Subscriptions) Add(sub *Subscription) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.drained {
+		sub.Drain(context.Background())
+		return
+	}
+	if s.subs == nil {
+		s.subs = make(map[*Subscription]struct{})
+	}
+	s.subs[sub] = struct{}{}
+}
+
+// Remove a subscription from the set.
+func (s *Subscriptions) Remove(sub *Subscription) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.subs, sub)
+}
+
+// Drain all the subscriptions at once, until ctx is done.
+func (s *Subscriptions) Drain(ctx context.Context) error {
+	s.lock.Lock()
+	s.drained = true
+	subs := make([]*Subscription, 0, len(s.subs))
+	for sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.lock.Unlock()
+
+	errs := make(chan error, len(subs))
+	for _, sub := range subs {
+		go func(sub *Subscription) {
+			errs <- sub.Drain(ctx)
+		}(sub)
+	}
+
+	var err error
+	for range subs {
+		if subErr := <-errs; subErr != nil && err == nil {
+			err = subErr
+		}
+	}
+	return err
+}
@@ -15,7 +15,15 @@ package servicebus
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	azservicebus "github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/ptr"
@@ -92,3 +100,154 @@ func TestNewSubscription(t *testing.T) {
 		})
 	}
 }
+
+type fakeReceiver struct {
+	msgs      chan *azservicebus.ReceivedMessage
+	completed atomic.Int32
+	abandoned atomic.Int32
+}
+
+func (f *fakeReceiver) ReceiveMessages(ctx context.Context, maxMessages int, options *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error) {
+	select {
+	case msg := <-f.msgs:
+		return []*azservicebus.ReceivedMessage{msg}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeReceiver) CompleteMessage(ctx context.Context, m *azservicebus.ReceivedMessage, opts *azservicebus.CompleteMessageOptions) error {
+	f.completed.Add(1)
+	return nil
+}
+
+func (f *fakeReceiver) AbandonMessage(ctx context.Context, m *azservicebus.ReceivedMessage, opts *azservicebus.AbandonMessageOptions) error {
+	f.abandoned.Add(1)
+	return nil
+}
+
+func (f *fakeReceiver) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestSubscriptionDrain(t *testing.T) {
+	sub := NewSubscription(
+		context.Background(), SubsriptionOptions{
+			MaxActiveMessages: 10,
+			TimeoutInSec:      1,
+			Entity:            "test",
+		},
+		logger.NewLogger("test"),
+	)
+
+	receiver := &fakeReceiver{msgs: make(chan *azservicebus.ReceivedMessage, 1)}
+	receiver.msgs <- &azservicebus.ReceivedMessage{MessageID: "1", SequenceNumber: ptr.Of[int64](1)}
+
+	handling := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(ctx context.Context, msgs []*azservicebus.ReceivedMessage) ([]HandlerResponseItem, error) {
+		close(handling)
+		<-release
+		return nil, nil
+	}
+
+	received := make(chan error, 1)
+	go func() {
+		received <- sub.ReceiveBlocking(handler, receiver, nil, ReceiveOptions{})
+	}()
+	<-handling
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- sub.Drain(context.Background())
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("drain returned with a message in flight")
+	case <-received:
+		t.Fatal("receiver closed with a message in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.True(t, sub.IsDraining())
+
+	close(release)
+	select {
+	case err := <-drained:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not return")
+	}
+	select {
+	case err := <-received:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("receiver did not stop")
+	}
+
+	assert.Equal(t, int32(1), receiver.completed.Load())
+	assert.Equal(t, int32(0), receiver.abandoned.Load())
+}
+
+type fakeStatsRecorder struct {
+	lock        sync.Mutex
+	retries     []string
+	deadLetters []string
+}
+
+func (f *fakeStatsRecorder) RecordRetry(topic string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.retries = append(f.retries, topic)
+}
+
+func (f *fakeStatsRecorder) RecordDeadLetter(topic string, deadLetterTopic string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.deadLetters = append(f.deadLetters, topic+" -> "+deadLetterTopic)
+}
+
+func TestSubscriptionStats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stats := &fakeStatsRecorder{}
+	sub := NewSubscription(
+		ctx, SubsriptionOptions{
+			MaxActiveMessages: 10,
+			TimeoutInSec:      1,
+			Entity:            "test",
+			Topic:             "orders",
+			Stats:             stats,
+			DeadLetterEntity:  "orders/$DeadLetterQueue",
+			MaxDeliveryCount:  ptr.Of[int32](2),
+		},
+		logger.NewLogger("test"),
+	)
+
+	receiver := &fakeReceiver{msgs: make(chan *azservicebus.ReceivedMessage, 2)}
+	receiver.msgs <- &azservicebus.ReceivedMessage{MessageID: "1", SequenceNumber: ptr.Of[int64](1), DeliveryCount: 1}
+	receiver.msgs <- &azservicebus.ReceivedMessage{MessageID: "2", SequenceNumber: ptr.Of[int64](2), DeliveryCount: 2}
+
+	handler := func(ctx context.Context, msgs []*azservicebus.ReceivedMessage) ([]HandlerResponseItem, error) {
+		return nil, errors.New("handler error")
+	}
+
+	received := make(chan error, 1)
+	go func() {
+		received <- sub.ReceiveBlocking(handler, receiver, nil, ReceiveOptions{})
+	}()
+
+	assert.Eventually(t, func() bool {
+		stats.lock.Lock()
+		defer stats.lock.Unlock()
+		return receiver.abandoned.Load() == 2 && len(stats.deadLetters) > 0
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-received
+
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	assert.Equal(t, []string{"orders"}, stats.retries)
+	assert.Equal(t, []string{"orders -> orders/$DeadLetterQueue"}, stats.deadLetters)
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bulkdata streams rows in and out of the database bindings, for their bulk import and export operations.
+package bulkdata
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format of the rows of bulk imports and exports.
+type Format string
+
+const (
+	// FormatCSV is comma-separated values, with a header line listing the columns.
+	FormatCSV Format = "csv"
+	// FormatJSONL is one JSON object per line, keyed by column name.
+	FormatJSONL Format = "jsonl"
+)
+
+// Metadata keys of the bulk operations requests.
+const (
+	FormatKey  = "format"
+	TableKey   = "table"
+	ColumnsKey = "columns"
+)
+
+// ParseFormat returns the format named by s, CSV by default.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSONL, "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("invalid %s: %s. Expected %s or %s", FormatKey, s, FormatCSV, FormatJSONL)
+	}
+}
+
+// ParseColumns parses a comma-separated list of column names.
+func ParseColumns(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	columns := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			columns = append(columns, p)
+		}
+	}
+	return columns
+}
+
+// Reader reads the rows of a bulk import one at a time.
+// It implements the CopyFromSource interface of pgx.
+type Reader struct {
+	columns []string
+	next    func() ([]any, error)
+	values  []any
+	err     error
+}
+
+// NewReader returns a Reader for the rows encoded in r.
+// If columns is empty, they are read from the CSV header, or from the keys of the first JSON object, sorted.
+// Empty CSV fields and JSON nulls are read as NULL values.
+func NewReader(r io.Reader, format Format, columns []string) (*Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r, columns)
+	case FormatJSONL:
+		return newJSONLReader(r, columns)
+	default:
+		return nil, fmt.Errorf("invalid %s: %s", FormatKey, format)
+	}
+}
+
+func newCSVReader(r io.Reader, columns []string) (*Reader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("missing CSV header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	if len(columns) == 0 {
+		columns = make([]string, len(header))
+		for i, c := range header {
+			columns[i] = strings.TrimSpace(c)
+		}
+	} else if len(columns) != len(header) {
+		return nil, fmt.Errorf("the CSV header has %d columns, expected %d", len(header), len(columns))
+	}
+
+	return &Reader{
+		columns: columns,
+		next: func() ([]any, error) {
+			record, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			row := make([]any, len(record))
+			for i, field := range record {
+				if field != "" {
+					row[i] = field
+				}
+			}
+			return row, nil
+		},
+	}, nil
+}
+
+func newJSONLReader(r io.Reader, columns []string) (*Reader, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	decode := func() (map[string]any, error) {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("error decoding JSON row: %w", err)
+		}
+		if obj == nil {
+			return nil, errors.New("error decoding JSON row: expected an object")
+		}
+		return obj, nil
+	}
+
+	var first map[string]any
+	if len(columns) == 0 {
+		var err error
+		first, err = decode()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no rows to infer the columns from")
+		}
+		if err != nil {
+			return nil, err
+		}
+		for c := range first {
+			columns = append(columns, c)
+		}
+		sort.Strings(columns)
+	}
+
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		index[c] = i
+	}
+
+	return &Reader{
+		columns: columns,
+		next: func() ([]any, error) {
+			obj := first
+			first = nil
+			if obj == nil {
+				var err error
+				obj, err = decode()
+				if err != nil {
+					return nil, err
+				}
+			}
+			row := make([]any, len(columns))
+			for k, v := range obj {
+				i, ok := index[k]
+				if !ok {
+					return nil, fmt.Errorf("unknown column %s", k)
+				}
+				row[i] = jsonValue(v)
+			}
+			return row, nil
+		},
+	}, nil
+}
+
+// jsonValue converts a decoded JSON value to a value of a column.
+// Numbers keep their representation, and objects and arrays are encoded back to JSON.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// Columns returns the names of the columns of the rows.
+func (r *Reader) Columns() []string {
+	return r.columns
+}
+
+// Next reads the next row, returning false at the end of the input or on error.
+func (r *Reader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	r.values, r.err = r.next()
+	if errors.Is(r.err, io.EOF) {
+		r.err = nil
+		r.values = nil
+		r.next = func() ([]any, error) { return nil, io.EOF }
+		return false
+	}
+	return r.err == nil
+}
+
+// Values returns the values of the current row: strings, or nil for NULL.
+func (r *Reader) Values() ([]any, error) {
+	return r.values, nil
+}
+
+// Err returns the error which stopped Next, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// CSV streams the rows of r as CSV, without header.
+// Values are always quoted, and NULL values are written as the unquoted NULL word.
+// A read error of r is returned by the Read method of the returned reader.
+func CSV(r *Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		w := bufio.NewWriter(pw)
+		for r.Next() {
+			values, _ := r.Values()
+			for i, v := range values {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				if v == nil {
+					w.WriteString("NULL")
+					continue
+				}
+				w.WriteByte('"')
+				w.WriteString(strings.ReplaceAll(fmt.Sprint(v), `"`, `""`))
+				w.WriteByte('"')
+			}
+			if _, err := w.WriteString("\n"); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(w.Flush())
+	}()
+	return pr
+}
+
+// Writer writes the rows of a bulk export.
+type Writer struct {
+	format  Format
+	columns []string
+	csv     *csv.Writer
+	enc     *json.Encoder
+	record  []string
+	rows    int
+}
+
+// NewWriter returns a Writer encoding rows with the given columns to w.
+// With the CSV format, the header is written right away.
+func NewWriter(w io.Writer, format Format, columns []string) (*Writer, error) {
+	bw := &Writer{format: format, columns: columns}
+	switch format {
+	case FormatCSV:
+		bw.csv = csv.NewWriter(w)
+		bw.record = make([]string, len(columns))
+		if err := bw.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		bw.enc = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("invalid %s: %s", FormatKey, format)
+	}
+	return bw, nil
+}
+
+// Write a row, with a value for each column.
+func (w *Writer) Write(values []any) error {
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("got %d values for %d columns", len(values), len(w.columns))
+	}
+	w.rows++
+
+	if w.csv != nil {
+		for i, v := range values {
+			w.record[i] = csvValue(v)
+		}
+		return w.csv.Write(w.record)
+	}
+
+	obj := make(map[string]any, len(values))
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		obj[w.columns[i]] = v
+	}
+	return w.enc.Encode(obj)
+}
+
+// Rows returns the number of rows written.
+func (w *Writer) Rows() int {
+	return w.rows
+}
+
+// Flush writes any buffered data.
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
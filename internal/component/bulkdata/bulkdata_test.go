@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkdata
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, r *Reader) [][]any {
+	t.Helper()
+
+	var rows [][]any
+	for r.Next() {
+		values, err := r.Values()
+		require.NoError(t, err)
+		rows = append(rows, values)
+	}
+	require.NoError(t, r.Err())
+	return rows
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, f)
+
+	f, err = ParseFormat("JSONL")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSONL, f)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestParseColumns(t *testing.T) {
+	assert.Nil(t, ParseColumns(" "))
+	assert.Equal(t, []string{"id", "name"}, ParseColumns("id, name,"))
+}
+
+func TestReader(t *testing.T) {
+	t.Run("csv with header", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader("id,name\n1,foo\n2,\n"), FormatCSV, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name"}, r.Columns())
+		assert.Equal(t, [][]any{{"1", "foo"}, {"2", nil}}, readAll(t, r))
+	})
+
+	t.Run("csv with columns", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader("a,b\n1,foo\n"), FormatCSV, []string{"id", "name"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name"}, r.Columns())
+		assert.Equal(t, [][]any{{"1", "foo"}}, readAll(t, r))
+	})
+
+	t.Run("csv header mismatch", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("a\n1\n"), FormatCSV, []string{"id", "name"})
+		assert.Error(t, err)
+	})
+
+	t.Run("csv without header", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader(""), FormatCSV, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		in := `{"name":"foo","id":1,"tags":["a"]}` + "\n" + `{"id":2.50,"name":null,"ok":true}`
+		r, err := NewReader(strings.NewReader(in), FormatJSONL, []string{"id", "name", "tags", "ok"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]any{
+			{"1", "foo", `["a"]`, nil},
+			{"2.50", nil, nil, "true"},
+		}, readAll(t, r))
+	})
+
+	t.Run("jsonl infers the columns", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader(`{"name":"foo","id":1}`+"\n"+`{"id":2}`), FormatJSONL, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name"}, r.Columns())
+		assert.Equal(t, [][]any{{"1", "foo"}, {"2", nil}}, readAll(t, r))
+	})
+
+	t.Run("jsonl unknown column", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader(`{"id":1}`+"\n"+`{"other":2}`), FormatJSONL, nil)
+		require.NoError(t, err)
+		assert.True(t, r.Next())
+		assert.False(t, r.Next())
+		assert.ErrorContains(t, r.Err(), "unknown column other")
+	})
+
+	t.Run("jsonl invalid row", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader(`{"id":1}`+"\n"+`[1]`), FormatJSONL, []string{"id"})
+		require.NoError(t, err)
+		assert.True(t, r.Next())
+		assert.False(t, r.Next())
+		assert.Error(t, r.Err())
+	})
+}
+
+func TestCSV(t *testing.T) {
+	r, err := NewReader(strings.NewReader(`{"id":1,"name":"say \"hi\""}`+"\n"+`{"id":2,"name":null}`), FormatJSONL, nil)
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(CSV(r))
+	require.NoError(t, err)
+	assert.Equal(t, "\"1\",\"say \"\"hi\"\"\"\n\"2\",NULL\n", string(out))
+
+	t.Run("read error", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader(`{"id":1}`+"\n"+`oops`), FormatJSONL, nil)
+		require.NoError(t, err)
+
+		_, err = io.ReadAll(CSV(r))
+		assert.Error(t, err)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, FormatCSV, []string{"id", "name", "ts"})
+		require.NoError(t, err)
+		require.NoError(t, w.Write([]any{int64(1), []byte("a,b"), ts}))
+		require.NoError(t, w.Write([]any{int64(2), nil, nil}))
+		require.NoError(t, w.Flush())
+
+		assert.Equal(t, "id,name,ts\n1,\"a,b\",2023-01-02T03:04:05Z\n2,,\n", buf.String())
+		assert.Equal(t, 2, w.Rows())
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, FormatJSONL, []string{"id", "name"})
+		require.NoError(t, err)
+		require.NoError(t, w.Write([]any{int64(1), []byte("foo")}))
+		require.NoError(t, w.Flush())
+
+		assert.Equal(t, `{"id":1,"name":"foo"}`+"\n", buf.String())
+	})
+
+	t.Run("values mismatch", func(t *testing.T) {
+		w, err := NewWriter(io.Discard, FormatJSONL, []string{"id"})
+		require.NoError(t, err)
+		assert.Error(t, w.Write([]any{1, 2}))
+	})
+}
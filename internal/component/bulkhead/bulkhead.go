@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+// ErrBulkheadFull is returned without calling the component when no slot frees up within the maximum wait.
+var ErrBulkheadFull = errors.New("too many concurrent operations")
+
+// Metadata contains the bulkhead settings of a component instance.
+type Metadata struct {
+	// Maximum number of operations running at the same time; 0 disables the bulkhead.
+	MaxConcurrentOperations int `mapstructure:"maxConcurrentOperations"`
+	// Maximum time an operation waits for a slot; 0 waits until its context is done.
+	MaxConcurrentOperationsWait time.Duration `mapstructure:"maxConcurrentOperationsWait"`
+}
+
+// ParseMetadata reads the bulkhead settings from the component metadata.
+func ParseMetadata(properties map[string]string) (Metadata, error) {
+	var m Metadata
+	err := metadata.DecodeMetadata(properties, &m)
+	if err != nil {
+		return m, err
+	}
+	if m.MaxConcurrentOperations < 0 {
+		return m, errors.New("maxConcurrentOperations must not be negative")
+	}
+	if m.MaxConcurrentOperationsWait < 0 {
+		return m, errors.New("maxConcurrentOperationsWait must not be negative")
+	}
+	return m, nil
+}
+
+// Bulkhead limits the number of operations running at the same time against a component.
+type Bulkhead struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// New returns the Bulkhead for the given settings, or nil if it is disabled.
+func New(m Metadata) *Bulkhead {
+	if m.MaxConcurrentOperations == 0 {
+		return nil
+	}
+
+	return &Bulkhead{
+		slots: make(chan struct{}, m.MaxConcurrentOperations),
+		wait:  m.MaxConcurrentOperationsWait,
+	}
+}
+
+// InUse returns the number of operations running.
+func (b *Bulkhead) InUse() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.slots)
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	var timeout <-chan time.Time
+	if b.wait > 0 {
+		timer := time.NewTimer(b.wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return fmt.Errorf("%w: %d running", ErrBulkheadFull, cap(b.slots))
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
+
+// Run runs op once a slot of the bulkhead b is free. A nil bulkhead runs op right away.
+func Run[T any](ctx context.Context, b *Bulkhead, op func(ctx context.Context) (T, error)) (T, error) {
+	if b == nil {
+		return op(ctx)
+	}
+
+	if err := b.acquire(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	defer b.release()
+
+	return op(ctx)
+}
+
+// RunOnce runs op, which returns only an error, with the bulkhead b.
+func RunOnce(ctx context.Context, b *Bulkhead, op func(ctx context.Context) error) error {
+	_, err := Run(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	})
+	return err
+}
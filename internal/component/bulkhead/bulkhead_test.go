@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkhead
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadata(t *testing.T) {
+	m, err := ParseMetadata(map[string]string{
+		"maxConcurrentOperations":     "4",
+		"maxConcurrentOperationsWait": "2s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, m.MaxConcurrentOperations)
+	assert.Equal(t, 2*time.Second, m.MaxConcurrentOperationsWait)
+
+	m, err = ParseMetadata(map[string]string{})
+	require.NoError(t, err)
+	assert.Nil(t, New(m))
+
+	_, err = ParseMetadata(map[string]string{"maxConcurrentOperations": "-1"})
+	assert.Error(t, err)
+}
+
+// hold runs an operation in the background on b until the returned function is called.
+func hold(t *testing.T, b *Bulkhead) func() {
+	t.Helper()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = RunOnce(context.Background(), b, func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	return func() {
+		close(release)
+		<-done
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("nil bulkhead", func(t *testing.T) {
+		res, err := Run(context.Background(), nil, func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, res)
+	})
+
+	t.Run("fails once the wait is over", func(t *testing.T) {
+		b := New(Metadata{MaxConcurrentOperations: 1, MaxConcurrentOperationsWait: 10 * time.Millisecond})
+		release := hold(t, b)
+		defer release()
+		assert.Equal(t, 1, b.InUse())
+
+		calls := 0
+		err := RunOnce(context.Background(), b, func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		require.ErrorIs(t, err, ErrBulkheadFull)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("waits for a free slot", func(t *testing.T) {
+		b := New(Metadata{MaxConcurrentOperations: 1})
+		release := hold(t, b)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- RunOnce(context.Background(), b, func(ctx context.Context) error {
+				return nil
+			})
+		}()
+		select {
+		case <-done:
+			t.Fatal("operation ran without a free slot")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		release()
+		require.NoError(t, <-done)
+		assert.Equal(t, 0, b.InUse())
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		b := New(Metadata{MaxConcurrentOperations: 1})
+		release := hold(t, b)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := RunOnce(ctx, b, func(ctx context.Context) error {
+			return nil
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/cenkalti/backoff/v4"
 
+	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/retry"
 )
 
@@ -76,11 +77,17 @@ func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 					return nil
 				}
 
+				// When draining, leave the message uncommitted so that it is delivered to the next owner of the partition
+				if !consumer.k.inFlight.Begin() {
+					return nil
+				}
+
 				if consumer.k.consumeRetryEnabled {
 					if err := retry.NotifyRecover(func() error {
 						return consumer.doCallback(session, message)
 					}, b, func(err error, d time.Duration) {
 						consumer.k.logger.Warnf("Error processing Kafka message: %s/%d/%d [key=%s]. Error: %v. Retrying...", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
+						consumer.k.recordRetries(message.Topic, 1)
 					}, func() {
 						consumer.k.logger.Infof("Successfully processed Kafka message after it previously failed: %s/%d/%d [key=%s]", message.Topic, message.Partition, message.Offset, asBase64String(message.Key))
 					}); err != nil {
@@ -92,6 +99,7 @@ func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 						consumer.k.logger.Errorf("Error processing Kafka message: %s/%d/%d [key=%s]. Error: %v.", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
 					}
 				}
+				consumer.k.inFlight.End()
 			// Should return when `session.Context()` is done.
 			// If not, will raise `ErrRebalanceInProgress` or `read tcp <ip>:<port>: i/o timeout` when kafka rebalance. see:
 			// https://github.com/Shopify/sarama/issues/1192
@@ -107,11 +115,17 @@ func (consumer *consumer) flushBulkMessages(claim sarama.ConsumerGroupClaim,
 	handler BulkEventHandler, b backoff.BackOff,
 ) error {
 	if len(messages) > 0 {
+		if !consumer.k.inFlight.Begin() {
+			return nil
+		}
+		defer consumer.k.inFlight.End()
+
 		if consumer.k.consumeRetryEnabled {
 			if err := retry.NotifyRecover(func() error {
 				return consumer.doBulkCallback(session, messages, handler, claim.Topic())
 			}, b, func(err error, d time.Duration) {
 				consumer.k.logger.Warnf("Error processing Kafka bulk messages: %s. Error: %v. Retrying...", claim.Topic(), err)
+				consumer.k.recordRetries(claim.Topic(), len(messages))
 			}, func() {
 				consumer.k.logger.Infof("Successfully processed Kafka message after it previously failed: %s", claim.Topic())
 			}); err != nil {
@@ -268,6 +282,10 @@ func (k *Kafka) Subscribe(ctx context.Context) error {
 		return nil
 	}
 
+	if err := k.ensureTopics(topics...); err != nil {
+		return err
+	}
+
 	cg, err := sarama.NewConsumerGroup(k.brokers, k.consumerGroup, k.config)
 	if err != nil {
 		return err
@@ -328,6 +346,37 @@ func (k *Kafka) Subscribe(ctx context.Context) error {
 	return nil
 }
 
+// SetStatsRecorder records the messages handled again after a failure, when consumeRetryEnabled is set.
+func (k *Kafka) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	k.stats = recorder
+}
+
+func (k *Kafka) recordRetries(topic string, n int) {
+	if k.stats == nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		k.stats.RecordRetry(topic)
+	}
+}
+
+// Drain pauses the consumption of all the partitions, waits for the messages being handled until ctx is done, then closes the consumer group.
+// The offsets of the handled messages are committed when the consumer group is closed; the other messages are delivered again to the next consumer.
+func (k *Kafka) Drain(ctx context.Context) error {
+	k.subscribeLock.Lock()
+	defer k.subscribeLock.Unlock()
+
+	if k.cg == nil {
+		return nil
+	}
+
+	k.cg.PauseAll()
+	err := k.inFlight.Drain(ctx)
+	k.closeSubscriptionResources()
+
+	return err
+}
+
 // Close down consumer group resources, refresh once.
 func (k *Kafka) closeSubscriptionResources() {
 	if k.cg != nil {
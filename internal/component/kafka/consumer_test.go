@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/kit/logger"
+)
+
+type fakeConsumerGroup struct {
+	paused atomic.Bool
+	closed atomic.Bool
+}
+
+func (f *fakeConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeConsumerGroup) Errors() <-chan error                 { return nil }
+func (f *fakeConsumerGroup) Close() error                         { f.closed.Store(true); return nil }
+func (f *fakeConsumerGroup) Pause(partitions map[string][]int32)  {}
+func (f *fakeConsumerGroup) Resume(partitions map[string][]int32) {}
+func (f *fakeConsumerGroup) PauseAll()                            { f.paused.Store(true) }
+func (f *fakeConsumerGroup) ResumeAll()                           { f.paused.Store(false) }
+
+func newDrainTestKafka(cg sarama.ConsumerGroup) *Kafka {
+	running := make(chan struct{})
+	close(running)
+	k := &Kafka{
+		logger: logger.NewLogger("kafka_test"),
+		cg:     cg,
+		cancel: func() {},
+	}
+	k.consumer = consumer{k: k, ready: make(chan bool), running: running}
+	// Subscribe returns once the consumer group session is set up
+	_ = k.consumer.Setup(nil)
+	return k
+}
+
+func TestDrain(t *testing.T) {
+	t.Run("waits for in-flight messages", func(t *testing.T) {
+		cg := &fakeConsumerGroup{}
+		k := newDrainTestKafka(cg)
+		require.True(t, k.inFlight.Begin())
+
+		done := make(chan error, 1)
+		go func() {
+			done <- k.Drain(context.Background())
+		}()
+
+		<-k.inFlight.Draining()
+		select {
+		case <-done:
+			t.Fatal("drain returned with a message in flight")
+		case <-time.After(50 * time.Millisecond):
+		}
+		assert.True(t, cg.paused.Load())
+		assert.False(t, cg.closed.Load())
+
+		k.inFlight.End()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("drain did not return")
+		}
+		assert.True(t, cg.closed.Load())
+		assert.False(t, k.inFlight.Begin())
+	})
+
+	t.Run("closes the consumer group at the deadline", func(t *testing.T) {
+		cg := &fakeConsumerGroup{}
+		k := newDrainTestKafka(cg)
+		require.True(t, k.inFlight.Begin())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := k.Drain(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, cg.closed.Load())
+	})
+
+	t.Run("without subscriptions", func(t *testing.T) {
+		k := &Kafka{logger: logger.NewLogger("kafka_test")}
+		require.NoError(t, k.Drain(context.Background()))
+	})
+}
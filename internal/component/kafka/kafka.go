@@ -41,6 +41,8 @@ type Kafka struct {
 	config          *sarama.Config
 	subscribeTopics TopicHandlerConfig
 	subscribeLock   sync.Mutex
+	inFlight        pubsub.InFlight
+	stats           pubsub.StatsRecorder
 
 	backOffConfig retry.Config
 
@@ -49,6 +51,12 @@ type Kafka struct {
 	DefaultConsumeRetryEnabled bool
 	consumeRetryEnabled        bool
 	consumeRetryInterval       time.Duration
+
+	// Topics created when entity management is enabled.
+	topics        topicsMetadata
+	topicCreator  topicCreator
+	ensuredTopics map[string]struct{}
+	topicsLock    sync.Mutex
 }
 
 func NewKafka(logger logger.Logger) *Kafka {
@@ -56,6 +64,7 @@ func NewKafka(logger logger.Logger) *Kafka {
 		logger:          logger,
 		subscribeTopics: make(TopicHandlerConfig),
 		subscribeLock:   sync.Mutex{},
+		ensuredTopics:   make(map[string]struct{}),
 	}
 }
 
@@ -84,6 +93,20 @@ func (k *Kafka) Init(metadata map[string]string) error {
 		config.ClientID = meta.ClientID
 	}
 
+	// With static membership, a restarted consumer that rejoins within the session timeout gets its previous
+	// assignments back without triggering a rebalance of the group.
+	config.Consumer.Group.InstanceId = meta.GroupInstanceID
+	if meta.RebalanceStrategy != nil && meta.RebalanceStrategy != sarama.BalanceStrategyRange {
+		// Range stays as a fallback so that the group can still form while members are rolled to the new strategy.
+		config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{meta.RebalanceStrategy, sarama.BalanceStrategyRange}
+	}
+	if meta.SessionTimeout > 0 {
+		config.Consumer.Group.Session.Timeout = meta.SessionTimeout
+	}
+	if meta.HeartbeatInterval > 0 {
+		config.Consumer.Group.Heartbeat.Interval = meta.HeartbeatInterval
+	}
+
 	err = updateTLSConfig(config, meta)
 	if err != nil {
 		return err
@@ -128,6 +151,11 @@ func (k *Kafka) Init(metadata map[string]string) error {
 	k.consumeRetryEnabled = meta.ConsumeRetryEnabled
 	k.consumeRetryInterval = meta.ConsumeRetryInterval
 
+	k.topics, err = getTopicsMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
 	k.logger.Debug("Kafka message bus initialization complete")
 
 	return nil
@@ -141,6 +169,13 @@ func (k *Kafka) Close() (err error) {
 		k.producer = nil
 	}
 
+	k.topicsLock.Lock()
+	if k.topicCreator != nil {
+		k.topicCreator.Close()
+		k.topicCreator = nil
+	}
+	k.topicsLock.Unlock()
+
 	return err
 }
 
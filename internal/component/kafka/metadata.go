@@ -36,6 +36,12 @@ const (
 	oidcAuthType         = "oidc"
 	mtlsAuthType         = "mtls"
 	noAuthType           = "none"
+
+	groupInstanceID                = "consumerGroupInstanceID"
+	consumerGroupRebalanceStrategy = "consumerGroupRebalanceStrategy"
+	consumerGroupSessionTimeout    = "sessionTimeout"
+	consumerGroupHeartbeatInterval = "heartbeatInterval"
+	cooperativeStickyStrategyName  = "cooperative-sticky"
 )
 
 type kafkaMetadata struct {
@@ -60,6 +66,10 @@ type kafkaMetadata struct {
 	ConsumeRetryEnabled  bool
 	ConsumeRetryInterval time.Duration
 	Version              sarama.KafkaVersion
+	GroupInstanceID      string
+	RebalanceStrategy    sarama.BalanceStrategy
+	SessionTimeout       time.Duration
+	HeartbeatInterval    time.Duration
 }
 
 // upgradeMetadata updates metadata properties based on deprecated usage.
@@ -270,15 +280,60 @@ func (k *Kafka) getKafkaMetadata(metadata map[string]string) (*kafkaMetadata, er
 		meta.ConsumeRetryInterval = durationVal
 	}
 
+	if val, ok := metadata[groupInstanceID]; ok && val != "" {
+		meta.GroupInstanceID = val
+		k.logger.Debugf("Using %s as consumer group instance ID for static membership", meta.GroupInstanceID)
+	}
+
 	if val, ok := metadata["version"]; ok && val != "" {
 		version, err := sarama.ParseKafkaVersion(val)
 		if err != nil {
 			return nil, errors.New("kafka error: invalid kafka version")
 		}
 		meta.Version = version
+	} else if meta.GroupInstanceID != "" {
+		// Static membership requires the JoinGroup request version introduced in Kafka 2.3.
+		meta.Version = sarama.V2_3_0_0 //nolint:nosnakecase
 	} else {
 		meta.Version = sarama.V2_0_0_0 //nolint:nosnakecase
 	}
 
+	if meta.GroupInstanceID != "" && !meta.Version.IsAtLeast(sarama.V2_3_0_0) { //nolint:nosnakecase
+		return nil, fmt.Errorf("kafka error: '%s' requires kafka version 2.3.0 or later, got %s", groupInstanceID, meta.Version)
+	}
+
+	if val, ok := metadata[consumerGroupRebalanceStrategy]; ok && val != "" {
+		switch strings.ToLower(val) {
+		case sarama.RangeBalanceStrategyName:
+			meta.RebalanceStrategy = sarama.BalanceStrategyRange
+		case sarama.RoundRobinBalanceStrategyName:
+			meta.RebalanceStrategy = sarama.BalanceStrategyRoundRobin
+		case sarama.StickyBalanceStrategyName:
+			meta.RebalanceStrategy = sarama.BalanceStrategySticky
+		case cooperativeStickyStrategyName:
+			// The consumer group client revokes all the partitions on every rebalance (eager protocol), so the
+			// incremental cooperative protocol cannot be honored; sticky gives the same assignments.
+			return nil, fmt.Errorf("kafka error: '%s' rebalance strategy is not supported by the kafka client, use '%s' together with '%s' to limit rebalances", cooperativeStickyStrategyName, sarama.StickyBalanceStrategyName, groupInstanceID)
+		default:
+			return nil, fmt.Errorf("kafka error: invalid value for '%s' attribute: %s", consumerGroupRebalanceStrategy, val)
+		}
+	}
+
+	if val, ok := metadata[consumerGroupSessionTimeout]; ok && val != "" {
+		durationVal, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("kafka error: invalid value for '%s' attribute: %w", consumerGroupSessionTimeout, err)
+		}
+		meta.SessionTimeout = durationVal
+	}
+
+	if val, ok := metadata[consumerGroupHeartbeatInterval]; ok && val != "" {
+		durationVal, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("kafka error: invalid value for '%s' attribute: %w", consumerGroupHeartbeatInterval, err)
+		}
+		meta.HeartbeatInterval = durationVal
+	}
+
 	return &meta, nil
 }
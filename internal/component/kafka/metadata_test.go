@@ -319,3 +319,54 @@ func TestTls(t *testing.T) {
 		require.Equal(t, "kafka error: invalid ca certificate", err.Error())
 	})
 }
+
+func TestConsumerGroupMembership(t *testing.T) {
+	k := getKafka()
+
+	t.Run("static membership defaults to kafka 2.3", func(t *testing.T) {
+		m := getBaseMetadata()
+		m[groupInstanceID] = "consumer-0"
+		meta, err := k.getKafkaMetadata(m)
+		require.NoError(t, err)
+		require.Equal(t, "consumer-0", meta.GroupInstanceID)
+		require.Equal(t, sarama.V2_3_0_0, meta.Version) //nolint:nosnakecase
+	})
+
+	t.Run("static membership with an older kafka version", func(t *testing.T) {
+		m := getBaseMetadata()
+		m[groupInstanceID] = "consumer-0"
+		m["version"] = "2.1.0"
+		_, err := k.getKafkaMetadata(m)
+		require.ErrorContains(t, err, "requires kafka version 2.3.0")
+	})
+
+	t.Run("rebalance strategy", func(t *testing.T) {
+		m := getBaseMetadata()
+		m[consumerGroupRebalanceStrategy] = "Sticky"
+		m[consumerGroupSessionTimeout] = "45s"
+		m[consumerGroupHeartbeatInterval] = "5s"
+		meta, err := k.getKafkaMetadata(m)
+		require.NoError(t, err)
+		require.Equal(t, sarama.BalanceStrategySticky, meta.RebalanceStrategy)
+		require.Equal(t, 45*time.Second, meta.SessionTimeout)
+		require.Equal(t, 5*time.Second, meta.HeartbeatInterval)
+	})
+
+	t.Run("unsupported rebalance strategies", func(t *testing.T) {
+		m := getBaseMetadata()
+		m[consumerGroupRebalanceStrategy] = cooperativeStickyStrategyName
+		_, err := k.getKafkaMetadata(m)
+		require.ErrorContains(t, err, "not supported")
+
+		m[consumerGroupRebalanceStrategy] = "fair"
+		_, err = k.getKafkaMetadata(m)
+		require.ErrorContains(t, err, "invalid value")
+	})
+
+	t.Run("invalid session timeout", func(t *testing.T) {
+		m := getBaseMetadata()
+		m[consumerGroupSessionTimeout] = "45"
+		_, err := k.getKafkaMetadata(m)
+		require.ErrorContains(t, err, consumerGroupSessionTimeout)
+	})
+}
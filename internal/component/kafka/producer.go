@@ -19,6 +19,7 @@ import (
 
 	"github.com/Shopify/sarama"
 
+	"github.com/dapr/components-contrib/internal/baggage"
 	"github.com/dapr/components-contrib/pubsub"
 )
 
@@ -41,18 +42,25 @@ func getSyncProducer(config sarama.Config, brokers []string, maxMessageBytes int
 }
 
 // Publish message to Kafka cluster.
-func (k *Kafka) Publish(_ context.Context, topic string, data []byte, metadata map[string]string) error {
+func (k *Kafka) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
 	if k.producer == nil {
 		return errors.New("component is closed")
 	}
 	// k.logger.Debugf("Publishing topic %v with data: %v", topic, string(data))
 	k.logger.Debugf("Publishing on topic %v", topic)
 
+	if err := k.ensureTopics(topic); err != nil {
+		return err
+	}
+
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
 		Value: sarama.ByteEncoder(data),
 	}
 
+	// Propagate the baggage of the request in the headers
+	metadata = baggage.Metadata(ctx, metadata)
+
 	for name, value := range metadata {
 		if name == key {
 			msg.Key = sarama.StringEncoder(value)
@@ -78,13 +86,20 @@ func (k *Kafka) Publish(_ context.Context, topic string, data []byte, metadata m
 	return nil
 }
 
-func (k *Kafka) BulkPublish(_ context.Context, topic string, entries []pubsub.BulkMessageEntry, metadata map[string]string) (pubsub.BulkPublishResponse, error) {
+func (k *Kafka) BulkPublish(ctx context.Context, topic string, entries []pubsub.BulkMessageEntry, metadata map[string]string) (pubsub.BulkPublishResponse, error) {
 	if k.producer == nil {
 		err := errors.New("component is closed")
 		return pubsub.NewBulkPublishResponse(entries, err), err
 	}
 	k.logger.Debugf("Bulk Publishing on topic %v", topic)
 
+	if err := k.ensureTopics(topic); err != nil {
+		return pubsub.NewBulkPublishResponse(entries, err), err
+	}
+
+	// Propagate the baggage of the request in the headers
+	metadata = baggage.Metadata(ctx, metadata)
+
 	msgs := []*sarama.ProducerMessage{}
 	for _, entry := range entries {
 		msg := &sarama.ProducerMessage{
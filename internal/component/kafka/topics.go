@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	enableEntityManagement = "enableEntityManagement"
+	topicPartitions        = "topicPartitions"
+	topicReplicationFactor = "topicReplicationFactor"
+	topicRetention         = "topicRetention"
+	topicRetentionBytes    = "topicRetentionBytes"
+
+	defaultTopicPartitions        = 1
+	defaultTopicReplicationFactor = 1
+)
+
+// topicCreator creates topics; implemented by sarama.ClusterAdmin.
+type topicCreator interface {
+	CreateTopic(topic string, detail *sarama.TopicDetail, validateOnly bool) error
+	Close() error
+}
+
+// topicsMetadata configures the topics created by the component when entity management is enabled.
+type topicsMetadata struct {
+	EnableEntityManagement bool
+	Partitions             int32
+	ReplicationFactor      int16
+	// Retention is the retention.ms of the topics; 0 for the default of the broker, negative for no time limit.
+	Retention time.Duration
+	// RetentionBytes is the retention.bytes of the partitions of the topics; 0 for the default of the broker.
+	RetentionBytes int64
+}
+
+// getTopicsMetadata parses the configuration of the created topics.
+func getTopicsMetadata(metadata map[string]string) (topicsMetadata, error) {
+	meta := topicsMetadata{
+		Partitions:        defaultTopicPartitions,
+		ReplicationFactor: defaultTopicReplicationFactor,
+	}
+
+	if val, ok := metadata[enableEntityManagement]; ok && val != "" {
+		boolVal, err := strconv.ParseBool(val)
+		if err != nil {
+			return meta, fmt.Errorf("kafka error: invalid value for '%s' attribute: %w", enableEntityManagement, err)
+		}
+		meta.EnableEntityManagement = boolVal
+	}
+
+	if val, ok := metadata[topicPartitions]; ok && val != "" {
+		intVal, err := strconv.ParseInt(val, 10, 32)
+		if err != nil || intVal < 1 {
+			return meta, fmt.Errorf("kafka error: invalid value for '%s' attribute: must be a positive integer", topicPartitions)
+		}
+		meta.Partitions = int32(intVal)
+	}
+
+	if val, ok := metadata[topicReplicationFactor]; ok && val != "" {
+		intVal, err := strconv.ParseInt(val, 10, 16)
+		if err != nil || intVal < 1 {
+			return meta, fmt.Errorf("kafka error: invalid value for '%s' attribute: must be a positive integer", topicReplicationFactor)
+		}
+		meta.ReplicationFactor = int16(intVal)
+	}
+
+	if val, ok := metadata[topicRetention]; ok && val != "" {
+		if val == "-1" {
+			meta.Retention = -1
+		} else {
+			durationVal, err := time.ParseDuration(val)
+			if err != nil {
+				intVal, err := strconv.ParseInt(val, 10, 64)
+				if err != nil {
+					return meta, fmt.Errorf("kafka error: invalid value for '%s' attribute: %w", topicRetention, err)
+				}
+				durationVal = time.Duration(intVal) * time.Millisecond
+			}
+			meta.Retention = durationVal
+		}
+	}
+
+	if val, ok := metadata[topicRetentionBytes]; ok && val != "" {
+		intVal, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return meta, fmt.Errorf("kafka error: invalid value for '%s' attribute: %w", topicRetentionBytes, err)
+		}
+		meta.RetentionBytes = intVal
+	}
+
+	return meta, nil
+}
+
+// topicDetail returns the configuration of the created topics.
+func (m topicsMetadata) topicDetail() *sarama.TopicDetail {
+	detail := &sarama.TopicDetail{
+		NumPartitions:     m.Partitions,
+		ReplicationFactor: m.ReplicationFactor,
+		ConfigEntries:     map[string]*string{},
+	}
+	if m.Retention != 0 {
+		ms := "-1"
+		if m.Retention > 0 {
+			ms = strconv.FormatInt(m.Retention.Milliseconds(), 10)
+		}
+		detail.ConfigEntries["retention.ms"] = &ms
+	}
+	if m.RetentionBytes != 0 {
+		bytes := strconv.FormatInt(m.RetentionBytes, 10)
+		detail.ConfigEntries["retention.bytes"] = &bytes
+	}
+	return detail
+}
+
+// ensureTopics creates the topics that do not exist with the configuration of the metadata, when entity management is
+// enabled. The configuration of the existing topics is not changed.
+func (k *Kafka) ensureTopics(topics ...string) error {
+	if !k.topics.EnableEntityManagement {
+		return nil
+	}
+
+	k.topicsLock.Lock()
+	defer k.topicsLock.Unlock()
+
+	for _, topic := range topics {
+		if _, ok := k.ensuredTopics[topic]; ok {
+			continue
+		}
+		if k.topicCreator == nil {
+			admin, err := k.newTopicCreator()
+			if err != nil {
+				return fmt.Errorf("kafka error: failed to create the cluster admin: %w", err)
+			}
+			k.topicCreator = admin
+		}
+
+		err := k.topicCreator.CreateTopic(topic, k.topics.topicDetail(), false)
+		var topicErr *sarama.TopicError
+		switch {
+		case err == nil:
+			k.logger.Infof("Created topic %s", topic)
+		case errors.As(err, &topicErr) && topicErr.Err == sarama.ErrTopicAlreadyExists:
+			k.logger.Debugf("Topic %s already exists", topic)
+		default:
+			return fmt.Errorf("kafka error: failed to create topic %s: %w", topic, err)
+		}
+		k.ensuredTopics[topic] = struct{}{}
+	}
+	return nil
+}
+
+func (k *Kafka) newTopicCreator() (topicCreator, error) {
+	return sarama.NewClusterAdmin(k.brokers, k.config)
+}
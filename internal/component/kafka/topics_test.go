@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTopicCreator struct {
+	created map[string]*sarama.TopicDetail
+	err     error
+}
+
+func (f *fakeTopicCreator) CreateTopic(topic string, detail *sarama.TopicDetail, validateOnly bool) error {
+	if f.err != nil {
+		return f.err
+	}
+	if _, ok := f.created[topic]; ok {
+		return &sarama.TopicError{Err: sarama.ErrTopicAlreadyExists}
+	}
+	f.created[topic] = detail
+	return nil
+}
+
+func (f *fakeTopicCreator) Close() error {
+	return nil
+}
+
+func TestGetTopicsMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		meta, err := getTopicsMetadata(map[string]string{})
+		require.NoError(t, err)
+		assert.Equal(t, topicsMetadata{Partitions: 1, ReplicationFactor: 1}, meta)
+		assert.Empty(t, meta.topicDetail().ConfigEntries)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		meta, err := getTopicsMetadata(map[string]string{
+			enableEntityManagement: "true",
+			topicPartitions:        "6",
+			topicReplicationFactor: "3",
+			topicRetention:         "168h",
+			topicRetentionBytes:    "1073741824",
+		})
+		require.NoError(t, err)
+		assert.True(t, meta.EnableEntityManagement)
+		assert.Equal(t, 7*24*time.Hour, meta.Retention)
+
+		detail := meta.topicDetail()
+		assert.Equal(t, int32(6), detail.NumPartitions)
+		assert.Equal(t, int16(3), detail.ReplicationFactor)
+		assert.Equal(t, "604800000", *detail.ConfigEntries["retention.ms"])
+		assert.Equal(t, "1073741824", *detail.ConfigEntries["retention.bytes"])
+	})
+
+	t.Run("retention in milliseconds or unlimited", func(t *testing.T) {
+		meta, err := getTopicsMetadata(map[string]string{topicRetention: "60000"})
+		require.NoError(t, err)
+		assert.Equal(t, "60000", *meta.topicDetail().ConfigEntries["retention.ms"])
+
+		meta, err = getTopicsMetadata(map[string]string{topicRetention: "-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "-1", *meta.topicDetail().ConfigEntries["retention.ms"])
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for k, v := range map[string]string{
+			enableEntityManagement: "maybe",
+			topicPartitions:        "0",
+			topicReplicationFactor: "a",
+			topicRetention:         "1 week",
+			topicRetentionBytes:    "1GB",
+		} {
+			_, err := getTopicsMetadata(map[string]string{k: v})
+			require.Error(t, err, k)
+		}
+	})
+}
+
+func TestEnsureTopics(t *testing.T) {
+	creator := &fakeTopicCreator{created: map[string]*sarama.TopicDetail{"existing": {}}}
+	k := getKafka()
+	k.ensuredTopics = map[string]struct{}{}
+	k.topicCreator = creator
+
+	t.Run("disabled", func(t *testing.T) {
+		require.NoError(t, k.ensureTopics("orders"))
+		assert.NotContains(t, creator.created, "orders")
+	})
+
+	k.topics = topicsMetadata{EnableEntityManagement: true, Partitions: 3, ReplicationFactor: 1, Retention: time.Hour}
+
+	t.Run("creates missing topics", func(t *testing.T) {
+		require.NoError(t, k.ensureTopics("orders", "existing"))
+		require.Contains(t, creator.created, "orders")
+		assert.Equal(t, int32(3), creator.created["orders"].NumPartitions)
+		assert.Equal(t, "3600000", *creator.created["orders"].ConfigEntries["retention.ms"])
+		assert.Equal(t, &sarama.TopicDetail{}, creator.created["existing"])
+	})
+
+	t.Run("topics are created once", func(t *testing.T) {
+		creator.err = errors.New("unavailable")
+		require.NoError(t, k.ensureTopics("orders"))
+		err := k.ensureTopics("payments")
+		require.ErrorContains(t, err, "failed to create topic payments: unavailable")
+	})
+}
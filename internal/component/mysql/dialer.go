@@ -0,0 +1,47 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/dapr/components-contrib/internal/dialer"
+)
+
+// DSNWithDialAddress returns the data source name connecting to the dial address instead of the address in the DSN,
+// e.g. a Unix domain socket or a local proxy. The DSN is returned as is if the dial address is empty.
+// The driver's dialers are registered by network name, so the DSN uses the network registered for the dial address.
+func DSNWithDialAddress(dsn string, address string) (string, error) {
+	if address == "" {
+		return dsn, nil
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	dial, err := dialer.New(address, cfg.Timeout)
+	if err != nil {
+		return "", err
+	}
+
+	name := dialer.NetworkName(address)
+	mysql.RegisterDialContext(name, func(ctx context.Context, addr string) (net.Conn, error) {
+		return dial(ctx, "tcp", addr)
+	})
+	cfg.Net = name
+	return cfg.FormatDSN(), nil
+}
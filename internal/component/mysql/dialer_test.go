@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/internal/dialer"
+)
+
+func TestDSNWithDialAddress(t *testing.T) {
+	dsn, err := DSNWithDialAddress("user:pass@tcp(db.example.com:3306)/dapr?parseTime=true", "")
+	require.NoError(t, err)
+	assert.Equal(t, "user:pass@tcp(db.example.com:3306)/dapr?parseTime=true", dsn)
+
+	sock := filepath.Join(t.TempDir(), "mysql.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	dsn, err = DSNWithDialAddress("user:pass@tcp(db.example.com:3306)/dapr?parseTime=true", "unix://"+sock)
+	require.NoError(t, err)
+	cfg, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, dialer.NetworkName("unix://"+sock), cfg.Net)
+	assert.Equal(t, "db.example.com:3306", cfg.Addr)
+	assert.Equal(t, "dapr", cfg.DBName)
+	assert.True(t, cfg.ParseTime)
+
+	// The connector dials the socket, whatever the address of the DSN.
+	connector, err := mysql.NewConnector(cfg)
+	require.NoError(t, err)
+	_, err = connector.Connect(context.Background())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "db.example.com")
+
+	_, err = DSNWithDialAddress("user:pass@tcp(db.example.com:3306)/dapr", "unix://relative.sock")
+	assert.ErrorContains(t, err, "must be absolute")
+}
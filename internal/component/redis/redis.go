@@ -34,6 +34,20 @@ type RedisXMessage struct {
 	Values map[string]interface{}
 }
 
+// RedisXAddTrim is the trimming of a stream when messages are appended to it; the zero value does not trim.
+type RedisXAddTrim struct {
+	// MaxLenApprox trims the stream to about this number of entries (MAXLEN ~).
+	MaxLenApprox int64
+	// MinIDApprox trims the entries with IDs lower than this one, about (MINID ~). Requires Redis 6.2.
+	MinIDApprox string
+}
+
+// RedisXAddMessage is a message appended to a stream with XAddPipeline.
+type RedisXAddMessage struct {
+	Stream string
+	Values map[string]interface{}
+}
+
 type RedisXStream struct {
 	Stream   string
 	Messages []RedisXMessage
@@ -65,7 +79,8 @@ type RedisClient interface {
 	PingResult(ctx context.Context) (string, error)
 	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (*bool, error)
 	EvalInt(ctx context.Context, script string, keys []string, args ...interface{}) (*int, error, error)
-	XAdd(ctx context.Context, stream string, maxLenApprox int64, values map[string]interface{}) (string, error)
+	XAdd(ctx context.Context, stream string, trim RedisXAddTrim, values map[string]interface{}) (string, error)
+	XAddPipeline(ctx context.Context, trim RedisXAddTrim, messages []RedisXAddMessage) []error
 	XGroupCreateMkStream(ctx context.Context, stream string, group string, start string) error
 	XAck(ctx context.Context, stream string, group string, messageID string) error
 	XReadGroupResult(ctx context.Context, group string, consumer string, streams []string, count int64, block time.Duration) ([]RedisXStream, error)
@@ -85,6 +100,9 @@ func ParseClientFromProperties(properties map[string]string, defaultSettings *Se
 	if err != nil {
 		return nil, nil, fmt.Errorf("redis client configuration error: %w", err)
 	}
+	if err = settings.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("redis client configuration error: %w", err)
+	}
 
 	var c RedisClient
 	if settings.Failover {
@@ -14,10 +14,12 @@ limitations under the License.
 package redis
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -101,6 +103,24 @@ func TestParseRedisMetadata(t *testing.T) {
 		assert.Equal(t, "master", m.SentinelMasterName)
 	})
 
+	t.Run("sentinel credentials and multiple hosts", func(t *testing.T) {
+		fakeProperties := getFakeProperties()
+		fakeProperties[host] = "sentinel-0:26379, sentinel-1:26379,sentinel-2:26379"
+		fakeProperties["sentinelUsername"] = "sentinel-user"
+		fakeProperties["sentinelPassword"] = "sentinel-pass"
+
+		// act
+		m := &Settings{}
+		err := m.Decode(fakeProperties)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "sentinel-user", m.SentinelUsername)
+		assert.Equal(t, "sentinel-pass", m.SentinelPassword)
+		assert.Equal(t, []string{"sentinel-0:26379", "sentinel-1:26379", "sentinel-2:26379"}, m.Hosts())
+		assert.False(t, m.IsCluster())
+	})
+
 	t.Run("host is not given", func(t *testing.T) {
 		fakeProperties := getFakeProperties()
 
@@ -136,3 +156,58 @@ func TestParseRedisMetadata(t *testing.T) {
 		assert.True(t, m.RedisMinRetryInterval == -1)
 	})
 }
+
+func TestKeyHashSlot(t *testing.T) {
+	// Expected values as returned by CLUSTER KEYSLOT.
+	assert.Equal(t, 12182, KeyHashSlot("foo"))
+	assert.Equal(t, 12739, KeyHashSlot("123456789"))
+	assert.Equal(t, KeyHashSlot("user1000"), KeyHashSlot("{user1000}.following"))
+	// An empty hash tag hashes the whole key.
+	assert.Equal(t, int(crc16("{}foo")%ClusterSlots), KeyHashSlot("{}foo"))
+
+	assert.True(t, SameHashSlot("{user1000}.following", "{user1000}.followers"))
+	assert.True(t, SameHashSlot("foo"))
+	assert.False(t, SameHashSlot("foo", "bar"))
+}
+
+func TestDialAddress(t *testing.T) {
+	t.Run("unix socket host", func(t *testing.T) {
+		s := &Settings{Host: "unix:///var/run/redis/redis.sock"}
+		assert.NoError(t, s.Validate())
+		network, addr := s.NodeAddress()
+		assert.Equal(t, "unix", network)
+		assert.Equal(t, "/var/run/redis/redis.sock", addr)
+	})
+
+	t.Run("tcp host", func(t *testing.T) {
+		s := &Settings{Host: "redis.example.com:6379"}
+		assert.NoError(t, s.Validate())
+		network, addr := s.NodeAddress()
+		assert.Equal(t, "tcp", network)
+		assert.Equal(t, "redis.example.com:6379", addr)
+		assert.Nil(t, s.Dialer(nil))
+	})
+
+	t.Run("dial address", func(t *testing.T) {
+		srv := miniredis.RunT(t)
+		s := &Settings{Host: "redis.example.com:6379", DialAddress: "tcp://" + srv.Addr()}
+		assert.NoError(t, s.Validate())
+
+		c := newV8Client(s)
+		defer c.Close()
+		_, err := c.PingResult(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid dial address", func(t *testing.T) {
+		s := &Settings{Host: "localhost:6379", DialAddress: "unix://redis.sock"}
+		assert.ErrorContains(t, s.Validate(), "must be absolute")
+	})
+
+	t.Run("not supported by clusters", func(t *testing.T) {
+		s := &Settings{Host: "localhost:6379", RedisType: ClusterType, DialAddress: "/var/run/redis.sock"}
+		assert.Error(t, s.Validate())
+		s = &Settings{Host: "localhost:6379", Failover: true, DialAddress: "/var/run/redis.sock"}
+		assert.Error(t, s.Validate())
+	})
+}
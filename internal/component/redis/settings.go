@@ -14,10 +14,16 @@ limitations under the License.
 package redis
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dapr/components-contrib/internal/dialer"
 	"github.com/dapr/kit/config"
 )
 
@@ -72,11 +78,19 @@ type Settings struct {
 	IdleCheckFrequency Duration `mapstructure:"idleCheckFrequency"`
 	// The master name
 	SentinelMasterName string `mapstructure:"sentinelMasterName"`
+	// The username used to authenticate with the sentinels
+	SentinelUsername string `mapstructure:"sentinelUsername"`
+	// The password used to authenticate with the sentinels
+	SentinelPassword string `mapstructure:"sentinelPassword"`
 	// Use Redis Sentinel for automatic failover.
 	Failover bool `mapstructure:"failover"`
 
 	// A flag to enables TLS by setting InsecureSkipVerify to true
 	EnableTLS bool `mapstructure:"enableTLS"`
+
+	// The address the connections are dialed to instead of the host, e.g. a Unix domain socket or a local proxy.
+	// The host can also be a Unix domain socket, as unix:///path/to/redis.sock.
+	DialAddress string `mapstructure:"dialAddress"`
 }
 
 func (s *Settings) Decode(in interface{}) error {
@@ -87,6 +101,64 @@ func (s *Settings) Decode(in interface{}) error {
 	return nil
 }
 
+// Hosts returns the comma-separated addresses in Host.
+// These are the cluster nodes for a cluster, or the sentinels when failover is enabled.
+func (s *Settings) Hosts() []string {
+	hosts := strings.Split(s.Host, ",")
+	for i := range hosts {
+		hosts[i] = strings.TrimSpace(hosts[i])
+	}
+
+	return hosts
+}
+
+// Validate checks the addresses of the settings.
+func (s *Settings) Validate() error {
+	unixHost := strings.HasPrefix(s.Host, "unix:")
+	if s.DialAddress == "" && !unixHost {
+		return nil
+	}
+	if s.IsCluster() || s.Failover {
+		return errors.New("dialAddress and Unix domain socket hosts are only supported by the node redisType without failover")
+	}
+	if unixHost {
+		if _, _, err := dialer.ParseAddress(s.Host); err != nil {
+			return err
+		}
+	}
+	if s.DialAddress != "" {
+		if _, _, err := dialer.ParseAddress(s.DialAddress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NodeAddress returns the network and the address of the host of a node.
+func (s *Settings) NodeAddress() (network string, addr string) {
+	if strings.HasPrefix(s.Host, "unix:") {
+		if network, addr, err := dialer.ParseAddress(s.Host); err == nil {
+			return network, addr
+		}
+	}
+	return "tcp", s.Host
+}
+
+// Dialer returns the dialer of the dial address, running the TLS handshake if tlsConfig is not nil, or nil if there
+// is no dial address.
+func (s *Settings) Dialer(tlsConfig *tls.Config) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	dial, err := dialer.New(s.DialAddress, time.Duration(s.DialTimeout))
+	if err != nil || dial == nil {
+		return nil
+	}
+	return dialer.WithTLS(dial, tlsConfig)
+}
+
+// IsCluster returns true if the settings describe a Redis Cluster deployment.
+func (s *Settings) IsCluster() bool {
+	return s.RedisType == ClusterType
+}
+
 type Duration time.Duration
 
 func (r *Duration) DecodeString(value string) error {
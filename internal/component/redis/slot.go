@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import "strings"
+
+// ClusterSlots is the number of hash slots in a Redis Cluster.
+const ClusterSlots = 16384
+
+// KeyHashSlot returns the Redis Cluster hash slot of a key.
+// If the key contains a non-empty hash tag, such as "{user1}" in "{user1}.profile", only the tag is hashed.
+func KeyHashSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key) % ClusterSlots)
+}
+
+// SameHashSlot returns true if all keys map to the same hash slot, which Redis Cluster requires for MULTI/EXEC and scripts.
+func SameHashSlot(keys ...string) bool {
+	for i := 1; i < len(keys); i++ {
+		if KeyHashSlot(keys[i]) != KeyHashSlot(keys[0]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// crc16 implements the CRC16-CCITT (XMODEM) checksum used by Redis Cluster.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
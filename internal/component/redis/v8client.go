@@ -16,7 +16,6 @@ package redis
 import (
 	"context"
 	"crypto/tls"
-	"strings"
 	"time"
 
 	v8 "github.com/go-redis/redis/v8"
@@ -135,7 +134,7 @@ func (c v8Client) SetNX(ctx context.Context, key string, value interface{}, expi
 	return &val, nx.Err()
 }
 
-func (c v8Client) XAdd(ctx context.Context, stream string, maxLenApprox int64, values map[string]interface{}) (string, error) {
+func (c v8Client) XAdd(ctx context.Context, stream string, trim RedisXAddTrim, values map[string]interface{}) (string, error) {
 	var writeCtx context.Context
 	if c.writeTimeout > 0 {
 		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.writeTimeout))
@@ -145,12 +144,46 @@ func (c v8Client) XAdd(ctx context.Context, stream string, maxLenApprox int64, v
 		writeCtx = ctx
 	}
 	return c.client.XAdd(writeCtx, &v8.XAddArgs{
-		Stream:       stream,
-		Values:       values,
-		MaxLenApprox: maxLenApprox,
+		Stream: stream,
+		Values: values,
+		MaxLen: trim.MaxLenApprox,
+		MinID:  trim.MinIDApprox,
+		Approx: true,
 	}).Result()
 }
 
+// XAddPipeline appends the messages with a single round trip, and returns the error of each message.
+func (c v8Client) XAddPipeline(ctx context.Context, trim RedisXAddTrim, messages []RedisXAddMessage) []error {
+	var writeCtx context.Context
+	if c.writeTimeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.writeTimeout))
+		defer cancel()
+		writeCtx = timeoutCtx
+	} else {
+		writeCtx = ctx
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*v8.StringCmd, len(messages))
+	for i, msg := range messages {
+		cmds[i] = pipe.XAdd(writeCtx, &v8.XAddArgs{
+			Stream: msg.Stream,
+			Values: msg.Values,
+			MaxLen: trim.MaxLenApprox,
+			MinID:  trim.MinIDApprox,
+			Approx: true,
+		})
+	}
+	// Errors are reported by each command
+	_, _ = pipe.Exec(writeCtx)
+
+	errs := make([]error, len(messages))
+	for i, cmd := range cmds {
+		errs[i] = cmd.Err()
+	}
+	return errs
+}
+
 func (c v8Client) XGroupCreateMkStream(ctx context.Context, stream string, group string, start string) error {
 	var writeCtx context.Context
 	if c.writeTimeout > 0 {
@@ -295,7 +328,9 @@ func newV8FailoverClient(s *Settings) RedisClient {
 	opts := &v8.FailoverOptions{
 		DB:                 s.DB,
 		MasterName:         s.SentinelMasterName,
-		SentinelAddrs:      []string{s.Host},
+		SentinelAddrs:      s.Hosts(),
+		SentinelUsername:   s.SentinelUsername,
+		SentinelPassword:   s.SentinelPassword,
 		Password:           s.Password,
 		Username:           s.Username,
 		MaxRetries:         s.RedisMaxRetries,
@@ -320,8 +355,6 @@ func newV8FailoverClient(s *Settings) RedisClient {
 	}
 
 	if s.RedisType == ClusterType {
-		opts.SentinelAddrs = strings.Split(s.Host, ",")
-
 		return v8Client{
 			client:       v8.NewFailoverClusterClient(opts),
 			readTimeout:  s.ReadTimeout,
@@ -344,7 +377,7 @@ func newV8Client(s *Settings) RedisClient {
 	}
 	if s.RedisType == ClusterType {
 		options := &v8.ClusterOptions{
-			Addrs:              strings.Split(s.Host, ","),
+			Addrs:              s.Hosts(),
 			Password:           s.Password,
 			Username:           s.Username,
 			MaxRetries:         s.RedisMaxRetries,
@@ -375,8 +408,10 @@ func newV8Client(s *Settings) RedisClient {
 		}
 	}
 
+	network, addr := s.NodeAddress()
 	options := &v8.Options{
-		Addr:               s.Host,
+		Network:            network,
+		Addr:               addr,
 		Password:           s.Password,
 		Username:           s.Username,
 		DB:                 s.DB,
@@ -401,6 +436,8 @@ func newV8Client(s *Settings) RedisClient {
 		}
 	}
 
+	options.Dialer = s.Dialer(options.TLSConfig)
+
 	return v8Client{
 		client:       v8.NewClient(options),
 		readTimeout:  s.ReadTimeout,
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resiliency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/retry"
+)
+
+// ErrCircuitOpen is returned without calling the component while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Metadata contains the resiliency settings of a component instance.
+// Retries are configured with the "resiliencyRetry" prefix, such as "resiliencyRetryMaxRetries" and "resiliencyRetryPolicy".
+type Metadata struct {
+	// Timeout of every attempt.
+	Timeout time.Duration `mapstructure:"resiliencyTimeout"`
+	// Consecutive failures opening the circuit breaker; 0 disables it.
+	CircuitBreakerThreshold int `mapstructure:"resiliencyCircuitBreakerThreshold"`
+	// Time the circuit breaker stays open before letting a trial call through.
+	CircuitBreakerTimeout time.Duration `mapstructure:"resiliencyCircuitBreakerTimeout"`
+
+	Retry retry.Config `mapstructure:"-"`
+}
+
+// ParseMetadata reads the resiliency settings from the component metadata.
+func ParseMetadata(properties map[string]string) (Metadata, error) {
+	m := Metadata{
+		CircuitBreakerTimeout: time.Minute,
+		Retry:                 retry.DefaultConfigWithNoRetry(),
+	}
+	err := metadata.DecodeMetadata(properties, &m)
+	if err != nil {
+		return m, err
+	}
+	err = retry.DecodeConfigWithPrefix(&m.Retry, properties, "resiliencyRetry")
+	if err != nil {
+		return m, fmt.Errorf("error decoding resiliencyRetry config: %w", err)
+	}
+	if m.CircuitBreakerThreshold < 0 {
+		return m, errors.New("resiliencyCircuitBreakerThreshold must not be negative")
+	}
+	return m, nil
+}
+
+// Policy runs operations with a timeout, retries and a circuit breaker.
+type Policy struct {
+	timeout   time.Duration
+	retry     retry.Config
+	breaker   *circuitBreaker
+	permanent func(error) bool
+}
+
+// NewPolicy returns the Policy for the given settings, or nil if none is enabled.
+// Errors for which permanent returns true are not retried and don't trip the circuit breaker.
+func NewPolicy(m Metadata, permanent func(error) bool) *Policy {
+	if m.Timeout <= 0 && m.Retry.MaxRetries == 0 && m.CircuitBreakerThreshold == 0 {
+		return nil
+	}
+
+	p := &Policy{
+		timeout:   m.Timeout,
+		retry:     m.Retry,
+		permanent: permanent,
+	}
+	if m.CircuitBreakerThreshold > 0 {
+		p.breaker = &circuitBreaker{
+			threshold: m.CircuitBreakerThreshold,
+			timeout:   m.CircuitBreakerTimeout,
+		}
+	}
+	return p
+}
+
+// Run runs op with the policy p. A nil policy runs op once.
+func Run[T any](ctx context.Context, p *Policy, op func(ctx context.Context) (T, error)) (T, error) {
+	if p == nil {
+		return op(ctx)
+	}
+
+	return backoff.RetryWithData(func() (T, error) {
+		var zero T
+		if p.breaker != nil && !p.breaker.allow() {
+			return zero, backoff.Permanent(ErrCircuitOpen)
+		}
+
+		attemptCtx := ctx
+		if p.timeout > 0 {
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+		}
+
+		res, err := op(attemptCtx)
+		if err != nil && p.permanent != nil && p.permanent(err) {
+			p.breaker.success()
+			return res, backoff.Permanent(err)
+		}
+		if err != nil {
+			p.breaker.failure()
+			return res, err
+		}
+		p.breaker.success()
+		return res, nil
+	}, p.retry.NewBackOffWithContext(ctx))
+}
+
+// RunOnce runs op, which returns only an error, with the policy p.
+func RunOnce(ctx context.Context, p *Policy, op func(ctx context.Context) error) error {
+	_, err := Run(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	})
+	return err
+}
+
+// circuitBreaker opens after threshold consecutive failures.
+// Once timeout has elapsed, a single trial call is let through: the circuit closes if it succeeds and opens again otherwise.
+type circuitBreaker struct {
+	threshold int
+	timeout   time.Duration
+
+	lock     sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.timeout {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	b.failures = 0
+	b.trial = false
+	b.lock.Unlock()
+}
+
+func (b *circuitBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+	b.trial = false
+	b.lock.Unlock()
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resiliency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func TestParseMetadata(t *testing.T) {
+	m, err := ParseMetadata(map[string]string{
+		"resiliencyTimeout":                 "2s",
+		"resiliencyRetryMaxRetries":         "3",
+		"resiliencyRetryPolicy":             "exponential",
+		"resiliencyCircuitBreakerThreshold": "5",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, m.Timeout)
+	assert.Equal(t, int64(3), m.Retry.MaxRetries)
+	assert.Equal(t, 5, m.CircuitBreakerThreshold)
+	assert.Equal(t, time.Minute, m.CircuitBreakerTimeout)
+
+	m, err = ParseMetadata(map[string]string{})
+	require.NoError(t, err)
+	assert.Nil(t, NewPolicy(m, nil))
+
+	_, err = ParseMetadata(map[string]string{"resiliencyCircuitBreakerThreshold": "-1"})
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	retries := func(n int64) Metadata {
+		m, _ := ParseMetadata(map[string]string{})
+		m.Retry.MaxRetries = n
+		m.Retry.Duration = time.Millisecond
+		return m
+	}
+
+	t.Run("retries until success", func(t *testing.T) {
+		calls := 0
+		res, err := Run(context.Background(), NewPolicy(retries(3), nil), func(ctx context.Context) (int, error) {
+			calls++
+			if calls < 3 {
+				return 0, errTest
+			}
+			return calls, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, res)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		err := RunOnce(context.Background(), NewPolicy(retries(2), nil), func(ctx context.Context) error {
+			calls++
+			return errTest
+		})
+		assert.ErrorIs(t, err, errTest)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		calls := 0
+		p := NewPolicy(retries(3), func(err error) bool { return errors.Is(err, errTest) })
+		err := RunOnce(context.Background(), p, func(ctx context.Context) error {
+			calls++
+			return errTest
+		})
+		assert.ErrorIs(t, err, errTest)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		m := retries(0)
+		m.Timeout = 10 * time.Millisecond
+		err := RunOnce(context.Background(), NewPolicy(m, nil), func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		m := retries(0)
+		m.CircuitBreakerThreshold = 2
+		m.CircuitBreakerTimeout = 50 * time.Millisecond
+		p := NewPolicy(m, nil)
+
+		calls := 0
+		failing := func(ctx context.Context) error {
+			calls++
+			return errTest
+		}
+		assert.ErrorIs(t, RunOnce(context.Background(), p, failing), errTest)
+		assert.ErrorIs(t, RunOnce(context.Background(), p, failing), errTest)
+		assert.ErrorIs(t, RunOnce(context.Background(), p, failing), ErrCircuitOpen)
+		assert.Equal(t, 2, calls)
+
+		time.Sleep(60 * time.Millisecond)
+		assert.NoError(t, RunOnce(context.Background(), p, func(ctx context.Context) error { return nil }))
+		assert.ErrorIs(t, RunOnce(context.Background(), p, failing), errTest)
+	})
+
+	t.Run("nil policy", func(t *testing.T) {
+		calls := 0
+		assert.ErrorIs(t, RunOnce(context.Background(), nil, func(ctx context.Context) error {
+			calls++
+			return errTest
+		}), errTest)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+// Metadata contains the payload validation settings.
+// It is read from the metadata of the component being validated.
+type Metadata struct {
+	// JSON Schema the payloads must match, either inline or as the path of a file.
+	Schema string `mapstructure:"validationSchema"`
+	// Topic receiving the inbound messages that don't match the schema.
+	DeadLetterTopic string `mapstructure:"validationDeadLetterTopic"`
+}
+
+// ParseMetadata reads the validation settings from the component metadata.
+func ParseMetadata(properties map[string]string) (Metadata, error) {
+	m := Metadata{}
+	err := metadata.DecodeMetadata(properties, &m)
+	return m, err
+}
+
+// ValidationError is returned for payloads that don't match the schema.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "payload does not match the schema: " + strings.Join(e.Errors, "; ")
+}
+
+// Validator validates payloads against a JSON Schema.
+type Validator struct {
+	schema *gojsonschema.Schema
+}
+
+// NewValidator compiles the schema, given inline or as a file path.
+// It returns nil if schema is empty, meaning validation is disabled.
+func NewValidator(schema string) (*Validator, error) {
+	schema = strings.TrimSpace(schema)
+	if schema == "" {
+		return nil, nil
+	}
+
+	raw := []byte(schema)
+	if !strings.HasPrefix(schema, "{") {
+		var err error
+		raw, err = os.ReadFile(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema file: %w", err)
+		}
+	}
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	return &Validator{schema: compiled}, nil
+}
+
+// Validate checks data against the schema.
+// When data is a CloudEvent, its data is validated rather than the envelope.
+// It returns a *ValidationError for payloads that don't match.
+func (v *Validator) Validate(data []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return &ValidationError{Errors: []string{"payload is not valid JSON"}}
+	}
+
+	doc, err := eventData(doc)
+	if err != nil {
+		return &ValidationError{Errors: []string{err.Error()}}
+	}
+
+	res, err := v.schema.Validate(gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return err
+	}
+	if res.Valid() {
+		return nil
+	}
+
+	errs := make([]string, len(res.Errors()))
+	for i, e := range res.Errors() {
+		errs[i] = e.String()
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// eventData returns the data of a CloudEvent, or doc itself if it isn't one.
+func eventData(doc interface{}) (interface{}, error) {
+	event, ok := doc.(map[string]interface{})
+	if !ok {
+		return doc, nil
+	}
+	if _, ok = event["specversion"]; !ok {
+		return doc, nil
+	}
+
+	if data, ok := event["data"]; ok {
+		return data, nil
+	}
+	if encoded, ok := event["data_base64"].(string); ok {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("event data is not valid base64")
+		}
+		var data interface{}
+		if err = json.Unmarshal(raw, &data); err != nil {
+			return nil, errors.New("event data is not valid JSON")
+		}
+		return data, nil
+	}
+	return nil, nil
+}
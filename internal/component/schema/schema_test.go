@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {"orderId": {"type": "integer"}},
+	"required": ["orderId"]
+}`
+
+func TestNewValidator(t *testing.T) {
+	t.Run("no schema", func(t *testing.T) {
+		v, err := NewValidator("")
+		require.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("schema file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "schema.json")
+		require.NoError(t, os.WriteFile(path, []byte(testSchema), 0o600))
+
+		v, err := NewValidator(path)
+		require.NoError(t, err)
+		assert.NoError(t, v.Validate([]byte(`{"orderId":1}`)))
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := NewValidator(`{"type": 1}`)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	v, err := NewValidator(testSchema)
+	require.NoError(t, err)
+
+	t.Run("valid payload", func(t *testing.T) {
+		assert.NoError(t, v.Validate([]byte(`{"orderId":1}`)))
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		err := v.Validate([]byte(`{"orderId":"one"}`))
+		var verr *ValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.Len(t, verr.Errors, 1)
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		var verr *ValidationError
+		assert.ErrorAs(t, v.Validate([]byte("hello")), &verr)
+	})
+
+	t.Run("cloudevent data", func(t *testing.T) {
+		assert.NoError(t, v.Validate([]byte(`{"specversion":"1.0","id":"1","data":{"orderId":1}}`)))
+		assert.Error(t, v.Validate([]byte(`{"specversion":"1.0","id":"1","data":{"id":1}}`)))
+	})
+
+	t.Run("cloudevent base64 data", func(t *testing.T) {
+		assert.NoError(t, v.Validate([]byte(`{"specversion":"1.0","id":"1","data_base64":"eyJvcmRlcklkIjoxfQ=="}`)))
+	})
+}
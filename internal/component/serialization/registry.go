@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serialization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// registeredSchema is a schema version returned by a Confluent Schema Registry.
+type registeredSchema struct {
+	ID         int    `json:"id"`
+	Version    int    `json:"version"`
+	SchemaType string `json:"schemaType"`
+	Schema     string `json:"schema"`
+}
+
+type registryClient struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func newRegistryClient(m Metadata) *registryClient {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	return &registryClient{
+		url:      strings.TrimSuffix(m.SchemaRegistryURL, "/"),
+		username: m.SchemaRegistryUsername,
+		password: m.SchemaRegistryPassword,
+		httpClient: &http.Client{
+			Timeout: m.SchemaRegistryTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+			},
+		},
+	}
+}
+
+// latest returns the latest schema version of subject.
+func (c *registryClient) latest(ctx context.Context, subject string) (*registeredSchema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/subjects/"+url.PathEscape(subject)+"/versions/latest", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema from registry: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema registry response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry returned status %d: %s", res.StatusCode, string(body))
+	}
+
+	schema := &registeredSchema{}
+	if err = json.Unmarshal(body, schema); err != nil {
+		return nil, fmt.Errorf("invalid schema registry response: %w", err)
+	}
+	return schema, nil
+}
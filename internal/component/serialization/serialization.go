@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serialization
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+// Supported serialization formats.
+const (
+	FormatAvro     = "avro"
+	FormatProtobuf = "protobuf"
+)
+
+// Metadata contains the serialization settings.
+// It is read from the metadata of the component whose payloads are serialized.
+type Metadata struct {
+	// Target format: "avro" or "protobuf". Serialization is disabled when empty.
+	Format string `mapstructure:"serializationFormat"`
+	// Inline schema: an Avro schema, or a base64-encoded protobuf FileDescriptorSet.
+	Schema string `mapstructure:"serializationSchema"`
+	// Full name of the protobuf message, such as "orders.v1.Order".
+	MessageType string `mapstructure:"serializationMessageType"`
+	// Confluent Schema Registry the latest Avro schema of the subject is fetched from.
+	// Payloads are then framed with the schema ID using the Confluent wire format.
+	SchemaRegistryURL      string        `mapstructure:"schemaRegistryUrl"`
+	SchemaRegistrySubject  string        `mapstructure:"schemaRegistrySubject"`
+	SchemaRegistryUsername string        `mapstructure:"schemaRegistryUsername"`
+	SchemaRegistryPassword string        `mapstructure:"schemaRegistryPassword"`
+	SchemaRegistryTimeout  time.Duration `mapstructure:"schemaRegistryTimeout"`
+}
+
+// ParseMetadata reads the serialization settings from the component metadata.
+func ParseMetadata(properties map[string]string) (Metadata, error) {
+	m := Metadata{
+		SchemaRegistryTimeout: 10 * time.Second,
+	}
+	err := metadata.DecodeMetadata(properties, &m)
+	return m, err
+}
+
+// Serializer converts JSON payloads to a binary format.
+type Serializer interface {
+	Serialize(data []byte) ([]byte, error)
+}
+
+// NewSerializer returns the Serializer for the configured format, or nil if serialization is disabled.
+func NewSerializer(ctx context.Context, m Metadata) (Serializer, error) {
+	switch m.Format {
+	case "":
+		return nil, nil
+	case FormatAvro:
+		return newAvroSerializer(ctx, m)
+	case FormatProtobuf:
+		return newProtobufSerializer(m)
+	default:
+		return nil, fmt.Errorf("unsupported serialization format: %s", m.Format)
+	}
+}
+
+type avroSerializer struct {
+	codec    *goavro.Codec
+	schemaID *int
+}
+
+func newAvroSerializer(ctx context.Context, m Metadata) (*avroSerializer, error) {
+	s := &avroSerializer{}
+	schema := m.Schema
+	if m.SchemaRegistryURL != "" {
+		if m.SchemaRegistrySubject == "" {
+			return nil, errors.New("schemaRegistrySubject is required with schemaRegistryUrl")
+		}
+		registered, err := newRegistryClient(m).latest(ctx, m.SchemaRegistrySubject)
+		if err != nil {
+			return nil, err
+		}
+		if registered.SchemaType != "" && registered.SchemaType != "AVRO" {
+			return nil, fmt.Errorf("unsupported schema type in registry: %s", registered.SchemaType)
+		}
+		schema = registered.Schema
+		s.schemaID = &registered.ID
+	}
+	if schema == "" {
+		return nil, errors.New("serializationSchema or schemaRegistryUrl is required for avro")
+	}
+
+	var err error
+	s.codec, err = goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	return s, nil
+}
+
+// Serialize converts data, in the Avro JSON encoding, to Avro binary.
+func (s *avroSerializer) Serialize(data []byte) ([]byte, error) {
+	native, _, err := s.codec.NativeFromTextual(data)
+	if err != nil {
+		return nil, fmt.Errorf("payload does not match the avro schema: %w", err)
+	}
+
+	var buf []byte
+	if s.schemaID != nil {
+		// Confluent wire format: magic byte and big-endian schema ID
+		buf = make([]byte, 5)
+		binary.BigEndian.PutUint32(buf[1:], uint32(*s.schemaID))
+	}
+	return s.codec.BinaryFromNative(buf, native)
+}
+
+type protobufSerializer struct {
+	message protoreflect.MessageDescriptor
+}
+
+func newProtobufSerializer(m Metadata) (*protobufSerializer, error) {
+	if m.Schema == "" || m.MessageType == "" {
+		return nil, errors.New("serializationSchema and serializationMessageType are required for protobuf")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(m.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("serializationSchema is not valid base64: %w", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(raw, set); err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set: %w", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(m.MessageType))
+	if err != nil {
+		return nil, fmt.Errorf("protobuf message %s not found: %w", m.MessageType, err)
+	}
+	message, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a protobuf message", m.MessageType)
+	}
+
+	return &protobufSerializer{message: message}, nil
+}
+
+// Serialize converts data, in the protobuf JSON mapping, to the protobuf wire format.
+func (s *protobufSerializer) Serialize(data []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(s.message)
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("payload does not match the protobuf message: %w", err)
+	}
+	// Deterministic, so that equal payloads are serialized to the same bytes
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serialization
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const orderSchema = `{
+	"type": "record",
+	"name": "Order",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "item", "type": "string"}
+	]
+}`
+
+func TestAvroSerializer(t *testing.T) {
+	codec, err := goavro.NewCodec(orderSchema)
+	require.NoError(t, err)
+
+	t.Run("inline schema", func(t *testing.T) {
+		s, err := NewSerializer(context.Background(), Metadata{Format: FormatAvro, Schema: orderSchema})
+		require.NoError(t, err)
+
+		data, err := s.Serialize([]byte(`{"id": 1, "item": "apple"}`))
+		require.NoError(t, err)
+
+		native, _, err := codec.NativeFromBinary(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"id": int64(1), "item": "apple"}, native)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		s, err := NewSerializer(context.Background(), Metadata{Format: FormatAvro, Schema: orderSchema})
+		require.NoError(t, err)
+
+		_, err = s.Serialize([]byte(`{"id": "one"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("schema registry", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/subjects/orders-value/versions/latest", r.URL.Path)
+			w.Write([]byte(`{"subject":"orders-value","id":42,"version":3,"schema":` + quote(orderSchema) + `}`))
+		}))
+		defer srv.Close()
+
+		m, err := ParseMetadata(map[string]string{
+			"serializationFormat":   "avro",
+			"schemaRegistryUrl":     srv.URL,
+			"schemaRegistrySubject": "orders-value",
+		})
+		require.NoError(t, err)
+		s, err := NewSerializer(context.Background(), m)
+		require.NoError(t, err)
+
+		data, err := s.Serialize([]byte(`{"id": 1, "item": "apple"}`))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0, 0, 0, 0, 42}, data[:5])
+
+		native, _, err := codec.NativeFromBinary(data[5:])
+		require.NoError(t, err)
+		assert.Equal(t, "apple", native.(map[string]interface{})["item"])
+	})
+
+	t.Run("missing schema", func(t *testing.T) {
+		_, err := NewSerializer(context.Background(), Metadata{Format: FormatAvro})
+		assert.Error(t, err)
+	})
+}
+
+func TestProtobufSerializer(t *testing.T) {
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("orders.proto"),
+			Package: proto.String("orders.v1"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("id"), JsonName: proto.String("id"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("item"), JsonName: proto.String("item"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			}},
+		}},
+	}
+	raw, err := proto.Marshal(set)
+	require.NoError(t, err)
+	schema := base64.StdEncoding.EncodeToString(raw)
+
+	t.Run("serialize", func(t *testing.T) {
+		s, err := NewSerializer(context.Background(), Metadata{Format: FormatProtobuf, Schema: schema, MessageType: "orders.v1.Order"})
+		require.NoError(t, err)
+
+		data, err := s.Serialize([]byte(`{"id": "7", "item": "apple"}`))
+		require.NoError(t, err)
+		// The order of the fields in the wire format isn't defined, so the messages are compared.
+		desc := s.(*protobufSerializer).message
+		expected := dynamicpb.NewMessage(desc)
+		expected.Set(desc.Fields().ByName("id"), protoreflect.ValueOfInt64(7))
+		expected.Set(desc.Fields().ByName("item"), protoreflect.ValueOfString("apple"))
+		actual := dynamicpb.NewMessage(desc)
+		require.NoError(t, proto.Unmarshal(data, actual))
+		assert.True(t, proto.Equal(expected, actual), "unexpected message %v", actual)
+
+		_, err = s.Serialize([]byte(`{"unknown": 1}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		_, err := NewSerializer(context.Background(), Metadata{Format: FormatProtobuf, Schema: schema, MessageType: "orders.v1.Invoice"})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewSerializer(t *testing.T) {
+	s, err := NewSerializer(context.Background(), Metadata{})
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = NewSerializer(context.Background(), Metadata{Format: "thrift"})
+	assert.Error(t, err)
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dialer lets the components connect to local services over Unix domain sockets, or to a dial address other
+// than the host of their connection settings, such as a local proxy or a socket shared with a sidecar.
+package dialer
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MetadataKey is the metadata property with the dial address of the components supporting it.
+const MetadataKey = "dialAddress"
+
+// DialContextFunc is the signature of the custom dialers of the clients.
+type DialContextFunc func(ctx context.Context, network string, addr string) (net.Conn, error)
+
+// ParseAddress returns the network and the address of a dial address:
+//   - "unix:///path/to.sock", "unix:/path/to.sock" or an absolute path for a Unix domain socket;
+//   - "tcp://host:port" or "host:port" for TCP.
+func ParseAddress(address string) (network string, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", address[len("unix://"):]
+	case strings.HasPrefix(address, "unix:"):
+		network, addr = "unix", address[len("unix:"):]
+	case filepath.IsAbs(address):
+		network, addr = "unix", address
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", address[len("tcp://"):]
+	default:
+		network, addr = "tcp", address
+	}
+
+	if network == "unix" {
+		if !filepath.IsAbs(addr) {
+			return "", "", fmt.Errorf("invalid dial address %q: the path of the socket must be absolute", address)
+		}
+		return network, addr, nil
+	}
+	if _, _, err = net.SplitHostPort(addr); err != nil {
+		return "", "", fmt.Errorf("invalid dial address %q: %w", address, err)
+	}
+	return network, addr, nil
+}
+
+// IsUnix returns true if the address is the one of a Unix domain socket.
+func IsUnix(address string) bool {
+	network, _, err := ParseAddress(address)
+	return err == nil && network == "unix"
+}
+
+// New returns a dialer connecting to the dial address instead of the address requested by the client, or nil if
+// the dial address is empty.
+func New(address string, timeout time.Duration) (DialContextFunc, error) {
+	if address == "" {
+		return nil, nil
+	}
+	network, addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &net.Dialer{Timeout: timeout}
+	if network == "tcp" {
+		d.KeepAlive = 5 * time.Minute
+	}
+	return func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, network, addr)
+	}, nil
+}
+
+// WithTLS returns a dialer running the TLS handshake on the connections of dial, for the clients which skip their own
+// TLS setup when using a custom dialer. The server name is the host of the address requested by the client if the
+// configuration doesn't have one, so that the certificate is verified against it and not against the dial address.
+func WithTLS(dial DialContextFunc, config *tls.Config) DialContextFunc {
+	if dial == nil || config == nil {
+		return dial
+	}
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := config
+		if cfg.ServerName == "" {
+			cfg = config.Clone()
+			if host, _, splitErr := net.SplitHostPort(addr); splitErr == nil {
+				cfg.ServerName = host
+			} else {
+				cfg.ServerName = addr
+			}
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// NetworkName returns a name identifying the dial address, for the drivers registering the dialers by network name.
+func NetworkName(address string) string {
+	h := sha256.Sum256([]byte(address))
+	return "dapr-dial-" + hex.EncodeToString(h[:8])
+}
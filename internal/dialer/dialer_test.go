@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dialer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+		err     bool
+	}{
+		{"unix:///var/run/redis.sock", "unix", "/var/run/redis.sock", false},
+		{"unix:/var/run/redis.sock", "unix", "/var/run/redis.sock", false},
+		{"/var/run/redis.sock", "unix", "/var/run/redis.sock", false},
+		{"unix://redis.sock", "", "", true},
+		{"tcp://127.0.0.1:6380", "tcp", "127.0.0.1:6380", false},
+		{"localhost:5432", "tcp", "localhost:5432", false},
+		{"[::1]:5432", "tcp", "[::1]:5432", false},
+		{"localhost", "", "", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.address, func(t *testing.T) {
+			network, addr, err := ParseAddress(tt.address)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.network, network)
+			assert.Equal(t, tt.addr, addr)
+		})
+	}
+
+	assert.True(t, IsUnix("/tmp/x.sock"))
+	assert.False(t, IsUnix("localhost:80"))
+}
+
+func TestNew(t *testing.T) {
+	dial, err := New("", time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, dial)
+
+	_, err = New("localhost", time.Second)
+	assert.Error(t, err)
+
+	sock := filepath.Join(t.TempDir(), "test.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("hello"))
+		conn.Close()
+	}()
+
+	dial, err = New("unix://"+sock, time.Second)
+	require.NoError(t, err)
+	conn, err := dial(context.Background(), "tcp", "db.example.com:5432")
+	require.NoError(t, err)
+	defer conn.Close()
+	buf := make([]byte, 5)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+}
+
+func TestWithTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	assert.Nil(t, WithTLS(nil, &tls.Config{MinVersion: tls.VersionTLS12}))
+
+	dial, err := New(srv.Listener.Addr().String(), time.Second)
+	require.NoError(t, err)
+
+	// The certificate of the test server is for example.com, not for the dial address.
+	pool := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	tlsDial := WithTLS(dial, &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12})
+	conn, err := tlsDial(context.Background(), "tcp", "example.com:443")
+	require.NoError(t, err)
+	conn.Close()
+
+	_, err = tlsDial(context.Background(), "tcp", "other.org:443")
+	assert.Error(t, err)
+}
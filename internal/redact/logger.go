@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dapr/kit/logger"
+)
+
+// Secrets shorter than this are not scrubbed from the messages, as they would match too often.
+const minSecretLength = 4
+
+// Logger wraps a logger to remove the sensitive values from the log entries: the values of the secrets added to it,
+// the credentials of connection strings and URLs, the values marked with Sensitive, and the fields with sensitive names.
+type Logger struct {
+	logger.Logger
+	secrets *secrets
+}
+
+// secrets are the values scrubbed from the messages, shared by a logger and the loggers derived from it.
+type secrets struct {
+	lock     sync.RWMutex
+	replacer *strings.Replacer
+	values   map[string]struct{}
+}
+
+var _ logger.Logger = (*Logger)(nil)
+
+// NewLogger returns a logger removing the sensitive values from the entries written to l.
+func NewLogger(l logger.Logger) *Logger {
+	if rl, ok := l.(*Logger); ok {
+		return rl
+	}
+	return &Logger{
+		Logger:  l,
+		secrets: &secrets{values: map[string]struct{}{}},
+	}
+}
+
+// AddSecrets adds values that must never be logged, such as the passwords and tokens of the component.
+func (l *Logger) AddSecrets(values ...string) {
+	l.secrets.lock.Lock()
+	defer l.secrets.lock.Unlock()
+	for _, v := range values {
+		if len(v) >= minSecretLength {
+			l.secrets.values[v] = struct{}{}
+		}
+	}
+	// Replace the longest secrets first, in case a secret contains another.
+	sorted := make([]string, 0, len(l.secrets.values))
+	for v := range l.secrets.values {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	oldnew := make([]string, 0, 2*len(sorted))
+	for _, v := range sorted {
+		oldnew = append(oldnew, v, Placeholder)
+	}
+	l.secrets.replacer = strings.NewReplacer(oldnew...)
+}
+
+// AddMetadataSecrets adds the values of the sensitive metadata properties as secrets.
+func (l *Logger) AddMetadataSecrets(properties map[string]string) {
+	values := make([]string, 0, len(properties))
+	for k, v := range properties {
+		if IsSensitiveKey(k) {
+			values = append(values, v)
+		}
+	}
+	l.AddSecrets(values...)
+}
+
+// Redact removes the sensitive values from a message.
+func (l *Logger) Redact(msg string) string {
+	l.secrets.lock.RLock()
+	replacer := l.secrets.replacer
+	l.secrets.lock.RUnlock()
+	if replacer != nil {
+		msg = replacer.Replace(msg)
+	}
+	return ConnectionString(msg)
+}
+
+// WithLogType returns a logger with the log type, removing the same sensitive values.
+func (l *Logger) WithLogType(logType string) logger.Logger {
+	return &Logger{Logger: l.Logger.WithLogType(logType), secrets: l.secrets}
+}
+
+// WithFields returns a logger with the fields, removing the same sensitive values.
+// The values of the fields with sensitive names are redacted.
+func (l *Logger) WithFields(fields map[string]any) logger.Logger {
+	redacted := make(map[string]any, len(fields))
+	for k, v := range fields {
+		switch {
+		case IsSensitiveKey(k):
+			redacted[k] = Placeholder
+		case isValue(v):
+			redacted[k] = Placeholder
+		default:
+			if s, ok := v.(string); ok {
+				v = l.Redact(s)
+			}
+			redacted[k] = v
+		}
+	}
+	return &Logger{Logger: l.Logger.WithFields(redacted), secrets: l.secrets}
+}
+
+// Info logs a message at level Info.
+func (l *Logger) Info(args ...interface{}) {
+	l.Logger.Info(l.Redact(fmt.Sprint(args...)))
+}
+
+// Infof logs a message at level Info.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Logger.Info(l.Redact(fmt.Sprintf(format, args...)))
+}
+
+// Debug logs a message at level Debug.
+func (l *Logger) Debug(args ...interface{}) {
+	if l.Logger.IsOutputLevelEnabled(logger.DebugLevel) {
+		l.Logger.Debug(l.Redact(fmt.Sprint(args...)))
+	}
+}
+
+// Debugf logs a message at level Debug.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if l.Logger.IsOutputLevelEnabled(logger.DebugLevel) {
+		l.Logger.Debug(l.Redact(fmt.Sprintf(format, args...)))
+	}
+}
+
+// Warn logs a message at level Warn.
+func (l *Logger) Warn(args ...interface{}) {
+	l.Logger.Warn(l.Redact(fmt.Sprint(args...)))
+}
+
+// Warnf logs a message at level Warn.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Logger.Warn(l.Redact(fmt.Sprintf(format, args...)))
+}
+
+// Error logs a message at level Error.
+func (l *Logger) Error(args ...interface{}) {
+	l.Logger.Error(l.Redact(fmt.Sprint(args...)))
+}
+
+// Errorf logs a message at level Error.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Logger.Error(l.Redact(fmt.Sprintf(format, args...)))
+}
+
+// Fatal logs a message at level Fatal then the process will exit with status set to 1.
+func (l *Logger) Fatal(args ...interface{}) {
+	l.Logger.Fatal(l.Redact(fmt.Sprint(args...)))
+}
+
+// Fatalf logs a message at level Fatal then the process will exit with status set to 1.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.Logger.Fatal(l.Redact(fmt.Sprintf(format, args...)))
+}
+
+func isValue(v any) bool {
+	switch v.(type) {
+	case Value, *Value:
+		return true
+	default:
+		return false
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	rediscomponent "github.com/dapr/components-contrib/internal/component/redis"
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/lock"
 	"github.com/dapr/kit/logger"
 )
@@ -78,7 +79,7 @@ func (r *StandaloneRedisLock) InitLockStore(metadata lock.Metadata) error {
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 	// 3. connect to redis
 	if _, err = r.client.PingResult(r.ctx); err != nil {
-		return fmt.Errorf("[standaloneRedisLock]: error connecting to redis at %s: %s", r.clientSettings.Host, err)
+		return fmt.Errorf("[standaloneRedisLock]: error connecting to redis at %s: %s", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 	// no replica
 	replicas, err := r.getConnectedSlaves()
@@ -0,0 +1,203 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baggage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/dapr/components-contrib/internal/baggage"
+	mdutils "github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultTokenHeader = "Authorization"
+	bearerPrefix       = "bearer "
+)
+
+// Metadata is the baggage middleware config.
+type Metadata struct {
+	// Comma-separated rules mapping the JWT claims to baggage keys, e.g. "tid=tenant,sub=user-id".
+	// Nested claims are separated by dots, e.g. "org.id=tenant".
+	Claims string `json:"claims" mapstructure:"claims"`
+	// Comma-separated rules mapping request headers to baggage keys, e.g. "X-Tenant=tenant".
+	Headers string `json:"headers" mapstructure:"headers"`
+	// Comma-separated rules writing baggage keys to correlation headers, e.g. "tenant=X-Tenant-ID".
+	CorrelationHeaders string `json:"correlationHeaders" mapstructure:"correlationHeaders"`
+	// Header the bearer JWT is read from.
+	TokenHeader string `json:"tokenHeader" mapstructure:"tokenHeader"`
+}
+
+// NewMiddleware returns a new baggage middleware.
+func NewMiddleware(logger logger.Logger) middleware.Middleware {
+	return &Middleware{logger: logger}
+}
+
+// Middleware is a baggage middleware.
+// It does not verify the JWT, it must follow a middleware authenticating the requests when the baggage is trusted.
+type Middleware struct {
+	logger logger.Logger
+}
+
+type rule struct {
+	from string
+	to   string
+}
+
+type enricher struct {
+	claims             []rule
+	headers            []rule
+	correlationHeaders []rule
+	tokenHeader        string
+	parser             *jwt.Parser
+}
+
+// GetHandler returns the HTTP handler provided by the middleware.
+func (m *Middleware) GetHandler(metadata middleware.Metadata) (func(next http.Handler) http.Handler, error) {
+	e, err := m.getEnricher(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			values := e.baggage(r)
+			if len(values) > 0 {
+				r.Header.Set(baggage.Header, baggage.Format(values))
+				for _, c := range e.correlationHeaders {
+					if v, ok := values[c.from]; ok {
+						r.Header.Set(c.to, v)
+					}
+				}
+				r = r.WithContext(baggage.WithValues(r.Context(), values))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func (m *Middleware) getEnricher(metadata middleware.Metadata) (*enricher, error) {
+	meta := Metadata{
+		TokenHeader: defaultTokenHeader,
+	}
+	err := mdutils.DecodeMetadata(metadata.Properties, &meta)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &enricher{
+		tokenHeader: meta.TokenHeader,
+		parser:      jwt.NewParser(),
+	}
+	if e.claims, err = parseRules(meta.Claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+	if e.headers, err = parseRules(meta.Headers); err != nil {
+		return nil, fmt.Errorf("invalid headers: %w", err)
+	}
+	if e.correlationHeaders, err = parseRules(meta.CorrelationHeaders); err != nil {
+		return nil, fmt.Errorf("invalid correlationHeaders: %w", err)
+	}
+	if len(e.claims) == 0 && len(e.headers) == 0 {
+		return nil, errors.New("metadata property claims or headers is required")
+	}
+
+	return e, nil
+}
+
+// baggage returns the baggage of the request: the incoming baggage, overridden by the mapped headers,
+// overridden by the mapped claims.
+func (e *enricher) baggage(r *http.Request) map[string]string {
+	values := baggage.Parse(r.Header.Get(baggage.Header))
+
+	for _, h := range e.headers {
+		if v := strings.TrimSpace(r.Header.Get(h.from)); v != "" {
+			values[h.to] = v
+		}
+	}
+
+	if len(e.claims) > 0 {
+		claims := e.tokenClaims(r.Header.Get(e.tokenHeader))
+		for _, c := range e.claims {
+			if v, ok := claimValue(claims, c.from); ok {
+				values[c.to] = v
+			}
+		}
+	}
+
+	return values
+}
+
+// tokenClaims returns the claims of the bearer token, without verifying it.
+func (e *enricher) tokenClaims(header string) jwt.MapClaims {
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return nil
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := e.parser.ParseUnverified(strings.TrimSpace(header[len(bearerPrefix):]), claims); err != nil {
+		return nil
+	}
+
+	return claims
+}
+
+// claimValue returns the string value of a scalar claim, following the dots of the name in nested claims.
+func claimValue(claims map[string]interface{}, name string) (string, bool) {
+	var val interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if val, ok = obj[part]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := val.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+func parseRules(s string) ([]rule, error) {
+	var res []rule
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(r, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid rule %q: expected from=to", r)
+		}
+		res = append(res, rule{from: from, to: to})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baggage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/internal/baggage"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/kit/logger"
+)
+
+func TestBaggageMiddleware(t *testing.T) {
+	meta := middleware.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"claims":             "tid=tenant, sub=user-id, org.plan=plan",
+		"headers":            "X-Tenant=tenant,X-Request-Source=source",
+		"correlationHeaders": "tenant=X-Tenant-ID,user-id=X-User-ID",
+	}}}
+	handler, err := NewMiddleware(logger.NewLogger("baggage.test")).GetHandler(meta)
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"tid": "contoso",
+		"sub": "alice",
+		"org": map[string]interface{}{"plan": "gold"},
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	serve := func(headers map[string]string) (http.Header, context.Context) {
+		var (
+			got http.Header
+			ctx context.Context
+		)
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:5001/v1.0/invoke/app/method/x", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ctx = r.Header, r.Context()
+		})).ServeHTTP(w, r)
+		return got, ctx
+	}
+
+	t.Run("claims override headers and incoming baggage", func(t *testing.T) {
+		h, ctx := serve(map[string]string{
+			"Authorization":    "Bearer " + token,
+			"X-Tenant":         "fabrikam",
+			"X-Request-Source": "mobile",
+			"Baggage":          "tenant=other,session=abc%20def;prop=1",
+		})
+		expected := map[string]string{
+			"tenant":  "contoso",
+			"user-id": "alice",
+			"plan":    "gold",
+			"source":  "mobile",
+			"session": "abc def",
+		}
+		assert.Equal(t, expected, baggage.Parse(h.Get(baggage.Header)))
+		assert.Equal(t, expected, baggage.FromContext(ctx))
+		assert.Equal(t, "contoso", h.Get("X-Tenant-ID"))
+		assert.Equal(t, "alice", h.Get("X-User-ID"))
+	})
+
+	t.Run("headers without token", func(t *testing.T) {
+		h, ctx := serve(map[string]string{
+			"Authorization": "Bearer not-a-jwt",
+			"X-Tenant":      "fabrikam",
+		})
+		assert.Equal(t, "tenant=fabrikam", h.Get(baggage.Header))
+		assert.Equal(t, map[string]string{"tenant": "fabrikam"}, baggage.FromContext(ctx))
+		assert.Equal(t, "fabrikam", h.Get("X-Tenant-ID"))
+		assert.Empty(t, h.Get("X-User-ID"))
+	})
+
+	t.Run("no baggage", func(t *testing.T) {
+		h, ctx := serve(nil)
+		assert.Empty(t, h.Get(baggage.Header))
+		assert.Nil(t, baggage.FromContext(ctx))
+	})
+}
+
+func TestBaggageMiddlewareMetadata(t *testing.T) {
+	m := NewMiddleware(logger.NewLogger("baggage.test"))
+
+	_, err := m.GetHandler(middleware.Metadata{Base: metadata.Base{Properties: map[string]string{}}})
+	assert.Error(t, err)
+
+	_, err = m.GetHandler(middleware.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"claims": "sub",
+	}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	mdutils "github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultLocaleHeader    = "X-Locale"
+	defaultTimezoneHeader  = "X-Timezone"
+	defaultTimezoneSources = "Time-Zone,X-Timezone"
+)
+
+// Metadata is the locale middleware config.
+type Metadata struct {
+	// Comma-separated locales the downstream services support; the first one is the default.
+	SupportedLocales string `json:"supportedLocales" mapstructure:"supportedLocales"`
+	// Comma-separated rules rewriting requested locales before matching, e.g. "pt=pt-BR,zh-TW=zh-Hant".
+	LocaleMappings string `json:"localeMappings" mapstructure:"localeMappings"`
+	// Header the negotiated locale is written to.
+	LocaleHeader string `json:"localeHeader" mapstructure:"localeHeader"`
+	// Comma-separated headers the client timezone is read from, in order of precedence.
+	TimezoneSourceHeaders string `json:"timezoneSourceHeaders" mapstructure:"timezoneSourceHeaders"`
+	// Timezone used when the client sends none or an unknown one.
+	DefaultTimezone string `json:"defaultTimezone" mapstructure:"defaultTimezone"`
+	// Header the canonical IANA timezone name is written to.
+	TimezoneHeader string `json:"timezoneHeader" mapstructure:"timezoneHeader"`
+}
+
+// NewMiddleware returns a new locale middleware.
+func NewMiddleware(logger logger.Logger) middleware.Middleware {
+	return &Middleware{logger: logger}
+}
+
+// Middleware is a locale middleware.
+type Middleware struct {
+	logger logger.Logger
+}
+
+type negotiator struct {
+	supported       []language.Tag
+	matcher         language.Matcher
+	mappings        map[string]language.Tag
+	localeHeader    string
+	timezoneSources []string
+	defaultTimezone string
+	timezoneHeader  string
+}
+
+// GetHandler returns the HTTP handler provided by the middleware.
+func (m *Middleware) GetHandler(metadata middleware.Metadata) (func(next http.Handler) http.Handler, error) {
+	n, err := m.getNegotiator(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set(n.localeHeader, n.locale(r.Header.Get("Accept-Language")))
+			r.Header.Set(n.timezoneHeader, n.timezone(r.Header))
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func (m *Middleware) getNegotiator(metadata middleware.Metadata) (*negotiator, error) {
+	meta := Metadata{
+		LocaleHeader:          defaultLocaleHeader,
+		TimezoneSourceHeaders: defaultTimezoneSources,
+		DefaultTimezone:       "UTC",
+		TimezoneHeader:        defaultTimezoneHeader,
+	}
+	err := mdutils.DecodeMetadata(metadata.Properties, &meta)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &negotiator{
+		mappings:        map[string]language.Tag{},
+		localeHeader:    meta.LocaleHeader,
+		timezoneSources: splitList(meta.TimezoneSourceHeaders),
+		timezoneHeader:  meta.TimezoneHeader,
+	}
+	for _, s := range splitList(meta.SupportedLocales) {
+		tag, err := language.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supported locale %q: %w", s, err)
+		}
+		n.supported = append(n.supported, tag)
+	}
+	if len(n.supported) == 0 {
+		return nil, errors.New("metadata property supportedLocales is required")
+	}
+	n.matcher = language.NewMatcher(n.supported)
+
+	for _, rule := range splitList(meta.LocaleMappings) {
+		from, to, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid locale mapping %q: expected from=to", rule)
+		}
+		fromTag, err := language.Parse(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale mapping %q: %w", rule, err)
+		}
+		toTag, err := language.Parse(strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale mapping %q: %w", rule, err)
+		}
+		n.mappings[fromTag.String()] = toTag
+	}
+
+	loc, err := time.LoadLocation(meta.DefaultTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default timezone %q: %w", meta.DefaultTimezone, err)
+	}
+	n.defaultTimezone = loc.String()
+
+	return n, nil
+}
+
+// locale returns the supported locale best matching the Accept-Language header, after applying the mapping rules.
+func (n *negotiator) locale(acceptLanguage string) string {
+	requested, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(requested) == 0 {
+		return n.supported[0].String()
+	}
+
+	for i, tag := range requested {
+		if mapped, ok := n.mappings[tag.String()]; ok {
+			requested[i] = mapped
+			continue
+		}
+		if base, conf := tag.Base(); conf != language.No {
+			if mapped, ok := n.mappings[base.String()]; ok {
+				requested[i] = mapped
+			}
+		}
+	}
+
+	_, idx, _ := n.matcher.Match(requested...)
+	return n.supported[idx].String()
+}
+
+// timezone returns the canonical name of the first valid timezone found in the source headers.
+func (n *negotiator) timezone(header http.Header) string {
+	for _, h := range n.timezoneSources {
+		name := strings.TrimSpace(header.Get(h))
+		if name == "" {
+			continue
+		}
+		// time.LoadLocation treats "" and "Local" specially, neither is a client timezone.
+		if strings.EqualFold(name, "local") {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc.String()
+		}
+	}
+
+	return n.defaultTimezone
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
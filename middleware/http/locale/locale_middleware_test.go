@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package locale
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/kit/logger"
+)
+
+func TestLocaleMiddleware(t *testing.T) {
+	meta := middleware.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"supportedLocales": "en-US, fr-FR, pt-BR, zh-Hant",
+		"localeMappings":   "pt=pt-BR,zh-TW=zh-Hant",
+		"defaultTimezone":  "Europe/Paris",
+	}}}
+	handler, err := NewMiddleware(logger.NewLogger("locale.test")).GetHandler(meta)
+	require.NoError(t, err)
+
+	serve := func(headers map[string]string) http.Header {
+		var got http.Header
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:5001/v1.0/invoke/app/method/x", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header
+		})).ServeHTTP(w, r)
+		return got
+	}
+
+	tests := []struct {
+		name             string
+		headers          map[string]string
+		expectedLocale   string
+		expectedTimezone string
+	}{
+		{"defaults", nil, "en-US", "Europe/Paris"},
+		{"weighted match", map[string]string{"Accept-Language": "de;q=0.9, fr-CA;q=0.8"}, "fr-FR", "Europe/Paris"},
+		{"base language mapping", map[string]string{"Accept-Language": "pt-PT"}, "pt-BR", "Europe/Paris"},
+		{"exact mapping", map[string]string{"Accept-Language": "zh-TW"}, "zh-Hant", "Europe/Paris"},
+		{"malformed header", map[string]string{"Accept-Language": "@@@"}, "en-US", "Europe/Paris"},
+		{"timezone", map[string]string{"Time-Zone": "America/New_York"}, "en-US", "America/New_York"},
+		{"invalid timezone falls through", map[string]string{"Time-Zone": "Mars/Base", "X-Timezone": "Asia/Tokyo"}, "en-US", "Asia/Tokyo"},
+		{"local timezone ignored", map[string]string{"Time-Zone": "Local"}, "en-US", "Europe/Paris"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := serve(tt.headers)
+			assert.Equal(t, tt.expectedLocale, h.Get(defaultLocaleHeader))
+			assert.Equal(t, tt.expectedTimezone, h.Get(defaultTimezoneHeader))
+		})
+	}
+}
+
+func TestLocaleMiddlewareMetadata(t *testing.T) {
+	m := NewMiddleware(logger.NewLogger("locale.test"))
+	for name, props := range map[string]map[string]string{
+		"no supported locales": {},
+		"invalid locale":       {"supportedLocales": "en-US,???"},
+		"invalid mapping":      {"supportedLocales": "en-US", "localeMappings": "pt"},
+		"invalid timezone":     {"supportedLocales": "en-US", "defaultTimezone": "Mars/Base"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.GetHandler(middleware.Metadata{Base: metadata.Base{Properties: props}})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -36,6 +36,8 @@ type intermediateConfig struct {
 	AdvancedRegistration *AgentServiceRegistration // advanced use-case
 	SelfRegister         bool
 	DaprPortMetaKey      string
+	UseCache             bool
+	Locality             map[string]string
 }
 
 type configSpec struct {
@@ -47,6 +49,8 @@ type configSpec struct {
 	AdvancedRegistration *consul.AgentServiceRegistration // advanced use-case
 	SelfRegister         bool
 	DaprPortMetaKey      string
+	UseCache             bool
+	Locality             map[string]string
 }
 
 func parseConfig(rawConfig interface{}) (configSpec, error) {
@@ -84,6 +88,8 @@ func mapConfig(config intermediateConfig) configSpec {
 		AdvancedRegistration: mapAdvancedRegistration(config.AdvancedRegistration),
 		SelfRegister:         config.SelfRegister,
 		DaprPortMetaKey:      config.DaprPortMetaKey,
+		UseCache:             config.UseCache,
+		Locality:             config.Locality,
 	}
 }
 
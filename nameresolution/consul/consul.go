@@ -14,11 +14,11 @@ limitations under the License.
 package consul
 
 import (
-	"crypto/rand"
+	"context"
 	"fmt"
-	"math/big"
 	"net"
 	"strconv"
+	"sync"
 
 	consul "github.com/hashicorp/consul/api"
 
@@ -67,9 +67,13 @@ type healthInterface interface {
 }
 
 type resolver struct {
-	config resolverConfig
-	logger logger.Logger
-	client clientInterface
+	config   resolverConfig
+	logger   logger.Logger
+	client   clientInterface
+	registry *registry
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
 }
 
 type resolverConfig struct {
@@ -77,6 +81,8 @@ type resolverConfig struct {
 	QueryOptions    *consul.QueryOptions
 	Registration    *consul.AgentServiceRegistration
 	DaprPortMetaKey string
+	UseCache        bool
+	Locality        map[string]string
 }
 
 // NewResolver creates Consul name resolver.
@@ -85,10 +91,14 @@ func NewResolver(logger logger.Logger) nr.Resolver {
 }
 
 func newResolver(logger logger.Logger, resolverConfig resolverConfig, client clientInterface) nr.Resolver {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &resolver{
-		logger: logger,
-		config: resolverConfig,
-		client: client,
+		logger:   logger,
+		config:   resolverConfig,
+		client:   client,
+		registry: newRegistry(),
+		ctx:      ctx,
+		cancel:   cancel,
 	}
 }
 
@@ -120,45 +130,92 @@ func (r *resolver) Init(metadata nr.Metadata) error {
 }
 
 // ResolveID resolves name to address via consul.
+// The address is picked according to the weights of the services, preferring the services in the locality of the resolver.
 func (r *resolver) ResolveID(req nr.ResolveRequest) (string, error) {
-	cfg := r.config
-	services, _, err := r.client.Health().Service(req.ID, "", true, cfg.QueryOptions)
+	endpoints, err := r.ResolveIDMulti(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to query healthy consul services: %w", err)
+		return "", err
 	}
 
-	if len(services) == 0 {
-		return "", fmt.Errorf("no healthy services found with AppID:%s", req.ID)
+	endpoint, err := nr.SelectEndpoint(endpoints, r.config.Locality)
+	if err != nil {
+		return "", fmt.Errorf("failed to select a service with AppID:%s: %w", req.ID, err)
 	}
 
-	shuffle := func(services []*consul.ServiceEntry) []*consul.ServiceEntry {
-		for i := len(services) - 1; i > 0; i-- {
-			rndbig, _ := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
-			j := rndbig.Int64()
+	return endpoint.Address, nil
+}
 
-			services[i], services[j] = services[j], services[i]
+// ResolveIDMulti resolves name to the addresses of all the healthy services via consul.
+// The weights are the passing or warning weights of the services, and the locality is read from the zone and region
+// keys of the service meta, or of the node meta.
+func (r *resolver) ResolveIDMulti(req nr.ResolveRequest) ([]nr.Endpoint, error) {
+	services, err := r.getServices(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no healthy services found with AppID:%s", req.ID)
+	}
+
+	endpoints := make([]nr.Endpoint, 0, len(services))
+	for _, svc := range services {
+		endpoint, epErr := r.getEndpoint(req.ID, svc)
+		if epErr != nil {
+			// skip the services which cannot be resolved
+			err = epErr
+			continue
 		}
+		endpoints = append(endpoints, endpoint)
+	}
 
-		return services
+	if len(endpoints) == 0 {
+		return nil, err
 	}
 
-	svc := shuffle(services)[0]
+	return endpoints, nil
+}
 
-	addr := ""
+func (r *resolver) getEndpoint(appID string, svc *consul.ServiceEntry) (nr.Endpoint, error) {
+	port, ok := svc.Service.Meta[r.config.DaprPortMetaKey]
+	if !ok {
+		return nr.Endpoint{}, fmt.Errorf("target service AppID:%s found but DAPR_PORT missing from meta", appID)
+	}
 
-	if port, ok := svc.Service.Meta[cfg.DaprPortMetaKey]; ok {
-		if svc.Service.Address != "" {
-			addr = fmt.Sprintf("%s:%s", svc.Service.Address, port)
-		} else if svc.Node.Address != "" {
-			addr = fmt.Sprintf("%s:%s", svc.Node.Address, port)
-		} else {
-			return "", fmt.Errorf("no healthy services found with AppID:%s", req.ID)
-		}
+	endpoint := nr.Endpoint{
+		Weight:   svc.Service.Weights.Passing,
+		Locality: map[string]string{},
+	}
+
+	if svc.Service.Address != "" {
+		endpoint.Address = fmt.Sprintf("%s:%s", svc.Service.Address, port)
+	} else if svc.Node != nil && svc.Node.Address != "" {
+		endpoint.Address = fmt.Sprintf("%s:%s", svc.Node.Address, port)
 	} else {
-		return "", fmt.Errorf("target service AppID:%s found but DAPR_PORT missing from meta", req.ID)
+		return nr.Endpoint{}, fmt.Errorf("no healthy services found with AppID:%s", appID)
+	}
+
+	if svc.Checks.AggregatedStatus() == consul.HealthWarning {
+		endpoint.Weight = svc.Service.Weights.Warning
+	}
+
+	for _, label := range []string{nr.LocalityZone, nr.LocalityRegion} {
+		if value := svc.Service.Meta[label]; value != "" {
+			endpoint.Locality[label] = value
+		} else if svc.Node != nil && svc.Node.Meta[label] != "" {
+			endpoint.Locality[label] = svc.Node.Meta[label]
+		}
 	}
 
-	return addr, nil
+	return endpoint, nil
+}
+
+// Close stops watching the cached services.
+func (r *resolver) Close() error {
+	r.cancel()
+	r.wg.Wait()
+
+	return nil
 }
 
 // getConfig configuration from metadata, defaults are best suited for self-hosted mode.
@@ -191,14 +248,22 @@ func getConfig(metadata nr.Metadata) (resolverConfig, error) {
 		return resolverCfg, err
 	}
 	resolverCfg.QueryOptions = getQueryOptionsConfig(cfg)
+	resolverCfg.UseCache = cfg.UseCache
+	resolverCfg.Locality = cfg.Locality
 
-	// if registering, set DaprPort in meta, needed for resolution
+	// if registering, set DaprPort and the locality in meta, needed for resolution
 	if resolverCfg.Registration != nil {
 		if resolverCfg.Registration.Meta == nil {
 			resolverCfg.Registration.Meta = map[string]string{}
 		}
 
 		resolverCfg.Registration.Meta[resolverCfg.DaprPortMetaKey] = daprPort
+
+		for label, value := range resolverCfg.Locality {
+			if _, ok := resolverCfg.Registration.Meta[label]; !ok {
+				resolverCfg.Registration.Meta[label] = value
+			}
+		}
 	}
 
 	return resolverCfg, nil
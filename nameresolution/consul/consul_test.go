@@ -14,10 +14,13 @@ limitations under the License.
 package consul
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 	"github.com/stretchr/testify/assert"
@@ -296,6 +299,103 @@ func TestResolveID(t *testing.T) {
 				assert.Error(t, err)
 			},
 		},
+		{
+			"should prefer services in the same zone",
+			nr.ResolveRequest{
+				ID: "test-app",
+			},
+			func(t *testing.T, req nr.ResolveRequest) {
+				t.Helper()
+				mock := mockClient{
+					mockHealth: mockHealth{
+						serviceResult: []*consul.ServiceEntry{
+							{
+								Service: &consul.AgentService{
+									Address: "10.0.0.1",
+									Meta: map[string]string{
+										"DAPR_PORT": "50005",
+										"zone":      "zone-a",
+									},
+								},
+							},
+							{
+								Node: &consul.Node{
+									Meta: map[string]string{
+										"zone": "zone-b",
+									},
+								},
+								Service: &consul.AgentService{
+									Address: "10.0.0.2",
+									Meta: map[string]string{
+										"DAPR_PORT": "50005",
+									},
+								},
+							},
+						},
+					},
+				}
+				cfg := *testConfig
+				cfg.Locality = map[string]string{nr.LocalityZone: "zone-b"}
+				resolver := newResolver(logger.NewLogger("test"), cfg, &mock)
+
+				for i := 0; i < 10; i++ {
+					addr, err := resolver.ResolveID(req)
+					assert.NoError(t, err)
+					assert.Equal(t, "10.0.0.2:50005", addr)
+				}
+			},
+		},
+		{
+			"should return weighted endpoints",
+			nr.ResolveRequest{
+				ID: "test-app",
+			},
+			func(t *testing.T, req nr.ResolveRequest) {
+				t.Helper()
+				mock := mockClient{
+					mockHealth: mockHealth{
+						serviceResult: []*consul.ServiceEntry{
+							{
+								Service: &consul.AgentService{
+									Address: "10.0.0.1",
+									Meta: map[string]string{
+										"DAPR_PORT": "50005",
+										"region":    "eu",
+									},
+									Weights: consul.AgentWeights{Passing: 10, Warning: 1},
+								},
+							},
+							{
+								Service: &consul.AgentService{
+									Address: "10.0.0.2",
+									Meta: map[string]string{
+										"DAPR_PORT": "50005",
+									},
+									Weights: consul.AgentWeights{Passing: 10, Warning: 2},
+								},
+								Checks: consul.HealthChecks{
+									{Status: consul.HealthWarning},
+								},
+							},
+							{
+								Service: &consul.AgentService{
+									Address: "10.0.0.3",
+								},
+							},
+						},
+					},
+				}
+				resolver := newResolver(logger.NewLogger("test"), *testConfig, &mock)
+
+				endpoints, err := resolver.(nr.MultiResolver).ResolveIDMulti(req)
+
+				assert.NoError(t, err)
+				assert.Equal(t, []nr.Endpoint{
+					{Address: "10.0.0.1:50005", Weight: 10, Locality: map[string]string{nr.LocalityRegion: "eu"}},
+					{Address: "10.0.0.2:50005", Weight: 2, Locality: map[string]string{}},
+				}, endpoints)
+			},
+		},
 		{
 			"error if consul service missing DaprPortMetaKey",
 			nr.ResolveRequest{
@@ -333,6 +433,136 @@ func TestResolveID(t *testing.T) {
 	}
 }
 
+func TestResolveIDWithCache(t *testing.T) {
+	t.Parallel()
+
+	newEntry := func(address string) *consul.ServiceEntry {
+		return &consul.ServiceEntry{
+			Service: &consul.AgentService{
+				Address: address,
+				Meta: map[string]string{
+					"DAPR_PORT": "50005",
+				},
+			},
+		}
+	}
+
+	health := &watchHealth{
+		index:    1,
+		services: []*consul.ServiceEntry{newEntry("10.0.0.1")},
+		changed:  make(chan struct{}),
+	}
+	mock := &watchClient{health: health}
+	resolver := newResolver(logger.NewLogger("test"), resolverConfig{
+		DaprPortMetaKey: "DAPR_PORT",
+		QueryOptions:    &consul.QueryOptions{},
+		UseCache:        true,
+	}, mock).(*resolver)
+	defer resolver.Close()
+
+	req := nr.ResolveRequest{ID: "test-app"}
+	addr, err := resolver.ResolveID(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:50005", addr)
+
+	// Resolutions are served from the cache while the watch is blocked
+	addr, err = resolver.ResolveID(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:50005", addr)
+	assert.Equal(t, 1, health.directCalls())
+
+	// A change of the healthy services updates the cache
+	health.update([]*consul.ServiceEntry{newEntry("10.0.0.2")})
+	assert.Eventually(t, func() bool {
+		addr, err = resolver.ResolveID(req)
+		return err == nil && addr == "10.0.0.2:50005"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, health.directCalls())
+
+	// A failed watch evicts the app from the cache
+	health.fail(errors.New("unavailable"))
+	assert.Eventually(t, func() bool {
+		_, ok := resolver.registry.get(req.ID)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+type watchClient struct {
+	mockAgent
+	health *watchHealth
+}
+
+func (m *watchClient) InitClient(config *consul.Config) error {
+	return nil
+}
+
+func (m *watchClient) Health() healthInterface {
+	return m.health
+}
+
+func (m *watchClient) Agent() agentInterface {
+	return &m.mockAgent
+}
+
+// watchHealth emulates blocking queries, which return when the index changes.
+type watchHealth struct {
+	lock     sync.Mutex
+	index    uint64
+	services []*consul.ServiceEntry
+	err      error
+	changed  chan struct{}
+	direct   int
+}
+
+func (m *watchHealth) Service(service, tag string, passingOnly bool, q *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
+	m.lock.Lock()
+	if q.WaitIndex == 0 {
+		m.direct++
+	}
+	for m.err == nil && q.WaitIndex != 0 && q.WaitIndex == m.index {
+		changed := m.changed
+		m.lock.Unlock()
+		select {
+		case <-changed:
+		case <-q.Context().Done():
+			return nil, nil, q.Context().Err()
+		}
+		m.lock.Lock()
+	}
+	defer m.lock.Unlock()
+
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+	return m.services, &consul.QueryMeta{LastIndex: m.index}, nil
+}
+
+func (m *watchHealth) update(services []*consul.ServiceEntry) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.index++
+	m.services = services
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func (m *watchHealth) fail(err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.err = err
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func (m *watchHealth) directCalls() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.direct
+}
+
 func TestParseConfig(t *testing.T) {
 	t.Parallel()
 
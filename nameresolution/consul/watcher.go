@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consul
+
+import (
+	"fmt"
+	"sync"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// registry caches the healthy services of the resolved apps.
+type registry struct {
+	lock     sync.RWMutex
+	services map[string][]*consul.ServiceEntry
+}
+
+func newRegistry() *registry {
+	return &registry{
+		services: make(map[string][]*consul.ServiceEntry),
+	}
+}
+
+// get returns a copy of the cached services of an app, which callers are free to reorder.
+func (r *registry) get(appID string) ([]*consul.ServiceEntry, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	services, ok := r.services[appID]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]*consul.ServiceEntry(nil), services...), true
+}
+
+// addOrUpdate caches the services of an app, and reports whether the app was not cached yet.
+func (r *registry) addOrUpdate(appID string, services []*consul.ServiceEntry) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, ok := r.services[appID]
+	r.services[appID] = services
+
+	return !ok
+}
+
+func (r *registry) remove(appID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.services, appID)
+}
+
+// getServices returns the healthy services of an app.
+// When the cache is enabled, the services of an app are queried once and then kept up to date by a watch.
+func (r *resolver) getServices(appID string) ([]*consul.ServiceEntry, error) {
+	if r.config.UseCache {
+		if services, ok := r.registry.get(appID); ok {
+			return services, nil
+		}
+	}
+
+	services, meta, err := r.client.Health().Service(appID, "", true, r.config.QueryOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query healthy consul services: %w", err)
+	}
+
+	if r.config.UseCache && meta != nil && r.ctx.Err() == nil {
+		if r.registry.addOrUpdate(appID, services) {
+			r.wg.Add(1)
+			go r.watch(appID, meta.LastIndex)
+		}
+
+		services = append([]*consul.ServiceEntry(nil), services...)
+	}
+
+	return services, nil
+}
+
+// watch keeps the cached services of an app up to date with blocking queries.
+// When a query fails the app is evicted from the cache, so that it is queried again on the next resolution.
+func (r *resolver) watch(appID string, index uint64) {
+	defer r.wg.Done()
+
+	for {
+		var opts consul.QueryOptions
+		if r.config.QueryOptions != nil {
+			opts = *r.config.QueryOptions
+		}
+		opts.WaitIndex = index
+
+		services, meta, err := r.client.Health().Service(appID, "", true, opts.WithContext(r.ctx))
+		if r.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.logger.Warnf("failed to watch consul services of AppID:%s, evicting from cache: %v", appID, err)
+			r.registry.remove(appID)
+			return
+		}
+
+		switch {
+		case meta.LastIndex < index:
+			// The index went backwards, e.g. after a restore: start again from the current state
+			index = 0
+		case meta.LastIndex == index:
+			// The blocking query timed out without changes
+			continue
+		default:
+			index = meta.LastIndex
+		}
+
+		r.logger.Debugf("consul services of AppID:%s changed, %d healthy instances", appID, len(services))
+		r.registry.addOrUpdate(appID, services)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nameresolution
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+const (
+	// LocalityRegion is the locality label of the region of an endpoint.
+	LocalityRegion string = "region"
+	// LocalityZone is the locality label of the zone of an endpoint.
+	LocalityZone string = "zone"
+)
+
+// ErrNoEndpoints is returned when there is no endpoint to select.
+var ErrNoEndpoints = errors.New("no endpoints")
+
+// Endpoint is one of the addresses an ID resolves to.
+type Endpoint struct {
+	// Address is the address of the endpoint, in the host:port format.
+	Address string
+	// Weight is the share of the traffic sent to the endpoint, relative to the other endpoints.
+	// Endpoints without a positive weight have a weight of 1.
+	Weight int
+	// Locality contains the labels locating the endpoint, such as its region and zone.
+	Locality map[string]string
+}
+
+// MultiResolver is the interface of the name resolvers which can resolve a name to all its endpoints.
+type MultiResolver interface {
+	Resolver
+	// ResolveIDMulti resolves name to weighted endpoints.
+	ResolveIDMulti(req ResolveRequest) ([]Endpoint, error)
+}
+
+// SelectEndpoint picks one of the endpoints at random in proportion to their weights.
+// The endpoints in the same zone as the locality are preferred, then the endpoints in the same region.
+func SelectEndpoint(endpoints []Endpoint, locality map[string]string) (Endpoint, error) {
+	if len(endpoints) == 0 {
+		return Endpoint{}, ErrNoEndpoints
+	}
+
+	candidates := endpoints
+	for _, label := range []string{LocalityZone, LocalityRegion} {
+		if local := inLocality(endpoints, label, locality[label]); len(local) > 0 {
+			candidates = local
+			break
+		}
+	}
+
+	total := int64(0)
+	for _, e := range candidates {
+		total += int64(weight(e))
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(total))
+	if err != nil {
+		return Endpoint{}, err
+	}
+	pick := n.Int64()
+	for _, e := range candidates {
+		pick -= int64(weight(e))
+		if pick < 0 {
+			return e, nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// inLocality returns the endpoints whose locality label has the value, if the value is set.
+func inLocality(endpoints []Endpoint, label, value string) []Endpoint {
+	if value == "" {
+		return nil
+	}
+	var res []Endpoint
+	for _, e := range endpoints {
+		if e.Locality[label] == value {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func weight(e Endpoint) int {
+	if e.Weight < 1 {
+		return 1
+	}
+	return e.Weight
+}
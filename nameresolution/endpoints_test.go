@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nameresolution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectEndpoint(t *testing.T) {
+	endpoints := []Endpoint{
+		{Address: "a:1", Locality: map[string]string{LocalityRegion: "r1", LocalityZone: "z1"}},
+		{Address: "b:1", Locality: map[string]string{LocalityRegion: "r1", LocalityZone: "z2"}},
+		{Address: "c:1", Locality: map[string]string{LocalityRegion: "r2", LocalityZone: "z3"}},
+	}
+
+	t.Run("no endpoints", func(t *testing.T) {
+		_, err := SelectEndpoint(nil, nil)
+		assert.ErrorIs(t, err, ErrNoEndpoints)
+	})
+
+	t.Run("prefers the same zone", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			e, err := SelectEndpoint(endpoints, map[string]string{LocalityRegion: "r1", LocalityZone: "z2"})
+			require.NoError(t, err)
+			assert.Equal(t, "b:1", e.Address)
+		}
+	})
+
+	t.Run("falls back to the same region", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			e, err := SelectEndpoint(endpoints, map[string]string{LocalityRegion: "r2", LocalityZone: "z4"})
+			require.NoError(t, err)
+			assert.Equal(t, "c:1", e.Address)
+		}
+	})
+
+	t.Run("falls back to all the endpoints", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i := 0; i < 200; i++ {
+			e, err := SelectEndpoint(endpoints, map[string]string{LocalityRegion: "r3"})
+			require.NoError(t, err)
+			seen[e.Address] = true
+		}
+		assert.Len(t, seen, 3)
+	})
+
+	t.Run("follows the weights", func(t *testing.T) {
+		weighted := []Endpoint{
+			{Address: "a:1", Weight: 99},
+			{Address: "b:1", Weight: 1},
+		}
+		counts := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			e, err := SelectEndpoint(weighted, nil)
+			require.NoError(t, err)
+			counts[e.Address]++
+		}
+		assert.Greater(t, counts["a:1"], 900)
+	})
+}
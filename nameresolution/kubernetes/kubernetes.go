@@ -15,7 +15,15 @@ package kubernetes
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
 
+	"k8s.io/client-go/kubernetes"
+
+	kubeclient "github.com/dapr/components-contrib/internal/authentication/kubernetes"
+	"github.com/dapr/components-contrib/internal/utils"
 	"github.com/dapr/components-contrib/nameresolution"
 	"github.com/dapr/kit/config"
 	"github.com/dapr/kit/logger"
@@ -24,22 +32,39 @@ import (
 const (
 	DefaultClusterDomain = "cluster.local"
 	ClusterDomainKey     = "clusterDomain"
+	LocalityAwareKey     = "localityAware"
+	ZoneKey              = "zone"
+
+	listTimeout = 5 * time.Second
 )
 
 type resolver struct {
 	logger        logger.Logger
 	clusterDomain string
+	localityAware bool
+	locality      map[string]string
+	kubeClient    kubernetes.Interface
+
+	lock     sync.Mutex
+	watchers map[serviceKey]*serviceWatcher
+	closed   bool
+	wg       sync.WaitGroup
 }
 
+var _ nameresolution.MultiResolver = (*resolver)(nil)
+
 // NewResolver creates Kubernetes name resolver.
 func NewResolver(logger logger.Logger) nameresolution.Resolver {
 	return &resolver{
 		logger:        logger,
 		clusterDomain: DefaultClusterDomain,
+		watchers:      map[serviceKey]*serviceWatcher{},
 	}
 }
 
 // Init initializes Kubernetes name resolver.
+// When locality aware, the resolver lists and watches the endpoint slices of the resolved services, which requires the
+// "list" and "watch" verbs on the "endpointslices" resource of the "discovery.k8s.io" API group in their namespaces.
 func (k *resolver) Init(metadata nameresolution.Metadata) error {
 	configInterface, err := config.Normalize(metadata.Configuration)
 	if err != nil {
@@ -53,13 +78,94 @@ func (k *resolver) Init(metadata nameresolution.Metadata) error {
 				k.clusterDomain = clusterDomain
 			}
 		}
+		if localityAware, ok := config[LocalityAwareKey]; ok {
+			k.localityAware = utils.IsTruthy(fmt.Sprint(localityAware))
+		}
+		if zone, _ := config[ZoneKey].(string); zone != "" {
+			k.locality = map[string]string{nameresolution.LocalityZone: zone}
+		}
+	}
+
+	if k.watchers == nil {
+		k.watchers = map[serviceKey]*serviceWatcher{}
+	}
+	if k.localityAware && k.kubeClient == nil {
+		k.kubeClient, err = kubeclient.GetKubeClient()
+		if err != nil {
+			return fmt.Errorf("failed to create the kubernetes client: %w", err)
+		}
 	}
 
 	return nil
 }
 
 // ResolveID resolves name to address in Kubernetes.
+// When locality aware, it resolves name to the address of one of the ready pods, preferring the pods in the same zone.
 func (k *resolver) ResolveID(req nameresolution.ResolveRequest) (string, error) {
+	if k.localityAware {
+		endpoints, err := k.ResolveIDMulti(req)
+		if err != nil {
+			k.logger.Warnf("failed to resolve the endpoints of %s, falling back to the service address: %v", req.ID, err)
+		} else if endpoint, err := nameresolution.SelectEndpoint(endpoints, k.locality); err == nil {
+			return endpoint.Address, nil
+		}
+	}
+
+	return k.serviceAddress(req), nil
+}
+
+// ResolveIDMulti resolves name to the addresses of the ready pods of the Dapr service in Kubernetes, with their zones.
+// The endpoint slices of the service are cached and kept up to date with a watch.
+// When not locality aware, it resolves name to the address of the service.
+func (k *resolver) ResolveIDMulti(req nameresolution.ResolveRequest) ([]nameresolution.Endpoint, error) {
+	if !k.localityAware {
+		return []nameresolution.Endpoint{{Address: k.serviceAddress(req), Weight: 1}}, nil
+	}
+
+	slices, err := k.getEndpointSlices(serviceKey{namespace: req.Namespace, id: req.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	port := strconv.Itoa(req.Port)
+	endpoints := []nameresolution.Endpoint{}
+	for _, slice := range slices {
+		for _, e := range slice.Endpoints {
+			if e.Conditions.Ready != nil && !*e.Conditions.Ready {
+				continue
+			}
+			locality := map[string]string{}
+			if e.Zone != nil {
+				locality[nameresolution.LocalityZone] = *e.Zone
+			}
+			for _, addr := range e.Addresses {
+				endpoints = append(endpoints, nameresolution.Endpoint{
+					Address:  net.JoinHostPort(addr, port),
+					Weight:   1,
+					Locality: locality,
+				})
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+// Close stops watching the endpoint slices of the resolved services.
+func (k *resolver) Close() error {
+	k.lock.Lock()
+	k.closed = true
+	for key, w := range k.watchers {
+		delete(k.watchers, key)
+		close(w.stop)
+	}
+	k.lock.Unlock()
+
+	k.wg.Wait()
+	return nil
+}
+
+func (k *resolver) serviceAddress(req nameresolution.ResolveRequest) string {
 	// Dapr requires this formatting for Kubernetes services
-	return fmt.Sprintf("%s-dapr.%s.svc.%s:%d", req.ID, req.Namespace, k.clusterDomain, req.Port), nil
+	return fmt.Sprintf("%s-dapr.%s.svc.%s:%d", req.ID, req.Namespace, k.clusterDomain, req.Port)
 }
@@ -14,9 +14,15 @@ limitations under the License.
 package kubernetes
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/dapr/components-contrib/nameresolution"
 	"github.com/dapr/kit/logger"
@@ -48,3 +54,82 @@ func TestResolveWithCustomClusterDomain(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, target, u)
 }
+
+func TestResolveLocalityAware(t *testing.T) {
+	ready := true
+	notReady := false
+	zoneA := "zone-a"
+	zoneB := "zone-b"
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myid-dapr-abcde",
+			Namespace: "abc",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "myid-dapr"},
+		},
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, Zone: &zoneA, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+			{Addresses: []string{"10.0.0.2"}, Zone: &zoneB, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+			{Addresses: []string{"10.0.0.3"}, Zone: &zoneB, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+		},
+	}
+	client := fake.NewSimpleClientset(slice)
+
+	r := &resolver{logger: logger.NewLogger("test"), clusterDomain: DefaultClusterDomain, kubeClient: client}
+	err := r.Init(nameresolution.Metadata{
+		Configuration: map[string]interface{}{
+			"localityAware": true,
+			"zone":          "zone-b",
+		},
+	})
+	assert.NoError(t, err)
+	defer r.Close()
+
+	request := nameresolution.ResolveRequest{ID: "myid", Namespace: "abc", Port: 1234}
+	endpoints, err := r.ResolveIDMulti(request)
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+
+	for i := 0; i < 10; i++ {
+		target, err := r.ResolveID(request)
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.2:1234", target)
+	}
+
+	t.Run("falls back to the service without endpoints", func(t *testing.T) {
+		target, err := r.ResolveID(nameresolution.ResolveRequest{ID: "other", Namespace: "abc", Port: 1234})
+		assert.NoError(t, err)
+		assert.Equal(t, "other-dapr.abc.svc.cluster.local:1234", target)
+	})
+
+	t.Run("lists the endpoint slices once and watches them", func(t *testing.T) {
+		lists := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "list" && action.GetNamespace() == "abc" {
+				lists++
+			}
+		}
+		// One list for myid and one for other
+		assert.Equal(t, 2, lists)
+
+		// Wait for the watch to be started, as the fake client doesn't replay the changes made before it.
+		require.Eventually(t, func() bool {
+			watches := 0
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "watch" {
+					watches++
+				}
+			}
+			return watches == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		updated := slice.DeepCopy()
+		updated.Endpoints[1].Conditions.Ready = &notReady
+		_, err := client.DiscoveryV1().EndpointSlices("abc").Update(context.Background(), updated, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			target, err := r.ResolveID(request)
+			return err == nil && target == "10.0.0.1:1234"
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// serviceKey identifies a resolved Dapr service.
+type serviceKey struct {
+	namespace string
+	id        string
+}
+
+// serviceWatcher keeps the endpoint slices of a Dapr service up to date with an informer.
+type serviceWatcher struct {
+	store      cache.Store
+	controller cache.Controller
+	stop       chan struct{}
+}
+
+func (w *serviceWatcher) slices(selector string) []*discoveryv1.EndpointSlice {
+	objects := w.store.List()
+	slices := make([]*discoveryv1.EndpointSlice, 0, len(objects))
+	for _, obj := range objects {
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
+		if ok && slice.Labels[discoveryv1.LabelServiceName] == selector {
+			slices = append(slices, slice)
+		}
+	}
+	return slices
+}
+
+// getEndpointSlices returns the endpoint slices of a Dapr service.
+// The endpoint slices of a service are listed on its first resolution and then kept up to date by a watch, so that
+// resolutions don't call the API server. When the first list fails the service is evicted, so that it is listed again
+// on the next resolution; later failures are retried by the informer, which keeps serving the last known slices.
+func (k *resolver) getEndpointSlices(key serviceKey) ([]*discoveryv1.EndpointSlice, error) {
+	k.lock.Lock()
+	w, ok := k.watchers[key]
+	if !ok {
+		if k.closed {
+			k.lock.Unlock()
+			return nil, errors.New("the resolver is closed")
+		}
+		w = k.watch(key)
+		k.watchers[key] = w
+	}
+	k.lock.Unlock()
+
+	selector := key.id + "-dapr"
+	if w.controller.HasSynced() {
+		return w.slices(selector), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), w.controller.HasSynced) {
+		k.evict(key, w)
+		return nil, fmt.Errorf("failed to list the endpoint slices of %s in %s", selector, key.namespace)
+	}
+	return w.slices(selector), nil
+}
+
+// watch starts the informer of the endpoint slices of a Dapr service.
+func (k *resolver) watch(key serviceKey) *serviceWatcher {
+	client := k.kubeClient.DiscoveryV1().EndpointSlices(key.namespace)
+	selector := discoveryv1.LabelServiceName + "=" + key.id + "-dapr"
+	watchlist := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = selector
+			return client.List(context.Background(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = selector
+			return client.Watch(context.Background(), options)
+		},
+	}
+
+	w := &serviceWatcher{stop: make(chan struct{})}
+	w.store, w.controller = cache.NewInformer(watchlist, &discoveryv1.EndpointSlice{}, 0, cache.ResourceEventHandlerFuncs{})
+
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+		w.controller.Run(w.stop)
+	}()
+	return w
+}
+
+// evict stops the watcher of a Dapr service, if it is still the one of the service.
+func (k *resolver) evict(key serviceKey, w *serviceWatcher) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.watchers[key] == w {
+		delete(k.watchers, key)
+		close(w.stop)
+	}
+}
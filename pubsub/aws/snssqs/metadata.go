@@ -20,6 +20,14 @@ type snsSqsMetadata struct {
 	SecretKey string
 	// aws session token to use.
 	SessionToken string
+	// ARN of an IAM role to assume.
+	AssumeRoleArn string
+	// external ID to use when assuming the role.
+	ExternalID string
+	// name of the assumed role session.
+	SessionName string
+	// path to a web identity token file, used with AssumeRoleArn for IAM Roles for Service Accounts.
+	WebIdentityTokenFile string
 	// aws region in which SNS/SQS should create resources.
 	Region string
 	// aws partition in which SNS/SQS should create resources.
@@ -159,6 +167,7 @@ func (md *snsSqsMetadata) hideDebugPrintedCredentials() string {
 	mdCopy.AccessKey = maskLeft(md.AccessKey)
 	mdCopy.SecretKey = maskLeft(md.SecretKey)
 	mdCopy.SessionToken = maskLeft(md.SessionToken)
+	mdCopy.ExternalID = maskLeft(md.ExternalID)
 
 	return fmt.Sprintf("%#v\n", mdCopy)
 }
@@ -180,6 +189,22 @@ func (md *snsSqsMetadata) setCredsAndQueueNameConfig(metadata pubsub.Metadata) e
 		md.SessionToken = val
 	}
 
+	if val, ok := metadata.Properties["assumeRoleArn"]; ok {
+		md.AssumeRoleArn = val
+	}
+
+	if val, ok := metadata.Properties["externalId"]; ok {
+		md.ExternalID = val
+	}
+
+	if val, ok := metadata.Properties["sessionName"]; ok {
+		md.SessionName = val
+	}
+
+	if val, ok := metadata.Properties["webIdentityTokenFile"]; ok {
+		md.WebIdentityTokenFile = val
+	}
+
 	if val, ok := mdutils.GetMetadataProperty(metadata.Properties, "awsRegion", "region"); ok {
 		md.Region = val
 
@@ -16,6 +16,7 @@ package snssqs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -67,6 +68,8 @@ type snsSqs struct {
 	pollerCancel  context.CancelFunc
 	backOffConfig retry.Config
 	pollerRunning chan struct{}
+	inFlight      pubsub.InFlight
+	stats         pubsub.StatsRecorder
 }
 
 type sqsQueueInfo struct {
@@ -162,7 +165,17 @@ func (s *snsSqs) Init(metadata pubsub.Metadata) error {
 	s.queues = sync.Map{}
 	s.subscriptions = sync.Map{}
 
-	sess, err := awsAuth.GetClient(md.AccessKey, md.SecretKey, md.SessionToken, md.Region, md.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               md.Region,
+		Endpoint:             md.Endpoint,
+		AccessKey:            md.AccessKey,
+		SecretKey:            md.SecretKey,
+		SessionToken:         md.SessionToken,
+		AssumeRoleARN:        md.AssumeRoleArn,
+		ExternalID:           md.ExternalID,
+		SessionName:          md.SessionName,
+		WebIdentityTokenFile: md.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return fmt.Errorf("error creating an AWS client: %w", err)
 	}
@@ -539,6 +552,11 @@ func (s *snsSqs) validateMessage(ctx context.Context, message *sqs.Message, queu
 	return nil
 }
 
+// SetStatsRecorder records the messages received again, and the failed messages moved to the dead-letters queue when sqsDeadLettersQueueName is set.
+func (s *snsSqs) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	s.stats = recorder
+}
+
 func (s *snsSqs) callHandler(ctx context.Context, message *sqs.Message, queueInfo *sqsQueueInfo) error {
 	// otherwise, try to handle the message.
 	var snsMessagePayload snsMessage
@@ -560,11 +578,21 @@ func (s *snsSqs) callHandler(ctx context.Context, message *sqs.Message, queueInf
 
 	s.logger.Debugf("Processing SNS message id: %s of topic: %s", *message.MessageId, sanitizedTopic)
 
+	// The receive count was validated before calling the handler
+	recvCount, _ := s.parseReceiveCount(message)
+	if recvCount > 1 && s.stats != nil {
+		s.stats.RecordRetry(handler.topicName)
+	}
+
 	err = handler.handler(handler.ctx, &pubsub.NewMessage{
 		Data:  []byte(snsMessagePayload.Message),
 		Topic: handler.topicName,
 	})
 	if err != nil {
+		// SQS moves the message to the dead-letters queue instead of receiving it again once over messageReceiveLimit
+		if s.stats != nil && s.metadata.sqsDeadLettersQueueName != "" && recvCount >= s.metadata.messageReceiveLimit {
+			s.stats.RecordDeadLetter(handler.topicName, s.metadata.sqsDeadLettersQueueName)
+		}
 		return fmt.Errorf("error handling message: %w", err)
 	}
 	// otherwise, there was no error, acknowledge the message.
@@ -585,9 +613,21 @@ func (s *snsSqs) consumeSubscription(ctx context.Context, queueInfo, deadLetters
 		WaitTimeSeconds:     aws.Int64(s.metadata.messageWaitTimeSeconds),
 	}
 
+	// Stop polling as soon as the component is draining, without canceling the messages being handled
+	pollCtx, pollCancel := context.WithCancel(ctx)
+	defer pollCancel()
+	draining := s.inFlight.Draining()
+	go func() {
+		select {
+		case <-draining:
+			pollCancel()
+		case <-pollCtx.Done():
+		}
+	}()
+
 	for {
 		// If the context is canceled, stop requesting messages
-		if ctx.Err() != nil {
+		if pollCtx.Err() != nil {
 			break
 		}
 
@@ -595,7 +635,7 @@ func (s *snsSqs) consumeSubscription(ctx context.Context, queueInfo, deadLetters
 		// sqs and try pull messages. Since we are iteratively short polling (based on the defined
 		// s.metadata.messageWaitTimeSeconds) the sdk backoff is not effective as it gets reset per each polling
 		// iteration. Therefore, a global backoff (to the internal backoff) is used (sqsPullExponentialBackoff).
-		messageResponse, err := s.sqsClient.ReceiveMessageWithContext(ctx, receiveMessageInput)
+		messageResponse, err := s.sqsClient.ReceiveMessageWithContext(pollCtx, receiveMessageInput)
 		if err != nil {
 			if err == context.Canceled || err == context.DeadlineExceeded {
 				s.logger.Warn("context canceled; stopping consuming from queue arn: %v", queueInfo.arn)
@@ -624,11 +664,20 @@ func (s *snsSqs) consumeSubscription(ctx context.Context, queueInfo, deadLetters
 				continue
 			}
 
+			// Make the messages received while draining visible again right away for the other consumers
+			if !s.inFlight.Begin() {
+				if err := s.resetMessageVisibilityTimeout(ctx, queueInfo.url, message.ReceiptHandle); err != nil {
+					s.logger.Errorf("error while releasing message received while draining. error is: %v", err)
+				}
+				continue
+			}
+
 			f := func(message *sqs.Message) {
 				if err := s.callHandler(ctx, message, queueInfo); err != nil {
 					s.logger.Errorf("error while handling received message. error is: %v", err)
 				}
 
+				s.inFlight.End()
 				wg.Done()
 			}
 
@@ -848,6 +897,76 @@ func (s *snsSqs) Subscribe(subscribeCtx context.Context, req pubsub.SubscribeReq
 	return nil
 }
 
+// SubscribeDeadLetters delivers the messages of topic req.Topic moved to the dead-letters queue set with "sqsDeadLettersQueueName".
+// Messages are deleted from the dead-letters queue once handled.
+func (s *snsSqs) SubscribeDeadLetters(subscribeCtx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	if s.metadata.sqsDeadLettersQueueName == "" {
+		return errors.New("no dead-letters queue: sqsDeadLettersQueueName is not set")
+	}
+
+	deadLettersQueueInfo, err := s.getOrCreateQueue(subscribeCtx, s.metadata.sqsDeadLettersQueueName)
+	if err != nil {
+		wrappedErr := fmt.Errorf("error retrieving SQS dead-letter queue: %w", err)
+		s.logger.Error(wrappedErr)
+
+		return wrappedErr
+	}
+
+	go s.consumeDeadLetters(subscribeCtx, deadLettersQueueInfo, req.Topic, handler)
+
+	return nil
+}
+
+func (s *snsSqs) consumeDeadLetters(ctx context.Context, queueInfo *sqsQueueInfo, topic string, handler pubsub.Handler) {
+	sqsPullExponentialBackoff := s.backOffConfig.NewBackOffWithContext(ctx)
+	sanitizedTopic := nameToAWSSanitizedName(topic, s.metadata.fifo)
+
+	receiveMessageInput := &sqs.ReceiveMessageInput{
+		MaxNumberOfMessages: aws.Int64(s.metadata.messageMaxNumber),
+		QueueUrl:            aws.String(queueInfo.url),
+		VisibilityTimeout:   aws.Int64(s.metadata.messageVisibilityTimeout),
+		WaitTimeSeconds:     aws.Int64(s.metadata.messageWaitTimeSeconds),
+	}
+
+	for ctx.Err() == nil {
+		messageResponse, err := s.sqsClient.ReceiveMessageWithContext(ctx, receiveMessageInput)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Errorf("error consuming from dead-letters queue arn: %v with error: %v. retrying...", queueInfo.arn, err)
+				time.Sleep(sqsPullExponentialBackoff.NextBackOff())
+			}
+
+			continue
+		}
+		sqsPullExponentialBackoff.Reset()
+
+		for _, message := range messageResponse.Messages {
+			var snsMessagePayload snsMessage
+			err = json.Unmarshal([]byte(*(message.Body)), &snsMessagePayload)
+			if err != nil {
+				s.logger.Errorf("error unmarshalling dead letter %s: %v", *message.MessageId, err)
+				continue
+			}
+			// The queue may hold the dead letters of other topics: leave them there.
+			if snsMessagePayload.parseTopicArn() != sanitizedTopic {
+				continue
+			}
+
+			err = handler(ctx, &pubsub.NewMessage{
+				Data:  []byte(snsMessagePayload.Message),
+				Topic: topic,
+			})
+			if err != nil {
+				s.logger.Debugf("error handling dead letter %s: %v", *message.MessageId, err)
+				continue
+			}
+			if err = s.acknowledgeMessage(ctx, queueInfo.url, message.ReceiptHandle); err != nil {
+				s.logger.Errorf("error deleting dead letter %s: %v", *message.MessageId, err)
+			}
+		}
+	}
+}
+
 func (s *snsSqs) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
 	topicArn, _, err := s.getOrCreateTopic(ctx, req.Topic)
 	if err != nil {
@@ -875,6 +994,11 @@ func (s *snsSqs) Publish(ctx context.Context, req *pubsub.PublishRequest) error
 	return nil
 }
 
+// Drain stops polling the queue and waits for the messages being handled to be acknowledged, or for ctx to be done.
+func (s *snsSqs) Drain(ctx context.Context) error {
+	return s.inFlight.Drain(ctx)
+}
+
 func (s *snsSqs) Close() error {
 	s.cancel()
 
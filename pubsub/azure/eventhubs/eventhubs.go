@@ -526,7 +526,7 @@ func (aeh *AzureEventHubs) Init(metadata pubsub.Metadata) error {
 			}
 
 			// Get Azure Management plane settings for creating consumer groups using event hubs management client.
-			settings, err := azauth.NewEnvironmentSettings("azure", metadata.Properties)
+			settings, err := azauth.NewEnvironmentSettings(azauth.AzureResourceManagerResourceName, metadata.Properties)
 			if err != nil {
 				return err
 			}
@@ -39,6 +39,8 @@ type azureServiceBus struct {
 	client   *impl.Client
 	logger   logger.Logger
 	features []pubsub.Feature
+	subs     impl.Subscriptions
+	stats    pubsub.StatsRecorder
 }
 
 // NewAzureServiceBusQueues returns a new implementation.
@@ -49,6 +51,12 @@ func NewAzureServiceBusQueues(logger logger.Logger) pubsub.PubSub {
 	}
 }
 
+// SetStatsRecorder records the messages delivered again, and the failed messages moved to the dead-letter queue after their last delivery.
+// The last delivery is the "maxDeliveryCount" one, or the 10th if it is not set.
+func (a *azureServiceBus) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	a.stats = recorder
+}
+
 func (a *azureServiceBus) Init(metadata pubsub.Metadata) (err error) {
 	a.metadata, err = impl.ParseMetadata(metadata.Properties, a.logger, impl.MetadataModeQueues)
 	if err != nil {
@@ -184,6 +192,10 @@ func (a *azureServiceBus) Subscribe(subscribeCtx context.Context, req pubsub.Sub
 			Entity:                "queue " + req.Topic,
 			LockRenewalInSec:      a.metadata.LockRenewalInSec,
 			RequireSessions:       false,
+			Topic:                 req.Topic,
+			Stats:                 a.stats,
+			DeadLetterEntity:      req.Topic + "/$DeadLetterQueue",
+			MaxDeliveryCount:      a.metadata.MaxDeliveryCount,
 		},
 		a.logger,
 	)
@@ -214,6 +226,10 @@ func (a *azureServiceBus) BulkSubscribe(subscribeCtx context.Context, req pubsub
 			Entity:                "queue " + req.Topic,
 			LockRenewalInSec:      a.metadata.LockRenewalInSec,
 			RequireSessions:       false,
+			Topic:                 req.Topic,
+			Stats:                 a.stats,
+			DeadLetterEntity:      req.Topic + "/$DeadLetterQueue",
+			MaxDeliveryCount:      a.metadata.MaxDeliveryCount,
 		},
 		a.logger,
 	)
@@ -254,7 +270,10 @@ func (a *azureServiceBus) doSubscribe(subscribeCtx context.Context,
 		bo.Reset()
 	}
 
+	a.subs.Add(sub)
 	go func() {
+		defer a.subs.Remove(sub)
+
 		// Reconnect loop.
 		for {
 			// Blocks until a successful connection (or until context is canceled)
@@ -283,8 +302,8 @@ func (a *azureServiceBus) doSubscribe(subscribeCtx context.Context,
 			sub.Close(closeCtx)
 			closeCancel()
 
-			// If context was canceled, do not attempt to reconnect
-			if subscribeCtx.Err() != nil {
+			// If context was canceled or the subscription drained, do not attempt to reconnect
+			if subscribeCtx.Err() != nil || sub.IsDraining() {
 				a.logger.Debug("Context canceled; will not reconnect")
 				return
 			}
@@ -298,6 +317,11 @@ func (a *azureServiceBus) doSubscribe(subscribeCtx context.Context,
 	return nil
 }
 
+// Drain stops receiving messages on all subscriptions, then waits for the active messages to be completed or abandoned until ctx is done.
+func (a *azureServiceBus) Drain(ctx context.Context) error {
+	return a.subs.Drain(ctx)
+}
+
 func (a *azureServiceBus) Close() (err error) {
 	a.client.CloseAllSenders(a.logger)
 	return nil
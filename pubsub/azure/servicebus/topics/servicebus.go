@@ -39,6 +39,8 @@ type azureServiceBus struct {
 	client   *impl.Client
 	logger   logger.Logger
 	features []pubsub.Feature
+	subs     impl.Subscriptions
+	stats    pubsub.StatsRecorder
 }
 
 // NewAzureServiceBusTopics returns a new pub-sub implementation.
@@ -49,6 +51,12 @@ func NewAzureServiceBusTopics(logger logger.Logger) pubsub.PubSub {
 	}
 }
 
+// SetStatsRecorder records the messages delivered again, and the failed messages moved to the dead-letter queue after their last delivery.
+// The last delivery is the "maxDeliveryCount" one, or the 10th if it is not set.
+func (a *azureServiceBus) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	a.stats = recorder
+}
+
 func (a *azureServiceBus) Init(metadata pubsub.Metadata) (err error) {
 	a.metadata, err = impl.ParseMetadata(metadata.Properties, a.logger, impl.MetadataModeTopics)
 	if err != nil {
@@ -186,6 +194,10 @@ func (a *azureServiceBus) Subscribe(subscribeCtx context.Context, req pubsub.Sub
 			Entity:                "topic " + req.Topic,
 			LockRenewalInSec:      a.metadata.LockRenewalInSec,
 			RequireSessions:       requireSessions,
+			Topic:                 req.Topic,
+			Stats:                 a.stats,
+			DeadLetterEntity:      req.Topic + "/Subscriptions/" + a.metadata.ConsumerID + "/$DeadLetterQueue",
+			MaxDeliveryCount:      a.metadata.MaxDeliveryCount,
 		},
 		a.logger,
 	)
@@ -224,6 +236,10 @@ func (a *azureServiceBus) BulkSubscribe(subscribeCtx context.Context, req pubsub
 			Entity:                "topic " + req.Topic,
 			LockRenewalInSec:      a.metadata.LockRenewalInSec,
 			RequireSessions:       requireSessions,
+			Topic:                 req.Topic,
+			Stats:                 a.stats,
+			DeadLetterEntity:      req.Topic + "/Subscriptions/" + a.metadata.ConsumerID + "/$DeadLetterQueue",
+			MaxDeliveryCount:      a.metadata.MaxDeliveryCount,
 		},
 		a.logger,
 	)
@@ -268,7 +284,10 @@ func (a *azureServiceBus) doSubscribe(subscribeCtx context.Context,
 		bo.Reset()
 	}
 
+	a.subs.Add(sub)
 	go func() {
+		defer a.subs.Remove(sub)
+
 		// Reconnect loop.
 		for {
 			if opts.RequireSessions {
@@ -277,8 +296,8 @@ func (a *azureServiceBus) doSubscribe(subscribeCtx context.Context,
 				a.ConnectAndReceive(subscribeCtx, req, sub, receiveAndBlockFn, onFirstSuccess)
 			}
 
-			// If context was canceled, do not attempt to reconnect
-			if subscribeCtx.Err() != nil {
+			// If context was canceled or the subscription drained, do not attempt to reconnect
+			if subscribeCtx.Err() != nil || sub.IsDraining() {
 				a.logger.Debug("Context canceled; will not reconnect")
 				return
 			}
@@ -292,6 +311,69 @@ func (a *azureServiceBus) doSubscribe(subscribeCtx context.Context,
 	return nil
 }
 
+// SubscribeDeadLetters delivers the messages in the dead-letter queue of the subscription "consumerID" to topic req.Topic.
+// Messages are completed, and so removed from the dead-letter queue, once handled.
+func (a *azureServiceBus) SubscribeDeadLetters(subscribeCtx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	sub := impl.NewSubscription(
+		subscribeCtx, impl.SubsriptionOptions{
+			MaxActiveMessages:     a.metadata.MaxActiveMessages,
+			TimeoutInSec:          a.metadata.TimeoutInSec,
+			MaxBulkSubCount:       nil,
+			MaxRetriableEPS:       a.metadata.MaxRetriableErrorsPerSec,
+			MaxConcurrentHandlers: a.metadata.MaxConcurrentHandlers,
+			Entity:                "dead-letter queue of topic " + req.Topic,
+			LockRenewalInSec:      a.metadata.LockRenewalInSec,
+		},
+		a.logger,
+	)
+
+	receiveAndBlockFn := func(receiver impl.Receiver, onFirstSuccess func()) error {
+		return sub.ReceiveBlocking(
+			impl.GetPubSubHandlerFunc(req.Topic, handler, a.logger, time.Duration(a.metadata.HandlerTimeoutInSec)*time.Second),
+			receiver,
+			onFirstSuccess,
+			impl.ReceiveOptions{},
+		)
+	}
+
+	// Reconnection backoff policy
+	bo := backoff.NewExponentialBackOff()
+	bo.MaxElapsedTime = 0
+	bo.InitialInterval = time.Duration(a.metadata.MinConnectionRecoveryInSec) * time.Second
+	bo.MaxInterval = time.Duration(a.metadata.MaxConnectionRecoveryInSec) * time.Second
+
+	receiverOpts := &servicebus.ReceiverOptions{
+		SubQueue: servicebus.SubQueueDeadLetter,
+	}
+
+	a.subs.Add(sub)
+	go func() {
+		defer a.subs.Remove(sub)
+
+		// Reconnect loop.
+		for {
+			a.connectAndReceive(subscribeCtx, req, sub, receiveAndBlockFn, bo.Reset, receiverOpts)
+
+			// If context was canceled or the subscription drained, do not attempt to reconnect
+			if subscribeCtx.Err() != nil || sub.IsDraining() {
+				a.logger.Debug("Context canceled; will not reconnect")
+				return
+			}
+
+			wait := bo.NextBackOff()
+			a.logger.Warnf("Dead-letter subscription to topic %s lost connection, attempting to reconnect in %s...", req.Topic, wait)
+			time.Sleep(wait)
+		}
+	}()
+
+	return nil
+}
+
+// Drain stops receiving messages on all subscriptions, then waits for the active messages to be completed or abandoned until ctx is done.
+func (a *azureServiceBus) Drain(ctx context.Context) error {
+	return a.subs.Drain(ctx)
+}
+
 func (a *azureServiceBus) Close() (err error) {
 	a.client.CloseAllSenders(a.logger)
 	return nil
@@ -302,6 +384,10 @@ func (a *azureServiceBus) Features() []pubsub.Feature {
 }
 
 func (a *azureServiceBus) ConnectAndReceive(subscribeCtx context.Context, req pubsub.SubscribeRequest, sub *impl.Subscription, receiveAndBlockFn func(impl.Receiver, func()) error, onFirstSuccess func()) error {
+	return a.connectAndReceive(subscribeCtx, req, sub, receiveAndBlockFn, onFirstSuccess, nil)
+}
+
+func (a *azureServiceBus) connectAndReceive(subscribeCtx context.Context, req pubsub.SubscribeRequest, sub *impl.Subscription, receiveAndBlockFn func(impl.Receiver, func()) error, onFirstSuccess func(), receiverOpts *servicebus.ReceiverOptions) error {
 	defer func() {
 		// Gracefully close the connection (in case it's not closed already)
 		// Use a background context here (with timeout) because ctx may be closed already.
@@ -313,7 +399,7 @@ func (a *azureServiceBus) ConnectAndReceive(subscribeCtx context.Context, req pu
 	// Blocks until a successful connection (or until context is canceled)
 	receiver, err := sub.Connect(func() (impl.Receiver, error) {
 		a.logger.Debugf("Connecting to subscription %s for topic %s", a.metadata.ConsumerID, req.Topic)
-		r, err := a.client.GetClient().NewReceiverForSubscription(req.Topic, a.metadata.ConsumerID, nil)
+		r, err := a.client.GetClient().NewReceiverForSubscription(req.Topic, a.metadata.ConsumerID, receiverOpts)
 		return impl.NewMessageReceiver(r), err
 	})
 	if err != nil {
@@ -380,6 +466,10 @@ func (a *azureServiceBus) ConnectAndReceiveWithSessions(subscribeCtx context.Con
 		case <-subscribeCtx.Done():
 			return
 		case <-sessionsChan:
+			if sub.IsDraining() {
+				return
+			}
+
 			select {
 			case <-subscribeCtx.Done():
 				return
@@ -0,0 +1,232 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// PublishBatchSizeKey is the metadata key name for the maximum number of messages published in a batch.
+	PublishBatchSizeKey = "publishBatchSize"
+	// PublishBatchIntervalKey is the metadata key name for the time a batch waits for more messages before being published.
+	PublishBatchIntervalKey = "publishBatchInterval"
+
+	defaultPublishBatchInterval = 10 * time.Millisecond
+)
+
+// ErrPublishBatcherClosed is returned when publishing with a PublishBatcher that was closed.
+var ErrPublishBatcherClosed = errors.New("publish batcher is closed")
+
+// PublishBatching takes a metadata object and returns the publish batching configuration.
+// A size of 0 or 1 means batching is disabled, which is the default.
+func PublishBatching(metadata map[string]string) (size int, interval time.Duration, err error) {
+	interval = defaultPublishBatchInterval
+	if val, ok := metadata[PublishBatchSizeKey]; ok && val != "" {
+		size, err = strconv.Atoi(val)
+		if err != nil || size < 0 {
+			return 0, 0, fmt.Errorf("invalid %s %s", PublishBatchSizeKey, val)
+		}
+	}
+	if val, ok := metadata[PublishBatchIntervalKey]; ok && val != "" {
+		interval, err = time.ParseDuration(val)
+		if err != nil || interval <= 0 {
+			return 0, 0, fmt.Errorf("invalid %s %s", PublishBatchIntervalKey, val)
+		}
+	}
+
+	return size, interval, nil
+}
+
+// PublishBatchFunc publishes a batch of messages and returns the error of each message, in order.
+type PublishBatchFunc func(ctx context.Context, reqs []*PublishRequest) []error
+
+// States of a publishBatchEntry.
+const (
+	entryQueued int32 = iota
+	entryFlushed
+	entryAbandoned
+)
+
+type publishBatchEntry struct {
+	ctx   context.Context
+	req   *PublishRequest
+	res   chan error
+	state atomic.Int32
+}
+
+// PublishBatcher groups concurrent Publish calls into batches published together.
+// A batch is published once it has reached its maximum size, or the interval has elapsed since its first message.
+type PublishBatcher struct {
+	maxSize  int
+	interval time.Duration
+	flush    PublishBatchFunc
+
+	queue   chan *publishBatchEntry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewPublishBatcher returns a PublishBatcher publishing batches of up to maxSize messages with flush.
+func NewPublishBatcher(maxSize int, interval time.Duration, flush PublishBatchFunc) *PublishBatcher {
+	b := &PublishBatcher{
+		maxSize:  maxSize,
+		interval: interval,
+		flush:    flush,
+		queue:    make(chan *publishBatchEntry),
+		closeCh:  make(chan struct{}),
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+
+	b.wg.Add(1)
+	go b.run()
+
+	return b
+}
+
+// Publish adds req to the current batch and waits until the batch has been published.
+// If ctx is done before the batch is flushed, the message is not published and ctx.Err() is returned. Once the flush has
+// started, Publish waits for its outcome: the batch is published with the values, such as the trace context, of the
+// context of its first message, and expires at the earliest deadline of its messages.
+func (b *PublishBatcher) Publish(ctx context.Context, req *PublishRequest) error {
+	entry := &publishBatchEntry{
+		ctx: ctx,
+		req: req,
+		res: make(chan error, 1),
+	}
+
+	select {
+	case b.queue <- entry:
+	case <-b.closeCh:
+		return ErrPublishBatcherClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-entry.res:
+		return err
+	case <-ctx.Done():
+		if entry.state.CompareAndSwap(entryQueued, entryAbandoned) {
+			return ctx.Err()
+		}
+		return <-entry.res
+	}
+}
+
+// Close publishes the current batch and stops the batcher.
+func (b *PublishBatcher) Close() {
+	b.once.Do(func() {
+		close(b.closeCh)
+		b.wg.Wait()
+		b.cancel()
+	})
+}
+
+func (b *PublishBatcher) run() {
+	defer b.wg.Done()
+
+	for {
+		var batch []*publishBatchEntry
+		select {
+		case entry := <-b.queue:
+			batch = append(batch, entry)
+		case <-b.closeCh:
+			return
+		}
+
+		timer := time.NewTimer(b.interval)
+	collect:
+		for len(batch) < b.maxSize {
+			select {
+			case entry := <-b.queue:
+				batch = append(batch, entry)
+			case <-timer.C:
+				break collect
+			case <-b.closeCh:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		b.publish(batch)
+	}
+}
+
+func (b *PublishBatcher) publish(batch []*publishBatchEntry) {
+	// Skip the messages whose caller is gone.
+	flushed := batch[:0]
+	for _, entry := range batch {
+		if entry.ctx.Err() == nil && entry.state.CompareAndSwap(entryQueued, entryFlushed) {
+			flushed = append(flushed, entry)
+		}
+	}
+	if len(flushed) == 0 {
+		return
+	}
+
+	reqs := make([]*PublishRequest, len(flushed))
+	for i, entry := range flushed {
+		reqs[i] = entry.req
+	}
+
+	ctx, cancel := b.batchContext(flushed)
+	defer cancel()
+	errs := b.flush(ctx, reqs)
+	for i, entry := range flushed {
+		var err error
+		if i < len(errs) {
+			err = errs[i]
+		}
+		entry.res <- err
+	}
+}
+
+// batchContext returns the context publishing a batch, which is canceled when the batcher is closed.
+func (b *PublishBatcher) batchContext(batch []*publishBatchEntry) (context.Context, context.CancelFunc) {
+	var (
+		deadline    time.Time
+		hasDeadline bool
+	)
+	for _, entry := range batch {
+		if d, ok := entry.ctx.Deadline(); ok && (!hasDeadline || d.Before(deadline)) {
+			deadline, hasDeadline = d, true
+		}
+	}
+
+	ctx, cancel := b.ctx, context.CancelFunc(func() {})
+	if hasDeadline {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	}
+	return valuesContext{Context: ctx, values: batch[0].ctx}, cancel
+}
+
+// valuesContext is a context with the values of another context, but not its cancellation.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	return c.values.Value(key)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishBatching(t *testing.T) {
+	size, interval, err := PublishBatching(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+	assert.Equal(t, defaultPublishBatchInterval, interval)
+
+	size, interval, err = PublishBatching(map[string]string{PublishBatchSizeKey: "50", PublishBatchIntervalKey: "2ms"})
+	require.NoError(t, err)
+	assert.Equal(t, 50, size)
+	assert.Equal(t, 2*time.Millisecond, interval)
+
+	_, _, err = PublishBatching(map[string]string{PublishBatchSizeKey: "-1"})
+	assert.Error(t, err)
+	_, _, err = PublishBatching(map[string]string{PublishBatchIntervalKey: "soon"})
+	assert.Error(t, err)
+}
+
+func TestPublishBatcher(t *testing.T) {
+	t.Run("publishes full batches", func(t *testing.T) {
+		var (
+			lock    sync.Mutex
+			batches [][]*PublishRequest
+		)
+		b := NewPublishBatcher(3, time.Minute, func(ctx context.Context, reqs []*PublishRequest) []error {
+			lock.Lock()
+			defer lock.Unlock()
+			batches = append(batches, reqs)
+			errs := make([]error, len(reqs))
+			for i, req := range reqs {
+				if string(req.Data) == "bad" {
+					errs[i] = errors.New("rejected")
+				}
+			}
+			return errs
+		})
+		defer b.Close()
+
+		errs := make(chan error, 3)
+		for _, data := range []string{"a", "bad", "c"} {
+			go func(data string) {
+				errs <- b.Publish(context.Background(), &PublishRequest{Topic: "t", Data: []byte(data)})
+			}(data)
+		}
+
+		failed := 0
+		for i := 0; i < 3; i++ {
+			if <-errs != nil {
+				failed++
+			}
+		}
+		assert.Equal(t, 1, failed)
+		require.Len(t, batches, 1)
+		assert.Len(t, batches[0], 3)
+	})
+
+	t.Run("publishes after the interval", func(t *testing.T) {
+		b := NewPublishBatcher(100, 5*time.Millisecond, func(ctx context.Context, reqs []*PublishRequest) []error {
+			return make([]error, len(reqs))
+		})
+		defer b.Close()
+
+		assert.NoError(t, b.Publish(context.Background(), &PublishRequest{Topic: "t"}))
+	})
+
+	t.Run("skips the messages whose context is done", func(t *testing.T) {
+		published := make(chan []*PublishRequest, 1)
+		b := NewPublishBatcher(2, 50*time.Millisecond, func(ctx context.Context, reqs []*PublishRequest) []error {
+			published <- reqs
+			return make([]error, len(reqs))
+		})
+		defer b.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := make(chan error, 1)
+		go func() {
+			canceled <- b.Publish(ctx, &PublishRequest{Topic: "t", Data: []byte("canceled")})
+		}()
+		// Wait for the message to be queued in the current batch.
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-canceled, context.Canceled)
+
+		require.NoError(t, b.Publish(context.Background(), &PublishRequest{Topic: "t", Data: []byte("kept")}))
+		reqs := <-published
+		require.Len(t, reqs, 1)
+		assert.Equal(t, "kept", string(reqs[0].Data))
+	})
+
+	t.Run("publishes with the deadline and values of the messages", func(t *testing.T) {
+		type key struct{}
+		var flushCtx context.Context
+		b := NewPublishBatcher(100, time.Millisecond, func(ctx context.Context, reqs []*PublishRequest) []error {
+			flushCtx = ctx
+			return make([]error, len(reqs))
+		})
+		defer b.Close()
+
+		ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "trace"), time.Minute)
+		defer cancel()
+		require.NoError(t, b.Publish(ctx, &PublishRequest{Topic: "t"}))
+		deadline, ok := flushCtx.Deadline()
+		expected, _ := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, expected, deadline)
+		assert.Equal(t, "trace", flushCtx.Value(key{}))
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		b := NewPublishBatcher(100, time.Minute, func(ctx context.Context, reqs []*PublishRequest) []error {
+			return make([]error, len(reqs))
+		})
+		b.Close()
+
+		assert.ErrorIs(t, b.Publish(context.Background(), &PublishRequest{Topic: "t"}), ErrPublishBatcherClosed)
+	})
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/ratelimit"
+)
+
+const defaultReplayIdleTimeout = 10 * time.Second
+
+// errReplayLimit rejects the messages received after the limit of a replay was reached, so they stay dead-lettered.
+var errReplayLimit = errors.New("replay limit reached")
+
+// DeadLetterSubscriber is implemented by components with a native dead-letter location, such as a SQS dead-letters queue,
+// the dead-letter sub-queue of a Service Bus subscription or the dead-letter topic set in the metadata of a Kafka component.
+type DeadLetterSubscriber interface {
+	// SubscribeDeadLetters delivers the messages dead-lettered for the subscription to req.Topic, until ctx is canceled.
+	// Messages are removed from the dead-letter location once the handler returns without error.
+	SubscribeDeadLetters(ctx context.Context, req SubscribeRequest, handler Handler) error
+}
+
+// ReplayRequest describes the dead-lettered messages to replay.
+type ReplayRequest struct {
+	// Topic the messages are republished to, usually the topic they were dead-lettered from.
+	Topic string
+	// Topic of the component holding the dead letters, e.g. the dead-letter topic of a Dapr subscription or a Kafka DLT.
+	// When empty, the dead letters are read from the native dead-letter location of the component, which must implement DeadLetterSubscriber.
+	DeadLetterTopic string
+	// Metadata of the subscription reading the dead letters.
+	Metadata map[string]string
+	// Maximum number of messages to replay; 0 for no limit.
+	MaxMessages int
+	// Maximum number of messages republished per second; 0 for no limit.
+	MaxMessagesPerSec int
+	// The replay ends once no dead letter was received for this duration. Defaults to 10s.
+	IdleTimeout time.Duration
+}
+
+// ReplayResult is the outcome of a replay.
+type ReplayResult struct {
+	// Number of messages republished and removed from the dead-letter location.
+	Replayed int
+	// Number of messages that couldn't be republished, and stay dead-lettered.
+	Failed int
+}
+
+// ReplayDeadLetters republishes the dead-lettered messages of ps to req.Topic, so they are processed again once the cause of the failures is fixed.
+// It returns once MaxMessages were replayed, no message was received for IdleTimeout, or ctx is done.
+func ReplayDeadLetters(ctx context.Context, ps PubSub, req ReplayRequest) (ReplayResult, error) {
+	if req.Topic == "" {
+		return ReplayResult{}, errors.New("missing topic to replay the dead letters to")
+	}
+	if req.DeadLetterTopic == req.Topic {
+		return ReplayResult{}, errors.New("the dead-letter topic must differ from the replay topic")
+	}
+	idleTimeout := req.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = defaultReplayIdleTimeout
+	}
+	limiter := ratelimit.NewUnlimited()
+	if req.MaxMessagesPerSec > 0 {
+		limiter = ratelimit.New(req.MaxMessagesPerSec)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		lock     sync.Mutex
+		result   ReplayResult
+		inflight int
+		stopped  bool
+		drained  = make(chan struct{})
+		received = make(chan struct{}, 1)
+	)
+	notify := func() {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	}
+	handler := func(ctx context.Context, msg *NewMessage) error {
+		lock.Lock()
+		if stopped || (req.MaxMessages > 0 && result.Replayed+inflight >= req.MaxMessages) {
+			lock.Unlock()
+			return errReplayLimit
+		}
+		inflight++
+		lock.Unlock()
+		notify()
+		defer notify()
+
+		limiter.Take()
+		err := ps.Publish(ctx, &PublishRequest{
+			Data:        msg.Data,
+			Topic:       req.Topic,
+			Metadata:    msg.Metadata,
+			ContentType: msg.ContentType,
+		})
+
+		lock.Lock()
+		defer lock.Unlock()
+		inflight--
+		if err != nil {
+			result.Failed++
+		} else {
+			result.Replayed++
+			if req.MaxMessages > 0 && result.Replayed >= req.MaxMessages {
+				cancel()
+			}
+		}
+		if stopped && inflight == 0 {
+			close(drained)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to republish dead letter to %s: %w", req.Topic, err)
+		}
+		return nil
+	}
+
+	subReq := SubscribeRequest{Topic: req.DeadLetterTopic, Metadata: req.Metadata}
+	var err error
+	if req.DeadLetterTopic != "" {
+		err = ps.Subscribe(subCtx, subReq, handler)
+	} else if dls, ok := ps.(DeadLetterSubscriber); ok {
+		subReq.Topic = req.Topic
+		err = dls.SubscribeDeadLetters(subCtx, subReq, handler)
+	} else {
+		err = errors.New("the component has no native dead-letter location: set the dead-letter topic")
+	}
+	if err != nil {
+		return ReplayResult{}, fmt.Errorf("failed to subscribe to the dead letters: %w", err)
+	}
+
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+	for subCtx.Err() == nil {
+		select {
+		case <-subCtx.Done():
+		case <-received:
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(idleTimeout)
+		case <-idle.C:
+			cancel()
+		}
+	}
+
+	// Wait for the messages being republished.
+	lock.Lock()
+	stopped = true
+	if inflight == 0 {
+		close(drained)
+	}
+	lock.Unlock()
+	<-drained
+
+	lock.Lock()
+	defer lock.Unlock()
+	return result, ctx.Err()
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadLetterPubSub delivers a queue of dead letters to the subscribers, and keeps the ones not handled successfully.
+type deadLetterPubSub struct {
+	lock        sync.Mutex
+	deadLetters []*NewMessage
+	subscribed  SubscribeRequest
+	published   []*PublishRequest
+	failPublish bool
+}
+
+func (f *deadLetterPubSub) Init(metadata Metadata) error { return nil }
+func (f *deadLetterPubSub) Features() []Feature          { return nil }
+func (f *deadLetterPubSub) Close() error                 { return nil }
+
+func (f *deadLetterPubSub) Publish(ctx context.Context, req *PublishRequest) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.failPublish {
+		return errors.New("broker unavailable")
+	}
+	f.published = append(f.published, req)
+	return nil
+}
+
+func (f *deadLetterPubSub) Subscribe(ctx context.Context, req SubscribeRequest, handler Handler) error {
+	f.subscribed = req
+	go func() {
+		f.lock.Lock()
+		pending := f.deadLetters
+		f.deadLetters = nil
+		f.lock.Unlock()
+
+		for _, msg := range pending {
+			if ctx.Err() != nil || handler(ctx, msg) != nil {
+				f.lock.Lock()
+				f.deadLetters = append(f.deadLetters, msg)
+				f.lock.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+func (f *deadLetterPubSub) remaining() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.deadLetters)
+}
+
+type nativeDeadLetterPubSub struct {
+	deadLetterPubSub
+}
+
+func (f *nativeDeadLetterPubSub) SubscribeDeadLetters(ctx context.Context, req SubscribeRequest, handler Handler) error {
+	return f.deadLetterPubSub.Subscribe(ctx, req, handler)
+}
+
+func newDeadLetters(n int) []*NewMessage {
+	msgs := make([]*NewMessage, n)
+	for i := range msgs {
+		msgs[i] = &NewMessage{Topic: "orders-dlq", Data: []byte{byte(i)}, Metadata: map[string]string{"id": string(rune('a' + i))}}
+	}
+	return msgs
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	t.Run("replays dead-letter topic", func(t *testing.T) {
+		ps := &deadLetterPubSub{deadLetters: newDeadLetters(5)}
+		res, err := ReplayDeadLetters(context.Background(), ps, ReplayRequest{
+			Topic:           "orders",
+			DeadLetterTopic: "orders-dlq",
+			IdleTimeout:     50 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayResult{Replayed: 5}, res)
+		assert.Equal(t, "orders-dlq", ps.subscribed.Topic)
+		require.Len(t, ps.published, 5)
+		assert.Equal(t, "orders", ps.published[0].Topic)
+		assert.Equal(t, "a", ps.published[0].Metadata["id"])
+		assert.Zero(t, ps.remaining())
+	})
+
+	t.Run("stops at max messages", func(t *testing.T) {
+		ps := &deadLetterPubSub{deadLetters: newDeadLetters(5)}
+		res, err := ReplayDeadLetters(context.Background(), ps, ReplayRequest{
+			Topic:           "orders",
+			DeadLetterTopic: "orders-dlq",
+			MaxMessages:     2,
+			IdleTimeout:     time.Minute,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Replayed)
+		assert.Len(t, ps.published, 2)
+
+		// The messages not replayed stay dead-lettered.
+		assert.Eventually(t, func() bool {
+			return ps.remaining() == 3
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		ps := &deadLetterPubSub{deadLetters: newDeadLetters(3)}
+		start := time.Now()
+		res, err := ReplayDeadLetters(context.Background(), ps, ReplayRequest{
+			Topic:             "orders",
+			DeadLetterTopic:   "orders-dlq",
+			MaxMessages:       3,
+			MaxMessagesPerSec: 20,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, res.Replayed)
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("failed republish keeps dead letters", func(t *testing.T) {
+		ps := &deadLetterPubSub{deadLetters: newDeadLetters(2), failPublish: true}
+		res, err := ReplayDeadLetters(context.Background(), ps, ReplayRequest{
+			Topic:           "orders",
+			DeadLetterTopic: "orders-dlq",
+			IdleTimeout:     50 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayResult{Failed: 2}, res)
+		assert.Eventually(t, func() bool {
+			return ps.remaining() == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("native dead-letter location", func(t *testing.T) {
+		ps := &nativeDeadLetterPubSub{deadLetterPubSub{deadLetters: newDeadLetters(1)}}
+		res, err := ReplayDeadLetters(context.Background(), ps, ReplayRequest{
+			Topic:       "orders",
+			IdleTimeout: 50 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Replayed)
+		assert.Equal(t, "orders", ps.subscribed.Topic)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		ps := &deadLetterPubSub{}
+		_, err := ReplayDeadLetters(context.Background(), ps, ReplayRequest{Topic: "orders"})
+		assert.ErrorContains(t, err, "no native dead-letter location")
+		_, err = ReplayDeadLetters(context.Background(), ps, ReplayRequest{DeadLetterTopic: "orders-dlq"})
+		assert.Error(t, err)
+		_, err = ReplayDeadLetters(context.Background(), ps, ReplayRequest{Topic: "orders", DeadLetterTopic: "orders"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDraining is returned for the messages received by a component once it started draining.
+var ErrDraining = errors.New("pubsub is draining")
+
+// Drainer is implemented by the components that can be drained before they are closed.
+type Drainer interface {
+	// Drain stops fetching new messages and waits for the messages being handled to complete, or for ctx to be done.
+	// The consumers are closed afterwards, so that the messages which were handled are not delivered again.
+	// A drained component does not deliver messages anymore, even to new subscriptions.
+	Drain(ctx context.Context) error
+}
+
+// Drain drains the component if it implements Drainer.
+func Drain(ctx context.Context, pubsub PubSub) error {
+	if drainer, ok := pubsub.(Drainer); ok {
+		return drainer.Drain(ctx)
+	}
+	return fmt.Errorf("drain is not implemented by this pubsub")
+}
+
+// InFlight tracks the messages being handled by the subscriptions of a component.
+// The zero value is ready to use.
+type InFlight struct {
+	lock     sync.Mutex
+	count    int
+	draining bool
+	drainCh  chan struct{}
+	idleCh   chan struct{}
+}
+
+// Begin records a message as being handled.
+// It returns false if the component is draining: the message must then be left to the broker for redelivery.
+func (f *InFlight) Begin() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.draining {
+		return false
+	}
+	f.count++
+	return true
+}
+
+// End records that the handling of a message started with Begin completed.
+func (f *InFlight) End() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.count--
+	if f.count <= 0 && f.idleCh != nil {
+		close(f.idleCh)
+		f.idleCh = nil
+	}
+}
+
+// Count returns the number of messages being handled.
+func (f *InFlight) Count() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.count
+}
+
+// Draining returns a channel which is closed once Drain is called.
+func (f *InFlight) Draining() <-chan struct{} {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.drainCh == nil {
+		f.drainCh = make(chan struct{})
+		if f.draining {
+			close(f.drainCh)
+		}
+	}
+	return f.drainCh
+}
+
+// Drain makes Begin refuse new messages, then waits for the messages being handled to complete or for ctx to be done.
+func (f *InFlight) Drain(ctx context.Context) error {
+	f.lock.Lock()
+	if !f.draining {
+		f.draining = true
+		if f.drainCh != nil {
+			close(f.drainCh)
+		}
+	}
+	if f.count <= 0 {
+		f.lock.Unlock()
+		return nil
+	}
+	if f.idleCh == nil {
+		f.idleCh = make(chan struct{})
+	}
+	idleCh := f.idleCh
+	f.lock.Unlock()
+
+	select {
+	case <-idleCh:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d message(s) still in flight: %w", f.Count(), ctx.Err())
+	}
+}
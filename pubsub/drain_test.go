@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlight(t *testing.T) {
+	t.Run("drain without messages", func(t *testing.T) {
+		var f InFlight
+		require.NoError(t, f.Drain(context.Background()))
+		assert.False(t, f.Begin())
+
+		select {
+		case <-f.Draining():
+		default:
+			t.Fatal("draining channel is not closed")
+		}
+	})
+
+	t.Run("drain waits for in-flight messages", func(t *testing.T) {
+		var f InFlight
+		draining := f.Draining()
+		require.True(t, f.Begin())
+		require.True(t, f.Begin())
+
+		done := make(chan error, 1)
+		go func() {
+			done <- f.Drain(context.Background())
+		}()
+
+		<-draining
+		assert.False(t, f.Begin())
+		f.End()
+		select {
+		case <-done:
+			t.Fatal("drain returned with a message in flight")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		f.End()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("drain did not return")
+		}
+		assert.Equal(t, 0, f.Count())
+	})
+
+	t.Run("drain deadline", func(t *testing.T) {
+		var f InFlight
+		require.True(t, f.Begin())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := f.Drain(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "1 message(s) still in flight")
+		f.End()
+	})
+}
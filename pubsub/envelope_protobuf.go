@@ -0,0 +1,485 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	contribContenttype "github.com/dapr/components-contrib/contenttype"
+	contribMetadata "github.com/dapr/components-contrib/metadata"
+)
+
+// ContentTypeHeader is the header, property or attribute that carries the
+// content type of a structured mode cloud event on the wire.
+const ContentTypeHeader = "content-type"
+
+// DataSchemaField is the dataschema attribute of a cloud event.
+const DataSchemaField = "dataschema"
+
+// Field numbers of the io.cloudevents.v1.CloudEvent message.
+const (
+	protoIDField          protowire.Number = 1
+	protoSourceField      protowire.Number = 2
+	protoSpecVersionField protowire.Number = 3
+	protoTypeField        protowire.Number = 4
+	protoAttributesField  protowire.Number = 5
+	protoBinaryDataField  protowire.Number = 6
+	protoTextDataField    protowire.Number = 7
+	protoProtoDataField   protowire.Number = 8
+)
+
+// Field numbers of the io.cloudevents.v1.CloudEvent.CloudEventAttributeValue message.
+const (
+	protoAttrBoolean   protowire.Number = 1
+	protoAttrInteger   protowire.Number = 2
+	protoAttrString    protowire.Number = 3
+	protoAttrBytes     protowire.Number = 4
+	protoAttrURI       protowire.Number = 5
+	protoAttrURIRef    protowire.Number = 6
+	protoAttrTimestamp protowire.Number = 7
+)
+
+var errInvalidProtobufCloudEvent = errors.New("invalid protobuf cloud event")
+
+// protoRequiredFields are the required attributes that the protobuf format
+// stores as fields of the CloudEvent message rather than in its attributes.
+var protoRequiredFields = []struct {
+	name   string
+	number protowire.Number
+}{
+	{IDField, protoIDField},
+	{SourceField, protoSourceField},
+	{SpecVersionField, protoSpecVersionField},
+	{TypeField, protoTypeField},
+}
+
+// IsProtobufCloudEventRequested returns true if the publisher asked for the
+// protobuf format, either through the content type of the request or through
+// the contentType metadata.
+func IsProtobufCloudEventRequested(contentType *string, metadata map[string]string) bool {
+	if contentType != nil && contribContenttype.IsCloudEventProtobufContentType(*contentType) {
+		return true
+	}
+
+	return contribContenttype.IsCloudEventProtobufContentType(metadata[contribMetadata.ContentType])
+}
+
+// EncodeProtobufCloudEvent returns the cloud event to publish in the protobuf
+// format. JSON cloud events are converted, data that already is a protobuf
+// cloud event is returned as is.
+func EncodeProtobufCloudEvent(data []byte) ([]byte, error) {
+	if json.Valid(data) {
+		return CloudEventToProtobuf(data)
+	}
+
+	if _, err := CloudEventFromProtobuf(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// DecodeProtobufCloudEvent converts the data of a received message to the
+// JSON format if contentType, as read from the broker, is the one of a
+// protobuf cloud event. Other messages are left untouched.
+func DecodeProtobufCloudEvent(msg *NewMessage, contentType string) error {
+	if !contribContenttype.IsCloudEventProtobufContentType(contentType) {
+		return nil
+	}
+
+	data, err := CloudEventFromProtobuf(msg.Data)
+	if err != nil {
+		return err
+	}
+	ct := contribContenttype.CloudEventContentType
+	msg.Data = data
+	msg.ContentType = &ct
+
+	return nil
+}
+
+// CloudEventToProtobuf converts a cloud event from the JSON format to the
+// protobuf format of the cloud events specification.
+func CloudEventToProtobuf(data []byte) ([]byte, error) {
+	var event map[string]json.RawMessage
+	if err := unmarshalPrecise(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud event: %w", err)
+	}
+
+	var (
+		b     []byte
+		attrs = make([]string, 0, len(event))
+	)
+	for _, field := range protoRequiredFields {
+		var val string
+		if raw, ok := event[field.name]; ok {
+			if err := json.Unmarshal(raw, &val); err != nil {
+				return nil, fmt.Errorf("cloud event attribute %s must be a string", field.name)
+			}
+		}
+		if val == "" {
+			if field.name != SpecVersionField {
+				return nil, fmt.Errorf("cloud event attribute %s is required", field.name)
+			}
+			val = CloudEventsSpecVersion
+		}
+		b = protowire.AppendTag(b, field.number, protowire.BytesType)
+		b = protowire.AppendString(b, val)
+	}
+
+	for name, raw := range event {
+		switch name {
+		case IDField, SourceField, SpecVersionField, TypeField, DataField, DataBase64Field:
+			continue
+		}
+		if string(raw) == "null" {
+			continue
+		}
+		attrs = append(attrs, name)
+	}
+	sort.Strings(attrs)
+
+	for _, name := range attrs {
+		value, err := encodeProtobufAttribute(name, event[name])
+		if err != nil {
+			return nil, err
+		}
+		var entry []byte
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, name)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, value)
+		b = protowire.AppendTag(b, protoAttributesField, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+
+	if raw, ok := event[DataBase64Field]; ok && string(raw) != "null" {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return nil, fmt.Errorf("cloud event attribute %s must be a string", DataBase64Field)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", DataBase64Field, err)
+		}
+		b = protowire.AppendTag(b, protoBinaryDataField, protowire.BytesType)
+		b = protowire.AppendBytes(b, decoded)
+	} else if raw, ok := event[DataField]; ok && string(raw) != "null" {
+		text := string(raw)
+		var dataContentType string
+		if ct, ok := event[DataContentTypeField]; ok {
+			_ = json.Unmarshal(ct, &dataContentType)
+		}
+		// A JSON string is the textual representation of the data, unless the
+		// data itself is JSON.
+		if !isJSONDataContentType(dataContentType) {
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				text = s
+			}
+		}
+		b = protowire.AppendTag(b, protoTextDataField, protowire.BytesType)
+		b = protowire.AppendString(b, text)
+	}
+
+	return b, nil
+}
+
+func encodeProtobufAttribute(name string, raw json.RawMessage) ([]byte, error) {
+	var b []byte
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		switch name {
+		case TimeField:
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, fmt.Errorf("cloud event attribute %s is not a RFC 3339 timestamp: %w", name, err)
+			}
+			var ts []byte
+			ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(t.Unix()))
+			if t.Nanosecond() != 0 {
+				ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+				ts = protowire.AppendVarint(ts, uint64(t.Nanosecond()))
+			}
+			b = protowire.AppendTag(b, protoAttrTimestamp, protowire.BytesType)
+			b = protowire.AppendBytes(b, ts)
+		case DataSchemaField:
+			b = protowire.AppendTag(b, protoAttrURI, protowire.BytesType)
+			b = protowire.AppendString(b, s)
+		default:
+			b = protowire.AppendTag(b, protoAttrString, protowire.BytesType)
+			b = protowire.AppendString(b, s)
+		}
+		return b, nil
+	}
+
+	var boolean bool
+	if err := json.Unmarshal(raw, &boolean); err == nil {
+		b = protowire.AppendTag(b, protoAttrBoolean, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(boolean))
+		return b, nil
+	}
+
+	var integer int64
+	if err := json.Unmarshal(raw, &integer); err == nil && integer >= math.MinInt32 && integer <= math.MaxInt32 {
+		b = protowire.AppendTag(b, protoAttrInteger, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(integer))
+		return b, nil
+	}
+
+	// Extensions that the protobuf format can't represent natively are kept
+	// as their JSON text.
+	b = protowire.AppendTag(b, protoAttrString, protowire.BytesType)
+	b = protowire.AppendString(b, string(raw))
+	return b, nil
+}
+
+// CloudEventFromProtobuf converts a cloud event from the protobuf format of
+// the cloud events specification to the JSON format.
+func CloudEventFromProtobuf(data []byte) ([]byte, error) {
+	event := map[string]interface{}{}
+
+	var (
+		protoTypeURL string
+		hasProtoData bool
+	)
+	err := consumeProtobufFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case protoIDField, protoSourceField, protoSpecVersionField, protoTypeField:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			for _, field := range protoRequiredFields {
+				if field.number == num {
+					event[field.name] = string(value)
+				}
+			}
+		case protoAttributesField:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			name, attr, err := decodeProtobufAttribute(value)
+			if err != nil {
+				return err
+			}
+			event[name] = attr
+		case protoBinaryDataField:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			event[DataBase64Field] = base64.StdEncoding.EncodeToString(value)
+		case protoTextDataField:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			event[DataField] = string(value)
+		case protoProtoDataField:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			hasProtoData = true
+			var protoValue []byte
+			err := consumeProtobufFields(value, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case 1:
+					protoTypeURL = string(v)
+				case 2:
+					protoValue = v
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			event[DataBase64Field] = base64.StdEncoding.EncodeToString(protoValue)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range protoRequiredFields {
+		if v, _ := event[field.name].(string); v == "" {
+			return nil, fmt.Errorf("%w: missing attribute %s", errInvalidProtobufCloudEvent, field.name)
+		}
+	}
+
+	if hasProtoData {
+		if _, ok := event[DataContentTypeField]; !ok {
+			event[DataContentTypeField] = "application/protobuf"
+		}
+		if _, ok := event[DataSchemaField]; !ok && protoTypeURL != "" {
+			event[DataSchemaField] = protoTypeURL
+		}
+	}
+
+	if text, ok := event[DataField].(string); ok {
+		dataContentType, _ := event[DataContentTypeField].(string)
+		if isJSONDataContentType(dataContentType) && json.Valid([]byte(text)) {
+			event[DataField] = json.RawMessage(text)
+		}
+	}
+
+	return json.Marshal(event)
+}
+
+func decodeProtobufAttribute(entry []byte) (name string, attr interface{}, err error) {
+	var value []byte
+	err = consumeProtobufFields(entry, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return errInvalidProtobufCloudEvent
+		}
+		switch num {
+		case 1:
+			name = string(v)
+		case 2:
+			value = v
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: attribute without a name", errInvalidProtobufCloudEvent)
+	}
+
+	err = consumeProtobufFields(value, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch num {
+		case protoAttrBoolean, protoAttrInteger:
+			if typ != protowire.VarintType {
+				return errInvalidProtobufCloudEvent
+			}
+			n, l := protowire.ConsumeVarint(v)
+			if l < 0 {
+				return errInvalidProtobufCloudEvent
+			}
+			if num == protoAttrBoolean {
+				attr = protowire.DecodeBool(n)
+			} else {
+				attr = int32(n)
+			}
+		case protoAttrString, protoAttrURI, protoAttrURIRef:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			attr = string(v)
+		case protoAttrBytes:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			attr = base64.StdEncoding.EncodeToString(v)
+		case protoAttrTimestamp:
+			if typ != protowire.BytesType {
+				return errInvalidProtobufCloudEvent
+			}
+			var seconds, nanos int64
+			err := consumeProtobufFields(v, func(num protowire.Number, typ protowire.Type, tv []byte) error {
+				if typ != protowire.VarintType {
+					return errInvalidProtobufCloudEvent
+				}
+				n, l := protowire.ConsumeVarint(tv)
+				if l < 0 {
+					return errInvalidProtobufCloudEvent
+				}
+				switch num {
+				case 1:
+					seconds = int64(n)
+				case 2:
+					nanos = int64(int32(n))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			attr = time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return name, attr, nil
+}
+
+// consumeProtobufFields walks the fields of a protobuf message. Varint values
+// are handed to fn still encoded, length-delimited values without their
+// length prefix. Other wire types are skipped.
+func consumeProtobufFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errInvalidProtobufCloudEvent
+		}
+		b = b[n:]
+
+		var value []byte
+		switch typ {
+		case protowire.BytesType:
+			v, l := protowire.ConsumeBytes(b)
+			if l < 0 {
+				return errInvalidProtobufCloudEvent
+			}
+			value, n = v, l
+		case protowire.VarintType:
+			_, l := protowire.ConsumeVarint(b)
+			if l < 0 {
+				return errInvalidProtobufCloudEvent
+			}
+			value, n = b[:l], l
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return errInvalidProtobufCloudEvent
+			}
+		}
+		b = b[n:]
+
+		if value != nil || typ == protowire.BytesType {
+			if err := fn(num, typ, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isJSONDataContentType returns true if the data of a cloud event with the
+// given datacontenttype is JSON. A missing datacontenttype implies JSON.
+func isJSONDataContentType(contentType string) bool {
+	if contentType == "" || contribContenttype.IsJSONContentType(contentType) {
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	return strings.HasSuffix(strings.TrimSpace(mediaType), "+json")
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestCloudEventProtobufRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"json data": `{"id":"1","source":"app","specversion":"1.0","type":"com.dapr.event.sent",` +
+			`"datacontenttype":"application/json","data":{"message":"hello","count":3},` +
+			`"topic":"orders","pubsubname":"kafka","time":"2023-01-02T03:04:05.123456789Z"}`,
+		"text data": `{"id":"2","source":"app","specversion":"1.0","type":"t",` +
+			`"datacontenttype":"text/plain","data":"hello world"}`,
+		"json string data": `{"id":"3","source":"app","specversion":"1.0","type":"t",` +
+			`"datacontenttype":"application/json","data":"hello"}`,
+		"binary data": `{"id":"4","source":"app","specversion":"1.0","type":"t",` +
+			`"datacontenttype":"application/octet-stream","data_base64":"AAECAw=="}`,
+		"extensions": `{"id":"5","source":"app","specversion":"1.0","type":"t",` +
+			`"dataschema":"https://example.com/schema","priority":7,"sampled":true}`,
+	}
+
+	for name, event := range tests {
+		event := event
+		t.Run(name, func(t *testing.T) {
+			encoded, err := CloudEventToProtobuf([]byte(event))
+			require.NoError(t, err)
+			assert.False(t, json.Valid(encoded))
+
+			decoded, err := CloudEventFromProtobuf(encoded)
+			require.NoError(t, err)
+			assert.JSONEq(t, event, string(decoded))
+		})
+	}
+}
+
+func TestCloudEventToProtobuf(t *testing.T) {
+	t.Run("core attributes are message fields", func(t *testing.T) {
+		encoded, err := CloudEventToProtobuf([]byte(`{"id":"1","source":"s","type":"t","data":"x","datacontenttype":"text/plain"}`))
+		require.NoError(t, err)
+
+		var expected []byte
+		expected = protowire.AppendTag(expected, 1, protowire.BytesType)
+		expected = protowire.AppendString(expected, "1")
+		expected = protowire.AppendTag(expected, 2, protowire.BytesType)
+		expected = protowire.AppendString(expected, "s")
+		expected = protowire.AppendTag(expected, 3, protowire.BytesType)
+		expected = protowire.AppendString(expected, "1.0")
+		expected = protowire.AppendTag(expected, 4, protowire.BytesType)
+		expected = protowire.AppendString(expected, "t")
+
+		var value, entry []byte
+		value = protowire.AppendTag(value, 3, protowire.BytesType)
+		value = protowire.AppendString(value, "text/plain")
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, "datacontenttype")
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, value)
+		expected = protowire.AppendTag(expected, 5, protowire.BytesType)
+		expected = protowire.AppendBytes(expected, entry)
+
+		expected = protowire.AppendTag(expected, 7, protowire.BytesType)
+		expected = protowire.AppendString(expected, "x")
+
+		assert.Equal(t, expected, encoded)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		_, err := CloudEventToProtobuf([]byte(`{"source":"s","type":"t"}`))
+		assert.ErrorContains(t, err, "id is required")
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		_, err := CloudEventToProtobuf([]byte(`{"id":"1","source":"s","type":"t","time":"yesterday"}`))
+		assert.ErrorContains(t, err, "RFC 3339")
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		_, err := CloudEventToProtobuf([]byte(`hello`))
+		assert.Error(t, err)
+	})
+}
+
+func TestCloudEventFromProtobuf(t *testing.T) {
+	t.Run("proto data", func(t *testing.T) {
+		var b, anyMsg []byte
+		for i, v := range []string{"1", "s", "1.0", "t"} {
+			b = protowire.AppendTag(b, protowire.Number(i+1), protowire.BytesType)
+			b = protowire.AppendString(b, v)
+		}
+		anyMsg = protowire.AppendTag(anyMsg, 1, protowire.BytesType)
+		anyMsg = protowire.AppendString(anyMsg, "type.googleapis.com/example.Order")
+		anyMsg = protowire.AppendTag(anyMsg, 2, protowire.BytesType)
+		anyMsg = protowire.AppendBytes(anyMsg, []byte{0x08, 0x01})
+		b = protowire.AppendTag(b, 8, protowire.BytesType)
+		b = protowire.AppendBytes(b, anyMsg)
+
+		decoded, err := CloudEventFromProtobuf(b)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"1","source":"s","specversion":"1.0","type":"t",`+
+			`"datacontenttype":"application/protobuf","dataschema":"type.googleapis.com/example.Order",`+
+			`"data_base64":"CAE="}`, string(decoded))
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, "1")
+		_, err := CloudEventFromProtobuf(b)
+		assert.ErrorIs(t, err, errInvalidProtobufCloudEvent)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		_, err := CloudEventFromProtobuf([]byte{0xff, 0xff, 0xff})
+		assert.ErrorIs(t, err, errInvalidProtobufCloudEvent)
+	})
+}
+
+func TestEncodeProtobufCloudEvent(t *testing.T) {
+	encoded, err := EncodeProtobufCloudEvent([]byte(`{"id":"1","source":"s","type":"t"}`))
+	require.NoError(t, err)
+
+	t.Run("protobuf is passed through", func(t *testing.T) {
+		res, err := EncodeProtobufCloudEvent(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, encoded, res)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		_, err := EncodeProtobufCloudEvent([]byte{0xff, 0xff})
+		assert.Error(t, err)
+	})
+}
+
+func TestIsProtobufCloudEventRequested(t *testing.T) {
+	ct := "application/cloudevents+protobuf"
+	other := "application/json"
+	assert.True(t, IsProtobufCloudEventRequested(&ct, nil))
+	assert.True(t, IsProtobufCloudEventRequested(nil, map[string]string{"contentType": ct}))
+	assert.False(t, IsProtobufCloudEventRequested(&other, nil))
+	assert.False(t, IsProtobufCloudEventRequested(nil, map[string]string{}))
+}
+
+func TestDecodeProtobufCloudEvent(t *testing.T) {
+	event := `{"id":"1","source":"s","specversion":"1.0","type":"t","datacontenttype":"application/json","data":{"a":1}}`
+	encoded, err := CloudEventToProtobuf([]byte(event))
+	require.NoError(t, err)
+
+	t.Run("protobuf cloud event", func(t *testing.T) {
+		msg := &NewMessage{Data: encoded}
+		require.NoError(t, DecodeProtobufCloudEvent(msg, "application/cloudevents+protobuf"))
+		assert.JSONEq(t, event, string(msg.Data))
+		require.NotNil(t, msg.ContentType)
+		assert.Equal(t, "application/cloudevents+json", *msg.ContentType)
+	})
+
+	t.Run("other content type", func(t *testing.T) {
+		msg := &NewMessage{Data: []byte("hello")}
+		require.NoError(t, DecodeProtobufCloudEvent(msg, "text/plain"))
+		assert.Equal(t, "hello", string(msg.Data))
+		assert.Nil(t, msg.ContentType)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		msg := &NewMessage{Data: []byte("hello")}
+		assert.Error(t, DecodeProtobufCloudEvent(msg, "application/cloudevents+protobuf"))
+	})
+}
@@ -31,4 +31,8 @@ type metadata struct {
 	EnableMessageOrdering   bool
 	MaxReconnectionAttempts int
 	ConnectionRecoveryInSec int
+	DeadLetterTopic         string
+	MaxDeliveryAttempts     int
+	EnableExactlyOnce       bool
+	ImpersonateAccount      string
 }
@@ -18,13 +18,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	gcppubsub "cloud.google.com/go/pubsub"
+	"google.golang.org/api/impersonate"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/dapr/components-contrib/contenttype"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
 )
@@ -49,10 +53,24 @@ const (
 	metadataEnableMessageOrderingKey   = "enableMessageOrdering"
 	metadataMaxReconnectionAttemptsKey = "maxReconnectionAttempts"
 	metadataConnectionRecoveryInSecKey = "connectionRecoveryInSec"
+	metadataDeadLetterTopicKey         = "deadLetterTopic"
+	metadataMaxDeliveryAttemptsKey     = "maxDeliveryAttempts"
+	metadataEnableExactlyOnceKey       = "enableExactlyOnceDelivery"
+	metadataImpersonateAccountKey      = "impersonateServiceAccount"
+
+	// Request metadata keys.
+	metadataOrderingKey = "orderingKey"
 
 	// Defaults.
 	defaultMaxReconnectionAttempts = 30
 	defaultConnectionRecoveryInSec = 2
+	defaultMaxDeliveryAttempts     = 5
+
+	// Limits enforced by GCP for dead letter policies.
+	minMaxDeliveryAttempts = 5
+	maxMaxDeliveryAttempts = 100
+
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
 )
 
 // GCPPubSub type.
@@ -60,6 +78,11 @@ type GCPPubSub struct {
 	client   *gcppubsub.Client
 	metadata *metadata
 	logger   logger.Logger
+
+	// Publisher topic handles are cached so that batching and ordering
+	// state is shared across Publish calls.
+	topics     map[string]*gcppubsub.Topic
+	topicsLock sync.Mutex
 }
 
 type GCPAuthJSON struct {
@@ -81,7 +104,10 @@ type WhatNow struct {
 
 // NewGCPPubSub returns a new GCPPubSub instance.
 func NewGCPPubSub(logger logger.Logger) pubsub.PubSub {
-	return &GCPPubSub{logger: logger}
+	return &GCPPubSub{
+		logger: logger,
+		topics: map[string]*gcppubsub.Topic{},
+	}
 }
 
 func createMetadata(pubSubMetadata pubsub.Metadata) (*metadata, error) {
@@ -171,6 +197,32 @@ func createMetadata(pubSubMetadata pubsub.Metadata) (*metadata, error) {
 		}
 	}
 
+	if val, found := pubSubMetadata.Properties[metadataDeadLetterTopicKey]; found && val != "" {
+		result.DeadLetterTopic = val
+	}
+
+	result.MaxDeliveryAttempts = defaultMaxDeliveryAttempts
+	if val, ok := pubSubMetadata.Properties[metadataMaxDeliveryAttemptsKey]; ok && val != "" {
+		var err error
+		result.MaxDeliveryAttempts, err = strconv.Atoi(val)
+		if err != nil {
+			return &result, fmt.Errorf("%s invalid maxDeliveryAttempts %s, %s", errorMessagePrefix, val, err)
+		}
+		if result.MaxDeliveryAttempts < minMaxDeliveryAttempts || result.MaxDeliveryAttempts > maxMaxDeliveryAttempts {
+			return &result, fmt.Errorf("%s invalid maxDeliveryAttempts %s, must be between %d and %d", errorMessagePrefix, val, minMaxDeliveryAttempts, maxMaxDeliveryAttempts)
+		}
+	}
+
+	if val, found := pubSubMetadata.Properties[metadataEnableExactlyOnceKey]; found && val != "" {
+		if boolVal, err := strconv.ParseBool(val); err == nil {
+			result.EnableExactlyOnce = boolVal
+		}
+	}
+
+	if val, found := pubSubMetadata.Properties[metadataImpersonateAccountKey]; found && val != "" {
+		result.ImpersonateAccount = val
+	}
+
 	return &result, nil
 }
 
@@ -193,8 +245,20 @@ func (g *GCPPubSub) Init(meta pubsub.Metadata) error {
 }
 
 func (g *GCPPubSub) getPubSubClient(ctx context.Context, metadata *metadata) (*gcppubsub.Client, error) {
-	var pubsubClient *gcppubsub.Client
-	var err error
+	clientOptions, err := g.getClientOptions(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcppubsub.NewClient(ctx, metadata.ProjectID, clientOptions...)
+}
+
+// getClientOptions returns the client options used to authenticate with GCP.
+// When no service account key is configured, Application Default Credentials
+// are used, which covers GKE workload identity, workload identity federation
+// credential configuration files and the metadata server on GCE/Cloud Run.
+func (g *GCPPubSub) getClientOptions(ctx context.Context, metadata *metadata) ([]option.ClientOption, error) {
+	var clientOptions []option.ClientOption
 
 	if metadata.PrivateKeyID != "" {
 		// TODO: validate that all auth json fields are filled
@@ -212,20 +276,24 @@ func (g *GCPPubSub) getPubSubClient(ctx context.Context, metadata *metadata) (*g
 		}
 		gcpCompatibleJSON, _ := json.Marshal(authJSON)
 		g.logger.Debugf("Using explicit credentials for GCP")
-		clientOptions := option.WithCredentialsJSON(gcpCompatibleJSON)
-		pubsubClient, err = gcppubsub.NewClient(ctx, metadata.ProjectID, clientOptions)
-		if err != nil {
-			return pubsubClient, err
-		}
+		clientOptions = append(clientOptions, option.WithCredentialsJSON(gcpCompatibleJSON))
 	} else {
 		g.logger.Debugf("Using implicit credentials for GCP")
-		pubsubClient, err = gcppubsub.NewClient(ctx, metadata.ProjectID)
+	}
+
+	if metadata.ImpersonateAccount != "" {
+		g.logger.Debugf("Impersonating service account %s for GCP", metadata.ImpersonateAccount)
+		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: metadata.ImpersonateAccount,
+			Scopes:          []string{cloudPlatformScope},
+		}, clientOptions...)
 		if err != nil {
-			return pubsubClient, err
+			return nil, fmt.Errorf("error impersonating service account %s: %w", metadata.ImpersonateAccount, err)
 		}
+		clientOptions = []option.ClientOption{option.WithTokenSource(ts)}
 	}
 
-	return pubsubClient, nil
+	return clientOptions, nil
 }
 
 // Publish the topic to GCP Pubsub.
@@ -237,11 +305,30 @@ func (g *GCPPubSub) Publish(ctx context.Context, req *pubsub.PublishRequest) err
 		}
 	}
 
-	topic := g.getTopic(req.Topic)
+	topic := g.getPublisherTopic(req.Topic)
 
-	_, err := topic.Publish(ctx, &gcppubsub.Message{
+	msg := &gcppubsub.Message{
 		Data: req.Data,
-	}).Get(ctx)
+	}
+	if pubsub.IsProtobufCloudEventRequested(req.ContentType, req.Metadata) {
+		data, err := pubsub.EncodeProtobufCloudEvent(req.Data)
+		if err != nil {
+			return fmt.Errorf("%s could not encode message for topic %s: %w", errorMessagePrefix, req.Topic, err)
+		}
+		msg.Data = data
+		msg.Attributes = map[string]string{
+			pubsub.ContentTypeHeader: contenttype.CloudEventProtobufContentType,
+		}
+	}
+	if val, ok := req.Metadata[metadataOrderingKey]; ok && val != "" {
+		msg.OrderingKey = val
+	}
+
+	_, err := topic.Publish(ctx, msg).Get(ctx)
+	if err != nil && msg.OrderingKey != "" {
+		// Publishing for an ordering key is paused after a failure until resumed.
+		topic.ResumePublish(msg.OrderingKey)
+	}
 
 	return err
 }
@@ -311,7 +398,25 @@ func (g *GCPPubSub) handleSubscriptionMessages(parentCtx context.Context, topic
 				Topic: topic.ID(),
 			}
 
-			err := handler(ctx, msg)
+			err := pubsub.DecodeProtobufCloudEvent(msg, m.Attributes[pubsub.ContentTypeHeader])
+			if err != nil {
+				g.logger.Errorf("Failed to decode message %s on subscription %s: %s", m.ID, sub.ID(), err)
+			} else {
+				err = handler(ctx, msg)
+			}
+
+			if g.metadata.EnableExactlyOnce {
+				var res *gcppubsub.AckResult
+				if err == nil {
+					res = m.AckWithResult()
+				} else {
+					res = m.NackWithResult()
+				}
+				if _, ackErr := res.Get(ctx); ackErr != nil {
+					g.logger.Warnf("Failed to acknowledge message %s on subscription %s: %s", m.ID, sub.ID(), ackErr)
+				}
+				return
+			}
 
 			if err == nil {
 				m.Ack()
@@ -366,6 +471,31 @@ func (g *GCPPubSub) getTopic(topic string) *gcppubsub.Topic {
 	return g.client.Topic(topic)
 }
 
+func (g *GCPPubSub) getPublisherTopic(topic string) *gcppubsub.Topic {
+	g.topicsLock.Lock()
+	defer g.topicsLock.Unlock()
+
+	entity, ok := g.topics[topic]
+	if !ok {
+		entity = g.getTopic(topic)
+		// Messages without an ordering key are unaffected by this setting.
+		entity.EnableMessageOrdering = true
+		g.topics[topic] = entity
+	}
+
+	return entity
+}
+
+// deadLetterTopicName returns the fully qualified name of the dead letter topic.
+// GCP requires the full resource name; short names are resolved against the project.
+func (g *GCPPubSub) deadLetterTopicName() string {
+	if strings.HasPrefix(g.metadata.DeadLetterTopic, "projects/") {
+		return g.metadata.DeadLetterTopic
+	}
+
+	return "projects/" + g.metadata.ProjectID + "/topics/" + g.metadata.DeadLetterTopic
+}
+
 func (g *GCPPubSub) ensureSubscription(parentCtx context.Context, subscription string, topic string) error {
 	err := g.ensureTopic(parentCtx, topic)
 	if err != nil {
@@ -376,10 +506,26 @@ func (g *GCPPubSub) ensureSubscription(parentCtx context.Context, subscription s
 	entity := g.getSubscription(managedSubscription)
 	exists, subErr := entity.Exists(parentCtx)
 	if !exists {
-		_, subErr = g.client.CreateSubscription(parentCtx, managedSubscription, gcppubsub.SubscriptionConfig{
-			Topic:                 g.getTopic(topic),
-			EnableMessageOrdering: g.metadata.EnableMessageOrdering,
-		})
+		subConfig := gcppubsub.SubscriptionConfig{
+			Topic:                     g.getTopic(topic),
+			EnableMessageOrdering:     g.metadata.EnableMessageOrdering,
+			EnableExactlyOnceDelivery: g.metadata.EnableExactlyOnce,
+		}
+
+		if g.metadata.DeadLetterTopic != "" {
+			if !strings.HasPrefix(g.metadata.DeadLetterTopic, "projects/") {
+				err = g.ensureTopic(parentCtx, g.metadata.DeadLetterTopic)
+				if err != nil {
+					return err
+				}
+			}
+			subConfig.DeadLetterPolicy = &gcppubsub.DeadLetterPolicy{
+				DeadLetterTopic:     g.deadLetterTopicName(),
+				MaxDeliveryAttempts: g.metadata.MaxDeliveryAttempts,
+			}
+		}
+
+		_, subErr = g.client.CreateSubscription(parentCtx, managedSubscription, subConfig)
 	}
 
 	return subErr
@@ -390,6 +536,13 @@ func (g *GCPPubSub) getSubscription(subscription string) *gcppubsub.Subscription
 }
 
 func (g *GCPPubSub) Close() error {
+	g.topicsLock.Lock()
+	for _, topic := range g.topics {
+		topic.Stop()
+	}
+	g.topics = map[string]*gcppubsub.Topic{}
+	g.topicsLock.Unlock()
+
 	return g.client.Close()
 }
 
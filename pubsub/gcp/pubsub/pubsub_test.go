@@ -129,6 +129,58 @@ func TestInit(t *testing.T) {
 		assert.Error(t, err)
 		assertValidErrorMessage(t, err)
 	})
+
+	t.Run("dead letter and exactly once delivery", func(t *testing.T) {
+		m := pubsub.Metadata{}
+		m.Properties = map[string]string{
+			"projectId":                 "superproject",
+			"deadLetterTopic":           "dlq",
+			"maxDeliveryAttempts":       "10",
+			"enableExactlyOnceDelivery": "true",
+			"impersonateServiceAccount": "sa@superproject.iam.gserviceaccount.com",
+		}
+
+		pubSubMetadata, err := createMetadata(m)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "dlq", pubSubMetadata.DeadLetterTopic)
+		assert.Equal(t, 10, pubSubMetadata.MaxDeliveryAttempts)
+		assert.True(t, pubSubMetadata.EnableExactlyOnce)
+		assert.Equal(t, "sa@superproject.iam.gserviceaccount.com", pubSubMetadata.ImpersonateAccount)
+
+		g := GCPPubSub{metadata: pubSubMetadata}
+		assert.Equal(t, "projects/superproject/topics/dlq", g.deadLetterTopicName())
+
+		g.metadata.DeadLetterTopic = "projects/other/topics/dlq"
+		assert.Equal(t, "projects/other/topics/dlq", g.deadLetterTopicName())
+	})
+
+	t.Run("missing optional maxDeliveryAttempts", func(t *testing.T) {
+		m := pubsub.Metadata{}
+		m.Properties = map[string]string{
+			"projectId": "superproject",
+		}
+
+		pubSubMetadata, err := createMetadata(m)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, pubSubMetadata.MaxDeliveryAttempts)
+	})
+
+	t.Run("invalid optional maxDeliveryAttempts", func(t *testing.T) {
+		for _, val := range []string{invalidNumber, "4", "101"} {
+			m := pubsub.Metadata{}
+			m.Properties = map[string]string{
+				"projectId": "superproject",
+			}
+			m.Properties[metadataMaxDeliveryAttemptsKey] = val
+
+			_, err := createMetadata(m)
+
+			assert.Error(t, err)
+			assertValidErrorMessage(t, err)
+		}
+	})
 }
 
 func assertValidErrorMessage(t *testing.T, err error) {
@@ -15,24 +15,31 @@ package kafka
 
 import (
 	"context"
+	"errors"
 
 	"github.com/dapr/kit/logger"
 
+	"github.com/dapr/components-contrib/contenttype"
 	"github.com/dapr/components-contrib/internal/component/kafka"
 	"github.com/dapr/components-contrib/internal/utils"
 
 	"github.com/dapr/components-contrib/pubsub"
 )
 
+// deadLetterTopic is the metadata key of the Kafka dead-letter topic (DLT) read by SubscribeDeadLetters.
+const deadLetterTopic = "deadLetterTopic"
+
 type PubSub struct {
 	kafka           *kafka.Kafka
 	logger          logger.Logger
 	subscribeCtx    context.Context
 	subscribeCancel context.CancelFunc
+	deadLetterTopic string
 }
 
 func (p *PubSub) Init(metadata pubsub.Metadata) error {
 	p.subscribeCtx, p.subscribeCancel = context.WithCancel(context.Background())
+	p.deadLetterTopic = metadata.Properties[deadLetterTopic]
 
 	return p.kafka.Init(metadata.Properties)
 }
@@ -60,8 +67,44 @@ func (p *PubSub) BulkSubscribe(ctx context.Context, req pubsub.SubscribeRequest,
 	return p.subscribeUtil(ctx, req, handlerConfig)
 }
 
+// SubscribeDeadLetters delivers the messages of the dead-letter topic set in the "deadLetterTopic" metadata, such as the DLT
+// of a Kafka Connect sink or of another consumer of the topics. The topic holds the dead letters of all the topics, whatever req.Topic.
+// Their offsets are committed once the handler returns without error.
+func (p *PubSub) SubscribeDeadLetters(ctx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	if p.deadLetterTopic == "" {
+		return errors.New("kafka error: missing 'deadLetterTopic' metadata")
+	}
+	req.Topic = p.deadLetterTopic
+	return p.Subscribe(ctx, req, handler)
+}
+
+// SubscribePriority subscribes to the topics of req, handling the waiting messages of the topics listed first before the others.
+func (p *PubSub) SubscribePriority(ctx context.Context, req pubsub.PrioritySubscribeRequest, handler pubsub.Handler) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	// The consumer of each partition waits for its message to be handled, so the scheduler picks among the messages
+	// at the head of every partition.
+	scheduler := pubsub.NewPriorityScheduler(ctx, req)
+	handlerConfigs := make(map[string]kafka.SubscriptionHandlerConfig, len(req.Topics))
+	for i, topic := range req.Topics {
+		handlerConfigs[topic] = kafka.SubscriptionHandlerConfig{
+			IsBulkSubscribe: false,
+			Handler:         adaptHandler(scheduler.Handler(i, handler)),
+		}
+	}
+	return p.subscribeHandlers(ctx, handlerConfigs)
+}
+
 func (p *PubSub) subscribeUtil(ctx context.Context, req pubsub.SubscribeRequest, handlerConfig kafka.SubscriptionHandlerConfig) error {
-	p.kafka.AddTopicHandler(req.Topic, handlerConfig)
+	return p.subscribeHandlers(ctx, map[string]kafka.SubscriptionHandlerConfig{req.Topic: handlerConfig})
+}
+
+func (p *PubSub) subscribeHandlers(ctx context.Context, handlerConfigs map[string]kafka.SubscriptionHandlerConfig) error {
+	for topic, handlerConfig := range handlerConfigs {
+		p.kafka.AddTopicHandler(topic, handlerConfig)
+	}
 
 	go func() {
 		// Wait for context cancelation
@@ -70,8 +113,10 @@ func (p *PubSub) subscribeUtil(ctx context.Context, req pubsub.SubscribeRequest,
 		case <-p.subscribeCtx.Done():
 		}
 
-		// Remove the topic handler before restarting the subscriber
-		p.kafka.RemoveTopicHandler(req.Topic)
+		// Remove the topic handlers before restarting the subscriber
+		for topic := range handlerConfigs {
+			p.kafka.RemoveTopicHandler(topic)
+		}
 
 		// If the component's context has been canceled, do not re-subscribe
 		if p.subscribeCtx.Err() != nil {
@@ -100,12 +145,61 @@ func NewKafka(logger logger.Logger) pubsub.PubSub {
 
 // Publish message to Kafka cluster.
 func (p *PubSub) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
-	return p.kafka.Publish(ctx, req.Topic, req.Data, req.Metadata)
+	data, md := req.Data, req.Metadata
+	if pubsub.IsProtobufCloudEventRequested(req.ContentType, req.Metadata) {
+		var err error
+		data, err = pubsub.EncodeProtobufCloudEvent(req.Data)
+		if err != nil {
+			return err
+		}
+		md = withProtobufContentType(req.Metadata)
+	}
+
+	return p.kafka.Publish(ctx, req.Topic, data, md)
 }
 
 // BatchPublish messages to Kafka cluster.
 func (p *PubSub) BulkPublish(ctx context.Context, req *pubsub.BulkPublishRequest) (pubsub.BulkPublishResponse, error) {
-	return p.kafka.BulkPublish(ctx, req.Topic, req.Entries, req.Metadata)
+	entries, md := req.Entries, req.Metadata
+	// Headers are shared by all the messages of a bulk request, so the
+	// protobuf format can only be requested for the request as a whole.
+	if pubsub.IsProtobufCloudEventRequested(nil, req.Metadata) {
+		entries = make([]pubsub.BulkMessageEntry, len(req.Entries))
+		for i, entry := range req.Entries {
+			event, err := pubsub.EncodeProtobufCloudEvent(entry.Event)
+			if err != nil {
+				return pubsub.NewBulkPublishResponse(req.Entries, err), err
+			}
+			entry.Event = event
+			entry.ContentType = contenttype.CloudEventProtobufContentType
+			entries[i] = entry
+		}
+		md = withProtobufContentType(req.Metadata)
+	}
+
+	return p.kafka.BulkPublish(ctx, req.Topic, entries, md)
+}
+
+// withProtobufContentType returns a copy of the metadata with the content-type
+// header of a structured mode protobuf cloud event.
+func withProtobufContentType(metadata map[string]string) map[string]string {
+	md := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		md[k] = v
+	}
+	md[pubsub.ContentTypeHeader] = contenttype.CloudEventProtobufContentType
+
+	return md
+}
+
+// SetStatsRecorder records the messages handled again after a failure.
+func (p *PubSub) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	p.kafka.SetStatsRecorder(recorder)
+}
+
+// Drain stops consuming, waits for the messages being handled, then closes the consumer group.
+func (p *PubSub) Drain(ctx context.Context) error {
+	return p.kafka.Drain(ctx)
 }
 
 func (p *PubSub) Close() (err error) {
@@ -119,12 +213,17 @@ func (p *PubSub) Features() []pubsub.Feature {
 
 func adaptHandler(handler pubsub.Handler) kafka.EventHandler {
 	return func(ctx context.Context, event *kafka.NewEvent) error {
-		return handler(ctx, &pubsub.NewMessage{
+		msg := &pubsub.NewMessage{
 			Topic:       event.Topic,
 			Data:        event.Data,
 			Metadata:    event.Metadata,
 			ContentType: event.ContentType,
-		})
+		}
+		if err := pubsub.DecodeProtobufCloudEvent(msg, event.Metadata[pubsub.ContentTypeHeader]); err != nil {
+			return err
+		}
+
+		return handler(ctx, msg)
 	}
 }
 
@@ -138,6 +237,14 @@ func adaptBulkHandler(handler pubsub.BulkHandler) kafka.BulkEventHandler {
 				Metadata:    leafEvent.Metadata,
 				ContentType: leafEvent.ContentType,
 			}
+			if contenttype.IsCloudEventProtobufContentType(leafEvent.Metadata[pubsub.ContentTypeHeader]) {
+				data, err := pubsub.CloudEventFromProtobuf(leafEvent.Event)
+				if err != nil {
+					return nil, err
+				}
+				message.Event = data
+				message.ContentType = contenttype.CloudEventContentType
+			}
 			messages = append(messages, message)
 		}
 
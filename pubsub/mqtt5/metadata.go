@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	contribMetadata "github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+)
+
+type metadata struct {
+	pubsub.TLSProperties `mapstructure:"-"`
+
+	URL        string `mapstructure:"url"`
+	ConsumerID string `mapstructure:"consumerID"`
+	QOS        byte   `mapstructure:"qos"`
+	Retain     bool   `mapstructure:"retain"`
+	// If false, the broker keeps the session, including the subscriptions and the undelivered messages, for SessionExpiry after a disconnection.
+	CleanSession  bool          `mapstructure:"cleanSession"`
+	SessionExpiry time.Duration `mapstructure:"sessionExpiry"`
+	KeepAlive     time.Duration `mapstructure:"keepAlive"`
+	// Subscriptions are shared between the consumers of the group, so that each message is delivered to one of them only.
+	SharedSubscriptionGroup string `mapstructure:"sharedSubscriptionGroup"`
+	// Default expiry interval of the published messages. It can be overridden per message with "ttlInSeconds".
+	MessageExpiry            time.Duration `mapstructure:"messageExpiry"`
+	MaxRetriableErrorsPerSec int           `mapstructure:"maxRetriableErrorsPerSec"`
+	// If true, Init doesn't wait for the connection to the broker: the component reports its readiness instead.
+	AsyncInit bool `mapstructure:"asyncInit"`
+}
+
+const (
+	// Keys
+	mqttRetain = "retain"
+
+	// Metadata of the received messages
+	mqttRetained    = "retained"
+	mqttContentType = "contentType"
+
+	// Defaults
+	defaultQOS                      = 1
+	defaultKeepAlive                = 30 * time.Second
+	defaultWait                     = 30 * time.Second
+	defaultMaxRetriableErrorsPerSec = 10
+)
+
+func parseMQTTMetaData(md pubsub.Metadata) (*metadata, error) {
+	m := metadata{
+		QOS:                      defaultQOS,
+		KeepAlive:                defaultKeepAlive,
+		MaxRetriableErrorsPerSec: defaultMaxRetriableErrorsPerSec,
+	}
+	err := contribMetadata.DecodeMetadata(md.Properties, &m)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", errorMsgPrefix, err)
+	}
+
+	if m.URL == "" {
+		return nil, fmt.Errorf("%s missing url", errorMsgPrefix)
+	}
+	// Note: the runtime sets the default value to the Dapr app ID if empty
+	if m.ConsumerID == "" {
+		return nil, fmt.Errorf("%s missing consumerID", errorMsgPrefix)
+	}
+	if m.QOS > 2 {
+		return nil, fmt.Errorf("%s invalid qos %d", errorMsgPrefix, m.QOS)
+	}
+	if m.SessionExpiry < 0 || m.MessageExpiry < 0 {
+		return nil, errors.New(errorMsgPrefix + " sessionExpiry and messageExpiry must not be negative")
+	}
+
+	m.TLSProperties, err = pubsub.TLS(md.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("%s invalid TLS configuration: %w", errorMsgPrefix, err)
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,457 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"go.uber.org/ratelimit"
+
+	"github.com/dapr/components-contrib/health"
+	contribMetadata "github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// errors.
+	errorMsgPrefix = "mqtt5 pub sub error:"
+
+	sharedSubscriptionPrefix = "$share/"
+)
+
+// mqttPubSub type allows sending and receiving data to/from an MQTT 5 broker.
+type mqttPubSub struct {
+	conn              *autopaho.ConnectionManager
+	router            *paho.StandardRouter
+	metadata          *metadata
+	logger            logger.Logger
+	topics            map[string]struct{}
+	retriableErrLimit ratelimit.Limiter
+	subscribingLock   sync.Mutex
+	ctx               context.Context
+	cancel            context.CancelFunc
+	readiness         *health.ReadinessTracker
+}
+
+// NewMQTTPubSub returns a new mqttPubSub instance.
+func NewMQTTPubSub(logger logger.Logger) pubsub.PubSub {
+	return &mqttPubSub{
+		logger:    logger,
+		topics:    make(map[string]struct{}),
+		router:    paho.NewStandardRouter(),
+		readiness: health.NewReadinessTracker("connecting to the broker"),
+	}
+}
+
+// Init parses metadata and connects to the broker.
+func (m *mqttPubSub) Init(metadata pubsub.Metadata) error {
+	mqttMeta, err := parseMQTTMetaData(metadata)
+	if err != nil {
+		return err
+	}
+	m.metadata = mqttMeta
+
+	if m.metadata.MaxRetriableErrorsPerSec > 0 {
+		m.retriableErrLimit = ratelimit.New(m.metadata.MaxRetriableErrorsPerSec)
+	} else {
+		m.retriableErrLimit = ratelimit.NewUnlimited()
+	}
+
+	cfg, err := m.clientConfig()
+	if err != nil {
+		return err
+	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.conn, err = autopaho.NewConnection(m.ctx, cfg)
+	if err != nil {
+		m.cancel()
+		m.readiness.SetFailed(err)
+		return fmt.Errorf("%s failed to connect: %w", errorMsgPrefix, err)
+	}
+
+	if m.metadata.AsyncInit {
+		m.logger.Debug("mqtt5 message bus connecting in the background")
+		return nil
+	}
+
+	connCtx, connCancel := context.WithTimeout(m.ctx, defaultWait)
+	err = m.conn.AwaitConnection(connCtx)
+	connCancel()
+	if err != nil {
+		m.cancel()
+		m.readiness.SetFailed(err)
+		return fmt.Errorf("%s failed to connect: %w", errorMsgPrefix, err)
+	}
+
+	m.logger.Debug("mqtt5 message bus initialization complete")
+
+	return nil
+}
+
+func (m *mqttPubSub) clientConfig() (autopaho.ClientConfig, error) {
+	uri, err := url.Parse(m.metadata.URL)
+	if err != nil {
+		return autopaho.ClientConfig{}, fmt.Errorf("%s invalid url: %w", errorMsgPrefix, err)
+	}
+
+	tlsConfig, err := pubsub.ConvertTLSPropertiesToTLSConfig(m.metadata.TLSProperties)
+	if err != nil {
+		m.logger.Warnf("failed to load TLS config: %s", err)
+	}
+
+	cfg := autopaho.ClientConfig{
+		BrokerUrls:        []*url.URL{uri},
+		TlsCfg:            tlsConfig,
+		KeepAlive:         uint16(m.metadata.KeepAlive.Seconds()),
+		ConnectRetryDelay: 5 * time.Second,
+		ConnectTimeout:    defaultWait,
+		OnConnectionUp: func(conn *autopaho.ConnectionManager, _ *paho.Connack) {
+			m.readiness.SetReady()
+			m.resubscribe(conn)
+		},
+		OnConnectError: func(err error) {
+			m.logger.Warnf("mqtt5 connection error: %v", err)
+			m.readiness.SetInitializing("failed to connect to the broker: " + err.Error())
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID: m.metadata.ConsumerID,
+			Router:   m.router,
+			OnServerDisconnect: func(d *paho.Disconnect) {
+				m.logger.Warnf("mqtt5 disconnected by the server, reason code %d", d.ReasonCode)
+				m.readiness.SetInitializing(fmt.Sprintf("disconnected by the broker, reason code %d", d.ReasonCode))
+			},
+			OnClientError: func(err error) {
+				m.logger.Warnf("mqtt5 client error: %v", err)
+				m.readiness.SetInitializing("connection to the broker lost: " + err.Error())
+			},
+		},
+	}
+
+	if uri.User != nil {
+		password, _ := uri.User.Password()
+		cfg.SetUsernamePassword(uri.User.Username(), []byte(password))
+	}
+
+	sessionExpiry := uint32(m.metadata.SessionExpiry.Seconds())
+	cleanStart := m.metadata.CleanSession
+	cfg.SetConnectPacketConfigurator(func(c *paho.Connect) *paho.Connect {
+		c.CleanStart = cleanStart
+		if sessionExpiry > 0 {
+			if c.Properties == nil {
+				c.Properties = &paho.ConnectProperties{}
+			}
+			c.Properties.SessionExpiryInterval = &sessionExpiry
+		}
+		return c
+	})
+
+	return cfg, nil
+}
+
+// Publish the topic to mqtt pub sub.
+func (m *mqttPubSub) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
+	if req.Topic == "" {
+		return errors.New("topic name is empty")
+	}
+
+	m.logger.Debugf("mqtt5 publishing topic %s", req.Topic)
+
+	retain := m.metadata.Retain
+	if val, ok := req.Metadata[mqttRetain]; ok && val != "" {
+		var err error
+		retain, err = strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("mqtt invalid retain %s, %s", val, err)
+		}
+	}
+
+	props, err := m.publishProperties(req)
+	if err != nil {
+		return err
+	}
+
+	return m.publish(ctx, &paho.Publish{
+		Topic:      req.Topic,
+		QoS:        m.metadata.QOS,
+		Retain:     retain,
+		Payload:    req.Data,
+		Properties: props,
+	})
+}
+
+func (m *mqttPubSub) publish(ctx context.Context, p *paho.Publish) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultWait)
+	defer cancel()
+
+	err := m.conn.AwaitConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("mqtt timeout while publishing: %w", err)
+	}
+	_, err = m.conn.Publish(ctx, p)
+	if err != nil {
+		return fmt.Errorf("mqtt error from publish: %w", err)
+	}
+
+	return nil
+}
+
+// publishProperties returns the MQTT 5 properties of a message.
+// The metadata of the request, other than the keys interpreted by the component, are sent as user properties.
+func (m *mqttPubSub) publishProperties(req *pubsub.PublishRequest) (*paho.PublishProperties, error) {
+	props := &paho.PublishProperties{}
+
+	expiry := m.metadata.MessageExpiry
+	ttl, ok, err := contribMetadata.TryGetTTL(req.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", errorMsgPrefix, err)
+	}
+	if ok {
+		expiry = ttl
+	}
+	if expiry > 0 {
+		seconds := uint32(math.MaxUint32)
+		if s := expiry.Seconds(); s < math.MaxUint32 {
+			seconds = uint32(math.Ceil(s))
+		}
+		props.MessageExpiry = &seconds
+	}
+
+	if req.ContentType != nil {
+		props.ContentType = *req.ContentType
+	}
+
+	for k, v := range req.Metadata {
+		switch k {
+		case mqttRetain, contribMetadata.TTLMetadataKey:
+			continue
+		}
+		props.User.Add(k, v)
+	}
+
+	return props, nil
+}
+
+// Subscribe to the mqtt pub sub topic.
+func (m *mqttPubSub) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
+	if ctxErr := m.ctx.Err(); ctxErr != nil {
+		// If the global context has been canceled, we do not allow more subscriptions
+		return ctxErr
+	}
+
+	if req.Topic == "" {
+		return errors.New("topic name is empty")
+	}
+
+	filter := m.subscriptionFilter(req.Topic)
+
+	m.subscribingLock.Lock()
+	defer m.subscribingLock.Unlock()
+
+	m.router.RegisterHandler(filter, m.onMessage(handler))
+	m.topics[filter] = struct{}{}
+
+	subscribeCtx, subscribeCancel := context.WithTimeout(ctx, defaultWait)
+	defer subscribeCancel()
+	err := m.subscribe(subscribeCtx, m.conn, filter)
+	if err != nil {
+		m.router.UnregisterHandler(filter)
+		delete(m.topics, filter)
+		return err
+	}
+
+	// Listen for context cancelation to remove the subscription
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-m.ctx.Done():
+			return
+		}
+
+		m.subscribingLock.Lock()
+		defer m.subscribingLock.Unlock()
+
+		m.router.UnregisterHandler(filter)
+		delete(m.topics, filter)
+
+		unsubscribeCtx, unsubscribeCancel := context.WithTimeout(m.ctx, defaultWait)
+		defer unsubscribeCancel()
+		_, err := m.conn.Unsubscribe(unsubscribeCtx, &paho.Unsubscribe{Topics: []string{filter}})
+		if err != nil {
+			m.logger.Warnf("mqtt5 error unsubscribing from %s: %v", filter, err)
+		}
+	}()
+
+	return nil
+}
+
+// subscriptionFilter returns the topic filter to subscribe to, making the subscription shared if a group is configured.
+func (m *mqttPubSub) subscriptionFilter(topic string) string {
+	if m.metadata.SharedSubscriptionGroup == "" || strings.HasPrefix(topic, sharedSubscriptionPrefix) {
+		return topic
+	}
+	return sharedSubscriptionPrefix + m.metadata.SharedSubscriptionGroup + "/" + topic
+}
+
+func (m *mqttPubSub) subscribe(ctx context.Context, conn *autopaho.ConnectionManager, filters ...string) error {
+	subs := make(map[string]paho.SubscribeOptions, len(filters))
+	for _, f := range filters {
+		subs[f] = paho.SubscribeOptions{QoS: m.metadata.QOS}
+	}
+
+	err := conn.AwaitConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("mqtt error from subscribe: %w", err)
+	}
+	suback, err := conn.Subscribe(ctx, &paho.Subscribe{Subscriptions: subs})
+	if err != nil {
+		return fmt.Errorf("mqtt error from subscribe: %w", err)
+	}
+	for i, code := range suback.Reasons {
+		if code >= 0x80 {
+			return fmt.Errorf("mqtt error from subscribe: reason code %d for subscription %d", code, i)
+		}
+	}
+
+	return nil
+}
+
+// resubscribe restores the subscriptions after a reconnection.
+func (m *mqttPubSub) resubscribe(conn *autopaho.ConnectionManager) {
+	m.subscribingLock.Lock()
+	filters := make([]string, 0, len(m.topics))
+	for f := range m.topics {
+		filters = append(filters, f)
+	}
+	m.subscribingLock.Unlock()
+
+	if len(filters) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, defaultWait)
+	defer cancel()
+	err := m.subscribe(ctx, conn, filters...)
+	if err != nil {
+		m.logger.Errorf("mqtt5 failed to restore the subscriptions: %v", err)
+	}
+}
+
+// onMessage returns the callback to be invoked when there's a new message from a topic.
+// Messages are acknowledged when the callback returns.
+func (m *mqttPubSub) onMessage(handler pubsub.Handler) paho.MessageHandler {
+	return func(p *paho.Publish) {
+		msg := pubsub.NewMessage{
+			Topic:    p.Topic,
+			Data:     p.Payload,
+			Metadata: messageMetadata(p),
+		}
+
+		m.logger.Debugf("Processing MQTT message %s#%d (retained=%v)", p.Topic, p.PacketID, p.Retain)
+		err := handler(m.ctx, &msg)
+		if err == nil {
+			m.logger.Debugf("Done processing MQTT message %s#%d", p.Topic, p.PacketID)
+			return
+		}
+		m.logger.Errorf("Failed processing MQTT message %s#%d: %v", p.Topic, p.PacketID, err)
+
+		// MQTT does not support NACK's, so in case of error we re-enqueue the message before it is acknowledged
+		m.logger.Debugf("Re-publishing message %s#%d", p.Topic, p.PacketID)
+		err = m.publish(m.ctx, &paho.Publish{
+			Topic:      p.Topic,
+			QoS:        m.metadata.QOS,
+			Payload:    p.Payload,
+			Properties: republishProperties(p.Properties),
+		})
+		if err != nil {
+			m.logger.Errorf("Failed to re-publish message %s#%d. Error: %v", p.Topic, p.PacketID, err)
+		}
+
+		m.logger.Debugf("Taking a retriable error token")
+		before := time.Now()
+		_ = m.retriableErrLimit.Take()
+		m.logger.Debugf("Resumed after pausing for %v", time.Since(before))
+	}
+}
+
+// messageMetadata returns the metadata of a received message, which include its user properties.
+func messageMetadata(p *paho.Publish) map[string]string {
+	md := map[string]string{}
+	if p.Properties != nil {
+		for _, u := range p.Properties.User {
+			md[u.Key] = u.Value
+		}
+		if p.Properties.ContentType != "" {
+			md[mqttContentType] = p.Properties.ContentType
+		}
+	}
+	md[mqttRetained] = strconv.FormatBool(p.Retain)
+	return md
+}
+
+// republishProperties returns the properties of a message to re-publish, which retains its remaining expiry.
+func republishProperties(props *paho.PublishProperties) *paho.PublishProperties {
+	if props == nil {
+		return nil
+	}
+	return &paho.PublishProperties{
+		ContentType:     props.ContentType,
+		CorrelationData: props.CorrelationData,
+		ResponseTopic:   props.ResponseTopic,
+		PayloadFormat:   props.PayloadFormat,
+		MessageExpiry:   props.MessageExpiry,
+		User:            props.User,
+	}
+}
+
+func (m *mqttPubSub) Close() error {
+	m.subscribingLock.Lock()
+	defer m.subscribingLock.Unlock()
+
+	if m.conn == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := m.conn.Disconnect(ctx)
+	m.cancel()
+
+	return err
+}
+
+// Readiness returns the state of the connection to the broker.
+func (m *mqttPubSub) Readiness() health.Readiness {
+	return m.readiness.Readiness()
+}
+
+// WaitReady blocks until the connection to the broker is up.
+func (m *mqttPubSub) WaitReady(ctx context.Context) error {
+	return m.readiness.WaitReady(ctx)
+}
+
+func (m *mqttPubSub) Features() []pubsub.Feature {
+	return []pubsub.Feature{pubsub.FeatureSubscribeWildcards, pubsub.FeatureMessageTTL}
+}
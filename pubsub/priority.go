@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const defaultStarvationThreshold = 10
+
+// PrioritySubscriber is implemented by components able to consume several topics by priority,
+// for job-queue style workloads where urgent messages overtake the backlog of the other topics.
+type PrioritySubscriber interface {
+	// SubscribePriority delivers the messages of req.Topics to the handler, preferring the topics listed first.
+	SubscribePriority(ctx context.Context, req PrioritySubscribeRequest, handler Handler) error
+}
+
+// PrioritySubscribeRequest is the request to subscribe to topics by priority.
+type PrioritySubscribeRequest struct {
+	// Topics ordered by decreasing priority, e.g. "jobs-high", "jobs-normal", "jobs-low".
+	Topics []string
+	// Metadata of the subscriptions to each topic.
+	Metadata map[string]string
+	// Maximum number of messages handled at the same time. Defaults to 1.
+	MaxConcurrentHandlers int
+	// Number of messages of higher priorities handled while a message of a lower priority is waiting,
+	// after which the waiting message is handled, so that low priority topics aren't starved. Defaults to 10.
+	StarvationThreshold int
+}
+
+// Validate checks the request and sets the defaults.
+func (r *PrioritySubscribeRequest) Validate() error {
+	if len(r.Topics) == 0 {
+		return errors.New("no topics to subscribe to")
+	}
+	seen := make(map[string]bool, len(r.Topics))
+	for _, t := range r.Topics {
+		if t == "" || seen[t] {
+			return errors.New("the topics must be distinct and non-empty")
+		}
+		seen[t] = true
+	}
+	if r.MaxConcurrentHandlers <= 0 {
+		r.MaxConcurrentHandlers = 1
+	}
+	if r.StarvationThreshold <= 0 {
+		r.StarvationThreshold = defaultStarvationThreshold
+	}
+	return nil
+}
+
+// PriorityScheduler runs the message handlers of several priorities: of the messages waiting to be handled,
+// the one with the highest priority is handled first, unless a message of a lower priority waited for
+// StarvationThreshold messages.
+// Components wrap the handlers of each topic with Handler; the wrapped handlers block until the message is handled,
+// so components should limit the number of messages they deliver at once, e.g. with a prefetch count.
+type PriorityScheduler struct {
+	lock      sync.Mutex
+	cond      *sync.Cond
+	queues    [][]*priorityTask
+	skipped   []int
+	threshold int
+	stopped   bool
+}
+
+type priorityTask struct {
+	fn      func() error
+	done    chan error
+	started bool
+}
+
+// NewPriorityScheduler returns a scheduler for the priorities of req.Topics, running req.MaxConcurrentHandlers
+// handlers at most until ctx is done.
+func NewPriorityScheduler(ctx context.Context, req PrioritySubscribeRequest) *PriorityScheduler {
+	s := &PriorityScheduler{
+		queues:    make([][]*priorityTask, len(req.Topics)),
+		skipped:   make([]int, len(req.Topics)),
+		threshold: req.StarvationThreshold,
+	}
+	s.cond = sync.NewCond(&s.lock)
+	for i := 0; i < req.MaxConcurrentHandlers; i++ {
+		go s.work()
+	}
+	go func() {
+		<-ctx.Done()
+		s.lock.Lock()
+		s.stopped = true
+		s.lock.Unlock()
+		s.cond.Broadcast()
+	}()
+	return s
+}
+
+// Handler returns the handler of the messages of the topic with the given priority, the index of the topic in the request.
+func (s *PriorityScheduler) Handler(priority int, handler Handler) Handler {
+	return func(ctx context.Context, msg *NewMessage) error {
+		return s.run(ctx, priority, func() error {
+			return handler(ctx, msg)
+		})
+	}
+}
+
+// run waits for fn to be scheduled and returns its result.
+func (s *PriorityScheduler) run(ctx context.Context, priority int, fn func() error) error {
+	t := &priorityTask{fn: fn, done: make(chan error, 1)}
+	s.lock.Lock()
+	if s.stopped {
+		s.lock.Unlock()
+		return context.Canceled
+	}
+	s.queues[priority] = append(s.queues[priority], t)
+	s.lock.Unlock()
+	s.cond.Signal()
+
+	select {
+	case err := <-t.done:
+		return err
+	case <-ctx.Done():
+		s.lock.Lock()
+		if !t.started {
+			s.remove(priority, t)
+			s.lock.Unlock()
+			return ctx.Err()
+		}
+		s.lock.Unlock()
+		return <-t.done
+	}
+}
+
+func (s *PriorityScheduler) work() {
+	for {
+		s.lock.Lock()
+		t := s.next()
+		for t == nil && !s.stopped {
+			s.cond.Wait()
+			t = s.next()
+		}
+		if t == nil {
+			// Stopped: the waiting handlers return with their contexts.
+			s.lock.Unlock()
+			return
+		}
+		t.started = true
+		s.lock.Unlock()
+
+		t.done <- t.fn()
+	}
+}
+
+// next removes the next task to run from the queues; it must be called with the lock held.
+func (s *PriorityScheduler) next() *priorityTask {
+	chosen := -1
+	for i, q := range s.queues {
+		if len(q) == 0 {
+			continue
+		}
+		if chosen < 0 {
+			chosen = i
+		}
+		if s.skipped[i] >= s.threshold {
+			// The highest priority starved for too long goes first.
+			chosen = i
+			break
+		}
+	}
+	if chosen < 0 {
+		return nil
+	}
+
+	t := s.queues[chosen][0]
+	s.queues[chosen] = s.queues[chosen][1:]
+	for i, q := range s.queues {
+		switch {
+		case i == chosen, len(q) == 0:
+			s.skipped[i] = 0
+		case i > chosen:
+			s.skipped[i]++
+		}
+	}
+	return t
+}
+
+func (s *PriorityScheduler) remove(priority int, t *priorityTask) {
+	q := s.queues[priority]
+	for i := range q {
+		if q[i] == t {
+			s.queues[priority] = append(q[:i:i], q[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queued returns the number of messages waiting in the queues of s.
+func queued(s *PriorityScheduler) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n := 0
+	for _, q := range s.queues {
+		n += len(q)
+	}
+	return n
+}
+
+func TestPriorityScheduler(t *testing.T) {
+	schedule := func(t *testing.T, threshold int, messages map[int]int) []int {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req := PrioritySubscribeRequest{Topics: []string{"high", "normal", "low"}, StarvationThreshold: threshold}
+		require.NoError(t, req.Validate())
+		s := NewPriorityScheduler(ctx, req)
+
+		// Keep the only worker busy while the messages are queued.
+		release := make(chan struct{})
+		go s.run(ctx, 0, func() error {
+			<-release
+			return nil
+		})
+		require.Eventually(t, func() bool {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			return len(s.queues[0]) == 0
+		}, time.Second, time.Millisecond)
+
+		var (
+			lock  sync.Mutex
+			order []int
+			wg    sync.WaitGroup
+			total int
+		)
+		for priority, n := range messages {
+			priority := priority
+			handler := s.Handler(priority, func(ctx context.Context, msg *NewMessage) error {
+				lock.Lock()
+				order = append(order, priority)
+				lock.Unlock()
+				return nil
+			})
+			for i := 0; i < n; i++ {
+				total++
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					assert.NoError(t, handler(ctx, &NewMessage{}))
+				}()
+			}
+		}
+		require.Eventually(t, func() bool { return queued(s) == total }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+		return order
+	}
+
+	t.Run("higher priorities first", func(t *testing.T) {
+		order := schedule(t, 100, map[int]int{0: 3, 1: 2, 2: 2})
+		assert.Equal(t, []int{0, 0, 0, 1, 1, 2, 2}, order)
+	})
+
+	t.Run("starvation protection", func(t *testing.T) {
+		order := schedule(t, 2, map[int]int{0: 6, 2: 2})
+		assert.Equal(t, []int{0, 0, 2, 0, 0, 2, 0, 0}, order)
+	})
+}
+
+func TestPrioritySchedulerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := PrioritySubscribeRequest{Topics: []string{"high", "low"}}
+	require.NoError(t, req.Validate())
+	s := NewPriorityScheduler(ctx, req)
+
+	errHandler := errors.New("handler error")
+	err := s.Handler(1, func(ctx context.Context, msg *NewMessage) error { return errHandler })(ctx, &NewMessage{})
+	assert.ErrorIs(t, err, errHandler)
+
+	release := make(chan struct{})
+	defer close(release)
+	go s.run(ctx, 0, func() error {
+		<-release
+		return nil
+	})
+	require.Eventually(t, func() bool { return queued(s) == 0 }, time.Second, time.Millisecond)
+
+	// A message still waiting when its context is done is dropped from the queue.
+	msgCtx, msgCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer msgCancel()
+	err = s.Handler(1, func(ctx context.Context, msg *NewMessage) error { return nil })(msgCtx, &NewMessage{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, queued(s))
+}
+
+func TestPrioritySubscribeRequestValidate(t *testing.T) {
+	req := PrioritySubscribeRequest{Topics: []string{"a", "b"}}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, 1, req.MaxConcurrentHandlers)
+	assert.Equal(t, defaultStarvationThreshold, req.StarvationThreshold)
+
+	assert.Error(t, (&PrioritySubscribeRequest{}).Validate())
+	assert.Error(t, (&PrioritySubscribeRequest{Topics: []string{"a", "a"}}).Validate())
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/apache/pulsar-client-go/pulsar"
 	lru "github.com/hashicorp/golang-lru"
 
+	"github.com/dapr/components-contrib/contenttype"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
 )
@@ -240,6 +241,15 @@ func parsePublishMetadata(req *pubsub.PublishRequest) (
 	msg = &pulsar.ProducerMessage{
 		Payload: req.Data,
 	}
+	if pubsub.IsProtobufCloudEventRequested(req.ContentType, req.Metadata) {
+		msg.Payload, err = pubsub.EncodeProtobufCloudEvent(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Properties = map[string]string{
+			pubsub.ContentTypeHeader: contenttype.CloudEventProtobufContentType,
+		}
+	}
 	if val, ok := req.Metadata[deliverAt]; ok {
 		msg.DeliverAt, err = time.Parse(time.RFC3339, val)
 		if err != nil {
@@ -304,9 +314,14 @@ func (p *Pulsar) handleMessage(ctx context.Context, originTopic string, msg puls
 		Topic:    originTopic,
 		Metadata: msg.Properties(),
 	}
+	err := pubsub.DecodeProtobufCloudEvent(&pubsubMsg, msg.Properties()[pubsub.ContentTypeHeader])
+	if err != nil {
+		msg.Nack(msg.Message)
+		return err
+	}
 
 	p.logger.Debugf("Processing Pulsar message %s/%#v", msg.Topic(), msg.ID())
-	err := handler(ctx, &pubsubMsg)
+	err = handler(ctx, &pubsubMsg)
 	if err != nil {
 		msg.Nack(msg.Message)
 		return err
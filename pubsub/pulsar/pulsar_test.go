@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/pubsub"
 )
@@ -60,6 +61,20 @@ func TestParsePublishMetadata(t *testing.T) {
 		msg.DeliverAt.Format(time.RFC3339))
 }
 
+func TestParsePublishMetadataProtobufCloudEvent(t *testing.T) {
+	m := &pubsub.PublishRequest{
+		Data:     []byte(`{"id":"1","source":"app","specversion":"1.0","type":"t","data":"hi"}`),
+		Metadata: map[string]string{"contentType": "application/cloudevents+protobuf"},
+	}
+	msg, err := parsePublishMetadata(m)
+	require.NoError(t, err)
+	assert.Equal(t, "application/cloudevents+protobuf", msg.Properties["content-type"])
+
+	event, err := pubsub.CloudEventFromProtobuf(msg.Payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(m.Data), string(event))
+}
+
 func TestMissingHost(t *testing.T) {
 	m := pubsub.Metadata{}
 	m.Properties = map[string]string{"host": ""}
@@ -21,6 +21,7 @@ import (
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/kit/logger"
 
 	contribMetadata "github.com/dapr/components-contrib/metadata"
@@ -49,6 +50,8 @@ type metadata struct {
 	publisherConfirm bool
 	concurrency      pubsub.ConcurrencyMode
 	defaultQueueTTL  *time.Duration
+	publishBatchSize int
+	publishBatchWait time.Duration
 }
 
 const (
@@ -104,14 +107,14 @@ func createMetadata(pubSubMetadata pubsub.Metadata, log logger.Logger) (*metadat
 	if result.connectionString != "" {
 		uri, err := amqp.ParseURI(result.connectionString)
 		if err != nil {
-			return &result, fmt.Errorf("%s invalid connection string: %s, err: %w", errorMessagePrefix, result.connectionString, err)
+			return &result, fmt.Errorf("%s invalid connection string: %s, err: %w", errorMessagePrefix, redact.ConnectionString(result.connectionString), err)
 		}
 		result.protocol = uri.Scheme
 	}
 
 	if val, found := pubSubMetadata.Properties[metadataProtocolKey]; found && val != "" {
 		if result.connectionString != "" && result.protocol != val {
-			return &result, fmt.Errorf("%s protocol does not match connection string, protocol: %s, connection string: %s", errorMessagePrefix, val, result.connectionString)
+			return &result, fmt.Errorf("%s protocol does not match connection string, protocol: %s, connection string: %s", errorMessagePrefix, val, redact.ConnectionString(result.connectionString))
 		}
 		result.protocol = val
 	}
@@ -229,6 +232,11 @@ func createMetadata(pubSubMetadata pubsub.Metadata, log logger.Logger) (*metadat
 	}
 	result.concurrency = c
 
+	result.publishBatchSize, result.publishBatchWait, err = pubsub.PublishBatching(pubSubMetadata.Properties)
+	if err != nil {
+		return &result, fmt.Errorf("%s %w", errorMessagePrefix, err)
+	}
+
 	return &result, nil
 }
 
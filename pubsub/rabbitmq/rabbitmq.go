@@ -25,6 +25,7 @@ import (
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/kit/logger"
 
 	contribMetadata "github.com/dapr/components-contrib/metadata"
@@ -61,6 +62,9 @@ type rabbitMQ struct {
 	declaredExchanges map[string]bool
 	ctx               context.Context
 	cancel            context.CancelFunc
+	batcher           *pubsub.PublishBatcher
+	inFlight          pubsub.InFlight
+	stats             pubsub.StatsRecorder
 
 	connectionDial func(protocol, uri string, tlsCfg *tls.Config) (rabbitMQConnectionBroker, rabbitMQChannelBroker, error)
 
@@ -126,6 +130,11 @@ func dial(protocol, uri string, tlsCfg *tls.Config) (rabbitMQConnectionBroker, r
 
 // Init does metadata parsing and connection creation.
 func (r *rabbitMQ) Init(metadata pubsub.Metadata) error {
+	// The connection string and the password must not appear in the logs.
+	rl := redact.NewLogger(r.logger)
+	rl.AddMetadataSecrets(metadata.Properties)
+	r.logger = rl
+
 	meta, err := createMetadata(metadata, r.logger)
 	if err != nil {
 		return err
@@ -135,6 +144,10 @@ func (r *rabbitMQ) Init(metadata pubsub.Metadata) error {
 
 	r.metadata = meta
 
+	if meta.publishBatchSize > 1 {
+		r.batcher = pubsub.NewPublishBatcher(meta.publishBatchSize, meta.publishBatchWait, r.publishBatch)
+	}
+
 	r.reconnect(0)
 	// We do not return error on reconnect because it can cause problems if init() happens
 	// right at the restart window for service. So, we try it now but there is logic in the
@@ -192,19 +205,8 @@ func (r *rabbitMQ) reconnect(connectionCount int) error {
 	return nil
 }
 
-func (r *rabbitMQ) publishSync(ctx context.Context, req *pubsub.PublishRequest) (rabbitMQChannelBroker, int, error) {
-	r.channelMutex.Lock()
-	defer r.channelMutex.Unlock()
-
-	if r.channel == nil {
-		return r.channel, r.connectionCount, errors.New(errorChannelNotInitialized)
-	}
-
-	if err := r.ensureExchangeDeclared(r.channel, req.Topic, r.metadata.exchangeKind); err != nil {
-		r.logger.Errorf("%s publishing to %s failed in ensureExchangeDeclared: %v", logMessagePrefix, req.Topic, err)
-
-		return r.channel, r.connectionCount, err
-	}
+// publishing returns the routing key and message to publish for req.
+func (r *rabbitMQ) publishing(req *pubsub.PublishRequest) (string, amqp.Publishing) {
 	routingKey := ""
 	if val, ok := req.Metadata[reqMetadataRoutingKey]; ok && val != "" {
 		routingKey = val
@@ -222,12 +224,30 @@ func (r *rabbitMQ) publishSync(ctx context.Context, req *pubsub.PublishRequest)
 		expiration = strconv.FormatInt(r.metadata.defaultQueueTTL.Milliseconds(), 10)
 	}
 
-	confirm, err := r.channel.PublishWithDeferredConfirmWithContext(ctx, req.Topic, routingKey, false, false, amqp.Publishing{
+	return routingKey, amqp.Publishing{
 		ContentType:  "text/plain",
 		Body:         req.Data,
 		DeliveryMode: r.metadata.deliveryMode,
 		Expiration:   expiration,
-	})
+	}
+}
+
+func (r *rabbitMQ) publishSync(ctx context.Context, req *pubsub.PublishRequest) (rabbitMQChannelBroker, int, error) {
+	r.channelMutex.Lock()
+	defer r.channelMutex.Unlock()
+
+	if r.channel == nil {
+		return r.channel, r.connectionCount, errors.New(errorChannelNotInitialized)
+	}
+
+	if err := r.ensureExchangeDeclared(r.channel, req.Topic, r.metadata.exchangeKind); err != nil {
+		r.logger.Errorf("%s publishing to %s failed in ensureExchangeDeclared: %v", logMessagePrefix, req.Topic, err)
+
+		return r.channel, r.connectionCount, err
+	}
+
+	routingKey, msg := r.publishing(req)
+	confirm, err := r.channel.PublishWithDeferredConfirmWithContext(ctx, req.Topic, routingKey, false, false, msg)
 	if err != nil {
 		r.logger.Errorf("%s publishing to %s failed in channel.Publish: %v", logMessagePrefix, req.Topic, err)
 
@@ -247,9 +267,98 @@ func (r *rabbitMQ) publishSync(ctx context.Context, req *pubsub.PublishRequest)
 	return r.channel, r.connectionCount, nil
 }
 
+// publishBatchSync publishes all messages before waiting for their confirmations, so the batch costs a single round trip.
+// It returns the error of each message.
+func (r *rabbitMQ) publishBatchSync(ctx context.Context, reqs []*pubsub.PublishRequest) (rabbitMQChannelBroker, int, []error) {
+	r.channelMutex.Lock()
+	defer r.channelMutex.Unlock()
+
+	errs := make([]error, len(reqs))
+	if r.channel == nil {
+		for i := range errs {
+			errs[i] = errors.New(errorChannelNotInitialized)
+		}
+		return r.channel, r.connectionCount, errs
+	}
+
+	confirms := make([]*amqp.DeferredConfirmation, len(reqs))
+	for i, req := range reqs {
+		if errs[i] = r.ensureExchangeDeclared(r.channel, req.Topic, r.metadata.exchangeKind); errs[i] != nil {
+			r.logger.Errorf("%s publishing to %s failed in ensureExchangeDeclared: %v", logMessagePrefix, req.Topic, errs[i])
+			continue
+		}
+
+		routingKey, msg := r.publishing(req)
+		confirms[i], errs[i] = r.channel.PublishWithDeferredConfirmWithContext(ctx, req.Topic, routingKey, false, false, msg)
+		if errs[i] != nil {
+			r.logger.Errorf("%s publishing to %s failed in channel.Publish: %v", logMessagePrefix, req.Topic, errs[i])
+		}
+	}
+
+	// confirms are nil if are not requesting publish confirmations
+	for i, confirm := range confirms {
+		if confirm != nil && !confirm.Wait() {
+			errs[i] = fmt.Errorf("did not receive confirmation of publishing")
+			r.logger.Errorf("%s publishing to %s failed: %v", logMessagePrefix, reqs[i].Topic, errs[i])
+		}
+	}
+
+	return r.channel, r.connectionCount, errs
+}
+
+// publishBatch publishes a batch collected by the batcher, retrying the messages that failed.
+func (r *rabbitMQ) publishBatch(ctx context.Context, reqs []*pubsub.PublishRequest) []error {
+	errs := make([]error, len(reqs))
+	pending := make([]int, len(reqs))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for attempt := 1; ; attempt++ {
+		batch := make([]*pubsub.PublishRequest, len(pending))
+		for i, idx := range pending {
+			batch[i] = reqs[idx]
+		}
+
+		channel, connectionCount, batchErrs := r.publishBatchSync(ctx, batch)
+		var (
+			failed    []int
+			reconnect bool
+		)
+		for i, err := range batchErrs {
+			errs[pending[i]] = err
+			if err != nil {
+				failed = append(failed, pending[i])
+				reconnect = reconnect || mustReconnect(channel, err)
+			}
+		}
+		if len(failed) == 0 {
+			return errs
+		}
+		if attempt >= publishMaxRetries {
+			r.logger.Errorf("%s publishing failed for %d messages of the batch", logMessagePrefix, len(failed))
+			return errs
+		}
+
+		pending = failed
+		if reconnect {
+			r.logger.Warnf("%s publisher is reconnecting in %s ...", logMessagePrefix, r.metadata.reconnectWait.String())
+			time.Sleep(r.metadata.reconnectWait)
+			r.reconnect(connectionCount)
+		} else {
+			r.logger.Warnf("%s publishing attempt (%d/%d) failed for %d messages of the batch", logMessagePrefix, attempt, publishMaxRetries, len(failed))
+			time.Sleep(publishRetryWaitSeconds * time.Second)
+		}
+	}
+}
+
 func (r *rabbitMQ) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
 	r.logger.Debugf("%s publishing message to %s", logMessagePrefix, req.Topic)
 
+	if r.batcher != nil {
+		return r.batcher.Publish(ctx, req)
+	}
+
 	attempt := 0
 	for {
 		attempt++
@@ -277,7 +386,7 @@ func (r *rabbitMQ) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, h
 		return errors.New("consumerID is required for subscriptions")
 	}
 
-	queueName := fmt.Sprintf("%s-%s", r.metadata.consumerID, req.Topic)
+	queueName := r.queueName(req.Topic)
 	r.logger.Infof("%s subscribe to topic/queue '%s/%s'", logMessagePrefix, req.Topic, queueName)
 
 	// Do not set a timeout on the context, as we're just waiting for the first ack; we're using a semaphore instead
@@ -294,6 +403,23 @@ func (r *rabbitMQ) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, h
 	}
 }
 
+// SubscribePriority consumes the queues of the topics of req, handling the waiting messages of the topics listed first before the others.
+// With the parallel concurrency mode, the prefetchCount metadata bounds the number of messages waiting to be handled.
+func (r *rabbitMQ) SubscribePriority(ctx context.Context, req pubsub.PrioritySubscribeRequest, handler pubsub.Handler) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	scheduler := pubsub.NewPriorityScheduler(ctx, req)
+	for i, topic := range req.Topics {
+		err := r.Subscribe(ctx, pubsub.SubscribeRequest{Topic: topic, Metadata: req.Metadata}, scheduler.Handler(i, handler))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // this function call should be wrapped by channelMutex.
 func (r *rabbitMQ) prepareSubscription(channel rabbitMQChannelBroker, req pubsub.SubscribeRequest, queueName string) (*amqp.Queue, error) {
 	err := r.ensureExchangeDeclared(channel, req.Topic, r.metadata.exchangeKind)
@@ -434,6 +560,11 @@ func (r *rabbitMQ) subscribeForever(ctx context.Context, req pubsub.SubscribeReq
 			return
 		}
 
+		if errors.Is(err, pubsub.ErrDraining) {
+			r.logger.Infof("%s subscriber for %s is draining", logMessagePrefix, queueName)
+			return
+		}
+
 		if r.isStopped() {
 			r.logger.Infof("%s subscriber for %s is stopped", logMessagePrefix, queueName)
 			return
@@ -454,10 +585,13 @@ func (r *rabbitMQ) subscribeForever(ctx context.Context, req pubsub.SubscribeReq
 
 func (r *rabbitMQ) listenMessages(ctx context.Context, channel rabbitMQChannelBroker, msgCh <-chan amqp.Delivery, topic string, handler pubsub.Handler) error {
 	var err error
+	draining := r.inFlight.Draining()
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-draining:
+			return pubsub.ErrDraining
 		case d, more := <-msgCh:
 			// Handle case of channel closed
 			if !more {
@@ -465,12 +599,18 @@ func (r *rabbitMQ) listenMessages(ctx context.Context, channel rabbitMQChannelBr
 				return nil
 			}
 
+			if !r.inFlight.Begin() {
+				return r.releaseMessage(ctx, d, topic, handler)
+			}
+
 			switch r.metadata.concurrency {
 			case pubsub.Single:
 				err = r.handleMessage(ctx, d, topic, handler)
+				r.inFlight.End()
 			case pubsub.Parallel:
 				go func(d amqp.Delivery) {
 					err = r.handleMessage(ctx, d, topic, handler)
+					r.inFlight.End()
 				}(d)
 			}
 			if err != nil && mustReconnect(channel, err) {
@@ -480,12 +620,31 @@ func (r *rabbitMQ) listenMessages(ctx context.Context, channel rabbitMQChannelBr
 	}
 }
 
+// releaseMessage gives a message received while draining back to the broker for another consumer.
+// Messages which were acknowledged on delivery cannot be requeued, so they are handled instead.
+func (r *rabbitMQ) releaseMessage(ctx context.Context, d amqp.Delivery, topic string, handler pubsub.Handler) error {
+	if r.metadata.autoAck {
+		r.handleMessage(ctx, d, topic, handler)
+	} else {
+		r.logger.Debugf("%s requeuing message '%s' from topic '%s' received while draining", logMessagePrefix, d.MessageId, topic)
+		if err := d.Nack(false, true); err != nil {
+			r.logger.Errorf("%s error requeuing message '%s' from topic '%s', %s", logMessagePrefix, d.MessageId, topic, err)
+		}
+	}
+
+	return pubsub.ErrDraining
+}
+
 func (r *rabbitMQ) handleMessage(ctx context.Context, d amqp.Delivery, topic string, handler pubsub.Handler) error {
 	pubsubMsg := &pubsub.NewMessage{
 		Data:  d.Body,
 		Topic: topic,
 	}
 
+	if d.Redelivered && r.stats != nil {
+		r.stats.RecordRetry(topic)
+	}
+
 	err := handler(ctx, pubsubMsg)
 
 	if err != nil {
@@ -496,6 +655,9 @@ func (r *rabbitMQ) handleMessage(ctx context.Context, d amqp.Delivery, topic str
 			r.logger.Debugf("%s nacking message '%s' from topic '%s', requeue=%t", logMessagePrefix, d.MessageId, topic, r.metadata.requeueInFailure)
 			if err = d.Nack(false, r.metadata.requeueInFailure); err != nil {
 				r.logger.Errorf("%s error nacking message '%s' from topic '%s', %s", logMessagePrefix, d.MessageId, topic, err)
+			} else if !r.metadata.requeueInFailure && r.metadata.enableDeadLetter && r.stats != nil {
+				// Rejected messages are routed to the dead letter exchange of the queue
+				r.stats.RecordDeadLetter(topic, fmt.Sprintf(defaultDeadLetterExchangeFormat, r.queueName(topic)))
 			}
 		}
 	} else if !r.metadata.autoAck {
@@ -509,6 +671,16 @@ func (r *rabbitMQ) handleMessage(ctx context.Context, d amqp.Delivery, topic str
 	return err
 }
 
+// SetStatsRecorder records the redelivered messages, and the failed messages routed to the dead letter exchange when enableDeadLetter is set.
+func (r *rabbitMQ) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	r.stats = recorder
+}
+
+// queueName returns the name of the queue of the subscription to topic.
+func (r *rabbitMQ) queueName(topic string) string {
+	return fmt.Sprintf("%s-%s", r.metadata.consumerID, topic)
+}
+
 // this function call should be wrapped by channelMutex.
 func (r *rabbitMQ) ensureExchangeDeclared(channel rabbitMQChannelBroker, exchange, exchangeKind string) error {
 	if !r.containsExchange(exchange) {
@@ -567,7 +739,27 @@ func (r *rabbitMQ) isStopped() bool {
 	return r.ctx.Err() != nil
 }
 
+// Drain stops consuming the queues, waits for the messages being handled to be acknowledged or for ctx to be done, then closes the channel.
+// The messages prefetched but not delivered yet are requeued by the broker when the channel is closed.
+func (r *rabbitMQ) Drain(ctx context.Context) error {
+	err := r.inFlight.Drain(ctx)
+
+	r.channelMutex.Lock()
+	defer r.channelMutex.Unlock()
+
+	if resetErr := r.reset(); err == nil {
+		err = resetErr
+	}
+
+	return err
+}
+
 func (r *rabbitMQ) Close() error {
+	// Publish the pending batch before closing the channel
+	if r.batcher != nil {
+		r.batcher.Close()
+	}
+
 	r.channelMutex.Lock()
 	defer r.channelMutex.Unlock()
 
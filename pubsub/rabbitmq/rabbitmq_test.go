@@ -149,6 +149,66 @@ func TestPublishAndSubscribe(t *testing.T) {
 	assert.Equal(t, "foo bar", lastMessage)
 }
 
+func TestSubscribePriority(t *testing.T) {
+	broker := newBroker()
+	pubsubRabbitMQ := newRabbitMQTest(broker)
+	metadata := pubsub.Metadata{Base: mdata.Base{
+		Properties: map[string]string{
+			metadataHostnameKey:   "anyhost",
+			metadataConsumerIDKey: "consumer",
+		},
+	}}
+	err := pubsubRabbitMQ.Init(metadata)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processed := make(chan string)
+	handler := func(ctx context.Context, msg *pubsub.NewMessage) error {
+		processed <- string(msg.Data)
+		return nil
+	}
+
+	subscriber := pubsubRabbitMQ.(pubsub.PrioritySubscriber)
+	err = subscriber.SubscribePriority(ctx, pubsub.PrioritySubscribeRequest{}, handler)
+	assert.Error(t, err)
+	err = subscriber.SubscribePriority(ctx, pubsub.PrioritySubscribeRequest{Topics: []string{"jobs-high", "jobs-low"}}, handler)
+	assert.Nil(t, err)
+
+	err = pubsubRabbitMQ.Publish(context.Background(), &pubsub.PublishRequest{Topic: "jobs-low", Data: []byte("hello world")})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", <-processed)
+}
+
+func TestPublishBatch(t *testing.T) {
+	broker := &rabbitMQInMemoryBroker{
+		buffer: make(chan amqp.Delivery, 10),
+	}
+	pubsubRabbitMQ := newRabbitMQTest(broker)
+	metadata := pubsub.Metadata{Base: mdata.Base{
+		Properties: map[string]string{
+			metadataHostnameKey:            "anyhost",
+			pubsub.PublishBatchSizeKey:     "5",
+			pubsub.PublishBatchIntervalKey: "1m",
+		},
+	}}
+	err := pubsubRabbitMQ.Init(metadata)
+	assert.Nil(t, err)
+	defer pubsubRabbitMQ.Close()
+
+	// The batch is only published once it's full
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			errs <- pubsubRabbitMQ.Publish(context.Background(), &pubsub.PublishRequest{Topic: "mytopic", Data: []byte("hello world")})
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, <-errs)
+	}
+	assert.Len(t, broker.buffer, 5)
+}
+
 func TestPublishReconnect(t *testing.T) {
 	broker := newBroker()
 	pubsubRabbitMQ := newRabbitMQTest(broker)
@@ -327,6 +387,65 @@ func TestSubscribeReconnect(t *testing.T) {
 	assert.Equal(t, 4, broker.closeCount)   // two counts for each connection closure - one for connection, one for channel
 }
 
+func TestDrain(t *testing.T) {
+	broker := newBroker()
+	pubsubRabbitMQ := newRabbitMQTest(broker)
+	metadata := pubsub.Metadata{Base: mdata.Base{
+		Properties: map[string]string{
+			metadataHostnameKey:   "anyhost",
+			metadataConsumerIDKey: "consumer",
+			pubsub.ConcurrencyKey: string(pubsub.Parallel),
+		},
+	}}
+	err := pubsubRabbitMQ.Init(metadata)
+	assert.Nil(t, err)
+
+	received := make(chan string, 2)
+	release := make(chan struct{})
+	handler := func(ctx context.Context, msg *pubsub.NewMessage) error {
+		received <- string(msg.Data)
+		<-release
+
+		return nil
+	}
+
+	err = pubsubRabbitMQ.Subscribe(context.Background(), pubsub.SubscribeRequest{Topic: "drained"}, handler)
+	assert.Nil(t, err)
+
+	err = pubsubRabbitMQ.Publish(context.Background(), &pubsub.PublishRequest{Topic: "drained", Data: []byte("in flight")})
+	assert.Nil(t, err)
+	assert.Equal(t, "in flight", <-received)
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- pubsub.Drain(context.Background(), pubsubRabbitMQ)
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("drain returned with a message in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, 0, broker.closeCount)
+
+	close(release)
+	select {
+	case err = <-drained:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not return")
+	}
+	assert.Equal(t, 2, broker.closeCount)
+
+	// The subscription does not consume anymore
+	broker.buffer <- createAMQPMessage([]byte("after drain"))
+	select {
+	case msg := <-received:
+		t.Fatalf("message %q delivered after drain", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func createAMQPMessage(body []byte) amqp.Delivery {
 	return amqp.Delivery{Body: body}
 }
@@ -378,6 +497,10 @@ func (r *rabbitMQInMemoryBroker) Ack(tag uint64, multiple bool) error {
 	return nil
 }
 
+func (r *rabbitMQInMemoryBroker) Reject(tag uint64, requeue bool) error {
+	return nil
+}
+
 func (r *rabbitMQInMemoryBroker) ExchangeDeclare(name string, kind string, durable bool, autoDelete bool, internal bool, noWait bool, args amqp.Table) error {
 	return nil
 }
@@ -395,3 +518,30 @@ func (r *rabbitMQInMemoryBroker) Close() error {
 func (r *rabbitMQInMemoryBroker) IsClosed() bool {
 	return r.connectCount <= r.closeCount
 }
+
+func TestStatsRecorder(t *testing.T) {
+	broker := newBroker()
+	r := newRabbitMQTest(broker).(*rabbitMQ)
+	r.metadata = &metadata{consumerID: "consumer", enableDeadLetter: true}
+	ps := pubsub.NewStatsPubSub(r)
+
+	handler := func(ctx context.Context, msg *pubsub.NewMessage) error {
+		return errors.New("handler error")
+	}
+
+	d := amqp.Delivery{Acknowledger: broker, Body: []byte("first")}
+	r.handleMessage(context.Background(), d, "orders", handler)
+	d = amqp.Delivery{Acknowledger: broker, Body: []byte("again"), Redelivered: true}
+	r.handleMessage(context.Background(), d, "orders", handler)
+
+	s := pubsub.GetStats(ps)["orders"]
+	assert.Equal(t, uint64(1), s.Retried)
+	assert.Equal(t, uint64(2), s.DeadLettered)
+
+	// Requeued messages are not dead-lettered
+	r.metadata.requeueInFailure = true
+	r.handleMessage(context.Background(), d, "orders", handler)
+	s = pubsub.GetStats(ps)["orders"]
+	assert.Equal(t, uint64(2), s.Retried)
+	assert.Equal(t, uint64(2), s.DeadLettered)
+}
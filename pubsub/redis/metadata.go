@@ -31,4 +31,11 @@ type metadata struct {
 
 	// the max len of stream
 	maxLenApprox int64
+	// The age of the entries trimmed from the streams when messages are appended (0 disables trimming by age)
+	streamRetention time.Duration
+
+	// The max number of messages appended to streams with a single pipeline (0 or 1 disables batching)
+	publishBatchSize int
+	// The time a batch waits for more messages before being appended
+	publishBatchInterval time.Duration
 }
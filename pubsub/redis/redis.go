@@ -21,6 +21,7 @@ import (
 	"time"
 
 	rediscomponent "github.com/dapr/components-contrib/internal/component/redis"
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
 )
@@ -33,6 +34,7 @@ const (
 	queueDepth        = "queueDepth"
 	concurrency       = "concurrency"
 	maxLenApprox      = "maxLenApprox"
+	streamRetention   = "streamRetention"
 )
 
 // redisStreams handles consuming from a Redis stream using
@@ -47,7 +49,9 @@ type redisStreams struct {
 	clientSettings *rediscomponent.Settings
 	logger         logger.Logger
 
-	queue chan redisMessageWrapper
+	queue   chan redisMessageWrapper
+	batcher *pubsub.PublishBatcher
+	stats   pubsub.StatsRecorder
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -67,6 +71,11 @@ func NewRedisStreams(logger logger.Logger) pubsub.PubSub {
 	return &redisStreams{logger: logger}
 }
 
+// SetStatsRecorder records the pending messages reclaimed for redelivery.
+func (r *redisStreams) SetStatsRecorder(recorder pubsub.StatsRecorder) {
+	r.stats = recorder
+}
+
 func parseRedisMetadata(meta pubsub.Metadata) (metadata, error) {
 	// Default values
 	m := metadata{
@@ -126,6 +135,23 @@ func parseRedisMetadata(meta pubsub.Metadata) (metadata, error) {
 		m.maxLenApprox = maxLenApprox
 	}
 
+	if val, ok := meta.Properties[streamRetention]; ok && val != "" {
+		retention, err := time.ParseDuration(val)
+		if err != nil || retention <= 0 {
+			return m, fmt.Errorf("redis streams error: invalid streamRetention %s: must be a positive duration", val)
+		}
+		if m.maxLenApprox > 0 {
+			return m, errors.New("redis streams error: maxLenApprox and streamRetention cannot be both set")
+		}
+		m.streamRetention = retention
+	}
+
+	var err error
+	m.publishBatchSize, m.publishBatchInterval, err = pubsub.PublishBatching(meta.Properties)
+	if err != nil {
+		return m, fmt.Errorf("redis streams error: %s", err)
+	}
+
 	return m, nil
 }
 
@@ -143,7 +169,7 @@ func (r *redisStreams) Init(metadata pubsub.Metadata) error {
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 
 	if _, err = r.client.PingResult(r.ctx); err != nil {
-		return fmt.Errorf("redis streams: error connecting to redis at %s: %s", r.clientSettings.Host, err)
+		return fmt.Errorf("redis streams: error connecting to redis at %s: %s", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 	r.queue = make(chan redisMessageWrapper, int(r.metadata.queueDepth))
 
@@ -151,11 +177,19 @@ func (r *redisStreams) Init(metadata pubsub.Metadata) error {
 		go r.worker()
 	}
 
+	if r.metadata.publishBatchSize > 1 {
+		r.batcher = pubsub.NewPublishBatcher(r.metadata.publishBatchSize, r.metadata.publishBatchInterval, r.publishBatch)
+	}
+
 	return nil
 }
 
 func (r *redisStreams) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
-	_, err := r.client.XAdd(ctx, req.Topic, r.metadata.maxLenApprox, map[string]interface{}{"data": req.Data})
+	if r.batcher != nil {
+		return r.batcher.Publish(ctx, req)
+	}
+
+	_, err := r.client.XAdd(ctx, req.Topic, r.trim(), map[string]interface{}{"data": req.Data})
 	if err != nil {
 		return fmt.Errorf("redis streams: error from publish: %s", err)
 	}
@@ -163,6 +197,35 @@ func (r *redisStreams) Publish(ctx context.Context, req *pubsub.PublishRequest)
 	return nil
 }
 
+// trim returns the trimming of the streams when messages are appended: to maxLenApprox entries, or to the entries
+// more recent than streamRetention, which are the ones with a greater ID as IDs start with the time they were added.
+func (r *redisStreams) trim() rediscomponent.RedisXAddTrim {
+	trim := rediscomponent.RedisXAddTrim{MaxLenApprox: r.metadata.maxLenApprox}
+	if r.metadata.streamRetention > 0 {
+		trim.MinIDApprox = strconv.FormatInt(time.Now().Add(-r.metadata.streamRetention).UnixMilli(), 10) + "-0"
+	}
+	return trim
+}
+
+// publishBatch appends a batch of messages collected by the batcher using pipelining.
+func (r *redisStreams) publishBatch(ctx context.Context, reqs []*pubsub.PublishRequest) []error {
+	messages := make([]rediscomponent.RedisXAddMessage, len(reqs))
+	for i, req := range reqs {
+		messages[i] = rediscomponent.RedisXAddMessage{
+			Stream: req.Topic,
+			Values: map[string]interface{}{"data": req.Data},
+		}
+	}
+
+	errs := r.client.XAddPipeline(ctx, r.trim(), messages)
+	for i, err := range errs {
+		if err != nil {
+			errs[i] = fmt.Errorf("redis streams: error from publish: %s", err)
+		}
+	}
+	return errs
+}
+
 func (r *redisStreams) Subscribe(ctx context.Context, req pubsub.SubscribeRequest, handler pubsub.Handler) error {
 	err := r.client.XGroupCreateMkStream(ctx, req.Topic, r.metadata.consumerID, "0")
 	// Ignore BUSYGROUP errors
@@ -358,6 +421,11 @@ func (r *redisStreams) reclaimPendingMessages(ctx context.Context, stream string
 		}
 
 		// Enqueue claimed messages
+		if r.stats != nil {
+			for range claimResult {
+				r.stats.RecordRetry(stream)
+			}
+		}
 		r.enqueueMessages(ctx, stream, handler, claimResult)
 
 		// If the Redis nil error is returned, it means somes message in the pending
@@ -411,6 +479,10 @@ func (r *redisStreams) removeMessagesThatNoLongerExistFromPending(ctx context.Co
 }
 
 func (r *redisStreams) Close() error {
+	if r.batcher != nil {
+		r.batcher.Close()
+	}
+
 	if r.cancel != nil {
 		r.cancel()
 	}
@@ -427,7 +499,7 @@ func (r *redisStreams) Features() []pubsub.Feature {
 
 func (r *redisStreams) Ping() error {
 	if _, err := r.client.PingResult(context.Background()); err != nil {
-		return fmt.Errorf("redis pubsub: error connecting to redis at %s: %s", r.clientSettings.Host, err)
+		return fmt.Errorf("redis pubsub: error connecting to redis at %s: %s", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 
 	return nil
@@ -17,9 +17,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 
 	mdata "github.com/dapr/components-contrib/metadata"
@@ -54,6 +59,56 @@ func TestParseRedisMetadata(t *testing.T) {
 		assert.Equal(t, int64(1000), m.maxLenApprox)
 	})
 
+	t.Run("publish batching", func(t *testing.T) {
+		fakeProperties := getFakeProperties()
+		fakeProperties[pubsub.PublishBatchSizeKey] = "100"
+		fakeProperties[pubsub.PublishBatchIntervalKey] = "5ms"
+
+		m, err := parseRedisMetadata(pubsub.Metadata{
+			Base: mdata.Base{Properties: fakeProperties},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 100, m.publishBatchSize)
+		assert.Equal(t, 5*time.Millisecond, m.publishBatchInterval)
+	})
+
+	t.Run("stream retention", func(t *testing.T) {
+		fakeProperties := getFakeProperties()
+		fakeProperties[maxLenApprox] = ""
+		fakeProperties[streamRetention] = "24h"
+
+		m, err := parseRedisMetadata(pubsub.Metadata{
+			Base: mdata.Base{Properties: fakeProperties},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, m.streamRetention)
+	})
+
+	t.Run("stream retention and maxLenApprox", func(t *testing.T) {
+		fakeProperties := getFakeProperties()
+		fakeProperties[streamRetention] = "24h"
+
+		_, err := parseRedisMetadata(pubsub.Metadata{
+			Base: mdata.Base{Properties: fakeProperties},
+		})
+
+		assert.ErrorContains(t, err, "maxLenApprox and streamRetention cannot be both set")
+	})
+
+	t.Run("invalid stream retention", func(t *testing.T) {
+		fakeProperties := getFakeProperties()
+		fakeProperties[maxLenApprox] = ""
+		fakeProperties[streamRetention] = "0s"
+
+		_, err := parseRedisMetadata(pubsub.Metadata{
+			Base: mdata.Base{Properties: fakeProperties},
+		})
+
+		assert.Error(t, err)
+	})
+
 	t.Run("consumerID is not given", func(t *testing.T) {
 		fakeProperties := getFakeProperties()
 
@@ -70,6 +125,63 @@ func TestParseRedisMetadata(t *testing.T) {
 	})
 }
 
+func TestStreamTrim(t *testing.T) {
+	t.Run("maxLenApprox", func(t *testing.T) {
+		r := &redisStreams{metadata: metadata{maxLenApprox: 1000}}
+
+		assert.Equal(t, internalredis.RedisXAddTrim{MaxLenApprox: 1000}, r.trim())
+	})
+
+	t.Run("stream retention", func(t *testing.T) {
+		r := &redisStreams{metadata: metadata{streamRetention: time.Hour}}
+		before := time.Now().Add(-time.Hour).UnixMilli()
+		trim := r.trim()
+		after := time.Now().Add(-time.Hour).UnixMilli()
+
+		ms, seq, ok := strings.Cut(trim.MinIDApprox, "-")
+		assert.True(t, ok)
+		assert.Equal(t, "0", seq)
+		minID, err := strconv.ParseInt(ms, 10, 64)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, minID, before)
+		assert.LessOrEqual(t, minID, after)
+	})
+}
+
+func TestPublishBatch(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+
+	r := &redisStreams{
+		client: internalredis.ClientFromV8Client(redis.NewClient(&redis.Options{Addr: s.Addr()})),
+		logger: logger.NewLogger("test"),
+	}
+	r.batcher = pubsub.NewPublishBatcher(3, time.Minute, r.publishBatch)
+	defer r.batcher.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			topic := "topic1"
+			if i == 2 {
+				topic = "topic2"
+			}
+			assert.NoError(t, r.Publish(context.Background(), &pubsub.PublishRequest{Topic: topic, Data: []byte("hello")}))
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := s.Stream("topic1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	entries, err = s.Stream("topic2")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
 func TestProcessStreams(t *testing.T) {
 	fakeConsumerID := "fakeConsumer"
 	topicCount := 0
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/resiliency"
+)
+
+// resilientPubSub publishes messages with the timeout, retries and circuit breaker set in the component metadata.
+type resilientPubSub struct {
+	forwarder
+
+	policy *resiliency.Policy
+}
+
+// NewResilientPubSub wraps a PubSub so that Publish is run with the resiliency settings in its metadata:
+// "resiliencyTimeout", "resiliencyRetry*" and "resiliencyCircuitBreaker*".
+// Subscriptions are not affected: redelivery of inbound messages is left to the component.
+// Neither is BulkPublish, as retrying a partially failed bulk would publish its successful entries again.
+func NewResilientPubSub(inner PubSub) PubSub {
+	return exposeOptional(&resilientPubSub{forwarder: forwarder{inner}}, inner)
+}
+
+func (p *resilientPubSub) Init(metadata Metadata) error {
+	m, err := resiliency.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("pubsub resiliency error: %w", err)
+	}
+	p.policy = resiliency.NewPolicy(m, nil)
+
+	return p.PubSub.Init(metadata)
+}
+
+func (p *resilientPubSub) Publish(ctx context.Context, req *PublishRequest) error {
+	return resiliency.RunOnce(ctx, p.policy, func(ctx context.Context) error {
+		return p.PubSub.Publish(ctx, req)
+	})
+}
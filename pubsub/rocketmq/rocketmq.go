@@ -29,6 +29,7 @@ import (
 	mqp "github.com/apache/rocketmq-client-go/v2/producer"
 	"github.com/apache/rocketmq-client-go/v2/rlog"
 
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/internal/utils"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
@@ -310,7 +311,7 @@ func (r *rocketMQ) resetProducer() {
 }
 
 func (r *rocketMQ) Publish(ctx context.Context, req *pubsub.PublishRequest) error {
-	r.logger.Debugf("rocketmq publish topic:%s with data:%v", req.Topic, req.Data)
+	r.logger.Debugf("rocketmq publish topic:%s with data:%s", req.Topic, redact.Body(req.Data))
 	msg := primitive.NewMessage(req.Topic, req.Data)
 	for k, v := range req.Metadata {
 		switch strings.ToLower(k) {
@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TopicStats holds the delivery counters of the messages received on a topic.
+type TopicStats struct {
+	// Messages passed to the handler, including redeliveries.
+	Delivered uint64 `json:"delivered"`
+	// Messages successfully processed by the handler.
+	Acked uint64 `json:"acked"`
+	// Messages the handler failed to process.
+	Failed uint64 `json:"failed"`
+	// Messages delivered again after a failure or a timeout, as reported by the component.
+	Retried uint64 `json:"retried"`
+	// Messages routed to a dead-letter topic.
+	DeadLettered uint64 `json:"deadLettered"`
+	// Total and maximum time spent by the handler on the acked messages.
+	AckLatency    time.Duration `json:"ackLatency"`
+	MaxAckLatency time.Duration `json:"maxAckLatency"`
+}
+
+// MeanAckLatency returns the average time spent by the handler on the acked messages.
+func (s TopicStats) MeanAckLatency() time.Duration {
+	if s.Acked == 0 {
+		return 0
+	}
+	return s.AckLatency / time.Duration(s.Acked)
+}
+
+// StatsProvider is implemented by the pub/subs exposing the delivery counters of their topics.
+type StatsProvider interface {
+	Stats() map[string]TopicStats
+}
+
+// StatsRecorder records the delivery events that are only known to the components.
+type StatsRecorder interface {
+	RecordRetry(topic string)
+	RecordDeadLetter(topic string, deadLetterTopic string)
+}
+
+// StatsReporter is implemented by the components reporting their retries and dead-letter routing decisions.
+type StatsReporter interface {
+	SetStatsRecorder(recorder StatsRecorder)
+}
+
+// GetStats returns the delivery counters of a pub/sub, or nil if it doesn't expose them.
+func GetStats(pubsub PubSub) map[string]TopicStats {
+	if provider, ok := pubsub.(StatsProvider); ok {
+		return provider.Stats()
+	}
+	return nil
+}
+
+type topicCounters struct {
+	delivered     uint64
+	acked         uint64
+	failed        uint64
+	retried       uint64
+	deadLettered  uint64
+	ackLatency    int64
+	maxAckLatency int64
+}
+
+// statsPubSub counts the messages delivered to the handlers of the subscriptions, per topic.
+type statsPubSub struct {
+	forwarder
+
+	lock   sync.RWMutex
+	topics map[string]*topicCounters
+}
+
+// NewStatsPubSub wraps a PubSub so that the deliveries, acks, failures and ack latency of its subscriptions are counted per topic, and exposed with StatsProvider.
+// The entries of bulk subscriptions are counted as single messages.
+// Retries and dead-lettered messages are counted when reported by the wrapped PubSub, if it implements StatsReporter.
+func NewStatsPubSub(inner PubSub) PubSub {
+	p := &statsPubSub{
+		forwarder: forwarder{inner},
+		topics:    map[string]*topicCounters{},
+	}
+	if reporter, ok := inner.(StatsReporter); ok {
+		reporter.SetStatsRecorder(p)
+	}
+	return exposeOptional(p, inner)
+}
+
+func (p *statsPubSub) Subscribe(ctx context.Context, req SubscribeRequest, handler Handler) error {
+	return p.PubSub.Subscribe(ctx, req, func(ctx context.Context, msg *NewMessage) error {
+		c := p.counters(msg.Topic)
+		atomic.AddUint64(&c.delivered, 1)
+
+		start := time.Now()
+		err := handler(ctx, msg)
+		if err != nil {
+			atomic.AddUint64(&c.failed, 1)
+			return err
+		}
+
+		c.ack(1, time.Since(start))
+		return nil
+	})
+}
+
+func (p *statsPubSub) BulkSubscribe(ctx context.Context, req SubscribeRequest, handler BulkHandler) error {
+	return p.forwarder.BulkSubscribe(ctx, req, func(ctx context.Context, msg *BulkMessage) ([]BulkSubscribeResponseEntry, error) {
+		c := p.counters(msg.Topic)
+		n := uint64(len(msg.Entries))
+		atomic.AddUint64(&c.delivered, n)
+
+		start := time.Now()
+		statuses, err := handler(ctx, msg)
+		var failed uint64
+		if err != nil {
+			// Without statuses, none of the messages was processed
+			if statuses == nil {
+				failed = n
+			}
+			for _, status := range statuses {
+				if status.Error != nil {
+					failed++
+				}
+			}
+		}
+
+		atomic.AddUint64(&c.failed, failed)
+		if failed < n {
+			c.ack(n-failed, time.Since(start))
+		}
+		return statuses, err
+	})
+}
+
+// ack counts n messages acked after latency.
+func (c *topicCounters) ack(n uint64, latency time.Duration) {
+	atomic.AddUint64(&c.acked, n)
+	atomic.AddInt64(&c.ackLatency, int64(latency)*int64(n))
+	for {
+		max := atomic.LoadInt64(&c.maxAckLatency)
+		if int64(latency) <= max || atomic.CompareAndSwapInt64(&c.maxAckLatency, max, int64(latency)) {
+			break
+		}
+	}
+}
+
+func (p *statsPubSub) RecordRetry(topic string) {
+	atomic.AddUint64(&p.counters(topic).retried, 1)
+}
+
+func (p *statsPubSub) RecordDeadLetter(topic string, deadLetterTopic string) {
+	atomic.AddUint64(&p.counters(topic).deadLettered, 1)
+}
+
+func (p *statsPubSub) Stats() map[string]TopicStats {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	res := make(map[string]TopicStats, len(p.topics))
+	for topic, c := range p.topics {
+		res[topic] = TopicStats{
+			Delivered:     atomic.LoadUint64(&c.delivered),
+			Acked:         atomic.LoadUint64(&c.acked),
+			Failed:        atomic.LoadUint64(&c.failed),
+			Retried:       atomic.LoadUint64(&c.retried),
+			DeadLettered:  atomic.LoadUint64(&c.deadLettered),
+			AckLatency:    time.Duration(atomic.LoadInt64(&c.ackLatency)),
+			MaxAckLatency: time.Duration(atomic.LoadInt64(&c.maxAckLatency)),
+		}
+	}
+	return res
+}
+
+func (p *statsPubSub) counters(topic string) *topicCounters {
+	p.lock.RLock()
+	c, ok := p.topics[topic]
+	p.lock.RUnlock()
+	if ok {
+		return c
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if c, ok = p.topics[topic]; !ok {
+		c = &topicCounters{}
+		p.topics[topic] = c
+	}
+	return c
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+type reportingPubSub struct {
+	fakePubSub
+	recorder StatsRecorder
+}
+
+func (r *reportingPubSub) SetStatsRecorder(recorder StatsRecorder) {
+	r.recorder = recorder
+}
+
+func TestStatsPubSub(t *testing.T) {
+	t.Run("counts deliveries and acks", func(t *testing.T) {
+		inner := &fakePubSub{}
+		ps := NewStatsPubSub(inner)
+		require.NoError(t, ps.Init(Metadata{}))
+
+		fail := true
+		require.NoError(t, ps.Subscribe(context.Background(), SubscribeRequest{Topic: "orders"}, func(ctx context.Context, msg *NewMessage) error {
+			if fail {
+				return errors.New("failed")
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		}))
+
+		assert.Error(t, inner.handler(context.Background(), &NewMessage{Topic: "orders"}))
+		fail = false
+		assert.NoError(t, inner.handler(context.Background(), &NewMessage{Topic: "orders"}))
+		assert.NoError(t, inner.handler(context.Background(), &NewMessage{Topic: "orders"}))
+
+		stats := GetStats(ps)
+		require.Contains(t, stats, "orders")
+		s := stats["orders"]
+		assert.Equal(t, uint64(3), s.Delivered)
+		assert.Equal(t, uint64(2), s.Acked)
+		assert.Equal(t, uint64(1), s.Failed)
+		assert.GreaterOrEqual(t, s.MaxAckLatency, time.Millisecond)
+		assert.GreaterOrEqual(t, s.AckLatency, 2*time.Millisecond)
+		assert.Equal(t, s.AckLatency/2, s.MeanAckLatency())
+	})
+
+	t.Run("counts events reported by the component", func(t *testing.T) {
+		inner := &reportingPubSub{}
+		ps := NewStatsPubSub(inner)
+		require.NotNil(t, inner.recorder)
+
+		inner.recorder.RecordRetry("orders")
+		inner.recorder.RecordRetry("orders")
+		inner.recorder.RecordDeadLetter("orders", "orders-dlq")
+
+		s := GetStats(ps)["orders"]
+		assert.Equal(t, uint64(2), s.Retried)
+		assert.Equal(t, uint64(1), s.DeadLettered)
+	})
+
+	t.Run("counts messages dead-lettered by the validation", func(t *testing.T) {
+		inner := &fakePubSub{}
+		ps := NewStatsPubSub(NewValidatingPubSub(inner, logger.NewLogger("test")))
+		require.NoError(t, ps.Init(Metadata{Base: metadata.Base{Properties: map[string]string{
+			"validationSchema":          `{"type": "object", "required": ["orderId"]}`,
+			"validationDeadLetterTopic": "orders-invalid",
+		}}}))
+		require.NoError(t, ps.Subscribe(context.Background(), SubscribeRequest{Topic: "orders"}, func(ctx context.Context, msg *NewMessage) error {
+			return nil
+		}))
+
+		assert.NoError(t, inner.handler(context.Background(), &NewMessage{Topic: "orders", Data: []byte(`{}`)}))
+
+		// Invalid messages are not passed to the handler
+		s := GetStats(ps)["orders"]
+		assert.Zero(t, s.Delivered)
+		assert.Equal(t, uint64(1), s.DeadLettered)
+	})
+
+	t.Run("no stats", func(t *testing.T) {
+		assert.Nil(t, GetStats(&fakePubSub{}))
+	})
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/schema"
+	"github.com/dapr/kit/logger"
+)
+
+// validatingPubSub validates the payloads published and received against a JSON Schema.
+type validatingPubSub struct {
+	forwarder
+
+	validator       *schema.Validator
+	deadLetterTopic string
+	logger          logger.Logger
+	stats           StatsRecorder
+}
+
+// NewValidatingPubSub wraps a PubSub so that payloads are validated against the JSON Schema set in the "validationSchema" metadata.
+// Publishing an invalid message fails. Invalid inbound messages are sent to "validationDeadLetterTopic" if set, and rejected otherwise.
+// Validation is disabled when no schema is set.
+// The entries of bulk requests are validated one by one: the invalid entries fail, while the other ones are published or handled.
+func NewValidatingPubSub(inner PubSub, logger logger.Logger) PubSub {
+	return exposeOptional(&validatingPubSub{
+		forwarder: forwarder{inner},
+		logger:    logger,
+	}, inner)
+}
+
+func (p *validatingPubSub) Init(metadata Metadata) error {
+	m, err := schema.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("pubsub schema validation error: %w", err)
+	}
+	p.validator, err = schema.NewValidator(m.Schema)
+	if err != nil {
+		return fmt.Errorf("pubsub schema validation error: %w", err)
+	}
+	p.deadLetterTopic = m.DeadLetterTopic
+
+	return p.PubSub.Init(metadata)
+}
+
+// SetStatsRecorder records the invalid messages sent to the dead-letter topic, and passes the recorder to the wrapped PubSub.
+func (p *validatingPubSub) SetStatsRecorder(recorder StatsRecorder) {
+	p.stats = recorder
+	if reporter, ok := p.PubSub.(StatsReporter); ok {
+		reporter.SetStatsRecorder(recorder)
+	}
+}
+
+func (p *validatingPubSub) Publish(ctx context.Context, req *PublishRequest) error {
+	if p.validator != nil {
+		if err := p.validator.Validate(req.Data); err != nil {
+			return fmt.Errorf("pubsub schema validation error: %w", err)
+		}
+	}
+
+	return p.PubSub.Publish(ctx, req)
+}
+
+func (p *validatingPubSub) Subscribe(ctx context.Context, req SubscribeRequest, handler Handler) error {
+	if p.validator == nil {
+		return p.PubSub.Subscribe(ctx, req, handler)
+	}
+
+	return p.PubSub.Subscribe(ctx, req, func(ctx context.Context, msg *NewMessage) error {
+		valid, err := p.checkReceived(ctx, msg)
+		if !valid {
+			return err
+		}
+		return handler(ctx, msg)
+	})
+}
+
+func (p *validatingPubSub) BulkPublish(ctx context.Context, req *BulkPublishRequest) (BulkPublishResponse, error) {
+	if p.validator == nil {
+		return p.forwarder.BulkPublish(ctx, req)
+	}
+
+	res := BulkPublishResponse{}
+	valid := make([]BulkMessageEntry, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		if err := p.validator.Validate(entry.Event); err != nil {
+			res.FailedEntries = append(res.FailedEntries, BulkPublishResponseFailedEntry{
+				EntryId: entry.EntryId,
+				Error:   fmt.Errorf("pubsub schema validation error: %w", err),
+			})
+			continue
+		}
+		valid = append(valid, entry)
+	}
+	if len(res.FailedEntries) == 0 {
+		return p.forwarder.BulkPublish(ctx, req)
+	}
+
+	if len(valid) > 0 {
+		validReq := *req
+		validReq.Entries = valid
+		validRes, err := p.forwarder.BulkPublish(ctx, &validReq)
+		res.FailedEntries = append(res.FailedEntries, validRes.FailedEntries...)
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, fmt.Errorf("pubsub schema validation error: %d invalid message(s)", len(req.Entries)-len(valid))
+}
+
+func (p *validatingPubSub) BulkSubscribe(ctx context.Context, req SubscribeRequest, handler BulkHandler) error {
+	if p.validator == nil {
+		return p.forwarder.BulkSubscribe(ctx, req, handler)
+	}
+
+	return p.forwarder.BulkSubscribe(ctx, req, func(ctx context.Context, msg *BulkMessage) ([]BulkSubscribeResponseEntry, error) {
+		statuses := make([]BulkSubscribeResponseEntry, len(msg.Entries))
+		valid := make([]BulkMessageEntry, 0, len(msg.Entries))
+		validIndexes := make([]int, 0, len(msg.Entries))
+		var resErr error
+		for i, entry := range msg.Entries {
+			statuses[i].EntryId = entry.EntryId
+			newMsg := &NewMessage{Data: entry.Event, Topic: msg.Topic, Metadata: entry.Metadata}
+			if entry.ContentType != "" {
+				newMsg.ContentType = &entry.ContentType
+			}
+			ok, err := p.checkReceived(ctx, newMsg)
+			if ok {
+				valid = append(valid, entry)
+				validIndexes = append(validIndexes, i)
+			} else if err != nil {
+				statuses[i].Error = err
+				resErr = err
+			}
+		}
+		if len(valid) == 0 {
+			return statuses, resErr
+		}
+
+		validStatuses, err := handler(ctx, &BulkMessage{Entries: valid, Topic: msg.Topic, Metadata: msg.Metadata})
+		if err != nil {
+			resErr = err
+			for j, i := range validIndexes {
+				// Without statuses, none of the messages was processed
+				if validStatuses == nil {
+					statuses[i].Error = err
+				} else if j < len(validStatuses) {
+					statuses[i].Error = validStatuses[j].Error
+				}
+			}
+		}
+		return statuses, resErr
+	})
+}
+
+// checkReceived returns true if msg matches the schema.
+// Otherwise the message is sent to the dead-letter topic, if any, and an error is returned if it could not be.
+func (p *validatingPubSub) checkReceived(ctx context.Context, msg *NewMessage) (bool, error) {
+	err := p.validator.Validate(msg.Data)
+	if err == nil {
+		return true, nil
+	}
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) {
+		return false, err
+	}
+
+	if p.deadLetterTopic == "" {
+		return false, fmt.Errorf("pubsub schema validation error: %w", err)
+	}
+
+	p.logger.Warnf("Message received on topic %s does not match the schema, sending it to %s: %v", msg.Topic, p.deadLetterTopic, err)
+	err = p.PubSub.Publish(ctx, &PublishRequest{
+		Data:        msg.Data,
+		Topic:       p.deadLetterTopic,
+		Metadata:    msg.Metadata,
+		ContentType: msg.ContentType,
+	})
+	if err == nil && p.stats != nil {
+		p.stats.RecordDeadLetter(msg.Topic, p.deadLetterTopic)
+	}
+	return false, err
+}
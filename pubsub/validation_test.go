@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+type fakePubSub struct {
+	published []*PublishRequest
+	handler   Handler
+}
+
+func (f *fakePubSub) Init(metadata Metadata) error { return nil }
+func (f *fakePubSub) Features() []Feature          { return nil }
+func (f *fakePubSub) Close() error                 { return nil }
+
+func (f *fakePubSub) Publish(ctx context.Context, req *PublishRequest) error {
+	f.published = append(f.published, req)
+	return nil
+}
+
+func (f *fakePubSub) Subscribe(ctx context.Context, req SubscribeRequest, handler Handler) error {
+	f.handler = handler
+	return nil
+}
+
+func TestValidatingPubSub(t *testing.T) {
+	props := map[string]string{
+		"validationSchema": `{"type": "object", "required": ["orderId"]}`,
+	}
+
+	t.Run("publish", func(t *testing.T) {
+		inner := &fakePubSub{}
+		ps := NewValidatingPubSub(inner, logger.NewLogger("test"))
+		require.NoError(t, ps.Init(Metadata{Base: metadata.Base{Properties: props}}))
+
+		assert.NoError(t, ps.Publish(context.Background(), &PublishRequest{Topic: "orders", Data: []byte(`{"orderId":1}`)}))
+		assert.Error(t, ps.Publish(context.Background(), &PublishRequest{Topic: "orders", Data: []byte(`{}`)}))
+		assert.Len(t, inner.published, 1)
+	})
+
+	t.Run("subscribe rejects invalid messages", func(t *testing.T) {
+		inner := &fakePubSub{}
+		ps := NewValidatingPubSub(inner, logger.NewLogger("test"))
+		require.NoError(t, ps.Init(Metadata{Base: metadata.Base{Properties: props}}))
+
+		delivered := 0
+		require.NoError(t, ps.Subscribe(context.Background(), SubscribeRequest{Topic: "orders"}, func(ctx context.Context, msg *NewMessage) error {
+			delivered++
+			return nil
+		}))
+
+		assert.NoError(t, inner.handler(context.Background(), &NewMessage{Topic: "orders", Data: []byte(`{"orderId":1}`)}))
+		assert.Error(t, inner.handler(context.Background(), &NewMessage{Topic: "orders", Data: []byte(`{}`)}))
+		assert.Equal(t, 1, delivered)
+	})
+
+	t.Run("subscribe sends invalid messages to the dead-letter topic", func(t *testing.T) {
+		inner := &fakePubSub{}
+		ps := NewValidatingPubSub(inner, logger.NewLogger("test"))
+		require.NoError(t, ps.Init(Metadata{Base: metadata.Base{Properties: map[string]string{
+			"validationSchema":          props["validationSchema"],
+			"validationDeadLetterTopic": "orders-invalid",
+		}}}))
+
+		require.NoError(t, ps.Subscribe(context.Background(), SubscribeRequest{Topic: "orders"}, func(ctx context.Context, msg *NewMessage) error {
+			return nil
+		}))
+
+		assert.NoError(t, inner.handler(context.Background(), &NewMessage{Topic: "orders", Data: []byte(`{}`)}))
+		require.Len(t, inner.published, 1)
+		assert.Equal(t, "orders-invalid", inner.published[0].Topic)
+	})
+}
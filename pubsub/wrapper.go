@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dapr/components-contrib/health"
+)
+
+// wrapperBase is implemented by all the PubSub wrappers.
+// Its optional interfaces behave as the wrapped PubSub when it doesn't implement them.
+type wrapperBase interface {
+	PubSub
+	Drainer
+	StatsReporter
+	StatsProvider
+	health.ReadinessReporter
+}
+
+// wrapper is implemented by the PubSub wrappers, which support all the optional interfaces of the wrapped PubSub.
+type wrapper interface {
+	wrapperBase
+	BulkPublisher
+	BulkSubscriber
+	health.Pinger
+}
+
+// forwarder is embedded by the wrappers to forward the optional interfaces to the wrapped PubSub.
+// The wrappers override the methods whose behavior they change.
+type forwarder struct {
+	PubSub
+}
+
+func (f forwarder) BulkPublish(ctx context.Context, req *BulkPublishRequest) (BulkPublishResponse, error) {
+	if publisher, ok := f.PubSub.(BulkPublisher); ok {
+		return publisher.BulkPublish(ctx, req)
+	}
+	err := errors.New("bulk publish is not implemented by this pubsub")
+	return NewBulkPublishResponse(req.Entries, err), err
+}
+
+func (f forwarder) BulkSubscribe(ctx context.Context, req SubscribeRequest, handler BulkHandler) error {
+	if subscriber, ok := f.PubSub.(BulkSubscriber); ok {
+		return subscriber.BulkSubscribe(ctx, req, handler)
+	}
+	return errors.New("bulk subscribe is not implemented by this pubsub")
+}
+
+func (f forwarder) Ping() error {
+	return Ping(f.PubSub)
+}
+
+func (f forwarder) Drain(ctx context.Context) error {
+	return Drain(ctx, f.PubSub)
+}
+
+func (f forwarder) SetStatsRecorder(recorder StatsRecorder) {
+	if reporter, ok := f.PubSub.(StatsReporter); ok {
+		reporter.SetStatsRecorder(recorder)
+	}
+}
+
+func (f forwarder) Stats() map[string]TopicStats {
+	return GetStats(f.PubSub)
+}
+
+func (f forwarder) Readiness() health.Readiness {
+	return health.GetReadiness(f.PubSub)
+}
+
+func (f forwarder) WaitReady(ctx context.Context) error {
+	if reporter, ok := f.PubSub.(health.ReadinessReporter); ok {
+		return reporter.WaitReady(ctx)
+	}
+	return nil
+}
+
+// exposeOptional returns the wrapper w of inner, implementing BulkPublisher, BulkSubscriber and health.Pinger only if inner does,
+// so that the callers checking for them keep their fallbacks.
+func exposeOptional(w wrapper, inner PubSub) PubSub {
+	_, bulkPublisher := inner.(BulkPublisher)
+	_, bulkSubscriber := inner.(BulkSubscriber)
+	_, pinger := inner.(health.Pinger)
+
+	switch {
+	case bulkPublisher && bulkSubscriber && pinger:
+		return w
+	case bulkPublisher && bulkSubscriber:
+		return struct {
+			wrapperBase
+			BulkPublisher
+			BulkSubscriber
+		}{w, w, w}
+	case bulkPublisher && pinger:
+		return struct {
+			wrapperBase
+			BulkPublisher
+			health.Pinger
+		}{w, w, w}
+	case bulkSubscriber && pinger:
+		return struct {
+			wrapperBase
+			BulkSubscriber
+			health.Pinger
+		}{w, w, w}
+	case bulkPublisher:
+		return struct {
+			wrapperBase
+			BulkPublisher
+		}{w, w}
+	case bulkSubscriber:
+		return struct {
+			wrapperBase
+			BulkSubscriber
+		}{w, w}
+	case pinger:
+		return struct {
+			wrapperBase
+			health.Pinger
+		}{w, w}
+	default:
+		return struct {
+			wrapperBase
+		}{w}
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// bulkPubSub implements all the optional interfaces of a PubSub.
+type bulkPubSub struct {
+	fakePubSub
+	bulkPublished []*BulkPublishRequest
+	bulkHandler   BulkHandler
+	drained       bool
+}
+
+func (f *bulkPubSub) BulkPublish(ctx context.Context, req *BulkPublishRequest) (BulkPublishResponse, error) {
+	f.bulkPublished = append(f.bulkPublished, req)
+	return BulkPublishResponse{}, nil
+}
+
+func (f *bulkPubSub) BulkSubscribe(ctx context.Context, req SubscribeRequest, handler BulkHandler) error {
+	f.bulkHandler = handler
+	return nil
+}
+
+func (f *bulkPubSub) Ping() error {
+	return nil
+}
+
+func (f *bulkPubSub) Drain(ctx context.Context) error {
+	f.drained = true
+	return nil
+}
+
+// connectingPubSub reports its readiness, like the components connecting after Init.
+type connectingPubSub struct {
+	fakePubSub
+	*health.ReadinessTracker
+}
+
+func wrapAll(inner PubSub) PubSub {
+	return NewStatsPubSub(NewValidatingPubSub(NewResilientPubSub(inner), logger.NewLogger("test")))
+}
+
+func TestWrappers(t *testing.T) {
+	t.Run("drain through the wrappers", func(t *testing.T) {
+		inner := &bulkPubSub{}
+		require.NoError(t, Drain(context.Background(), wrapAll(inner)))
+		assert.True(t, inner.drained)
+
+		assert.Error(t, Drain(context.Background(), wrapAll(&fakePubSub{})))
+	})
+
+	t.Run("readiness of the wrapped pubsub", func(t *testing.T) {
+		inner := &connectingPubSub{ReadinessTracker: health.NewReadinessTracker("connecting")}
+		ps := wrapAll(inner)
+		assert.Equal(t, health.StatusInitializing, health.GetReadiness(ps).Status)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Error(t, ps.(health.ReadinessReporter).WaitReady(ctx))
+
+		inner.SetReady()
+		assert.Equal(t, health.StatusReady, health.GetReadiness(ps).Status)
+		assert.NoError(t, ps.(health.ReadinessReporter).WaitReady(context.Background()))
+
+		assert.Equal(t, health.StatusReady, health.GetReadiness(wrapAll(&fakePubSub{})).Status)
+	})
+
+	t.Run("optional interfaces of the wrapped pubsub", func(t *testing.T) {
+		ps := wrapAll(&bulkPubSub{})
+		assert.Implements(t, (*BulkPublisher)(nil), ps)
+		assert.Implements(t, (*BulkSubscriber)(nil), ps)
+		assert.Implements(t, (*health.Pinger)(nil), ps)
+		assert.NoError(t, Ping(ps))
+
+		ps = wrapAll(&fakePubSub{})
+		_, ok := ps.(BulkPublisher)
+		assert.False(t, ok)
+		_, ok = ps.(BulkSubscriber)
+		assert.False(t, ok)
+		assert.Error(t, Ping(ps))
+		assert.Implements(t, (*Drainer)(nil), ps)
+	})
+
+	t.Run("stats of bulk subscriptions", func(t *testing.T) {
+		inner := &bulkPubSub{}
+		ps := NewStatsPubSub(inner)
+		require.NoError(t, ps.(BulkSubscriber).BulkSubscribe(context.Background(), SubscribeRequest{Topic: "orders"}, func(ctx context.Context, msg *BulkMessage) ([]BulkSubscribeResponseEntry, error) {
+			return []BulkSubscribeResponseEntry{{EntryId: "1"}, {EntryId: "2", Error: errors.New("failed")}}, errors.New("failed")
+		}))
+
+		_, err := inner.bulkHandler(context.Background(), &BulkMessage{Topic: "orders", Entries: []BulkMessageEntry{{EntryId: "1"}, {EntryId: "2"}}})
+		assert.Error(t, err)
+
+		s := GetStats(ps)["orders"]
+		assert.Equal(t, uint64(2), s.Delivered)
+		assert.Equal(t, uint64(1), s.Acked)
+		assert.Equal(t, uint64(1), s.Failed)
+	})
+
+	t.Run("validation of bulk requests", func(t *testing.T) {
+		inner := &bulkPubSub{}
+		ps := NewValidatingPubSub(inner, logger.NewLogger("test"))
+		require.NoError(t, ps.Init(Metadata{Base: metadata.Base{Properties: map[string]string{
+			"validationSchema": `{"type": "object", "required": ["orderId"]}`,
+		}}}))
+
+		res, err := ps.(BulkPublisher).BulkPublish(context.Background(), &BulkPublishRequest{Topic: "orders", Entries: []BulkMessageEntry{
+			{EntryId: "1", Event: []byte(`{"orderId":1}`)},
+			{EntryId: "2", Event: []byte(`{}`)},
+		}})
+		assert.Error(t, err)
+		require.Len(t, res.FailedEntries, 1)
+		assert.Equal(t, "2", res.FailedEntries[0].EntryId)
+		require.Len(t, inner.bulkPublished, 1)
+		assert.Equal(t, []BulkMessageEntry{{EntryId: "1", Event: []byte(`{"orderId":1}`)}}, inner.bulkPublished[0].Entries)
+
+		var handled []BulkMessageEntry
+		require.NoError(t, ps.(BulkSubscriber).BulkSubscribe(context.Background(), SubscribeRequest{Topic: "orders"}, func(ctx context.Context, msg *BulkMessage) ([]BulkSubscribeResponseEntry, error) {
+			handled = msg.Entries
+			return nil, nil
+		}))
+		statuses, err := inner.bulkHandler(context.Background(), &BulkMessage{Topic: "orders", Entries: []BulkMessageEntry{
+			{EntryId: "1", Event: []byte(`{}`)},
+			{EntryId: "2", Event: []byte(`{"orderId":2}`)},
+		}})
+		assert.Error(t, err)
+		require.Len(t, statuses, 2)
+		assert.Error(t, statuses[0].Error)
+		assert.NoError(t, statuses[1].Error)
+		assert.Equal(t, []BulkMessageEntry{{EntryId: "2", Event: []byte(`{"orderId":2}`)}}, handled)
+	})
+}
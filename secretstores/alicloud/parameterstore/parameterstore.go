@@ -48,6 +48,7 @@ type ParameterStoreMetaData struct {
 	AccessKeyID     *string `json:"accessKeyId"`
 	AccessKeySecret *string `json:"accessKeySecret"`
 	SecurityToken   *string `json:"securityToken"`
+	PinVersion      string  `json:"pinVersion"`
 }
 
 type parameterStoreClient interface {
@@ -57,6 +58,7 @@ type parameterStoreClient interface {
 
 type oosSecretStore struct {
 	client parameterStoreClient
+	pins   secretstores.VersionPins
 	logger logger.Logger
 }
 
@@ -67,6 +69,11 @@ func (o *oosSecretStore) Init(metadata secretstores.Metadata) error {
 		return err
 	}
 
+	o.pins, err = secretstores.ParseVersionPins(meta.PinVersion)
+	if err != nil {
+		return err
+	}
+
 	client, err := o.getClient(meta)
 	if err != nil {
 		return err
@@ -80,7 +87,7 @@ func (o *oosSecretStore) Init(metadata secretstores.Metadata) error {
 func (o *oosSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
 	name := req.Name
 
-	parameterVersion, err := o.getVersionFromMetadata(req.Metadata)
+	parameterVersion, err := o.getVersionFromMetadata(name, req.Metadata)
 	if err != nil {
 		return secretstores.GetSecretResponse{}, err
 	}
@@ -168,9 +175,9 @@ func (o *oosSecretStore) getParameterStoreMetadata(spec secretstores.Metadata) (
 	return &meta, nil
 }
 
-// getVersionFromMetadata returns the parameter version from the metadata. If not set means latest version.
-func (o *oosSecretStore) getVersionFromMetadata(metadata map[string]string) (*int32, error) {
-	if s, ok := metadata[VersionID]; ok && s != "" {
+// getVersionFromMetadata returns the parameter version from the metadata or the pinned version. If not set means latest version.
+func (o *oosSecretStore) getVersionFromMetadata(name string, metadata map[string]string) (*int32, error) {
+	if s := o.pins.Version(name, metadata, VersionID); s != "" {
 		val, err := strconv.ParseInt(s, 10, 32)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,279 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kms implements a secret store for the keys of AWS KMS, including the keys which KMS keeps in an AWS CloudHSM
+// cluster through a custom key store.
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+
+	awsAuth "github.com/dapr/components-contrib/internal/authentication/aws"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/kit/logger"
+)
+
+// Request metadata keys.
+const (
+	// KeyFormat is the format of the public keys: "pem", the default, or "der" for base64 encoded DER.
+	KeyFormat = "key_format"
+	// Ciphertext is a base64 encoded data key wrapped by a symmetric key, which GetSecret unwraps.
+	Ciphertext = "ciphertext"
+
+	keyFormatPEM = "pem"
+	keyFormatDER = "der"
+)
+
+var _ secretstores.SecretStore = (*kmsSecretStore)(nil)
+
+// NewKMSSecretStore returns a new AWS KMS secret store.
+// The secrets are the public keys of the asymmetric keys, and the data keys unwrapped by the symmetric keys.
+// The keys never leave KMS, or the AWS CloudHSM cluster of a custom key store: as KMS only keeps symmetric keys in
+// CloudHSM key stores, their keys are retrieved by unwrapping the data keys they encrypted, with the ciphertext
+// request metadata.
+func NewKMSSecretStore(logger logger.Logger) secretstores.SecretStore {
+	return &kmsSecretStore{logger: logger}
+}
+
+type KMSMetaData struct {
+	Region               string `json:"region"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	Endpoint             string `json:"endpoint"`
+	// CustomKeyStoreID restricts the store to the keys of a custom key store, such as an AWS CloudHSM key store.
+	CustomKeyStoreID string `json:"customKeyStoreId"`
+}
+
+type kmsSecretStore struct {
+	client           kmsiface.KMSAPI
+	customKeyStoreID string
+	logger           logger.Logger
+}
+
+// Init creates a AWS KMS client.
+// With a custom key store, it checks that the key store is connected to its cluster: KMS authenticates to the
+// CloudHSM cluster with the credentials of the key store, while the store authenticates to KMS as any AWS client.
+func (s *kmsSecretStore) Init(metadata secretstores.Metadata) error {
+	meta, err := s.getKMSMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.getClient(meta)
+	if err != nil {
+		return err
+	}
+	s.client = client
+	s.customKeyStoreID = meta.CustomKeyStoreID
+
+	if s.customKeyStoreID != "" {
+		return s.checkCustomKeyStore(context.Background())
+	}
+	return nil
+}
+
+func (s *kmsSecretStore) checkCustomKeyStore(ctx context.Context) error {
+	output, err := s.client.DescribeCustomKeyStoresWithContext(ctx, &kms.DescribeCustomKeyStoresInput{
+		CustomKeyStoreId: aws.String(s.customKeyStoreID),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't describe custom key store %s: %w", s.customKeyStoreID, err)
+	}
+	if len(output.CustomKeyStores) != 1 {
+		return fmt.Errorf("custom key store %s not found", s.customKeyStoreID)
+	}
+	if state := aws.StringValue(output.CustomKeyStores[0].ConnectionState); state != kms.ConnectionStateTypeConnected {
+		return fmt.Errorf("custom key store %s is %s: it must be connected to its key manager", s.customKeyStoreID, state)
+	}
+	return nil
+}
+
+// GetSecret retrieves the public key of an asymmetric key, or unwraps the data key in the ciphertext metadata with a
+// symmetric key. The name of the secret is the ID, the ARN or the alias of the key.
+func (s *kmsSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	key, err := s.describeKey(ctx, req.Name)
+	if err != nil {
+		return secretstores.GetSecretResponse{Data: nil}, err
+	}
+
+	var value string
+	if ciphertext, ok := req.Metadata[Ciphertext]; ok {
+		value, err = s.unwrap(ctx, key, ciphertext)
+	} else {
+		value, err = s.publicKey(ctx, key, req.Metadata[KeyFormat])
+	}
+	if err != nil {
+		return secretstores.GetSecretResponse{Data: nil}, err
+	}
+
+	return secretstores.GetSecretResponse{
+		Data: map[string]string{req.Name: value},
+	}, nil
+}
+
+// BulkGetSecret retrieves the public keys of all the enabled asymmetric keys, by key ID.
+// The symmetric keys, such as the keys of CloudHSM key stores, have no public key and are skipped.
+func (s *kmsSecretStore) BulkGetSecret(ctx context.Context, req secretstores.BulkGetSecretRequest) (secretstores.BulkGetSecretResponse, error) {
+	resp := secretstores.BulkGetSecretResponse{
+		Data: map[string]map[string]string{},
+	}
+
+	var keyIDs []string
+	err := s.client.ListKeysPagesWithContext(ctx, &kms.ListKeysInput{}, func(page *kms.ListKeysOutput, _ bool) bool {
+		for _, entry := range page.Keys {
+			keyIDs = append(keyIDs, aws.StringValue(entry.KeyId))
+		}
+		return true
+	})
+	if err != nil {
+		return secretstores.BulkGetSecretResponse{Data: nil}, fmt.Errorf("couldn't list keys: %w", err)
+	}
+
+	for _, keyID := range keyIDs {
+		output, err := s.client.DescribeKeyWithContext(ctx, &kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+		if err != nil {
+			return secretstores.BulkGetSecretResponse{Data: nil}, fmt.Errorf("couldn't describe key %s: %w", keyID, err)
+		}
+		key := output.KeyMetadata
+		if !s.inKeyStore(key) || aws.StringValue(key.KeyState) != kms.KeyStateEnabled || !asymmetric(key) {
+			continue
+		}
+		value, err := s.publicKey(ctx, key, req.Metadata[KeyFormat])
+		if err != nil {
+			return secretstores.BulkGetSecretResponse{Data: nil}, err
+		}
+		resp.Data[keyID] = map[string]string{keyID: value}
+	}
+
+	return resp, nil
+}
+
+// describeKey returns the metadata of an enabled key of the store.
+func (s *kmsSecretStore) describeKey(ctx context.Context, name string) (*kms.KeyMetadata, error) {
+	output, err := s.client.DescribeKeyWithContext(ctx, &kms.DescribeKeyInput{KeyId: aws.String(name)})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get secret: %w", err)
+	}
+	key := output.KeyMetadata
+	if !s.inKeyStore(key) {
+		return nil, fmt.Errorf("key %s is not in custom key store %s", name, s.customKeyStoreID)
+	}
+	if state := aws.StringValue(key.KeyState); state != kms.KeyStateEnabled {
+		return nil, fmt.Errorf("key %s is %s", name, state)
+	}
+	return key, nil
+}
+
+func (s *kmsSecretStore) inKeyStore(key *kms.KeyMetadata) bool {
+	return s.customKeyStoreID == "" || aws.StringValue(key.CustomKeyStoreId) == s.customKeyStoreID
+}
+
+func asymmetric(key *kms.KeyMetadata) bool {
+	spec := aws.StringValue(key.KeySpec)
+	return spec != kms.KeySpecSymmetricDefault && spec != kms.KeySpecHmac224 && spec != kms.KeySpecHmac256 &&
+		spec != kms.KeySpecHmac384 && spec != kms.KeySpecHmac512
+}
+
+// publicKey returns the public key of an asymmetric key, in the format.
+func (s *kmsSecretStore) publicKey(ctx context.Context, key *kms.KeyMetadata, format string) (string, error) {
+	if format != "" && format != keyFormatPEM && format != keyFormatDER {
+		return "", fmt.Errorf("invalid %s %q: must be %s or %s", KeyFormat, format, keyFormatPEM, keyFormatDER)
+	}
+	if !asymmetric(key) {
+		return "", fmt.Errorf("symmetric key %s has no public key: set the %s metadata to unwrap a data key with it", aws.StringValue(key.KeyId), Ciphertext)
+	}
+
+	output, err := s.client.GetPublicKeyWithContext(ctx, &kms.GetPublicKeyInput{KeyId: key.Arn})
+	if err != nil {
+		return "", fmt.Errorf("couldn't get public key of %s: %w", aws.StringValue(key.KeyId), err)
+	}
+	if format == keyFormatDER {
+		return base64.StdEncoding.EncodeToString(output.PublicKey), nil
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: output.PublicKey})), nil
+}
+
+// unwrap returns the base64 encoded data key decrypted by a symmetric key from its base64 encoded ciphertext.
+func (s *kmsSecretStore) unwrap(ctx context.Context, key *kms.KeyMetadata, ciphertext string) (string, error) {
+	if aws.StringValue(key.KeySpec) != kms.KeySpecSymmetricDefault {
+		return "", fmt.Errorf("key %s can't unwrap data keys: only symmetric keys can", aws.StringValue(key.KeyId))
+	}
+	blob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", Ciphertext, err)
+	}
+	if len(blob) == 0 {
+		return "", errors.New("invalid " + Ciphertext + ": empty")
+	}
+
+	output, err := s.client.DecryptWithContext(ctx, &kms.DecryptInput{
+		KeyId:          key.Arn,
+		CiphertextBlob: blob,
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't unwrap data key with %s: %w", aws.StringValue(key.KeyId), err)
+	}
+	return base64.StdEncoding.EncodeToString(output.Plaintext), nil
+}
+
+func (s *kmsSecretStore) getClient(metadata *KMSMetaData) (*kms.KMS, error) {
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kms.New(sess), nil
+}
+
+func (s *kmsSecretStore) getKMSMetadata(spec secretstores.Metadata) (*KMSMetaData, error) {
+	meta := KMSMetaData{}
+	err := metadata.DecodeMetadata(spec.Properties, &meta)
+	return &meta, err
+}
+
+// Features returns the features available in this secret store.
+func (s *kmsSecretStore) Features() []secretstores.Feature {
+	return []secretstores.Feature{} // No Feature supported.
+}
+
+func (s *kmsSecretStore) GetComponentMetadata() map[string]string {
+	metadataStruct := KMSMetaData{}
+	metadataInfo := map[string]string{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo)
+	return metadataInfo
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/kit/logger"
+)
+
+// mockedKMS serves the keys, by key ID.
+type mockedKMS struct {
+	kmsiface.KMSAPI
+	keys       map[string]*kms.KeyMetadata
+	publicKey  []byte
+	keyStores  []*kms.CustomKeyStoresListEntry
+	decryptErr error
+}
+
+func (m *mockedKMS) DescribeKeyWithContext(ctx context.Context, input *kms.DescribeKeyInput, option ...request.Option) (*kms.DescribeKeyOutput, error) {
+	key, ok := m.keys[*input.KeyId]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &kms.DescribeKeyOutput{KeyMetadata: key}, nil
+}
+
+func (m *mockedKMS) GetPublicKeyWithContext(ctx context.Context, input *kms.GetPublicKeyInput, option ...request.Option) (*kms.GetPublicKeyOutput, error) {
+	return &kms.GetPublicKeyOutput{KeyId: input.KeyId, PublicKey: m.publicKey}, nil
+}
+
+func (m *mockedKMS) DecryptWithContext(ctx context.Context, input *kms.DecryptInput, option ...request.Option) (*kms.DecryptOutput, error) {
+	if m.decryptErr != nil {
+		return nil, m.decryptErr
+	}
+	// The "ciphertext" is the reversed data key.
+	plaintext := make([]byte, len(input.CiphertextBlob))
+	for i, b := range input.CiphertextBlob {
+		plaintext[len(plaintext)-1-i] = b
+	}
+	return &kms.DecryptOutput{KeyId: input.KeyId, Plaintext: plaintext}, nil
+}
+
+func (m *mockedKMS) ListKeysPagesWithContext(ctx context.Context, input *kms.ListKeysInput, fn func(*kms.ListKeysOutput, bool) bool, option ...request.Option) error {
+	for id := range m.keys {
+		if !fn(&kms.ListKeysOutput{Keys: []*kms.KeyListEntry{{KeyId: aws.String(id)}}}, false) {
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockedKMS) DescribeCustomKeyStoresWithContext(ctx context.Context, input *kms.DescribeCustomKeyStoresInput, option ...request.Option) (*kms.DescribeCustomKeyStoresOutput, error) {
+	return &kms.DescribeCustomKeyStoresOutput{CustomKeyStores: m.keyStores}, nil
+}
+
+func key(id string, spec string, keyStoreID string) *kms.KeyMetadata {
+	key := &kms.KeyMetadata{
+		KeyId:    aws.String(id),
+		Arn:      aws.String("arn:aws:kms:us-east-1:111122223333:key/" + id),
+		KeySpec:  aws.String(spec),
+		KeyState: aws.String(kms.KeyStateEnabled),
+	}
+	if keyStoreID != "" {
+		key.CustomKeyStoreId = aws.String(keyStoreID)
+		key.KeyManager = aws.String(kms.KeyManagerTypeCustomer)
+	}
+	return key
+}
+
+func newMockedKMS(t *testing.T) *mockedKMS {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+
+	disabled := key("disabled", kms.KeySpecEccNistP256, "")
+	disabled.KeyState = aws.String(kms.KeyStateDisabled)
+	return &mockedKMS{
+		keys: map[string]*kms.KeyMetadata{
+			"signing":  key("signing", kms.KeySpecEccNistP256, ""),
+			"hsm":      key("hsm", kms.KeySpecSymmetricDefault, "cks-1234"),
+			"disabled": disabled,
+		},
+		publicKey: der,
+	}
+}
+
+func TestInit(t *testing.T) {
+	m := secretstores.Metadata{}
+	s := NewKMSSecretStore(logger.NewLogger("test"))
+	t.Run("Init with valid metadata", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"AccessKey":    "a",
+			"Region":       "a",
+			"Endpoint":     "a",
+			"SecretKey":    "a",
+			"SessionToken": "a",
+		}
+		err := s.Init(m)
+		assert.Nil(t, err)
+	})
+
+	t.Run("custom key store must be connected", func(t *testing.T) {
+		client := newMockedKMS(t)
+		s := kmsSecretStore{client: client, customKeyStoreID: "cks-1234"}
+		assert.Error(t, s.checkCustomKeyStore(context.Background()))
+
+		client.keyStores = []*kms.CustomKeyStoresListEntry{{
+			CustomKeyStoreId:   aws.String("cks-1234"),
+			CustomKeyStoreType: aws.String(kms.CustomKeyStoreTypeAwsCloudhsm),
+			ConnectionState:    aws.String(kms.ConnectionStateTypeDisconnected),
+		}}
+		assert.Error(t, s.checkCustomKeyStore(context.Background()))
+
+		client.keyStores[0].ConnectionState = aws.String(kms.ConnectionStateTypeConnected)
+		assert.NoError(t, s.checkCustomKeyStore(context.Background()))
+	})
+}
+
+func TestGetSecret(t *testing.T) {
+	client := newMockedKMS(t)
+	s := kmsSecretStore{client: client}
+
+	t.Run("public key as PEM", func(t *testing.T) {
+		output, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "signing"})
+		require.NoError(t, err)
+		block, _ := pem.Decode([]byte(output.Data["signing"]))
+		require.NotNil(t, block)
+		assert.Equal(t, "PUBLIC KEY", block.Type)
+		assert.Equal(t, client.publicKey, block.Bytes)
+	})
+
+	t.Run("public key as DER", func(t *testing.T) {
+		output, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{
+			Name:     "signing",
+			Metadata: map[string]string{KeyFormat: "der"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(client.publicKey), output.Data["signing"])
+
+		_, err = s.GetSecret(context.Background(), secretstores.GetSecretRequest{
+			Name:     "signing",
+			Metadata: map[string]string{KeyFormat: "jwk"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("data key unwrapped by a CloudHSM key", func(t *testing.T) {
+		output, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{
+			Name:     "hsm",
+			Metadata: map[string]string{Ciphertext: base64.StdEncoding.EncodeToString([]byte("yek"))},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("key")), output.Data["hsm"])
+
+		client.decryptErr = errors.New("the key store is disconnected")
+		defer func() { client.decryptErr = nil }()
+		_, err = s.GetSecret(context.Background(), secretstores.GetSecretRequest{
+			Name:     "hsm",
+			Metadata: map[string]string{Ciphertext: base64.StdEncoding.EncodeToString([]byte("yek"))},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("symmetric keys have no public key", func(t *testing.T) {
+		_, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "hsm"})
+		assert.Error(t, err)
+	})
+
+	t.Run("asymmetric keys don't unwrap data keys", func(t *testing.T) {
+		_, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{
+			Name:     "signing",
+			Metadata: map[string]string{Ciphertext: base64.StdEncoding.EncodeToString([]byte("yek"))},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("disabled and unknown keys", func(t *testing.T) {
+		_, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "disabled"})
+		assert.Error(t, err)
+		_, err = s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "unknown"})
+		assert.Error(t, err)
+	})
+
+	t.Run("keys outside of the custom key store", func(t *testing.T) {
+		s := kmsSecretStore{client: client, customKeyStoreID: "cks-1234"}
+		_, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "signing"})
+		assert.Error(t, err)
+
+		_, err = s.GetSecret(context.Background(), secretstores.GetSecretRequest{
+			Name:     "hsm",
+			Metadata: map[string]string{Ciphertext: base64.StdEncoding.EncodeToString([]byte("yek"))},
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetBulkSecrets(t *testing.T) {
+	client := newMockedKMS(t)
+
+	s := kmsSecretStore{client: client}
+	output, err := s.BulkGetSecret(context.Background(), secretstores.BulkGetSecretRequest{})
+	require.NoError(t, err)
+	assert.Len(t, output.Data, 1)
+	assert.Contains(t, output.Data["signing"]["signing"], "PUBLIC KEY")
+
+	s = kmsSecretStore{client: client, customKeyStoreID: "cks-1234"}
+	output, err = s.BulkGetSecret(context.Background(), secretstores.BulkGetSecretRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, output.Data)
+}
+
+func TestGetFeatures(t *testing.T) {
+	s := kmsSecretStore{}
+	t.Run("no features are advertised", func(t *testing.T) {
+		f := s.Features()
+		assert.Empty(t, f)
+	})
+}
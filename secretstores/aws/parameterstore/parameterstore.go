@@ -41,16 +41,24 @@ func NewParameterStore(logger logger.Logger) secretstores.SecretStore {
 }
 
 type ParameterStoreMetaData struct {
-	Region       string `json:"region"`
-	AccessKey    string `json:"accessKey"`
-	SecretKey    string `json:"secretKey"`
-	SessionToken string `json:"sessionToken"`
-	Prefix       string `json:"prefix"`
+	Region               string `json:"region"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	Endpoint             string `json:"endpoint"`
+	Prefix               string `json:"prefix"`
+	// PinVersion is the version, or the label, of the parameters read without a version.
+	PinVersion string `json:"pinVersion"`
 }
 
 type ssmSecretStore struct {
 	client ssmiface.SSMAPI
 	prefix string
+	pins   secretstores.VersionPins
 	logger logger.Logger
 }
 
@@ -61,6 +69,11 @@ func (s *ssmSecretStore) Init(metadata secretstores.Metadata) error {
 		return err
 	}
 
+	s.pins, err = secretstores.ParseVersionPins(meta.PinVersion)
+	if err != nil {
+		return err
+	}
+
 	client, err := s.getClient(meta)
 	if err != nil {
 		return err
@@ -73,16 +86,8 @@ func (s *ssmSecretStore) Init(metadata secretstores.Metadata) error {
 
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values.
 func (s *ssmSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
-	name := req.Name
-
-	var versionID string
-	if value, ok := req.Metadata[VersionID]; ok {
-		versionID = value
-		name = fmt.Sprintf("%s:%s", req.Name, versionID)
-	}
-
 	output, err := s.client.GetParameterWithContext(ctx, &ssm.GetParameterInput{
-		Name:           aws.String(s.prefix + name),
+		Name:           aws.String(s.prefix + s.selector(req.Name, req.Metadata)),
 		WithDecryption: aws.Bool(true),
 	})
 	if err != nil {
@@ -132,7 +137,7 @@ func (s *ssmSecretStore) BulkGetSecret(ctx context.Context, req secretstores.Bul
 
 		for _, entry := range output.Parameters {
 			params, err := s.client.GetParameterWithContext(ctx, &ssm.GetParameterInput{
-				Name:           entry.Name,
+				Name:           aws.String(s.prefix + s.selector((*entry.Name)[len(s.prefix):], nil)),
 				WithDecryption: aws.Bool(true),
 			})
 			if err != nil {
@@ -152,8 +157,31 @@ func (s *ssmSecretStore) BulkGetSecret(ctx context.Context, req secretstores.Bul
 	return resp, nil
 }
 
+// selector returns the name of the parameter with the version or the label to read, either requested or pinned.
+// Parameter Store labels play the role of version stages.
+func (s *ssmSecretStore) selector(name string, md map[string]string) string {
+	version := secretstores.RequestedVersionStage(md)
+	if version == "" {
+		version = s.pins.Version(name, md, VersionID)
+	}
+	if version == "" {
+		return name
+	}
+	return name + ":" + version
+}
+
 func (s *ssmSecretStore) getClient(metadata *ParameterStoreMetaData) (*ssm.SSM, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, "")
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
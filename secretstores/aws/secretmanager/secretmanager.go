@@ -41,15 +41,26 @@ func NewSecretManager(logger logger.Logger) secretstores.SecretStore {
 }
 
 type SecretManagerMetaData struct {
-	Region       string `json:"region"`
-	AccessKey    string `json:"accessKey"`
-	SecretKey    string `json:"secretKey"`
-	SessionToken string `json:"sessionToken"`
+	Region               string `json:"region"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	Endpoint             string `json:"endpoint"`
+	// PinVersion is the version ID of the secrets read without a version or a stage.
+	PinVersion string `json:"pinVersion"`
+	// PinVersionStage is the stage of the secrets read without a version or a stage, e.g. AWSPREVIOUS to roll back.
+	PinVersionStage string `json:"pinVersionStage"`
 }
 
 type smSecretStore struct {
-	client secretsmanageriface.SecretsManagerAPI
-	logger logger.Logger
+	client    secretsmanageriface.SecretsManagerAPI
+	pins      secretstores.VersionPins
+	stagePins secretstores.VersionPins
+	logger    logger.Logger
 }
 
 // Init creates a AWS secret manager client.
@@ -59,6 +70,15 @@ func (s *smSecretStore) Init(metadata secretstores.Metadata) error {
 		return err
 	}
 
+	s.pins, err = secretstores.ParseVersionPins(meta.PinVersion)
+	if err != nil {
+		return err
+	}
+	s.stagePins, err = secretstores.ParseVersionPins(meta.PinVersionStage)
+	if err != nil {
+		return err
+	}
+
 	client, err := s.getClient(meta)
 	if err != nil {
 		return err
@@ -70,15 +90,7 @@ func (s *smSecretStore) Init(metadata secretstores.Metadata) error {
 
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values.
 func (s *smSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
-	var versionID *string
-	if value, ok := req.Metadata[VersionID]; ok {
-		versionID = &value
-	}
-	var versionStage *string
-	if value, ok := req.Metadata[VersionStage]; ok {
-		versionStage = &value
-	}
-
+	versionID, versionStage := s.version(req.Name, req.Metadata)
 	output, err := s.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
 		SecretId:     &req.Name,
 		VersionId:    versionID,
@@ -117,8 +129,11 @@ func (s *smSecretStore) BulkGetSecret(ctx context.Context, req secretstores.Bulk
 		}
 
 		for _, entry := range output.SecretList {
+			versionID, versionStage := s.version(*entry.Name, nil)
 			secrets, err := s.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
-				SecretId: entry.Name,
+				SecretId:     entry.Name,
+				VersionId:    versionID,
+				VersionStage: versionStage,
 			})
 			if err != nil {
 				return secretstores.BulkGetSecretResponse{Data: nil}, fmt.Errorf("couldn't get secret: %s", *entry.Name)
@@ -136,8 +151,37 @@ func (s *smSecretStore) BulkGetSecret(ctx context.Context, req secretstores.Bulk
 	return resp, nil
 }
 
+// version returns the version ID and the stage to read: the ones of the request if any, else the pinned ones.
+func (s *smSecretStore) version(name string, md map[string]string) (*string, *string) {
+	versionID := secretstores.RequestedVersion(md, VersionID)
+	versionStage := secretstores.RequestedVersionStage(md, VersionStage)
+	if versionID == "" && versionStage == "" {
+		versionID = s.pins.Pinned(name)
+		versionStage = s.stagePins.Pinned(name)
+	}
+
+	var id, stage *string
+	if versionID != "" {
+		id = &versionID
+	}
+	if versionStage != "" {
+		stage = &versionStage
+	}
+	return id, stage
+}
+
 func (s *smSecretStore) getClient(metadata *SecretManagerMetaData) (*secretsmanager.SecretsManager, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, "")
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
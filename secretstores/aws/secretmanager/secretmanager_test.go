@@ -133,6 +133,64 @@ func TestGetSecret(t *testing.T) {
 			assert.Nil(t, e)
 			assert.Equal(t, secretValue, output.Data[req.Name])
 		})
+
+		t.Run("with pinned version", func(t *testing.T) {
+			pins, err := secretstores.ParseVersionPins("/aws/secret/testing=v1,/aws/secret/other=v2")
+			assert.NoError(t, err)
+			s := smSecretStore{
+				pins: pins,
+				client: &mockedSM{
+					GetSecretValueFn: func(ctx context.Context, input *secretsmanager.GetSecretValueInput, option ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+						assert.Equal(t, "v1", *input.VersionId)
+						assert.Nil(t, input.VersionStage)
+						secret := secretValue
+
+						return &secretsmanager.GetSecretValueOutput{
+							Name:         input.SecretId,
+							SecretString: &secret,
+						}, nil
+					},
+				},
+			}
+
+			req := secretstores.GetSecretRequest{
+				Name:     "/aws/secret/testing",
+				Metadata: map[string]string{},
+			}
+			output, e := s.GetSecret(context.Background(), req)
+			assert.Nil(t, e)
+			assert.Equal(t, secretValue, output.Data[req.Name])
+		})
+
+		t.Run("requested version overrides pinned version", func(t *testing.T) {
+			pins, err := secretstores.ParseVersionPins("v1")
+			assert.NoError(t, err)
+			s := smSecretStore{
+				pins: pins,
+				client: &mockedSM{
+					GetSecretValueFn: func(ctx context.Context, input *secretsmanager.GetSecretValueInput, option ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+						assert.Nil(t, input.VersionId)
+						assert.Equal(t, "AWSPREVIOUS", *input.VersionStage)
+						secret := secretValue
+
+						return &secretsmanager.GetSecretValueOutput{
+							Name:         input.SecretId,
+							SecretString: &secret,
+						}, nil
+					},
+				},
+			}
+
+			req := secretstores.GetSecretRequest{
+				Name: "/aws/secret/testing",
+				Metadata: map[string]string{
+					secretstores.VersionStageMetadataKey: "AWSPREVIOUS",
+				},
+			}
+			output, e := s.GetSecret(context.Background(), req)
+			assert.Nil(t, e)
+			assert.Equal(t, secretValue, output.Data[req.Name])
+		})
 	})
 
 	t.Run("unsuccessfully retrieve secret", func(t *testing.T) {
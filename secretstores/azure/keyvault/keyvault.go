@@ -34,7 +34,12 @@ import (
 // This is in addition to what's defined in authentication/azure.
 const (
 	VersionID          = "version_id"
+	KeyFormat          = "key_format"
 	secretItemIDPrefix = "/secrets/"
+
+	// Values of vaultType.
+	vaultTypeVault      = "vault"
+	vaultTypeManagedHSM = "managedHSM"
 )
 
 var _ secretstores.SecretStore = (*keyvaultSecretStore)(nil)
@@ -43,12 +48,19 @@ type keyvaultSecretStore struct {
 	vaultName      string
 	vaultClient    *azsecrets.Client
 	vaultDNSSuffix string
+	// hsmClient retrieves the keys of the Managed HSM pool when the vault type is managedHSM.
+	hsmClient *managedHSMClient
+	pins      secretstores.VersionPins
 
 	logger logger.Logger
 }
 
 type KeyvaultMetadata struct {
 	VaultName string
+	// VaultType is "vault", the default, or "managedHSM" for the keys of a Managed HSM pool.
+	VaultType string
+	// PinVersion is the version of the secrets read without a version.
+	PinVersion string
 }
 
 // NewAzureKeyvaultSecretStore returns a new Azure Key Vault secret store.
@@ -62,10 +74,18 @@ func NewAzureKeyvaultSecretStore(logger logger.Logger) secretstores.SecretStore
 
 // Init creates a Azure Key Vault client.
 func (k *keyvaultSecretStore) Init(meta secretstores.Metadata) error {
-	m := KeyvaultMetadata{}
+	m := KeyvaultMetadata{VaultType: vaultTypeVault}
 	if err := metadata.DecodeMetadata(meta.Properties, &m); err != nil {
 		return err
 	}
+	if m.VaultType != vaultTypeVault && m.VaultType != vaultTypeManagedHSM {
+		return fmt.Errorf("invalid vaultType %q: must be %s or %s", m.VaultType, vaultTypeVault, vaultTypeManagedHSM)
+	}
+	pins, err := secretstores.ParseVersionPins(m.PinVersion)
+	if err != nil {
+		return err
+	}
+	k.pins = pins
 	// Fix for maintaining backwards compatibility with a change introduced in 1.3 that allowed specifying an Azure environment by setting a FQDN for vault name
 	// This should be considered deprecated and users should rely the "azureEnvironment" metadata instead, but it's maintained here for backwards-compatibility
 	if m.VaultName != "" {
@@ -75,6 +95,13 @@ func (k *keyvaultSecretStore) Init(meta secretstores.Metadata) error {
 			".vault.usgovcloudapi.net": "AZUREUSGOVERNMENTCLOUD",
 			".vault.microsoftazure.de": "AZUREGERMANCLOUD",
 		}
+		if m.VaultType == vaultTypeManagedHSM {
+			keyVaultSuffixToEnvironment = map[string]string{
+				".managedhsm.azure.net":         "AZUREPUBLICCLOUD",
+				".managedhsm.azure.cn":          "AZURECHINACLOUD",
+				".managedhsm.usgovcloudapi.net": "AZUREUSGOVERNMENTCLOUD",
+			}
+		}
 		for suffix, environment := range keyVaultSuffixToEnvironment {
 			if strings.HasSuffix(m.VaultName, suffix) {
 				meta.Properties["azureEnvironment"] = environment
@@ -89,7 +116,11 @@ func (k *keyvaultSecretStore) Init(meta secretstores.Metadata) error {
 	}
 
 	// Initialization code
-	settings, err := azauth.NewEnvironmentSettings("keyvault", meta.Properties)
+	resourceName := azauth.AzureKeyVaultResourceName
+	if m.VaultType == vaultTypeManagedHSM {
+		resourceName = azauth.AzureManagedHSMResourceName
+	}
+	settings, err := azauth.NewEnvironmentSettings(resourceName, meta.Properties)
 	if err != nil {
 		return err
 	}
@@ -106,6 +137,14 @@ func (k *keyvaultSecretStore) Init(meta secretstores.Metadata) error {
 			ApplicationID: "dapr-" + logger.DaprVersion,
 		},
 	}
+	if m.VaultType == vaultTypeManagedHSM {
+		// Managed HSM pools are at https://<name>.managedhsm.azure.net, and tokens are requested for that resource.
+		k.vaultDNSSuffix = strings.TrimPrefix(settings.Resource, "https://")
+		k.vaultClient = nil
+		k.hsmClient = newManagedHSMClient(k.getVaultURI(), cred, settings.Resource, &coreClientOpts)
+		return nil
+	}
+	k.hsmClient = nil
 	client, clientErr := azsecrets.NewClient(k.getVaultURI(), cred, &azsecrets.ClientOptions{
 		ClientOptions: coreClientOpts,
 	})
@@ -115,9 +154,22 @@ func (k *keyvaultSecretStore) Init(meta secretstores.Metadata) error {
 
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values.
 func (k *keyvaultSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
-	version := "" // empty string means latest version
-	if val, ok := req.Metadata[VersionID]; ok {
-		version = val
+	version := k.pins.Version(req.Name, req.Metadata, VersionID) // empty string means latest version
+
+	if k.hsmClient != nil {
+		key, err := k.hsmClient.getKey(ctx, req.Name, version)
+		if err != nil {
+			return secretstores.GetSecretResponse{}, err
+		}
+		value, err := formatKey(key, req.Metadata[KeyFormat])
+		if err != nil {
+			return secretstores.GetSecretResponse{}, err
+		}
+		return secretstores.GetSecretResponse{
+			Data: map[string]string{
+				req.Name: value,
+			},
+		}, nil
 	}
 
 	secretResp, err := k.vaultClient.GetSecret(ctx, req.Name, version, nil)
@@ -148,6 +200,14 @@ func (k *keyvaultSecretStore) BulkGetSecret(ctx context.Context, req secretstore
 		Data: map[string]map[string]string{},
 	}
 
+	if k.hsmClient != nil {
+		err = k.bulkGetKeys(ctx, req.Metadata[KeyFormat], maxResults, resp.Data)
+		if err != nil {
+			return secretstores.BulkGetSecretResponse{}, err
+		}
+		return resp, nil
+	}
+
 	secretIDPrefix := k.getVaultURI() + secretItemIDPrefix
 
 	pager := k.vaultClient.NewListSecretsPager(nil)
@@ -165,7 +225,7 @@ out:
 			}
 
 			secretName := strings.TrimPrefix(secret.ID.Name(), secretIDPrefix)
-			secretResp, err := k.vaultClient.GetSecret(ctx, secretName, "", nil) // empty string means latest version
+			secretResp, err := k.vaultClient.GetSecret(ctx, secretName, k.pins.Pinned(secretName), nil) // empty string means latest version
 			if err != nil {
 				return secretstores.BulkGetSecretResponse{}, err
 			}
@@ -186,6 +246,33 @@ out:
 	return resp, nil
 }
 
+// bulkGetKeys retrieves the enabled keys of the Managed HSM pool, in the format.
+func (k *keyvaultSecretStore) bulkGetKeys(ctx context.Context, format string, maxResults *int32, data map[string]map[string]string) error {
+	var err error
+	listErr := k.hsmClient.listKeys(ctx, func(names []string) bool {
+		for _, name := range names {
+			var (
+				key   []byte
+				value string
+			)
+			key, err = k.hsmClient.getKey(ctx, name, k.pins.Pinned(name))
+			if err != nil {
+				return false
+			}
+			value, err = formatKey(key, format)
+			if err != nil {
+				return false
+			}
+			data[name] = map[string]string{name: value}
+		}
+		return maxResults == nil || *maxResults <= 0 || len(data) < int(*maxResults)
+	})
+	if listErr != nil {
+		return listErr
+	}
+	return err
+}
+
 // getVaultURI returns Azure Key Vault URI.
 func (k *keyvaultSecretStore) getVaultURI() string {
 	return fmt.Sprintf("https://%s.%s", k.vaultName, k.vaultDNSSuffix)
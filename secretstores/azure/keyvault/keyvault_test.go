@@ -15,9 +15,22 @@ limitations under the License.
 package keyvault
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/secretstores"
 	"github.com/dapr/kit/logger"
@@ -87,6 +100,134 @@ func TestInit(t *testing.T) {
 		assert.Equal(t, kv.vaultDNSSuffix, "vault.usgovcloudapi.net")
 		assert.NotNil(t, kv.vaultClient)
 	})
+	t.Run("Init with managed HSM", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"vaultName":         "foo",
+			"vaultType":         "managedHSM",
+			"azureTenantId":     "00000000-0000-0000-0000-000000000000",
+			"azureClientId":     "00000000-0000-0000-0000-000000000000",
+			"azureClientSecret": "passw0rd",
+		}
+		err := s.Init(m)
+		assert.Nil(t, err)
+		kv, ok := s.(*keyvaultSecretStore)
+		assert.True(t, ok)
+		assert.Equal(t, kv.vaultName, "foo")
+		assert.Equal(t, kv.vaultDNSSuffix, "managedhsm.azure.net")
+		assert.Nil(t, kv.vaultClient)
+		assert.NotNil(t, kv.hsmClient)
+		assert.Equal(t, "https://foo.managedhsm.azure.net", kv.hsmClient.hsmURI)
+	})
+	t.Run("Init with managed HSM and Azure environment as part of vaultName FQDN", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"vaultName":         "https://foo.managedhsm.azure.cn",
+			"vaultType":         "managedHSM",
+			"azureTenantId":     "00000000-0000-0000-0000-000000000000",
+			"azureClientId":     "00000000-0000-0000-0000-000000000000",
+			"azureClientSecret": "passw0rd",
+		}
+		err := s.Init(m)
+		assert.Nil(t, err)
+		kv, ok := s.(*keyvaultSecretStore)
+		assert.True(t, ok)
+		assert.Equal(t, kv.vaultName, "foo")
+		assert.Equal(t, kv.vaultDNSSuffix, "managedhsm.azure.cn")
+	})
+	t.Run("Init with invalid vault type", func(t *testing.T) {
+		m.Properties = map[string]string{
+			"vaultName": "foo",
+			"vaultType": "cloudHSM",
+		}
+		err := s.Init(m)
+		assert.Error(t, err)
+	})
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if len(opts.Scopes) != 1 || opts.Scopes[0] != "https://managedhsm.azure.net/.default" {
+		return azcore.AccessToken{}, fmt.Errorf("unexpected scopes %v", opts.Scopes)
+	}
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+func TestManagedHSM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwk := fmt.Sprintf(`{"kid":"https://foo.managedhsm.azure.net/keys/signing/v1","kty":"RSA-HSM","key_ops":["sign","verify"],"n":"%s","e":"%s"}`,
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()))
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, managedHSMAPIVersion, r.URL.Query().Get("api-version"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/keys/signing", "/keys/signing/v1":
+			fmt.Fprintf(w, `{"key":%s,"attributes":{"enabled":true}}`, jwk)
+		case "/keys/wrapping":
+			w.Write([]byte(`{"key":{"kid":"https://foo.managedhsm.azure.net/keys/wrapping/v1","kty":"oct-HSM","key_ops":["wrapKey"]},"attributes":{"enabled":true}}`))
+		case "/keys":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"value":[{"kid":"https://foo.managedhsm.azure.net/keys/signing","attributes":{"enabled":true}},
+					{"kid":"https://foo.managedhsm.azure.net/keys/disabled","attributes":{"enabled":false}}],
+					"nextLink":"https://%s/keys?api-version=%s&page=2"}`, r.Host, managedHSMAPIVersion)
+				return
+			}
+			w.Write([]byte(`{"value":[{"kid":"https://foo.managedhsm.azure.net/keys/wrapping","attributes":{"enabled":true}}],"nextLink":null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"KeyNotFound","message":"not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	s := &keyvaultSecretStore{
+		hsmClient: newManagedHSMClient(srv.URL, fakeCredential{}, "https://managedhsm.azure.net", &policy.ClientOptions{
+			Transport: srv.Client(),
+		}),
+		logger: logger.NewLogger("test"),
+	}
+
+	t.Run("get a key as JWK", func(t *testing.T) {
+		resp, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "signing", Metadata: map[string]string{VersionID: "v1"}})
+		require.NoError(t, err)
+		assert.JSONEq(t, jwk, resp.Data["signing"])
+	})
+
+	t.Run("get a key as PEM", func(t *testing.T) {
+		resp, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "signing", Metadata: map[string]string{KeyFormat: "pem"}})
+		require.NoError(t, err)
+		block, _ := pem.Decode([]byte(resp.Data["signing"]))
+		require.NotNil(t, block)
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		require.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(public))
+
+		_, err = s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "wrapping", Metadata: map[string]string{KeyFormat: "pem"}})
+		assert.ErrorContains(t, err, "symmetric keys have no public key")
+	})
+
+	t.Run("key not found", func(t *testing.T) {
+		_, err := s.GetSecret(context.Background(), secretstores.GetSecretRequest{Name: "missing"})
+		assert.ErrorContains(t, err, "KeyNotFound")
+	})
+
+	t.Run("bulk get keys", func(t *testing.T) {
+		resp, err := s.BulkGetSecret(context.Background(), secretstores.BulkGetSecretRequest{})
+		require.NoError(t, err)
+		assert.Len(t, resp.Data, 2)
+		assert.JSONEq(t, jwk, resp.Data["signing"]["signing"])
+		assert.Contains(t, resp.Data["wrapping"]["wrapping"], `"oct-HSM"`)
+
+		resp, err = s.BulkGetSecret(context.Background(), secretstores.BulkGetSecretRequest{Metadata: map[string]string{"maxresults": "1"}})
+		require.NoError(t, err)
+		assert.Len(t, resp.Data, 1)
+	})
 }
 
 func TestGetFeatures(t *testing.T) {
@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvault
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	managedHSMAPIVersion = "7.4"
+
+	// Values of the keyFormat request metadata.
+	keyFormatJWK = "jwk"
+	keyFormatPEM = "pem"
+)
+
+// managedHSMClient retrieves the keys of an Azure Key Vault Managed HSM pool.
+// Managed HSM stores keys only: the "secrets" of the store are the public parts of the keys.
+// The keys of AWS CloudHSM clusters are retrieved through the KMS custom key stores, by the secretstores/aws/kms store.
+type managedHSMClient struct {
+	hsmURI   string
+	pipeline runtime.Pipeline
+}
+
+// managedHSMKey is a key bundle returned by the API.
+type managedHSMKey struct {
+	Key        json.RawMessage `json:"key"`
+	Attributes *struct {
+		Enabled *bool `json:"enabled"`
+	} `json:"attributes"`
+	KID string `json:"kid"`
+}
+
+func newManagedHSMClient(hsmURI string, cred azcore.TokenCredential, resource string, opts *policy.ClientOptions) *managedHSMClient {
+	scopes := []string{strings.TrimSuffix(resource, "/") + "/.default"}
+	return &managedHSMClient{
+		hsmURI: hsmURI,
+		pipeline: runtime.NewPipeline("managedhsm", logger.DaprVersion, runtime.PipelineOptions{
+			PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(cred, scopes, nil)},
+		}, opts),
+	}
+}
+
+// getKey returns the JSON Web Key of the version of the key; the latest version if empty.
+func (c *managedHSMClient) getKey(ctx context.Context, name string, version string) (json.RawMessage, error) {
+	path := "/keys/" + url.PathEscape(name)
+	if version != "" {
+		path += "/" + url.PathEscape(version)
+	}
+	var key managedHSMKey
+	if err := c.get(ctx, c.hsmURI+path+"?api-version="+managedHSMAPIVersion, &key); err != nil {
+		return nil, err
+	}
+	return key.Key, nil
+}
+
+// listKeys calls fn with the names of the enabled keys, page by page, until it returns false.
+func (c *managedHSMClient) listKeys(ctx context.Context, fn func(names []string) bool) error {
+	next := c.hsmURI + "/keys?api-version=" + managedHSMAPIVersion
+	for next != "" {
+		var page struct {
+			Value    []managedHSMKey `json:"value"`
+			NextLink *string         `json:"nextLink"`
+		}
+		if err := c.get(ctx, next, &page); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(page.Value))
+		for _, key := range page.Value {
+			if key.Attributes == nil || key.Attributes.Enabled == nil || !*key.Attributes.Enabled {
+				continue
+			}
+			// Key IDs are https://<pool>/keys/<name>.
+			names = append(names, key.KID[strings.LastIndex(key.KID, "/")+1:])
+		}
+		if !fn(names) {
+			return nil
+		}
+		next = ""
+		if page.NextLink != nil {
+			next = *page.NextLink
+		}
+	}
+	return nil
+}
+
+func (c *managedHSMClient) get(ctx context.Context, u string, res interface{}) error {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, u)
+	if err != nil {
+		return err
+	}
+	req.Raw().Header.Set("Accept", "application/json")
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, res)
+}
+
+// formatKey returns the key as a JSON Web Key, or as a PEM encoded public key.
+func formatKey(key json.RawMessage, format string) (string, error) {
+	switch format {
+	case "", keyFormatJWK:
+		return string(key), nil
+	case keyFormatPEM:
+		// The key types of the HSM keys are suffixed with -HSM: RSA-HSM, EC-HSM, oct-HSM.
+		var fields map[string]interface{}
+		if err := json.Unmarshal(key, &fields); err != nil {
+			return "", fmt.Errorf("invalid key: %w", err)
+		}
+		kty, _ := fields["kty"].(string)
+		fields["kty"] = strings.TrimSuffix(kty, "-HSM")
+		if fields["kty"] == "oct" {
+			return "", errors.New("symmetric keys have no public key")
+		}
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return "", err
+		}
+		parsed, err := jwk.ParseKey(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid key: %w", err)
+		}
+		public, err := jwk.PublicRawKeyOf(parsed)
+		if err != nil {
+			return "", fmt.Errorf("invalid key: %w", err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(public)
+		if err != nil {
+			return "", fmt.Errorf("invalid key: %w", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+	default:
+		return "", fmt.Errorf("invalid %s %q: must be %s or %s", KeyFormat, format, keyFormatJWK, keyFormatPEM)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstores
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dapr/components-contrib/internal/component/bulkhead"
+)
+
+// bulkheadSecretStore limits the number of requests to a secret store running at the same time.
+type bulkheadSecretStore struct {
+	SecretStore
+
+	bulkhead *bulkhead.Bulkhead
+}
+
+type bulkheadSecretSetterStore struct {
+	*bulkheadSecretStore
+
+	setter SecretSetter
+}
+
+// NewBulkheadSecretStore wraps a SecretStore so that at most "maxConcurrentOperations" of its requests run at the same time.
+// The other ones wait for a free slot for up to "maxConcurrentOperationsWait", then fail with bulkhead.ErrBulkheadFull.
+// The wrapper keeps implementing SecretSetter if the inner store does.
+func NewBulkheadSecretStore(inner SecretStore) SecretStore {
+	s := &bulkheadSecretStore{SecretStore: inner}
+	if setter, ok := inner.(SecretSetter); ok {
+		return &bulkheadSecretSetterStore{bulkheadSecretStore: s, setter: setter}
+	}
+	return s
+}
+
+func (s *bulkheadSecretStore) Init(metadata Metadata) error {
+	m, err := bulkhead.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("secret store bulkhead error: %w", err)
+	}
+	s.bulkhead = bulkhead.New(m)
+
+	return s.SecretStore.Init(metadata)
+}
+
+func (s *bulkheadSecretStore) GetSecret(ctx context.Context, req GetSecretRequest) (GetSecretResponse, error) {
+	return bulkhead.Run(ctx, s.bulkhead, func(ctx context.Context) (GetSecretResponse, error) {
+		return s.SecretStore.GetSecret(ctx, req)
+	})
+}
+
+func (s *bulkheadSecretStore) BulkGetSecret(ctx context.Context, req BulkGetSecretRequest) (BulkGetSecretResponse, error) {
+	return bulkhead.Run(ctx, s.bulkhead, func(ctx context.Context) (BulkGetSecretResponse, error) {
+		return s.SecretStore.BulkGetSecret(ctx, req)
+	})
+}
+
+func (s *bulkheadSecretStore) Close() error {
+	if closer, ok := s.SecretStore.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *bulkheadSecretSetterStore) SetSecret(ctx context.Context, req SetSecretRequest) error {
+	return bulkhead.RunOnce(ctx, s.bulkhead, func(ctx context.Context) error {
+		return s.setter.SetSecret(ctx, req)
+	})
+}
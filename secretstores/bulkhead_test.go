@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstores
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/internal/component/bulkhead"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+type blockingSecretStore struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSecretStore) Init(metadata Metadata) error {
+	return nil
+}
+
+func (s *blockingSecretStore) GetSecret(ctx context.Context, req GetSecretRequest) (GetSecretResponse, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return GetSecretResponse{Data: map[string]string{req.Name: "value"}}, nil
+}
+
+func (s *blockingSecretStore) BulkGetSecret(ctx context.Context, req BulkGetSecretRequest) (BulkGetSecretResponse, error) {
+	return BulkGetSecretResponse{}, nil
+}
+
+func (s *blockingSecretStore) Features() []Feature {
+	return nil
+}
+
+func (s *blockingSecretStore) GetComponentMetadata() map[string]string {
+	return nil
+}
+
+type settableSecretStore struct {
+	blockingSecretStore
+}
+
+func (s *settableSecretStore) SetSecret(ctx context.Context, req SetSecretRequest) error {
+	return nil
+}
+
+func TestBulkheadSecretStore(t *testing.T) {
+	props := map[string]string{
+		"maxConcurrentOperations":     "1",
+		"maxConcurrentOperationsWait": "10ms",
+	}
+
+	t.Run("limits concurrent requests", func(t *testing.T) {
+		inner := &blockingSecretStore{started: make(chan struct{}, 1), release: make(chan struct{})}
+		s := NewBulkheadSecretStore(inner)
+		require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := s.GetSecret(context.Background(), GetSecretRequest{Name: "a"})
+			done <- err
+		}()
+		<-inner.started
+
+		_, err := s.BulkGetSecret(context.Background(), BulkGetSecretRequest{})
+		require.ErrorIs(t, err, bulkhead.ErrBulkheadFull)
+
+		close(inner.release)
+		require.NoError(t, <-done)
+
+		_, err = s.BulkGetSecret(context.Background(), BulkGetSecretRequest{})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		s := NewBulkheadSecretStore(&blockingSecretStore{})
+		err := s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{"maxConcurrentOperationsWait": "-1s"}}})
+		assert.ErrorContains(t, err, "secret store bulkhead error")
+	})
+
+	t.Run("keeps the secret setter of the inner store", func(t *testing.T) {
+		_, ok := NewBulkheadSecretStore(&settableSecretStore{}).(SecretSetter)
+		assert.True(t, ok)
+		_, ok = NewBulkheadSecretStore(&blockingSecretStore{}).(SecretSetter)
+		assert.False(t, ok)
+	})
+}
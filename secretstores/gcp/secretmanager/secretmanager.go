@@ -17,6 +17,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"reflect"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
@@ -44,6 +45,8 @@ type GcpSecretManagerMetadata struct {
 	TokenURI            string `mapstructure:"token_uri" json:"token_uri"`
 	AuthProviderCertURL string `mapstructure:"auth_provider_x509_cert_url" json:"auth_provider_x509_cert_url"`
 	ClientCertURL       string `mapstructure:"client_x509_cert_url" json:"client_x509_cert_url"`
+	// PinVersion is the version, or the alias, of the secrets read without a version. It isn't part of the credentials.
+	PinVersion string `mapstructure:"pinVersion" json:"-"`
 }
 
 type gcpSecretemanagerClient interface {
@@ -58,6 +61,7 @@ var _ secretstores.SecretStore = (*Store)(nil)
 type Store struct {
 	client    gcpSecretemanagerClient
 	ProjectID string
+	pins      secretstores.VersionPins
 
 	logger logger.Logger
 }
@@ -74,6 +78,11 @@ func (s *Store) Init(metadataRaw secretstores.Metadata) error {
 		return err
 	}
 
+	s.pins, err = secretstores.ParseVersionPins(metadata.PinVersion)
+	if err != nil {
+		return err
+	}
+
 	client, err := s.getClient(metadata)
 	if err != nil {
 		return fmt.Errorf("failed to setup secretmanager client: %s", err)
@@ -111,12 +120,7 @@ func (s *Store) GetSecret(ctx context.Context, req secretstores.GetSecretRequest
 	}
 	secretName := fmt.Sprintf("projects/%s/secrets/%s", s.ProjectID, req.Name)
 
-	versionID := "latest"
-	if value, ok := req.Metadata[VersionID]; ok {
-		versionID = value
-	}
-
-	secret, err := s.getSecret(ctx, secretName, versionID)
+	secret, err := s.getSecret(ctx, secretName, s.version(req.Name, req.Metadata))
 	if err != nil {
 		return res, fmt.Errorf("failed to access secret version: %v", err)
 	}
@@ -126,8 +130,6 @@ func (s *Store) GetSecret(ctx context.Context, req secretstores.GetSecretRequest
 
 // BulkGetSecret retrieves all secrets in the store and returns a map of decrypted string/string values.
 func (s *Store) BulkGetSecret(ctx context.Context, req secretstores.BulkGetSecretRequest) (secretstores.BulkGetSecretResponse, error) {
-	versionID := "latest"
-
 	response := map[string]map[string]string{}
 
 	if s.client == nil {
@@ -151,7 +153,7 @@ func (s *Store) BulkGetSecret(ctx context.Context, req secretstores.BulkGetSecre
 		}
 
 		name := resp.GetName()
-		secret, err := s.getSecret(ctx, name, versionID)
+		secret, err := s.getSecret(ctx, name, s.version(path.Base(name), nil))
 		if err != nil {
 			return secretstores.BulkGetSecretResponse{Data: nil}, fmt.Errorf("failed to access secret version: %v", err)
 		}
@@ -161,6 +163,19 @@ func (s *Store) BulkGetSecret(ctx context.Context, req secretstores.BulkGetSecre
 	return secretstores.BulkGetSecretResponse{Data: response}, nil
 }
 
+// version returns the version or the alias to read, either requested or pinned, "latest" by default.
+// Secret Manager aliases play the role of version stages.
+func (s *Store) version(name string, md map[string]string) string {
+	version := secretstores.RequestedVersionStage(md)
+	if version == "" {
+		version = s.pins.Version(name, md, VersionID)
+	}
+	if version == "" {
+		return "latest"
+	}
+	return version
+}
+
 func (s *Store) getSecret(ctx context.Context, secretName string, versionID string) (*string, error) {
 	accessRequest := &secretmanagerpb.AccessSecretVersionRequest{
 		Name: fmt.Sprintf("%s/versions/%s", secretName, versionID),
@@ -31,6 +31,7 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"golang.org/x/net/http2"
 
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/secretstores"
 	"github.com/dapr/kit/logger"
@@ -83,6 +84,7 @@ type vaultSecretStore struct {
 	vaultKVPrefix       string
 	vaultEnginePath     string
 	vaultValueType      valueType
+	pins                secretstores.VersionPins
 
 	json jsoniter.API
 
@@ -102,6 +104,8 @@ type VaultMetadata struct {
 	VaultTokenMountPath string
 	EnginePath          string
 	VaultValueType      string
+	// PinVersion is the KV version of the secrets read without a version.
+	PinVersion string
 }
 
 // tlsConfig is TLS configuration to interact with HashiCorp Vault.
@@ -170,12 +174,20 @@ func (v *vaultSecretStore) Init(meta secretstores.Metadata) error {
 		}
 	}
 
+	v.pins, err = secretstores.ParseVersionPins(m.PinVersion)
+	if err != nil {
+		return err
+	}
+
 	v.vaultToken = m.VaultToken
 	v.vaultTokenMountPath = m.VaultTokenMountPath
 	initErr := v.initVaultToken()
 	if initErr != nil {
 		return initErr
 	}
+	rl := redact.NewLogger(v.logger)
+	rl.AddSecrets(v.vaultToken)
+	v.logger = rl
 
 	vaultKVPrefix := m.VaultKVPrefix
 	if !m.VaultKVUsePrefix {
@@ -240,7 +252,7 @@ func (v *vaultSecretStore) getSecret(ctx context.Context, secret, version string
 	if httpresp.StatusCode != http.StatusOK {
 		var b bytes.Buffer
 		io.Copy(&b, httpresp.Body)
-		v.logger.Debugf("getSecret %s couldn't get successful response: %#v, %s", secret, httpresp, b.String())
+		v.logger.Debugf("getSecret %s couldn't get successful response: %s, %s", secret, httpresp.Status, b.String())
 		if httpresp.StatusCode == http.StatusNotFound {
 			// handle not found error
 			return nil, fmt.Errorf("getSecret %s failed %w", secret, ErrNotFound)
@@ -274,12 +286,7 @@ func (v *vaultSecretStore) getSecret(ctx context.Context, secret, version string
 
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values.
 func (v *vaultSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
-	// version 0 represent for latest version
-	version := "0"
-	if value, ok := req.Metadata[versionID]; ok {
-		version = value
-	}
-	d, err := v.getSecret(ctx, req.Name, version)
+	d, err := v.getSecret(ctx, req.Name, v.version(req.Name, req.Metadata))
 	if err != nil {
 		return secretstores.GetSecretResponse{Data: nil}, err
 	}
@@ -291,13 +298,16 @@ func (v *vaultSecretStore) GetSecret(ctx context.Context, req secretstores.GetSe
 	return resp, nil
 }
 
-// BulkGetSecret retrieves all secrets in the store and returns a map of decrypted string/string values.
-func (v *vaultSecretStore) BulkGetSecret(ctx context.Context, req secretstores.BulkGetSecretRequest) (secretstores.BulkGetSecretResponse, error) {
-	version := "0"
-	if value, ok := req.Metadata[versionID]; ok {
-		version = value
+// version returns the version to read, either requested or pinned. Version 0 is the latest version.
+func (v *vaultSecretStore) version(name string, md map[string]string) string {
+	if version := v.pins.Version(name, md, versionID); version != "" {
+		return version
 	}
+	return "0"
+}
 
+// BulkGetSecret retrieves all secrets in the store and returns a map of decrypted string/string values.
+func (v *vaultSecretStore) BulkGetSecret(ctx context.Context, req secretstores.BulkGetSecretRequest) (secretstores.BulkGetSecretResponse, error) {
 	resp := secretstores.BulkGetSecretResponse{
 		Data: map[string]map[string]string{},
 	}
@@ -309,7 +319,7 @@ func (v *vaultSecretStore) BulkGetSecret(ctx context.Context, req secretstores.B
 
 	for _, key := range keys {
 		keyValues := map[string]string{}
-		secrets, err := v.getSecret(ctx, key, version)
+		secrets, err := v.getSecret(ctx, key, v.version(key, req.Metadata))
 		if err != nil {
 			if errors.Is(err, ErrNotFound) {
 				// version not exist skip
@@ -328,6 +338,46 @@ func (v *vaultSecretStore) BulkGetSecret(ctx context.Context, req secretstores.B
 	return resp, nil
 }
 
+// SetSecret writes a new version of the secret with the values.
+func (v *vaultSecretStore) SetSecret(ctx context.Context, req secretstores.SetSecretRequest) error {
+	if !v.vaultValueType.isMapType() {
+		return errors.New("setting secrets requires the map vaultValueType")
+	}
+
+	var vaultSecretPathAddr string
+	if v.vaultKVPrefix == "" {
+		vaultSecretPathAddr = fmt.Sprintf("%s/v1/%s/data/%s", v.vaultAddress, v.vaultEnginePath, req.Name)
+	} else {
+		vaultSecretPathAddr = fmt.Sprintf("%s/v1/%s/data/%s/%s", v.vaultAddress, v.vaultEnginePath, v.vaultKVPrefix, req.Name)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"data": req.Data})
+	if err != nil {
+		return fmt.Errorf("couldn't encode secret: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, vaultSecretPathAddr, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("couldn't generate request: %w", err)
+	}
+	httpReq.Header.Set(vaultHTTPHeader, v.vaultToken)
+	httpReq.Header.Set(vaultHTTPRequestHeader, "true")
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpresp, err := v.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("couldn't set secret: %w", err)
+	}
+	defer httpresp.Body.Close()
+
+	if httpresp.StatusCode != http.StatusOK && httpresp.StatusCode != http.StatusNoContent {
+		var b bytes.Buffer
+		io.Copy(&b, httpresp.Body)
+		return fmt.Errorf("couldn't get successful response, status code %d, body %s",
+			httpresp.StatusCode, b.String())
+	}
+	return nil
+}
+
 // listKeysUnderPath get all the keys recursively under a given path.(returned keys including path as prefix)
 // path should not has `/` prefix.
 func (v *vaultSecretStore) listKeysUnderPath(ctx context.Context, path string) ([]string, error) {
@@ -358,7 +408,7 @@ func (v *vaultSecretStore) listKeysUnderPath(ctx context.Context, path string) (
 	if httpresp.StatusCode != http.StatusOK {
 		var b bytes.Buffer
 		io.Copy(&b, httpresp.Body)
-		v.logger.Debugf("list keys couldn't get successful response: %#v, %s", httpresp, b.String())
+		v.logger.Debugf("list keys couldn't get successful response: %s, %s", httpresp.Status, b.String())
 
 		return nil, fmt.Errorf("list keys couldn't get successful response, status code: %d, status: %s, response %s",
 			httpresp.StatusCode, httpresp.Status, b.String())
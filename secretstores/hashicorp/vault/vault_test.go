@@ -14,7 +14,11 @@ limitations under the License.
 package vault
 
 import (
+	"context"
 	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"testing"
@@ -454,3 +458,39 @@ func TestGetFeatures(t *testing.T) {
 		assert.False(t, secretstores.FeatureMultipleKeyValuesPerSecret.IsPresent(f))
 	})
 }
+
+func TestSetSecret(t *testing.T) {
+	var (
+		path string
+		body map[string]map[string]string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, expectedTok, r.Header.Get(vaultHTTPHeader))
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"data":{"version":2}}`))
+	}))
+	defer srv.Close()
+
+	v := &vaultSecretStore{
+		client:          srv.Client(),
+		vaultAddress:    srv.URL,
+		vaultToken:      expectedTok,
+		vaultEnginePath: defaultVaultEnginePath,
+		vaultKVPrefix:   defaultVaultKVPrefix,
+		vaultValueType:  valueTypeMap,
+	}
+
+	err := v.SetSecret(context.Background(), secretstores.SetSecretRequest{
+		Name: "cert",
+		Data: map[string]string{"tls.crt": "crt", "tls.key": "key"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/secret/data/dapr/cert", path)
+	assert.Equal(t, map[string]string{"tls.crt": "crt", "tls.key": "key"}, body["data"])
+
+	v.vaultValueType = valueTypeText
+	err = v.SetSecret(context.Background(), secretstores.SetSecretRequest{Name: "cert"})
+	assert.Error(t, err)
+}
@@ -42,6 +42,7 @@ var _ secretstores.SecretStore = (*csmsSecretStore)(nil)
 
 type csmsSecretStore struct {
 	client csmsClient
+	pins   secretstores.VersionPins
 	logger logger.Logger
 }
 
@@ -49,6 +50,7 @@ type CsmsSecretStoreMetadata struct {
 	Region          string
 	AccessKey       string
 	SecretAccessKey string
+	PinVersion      string
 }
 
 // NewHuaweiCsmsSecretStore returns a new Huawei csms secret store.
@@ -60,6 +62,12 @@ func NewHuaweiCsmsSecretStore(logger logger.Logger) secretstores.SecretStore {
 func (c *csmsSecretStore) Init(meta secretstores.Metadata) error {
 	m := CsmsSecretStoreMetadata{}
 	metadata.DecodeMetadata(meta.Properties, &m)
+	pins, err := secretstores.ParseVersionPins(m.PinVersion)
+	if err != nil {
+		return err
+	}
+	c.pins = pins
+
 	auth := basic.NewCredentialsBuilder().
 		WithAk(m.AccessKey).
 		WithSk(m.SecretAccessKey).
@@ -78,7 +86,7 @@ func (c *csmsSecretStore) Init(meta secretstores.Metadata) error {
 func (c *csmsSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
 	request := &model.ShowSecretVersionRequest{}
 	request.SecretName = req.Name
-	if value, ok := req.Metadata[versionID]; ok {
+	if value := c.pins.Version(req.Name, req.Metadata, versionID); value != "" {
 		request.VersionId = value
 	}
 
@@ -106,10 +114,14 @@ func (c *csmsSecretStore) BulkGetSecret(ctx context.Context, req secretstores.Bu
 	}
 
 	for _, secretName := range secretNames {
+		version := c.pins.Pinned(secretName)
+		if version == "" {
+			version = latestVersion
+		}
 		secret, err := c.GetSecret(ctx, secretstores.GetSecretRequest{
 			Name: secretName,
 			Metadata: map[string]string{
-				versionID: latestVersion,
+				versionID: version,
 			},
 		})
 		if err != nil {
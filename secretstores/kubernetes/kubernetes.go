@@ -20,6 +20,8 @@ import (
 	"os"
 	"reflect"
 
+	core_v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -29,7 +31,10 @@ import (
 	"github.com/dapr/kit/logger"
 )
 
-var _ secretstores.SecretStore = (*kubernetesSecretStore)(nil)
+var (
+	_ secretstores.SecretStore  = (*kubernetesSecretStore)(nil)
+	_ secretstores.SecretSetter = (*kubernetesSecretStore)(nil)
+)
 
 type kubernetesSecretStore struct {
 	kubeClient kubernetes.Interface
@@ -99,6 +104,42 @@ func (k *kubernetesSecretStore) BulkGetSecret(ctx context.Context, req secretsto
 	return resp, nil
 }
 
+// SetSecret creates the secret, or replaces its data if it exists.
+// The "type" metadata sets the type of the created secrets, such as kubernetes.io/tls, Opaque by default.
+func (k *kubernetesSecretStore) SetSecret(ctx context.Context, req secretstores.SetSecretRequest) error {
+	namespace, err := k.getNamespaceFromMetadata(req.Metadata)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string][]byte, len(req.Data))
+	for k, v := range req.Data {
+		data[k] = []byte(v)
+	}
+
+	secrets := k.kubeClient.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(ctx, req.Name, meta_v1.GetOptions{}) //nolint:nosnakecase
+	if k8serrors.IsNotFound(err) {
+		secret = &core_v1.Secret{ //nolint:nosnakecase
+			ObjectMeta: meta_v1.ObjectMeta{Name: req.Name, Namespace: namespace}, //nolint:nosnakecase
+			Type:       core_v1.SecretTypeOpaque,                                 //nolint:nosnakecase
+			Data:       data,
+		}
+		if t := req.Metadata["type"]; t != "" {
+			secret.Type = core_v1.SecretType(t) //nolint:nosnakecase
+		}
+		_, err = secrets.Create(ctx, secret, meta_v1.CreateOptions{}) //nolint:nosnakecase
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	secret.Data = data
+	_, err = secrets.Update(ctx, secret, meta_v1.UpdateOptions{}) //nolint:nosnakecase
+	return err
+}
+
 func (k *kubernetesSecretStore) getNamespaceFromMetadata(metadata map[string]string) (string, error) {
 	if val, ok := metadata["namespace"]; ok && val != "" {
 		return val, nil
@@ -13,12 +13,19 @@ limitations under the License.
 
 package kubernetes
 
+//nolint:nosnakecase
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 
+	"github.com/dapr/components-contrib/secretstores"
 	"github.com/dapr/kit/logger"
 )
 
@@ -59,3 +66,32 @@ func TestGetFeatures(t *testing.T) {
 		assert.Empty(t, f)
 	})
 }
+
+func TestSetSecret(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := kubernetesSecretStore{kubeClient: client, logger: logger.NewLogger("test")}
+	ctx := context.Background()
+
+	err := store.SetSecret(ctx, secretstores.SetSecretRequest{
+		Name:     "cert",
+		Data:     map[string]string{"tls.crt": "crt1", "tls.key": "key1"},
+		Metadata: map[string]string{"namespace": "a", "type": "kubernetes.io/tls"},
+	})
+	require.NoError(t, err)
+
+	err = store.SetSecret(ctx, secretstores.SetSecretRequest{
+		Name:     "cert",
+		Data:     map[string]string{"tls.crt": "crt2", "tls.key": "key2"},
+		Metadata: map[string]string{"namespace": "a"},
+	})
+	require.NoError(t, err)
+
+	secret, err := client.CoreV1().Secrets("a").Get(ctx, "cert", meta_v1.GetOptions{}) //nolint:nosnakecase
+	require.NoError(t, err)
+	assert.Equal(t, core_v1.SecretTypeTLS, secret.Type) //nolint:nosnakecase
+	assert.Equal(t, []byte("crt2"), secret.Data["tls.crt"])
+
+	res, err := store.GetSecret(ctx, secretstores.GetSecretRequest{Name: "cert", Metadata: map[string]string{"namespace": "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tls.crt": "crt2", "tls.key": "key2"}, res.Data)
+}
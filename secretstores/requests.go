@@ -23,3 +23,10 @@ type GetSecretRequest struct {
 type BulkGetSecretRequest struct {
 	Metadata map[string]string `json:"metadata"`
 }
+
+// SetSecretRequest describes a set secret request to a secret store.
+type SetSecretRequest struct {
+	Name     string            `json:"name"`
+	Data     map[string]string `json:"data"`
+	Metadata map[string]string `json:"metadata"`
+}
@@ -34,6 +34,13 @@ type SecretStore interface {
 	GetComponentMetadata() map[string]string
 }
 
+// SecretSetter is implemented by the secret stores which can create or replace secrets,
+// so that other components can persist the secrets they generate.
+type SecretSetter interface {
+	// SetSecret creates the secret, or replaces its values if it exists.
+	SetSecret(ctx context.Context, req SetSecretRequest) error
+}
+
 func Ping(secretStore SecretStore) error {
 	// checks if this secretStore has the ping option then executes
 	if secretStoreWithPing, ok := secretStore.(health.Pinger); ok {
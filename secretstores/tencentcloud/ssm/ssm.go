@@ -51,6 +51,7 @@ type ssmClient interface {
 
 type ssmSecretStore struct {
 	client ssmClient
+	pins   secretstores.VersionPins
 	logger logger.Logger
 }
 
@@ -59,6 +60,8 @@ type SsmMetadata struct {
 	SecretKey string
 	Token     string
 	Region    string
+	// PinVersion is the version of the secrets read without a VersionID.
+	PinVersion string
 }
 
 // NewSSM returns a new TencentCloud ssm secret store.
@@ -80,6 +83,11 @@ func (s *ssmSecretStore) Init(meta secretstores.Metadata) error {
 		return errors.New("secret params are empty")
 	}
 
+	s.pins, err = secretstores.ParseVersionPins(m.PinVersion)
+	if err != nil {
+		return err
+	}
+
 	credential := common.NewTokenCredential(m.SecretID, m.SecretKey, m.Token)
 	s.client, err = ssm.NewClient(credential, m.Region, profile.NewClientProfile())
 	if err != nil {
@@ -98,7 +106,7 @@ func (s *ssmSecretStore) GetSecret(ctx context.Context, req secretstores.GetSecr
 		return response, errors.New("secret name is empty")
 	}
 
-	versionID := req.Metadata[VersionID]
+	versionID := s.pins.Version(req.Name, req.Metadata, VersionID)
 	ssmReq := ssm.NewGetSecretValueRequest()
 	ssmReq.SecretName = &req.Name
 	ssmReq.VersionId = &versionID
@@ -130,7 +138,7 @@ func (s *ssmSecretStore) BulkGetSecret(ctx context.Context, req secretstores.Bul
 	response := secretstores.BulkGetSecretResponse{
 		Data: map[string]map[string]string{},
 	}
-	versionID := req.Metadata[VersionID]
+	versionID := secretstores.RequestedVersion(req.Metadata, VersionID)
 
 	var start uint64 = 0
 	names, err := s.getSecretNames(ctx, &start)
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstores
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// VersionMetadataKey is the request metadata selecting the version of a secret,
+	// for the secret stores supporting versions.
+	VersionMetadataKey = "version"
+	// VersionStageMetadataKey is the request metadata selecting the version of a secret by its stage label,
+	// for the secret stores supporting stages.
+	VersionStageMetadataKey = "versionStage"
+	// PinVersionMetadataKey is the component metadata pinning the version of the secrets read without
+	// an explicit version: either one version for all the secrets, or comma-separated name=version pairs.
+	PinVersionMetadataKey = "pinVersion"
+)
+
+// VersionPins are the versions pinned by the pinVersion metadata of a secret store.
+type VersionPins struct {
+	all     string
+	secrets map[string]string
+}
+
+// ParseVersionPins parses the value of the pinVersion metadata, e.g. "3" or "db-password=3,api-key=7".
+func ParseVersionPins(val string) (VersionPins, error) {
+	val = strings.TrimSpace(val)
+	if !strings.Contains(val, "=") {
+		return VersionPins{all: val}, nil
+	}
+
+	pins := VersionPins{secrets: map[string]string{}}
+	for _, pair := range strings.Split(val, ",") {
+		name, version, ok := strings.Cut(pair, "=")
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		if !ok || name == "" || version == "" {
+			return VersionPins{}, fmt.Errorf("invalid metadata '%s': %q is not a name=version pair", PinVersionMetadataKey, pair)
+		}
+		pins.secrets[name] = version
+	}
+	return pins, nil
+}
+
+// Pinned returns the version pinned for the secret, or an empty string.
+func (p VersionPins) Pinned(name string) string {
+	if v, ok := p.secrets[name]; ok {
+		return v
+	}
+	return p.all
+}
+
+// Version returns the version of the secret to read: the version of the request, set with the "version" metadata
+// or one of the legacy keys of the store, otherwise the version pinned for the secret. It returns an empty string
+// for the current version.
+func (p VersionPins) Version(name string, requestMetadata map[string]string, legacyKeys ...string) string {
+	if v := RequestedVersion(requestMetadata, legacyKeys...); v != "" {
+		return v
+	}
+	return p.Pinned(name)
+}
+
+// RequestedVersion returns the version set in the request metadata with the "version" key or one of the legacy keys.
+func RequestedVersion(requestMetadata map[string]string, legacyKeys ...string) string {
+	return firstMetadataValue(requestMetadata, VersionMetadataKey, legacyKeys)
+}
+
+// RequestedVersionStage returns the stage set in the request metadata with the "versionStage" key or one of the legacy keys.
+func RequestedVersionStage(requestMetadata map[string]string, legacyKeys ...string) string {
+	return firstMetadataValue(requestMetadata, VersionStageMetadataKey, legacyKeys)
+}
+
+func firstMetadataValue(md map[string]string, key string, legacyKeys []string) string {
+	if v := md[key]; v != "" {
+		return v
+	}
+	for _, k := range legacyKeys {
+		if v := md[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstores
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersionPins(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		pins, err := ParseVersionPins("")
+		require.NoError(t, err)
+		assert.Empty(t, pins.Pinned("secret"))
+	})
+
+	t.Run("one version for all the secrets", func(t *testing.T) {
+		pins, err := ParseVersionPins(" 3 ")
+		require.NoError(t, err)
+		assert.Equal(t, "3", pins.Pinned("secret"))
+		assert.Equal(t, "3", pins.Pinned("other"))
+	})
+
+	t.Run("versions per secret", func(t *testing.T) {
+		pins, err := ParseVersionPins("db-password=3, api-key = 7")
+		require.NoError(t, err)
+		assert.Equal(t, "3", pins.Pinned("db-password"))
+		assert.Equal(t, "7", pins.Pinned("api-key"))
+		assert.Empty(t, pins.Pinned("other"))
+	})
+
+	t.Run("invalid pairs", func(t *testing.T) {
+		for _, val := range []string{"db-password=3,api-key", "=3", "db-password="} {
+			_, err := ParseVersionPins(val)
+			assert.Error(t, err, val)
+		}
+	})
+}
+
+func TestVersionPinsVersion(t *testing.T) {
+	pins, err := ParseVersionPins("db-password=3")
+	require.NoError(t, err)
+
+	assert.Equal(t, "3", pins.Version("db-password", nil))
+	assert.Equal(t, "5", pins.Version("db-password", map[string]string{VersionMetadataKey: "5"}))
+	assert.Equal(t, "4", pins.Version("db-password", map[string]string{"version_id": "4"}, "version_id"))
+	assert.Equal(t, "5", pins.Version("db-password", map[string]string{VersionMetadataKey: "5", "version_id": "4"}, "version_id"))
+	assert.Empty(t, pins.Version("other", map[string]string{}))
+}
+
+func TestRequestedVersionStage(t *testing.T) {
+	assert.Equal(t, "AWSPREVIOUS", RequestedVersionStage(map[string]string{VersionStageMetadataKey: "AWSPREVIOUS"}, "version_stage"))
+	assert.Equal(t, "AWSCURRENT", RequestedVersionStage(map[string]string{"version_stage": "AWSCURRENT"}, "version_stage"))
+	assert.Empty(t, RequestedVersionStage(nil))
+}
@@ -18,9 +18,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	as "github.com/aerospike/aerospike-client-go"
 	"github.com/aerospike/aerospike-client-go/types"
@@ -28,6 +31,7 @@ import (
 
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
+	stateutils "github.com/dapr/components-contrib/state/utils"
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/ptr"
 )
@@ -36,11 +40,22 @@ type aerospikeMetadata struct {
 	Hosts     string
 	Namespace string
 	Set       string // optional
+	// Number of connections opened to each node on Init; 0 disables the warm-up.
+	WarmUpConnections int
+	// Log nodes joining or leaving the cluster and partition map changes.
+	LogClusterEvents bool
 }
 
+// Keys of the record metadata returned by Get.
+const (
+	metadataGeneration = "generation"
+	metadataExpiration = "expiration"
+)
+
 var (
-	errMissingHosts = errors.New("aerospike: value for 'hosts' missing")
-	errInvalidHosts = errors.New("aerospike: invalid value for hosts")
+	errMissingHosts  = errors.New("aerospike: value for 'hosts' missing")
+	errInvalidHosts  = errors.New("aerospike: invalid value for hosts")
+	errInvalidWarmUp = errors.New("aerospike: value for 'warmUpConnections' must not be negative")
 )
 
 // Aerospike is a state store.
@@ -53,6 +68,9 @@ type Aerospike struct {
 
 	features []state.Feature
 	logger   logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewAerospikeStateStore returns a new Aerospike state store.
@@ -80,6 +98,9 @@ func parseAndValidateMetadata(meta state.Metadata) (*aerospikeMetadata, error) {
 	if m.Namespace == "" {
 		return nil, errMissingHosts
 	}
+	if m.WarmUpConnections < 0 {
+		return nil, errInvalidWarmUp
+	}
 
 	// format is host1:port1,host2:port2
 	_, err := parseHosts(m.Hosts)
@@ -108,6 +129,36 @@ func (aspike *Aerospike) Init(metadata state.Metadata) error {
 	aspike.namespace = m.Namespace
 	aspike.set = m.Set
 
+	if m.WarmUpConnections > 0 {
+		// A failed warm-up only costs latency on the first requests, so it doesn't fail Init.
+		n, err := c.WarmUp(m.WarmUpConnections)
+		if err != nil {
+			aspike.logger.Warnf("aerospike: connection warm-up failed after %d connections: %v", n, err)
+		} else {
+			aspike.logger.Infof("aerospike: warmed up %d connections", n)
+		}
+	}
+
+	if m.LogClusterEvents {
+		var ctx context.Context
+		ctx, aspike.cancel = context.WithCancel(context.Background())
+		aspike.wg.Add(1)
+		go aspike.watchCluster(ctx, c.Cluster().ClientPolicy().TendInterval)
+	}
+
+	return nil
+}
+
+// Close stops the cluster watcher and closes the connections to the cluster.
+func (aspike *Aerospike) Close() error {
+	if aspike.cancel != nil {
+		aspike.cancel()
+		aspike.wg.Wait()
+	}
+	if aspike.client != nil {
+		aspike.client.Close()
+	}
+
 	return nil
 }
 
@@ -170,6 +221,11 @@ func (aspike *Aerospike) Set(ctx context.Context, req *state.SetRequest) error {
 
 // Get retrieves state from Aerospike with a key.
 func (aspike *Aerospike) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	fields, err := stateutils.ParseFields(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
 	asKey, err := as.NewKey(aspike.namespace, aspike.set, req.Key)
 	if err != nil {
 		return nil, err
@@ -183,7 +239,7 @@ func (aspike *Aerospike) Get(ctx context.Context, req *state.GetRequest) (*state
 		policy.ReadModeAP = as.ReadModeAPOne
 		policy.ReadModeSC = as.ReadModeSCSession
 	}
-	record, err := aspike.client.Get(policy, asKey)
+	record, err := aspike.client.Get(policy, asKey, fieldBins(fields)...)
 	if err != nil {
 		if err == types.ErrKeyNotFound {
 			return &state.GetResponse{}, nil
@@ -195,13 +251,42 @@ func (aspike *Aerospike) Get(ctx context.Context, req *state.GetRequest) (*state
 	if err != nil {
 		return nil, err
 	}
+	if len(fields) > 0 {
+		// Only top-level fields map to bins: nested paths are projected from the bins that were read.
+		value, err = stateutils.ProjectJSON(value, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	meta := map[string]string{
+		metadataGeneration: strconv.FormatUint(uint64(record.Generation), 10),
+	}
+	if record.Expiration != math.MaxUint32 {
+		meta[metadataExpiration] = strconv.FormatUint(uint64(record.Expiration), 10)
+		meta[state.GetResponseMetadataExpireTime] = time.Now().Add(time.Duration(record.Expiration) * time.Second).UTC().Format(time.RFC3339)
+	}
 
 	return &state.GetResponse{
-		Data: value,
-		ETag: ptr.Of(strconv.FormatUint(uint64(record.Generation), 10)),
+		Data:     value,
+		ETag:     ptr.Of(strconv.FormatUint(uint64(record.Generation), 10)),
+		Metadata: meta,
 	}, nil
 }
 
+// fieldBins returns the distinct names of the bins holding the given fields.
+func fieldBins(fields [][]string) []string {
+	var bins []string
+	seen := map[string]struct{}{}
+	for _, f := range fields {
+		if _, ok := seen[f[0]]; !ok {
+			seen[f[0]] = struct{}{}
+			bins = append(bins, f[0])
+		}
+	}
+	return bins
+}
+
 // Delete performs a delete operation.
 func (aspike *Aerospike) Delete(ctx context.Context, req *state.DeleteRequest) error {
 	err := state.CheckRequestOptions(req.Options)
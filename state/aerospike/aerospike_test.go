@@ -14,12 +14,14 @@ limitations under the License.
 package aerospike
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
+	"github.com/dapr/kit/logger"
 )
 
 const (
@@ -47,6 +49,12 @@ func TestValidateMetadataForValidInputs(t *testing.T) {
 			namespace: "foobarnamespace",
 			set:       "fooset",
 		}},
+		{"with warm-up and cluster events", map[string]string{
+			hosts:               "host1:1234",
+			namespace:           "foobarnamespace",
+			"warmUpConnections": "8",
+			"logClusterEvents":  "true",
+		}},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -81,6 +89,11 @@ func TestValidateMetadataForInvalidInputs(t *testing.T) {
 			hosts: "host1:1234",
 			set:   "fooset",
 		}},
+		{"With negative warm-up", map[string]string{
+			hosts:               "host1:1234",
+			namespace:           "foobarnamespace",
+			"warmUpConnections": "-1",
+		}},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -142,3 +155,35 @@ func TestConvertETag(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestFieldBins(t *testing.T) {
+	bins := fieldBins([][]string{{"name"}, {"address", "city"}, {"address", "zip"}})
+	assert.Equal(t, []string{"name", "address"}, bins)
+}
+
+func TestLogClusterChanges(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewLogger("aerospike.test")
+	l.SetOutput(&buf)
+	aspike := &Aerospike{logger: l}
+
+	prev := clusterSnapshot{
+		nodes:                map[string]string{"A": "host1:3000", "B": "host2:3000"},
+		partitionGenerations: map[string]string{"A": "10", "B": "4"},
+	}
+	cur := clusterSnapshot{
+		nodes:                map[string]string{"A": "host1:3000", "C": "host3:3000"},
+		partitionGenerations: map[string]string{"A": "11", "C": "1"},
+	}
+	aspike.logClusterChanges(prev, cur)
+
+	out := buf.String()
+	assert.Contains(t, out, "node C (host3:3000) added to the cluster")
+	assert.Contains(t, out, "node B (host2:3000) removed from the cluster")
+	assert.Contains(t, out, "partition map of node A changed (generation 10 -> 11)")
+	assert.NotContains(t, out, "node A (")
+
+	buf.Reset()
+	aspike.logClusterChanges(cur, cur)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aerospike
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go"
+)
+
+const partitionGenerationInfo = "partition-generation"
+
+// clusterSnapshot is the view of the cluster compared between two tend intervals.
+type clusterSnapshot struct {
+	// Host of each node, by node name.
+	nodes map[string]string
+	// Partition map generation of each node, by node name.
+	partitionGenerations map[string]string
+}
+
+func takeClusterSnapshot(nodes []*as.Node) clusterSnapshot {
+	snapshot := clusterSnapshot{
+		nodes:                make(map[string]string, len(nodes)),
+		partitionGenerations: make(map[string]string, len(nodes)),
+	}
+	policy := as.NewInfoPolicy()
+	for _, node := range nodes {
+		name := node.GetName()
+		snapshot.nodes[name] = node.GetHost().String()
+		info, err := node.RequestInfo(policy, partitionGenerationInfo)
+		if err == nil {
+			snapshot.partitionGenerations[name] = info[partitionGenerationInfo]
+		}
+	}
+
+	return snapshot
+}
+
+// watchCluster logs the changes of the cluster the client is connected to until ctx is canceled.
+// The client in this version has no cluster event API, so the cluster is polled at the tend interval.
+func (aspike *Aerospike) watchCluster(ctx context.Context, interval time.Duration) {
+	defer aspike.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := takeClusterSnapshot(aspike.client.GetNodes())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cur := takeClusterSnapshot(aspike.client.GetNodes())
+			aspike.logClusterChanges(prev, cur)
+			prev = cur
+		}
+	}
+}
+
+func (aspike *Aerospike) logClusterChanges(prev, cur clusterSnapshot) {
+	for _, name := range sortedKeys(cur.nodes) {
+		if _, ok := prev.nodes[name]; !ok {
+			aspike.logger.Infof("aerospike: node %s (%s) added to the cluster", name, cur.nodes[name])
+		}
+	}
+	for _, name := range sortedKeys(prev.nodes) {
+		if _, ok := cur.nodes[name]; !ok {
+			aspike.logger.Warnf("aerospike: node %s (%s) removed from the cluster", name, prev.nodes[name])
+		}
+	}
+	for _, name := range sortedKeys(cur.partitionGenerations) {
+		old, ok := prev.partitionGenerations[name]
+		if ok && old != cur.partitionGenerations[name] {
+			aspike.logger.Infof("aerospike: partition map of node %s changed (generation %s -> %s)", name, old, cur.partitionGenerations[name])
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
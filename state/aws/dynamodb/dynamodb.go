@@ -17,6 +17,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -43,19 +44,26 @@ type StateStore struct {
 }
 
 type dynamoDBMetadata struct {
-	Region           string `json:"region"`
-	Endpoint         string `json:"endpoint"`
-	AccessKey        string `json:"accessKey"`
-	SecretKey        string `json:"secretKey"`
-	SessionToken     string `json:"sessionToken"`
-	Table            string `json:"table"`
-	TTLAttributeName string `json:"ttlAttributeName"`
-	PartitionKey     string `json:"partitionKey"`
+	Region               string `json:"region"`
+	Endpoint             string `json:"endpoint"`
+	AccessKey            string `json:"accessKey"`
+	SecretKey            string `json:"secretKey"`
+	SessionToken         string `json:"sessionToken"`
+	AssumeRoleArn        string `json:"assumeRoleArn"`
+	ExternalID           string `json:"externalId"`
+	SessionName          string `json:"sessionName"`
+	WebIdentityTokenFile string `json:"webIdentityTokenFile"`
+	Table                string `json:"table"`
+	TTLAttributeName     string `json:"ttlAttributeName"`
+	PartitionKey         string `json:"partitionKey"`
 }
 
 const (
 	defaultPartitionKeyName = "key"
 	metadataPartitionKey    = "partitionKey"
+
+	// maxTransactionItems is the maximum number of actions DynamoDB allows in a single TransactWriteItems call.
+	maxTransactionItems = 100
 )
 
 // NewDynamoDBStateStore returns a new dynamoDB state store.
@@ -87,7 +95,7 @@ func (d *StateStore) Init(metadata state.Metadata) error {
 
 // Features returns the features available in this state store.
 func (d *StateStore) Features() []state.Feature {
-	return []state.Feature{state.FeatureETag}
+	return []state.Feature{state.FeatureETag, state.FeatureTransactional}
 }
 
 // Get retrieves a dynamoDB item.
@@ -161,24 +169,10 @@ func (d *StateStore) Set(ctx context.Context, req *state.SetRequest) error {
 		Item:      item,
 		TableName: &d.table,
 	}
-
-	haveEtag := false
-	if req.ETag != nil && *req.ETag != "" {
-		haveEtag = true
-		condExpr := "etag = :etag"
-		input.ConditionExpression = &condExpr
-		exprAttrValues := make(map[string]*dynamodb.AttributeValue)
-		exprAttrValues[":etag"] = &dynamodb.AttributeValue{
-			S: req.ETag,
-		}
-		input.ExpressionAttributeValues = exprAttrValues
-	} else if req.Options.Concurrency == state.FirstWrite {
-		condExpr := "attribute_not_exists(etag)"
-		input.ConditionExpression = &condExpr
-	}
+	input.ConditionExpression, input.ExpressionAttributeValues = setCondition(req)
 
 	_, err = d.client.PutItemWithContext(ctx, input)
-	if err != nil && haveEtag {
+	if err != nil && input.ExpressionAttributeValues != nil {
 		switch cErr := err.(type) {
 		case *dynamodb.ConditionalCheckFailedException:
 			err = state.NewETagError(state.ETagMismatch, cErr)
@@ -239,16 +233,7 @@ func (d *StateStore) Delete(ctx context.Context, req *state.DeleteRequest) error
 		},
 		TableName: aws.String(d.table),
 	}
-
-	if req.ETag != nil && *req.ETag != "" {
-		condExpr := "etag = :etag"
-		input.ConditionExpression = &condExpr
-		exprAttrValues := make(map[string]*dynamodb.AttributeValue)
-		exprAttrValues[":etag"] = &dynamodb.AttributeValue{
-			S: req.ETag,
-		}
-		input.ExpressionAttributeValues = exprAttrValues
-	}
+	input.ConditionExpression, input.ExpressionAttributeValues = deleteCondition(req)
 
 	_, err := d.client.DeleteItemWithContext(ctx, input)
 	if err != nil {
@@ -296,6 +281,102 @@ func (d *StateStore) BulkDelete(ctx context.Context, req []state.DeleteRequest)
 	return e
 }
 
+// Multi performs a transactional operation. succeeds only if all operations succeed, and fails if one or more operations fail.
+func (d *StateStore) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
+	if len(request.Operations) == 0 {
+		return nil
+	}
+	if len(request.Operations) > maxTransactionItems {
+		return fmt.Errorf("dynamodb error: transactions support at most %d operations, got %d", maxTransactionItems, len(request.Operations))
+	}
+
+	// DynamoDB rejects the transactions with several operations on the same item.
+	keys := make(map[string]struct{}, len(request.Operations))
+	items := make([]*dynamodb.TransactWriteItem, 0, len(request.Operations))
+	for _, o := range request.Operations {
+		if r, ok := o.Request.(state.KeyInt); ok {
+			if _, dup := keys[r.GetKey()]; dup {
+				return fmt.Errorf("dynamodb error: transactions support a single operation per key, got several for key %s", r.GetKey())
+			}
+			keys[r.GetKey()] = struct{}{}
+		}
+
+		switch req := o.Request.(type) {
+		case state.SetRequest:
+			item, err := d.getItemFromReq(&req)
+			if err != nil {
+				return err
+			}
+			put := &dynamodb.Put{
+				Item:      item,
+				TableName: aws.String(d.table),
+			}
+			put.ConditionExpression, put.ExpressionAttributeValues = setCondition(&req)
+			items = append(items, &dynamodb.TransactWriteItem{Put: put})
+		case state.DeleteRequest:
+			del := &dynamodb.Delete{
+				Key: map[string]*dynamodb.AttributeValue{
+					d.partitionKey: {
+						S: aws.String(req.Key),
+					},
+				},
+				TableName: aws.String(d.table),
+			}
+			del.ConditionExpression, del.ExpressionAttributeValues = deleteCondition(&req)
+			items = append(items, &dynamodb.TransactWriteItem{Delete: del})
+		default:
+			return fmt.Errorf("dynamodb error: unsupported operation %s", o.Operation)
+		}
+	}
+
+	_, err := d.client.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	var cErr *dynamodb.TransactionCanceledException
+	if errors.As(err, &cErr) {
+		// Reasons are reported in the order of the transaction items; as with Set and Delete, only ETag conditions are
+		// reported as ETag mismatches.
+		for i, reason := range cErr.CancellationReasons {
+			if reason == nil || aws.StringValue(reason.Code) != dynamodb.BatchStatementErrorCodeEnumConditionalCheckFailed || i >= len(items) {
+				continue
+			}
+			if (items[i].Put != nil && items[i].Put.ExpressionAttributeValues != nil) || (items[i].Delete != nil && items[i].Delete.ExpressionAttributeValues != nil) {
+				return state.NewETagError(state.ETagMismatch, cErr)
+			}
+		}
+	}
+
+	return err
+}
+
+// setCondition returns the condition expression enforcing the request's ETag or first-write concurrency, if any.
+func setCondition(req *state.SetRequest) (*string, map[string]*dynamodb.AttributeValue) {
+	if req.ETag != nil && *req.ETag != "" {
+		return aws.String("etag = :etag"), map[string]*dynamodb.AttributeValue{
+			":etag": {
+				S: req.ETag,
+			},
+		}
+	} else if req.Options.Concurrency == state.FirstWrite {
+		return aws.String("attribute_not_exists(etag)"), nil
+	}
+
+	return nil, nil
+}
+
+// deleteCondition returns the condition expression enforcing the request's ETag, if any.
+func deleteCondition(req *state.DeleteRequest) (*string, map[string]*dynamodb.AttributeValue) {
+	if req.ETag != nil && *req.ETag != "" {
+		return aws.String("etag = :etag"), map[string]*dynamodb.AttributeValue{
+			":etag": {
+				S: req.ETag,
+			},
+		}
+	}
+
+	return nil, nil
+}
+
 func (d *StateStore) GetComponentMetadata() map[string]string {
 	metadataStruct := dynamoDBMetadata{}
 	metadataInfo := map[string]string{}
@@ -314,7 +395,17 @@ func (d *StateStore) getDynamoDBMetadata(meta state.Metadata) (*dynamoDBMetadata
 }
 
 func (d *StateStore) getClient(metadata *dynamoDBMetadata) (*dynamodb.DynamoDB, error) {
-	sess, err := awsAuth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.SessionToken, metadata.Region, metadata.Endpoint)
+	sess, err := awsAuth.NewSession(awsAuth.Options{
+		Region:               metadata.Region,
+		Endpoint:             metadata.Endpoint,
+		AccessKey:            metadata.AccessKey,
+		SecretKey:            metadata.SecretKey,
+		SessionToken:         metadata.SessionToken,
+		AssumeRoleARN:        metadata.AssumeRoleArn,
+		ExternalID:           metadata.ExternalID,
+		SessionName:          metadata.SessionName,
+		WebIdentityTokenFile: metadata.WebIdentityTokenFile,
+	})
 	if err != nil {
 		return nil, err
 	}
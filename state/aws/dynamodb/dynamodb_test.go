@@ -27,16 +27,18 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/kit/logger"
 )
 
 type mockedDynamoDB struct {
-	GetItemWithContextFn        func(ctx context.Context, input *dynamodb.GetItemInput, op ...request.Option) (*dynamodb.GetItemOutput, error)
-	PutItemWithContextFn        func(ctx context.Context, input *dynamodb.PutItemInput, op ...request.Option) (*dynamodb.PutItemOutput, error)
-	DeleteItemWithContextFn     func(ctx context.Context, input *dynamodb.DeleteItemInput, op ...request.Option) (*dynamodb.DeleteItemOutput, error)
-	BatchWriteItemWithContextFn func(ctx context.Context, input *dynamodb.BatchWriteItemInput, op ...request.Option) (*dynamodb.BatchWriteItemOutput, error)
+	GetItemWithContextFn            func(ctx context.Context, input *dynamodb.GetItemInput, op ...request.Option) (*dynamodb.GetItemOutput, error)
+	PutItemWithContextFn            func(ctx context.Context, input *dynamodb.PutItemInput, op ...request.Option) (*dynamodb.PutItemOutput, error)
+	DeleteItemWithContextFn         func(ctx context.Context, input *dynamodb.DeleteItemInput, op ...request.Option) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItemWithContextFn     func(ctx context.Context, input *dynamodb.BatchWriteItemInput, op ...request.Option) (*dynamodb.BatchWriteItemOutput, error)
+	TransactWriteItemsWithContextFn func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error)
 	dynamodbiface.DynamoDBAPI
 }
 
@@ -67,6 +69,10 @@ func (m *mockedDynamoDB) BatchWriteItemWithContext(ctx context.Context, input *d
 	return m.BatchWriteItemWithContextFn(ctx, input, op...)
 }
 
+func (m *mockedDynamoDB) TransactWriteItemsWithContext(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	return m.TransactWriteItemsWithContextFn(ctx, input, op...)
+}
+
 func TestInit(t *testing.T) {
 	m := state.Metadata{}
 	s := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
@@ -1047,3 +1053,198 @@ func TestBulkDelete(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestMulti(t *testing.T) {
+	type value struct {
+		Value string
+	}
+
+	t.Run("Successfully run transaction", func(t *testing.T) {
+		etag := "1bdead4badc0ffee"
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ss.table = tableName
+		ss.client = &mockedDynamoDB{
+			TransactWriteItemsWithContextFn: func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+				assert.Len(t, input.TransactItems, 2)
+
+				put := input.TransactItems[0].Put
+				assert.Equal(t, tableName, *put.TableName)
+				assert.Equal(t, "key1", *put.Item["key"].S)
+				assert.Equal(t, `{"Value":"value1"}`, *put.Item["value"].S)
+				assert.Equal(t, "etag = :etag", *put.ConditionExpression)
+				assert.Equal(t, etag, *put.ExpressionAttributeValues[":etag"].S)
+
+				del := input.TransactItems[1].Delete
+				assert.Equal(t, "key2", *del.Key["key"].S)
+				assert.Nil(t, del.ConditionExpression)
+
+				return &dynamodb.TransactWriteItemsOutput{}, nil
+			},
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{
+					Operation: state.Upsert,
+					Request: state.SetRequest{
+						Key:   "key1",
+						Value: value{Value: "value1"},
+						ETag:  &etag,
+					},
+				},
+				{
+					Operation: state.Delete,
+					Request: state.DeleteRequest{
+						Key: "key2",
+					},
+				},
+			},
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Etag mismatch cancels transaction", func(t *testing.T) {
+		etag := "1bdead4badc0ffee"
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ss.client = &mockedDynamoDB{
+			TransactWriteItemsWithContextFn: func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+				return nil, &dynamodb.TransactionCanceledException{
+					CancellationReasons: []*dynamodb.CancellationReason{
+						{Code: aws.String("None")},
+						{Code: aws.String("ConditionalCheckFailed")},
+					},
+				}
+			},
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{
+					Operation: state.Upsert,
+					Request:   state.SetRequest{Key: "key1", Value: value{Value: "value1"}},
+				},
+				{
+					Operation: state.Delete,
+					Request:   state.DeleteRequest{Key: "key2", ETag: &etag},
+				},
+			},
+		})
+		assert.NotNil(t, err)
+		_, ok := err.(*state.ETagError)
+		assert.True(t, ok)
+	})
+
+	t.Run("Etag mismatch of an upsert cancels transaction", func(t *testing.T) {
+		etag := "1bdead4badc0ffee"
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ss.client = &mockedDynamoDB{
+			TransactWriteItemsWithContextFn: func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+				return nil, fmt.Errorf("transaction failed: %w", &dynamodb.TransactionCanceledException{
+					CancellationReasons: []*dynamodb.CancellationReason{
+						{Code: aws.String("ConditionalCheckFailed")},
+					},
+				})
+			},
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{
+					Operation: state.Upsert,
+					Request:   state.SetRequest{Key: "key1", Value: value{Value: "value1"}, ETag: &etag},
+				},
+			},
+		})
+		var etagErr *state.ETagError
+		require.ErrorAs(t, err, &etagErr)
+		assert.Equal(t, state.ETagMismatch, etagErr.Kind())
+	})
+
+	t.Run("First-write conflict cancels transaction", func(t *testing.T) {
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ss.client = &mockedDynamoDB{
+			TransactWriteItemsWithContextFn: func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+				return nil, &dynamodb.TransactionCanceledException{
+					CancellationReasons: []*dynamodb.CancellationReason{
+						{Code: aws.String("ConditionalCheckFailed")},
+					},
+				}
+			},
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{
+					Operation: state.Upsert,
+					Request: state.SetRequest{
+						Key:     "key1",
+						Value:   value{Value: "value1"},
+						Options: state.SetStateOption{Concurrency: state.FirstWrite},
+					},
+				},
+			},
+		})
+		assert.NotNil(t, err)
+		_, ok := err.(*state.ETagError)
+		assert.False(t, ok)
+	})
+
+	t.Run("Several operations on the same key", func(t *testing.T) {
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ss.client = &mockedDynamoDB{
+			TransactWriteItemsWithContextFn: func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+				assert.Fail(t, "the transaction must not be run")
+				return nil, nil
+			},
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{
+					Operation: state.Upsert,
+					Request:   state.SetRequest{Key: "key1", Value: value{Value: "value1"}},
+				},
+				{
+					Operation: state.Delete,
+					Request:   state.DeleteRequest{Key: "key1"},
+				},
+			},
+		})
+		assert.ErrorContains(t, err, "key1")
+	})
+
+	t.Run("Unsuccessfully run transaction", func(t *testing.T) {
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ss.client = &mockedDynamoDB{
+			TransactWriteItemsWithContextFn: func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, op ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+				return nil, fmt.Errorf("unable to run transaction")
+			},
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{
+					Operation: state.Delete,
+					Request:   state.DeleteRequest{Key: "key"},
+				},
+			},
+		})
+		assert.NotNil(t, err)
+		_, ok := err.(*state.ETagError)
+		assert.False(t, ok)
+	})
+
+	t.Run("Too many operations", func(t *testing.T) {
+		ss := NewDynamoDBStateStore(logger.NewLogger("test")).(*StateStore)
+		ops := make([]state.TransactionalStateOperation, maxTransactionItems+1)
+		for i := range ops {
+			ops[i] = state.TransactionalStateOperation{
+				Operation: state.Delete,
+				Request:   state.DeleteRequest{Key: strconv.Itoa(i)},
+			}
+		}
+
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{Operations: ops})
+		assert.NotNil(t, err)
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -77,6 +78,7 @@ type CosmosItem struct {
 
 const (
 	metadataPartitionKey = "partitionKey"
+	metadataTimestamp    = "_ts"
 	defaultTimeout       = 20 * time.Second
 	statusNotFound       = "NotFound"
 )
@@ -163,7 +165,7 @@ func (c *StateStore) Init(meta state.Metadata) error {
 	} else {
 		// Fallback to using Azure AD
 		var env azure.EnvironmentSettings
-		env, err := azure.NewEnvironmentSettings("cosmosdb", meta.Properties)
+		env, err := azure.NewEnvironmentSettings(azure.AzureCosmosDBResourceName, meta.Properties)
 		if err != nil {
 			return err
 		}
@@ -203,11 +205,105 @@ func (c *StateStore) Features() []state.Feature {
 		state.FeatureETag,
 		state.FeatureTransactional,
 		state.FeatureQueryAPI,
+		state.FeatureSessionToken,
 	}
 }
 
 // Get retrieves a CosmosDB item.
 func (c *StateStore) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	fields, err := stateutils.ParseFields(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		return c.getFields(ctx, req, fields)
+	}
+
+	return c.readItem(ctx, req)
+}
+
+// getFields retrieves only the requested fields of the stored value using a SELECT projection.
+func (c *StateStore) getFields(ctx context.Context, req *state.GetRequest, fields [][]string) (*state.GetResponse, error) {
+	partitionKey := populatePartitionMetadata(req.Key, req.Metadata)
+
+	opts := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@id", Value: req.Key}},
+	}
+	if req.Options.Consistency == state.Strong {
+		opts.ConsistencyLevel = azcosmos.ConsistencyLevelSession.ToPtr()
+	} else if req.Options.Consistency == state.Eventual {
+		opts.ConsistencyLevel = azcosmos.ConsistencyLevelEventual.ToPtr()
+	}
+	opts.SessionToken = req.Metadata[state.SessionTokenMetadataKey]
+
+	queryPager := c.client.NewQueryItemsPager(fieldsQuery(fields), azcosmos.NewPartitionKeyString(partitionKey), opts)
+	var items [][]byte
+	for queryPager.More() && len(items) == 0 {
+		queryCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+		queryResponse, err := queryPager.NextPage(queryCtx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, queryResponse.Items...)
+	}
+	if len(items) == 0 {
+		return &state.GetResponse{}, nil
+	}
+
+	var row map[string]interface{}
+	err := jsoniter.ConfigFastest.Unmarshal(items[0], &row)
+	if err != nil {
+		return nil, err
+	}
+
+	// Binary values can't be projected.
+	if isBinary, _ := row["isBinary"].(bool); isBinary {
+		return c.readItem(ctx, req)
+	}
+
+	value := map[string]interface{}{}
+	for i, f := range fields {
+		if v, ok := row["f"+strconv.Itoa(i)]; ok {
+			stateutils.SetField(value, f, v)
+		}
+	}
+
+	b, err := jsoniter.ConfigFastest.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	etag, _ := row["_etag"].(string)
+	ts, _ := row["_ts"].(float64)
+	var ttl *int
+	if v, ok := row["ttl"].(float64); ok {
+		ttl = ptr.Of(int(v))
+	}
+	return &state.GetResponse{
+		Data:     b,
+		ETag:     ptr.Of(etag),
+		Metadata: itemMetadata(int64(ts), ttl),
+	}, nil
+}
+
+// fieldsQuery builds the query selecting the given fields of the value of the item with id "@id".
+// Each field is returned with the alias "f<index>".
+func fieldsQuery(fields [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("SELECT c.id, c._etag, c._ts, c.ttl, c.isBinary")
+	for i, f := range fields {
+		sb.WriteString(`, c["value"]`)
+		for _, s := range f {
+			sb.WriteString(`["` + strings.ReplaceAll(s, `\`, `\\`) + `"]`)
+		}
+		sb.WriteString(" AS f" + strconv.Itoa(i))
+	}
+	sb.WriteString(" FROM c WHERE c.id = @id")
+	return sb.String()
+}
+
+func (c *StateStore) readItem(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
 	partitionKey := populatePartitionMetadata(req.Key, req.Metadata)
 
 	options := azcosmos.ItemOptions{}
@@ -216,6 +312,7 @@ func (c *StateStore) Get(ctx context.Context, req *state.GetRequest) (*state.Get
 	} else if req.Options.Consistency == state.Eventual {
 		options.ConsistencyLevel = azcosmos.ConsistencyLevelEventual.ToPtr()
 	}
+	options.SessionToken = req.Metadata[state.SessionTokenMetadataKey]
 
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	readItem, err := c.client.ReadItem(ctx, azcosmos.NewPartitionKeyString(partitionKey), req.Key, &options)
@@ -236,11 +333,24 @@ func (c *StateStore) Get(ctx context.Context, req *state.GetRequest) (*state.Get
 
 	item.Etag = string(readItem.Response.ETag)
 
+	system := struct {
+		Ts int64 `json:"_ts"`
+	}{}
+	err = jsoniter.ConfigFastest.Unmarshal(readItem.Value, &system)
+	if err != nil {
+		return nil, err
+	}
+	meta := itemMetadata(system.Ts, item.TTL)
+	if readItem.SessionToken != "" {
+		meta[state.SessionTokenMetadataKey] = readItem.SessionToken
+	}
+
 	if item.IsBinary {
 		if item.Value == nil {
 			return &state.GetResponse{
-				Data: make([]byte, 0),
-				ETag: ptr.Of(item.Etag),
+				Data:     make([]byte, 0),
+				ETag:     ptr.Of(item.Etag),
+				Metadata: meta,
 			}, nil
 		}
 
@@ -251,8 +361,9 @@ func (c *StateStore) Get(ctx context.Context, req *state.GetRequest) (*state.Get
 		}
 
 		return &state.GetResponse{
-			Data: bytes,
-			ETag: ptr.Of(item.Etag),
+			Data:     bytes,
+			ETag:     ptr.Of(item.Etag),
+			Metadata: meta,
 		}, nil
 	}
 
@@ -262,16 +373,37 @@ func (c *StateStore) Get(ctx context.Context, req *state.GetRequest) (*state.Get
 	}
 
 	return &state.GetResponse{
-		Data: b,
-		ETag: ptr.Of(item.Etag),
+		Data:     b,
+		ETag:     ptr.Of(item.Etag),
+		Metadata: meta,
 	}, nil
 }
 
+// itemMetadata returns the metadata of an item last modified at ts, the "_ts" system property of the item.
+// ttl is the time to live of the item, in seconds from its last modification.
+func itemMetadata(ts int64, ttl *int) map[string]string {
+	meta := map[string]string{
+		metadataTimestamp: strconv.FormatInt(ts, 10),
+	}
+	modified := time.Unix(ts, 0).UTC()
+	meta[state.GetResponseMetadataLastModified] = modified.Format(time.RFC3339)
+	if ttl != nil && *ttl > 0 {
+		meta[state.GetResponseMetadataExpireTime] = modified.Add(time.Duration(*ttl) * time.Second).Format(time.RFC3339)
+	}
+	return meta
+}
+
 // Set saves a CosmosDB item.
 func (c *StateStore) Set(ctx context.Context, req *state.SetRequest) error {
+	_, err := c.SetWithSessionToken(ctx, req)
+	return err
+}
+
+// SetWithSessionToken saves a CosmosDB item and returns the session token after the write.
+func (c *StateStore) SetWithSessionToken(ctx context.Context, req *state.SetRequest) (string, error) {
 	err := state.CheckRequestOptions(req.Options)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	partitionKey := populatePartitionMetadata(req.Key, req.Metadata)
@@ -285,7 +417,7 @@ func (c *StateStore) Set(ctx context.Context, req *state.SetRequest) error {
 		var u uuid.UUID
 		u, err = uuid.NewRandom()
 		if err != nil {
-			return err
+			return "", err
 		}
 		options.IfMatchEtag = ptr.Of(azcore.ETag(u.String()))
 	}
@@ -295,32 +427,39 @@ func (c *StateStore) Set(ctx context.Context, req *state.SetRequest) error {
 	} else if req.Options.Consistency == state.Eventual {
 		options.ConsistencyLevel = azcosmos.ConsistencyLevelEventual.ToPtr()
 	}
+	options.SessionToken = req.Metadata[state.SessionTokenMetadataKey]
 
 	doc, err := createUpsertItem(c.contentType, *req, partitionKey)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	marsh, err := json.Marshal(doc)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	pk := azcosmos.NewPartitionKeyString(partitionKey)
-	_, err = c.client.UpsertItem(ctx, pk, marsh, &options)
+	res, err := c.client.UpsertItem(ctx, pk, marsh, &options)
 	cancel()
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return res.SessionToken, nil
 }
 
 // Delete performs a delete operation.
 func (c *StateStore) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	_, err := c.DeleteWithSessionToken(ctx, req)
+	return err
+}
+
+// DeleteWithSessionToken performs a delete operation and returns the session token after the delete.
+func (c *StateStore) DeleteWithSessionToken(ctx context.Context, req *state.DeleteRequest) (string, error) {
 	err := state.CheckRequestOptions(req.Options)
 	if err != nil {
-		return err
+		return "", err
 	}
 	partitionKey := populatePartitionMetadata(req.Key, req.Metadata)
 	options := azcosmos.ItemOptions{}
@@ -334,20 +473,21 @@ func (c *StateStore) Delete(ctx context.Context, req *state.DeleteRequest) error
 	} else if req.Options.Consistency == state.Eventual {
 		options.ConsistencyLevel = azcosmos.ConsistencyLevelEventual.ToPtr()
 	}
+	options.SessionToken = req.Metadata[state.SessionTokenMetadataKey]
 
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	pk := azcosmos.NewPartitionKeyString(partitionKey)
-	_, err = c.client.DeleteItem(ctx, pk, req.Key, &options)
+	res, err := c.client.DeleteItem(ctx, pk, req.Key, &options)
 	cancel()
 	if err != nil && !isNotFoundError(err) {
 		c.logger.Debugf("Error from cosmos.DeleteDocument e=%e, e.Error=%s", err, err.Error())
 		if req.ETag != nil && *req.ETag != "" {
-			return state.NewETagError(state.ETagMismatch, err)
+			return "", state.NewETagError(state.ETagMismatch, err)
 		}
-		return err
+		return "", err
 	}
 
-	return nil
+	return res.SessionToken, nil
 }
 
 // Multi performs a transactional operation. succeeds only if all operations succeed, and fails if one or more operations fail.
@@ -419,7 +559,9 @@ func (c *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 	c.logger.Debugf("#operations=%d,partitionkey=%s", numOperations, partitionKey)
 
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
-	batchResponse, err := c.client.ExecuteTransactionalBatch(ctx, batch, nil)
+	batchResponse, err := c.client.ExecuteTransactionalBatch(ctx, batch, &azcosmos.TransactionalBatchOptions{
+		SessionToken: request.Metadata[state.SessionTokenMetadataKey],
+	})
 	cancel()
 	if err != nil {
 		return err
@@ -14,14 +14,22 @@ limitations under the License.
 package cosmosdb
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"sync"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/state"
 	stateutils "github.com/dapr/components-contrib/state/utils"
+	"github.com/dapr/kit/ptr"
 )
 
 type widget struct {
@@ -356,3 +364,74 @@ func TestCreateCosmosItemWithTTL(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestFieldsQuery(t *testing.T) {
+	q := fieldsQuery([][]string{{"name"}, {"address", "city"}})
+	assert.Equal(t, `SELECT c.id, c._etag, c._ts, c.ttl, c.isBinary, c["value"]["name"] AS f0, c["value"]["address"]["city"] AS f1 FROM c WHERE c.id = @id`, q)
+}
+
+func TestItemMetadata(t *testing.T) {
+	meta := itemMetadata(1672531200, nil)
+	assert.Equal(t, map[string]string{
+		"_ts":          "1672531200",
+		"lastModified": "2023-01-01T00:00:00Z",
+	}, meta)
+
+	meta = itemMetadata(1672531200, ptr.Of(3600))
+	assert.Equal(t, "2023-01-01T01:00:00Z", meta[state.GetResponseMetadataExpireTime])
+
+	meta = itemMetadata(1672531200, ptr.Of(-1))
+	assert.NotContains(t, meta, state.GetResponseMetadataExpireTime)
+}
+
+func TestSessionToken(t *testing.T) {
+	var (
+		lock   sync.Mutex
+		tokens []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		tokens = append(tokens, r.Header.Get("x-ms-session-token"))
+		lock.Unlock()
+		w.Header().Set("x-ms-session-token", "0:1#"+strconv.Itoa(len(tokens)))
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case http.MethodGet:
+			w.Write([]byte(`{"id":"key","value":"v","partitionKey":"key","_ts":1672531200}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	cred, err := azcosmos.NewKeyCredential(base64.StdEncoding.EncodeToString([]byte("key")))
+	require.NoError(t, err)
+	client, err := azcosmos.NewClientWithKey(srv.URL, cred, nil)
+	require.NoError(t, err)
+	container, err := client.NewContainer("db", "container")
+	require.NoError(t, err)
+	store := &StateStore{client: container, contentType: "application/json"}
+	ctx := context.Background()
+
+	token, err := store.SetWithSessionToken(ctx, &state.SetRequest{Key: "key", Value: "v"})
+	require.NoError(t, err)
+	assert.Equal(t, "0:1#1", token)
+
+	res, err := store.Get(ctx, &state.GetRequest{
+		Key:      "key",
+		Metadata: map[string]string{state.SessionTokenMetadataKey: token},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0:1#2", res.Metadata[state.SessionTokenMetadataKey])
+
+	token, err = store.DeleteWithSessionToken(ctx, &state.DeleteRequest{
+		Key:      "key",
+		Metadata: map[string]string{state.SessionTokenMetadataKey: res.Metadata[state.SessionTokenMetadataKey]},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0:1#3", token)
+
+	assert.Equal(t, []string{"", "0:1#1", "0:1#2"}, tokens)
+}
@@ -127,9 +127,9 @@ func (r *StateStore) Init(metadata state.Metadata) error {
 		var settings azauth.EnvironmentSettings
 		var innerErr error
 		if r.cosmosDBMode {
-			settings, innerErr = azauth.NewEnvironmentSettings("cosmosdb", metadata.Properties)
+			settings, innerErr = azauth.NewEnvironmentSettings(azauth.AzureCosmosDBResourceName, metadata.Properties)
 		} else {
-			settings, innerErr = azauth.NewEnvironmentSettings("storage", metadata.Properties)
+			settings, innerErr = azauth.NewEnvironmentSettings(azauth.AzureStorageResourceName, metadata.Properties)
 		}
 		if innerErr != nil {
 			return innerErr
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/bulkhead"
+)
+
+// bulkheadStore limits the number of operations of a store running at the same time.
+type bulkheadStore struct {
+	forwarder
+
+	bulkhead *bulkhead.Bulkhead
+}
+
+// NewBulkheadStore wraps a Store so that at most "maxConcurrentOperations" of its operations run at the same time.
+// The other ones wait for a free slot for up to "maxConcurrentOperationsWait", then fail with bulkhead.ErrBulkheadFull.
+// The wrapper keeps the transactional and query capabilities of the inner store.
+func NewBulkheadStore(inner Store) Store {
+	s := &bulkheadStore{forwarder: forwarder{inner}}
+	return exposeOptional(s, inner)
+}
+
+func (s *bulkheadStore) Init(metadata Metadata) error {
+	m, err := bulkhead.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("state store bulkhead error: %w", err)
+	}
+	s.bulkhead = bulkhead.New(m)
+
+	return s.Store.Init(metadata)
+}
+
+func (s *bulkheadStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	return bulkhead.Run(ctx, s.bulkhead, func(ctx context.Context) (*GetResponse, error) {
+		return s.Store.Get(ctx, req)
+	})
+}
+
+func (s *bulkheadStore) Set(ctx context.Context, req *SetRequest) error {
+	return bulkhead.RunOnce(ctx, s.bulkhead, func(ctx context.Context) error {
+		return s.forwarder.Set(ctx, req)
+	})
+}
+
+func (s *bulkheadStore) Delete(ctx context.Context, req *DeleteRequest) error {
+	return bulkhead.RunOnce(ctx, s.bulkhead, func(ctx context.Context) error {
+		return s.forwarder.Delete(ctx, req)
+	})
+}
+
+func (s *bulkheadStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	type bulkGetResult struct {
+		supported bool
+		res       []BulkGetResponse
+	}
+	res, err := bulkhead.Run(ctx, s.bulkhead, func(ctx context.Context) (bulkGetResult, error) {
+		supported, res, err := s.Store.BulkGet(ctx, req)
+		return bulkGetResult{supported: supported, res: res}, err
+	})
+	return res.supported, res.res, err
+}
+
+func (s *bulkheadStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	return bulkhead.RunOnce(ctx, s.bulkhead, func(ctx context.Context) error {
+		return s.Store.BulkSet(ctx, req)
+	})
+}
+
+func (s *bulkheadStore) BulkDelete(ctx context.Context, req []DeleteRequest) error {
+	return bulkhead.RunOnce(ctx, s.bulkhead, func(ctx context.Context) error {
+		return s.Store.BulkDelete(ctx, req)
+	})
+}
+
+func (s *bulkheadStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	return bulkhead.RunOnce(ctx, s.bulkhead, func(ctx context.Context) error {
+		return s.forwarder.Multi(ctx, request)
+	})
+}
+
+func (s *bulkheadStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	return bulkhead.Run(ctx, s.bulkhead, func(ctx context.Context) (*QueryResponse, error) {
+		return s.forwarder.Query(ctx, req)
+	})
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/internal/component/bulkhead"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+type blockingStore struct {
+	memStore
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Set(ctx context.Context, req *SetRequest) error {
+	s.started <- struct{}{}
+	<-s.release
+	return s.memStore.Set(ctx, req)
+}
+
+func TestBulkheadStore(t *testing.T) {
+	props := map[string]string{
+		"maxConcurrentOperations":     "1",
+		"maxConcurrentOperationsWait": "10ms",
+	}
+
+	t.Run("limits concurrent operations", func(t *testing.T) {
+		inner := &blockingStore{memStore: *newMemStore(), started: make(chan struct{}, 1), release: make(chan struct{})}
+		s := NewBulkheadStore(inner)
+		require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")})
+		}()
+		<-inner.started
+
+		_, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.ErrorIs(t, err, bulkhead.ErrBulkheadFull)
+
+		close(inner.release)
+		require.NoError(t, <-done)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v"), res.Data)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		s := NewBulkheadStore(newMemStore())
+		err := s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{"maxConcurrentOperations": "-1"}}})
+		assert.ErrorContains(t, err, "state store bulkhead error")
+	})
+
+	t.Run("keeps the capabilities of the inner store", func(t *testing.T) {
+		_, ok := NewBulkheadStore(newMemStore()).(TransactionalStore)
+		assert.True(t, ok)
+		_, ok = NewBulkheadStore(newMemStore()).(Querier)
+		assert.False(t, ok)
+		_, ok = NewBulkheadStore(&Store1{}).(TransactionalStore)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Metadata keys for the encryption keys of a store wrapped with NewEncryptedStore.
+// Keys are 128, 192 or 256-bit AES keys, hex or base64-encoded; they are usually set with a secretKeyRef.
+const (
+	// Key used to encrypt new values.
+	PrimaryEncryptionKey = "primaryEncryptionKey"
+	// Previous key, still used to decrypt values written before a key rotation.
+	SecondaryEncryptionKey = "secondaryEncryptionKey"
+)
+
+// encryptedValueSeparator separates the key ID from the ciphertext in encrypted values.
+const encryptedValueSeparator = "."
+
+type encryptionKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// encryptedStore encrypts values with AES-GCM before saving them in the wrapped store, and decrypts them when read.
+type encryptedStore struct {
+	forwarder
+
+	primary *encryptionKey
+	keys    map[string]*encryptionKey
+}
+
+// NewEncryptedStore wraps a Store so that values are encrypted client-side with the key set in the "primaryEncryptionKey" metadata.
+// Encrypted values are prefixed with the ID of their key, so values written with the "secondaryEncryptionKey" can still be read after a rotation.
+// Encryption is disabled when no key is set. Encrypted values can't be queried, so Query fails once encryption is enabled.
+func NewEncryptedStore(inner Store) Store {
+	s := &encryptedStore{forwarder: forwarder{inner}}
+	return exposeOptional(s, inner)
+}
+
+func (s *encryptedStore) Init(metadata Metadata) error {
+	s.primary = nil
+	s.keys = map[string]*encryptionKey{}
+	for _, name := range []string{PrimaryEncryptionKey, SecondaryEncryptionKey} {
+		val := metadata.Properties[name]
+		if val == "" {
+			continue
+		}
+		key, err := newEncryptionKey(val)
+		if err != nil {
+			return fmt.Errorf("invalid metadata '%s': %w", name, err)
+		}
+		if name == PrimaryEncryptionKey {
+			s.primary = key
+		}
+		s.keys[key.id] = key
+	}
+	if s.primary == nil && len(s.keys) > 0 {
+		return fmt.Errorf("metadata '%s' is required with '%s'", PrimaryEncryptionKey, SecondaryEncryptionKey)
+	}
+
+	return s.Store.Init(metadata)
+}
+
+func (s *encryptedStore) Features() []Feature {
+	features := s.Store.Features()
+	if s.primary == nil {
+		return features
+	}
+
+	res := make([]Feature, 0, len(features))
+	for _, f := range features {
+		if f != FeatureQueryAPI {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func (s *encryptedStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if s.primary != nil {
+		return nil, errors.New("the query API is not available with encryption")
+	}
+	return s.forwarder.Query(ctx, req)
+}
+
+func (s *encryptedStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	res, err := s.Store.Get(ctx, req)
+	if err != nil || s.primary == nil || res == nil || len(res.Data) == 0 {
+		return res, err
+	}
+
+	res.Data, err = s.decrypt(req.Key, res.Data)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *encryptedStore) Set(ctx context.Context, req *SetRequest) error {
+	if s.primary == nil {
+		return s.forwarder.Set(ctx, req)
+	}
+
+	encrypted, err := s.encryptRequest(req)
+	if err != nil {
+		return err
+	}
+	return s.forwarder.Set(ctx, encrypted)
+}
+
+func (s *encryptedStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	supported, res, err := s.Store.BulkGet(ctx, req)
+	if err != nil || !supported || s.primary == nil {
+		return supported, res, err
+	}
+
+	for i := range res {
+		if res[i].Error != "" || len(res[i].Data) == 0 {
+			continue
+		}
+		data, err := s.decrypt(res[i].Key, res[i].Data)
+		if err != nil {
+			res[i].Data = nil
+			res[i].Error = err.Error()
+			continue
+		}
+		res[i].Data = data
+	}
+	return true, res, nil
+}
+
+func (s *encryptedStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	if s.primary == nil {
+		return s.Store.BulkSet(ctx, req)
+	}
+
+	encrypted := make([]SetRequest, len(req))
+	for i := range req {
+		r, err := s.encryptRequest(&req[i])
+		if err != nil {
+			return err
+		}
+		encrypted[i] = *r
+	}
+	return s.Store.BulkSet(ctx, encrypted)
+}
+
+func (s *encryptedStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	if s.primary == nil {
+		return s.forwarder.Multi(ctx, request)
+	}
+
+	encrypted := *request
+	encrypted.Operations = make([]TransactionalStateOperation, len(request.Operations))
+	for i, o := range request.Operations {
+		encrypted.Operations[i] = o
+		if o.Operation != Upsert {
+			continue
+		}
+
+		var req SetRequest
+		switch r := o.Request.(type) {
+		case SetRequest:
+			req = r
+		case *SetRequest:
+			req = *r
+		default:
+			return fmt.Errorf("unexpected request type %T for upsert operation", o.Request)
+		}
+		r, err := s.encryptRequest(&req)
+		if err != nil {
+			return err
+		}
+		encrypted.Operations[i].Request = *r
+	}
+	return s.forwarder.Multi(ctx, &encrypted)
+}
+
+// encryptRequest returns a copy of req with its value encrypted.
+func (s *encryptedStore) encryptRequest(req *SetRequest) (*SetRequest, error) {
+	var plaintext []byte
+	switch v := req.Value.(type) {
+	case []byte:
+		plaintext = v
+	default:
+		var err error
+		plaintext, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nonce := make([]byte, s.primary.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The state key is used as additional data, so values can't be swapped between keys
+	ciphertext := s.primary.aead.Seal(nonce, nonce, plaintext, []byte(req.Key))
+
+	encrypted := *req
+	encrypted.Value = []byte(s.primary.id + encryptedValueSeparator + base64.StdEncoding.EncodeToString(ciphertext))
+	return &encrypted, nil
+}
+
+func (s *encryptedStore) decrypt(stateKey string, data []byte) ([]byte, error) {
+	id, encoded, ok := strings.Cut(string(data), encryptedValueSeparator)
+	if !ok {
+		return nil, errors.New("failed to decrypt state: value is not encrypted")
+	}
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt state: no encryption key with ID %s", id)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(ciphertext) < key.aead.NonceSize() {
+		return nil, errors.New("failed to decrypt state: invalid ciphertext")
+	}
+	nonce, ciphertext := ciphertext[:key.aead.NonceSize()], ciphertext[key.aead.NonceSize():]
+	plaintext, err := key.aead.Open(nil, nonce, ciphertext, []byte(stateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state: %w", err)
+	}
+	return plaintext, nil
+}
+
+// newEncryptionKey parses a hex or base64-encoded AES key.
+// Its ID is derived from the key itself, so it doesn't disclose the key.
+func newEncryptionKey(val string) (*encryptionKey, error) {
+	raw, err := hex.DecodeString(val)
+	if err != nil {
+		raw, err = base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, errors.New("key must be hex or base64-encoded")
+		}
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+	return &encryptionKey{
+		id:   hex.EncodeToString(sum[:4]),
+		aead: aead,
+	}, nil
+}
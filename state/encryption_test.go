@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	testKey1 = "000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f"
+	testKey2 = "AAECAwQFBgcICQoLDA0ODw=="
+)
+
+type memStore struct {
+	DefaultBulkStore
+	items map[string][]byte
+}
+
+func newMemStore() *memStore {
+	s := &memStore{items: map[string][]byte{}}
+	s.DefaultBulkStore = NewDefaultBulkStore(s)
+	return s
+}
+
+func (s *memStore) Init(metadata Metadata) error { return nil }
+func (s *memStore) Features() []Feature {
+	return []Feature{FeatureETag, FeatureTransactional, FeatureQueryAPI}
+}
+
+func (s *memStore) Delete(ctx context.Context, req *DeleteRequest) error {
+	delete(s.items, req.Key)
+	return nil
+}
+
+func (s *memStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	return &GetResponse{Data: s.items[req.Key]}, nil
+}
+
+func (s *memStore) Set(ctx context.Context, req *SetRequest) error {
+	s.items[req.Key] = req.Value.([]byte)
+	return nil
+}
+
+func (s *memStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	for _, o := range request.Operations {
+		req := o.Request.(SetRequest)
+		s.Set(ctx, &req)
+	}
+	return nil
+}
+
+func (s *memStore) GetComponentMetadata() map[string]string { return nil }
+
+func initEncryptedStore(t *testing.T, inner Store, props map[string]string) Store {
+	t.Helper()
+	s := NewEncryptedStore(inner)
+	require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+	return s
+}
+
+func TestEncryptedStore(t *testing.T) {
+	t.Run("encrypts and decrypts values", func(t *testing.T) {
+		inner := newMemStore()
+		s := initEncryptedStore(t, inner, map[string]string{PrimaryEncryptionKey: testKey1})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: map[string]string{"a": "b"}}))
+		assert.NotContains(t, string(inner.items["k"]), `"a"`)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":"b"}`, string(res.Data))
+	})
+
+	t.Run("values are bound to their key", func(t *testing.T) {
+		inner := newMemStore()
+		s := initEncryptedStore(t, inner, map[string]string{PrimaryEncryptionKey: testKey1})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k1", Value: []byte("secret")}))
+		inner.items["k2"] = inner.items["k1"]
+		_, err := s.Get(context.Background(), &GetRequest{Key: "k2"})
+		assert.Error(t, err)
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		inner := newMemStore()
+		s := initEncryptedStore(t, inner, map[string]string{PrimaryEncryptionKey: testKey1})
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("secret")}))
+
+		rotated := initEncryptedStore(t, inner, map[string]string{PrimaryEncryptionKey: testKey2, SecondaryEncryptionKey: testKey1})
+		res, err := rotated.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(res.Data))
+
+		require.NoError(t, rotated.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("secret")}))
+		_, err = s.Get(context.Background(), &GetRequest{Key: "k"})
+		assert.Error(t, err)
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		inner := newMemStore()
+		s := initEncryptedStore(t, inner, map[string]string{PrimaryEncryptionKey: testKey1})
+
+		transactional, ok := s.(TransactionalStore)
+		require.True(t, ok)
+		require.NoError(t, transactional.Multi(context.Background(), &TransactionalStateRequest{
+			Operations: []TransactionalStateOperation{{Operation: Upsert, Request: SetRequest{Key: "k", Value: []byte("secret")}}},
+		}))
+		assert.False(t, strings.Contains(string(inner.items["k"]), "secret"))
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(res.Data))
+	})
+
+	t.Run("query API is not available", func(t *testing.T) {
+		s := initEncryptedStore(t, newMemStore(), map[string]string{PrimaryEncryptionKey: testKey1})
+		assert.Equal(t, []Feature{FeatureETag, FeatureTransactional}, s.Features())
+	})
+
+	t.Run("disabled without key", func(t *testing.T) {
+		inner := newMemStore()
+		s := initEncryptedStore(t, inner, map[string]string{})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("plain")}))
+		assert.Equal(t, "plain", string(inner.items["k"]))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		s := NewEncryptedStore(newMemStore())
+		assert.Error(t, s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{PrimaryEncryptionKey: "abcd"}}}))
+		assert.Error(t, s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{SecondaryEncryptionKey: testKey1}}}))
+	})
+}
@@ -20,6 +20,10 @@ const (
 	FeatureTransactional Feature = "TRANSACTIONAL"
 	// FeatureQueryAPI is the feature that performs query operations.
 	FeatureQueryAPI Feature = "QUERY_API"
+	// FeatureSessionToken is the feature to return and honor session tokens for read-your-writes consistency.
+	FeatureSessionToken Feature = "SESSION_TOKEN"
+	// FeatureTTL is the feature to expire items after the "ttlInSeconds" of their Set request.
+	FeatureTTL Feature = "TTL"
 )
 
 // Feature names a feature that can be implemented by PubSub components.
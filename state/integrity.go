@@ -0,0 +1,313 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// VerifyIntegrity is the metadata key of a store wrapped with NewIntegrityStore enabling the checksums of the values.
+const VerifyIntegrity = "verifyIntegrity"
+
+// checksumPrefix prefixes the values saved with their checksum, followed by the hex SHA-256 checksum and a colon.
+const checksumPrefix = "dapr-sha256:"
+
+// verifyIntegrityBatchSize is the number of values read at once by VerifyStoreIntegrity.
+const verifyIntegrityBatchSize = 100
+
+// ErrStateCorrupted is the error, wrapped in an *IntegrityError, returned when a value doesn't match its checksum.
+var ErrStateCorrupted = errors.New("state value is corrupted")
+
+// IntegrityError is the error returned when the value of a key doesn't match its checksum.
+type IntegrityError struct {
+	Key    string
+	reason string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("%s: key %s: %s", ErrStateCorrupted, e.Key, e.reason)
+}
+
+func (e *IntegrityError) Unwrap() error {
+	return ErrStateCorrupted
+}
+
+// integrityStore saves the values with a SHA-256 checksum in the wrapped store, and verifies them when read.
+type integrityStore struct {
+	forwarder
+
+	enabled bool
+}
+
+// NewIntegrityStore wraps a Store so that values are saved with a SHA-256 checksum of the state key and the value when the "verifyIntegrity" metadata is true.
+// Get fails with an *IntegrityError, which wraps ErrStateCorrupted, when a value doesn't match its checksum.
+// Values saved before the checksums were enabled are returned as they are. Values with checksums can't be queried, so Query fails once integrity checks is enabled.
+func NewIntegrityStore(inner Store) Store {
+	s := &integrityStore{forwarder: forwarder{inner}}
+	return exposeOptional(s, inner)
+}
+
+func (s *integrityStore) Init(metadata Metadata) error {
+	s.enabled = false
+	if val := metadata.Properties[VerifyIntegrity]; val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid metadata '%s': must be a boolean", VerifyIntegrity)
+		}
+		s.enabled = enabled
+	}
+
+	return s.Store.Init(metadata)
+}
+
+func (s *integrityStore) Features() []Feature {
+	features := s.Store.Features()
+	if !s.enabled {
+		return features
+	}
+
+	res := make([]Feature, 0, len(features))
+	for _, f := range features {
+		if f != FeatureQueryAPI {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func (s *integrityStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if s.enabled {
+		return nil, errors.New("the query API is not available with integrity checks")
+	}
+	return s.forwarder.Query(ctx, req)
+}
+
+func (s *integrityStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	res, err := s.Store.Get(ctx, req)
+	if err != nil || !s.enabled || res == nil || len(res.Data) == 0 {
+		return res, err
+	}
+
+	res.Data, _, err = verifyChecksum(req.Key, res.Data)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *integrityStore) Set(ctx context.Context, req *SetRequest) error {
+	if !s.enabled {
+		return s.forwarder.Set(ctx, req)
+	}
+
+	r, err := checksumRequest(req)
+	if err != nil {
+		return err
+	}
+	return s.forwarder.Set(ctx, r)
+}
+
+func (s *integrityStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	supported, res, err := s.Store.BulkGet(ctx, req)
+	if err != nil || !supported || !s.enabled {
+		return supported, res, err
+	}
+
+	for i := range res {
+		if res[i].Error != "" || len(res[i].Data) == 0 {
+			continue
+		}
+		data, _, err := verifyChecksum(res[i].Key, res[i].Data)
+		if err != nil {
+			res[i].Data = nil
+			res[i].Error = err.Error()
+			continue
+		}
+		res[i].Data = data
+	}
+	return true, res, nil
+}
+
+func (s *integrityStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	if !s.enabled {
+		return s.Store.BulkSet(ctx, req)
+	}
+
+	checksummed := make([]SetRequest, len(req))
+	for i := range req {
+		r, err := checksumRequest(&req[i])
+		if err != nil {
+			return err
+		}
+		checksummed[i] = *r
+	}
+	return s.Store.BulkSet(ctx, checksummed)
+}
+
+func (s *integrityStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	if !s.enabled {
+		return s.forwarder.Multi(ctx, request)
+	}
+
+	checksummed := *request
+	checksummed.Operations = make([]TransactionalStateOperation, len(request.Operations))
+	for i, o := range request.Operations {
+		checksummed.Operations[i] = o
+		if o.Operation != Upsert {
+			continue
+		}
+
+		var req SetRequest
+		switch r := o.Request.(type) {
+		case SetRequest:
+			req = r
+		case *SetRequest:
+			req = *r
+		default:
+			return fmt.Errorf("unexpected request type %T for upsert operation", o.Request)
+		}
+		r, err := checksumRequest(&req)
+		if err != nil {
+			return err
+		}
+		checksummed.Operations[i].Request = *r
+	}
+	return s.forwarder.Multi(ctx, &checksummed)
+}
+
+// checksumRequest returns a copy of req with its value prefixed with its checksum.
+func checksumRequest(req *SetRequest) (*SetRequest, error) {
+	var value []byte
+	switch v := req.Value.(type) {
+	case []byte:
+		value = v
+	default:
+		var err error
+		value, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sum := checksum(req.Key, value)
+	data := make([]byte, 0, len(checksumPrefix)+len(sum)+1+len(value))
+	data = append(data, checksumPrefix...)
+	data = append(data, sum...)
+	data = append(data, ':')
+	data = append(data, value...)
+
+	r := *req
+	r.Value = data
+	return &r, nil
+}
+
+// checksum returns the hex SHA-256 checksum of the state key and the value, so that values can't be swapped between keys.
+func checksum(key string, value []byte) string {
+	h := sha256.New()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write(value)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyChecksum returns the value without its checksum, and whether it had one.
+func verifyChecksum(key string, data []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(data, []byte(checksumPrefix)) {
+		return data, false, nil
+	}
+	rest := data[len(checksumPrefix):]
+	if len(rest) < sha256.Size*2+1 || rest[sha256.Size*2] != ':' {
+		return nil, true, &IntegrityError{Key: key, reason: "invalid checksum"}
+	}
+	sum, value := rest[:sha256.Size*2], rest[sha256.Size*2+1:]
+	if subtle.ConstantTimeCompare(sum, []byte(checksum(key, value))) != 1 {
+		return nil, true, &IntegrityError{Key: key, reason: "checksum mismatch"}
+	}
+	return value, true, nil
+}
+
+// IntegrityReport is the result of VerifyStoreIntegrity.
+type IntegrityReport struct {
+	// Verified is the number of values matching their checksum.
+	Verified int
+	// Corrupted are the errors of the values not matching their checksum.
+	Corrupted []*IntegrityError
+	// Unverified are the keys of the values without a checksum, saved before the checksums were enabled.
+	Unverified []string
+	// Missing are the keys without a value.
+	Missing []string
+	// Failed are the errors returned by the store for some keys.
+	Failed map[string]string
+}
+
+// VerifyStoreIntegrity reads the values of the keys from the store, without the NewIntegrityStore wrapper, and verifies their checksum.
+// It is meant for offline scans, e.g. with the keys listed from a backup or with the tools of the store, and reads the values in bulk when the store supports it.
+func VerifyStoreIntegrity(ctx context.Context, inner Store, keys []string) (*IntegrityReport, error) {
+	report := &IntegrityReport{Failed: map[string]string{}}
+	for start := 0; start < len(keys); start += verifyIntegrityBatchSize {
+		end := start + verifyIntegrityBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		req := make([]GetRequest, end-start)
+		for i, key := range keys[start:end] {
+			req[i] = GetRequest{Key: key}
+		}
+
+		supported, res, err := inner.BulkGet(ctx, req)
+		if err != nil {
+			return report, err
+		}
+		if !supported {
+			res = make([]BulkGetResponse, len(req))
+			for i := range req {
+				res[i].Key = req[i].Key
+				r, err := inner.Get(ctx, &req[i])
+				if err != nil {
+					res[i].Error = err.Error()
+				} else if r != nil {
+					res[i].Data = r.Data
+				}
+			}
+		}
+		for _, r := range res {
+			switch {
+			case r.Error != "":
+				report.Failed[r.Key] = r.Error
+			case len(r.Data) == 0:
+				report.Missing = append(report.Missing, r.Key)
+			default:
+				_, checksummed, err := verifyChecksum(r.Key, r.Data)
+				var integrityErr *IntegrityError
+				switch {
+				case errors.As(err, &integrityErr):
+					report.Corrupted = append(report.Corrupted, integrityErr)
+				case !checksummed:
+					report.Unverified = append(report.Unverified, r.Key)
+				default:
+					report.Verified++
+				}
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+func initIntegrityStore(t *testing.T, inner Store, props map[string]string) Store {
+	t.Helper()
+	s := NewIntegrityStore(inner)
+	require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+	return s
+}
+
+func TestIntegrityStore(t *testing.T) {
+	props := map[string]string{VerifyIntegrity: "true"}
+
+	t.Run("saves and verifies checksums", func(t *testing.T) {
+		inner := newMemStore()
+		s := initIntegrityStore(t, inner, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: map[string]string{"a": "b"}}))
+		assert.Regexp(t, `^dapr-sha256:[0-9a-f]{64}:\{"a":"b"\}$`, string(inner.items["k"]))
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":"b"}`, string(res.Data))
+	})
+
+	t.Run("detects corrupted values", func(t *testing.T) {
+		inner := newMemStore()
+		s := initIntegrityStore(t, inner, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("value")}))
+		inner.items["k"][len(inner.items["k"])-1] = 'X'
+
+		_, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		assert.ErrorIs(t, err, ErrStateCorrupted)
+		var integrityErr *IntegrityError
+		require.True(t, errors.As(err, &integrityErr))
+		assert.Equal(t, "k", integrityErr.Key)
+
+		inner.items["k"] = []byte(checksumPrefix + "abc:value")
+		_, err = s.Get(context.Background(), &GetRequest{Key: "k"})
+		assert.ErrorIs(t, err, ErrStateCorrupted)
+	})
+
+	t.Run("values are bound to their key", func(t *testing.T) {
+		inner := newMemStore()
+		s := initIntegrityStore(t, inner, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k1", Value: []byte("value")}))
+		inner.items["k2"] = inner.items["k1"]
+
+		_, err := s.Get(context.Background(), &GetRequest{Key: "k2"})
+		assert.ErrorIs(t, err, ErrStateCorrupted)
+	})
+
+	t.Run("values without checksums are returned", func(t *testing.T) {
+		inner := newMemStore()
+		inner.items["old"] = []byte("old value")
+		s := initIntegrityStore(t, inner, props)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "old"})
+		require.NoError(t, err)
+		assert.Equal(t, "old value", string(res.Data))
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		inner := newMemStore()
+		s := initIntegrityStore(t, inner, props)
+
+		err := s.(TransactionalStore).Multi(context.Background(), &TransactionalStateRequest{
+			Operations: []TransactionalStateOperation{
+				{Operation: Upsert, Request: SetRequest{Key: "k", Value: []byte("value")}},
+			},
+		})
+		require.NoError(t, err)
+		_, checksummed, err := verifyChecksum("k", inner.items["k"])
+		require.NoError(t, err)
+		assert.True(t, checksummed)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		inner := newMemStore()
+		s := initIntegrityStore(t, inner, nil)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("value")}))
+		assert.Equal(t, "value", string(inner.items["k"]))
+		assert.Contains(t, s.Features(), FeatureQueryAPI)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		s := NewIntegrityStore(newMemStore())
+		err := s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{VerifyIntegrity: "maybe"}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifyStoreIntegrity(t *testing.T) {
+	inner := newMemStore()
+	s := initIntegrityStore(t, inner, map[string]string{VerifyIntegrity: "true"})
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: key, Value: []byte("value " + key)}))
+	}
+	inner.items["b"] = append(inner.items["b"], '!')
+	inner.items["old"] = []byte("old value")
+
+	report, err := VerifyStoreIntegrity(context.Background(), inner, []string{"a", "b", "c", "old", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Verified)
+	require.Len(t, report.Corrupted, 1)
+	assert.Equal(t, "b", report.Corrupted[0].Key)
+	assert.Equal(t, []string{"old"}, report.Unverified)
+	assert.Equal(t, []string{"missing"}, report.Missing)
+	assert.Empty(t, report.Failed)
+}
@@ -0,0 +1,392 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+const (
+	// SchemaVersion is the metadata key of a store wrapped with NewSchemaMigrationStore setting the current schema
+	// version of the values, a positive integer. The migrations are disabled when it is not set.
+	SchemaVersion = "schemaVersion"
+	// SchemaVersionField is the metadata key of the field of the values holding their schema version.
+	SchemaVersionField = "schemaVersionField"
+	// SchemaMigration is the metadata key of the JMESPath expression migrating the values of any older schema version
+	// to the current one.
+	SchemaMigration = "schemaMigration"
+	// SchemaMigrations is the metadata key of the chain of JMESPath expressions migrating the values from a schema
+	// version to the next one, as a JSON object keyed by version, e.g. {"1": "expression from 1 to 2"}.
+	// Versions without expression don't change the values.
+	SchemaMigrations = "schemaMigrations"
+)
+
+// defaultSchemaVersionField is the default field of the values holding their schema version.
+const defaultSchemaVersionField = "_schemaVersion"
+
+// ErrSchemaMigration is the error, wrapped in a *SchemaMigrationError, returned when a value can't be migrated.
+var ErrSchemaMigration = errors.New("state value schema migration failed")
+
+// SchemaMigrationError is the error returned when the value of a key can't be migrated from its schema version.
+type SchemaMigrationError struct {
+	Key     string
+	Version int
+	err     error
+}
+
+func (e *SchemaMigrationError) Error() string {
+	return fmt.Sprintf("%s: key %s: from version %d: %v", ErrSchemaMigration, e.Key, e.Version, e.err)
+}
+
+func (e *SchemaMigrationError) Unwrap() error {
+	return ErrSchemaMigration
+}
+
+// schemaMigrationStore migrates the values read from the wrapped store from older schema versions, and saves the
+// values with the current schema version.
+type schemaMigrationStore struct {
+	forwarder
+
+	version int
+	field   string
+	// migration migrates the values of any older version, when there is no chain.
+	migration *jmespath.JMESPath
+	// chain migrates the values from a version, the key, to the next one.
+	chain map[int]*jmespath.JMESPath
+}
+
+// NewSchemaMigrationStore wraps a Store so that, when the "schemaVersion" metadata is set, the JSON objects saved are
+// marked with that schema version in the "schemaVersionField" field, and the JSON objects read with an older schema
+// version are migrated with the "schemaMigration" JMESPath expression or the "schemaMigrations" chain of expressions
+// before being returned. The migrated values are saved the next time they are written.
+// Values without a schema version, values with a newer schema version, e.g. written by newer instances during a
+// rolling upgrade, and values which are not JSON objects are returned as they are.
+// Get fails with a *SchemaMigrationError, which wraps ErrSchemaMigration, when a value can't be migrated.
+func NewSchemaMigrationStore(inner Store) Store {
+	s := &schemaMigrationStore{forwarder: forwarder{inner}}
+	return exposeOptional(s, inner)
+}
+
+func (s *schemaMigrationStore) Init(metadata Metadata) error {
+	if err := s.parseMetadata(metadata.Properties); err != nil {
+		return err
+	}
+
+	return s.Store.Init(metadata)
+}
+
+func (s *schemaMigrationStore) parseMetadata(props map[string]string) error {
+	s.version = 0
+	s.field = defaultSchemaVersionField
+	s.migration = nil
+	s.chain = nil
+
+	val := props[SchemaVersion]
+	if val == "" {
+		return nil
+	}
+	version, err := strconv.Atoi(val)
+	if err != nil || version < 1 {
+		return fmt.Errorf("invalid metadata '%s': must be a positive integer", SchemaVersion)
+	}
+	s.version = version
+	if field := props[SchemaVersionField]; field != "" {
+		s.field = field
+	}
+
+	migration, migrations := props[SchemaMigration], props[SchemaMigrations]
+	switch {
+	case migration != "" && migrations != "":
+		return fmt.Errorf("invalid metadata: '%s' and '%s' are mutually exclusive", SchemaMigration, SchemaMigrations)
+	case migration != "":
+		s.migration, err = jmespath.Compile(migration)
+		if err != nil {
+			return fmt.Errorf("invalid metadata '%s': %w", SchemaMigration, err)
+		}
+	case migrations != "":
+		var exprs map[string]string
+		if err = json.Unmarshal([]byte(migrations), &exprs); err != nil {
+			return fmt.Errorf("invalid metadata '%s': must be a JSON object of expressions keyed by version: %w", SchemaMigrations, err)
+		}
+		s.chain = make(map[int]*jmespath.JMESPath, len(exprs))
+		for k, expr := range exprs {
+			from, err := strconv.Atoi(k)
+			if err != nil || from < 1 || from >= s.version {
+				return fmt.Errorf("invalid metadata '%s': invalid version %q: must be a positive integer lower than %d", SchemaMigrations, k, s.version)
+			}
+			s.chain[from], err = jmespath.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid metadata '%s': version %d: %w", SchemaMigrations, from, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *schemaMigrationStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	res, err := s.Store.Get(ctx, req)
+	if err != nil || s.version == 0 || res == nil || len(res.Data) == 0 {
+		return res, err
+	}
+
+	res.Data, err = s.migrate(req.Key, res.Data)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *schemaMigrationStore) Set(ctx context.Context, req *SetRequest) error {
+	if s.version == 0 {
+		return s.forwarder.Set(ctx, req)
+	}
+
+	return s.forwarder.Set(ctx, s.markRequest(req))
+}
+
+func (s *schemaMigrationStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	supported, res, err := s.Store.BulkGet(ctx, req)
+	if err != nil || !supported || s.version == 0 {
+		return supported, res, err
+	}
+
+	for i := range res {
+		if res[i].Error != "" || len(res[i].Data) == 0 {
+			continue
+		}
+		data, err := s.migrate(res[i].Key, res[i].Data)
+		if err != nil {
+			res[i].Data = nil
+			res[i].Error = err.Error()
+			continue
+		}
+		res[i].Data = data
+	}
+	return true, res, nil
+}
+
+func (s *schemaMigrationStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	if s.version == 0 {
+		return s.Store.BulkSet(ctx, req)
+	}
+
+	marked := make([]SetRequest, len(req))
+	for i := range req {
+		marked[i] = *s.markRequest(&req[i])
+	}
+	return s.Store.BulkSet(ctx, marked)
+}
+
+func (s *schemaMigrationStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	if s.version == 0 {
+		return s.forwarder.Multi(ctx, request)
+	}
+
+	marked := *request
+	marked.Operations = make([]TransactionalStateOperation, len(request.Operations))
+	for i, o := range request.Operations {
+		marked.Operations[i] = o
+		if o.Operation != Upsert {
+			continue
+		}
+
+		switch r := o.Request.(type) {
+		case SetRequest:
+			marked.Operations[i].Request = *s.markRequest(&r)
+		case *SetRequest:
+			marked.Operations[i].Request = *s.markRequest(r)
+		default:
+			return fmt.Errorf("unexpected request type %T for upsert operation", o.Request)
+		}
+	}
+	return s.forwarder.Multi(ctx, &marked)
+}
+
+func (s *schemaMigrationStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	res, err := s.forwarder.Query(ctx, req)
+	if err != nil || s.version == 0 || res == nil {
+		return res, err
+	}
+
+	for i := range res.Results {
+		if res.Results[i].Error != "" || len(res.Results[i].Data) == 0 {
+			continue
+		}
+		data, err := s.migrate(res.Results[i].Key, res.Results[i].Data)
+		if err != nil {
+			res.Results[i].Data = nil
+			res.Results[i].Error = err.Error()
+			continue
+		}
+		res.Results[i].Data = data
+	}
+	return res, nil
+}
+
+// migrate returns the value migrated to the current schema version, or the value as it is when it is not a JSON
+// object with an older schema version.
+func (s *schemaMigrationStore) migrate(key string, data []byte) ([]byte, error) {
+	obj, err := unmarshalJSONObject(data)
+	if err != nil || obj == nil {
+		return data, nil
+	}
+	version, ok := schemaVersionOf(obj[s.field])
+	if !ok || version >= s.version {
+		return data, nil
+	}
+
+	var value interface{} = obj
+	if s.migration != nil {
+		value, err = s.migration.Search(value)
+		if err != nil {
+			return nil, &SchemaMigrationError{Key: key, Version: version, err: err}
+		}
+	} else {
+		for from := version; from < s.version; from++ {
+			expr, ok := s.chain[from]
+			if !ok {
+				continue
+			}
+			if value, err = expr.Search(value); err != nil {
+				return nil, &SchemaMigrationError{Key: key, Version: version, err: fmt.Errorf("to version %d: %w", from+1, err)}
+			}
+		}
+	}
+
+	migrated, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &SchemaMigrationError{Key: key, Version: version, err: fmt.Errorf("the migration returned a %T instead of an object", value)}
+	}
+	migrated[s.field] = s.version
+	return json.Marshal(migrated)
+}
+
+// markRequest returns a copy of req with its value marked with the current schema version if it is a JSON object
+// without a schema version, or req as it is.
+func (s *schemaMigrationStore) markRequest(req *SetRequest) *SetRequest {
+	var data []byte
+	switch v := req.Value.(type) {
+	case []byte:
+		data = v
+	case string, nil:
+		return req
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return req
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(data, &obj) != nil || obj == nil {
+		return req
+	}
+	if _, ok := obj[s.field]; ok {
+		return req
+	}
+
+	// The field is spliced in the object, so that its members are saved exactly as they are.
+	field, err := json.Marshal(s.field)
+	if err != nil {
+		return req
+	}
+	marked := make([]byte, 0, len(data)+len(field)+8)
+	marked = append(marked, '{')
+	marked = append(marked, field...)
+	marked = append(marked, ':')
+	marked = strconv.AppendInt(marked, int64(s.version), 10)
+	if len(obj) > 0 {
+		marked = append(marked, ',')
+	}
+	marked = append(marked, data[1:]...)
+
+	r := *req
+	r.Value = marked
+	return &r
+}
+
+// unmarshalJSONObject decodes a JSON object, or null. The numbers that a float64 can't hold exactly, such as integers
+// above 2^53, are kept as json.Number so that they are encoded again as they were.
+func unmarshalJSONObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON object")
+	}
+	for k, v := range obj {
+		obj[k] = exactNumbers(v)
+	}
+	return obj, nil
+}
+
+// exactNumbers replaces the json.Number values with float64, except the integers that a float64 can't hold exactly.
+func exactNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			if i >= -1<<53 && i <= 1<<53 {
+				return float64(i)
+			}
+			return val
+		}
+		// Fractions and exponents, but not the integers out of the int64 range
+		if strings.ContainsAny(val.String(), ".eE") {
+			if f, err := val.Float64(); err == nil {
+				return f
+			}
+		}
+		return val
+	case map[string]interface{}:
+		for k, e := range val {
+			val[k] = exactNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range val {
+			val[i] = exactNumbers(e)
+		}
+	}
+	return v
+}
+
+// schemaVersionOf returns the schema version of a value's schema version field, a JSON number or string.
+func schemaVersionOf(v interface{}) (int, bool) {
+	switch version := v.(type) {
+	case float64:
+		if version != math.Trunc(version) {
+			return 0, false
+		}
+		return int(version), true
+	case string:
+		n, err := strconv.Atoi(version)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+func initSchemaMigrationStore(t *testing.T, inner Store, props map[string]string) Store {
+	t.Helper()
+	s := NewSchemaMigrationStore(inner)
+	require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+	return s
+}
+
+func TestSchemaMigrationStore(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		inner := newMemStore()
+		s := initSchemaMigrationStore(t, inner, map[string]string{})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte(`{"a":"b"}`)}))
+		assert.Equal(t, `{"a":"b"}`, string(inner.items["k"]))
+	})
+
+	t.Run("marks the saved objects with the schema version", func(t *testing.T) {
+		inner := newMemStore()
+		s := initSchemaMigrationStore(t, inner, map[string]string{SchemaVersion: "2"})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k1", Value: map[string]string{"a": "b"}}))
+		assert.JSONEq(t, `{"a":"b","_schemaVersion":2}`, string(inner.items["k1"]))
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k2", Value: []byte(`{"a":"b","_schemaVersion":3}`)}))
+		assert.JSONEq(t, `{"a":"b","_schemaVersion":3}`, string(inner.items["k2"]))
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k3", Value: []byte(`not json`)}))
+		assert.Equal(t, `not json`, string(inner.items["k3"]))
+
+		require.NoError(t, s.BulkSet(context.Background(), []SetRequest{{Key: "k4", Value: []byte(`{"a":1}`)}}))
+		assert.JSONEq(t, `{"a":1,"_schemaVersion":2}`, string(inner.items["k4"]))
+
+		err := s.(TransactionalStore).Multi(context.Background(), &TransactionalStateRequest{
+			Operations: []TransactionalStateOperation{
+				{Operation: Upsert, Request: SetRequest{Key: "k5", Value: []byte(`{"a":1}`)}},
+			},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":1,"_schemaVersion":2}`, string(inner.items["k5"]))
+	})
+
+	t.Run("migrates older values with an expression", func(t *testing.T) {
+		inner := newMemStore()
+		s := initSchemaMigrationStore(t, inner, map[string]string{
+			SchemaVersion:      "2",
+			SchemaVersionField: "v",
+			SchemaMigration:    `{name: join(' ', [firstName, lastName]), email: email}`,
+		})
+		inner.items["old"] = []byte(`{"v":1,"firstName":"Ada","lastName":"Lovelace","email":"ada@example.com"}`)
+		inner.items["current"] = []byte(`{"v":2,"name":"Alan Turing"}`)
+		inner.items["newer"] = []byte(`{"v":3,"fullName":"Grace Hopper"}`)
+		inner.items["unmarked"] = []byte(`{"firstName":"Edsger"}`)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "old"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"v":2,"name":"Ada Lovelace","email":"ada@example.com"}`, string(res.Data))
+
+		for _, k := range []string{"current", "newer", "unmarked"} {
+			res, err = s.Get(context.Background(), &GetRequest{Key: k})
+			require.NoError(t, err)
+			assert.Equal(t, string(inner.items[k]), string(res.Data))
+		}
+	})
+
+	t.Run("keeps large numbers", func(t *testing.T) {
+		inner := newMemStore()
+		s := initSchemaMigrationStore(t, inner, map[string]string{
+			SchemaVersion:   "2",
+			SchemaMigration: `merge(@, {migrated: ` + "`true`" + `})`,
+		})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "new", Value: []byte(` {"id":9007199254740993,"n":1.50} `)}))
+		assert.Equal(t, `{"_schemaVersion":2,"id":9007199254740993,"n":1.50}`, string(inner.items["new"]))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "empty", Value: []byte(`{}`)}))
+		assert.Equal(t, `{"_schemaVersion":2}`, string(inner.items["empty"]))
+
+		inner.items["old"] = []byte(`{"_schemaVersion":1,"id":9007199254740993,"ids":[18446744073709551617]}`)
+		res, err := s.Get(context.Background(), &GetRequest{Key: "old"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"_schemaVersion":2,"id":9007199254740993,"ids":[18446744073709551617],"migrated":true}`, string(res.Data))
+		assert.Contains(t, string(res.Data), `9007199254740993`)
+	})
+
+	t.Run("migrates older values with a chain", func(t *testing.T) {
+		inner := newMemStore()
+		s := initSchemaMigrationStore(t, inner, map[string]string{
+			SchemaVersion: "4",
+			SchemaMigrations: `{
+				"1": "merge(@, {tags: [category]})",
+				"3": "{_schemaVersion: _schemaVersion, title: name, tags: tags}"
+			}`,
+		})
+		inner.items["v1"] = []byte(`{"_schemaVersion":1,"name":"a","category":"x"}`)
+		inner.items["v2"] = []byte(`{"_schemaVersion":"2","name":"b","tags":["y"]}`)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "v1"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"_schemaVersion":4,"title":"a","tags":["x"]}`, string(res.Data))
+
+		res, err = s.Get(context.Background(), &GetRequest{Key: "v2"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"_schemaVersion":4,"title":"b","tags":["y"]}`, string(res.Data))
+	})
+
+	t.Run("fails when the migration does not return an object", func(t *testing.T) {
+		inner := newMemStore()
+		s := initSchemaMigrationStore(t, inner, map[string]string{
+			SchemaVersion:   "2",
+			SchemaMigration: "name",
+		})
+		inner.items["k"] = []byte(`{"_schemaVersion":1,"name":"a"}`)
+
+		_, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		assert.ErrorIs(t, err, ErrSchemaMigration)
+		var migrationErr *SchemaMigrationError
+		require.True(t, errors.As(err, &migrationErr))
+		assert.Equal(t, "k", migrationErr.Key)
+		assert.Equal(t, 1, migrationErr.Version)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for _, props := range []map[string]string{
+			{SchemaVersion: "0"},
+			{SchemaVersion: "two"},
+			{SchemaVersion: "2", SchemaMigration: "a", SchemaMigrations: `{"1":"a"}`},
+			{SchemaVersion: "2", SchemaMigration: "{a:"},
+			{SchemaVersion: "2", SchemaMigrations: `["a"]`},
+			{SchemaVersion: "2", SchemaMigrations: `{"2":"a"}`},
+		} {
+			err := NewSchemaMigrationStore(newMemStore()).Init(Metadata{Base: metadata.Base{Properties: props}})
+			assert.Error(t, err, props)
+		}
+	})
+}
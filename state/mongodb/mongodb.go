@@ -16,12 +16,16 @@ package mongodb
 // mongodb package is an implementation of StateStore interface to perform operations on store
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,11 +34,14 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 
+	"github.com/dapr/components-contrib/internal/redact"
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/components-contrib/state/query"
+	stateutils "github.com/dapr/components-contrib/state/utils"
 	"github.com/dapr/kit/logger"
 	"github.com/dapr/kit/ptr"
 )
@@ -51,10 +58,13 @@ const (
 	id               = "_id"
 	value            = "value"
 	etag             = "_etag"
+	ttl              = "_ttl"
+	deleted          = "_deleted"
 
 	defaultTimeout        = 5 * time.Second
 	defaultDatabaseName   = "daprStore"
 	defaultCollectionName = "daprCollection"
+	defaultSoftDeleteTTL  = 7 * 24 * time.Hour
 
 	// mongodb://<username>:<password@<host>/<database><params>
 	connectionURIFormatWithAuthentication = "mongodb://%s:%s@%s/%s%s"
@@ -90,8 +100,13 @@ type mongoDBMetadata struct {
 	Server           string
 	Writeconcern     string
 	Readconcern      string
+	ReadPreference   string
 	Params           string
 	OperationTimeout time.Duration
+	// Keep deleted documents as tombstones instead of removing them.
+	SoftDelete bool
+	// Time after which tombstones are removed by the server.
+	SoftDeleteRetention time.Duration
 }
 
 // Item is Mongodb document wrapper.
@@ -99,12 +114,13 @@ type Item struct {
 	Key   string      `bson:"_id"`
 	Value interface{} `bson:"value"`
 	Etag  string      `bson:"_etag"`
+	TTL   *time.Time  `bson:"_ttl,omitempty"`
 }
 
 // NewMongoDB returns a new MongoDB state store.
 func NewMongoDB(logger logger.Logger) state.Store {
 	s := &MongoDB{
-		features: []state.Feature{state.FeatureETag, state.FeatureTransactional, state.FeatureQueryAPI},
+		features: []state.Feature{state.FeatureETag, state.FeatureTransactional, state.FeatureQueryAPI, state.FeatureSessionToken, state.FeatureTTL},
 		logger:   logger,
 	}
 	s.DefaultBulkStore = state.NewDefaultBulkStore(s)
@@ -127,7 +143,7 @@ func (m *MongoDB) Init(metadata state.Metadata) error {
 	}
 
 	if err = client.Ping(context.Background(), nil); err != nil {
-		return fmt.Errorf("error in connecting to mongodb, host: %s error: %s", meta.Host, err)
+		return fmt.Errorf("error in connecting to mongodb, host: %s error: %s", redact.ConnectionString(meta.Host), err)
 	}
 
 	m.client = client
@@ -144,12 +160,32 @@ func (m *MongoDB) Init(metadata state.Metadata) error {
 		return fmt.Errorf("error in getting read concern object: %s", err)
 	}
 
+	// get the read preference
+	rp, err := getReadPreferenceObject(meta.ReadPreference)
+	if err != nil {
+		return fmt.Errorf("error in getting read preference object: %s", err)
+	}
+
 	m.metadata = *meta
-	opts := options.Collection().SetWriteConcern(wc).SetReadConcern(rc)
+	opts := options.Collection().SetWriteConcern(wc).SetReadConcern(rc).SetReadPreference(rp)
 	collection := m.client.Database(meta.DatabaseName).Collection(meta.CollectionName, opts)
 
 	m.collection = collection
 
+	// Documents are removed by the server once their "_ttl" date is reached.
+	// This also removes tombstones once their retention is over.
+	// Some servers (such as the Mongo API of Azure Cosmos DB) or users can't create this index: expired
+	// documents are still never returned, but they stay in the collection.
+	ctx, cancel := context.WithTimeout(context.Background(), m.operationTimeout)
+	defer cancel()
+	_, err = m.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: ttl, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		m.logger.Warnf("Could not create the ttl index, expired documents will not be removed from the collection: %v", err)
+	}
+
 	return nil
 }
 
@@ -160,6 +196,11 @@ func (m *MongoDB) Features() []state.Feature {
 
 // Set saves state into MongoDB.
 func (m *MongoDB) Set(ctx context.Context, req *state.SetRequest) error {
+	if req.Metadata[state.SessionTokenMetadataKey] != "" {
+		_, err := m.SetWithSessionToken(ctx, req)
+		return err
+	}
+
 	err := m.setInternal(ctx, req)
 	if err != nil {
 		return err
@@ -168,9 +209,16 @@ func (m *MongoDB) Set(ctx context.Context, req *state.SetRequest) error {
 	return nil
 }
 
+// SetWithSessionToken saves state into MongoDB in a causally consistent session and returns the session token after the write.
+func (m *MongoDB) SetWithSessionToken(ctx context.Context, req *state.SetRequest) (string, error) {
+	return m.withSession(ctx, req.Metadata, func(ctx context.Context) error {
+		return m.setInternal(ctx, req)
+	})
+}
+
 func (m *MongoDB) Ping() error {
 	if err := m.client.Ping(context.Background(), nil); err != nil {
-		return fmt.Errorf("mongoDB store: error connecting to mongoDB at %s: %s", m.metadata.Host, err)
+		return fmt.Errorf("mongoDB store: error connecting to mongoDB at %s: %s", redact.ConnectionString(m.metadata.Host), err)
 	}
 
 	return nil
@@ -180,7 +228,7 @@ func (m *MongoDB) setInternal(ctx context.Context, req *state.SetRequest) error
 	var v interface{}
 	switch obj := req.Value.(type) {
 	case []byte:
-		v = string(obj)
+		v = jsonToBSON(obj)
 	case string:
 		v = fmt.Sprintf("%q", obj)
 	default:
@@ -191,22 +239,163 @@ func (m *MongoDB) setInternal(ctx context.Context, req *state.SetRequest) error
 	filter := bson.M{id: req.Key}
 	if req.ETag != nil {
 		filter[etag] = *req.ETag
+		filter[deleted] = bson.M{"$exists": false}
 	} else if req.Options.Concurrency == state.FirstWrite {
-		filter[etag] = uuid.NewString()
+		// The random etag matches no document, except tombstones which can be overwritten.
+		filter["$or"] = bson.A{
+			bson.M{etag: uuid.NewString()},
+			bson.M{deleted: bson.M{"$exists": true}},
+		}
+	}
+
+	reqTTL, err := stateutils.ParseTTL(req.Metadata)
+	if err != nil {
+		return fmt.Errorf("error parsing TTL: %w", err)
 	}
 
 	update := bson.M{"$set": bson.M{id: req.Key, value: v, etag: uuid.NewString()}}
-	_, err := m.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if reqTTL != nil && *reqTTL > 0 {
+		update["$set"].(bson.M)[ttl] = time.Now().UTC().Add(time.Duration(*reqTTL) * time.Second)
+		update["$unset"] = bson.M{deleted: ""}
+	} else {
+		update["$unset"] = bson.M{ttl: "", deleted: ""}
+	}
+	_, err = m.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
 
 	return err
 }
 
+// errInvalidFieldName is returned for JSON keys which can't be document field names.
+var errInvalidFieldName = errors.New("invalid field name")
+
+// jsonToBSON returns the BSON document for a JSON object, so it can be queried with Mongo tools.
+// The object is decoded as plain JSON, not Extended JSON, so "$date" or "$oid" values are kept as they are.
+// Any other payload, or an object with "$"-prefixed or dotted keys, is stored as a string.
+func jsonToBSON(data []byte) interface{} {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return string(data)
+	}
+	doc, err := decodeJSONObject(dec)
+	if err != nil {
+		return string(data)
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return string(data)
+	}
+	return doc
+}
+
+// decodeJSONObject decodes the members of a JSON object, keeping their order, after its opening brace.
+func decodeJSONObject(dec *json.Decoder) (bson.D, error) {
+	doc := bson.D{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := t.(string)
+		if !ok || strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			return nil, errInvalidFieldName
+		}
+		v, err := decodeJSONValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		doc = append(doc, bson.E{Key: key, Value: v})
+	}
+	// Closing brace
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '{' {
+			return decodeJSONObject(dec)
+		}
+		arr := bson.A{}
+		for dec.More() {
+			e, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, e)
+		}
+		// Closing bracket
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i), nil
+			}
+			return i, nil
+		}
+		return v.Float64()
+	default:
+		// string, bool or nil
+		return v, nil
+	}
+}
+
 // Get retrieves state from MongoDB with a key.
+// With a session token, the read runs in a causally consistent session and the response carries the new session token.
 func (m *MongoDB) Get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
+	if req.Metadata[state.SessionTokenMetadataKey] == "" {
+		return m.get(ctx, req)
+	}
+
+	res := &state.GetResponse{}
+	token, err := m.withSession(ctx, req.Metadata, func(ctx context.Context) (err error) {
+		res, err = m.get(ctx, req)
+		return err
+	})
+	if err != nil {
+		return res, err
+	}
+	if res.Metadata == nil {
+		res.Metadata = map[string]string{}
+	}
+	res.Metadata[state.SessionTokenMetadataKey] = token
+	return res, nil
+}
+
+func (m *MongoDB) get(ctx context.Context, req *state.GetRequest) (*state.GetResponse, error) {
 	var result Item
 
-	filter := bson.M{id: req.Key}
-	err := m.collection.FindOne(ctx, filter).Decode(&result)
+	fields, err := stateutils.ParseFields(req.Metadata)
+	if err != nil {
+		return &state.GetResponse{}, err
+	}
+
+	// Expired documents may not have been removed by the server yet
+	filter := bson.M{id: req.Key, deleted: bson.M{"$exists": false}, "$or": bson.A{
+		bson.M{ttl: bson.M{"$exists": false}},
+		bson.M{ttl: bson.M{"$gt": time.Now().UTC()}},
+	}}
+	findOpts := options.FindOne()
+	if len(fields) > 0 {
+		findOpts.SetProjection(fieldsProjection(fields))
+	}
+	err = m.collection.FindOne(ctx, filter, findOpts).Decode(&result)
+	if err == nil && len(fields) > 0 && result.Value == nil {
+		// Values stored as strings can't be projected by the server: fetch the whole document and project it here.
+		err = m.collection.FindOne(ctx, filter).Decode(&result)
+		if err == nil {
+			return m.projectedResponse(&result, fields)
+		}
+	}
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			// Key not found, not an error.
@@ -252,13 +441,62 @@ func (m *MongoDB) Get(ctx context.Context, req *state.GetRequest) (*state.GetRes
 	}
 
 	return &state.GetResponse{
-		Data: data,
-		ETag: ptr.Of(result.Etag),
+		Data:     data,
+		ETag:     ptr.Of(result.Etag),
+		Metadata: itemMetadata(&result),
+	}, nil
+}
+
+// itemMetadata returns the metadata of a document: its expiration time, if any.
+func itemMetadata(item *Item) map[string]string {
+	if item.TTL == nil {
+		return nil
+	}
+	return map[string]string{
+		state.GetResponseMetadataExpireTime: item.TTL.UTC().Format(time.RFC3339),
+	}
+}
+
+// fieldsProjection builds a projection returning only the given paths of the stored value, the etag and the expiration time.
+func fieldsProjection(fields [][]string) bson.D {
+	projection := bson.D{{Key: etag, Value: 1}, {Key: ttl, Value: 1}}
+	for _, f := range fields {
+		projection = append(projection, bson.E{Key: value + "." + strings.Join(f, "."), Value: 1})
+	}
+	return projection
+}
+
+func (m *MongoDB) projectedResponse(result *Item, fields [][]string) (*state.GetResponse, error) {
+	var data []byte
+	switch obj := result.Value.(type) {
+	case string:
+		data = []byte(obj)
+	default:
+		var err error
+		if data, err = json.Marshal(result.Value); err != nil {
+			return &state.GetResponse{}, err
+		}
+	}
+
+	data, err := stateutils.ProjectJSON(data, fields)
+	if err != nil {
+		return &state.GetResponse{}, err
+	}
+
+	return &state.GetResponse{
+		Data:     data,
+		ETag:     ptr.Of(result.Etag),
+		Metadata: itemMetadata(result),
 	}, nil
 }
 
 // Delete performs a delete operation.
 func (m *MongoDB) Delete(ctx context.Context, req *state.DeleteRequest) error {
+	if req.Metadata[state.SessionTokenMetadataKey] != "" {
+		_, err := m.DeleteWithSessionToken(ctx, req)
+		return err
+	}
+
 	err := m.deleteInternal(ctx, req)
 	if err != nil {
 		return err
@@ -267,11 +505,21 @@ func (m *MongoDB) Delete(ctx context.Context, req *state.DeleteRequest) error {
 	return nil
 }
 
+// DeleteWithSessionToken performs a delete operation in a causally consistent session and returns the session token after the delete.
+func (m *MongoDB) DeleteWithSessionToken(ctx context.Context, req *state.DeleteRequest) (string, error) {
+	return m.withSession(ctx, req.Metadata, func(ctx context.Context) error {
+		return m.deleteInternal(ctx, req)
+	})
+}
+
 func (m *MongoDB) deleteInternal(ctx context.Context, req *state.DeleteRequest) error {
 	filter := bson.M{id: req.Key}
 	if req.ETag != nil {
 		filter[etag] = *req.ETag
 	}
+	if m.metadata.SoftDelete {
+		return m.softDelete(ctx, filter, req.ETag != nil)
+	}
 	result, err := m.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
@@ -284,6 +532,30 @@ func (m *MongoDB) deleteInternal(ctx context.Context, req *state.DeleteRequest)
 	return nil
 }
 
+// softDelete replaces the document matching filter with a tombstone, which the server removes once the retention is over.
+func (m *MongoDB) softDelete(ctx context.Context, filter bson.M, hasETag bool) error {
+	filter[deleted] = bson.M{"$exists": false}
+	result, err := m.collection.UpdateOne(ctx, filter, tombstoneUpdate(time.Now().UTC(), m.metadata.SoftDeleteRetention))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 && hasETag {
+		return errors.New("key or etag not found")
+	}
+
+	return nil
+}
+
+// tombstoneUpdate returns the update turning a document into a tombstone deleted at now.
+// The value is dropped, and a new etag invalidates the ones handed out before the deletion.
+func tombstoneUpdate(now time.Time, retention time.Duration) bson.M {
+	return bson.M{
+		"$set":   bson.M{deleted: now, ttl: now.Add(retention), etag: uuid.NewString()},
+		"$unset": bson.M{value: ""},
+	}
+}
+
 // Multi performs a transactional operation. succeeds only if all operations succeed, and fails if one or more operations fail.
 func (m *MongoDB) Multi(ctx context.Context, request *state.TransactionalStateRequest) error {
 	sess, err := m.client.StartSession()
@@ -296,6 +568,13 @@ func (m *MongoDB) Multi(ctx context.Context, request *state.TransactionalStateRe
 		return fmt.Errorf("error in starting the transaction: %s", err)
 	}
 
+	if token := request.Metadata[state.SessionTokenMetadataKey]; token != "" {
+		err = advanceSession(sess, token)
+		if err != nil {
+			return err
+		}
+	}
+
 	sess.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
 		err = m.doTransaction(sessCtx, request.Operations)
 
@@ -328,7 +607,7 @@ func (m *MongoDB) doTransaction(sessCtx mongo.SessionContext, operations []state
 
 // Query executes a query against store.
 func (m *MongoDB) Query(ctx context.Context, req *state.QueryRequest) (*state.QueryResponse, error) {
-	q := &Query{}
+	q := &Query{softDelete: m.metadata.SoftDelete}
 	qbuilder := query.NewQueryBuilder(q)
 	if err := qbuilder.BuildQuery(&req.Query); err != nil {
 		return &state.QueryResponse{}, err
@@ -387,9 +666,10 @@ func getMongoDBClient(metadata *mongoDBMetadata) (*mongo.Client, error) {
 
 func getMongoDBMetaData(meta state.Metadata) (*mongoDBMetadata, error) {
 	m := mongoDBMetadata{
-		DatabaseName:     defaultDatabaseName,
-		CollectionName:   defaultCollectionName,
-		OperationTimeout: defaultTimeout,
+		DatabaseName:        defaultDatabaseName,
+		CollectionName:      defaultCollectionName,
+		OperationTimeout:    defaultTimeout,
+		SoftDeleteRetention: defaultSoftDeleteTTL,
 	}
 
 	decodeErr := metadata.DecodeMetadata(meta.Properties, &m)
@@ -413,6 +693,10 @@ func getMongoDBMetaData(meta state.Metadata) (*mongoDBMetadata, error) {
 		}
 	}
 
+	if m.SoftDeleteRetention < 0 {
+		return nil, errors.New("softDeleteRetention must not be negative")
+	}
+
 	return &m, nil
 }
 
@@ -453,6 +737,18 @@ func getReadConcernObject(cn string) (*readconcern.ReadConcern, error) {
 	return nil, fmt.Errorf("readConcern %s not found", cn)
 }
 
+func getReadPreferenceObject(cn string) (*readpref.ReadPref, error) {
+	if cn == "" {
+		return readpref.Primary(), nil
+	}
+
+	mode, err := readpref.ModeFromString(cn)
+	if err != nil {
+		return nil, fmt.Errorf("readPreference %s not found", cn)
+	}
+	return readpref.New(mode)
+}
+
 func (m *MongoDB) GetComponentMetadata() map[string]string {
 	metadataStruct := mongoDBMetadata{}
 	metadataInfo := map[string]string{}
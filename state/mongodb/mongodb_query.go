@@ -33,6 +33,8 @@ type Query struct {
 	query  string
 	filter interface{}
 	opts   *options.FindOptions
+	// Exclude tombstones from the results.
+	softDelete bool
 }
 
 func (q *Query) VisitEQ(f *query.EQ) (string, error) {
@@ -151,7 +153,11 @@ func (q *Query) Finalize(filters string, qq *query.Query) error {
 }
 
 func (q *Query) execute(ctx context.Context, collection *mongo.Collection) ([]state.QueryItem, string, error) {
-	cur, err := collection.Find(ctx, q.filter, []*options.FindOptions{q.opts}...)
+	filter := q.filter
+	if q.softDelete {
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, bson.M{deleted: bson.M{"$exists": false}}}}}
+	}
+	cur, err := collection.Find(ctx, filter, []*options.FindOptions{q.opts}...)
 	if err != nil {
 		return nil, "", err
 	}
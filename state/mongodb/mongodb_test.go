@@ -15,8 +15,11 @@ package mongodb
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
@@ -36,6 +39,28 @@ func TestGetMongoDBMetadata(t *testing.T) {
 		assert.Equal(t, properties[host], metadata.Host)
 		assert.Equal(t, defaultDatabaseName, metadata.DatabaseName)
 		assert.Equal(t, defaultCollectionName, metadata.CollectionName)
+		assert.False(t, metadata.SoftDelete)
+		assert.Equal(t, defaultSoftDeleteTTL, metadata.SoftDeleteRetention)
+	})
+
+	t.Run("With soft delete", func(t *testing.T) {
+		properties := map[string]string{
+			host:                  "127.0.0.1",
+			"softDelete":          "true",
+			"softDeleteRetention": "72h",
+		}
+		m := state.Metadata{
+			Base: metadata.Base{Properties: properties},
+		}
+
+		metadata, err := getMongoDBMetaData(m)
+		assert.Nil(t, err)
+		assert.True(t, metadata.SoftDelete)
+		assert.Equal(t, 72*time.Hour, metadata.SoftDeleteRetention)
+
+		m.Properties["softDeleteRetention"] = "-1h"
+		_, err = getMongoDBMetaData(m)
+		assert.Error(t, err)
 	})
 
 	t.Run("With custom values", func(t *testing.T) {
@@ -188,3 +213,78 @@ func TestGetMongoDBMetadata(t *testing.T) {
 		assert.Equal(t, expected, err.Error())
 	})
 }
+
+func TestFieldsProjection(t *testing.T) {
+	projection := fieldsProjection([][]string{{"name"}, {"address", "city"}})
+	assert.Equal(t, bson.D{
+		{Key: etag, Value: 1},
+		{Key: ttl, Value: 1},
+		{Key: "value.name", Value: 1},
+		{Key: "value.address.city", Value: 1},
+	}, projection)
+}
+
+func TestProjectedResponse(t *testing.T) {
+	m := &MongoDB{}
+	res, err := m.projectedResponse(&Item{
+		Value: `{"name":"dapr","address":{"city":"Seattle","zip":"98101"},"tags":["a"]}`,
+		Etag:  "1",
+	}, [][]string{{"address", "city"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"address":{"city":"Seattle"}}`, string(res.Data))
+	assert.Equal(t, "1", *res.ETag)
+}
+
+func TestJSONToBSON(t *testing.T) {
+	t.Run("JSON object is stored as a document", func(t *testing.T) {
+		v := jsonToBSON([]byte(`{"name":"dapr","count":3}`))
+		assert.Equal(t, bson.D{{Key: "name", Value: "dapr"}, {Key: "count", Value: int32(3)}}, v)
+	})
+
+	t.Run("JSON object with invalid field names is stored as a string", func(t *testing.T) {
+		for _, data := range []string{`{"$set":1}`, `{"a.b":1}`, `{"a":{"$date":"2023-01-02T03:04:05Z"}}`} {
+			assert.Equal(t, data, jsonToBSON([]byte(data)))
+		}
+	})
+
+	t.Run("JSON numbers keep their type", func(t *testing.T) {
+		v := jsonToBSON([]byte(`{"big":5000000000,"pi":3.14,"null":null,"l":[true,"x",{"k":1}]}`))
+		assert.Equal(t, bson.D{
+			{Key: "big", Value: int64(5000000000)},
+			{Key: "pi", Value: 3.14},
+			{Key: "null", Value: nil},
+			{Key: "l", Value: bson.A{true, "x", bson.D{{Key: "k", Value: int32(1)}}}},
+		}, v)
+	})
+
+	t.Run("Other payloads are stored as strings", func(t *testing.T) {
+		assert.Equal(t, "hello world", jsonToBSON([]byte("hello world")))
+		assert.Equal(t, `[1,2]`, jsonToBSON([]byte(`[1,2]`)))
+		assert.Equal(t, `"dapr"`, jsonToBSON([]byte(`"dapr"`)))
+		assert.Equal(t, `{"a":1} {"b":2}`, jsonToBSON([]byte(`{"a":1} {"b":2}`)))
+	})
+}
+
+func TestGetReadPreferenceObject(t *testing.T) {
+	rp, err := getReadPreferenceObject("")
+	assert.NoError(t, err)
+	assert.Equal(t, readpref.PrimaryMode, rp.Mode())
+
+	rp, err = getReadPreferenceObject("secondaryPreferred")
+	assert.NoError(t, err)
+	assert.Equal(t, readpref.SecondaryPreferredMode, rp.Mode())
+
+	_, err = getReadPreferenceObject("fastest")
+	assert.Error(t, err)
+}
+
+func TestTombstoneUpdate(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	update := tombstoneUpdate(now, time.Hour)
+
+	set := update["$set"].(bson.M)
+	assert.Equal(t, now, set[deleted])
+	assert.Equal(t, now.Add(time.Hour), set[ttl])
+	assert.NotEmpty(t, set[etag])
+	assert.Equal(t, bson.M{value: ""}, update["$unset"])
+}
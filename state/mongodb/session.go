@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/dapr/components-contrib/state"
+)
+
+// sessionToken is the causal consistency state of a session, handed out to clients as base64 of its BSON document.
+// A session advanced to the operation and cluster times of a previous session reads at least what it has written,
+// as long as both use majority read and write concerns.
+type sessionToken struct {
+	OperationTime primitive.Timestamp `bson:"operationTime"`
+	ClusterTime   bson.Raw            `bson:"clusterTime,omitempty"`
+}
+
+// encodeSessionToken returns the session token after the last operation of sess.
+func encodeSessionToken(sess mongo.Session) (string, error) {
+	t := sessionToken{ClusterTime: sess.ClusterTime()}
+	if ot := sess.OperationTime(); ot != nil {
+		t.OperationTime = *ot
+	} else if t.ClusterTime == nil {
+		return "", nil
+	}
+	b, err := bson.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// advanceSession advances the operation and cluster times of sess to the ones of the session token.
+func advanceSession(sess mongo.Session, token string) error {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("invalid session token: %w", err)
+	}
+	var t sessionToken
+	err = bson.Unmarshal(b, &t)
+	if err != nil {
+		return fmt.Errorf("invalid session token: %w", err)
+	}
+	if !t.OperationTime.IsZero() {
+		err = sess.AdvanceOperationTime(&t.OperationTime)
+		if err != nil {
+			return err
+		}
+	}
+	if t.ClusterTime != nil {
+		return sess.AdvanceClusterTime(t.ClusterTime)
+	}
+	return nil
+}
+
+// withSession runs fn in a causally consistent session, advanced to the session token of the request metadata if any,
+// and returns the session token after fn.
+func (m *MongoDB) withSession(ctx context.Context, reqMetadata map[string]string, fn func(ctx context.Context) error) (string, error) {
+	sess, err := m.client.StartSession(options.Session().SetCausalConsistency(true))
+	if err != nil {
+		return "", fmt.Errorf("error in starting the session: %w", err)
+	}
+	defer sess.EndSession(ctx)
+
+	if token := reqMetadata[state.SessionTokenMetadataKey]; token != "" {
+		err = advanceSession(sess, token)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = mongo.WithSession(ctx, sess, func(sessCtx mongo.SessionContext) error {
+		return fn(sessCtx)
+	})
+	if err != nil {
+		return "", err
+	}
+	return encodeSessionToken(sess)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSessionToken(t *testing.T) {
+	client, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	require.NoError(t, client.Connect(context.Background()))
+	defer client.Disconnect(context.Background())
+
+	sess, err := client.StartSession()
+	require.NoError(t, err)
+	defer sess.EndSession(context.Background())
+
+	token, err := encodeSessionToken(sess)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+
+	clusterTime, err := bson.Marshal(bson.M{"$clusterTime": bson.M{"clusterTime": primitive.Timestamp{T: 1672531200, I: 3}}})
+	require.NoError(t, err)
+	require.NoError(t, sess.AdvanceOperationTime(&primitive.Timestamp{T: 1672531200, I: 2}))
+	require.NoError(t, sess.AdvanceClusterTime(clusterTime))
+	token, err = encodeSessionToken(sess)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	other, err := client.StartSession()
+	require.NoError(t, err)
+	defer other.EndSession(context.Background())
+	require.NoError(t, advanceSession(other, token))
+	assert.Equal(t, &primitive.Timestamp{T: 1672531200, I: 2}, other.OperationTime())
+	assert.Equal(t, bson.Raw(clusterTime), other.ClusterTime())
+
+	assert.Error(t, advanceSession(other, "not a token"))
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/google/uuid"
 
+	mysqlinternal "github.com/dapr/components-contrib/internal/component/mysql"
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/kit/logger"
@@ -93,6 +94,9 @@ type mySQLMetadata struct {
 	ConnectionString string
 	Timeout          int
 	PemPath          string
+	// DialAddress is the address the connections are dialed to instead of the one of the connection string, e.g. a
+	// Unix domain socket or a local proxy. The connection string also accepts sockets as user@unix(/path/to.sock)/db.
+	DialAddress string
 }
 
 // NewMySQLStateStore creates a new instance of MySQL state store.
@@ -169,7 +173,10 @@ func (m *MySQL) parseMetadata(md map[string]string) error {
 		m.logger.Error("Missing MySql connection string")
 		return fmt.Errorf(errMissingConnectionString)
 	}
-	m.connectionString = meta.ConnectionString
+	m.connectionString, err = mysqlinternal.DSNWithDialAddress(meta.ConnectionString, meta.DialAddress)
+	if err != nil {
+		return fmt.Errorf("invalid dialAddress: %w", err)
+	}
 
 	if meta.PemPath != "" {
 		err := m.factory.RegisterTLSConfig(meta.PemPath)
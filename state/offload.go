@@ -0,0 +1,352 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// OffloadThreshold is the metadata key of a store wrapped with NewOffloadingStore holding the size, in bytes, above which values are offloaded.
+const OffloadThreshold = "offloadThreshold"
+
+// offloadPointerPrefix prefixes the pointer records saved in place of the offloaded values.
+const offloadPointerPrefix = "dapr-offload:"
+
+// OffloadStorage saves the values offloaded from a state store, usually in an object storage.
+type OffloadStorage interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// offloadingStore saves the values above a threshold in an OffloadStorage, and a pointer to them in the wrapped store.
+type offloadingStore struct {
+	forwarder
+
+	storage   OffloadStorage
+	threshold int
+}
+
+// NewOffloadingStore wraps a Store so that values larger than the "offloadThreshold" metadata are saved in storage, while the wrapped store holds a pointer record resolved on Get.
+// Writing a value reads the previous one first, so that the objects it may point to are deleted once they're no longer referenced.
+// Offloading is disabled when no threshold is set. Offloaded values can't be queried, so Query fails once offloading is enabled.
+func NewOffloadingStore(inner Store, storage OffloadStorage) Store {
+	s := &offloadingStore{forwarder: forwarder{inner}, storage: storage}
+	return exposeOptional(s, inner)
+}
+
+func (s *offloadingStore) Init(metadata Metadata) error {
+	s.threshold = 0
+	if val := metadata.Properties[OffloadThreshold]; val != "" {
+		threshold, err := strconv.Atoi(val)
+		if err != nil || threshold <= 0 {
+			return fmt.Errorf("invalid metadata '%s': must be a positive number of bytes", OffloadThreshold)
+		}
+		if s.storage == nil {
+			return fmt.Errorf("metadata '%s' requires an offload storage", OffloadThreshold)
+		}
+		s.threshold = threshold
+	}
+
+	return s.Store.Init(metadata)
+}
+
+func (s *offloadingStore) enabled() bool {
+	return s.threshold > 0
+}
+
+func (s *offloadingStore) Features() []Feature {
+	features := s.Store.Features()
+	if !s.enabled() {
+		return features
+	}
+
+	res := make([]Feature, 0, len(features))
+	for _, f := range features {
+		if f != FeatureQueryAPI {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func (s *offloadingStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if s.enabled() {
+		return nil, errors.New("the query API is not available with offloading")
+	}
+	return s.forwarder.Query(ctx, req)
+}
+
+func (s *offloadingStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	res, err := s.Store.Get(ctx, req)
+	if err != nil || !s.enabled() || res == nil {
+		return res, err
+	}
+
+	res.Data, err = s.resolve(ctx, res.Data)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *offloadingStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	supported, res, err := s.Store.BulkGet(ctx, req)
+	if err != nil || !supported || !s.enabled() {
+		return supported, res, err
+	}
+
+	for i := range res {
+		if res[i].Error != "" {
+			continue
+		}
+		data, err := s.resolve(ctx, res[i].Data)
+		if err != nil {
+			res[i].Data = nil
+			res[i].Error = err.Error()
+			continue
+		}
+		res[i].Data = data
+	}
+	return true, res, nil
+}
+
+func (s *offloadingStore) Set(ctx context.Context, req *SetRequest) error {
+	if !s.enabled() {
+		return s.forwarder.Set(ctx, req)
+	}
+
+	previous, err := s.pointer(ctx, req.Key, req.Metadata)
+	if err != nil {
+		return err
+	}
+	offloaded, name, err := s.offload(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	err = s.forwarder.Set(ctx, offloaded)
+	if err != nil {
+		s.discard(ctx, name)
+		return err
+	}
+	s.discard(ctx, previous)
+	return nil
+}
+
+func (s *offloadingStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	if !s.enabled() {
+		return s.Store.BulkSet(ctx, req)
+	}
+
+	for i := range req {
+		err := s.Set(ctx, &req[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *offloadingStore) Delete(ctx context.Context, req *DeleteRequest) error {
+	if !s.enabled() {
+		return s.forwarder.Delete(ctx, req)
+	}
+
+	previous, err := s.pointer(ctx, req.Key, req.Metadata)
+	if err != nil {
+		return err
+	}
+
+	err = s.forwarder.Delete(ctx, req)
+	if err != nil {
+		return err
+	}
+	s.discard(ctx, previous)
+	return nil
+}
+
+func (s *offloadingStore) BulkDelete(ctx context.Context, req []DeleteRequest) error {
+	if !s.enabled() {
+		return s.Store.BulkDelete(ctx, req)
+	}
+
+	for i := range req {
+		err := s.Delete(ctx, &req[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *offloadingStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	if !s.enabled() {
+		return s.forwarder.Multi(ctx, request)
+	}
+
+	var previous, created []string
+	rollback := func() {
+		for _, name := range created {
+			s.discard(ctx, name)
+		}
+	}
+
+	offloaded := *request
+	offloaded.Operations = make([]TransactionalStateOperation, len(request.Operations))
+	for i, o := range request.Operations {
+		offloaded.Operations[i] = o
+
+		var (
+			key string
+			md  map[string]string
+		)
+		switch r := o.Request.(type) {
+		case SetRequest:
+			key, md = r.Key, r.Metadata
+		case *SetRequest:
+			key, md = r.Key, r.Metadata
+		case DeleteRequest:
+			key, md = r.Key, r.Metadata
+		case *DeleteRequest:
+			key, md = r.Key, r.Metadata
+		default:
+			rollback()
+			return fmt.Errorf("unexpected request type %T for %s operation", o.Request, o.Operation)
+		}
+
+		name, err := s.pointer(ctx, key, md)
+		if err != nil {
+			rollback()
+			return err
+		}
+		if name != "" {
+			previous = append(previous, name)
+		}
+
+		if o.Operation != Upsert {
+			continue
+		}
+		var req SetRequest
+		switch r := o.Request.(type) {
+		case SetRequest:
+			req = r
+		case *SetRequest:
+			req = *r
+		default:
+			rollback()
+			return fmt.Errorf("unexpected request type %T for upsert operation", o.Request)
+		}
+		r, name, err := s.offload(ctx, &req)
+		if err != nil {
+			rollback()
+			return err
+		}
+		if name != "" {
+			created = append(created, name)
+		}
+		offloaded.Operations[i].Request = *r
+	}
+
+	err := s.forwarder.Multi(ctx, &offloaded)
+	if err != nil {
+		rollback()
+		return err
+	}
+	for _, name := range previous {
+		s.discard(ctx, name)
+	}
+	return nil
+}
+
+// offload saves the value of req in the storage if it's above the threshold, and returns a copy of req holding the pointer to it and the name of the object.
+// Small values are returned as is, unless they look like a pointer.
+func (s *offloadingStore) offload(ctx context.Context, req *SetRequest) (*SetRequest, string, error) {
+	var data []byte
+	switch v := req.Value.(type) {
+	case []byte:
+		data = v
+	default:
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if len(data) <= s.threshold && !bytes.HasPrefix(data, []byte(offloadPointerPrefix)) {
+		return req, "", nil
+	}
+
+	// Each value is saved in a new object, so the previous one is still available if saving the pointer fails
+	name := url.PathEscape(req.Key) + "/" + uuid.NewString()
+	err := s.storage.Put(ctx, name, data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to offload state: %w", err)
+	}
+
+	offloaded := *req
+	offloaded.Value = []byte(offloadPointerPrefix + name)
+	return &offloaded, name, nil
+}
+
+// resolve returns the offloaded value data points to, or data itself if it isn't a pointer.
+func (s *offloadingStore) resolve(ctx context.Context, data []byte) ([]byte, error) {
+	name, ok := pointerName(data)
+	if !ok {
+		return data, nil
+	}
+
+	value, err := s.storage.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offloaded state: %w", err)
+	}
+	return value, nil
+}
+
+// pointer returns the name of the object the current value of a key points to, if any.
+func (s *offloadingStore) pointer(ctx context.Context, key string, metadata map[string]string) (string, error) {
+	res, err := s.Store.Get(ctx, &GetRequest{Key: key, Metadata: metadata})
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return "", nil
+	}
+	name, _ := pointerName(res.Data)
+	return name, nil
+}
+
+// discard deletes an object which is no longer referenced.
+// Failures are not returned, since the state was saved: they leave an orphan object behind.
+func (s *offloadingStore) discard(ctx context.Context, name string) {
+	if name == "" {
+		return
+	}
+	_ = s.storage.Delete(ctx, name)
+}
+
+func pointerName(data []byte) (string, bool) {
+	if !bytes.HasPrefix(data, []byte(offloadPointerPrefix)) {
+		return "", false
+	}
+	return string(data[len(offloadPointerPrefix):]), true
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+type memOffloadStorage struct {
+	objects map[string][]byte
+}
+
+func (s *memOffloadStorage) Put(ctx context.Context, name string, data []byte) error {
+	s.objects[name] = data
+	return nil
+}
+
+func (s *memOffloadStorage) Get(ctx context.Context, name string) ([]byte, error) {
+	data, ok := s.objects[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (s *memOffloadStorage) Delete(ctx context.Context, name string) error {
+	delete(s.objects, name)
+	return nil
+}
+
+func initOffloadingStore(t *testing.T, inner Store, storage OffloadStorage, props map[string]string) Store {
+	t.Helper()
+	s := NewOffloadingStore(inner, storage)
+	require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+	return s
+}
+
+func TestOffloadingStore(t *testing.T) {
+	props := map[string]string{OffloadThreshold: "8"}
+	large := []byte("a value larger than the threshold")
+
+	t.Run("offloads large values only", func(t *testing.T) {
+		inner := newMemStore()
+		storage := &memOffloadStorage{objects: map[string][]byte{}}
+		s := initOffloadingStore(t, inner, storage, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "small", Value: []byte("small")}))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "large", Value: large}))
+
+		assert.Equal(t, "small", string(inner.items["small"]))
+		assert.True(t, strings.HasPrefix(string(inner.items["large"]), offloadPointerPrefix))
+		assert.Len(t, storage.objects, 1)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "small"})
+		require.NoError(t, err)
+		assert.Equal(t, "small", string(res.Data))
+		res, err = s.Get(context.Background(), &GetRequest{Key: "large"})
+		require.NoError(t, err)
+		assert.Equal(t, large, res.Data)
+	})
+
+	t.Run("deletes objects no longer referenced", func(t *testing.T) {
+		inner := newMemStore()
+		storage := &memOffloadStorage{objects: map[string][]byte{}}
+		s := initOffloadingStore(t, inner, storage, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "key", Value: large}))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "key", Value: append(large, '!')}))
+		assert.Len(t, storage.objects, 1)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "key", Value: []byte("small")}))
+		assert.Empty(t, storage.objects)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "key", Value: large}))
+		require.NoError(t, s.Delete(context.Background(), &DeleteRequest{Key: "key"}))
+		assert.Empty(t, storage.objects)
+		assert.Empty(t, inner.items)
+	})
+
+	t.Run("values looking like a pointer are offloaded", func(t *testing.T) {
+		inner := newMemStore()
+		storage := &memOffloadStorage{objects: map[string][]byte{}}
+		s := initOffloadingStore(t, inner, storage, map[string]string{OffloadThreshold: "100"})
+
+		value := []byte(offloadPointerPrefix + "x")
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "key", Value: value}))
+		assert.Len(t, storage.objects, 1)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "key"})
+		require.NoError(t, err)
+		assert.Equal(t, value, res.Data)
+	})
+
+	t.Run("transactions offload large values", func(t *testing.T) {
+		inner := newMemStore()
+		storage := &memOffloadStorage{objects: map[string][]byte{}}
+		s := initOffloadingStore(t, inner, storage, props)
+
+		err := s.(TransactionalStore).Multi(context.Background(), &TransactionalStateRequest{
+			Operations: []TransactionalStateOperation{
+				{Operation: Upsert, Request: SetRequest{Key: "large", Value: map[string]string{"data": string(large)}}},
+				{Operation: Upsert, Request: SetRequest{Key: "small", Value: []byte("small")}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Len(t, storage.objects, 1)
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "large"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data":"`+string(large)+`"}`, string(res.Data))
+	})
+
+	t.Run("disabled without threshold", func(t *testing.T) {
+		inner := newMemStore()
+		s := initOffloadingStore(t, inner, nil, nil)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "large", Value: large}))
+		assert.Equal(t, large, inner.items["large"])
+		assert.Contains(t, s.Features(), FeatureQueryAPI)
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		s := NewOffloadingStore(newMemStore(), &memOffloadStorage{})
+		err := s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{OffloadThreshold: "-1"}}})
+		assert.Error(t, err)
+
+		s = NewOffloadingStore(newMemStore(), nil)
+		err = s.Init(Metadata{Base: metadata.Base{Properties: props}})
+		assert.Error(t, err)
+	})
+}
@@ -18,19 +18,22 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/dapr/components-contrib/internal/dialer"
 	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/kit/ptr"
 )
 
 const (
-	cleanupIntervalKey = "cleanupIntervalInSeconds"
-	timeoutKey         = "timeoutInSeconds"
+	cleanupIntervalKey     = "cleanupIntervalInSeconds"
+	timeoutKey             = "timeoutInSeconds"
+	softDeleteRetentionKey = "softDeleteRetentionInSeconds"
 
-	defaultTableName         = "state"
-	defaultMetadataTableName = "dapr_metadata"
-	defaultCleanupInternal   = 3600 // In seconds = 1 hour
-	defaultTimeout           = 20   // Default timeout for network requests, in seconds
+	defaultTableName           = "state"
+	defaultMetadataTableName   = "dapr_metadata"
+	defaultCleanupInternal     = 3600          // In seconds = 1 hour
+	defaultTimeout             = 20            // Default timeout for network requests, in seconds
+	defaultSoftDeleteRetention = 7 * 24 * 3600 // In seconds = 7 days
 )
 
 type postgresMetadataStruct struct {
@@ -38,18 +41,26 @@ type postgresMetadataStruct struct {
 	ConnectionMaxIdleTime time.Duration
 	TableName             string // Could be in the format "schema.table" or just "table"
 	MetadataTableName     string // Could be in the format "schema.table" or just "table"
+	SoftDelete            bool   // Keep deleted rows as tombstones, removed by the cleanup once the retention is over
+	// DialAddress is the address the connections are dialed to instead of the host of the connection string, e.g. a Unix
+	// domain socket or a local proxy. The connection string also accepts the directory of a socket as host=/var/run/postgresql.
+	DialAddress string
 
-	timeout         time.Duration
-	cleanupInterval *time.Duration
+	timeout             time.Duration
+	cleanupInterval     *time.Duration
+	softDeleteRetention time.Duration
 }
 
 func (m *postgresMetadataStruct) InitWithMetadata(meta state.Metadata) error {
 	// Reset the object
 	m.ConnectionString = ""
+	m.DialAddress = ""
 	m.TableName = defaultTableName
 	m.MetadataTableName = defaultMetadataTableName
 	m.cleanupInterval = ptr.Of(defaultCleanupInternal * time.Second)
 	m.timeout = defaultTimeout * time.Second
+	m.SoftDelete = false
+	m.softDeleteRetention = defaultSoftDeleteRetention * time.Second
 
 	// Decode the metadata
 	err := metadata.DecodeMetadata(meta.Properties, &m)
@@ -61,6 +72,11 @@ func (m *postgresMetadataStruct) InitWithMetadata(meta state.Metadata) error {
 	if m.ConnectionString == "" {
 		return errMissingConnectionString
 	}
+	if m.DialAddress != "" {
+		if _, _, err = dialer.ParseAddress(m.DialAddress); err != nil {
+			return err
+		}
+	}
 
 	// Timeout
 	s, ok := meta.Properties[timeoutKey]
@@ -92,5 +108,19 @@ func (m *postgresMetadataStruct) InitWithMetadata(meta state.Metadata) error {
 		}
 	}
 
+	// Soft delete retention
+	s, ok = meta.Properties[softDeleteRetentionKey]
+	if ok && s != "" {
+		retentionInSec, err := strconv.ParseInt(s, 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid value for '%s': %s", softDeleteRetentionKey, s)
+		}
+		if retentionInSec < 0 {
+			return fmt.Errorf("invalid value for '%s': must not be negative", softDeleteRetentionKey)
+		}
+
+		m.softDeleteRetention = time.Duration(retentionInSec) * time.Second
+	}
+
 	return nil
 }
@@ -158,4 +158,43 @@ func TestMetadata(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Nil(t, m.cleanupInterval)
 	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		m := postgresMetadataStruct{}
+		props := map[string]string{
+			"connectionString": "foo",
+		}
+
+		err := m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}})
+		assert.NoError(t, err)
+		assert.False(t, m.SoftDelete)
+		assert.Equal(t, defaultSoftDeleteRetention*time.Second, m.softDeleteRetention)
+
+		props["softDelete"] = "true"
+		props["softDeleteRetentionInSeconds"] = "60"
+		err = m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}})
+		assert.NoError(t, err)
+		assert.True(t, m.SoftDelete)
+		assert.Equal(t, time.Minute, m.softDeleteRetention)
+
+		props["softDeleteRetentionInSeconds"] = "-1"
+		err = m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}})
+		assert.Error(t, err)
+	})
+
+	t.Run("dial address", func(t *testing.T) {
+		m := postgresMetadataStruct{}
+		props := map[string]string{
+			"connectionString": "host=db.example.com user=dapr",
+			"dialAddress":      "unix:///var/run/postgresql/.s.PGSQL.5432",
+		}
+
+		err := m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}})
+		assert.NoError(t, err)
+		assert.Equal(t, "unix:///var/run/postgresql/.s.PGSQL.5432", m.DialAddress)
+
+		props["dialAddress"] = "localhost"
+		err = m.InitWithMetadata(state.Metadata{Base: metadata.Base{Properties: props}})
+		assert.Error(t, err)
+	})
 }
@@ -190,7 +190,7 @@ func (m migrations) tableSchemaName(tableName string) (table string, schema stri
 	}
 }
 
-var allMigrations = [2]func(ctx context.Context, m *migrations) error{
+var allMigrations = [3]func(ctx context.Context, m *migrations) error{
 	// Migration 0: create the state table
 	func(ctx context.Context, m *migrations) error {
 		// We need to add an "IF NOT EXISTS" because we may be migrating from when we did not use a metadata table
@@ -226,4 +226,17 @@ var allMigrations = [2]func(ctx context.Context, m *migrations) error{
 		}
 		return nil
 	},
+
+	// Migration 2: add the "deletedate" column, set on the tombstones of the soft-delete mode
+	func(ctx context.Context, m *migrations) error {
+		m.Logger.Infof("Adding deletedate column to state table '%s'", m.StateTableName)
+		_, err := m.Conn.Exec(ctx, fmt.Sprintf(
+			`ALTER TABLE %s ADD deletedate TIMESTAMP WITH TIME ZONE`,
+			m.StateTableName,
+		))
+		if err != nil {
+			return fmt.Errorf("failed to update state table: %w", err)
+		}
+		return nil
+	},
 }
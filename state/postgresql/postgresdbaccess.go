@@ -26,6 +26,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/dapr/components-contrib/internal/dialer"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/components-contrib/state/query"
 	stateutils "github.com/dapr/components-contrib/state/utils"
@@ -86,6 +87,10 @@ func (p *PostgresDBAccess) Init(meta state.Metadata) error {
 	if p.metadata.ConnectionMaxIdleTime > 0 {
 		config.MaxConnIdleTime = p.metadata.ConnectionMaxIdleTime
 	}
+	if p.metadata.DialAddress != "" {
+		dial, _ := dialer.New(p.metadata.DialAddress, config.ConnConfig.ConnectTimeout)
+		config.ConnConfig.DialFunc = pgconn.DialFunc(dial)
+	}
 
 	connCtx, connCancel := context.WithTimeout(p.ctx, p.metadata.timeout)
 	p.db, err = pgxpool.NewWithConfig(connCtx, config)
@@ -174,10 +179,19 @@ func (p *PostgresDBAccess) doSet(parentCtx context.Context, db dbquerier, req *s
 	)
 	if req.ETag == nil || *req.ETag == "" {
 		if req.Options.Concurrency == state.FirstWrite {
+			// Tombstones left by soft deletes don't count as existing rows
 			query = `INSERT INTO %[1]s
 					(key, value, isbinary, expiredate)
 				VALUES
-					($1, $2, $3, %[2]s)`
+					($1, $2, $3, %[2]s)
+				ON CONFLICT (key)
+				DO UPDATE SET
+					value = $2,
+					isbinary = $3,
+					updatedate = CURRENT_TIMESTAMP,
+					expiredate = %[2]s,
+					deletedate = NULL
+				WHERE %[1]s.deletedate IS NOT NULL`
 		} else {
 			query = `INSERT INTO %[1]s
 					(key, value, isbinary, expiredate)
@@ -188,7 +202,8 @@ func (p *PostgresDBAccess) doSet(parentCtx context.Context, db dbquerier, req *s
 					value = $2,
 					isbinary = $3,
 					updatedate = CURRENT_TIMESTAMP,
-					expiredate = %[2]s`
+					expiredate = %[2]s,
+					deletedate = NULL`
 		}
 		params = []any{req.Key, value, isBinary}
 	} else {
@@ -207,7 +222,8 @@ func (p *PostgresDBAccess) doSet(parentCtx context.Context, db dbquerier, req *s
 				expiredate = %[2]s
 			WHERE
 				key = $3
-				AND xmin = $4`
+				AND xmin = $4
+				AND deletedate IS NULL`
 		params = []any{value, isBinary, req.Key, uint32(etag64)}
 	}
 
@@ -226,6 +242,10 @@ func (p *PostgresDBAccess) doSet(parentCtx context.Context, db dbquerier, req *s
 	}
 
 	if result.RowsAffected() != 1 {
+		// The row exists (and isn't a tombstone) for first-write, or doesn't match the etag
+		if req.Options.Concurrency == state.FirstWrite || (req.ETag != nil && *req.ETag != "") {
+			return state.NewETagError(state.ETagMismatch, nil)
+		}
 		return errors.New("no item was updated")
 	}
 
@@ -265,18 +285,21 @@ func (p *PostgresDBAccess) Get(parentCtx context.Context, req *state.GetRequest)
 	}
 
 	var (
-		value    []byte
-		isBinary bool
-		etag     uint32
+		value        []byte
+		isBinary     bool
+		etag         uint32
+		lastModified time.Time
+		expireDate   *time.Time
 	)
 	query := `SELECT
-			value, isbinary, xmin AS etag
+			value, isbinary, xmin AS etag, COALESCE(updatedate, insertdate) AS lastmodified, expiredate
 		FROM %s
 			WHERE
 				key = $1
+				AND deletedate IS NULL
 				AND (expiredate IS NULL OR expiredate >= CURRENT_TIMESTAMP)`
 	err := p.db.QueryRow(parentCtx, fmt.Sprintf(query, p.metadata.TableName), req.Key).
-		Scan(&value, &isBinary, &etag)
+		Scan(&value, &isBinary, &etag, &lastModified, &expireDate)
 	if err != nil {
 		// If no rows exist, return an empty response, otherwise return the error.
 		if err == pgx.ErrNoRows {
@@ -285,6 +308,15 @@ func (p *PostgresDBAccess) Get(parentCtx context.Context, req *state.GetRequest)
 		return nil, err
 	}
 
+	meta := make(map[string]string, len(req.Metadata)+2)
+	for k, v := range req.Metadata {
+		meta[k] = v
+	}
+	meta[state.GetResponseMetadataLastModified] = lastModified.UTC().Format(time.RFC3339)
+	if expireDate != nil {
+		meta[state.GetResponseMetadataExpireTime] = expireDate.UTC().Format(time.RFC3339)
+	}
+
 	if isBinary {
 		var (
 			s    string
@@ -302,14 +334,14 @@ func (p *PostgresDBAccess) Get(parentCtx context.Context, req *state.GetRequest)
 		return &state.GetResponse{
 			Data:     data,
 			ETag:     ptr.Of(strconv.FormatUint(uint64(etag), 10)),
-			Metadata: req.Metadata,
+			Metadata: meta,
 		}, nil
 	}
 
 	return &state.GetResponse{
 		Data:     value,
 		ETag:     ptr.Of(strconv.FormatUint(uint64(etag), 10)),
-		Metadata: req.Metadata,
+		Metadata: meta,
 	}, nil
 }
 
@@ -323,6 +355,10 @@ func (p *PostgresDBAccess) doDelete(parentCtx context.Context, db dbquerier, req
 		return errors.New("missing key in delete operation")
 	}
 
+	if p.metadata.SoftDelete {
+		return p.doSoftDelete(parentCtx, db, req)
+	}
+
 	var result pgconn.CommandTag
 	if req.ETag == nil || *req.ETag == "" {
 		result, err = db.Exec(parentCtx, "DELETE FROM state WHERE key = $1", req.Key)
@@ -349,6 +385,42 @@ func (p *PostgresDBAccess) doDelete(parentCtx context.Context, db dbquerier, req
 	return nil
 }
 
+// doSoftDelete replaces the row with a tombstone, removed by the cleanup once the retention is over.
+func (p *PostgresDBAccess) doSoftDelete(parentCtx context.Context, db dbquerier, req *state.DeleteRequest) (err error) {
+	query := `UPDATE %s
+		SET
+			value = 'null',
+			isbinary = false,
+			updatedate = CURRENT_TIMESTAMP,
+			deletedate = CURRENT_TIMESTAMP
+		WHERE
+			key = $1
+			AND deletedate IS NULL`
+	params := []any{req.Key}
+	if req.ETag != nil && *req.ETag != "" {
+		// Convert req.ETag to uint32 for postgres XID compatibility
+		var etag64 uint64
+		etag64, err = strconv.ParseUint(*req.ETag, 10, 32)
+		if err != nil {
+			return state.NewETagError(state.ETagInvalid, err)
+		}
+
+		query += " AND xmin = $2"
+		params = append(params, uint32(etag64))
+	}
+
+	result, err := db.Exec(parentCtx, fmt.Sprintf(query, p.metadata.TableName), params...)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() != 1 && req.ETag != nil && *req.ETag != "" {
+		return state.NewETagError(state.ETagMismatch, nil)
+	}
+
+	return nil
+}
+
 func (p *PostgresDBAccess) BulkDelete(parentCtx context.Context, req []state.DeleteRequest) error {
 	tx, err := p.beginTx(parentCtx)
 	if err != nil {
@@ -492,6 +564,21 @@ func (p *PostgresDBAccess) CleanupExpired(ctx context.Context) error {
 	}
 
 	p.logger.Infof("Removed %d expired rows", res.RowsAffected())
+
+	return p.purgeTombstones(ctx)
+}
+
+// purgeTombstones removes the tombstones left by soft deletes once their retention is over.
+// This runs even when soft deletes are disabled, to clean up after the mode was turned off.
+func (p *PostgresDBAccess) purgeTombstones(ctx context.Context) error {
+	stmt := fmt.Sprintf(`DELETE FROM %s WHERE deletedate IS NOT NULL AND deletedate < CURRENT_TIMESTAMP - interval '%d seconds'`,
+		p.metadata.TableName, int(p.metadata.softDeleteRetention.Seconds()))
+	res, err := p.db.Exec(ctx, stmt)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	p.logger.Infof("Removed %d tombstones", res.RowsAffected())
 	return nil
 }
 
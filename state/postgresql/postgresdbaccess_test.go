@@ -247,6 +247,65 @@ func TestValidMultiDeleteRequest(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSoftDeleteRequest(t *testing.T) {
+	// Arrange
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+	m.pgDba.metadata.SoftDelete = true
+	m.pgDba.metadata.TableName = defaultTableName
+
+	m.db.ExpectExec("UPDATE state SET (.+) deletedate = CURRENT_TIMESTAMP WHERE key = \\$1 AND deletedate IS NULL$").
+		WithArgs("key1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	m.db.ExpectExec("AND deletedate IS NULL AND xmin = \\$2").
+		WithArgs("key1", uint32(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	// Act
+	err := m.pgDba.Delete(context.Background(), &state.DeleteRequest{Key: "key1"})
+	assert.NoError(t, err)
+	etag := "7"
+	err = m.pgDba.Delete(context.Background(), &state.DeleteRequest{Key: "key1", ETag: &etag})
+
+	// Assert
+	var etagErr *state.ETagError
+	assert.ErrorAs(t, err, &etagErr)
+	assert.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestSetConcurrencyConflict(t *testing.T) {
+	// Arrange
+	m, _ := mockDatabase(t)
+	defer m.db.Close()
+	m.pgDba.metadata.TableName = defaultTableName
+
+	m.db.ExpectExec("INSERT INTO state (.+) ON CONFLICT (.+) WHERE state.deletedate IS NOT NULL").
+		WithArgs("key1", `"value"`, false).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	m.db.ExpectExec("UPDATE state SET (.+) AND deletedate IS NULL").
+		WithArgs(`"value"`, false, "key1", uint32(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	// Act
+	errFirstWrite := m.pgDba.Set(context.Background(), &state.SetRequest{
+		Key:     "key1",
+		Value:   "value",
+		Options: state.SetStateOption{Concurrency: state.FirstWrite},
+	})
+	etag := "7"
+	errETag := m.pgDba.Set(context.Background(), &state.SetRequest{Key: "key1", Value: "value", ETag: &etag})
+
+	// Assert
+	var etagErr *state.ETagError
+	if assert.ErrorAs(t, errFirstWrite, &etagErr) {
+		assert.Equal(t, state.ETagMismatch, etagErr.Kind())
+	}
+	if assert.ErrorAs(t, errETag, &etagErr) {
+		assert.Equal(t, state.ETagMismatch, etagErr.Kind())
+	}
+	assert.NoError(t, m.db.ExpectationsWereMet())
+}
+
 func TestInvalidMultiDeleteRequest(t *testing.T) {
 	// Arrange
 	m, _ := mockDatabase(t)
@@ -104,8 +104,11 @@ func (q *Query) VisitOR(f *query.OR) (string, error) {
 func (q *Query) Finalize(filters string, qq *query.Query) error {
 	q.query = "SELECT key, value, xmin as etag FROM " + q.tableName
 
+	// Exclude the tombstones left by soft deletes, which remain until they are purged even if soft deletes are disabled afterwards
 	if filters != "" {
-		q.query += " WHERE " + filters
+		q.query += " WHERE deletedate IS NULL AND (" + filters + ")"
+	} else {
+		q.query += " WHERE deletedate IS NULL"
 	}
 
 	if len(qq.Sort) > 0 {
@@ -30,27 +30,27 @@ func TestPostgresqlQueryBuildQuery(t *testing.T) {
 	}{
 		{
 			input: "../../tests/state/query/q1.json",
-			query: "SELECT key, value, xmin as etag FROM state LIMIT 2",
+			query: "SELECT key, value, xmin as etag FROM state WHERE deletedate IS NULL LIMIT 2",
 		},
 		{
 			input: "../../tests/state/query/q2.json",
-			query: "SELECT key, value, xmin as etag FROM state WHERE value->>'state'=$1 LIMIT 2",
+			query: "SELECT key, value, xmin as etag FROM state WHERE deletedate IS NULL AND (value->>'state'=$1) LIMIT 2",
 		},
 		{
 			input: "../../tests/state/query/q2-token.json",
-			query: "SELECT key, value, xmin as etag FROM state WHERE value->>'state'=$1 LIMIT 2 OFFSET 2",
+			query: "SELECT key, value, xmin as etag FROM state WHERE deletedate IS NULL AND (value->>'state'=$1) LIMIT 2 OFFSET 2",
 		},
 		{
 			input: "../../tests/state/query/q3.json",
-			query: "SELECT key, value, xmin as etag FROM state WHERE (value->'person'->>'org'=$1 AND (value->>'state'=$2 OR value->>'state'=$3)) ORDER BY value->>'state' DESC, value->'person'->>'name'",
+			query: "SELECT key, value, xmin as etag FROM state WHERE deletedate IS NULL AND ((value->'person'->>'org'=$1 AND (value->>'state'=$2 OR value->>'state'=$3))) ORDER BY value->>'state' DESC, value->'person'->>'name'",
 		},
 		{
 			input: "../../tests/state/query/q4.json",
-			query: "SELECT key, value, xmin as etag FROM state WHERE (value->'person'->>'org'=$1 OR (value->'person'->>'org'=$2 AND (value->>'state'=$3 OR value->>'state'=$4))) ORDER BY value->>'state' DESC, value->'person'->>'name' LIMIT 2",
+			query: "SELECT key, value, xmin as etag FROM state WHERE deletedate IS NULL AND ((value->'person'->>'org'=$1 OR (value->'person'->>'org'=$2 AND (value->>'state'=$3 OR value->>'state'=$4)))) ORDER BY value->>'state' DESC, value->'person'->>'name' LIMIT 2",
 		},
 		{
 			input: "../../tests/state/query/q5.json",
-			query: "SELECT key, value, xmin as etag FROM state WHERE (value->'person'->>'org'=$1 AND (value->'person'->>'name'=$2 OR (value->>'state'=$3 OR value->>'state'=$4))) ORDER BY value->>'state' DESC, value->'person'->>'name' LIMIT 2",
+			query: "SELECT key, value, xmin as etag FROM state WHERE deletedate IS NULL AND ((value->'person'->>'org'=$1 AND (value->'person'->>'name'=$2 OR (value->>'state'=$3 OR value->>'state'=$4)))) ORDER BY value->>'state' DESC, value->'person'->>'name' LIMIT 2",
 		},
 	}
 	for _, test := range tests {
@@ -15,6 +15,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/dapr/components-contrib/contenttype"
 	rediscomponent "github.com/dapr/components-contrib/internal/component/redis"
+	"github.com/dapr/components-contrib/internal/redact"
 	daprmetadata "github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/components-contrib/state/query"
@@ -119,7 +121,7 @@ func NewRedisStateStore(logger logger.Logger) state.Store {
 
 func (r *StateStore) Ping() error {
 	if _, err := r.client.PingResult(context.Background()); err != nil {
-		return fmt.Errorf("redis store: error connecting to redis at %s: %s", r.clientSettings.Host, err)
+		return fmt.Errorf("redis store: error connecting to redis at %s: %s", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 
 	return nil
@@ -147,7 +149,7 @@ func (r *StateStore) Init(metadata state.Metadata) error {
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 
 	if _, err = r.client.PingResult(r.ctx); err != nil {
-		return fmt.Errorf("redis store: error connecting to redis at %s: %v", r.clientSettings.Host, err)
+		return fmt.Errorf("redis store: error connecting to redis at %s: %v", redact.ConnectionString(r.clientSettings.Host), err)
 	}
 
 	if r.replicas, err = r.getConnectedSlaves(); err != nil {
@@ -402,6 +404,22 @@ func (r *StateStore) Multi(ctx context.Context, request *state.TransactionalStat
 		delQuery = delDefaultQuery
 	}
 
+	// On a Redis Cluster the transaction is split per hash slot, so it is only atomic when all keys share a slot.
+	if r.clientSettings != nil && r.clientSettings.IsCluster() {
+		keys := make([]string, 0, len(request.Operations))
+		for _, o := range request.Operations {
+			switch req := o.Request.(type) {
+			case state.SetRequest:
+				keys = append(keys, req.Key)
+			case state.DeleteRequest:
+				keys = append(keys, req.Key)
+			}
+		}
+		if !rediscomponent.SameHashSlot(keys...) {
+			return errors.New("redis store: transactions on a Redis Cluster require all keys to map to the same hash slot; use a hash tag such as '{tag}' in the keys")
+		}
+	}
+
 	pipe := r.client.TxPipeline()
 	for _, o := range request.Operations {
 		if o.Operation == state.Upsert {
@@ -261,6 +261,39 @@ func TestTransactionalUpsert(t *testing.T) {
 	assert.Equal(t, int64(-1), res)
 }
 
+func TestTransactionalClusterHashSlots(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	ss := &StateStore{
+		client:         c,
+		clientSettings: &rediscomponent.Settings{RedisType: rediscomponent.ClusterType},
+		json:           jsoniter.ConfigFastest,
+		logger:         logger.NewLogger("test"),
+	}
+	ss.ctx, ss.cancel = context.WithCancel(context.Background())
+
+	t.Run("keys in different slots are rejected", func(t *testing.T) {
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{Operation: state.Upsert, Request: state.SetRequest{Key: "weapon", Value: "deathstar"}},
+				{Operation: state.Delete, Request: state.DeleteRequest{Key: "planet"}},
+			},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("keys sharing a hash tag are accepted", func(t *testing.T) {
+		err := ss.Multi(context.Background(), &state.TransactionalStateRequest{
+			Operations: []state.TransactionalStateOperation{
+				{Operation: state.Upsert, Request: state.SetRequest{Key: "{empire}weapon", Value: "deathstar"}},
+				{Operation: state.Delete, Request: state.DeleteRequest{Key: "{empire}planet"}},
+			},
+		})
+		assert.NoError(t, err)
+	})
+}
+
 func TestTransactionalDelete(t *testing.T) {
 	s, c := setupMiniredis()
 	defer s.Close()
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dapr/components-contrib/internal/component/resiliency"
+)
+
+// resilientStore runs the operations of a store with the timeout, retries and circuit breaker set in its metadata.
+type resilientStore struct {
+	forwarder
+
+	policy *resiliency.Policy
+}
+
+// NewResilientStore wraps a Store so that its operations are run with the resiliency settings in its metadata:
+// "resiliencyTimeout", "resiliencyRetry*" and "resiliencyCircuitBreaker*". ETag errors are never retried.
+// The wrapper keeps the transactional and query capabilities of the inner store.
+func NewResilientStore(inner Store) Store {
+	s := &resilientStore{forwarder: forwarder{inner}}
+	return exposeOptional(s, inner)
+}
+
+func (s *resilientStore) Init(metadata Metadata) error {
+	m, err := resiliency.ParseMetadata(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("state store resiliency error: %w", err)
+	}
+	s.policy = resiliency.NewPolicy(m, isETagError)
+
+	return s.Store.Init(metadata)
+}
+
+func (s *resilientStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	return resiliency.Run(ctx, s.policy, func(ctx context.Context) (*GetResponse, error) {
+		return s.Store.Get(ctx, req)
+	})
+}
+
+func (s *resilientStore) Set(ctx context.Context, req *SetRequest) error {
+	return resiliency.RunOnce(ctx, s.policy, func(ctx context.Context) error {
+		return s.forwarder.Set(ctx, req)
+	})
+}
+
+func (s *resilientStore) Delete(ctx context.Context, req *DeleteRequest) error {
+	return resiliency.RunOnce(ctx, s.policy, func(ctx context.Context) error {
+		return s.forwarder.Delete(ctx, req)
+	})
+}
+
+func (s *resilientStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	type bulkGetResult struct {
+		supported bool
+		res       []BulkGetResponse
+	}
+	res, err := resiliency.Run(ctx, s.policy, func(ctx context.Context) (bulkGetResult, error) {
+		supported, res, err := s.Store.BulkGet(ctx, req)
+		return bulkGetResult{supported: supported, res: res}, err
+	})
+	return res.supported, res.res, err
+}
+
+func (s *resilientStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	return resiliency.RunOnce(ctx, s.policy, func(ctx context.Context) error {
+		return s.Store.BulkSet(ctx, req)
+	})
+}
+
+func (s *resilientStore) BulkDelete(ctx context.Context, req []DeleteRequest) error {
+	return resiliency.RunOnce(ctx, s.policy, func(ctx context.Context) error {
+		return s.Store.BulkDelete(ctx, req)
+	})
+}
+
+func (s *resilientStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	return resiliency.RunOnce(ctx, s.policy, func(ctx context.Context) error {
+		return s.forwarder.Multi(ctx, request)
+	})
+}
+
+func (s *resilientStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	return resiliency.Run(ctx, s.policy, func(ctx context.Context) (*QueryResponse, error) {
+		return s.forwarder.Query(ctx, req)
+	})
+}
+
+func isETagError(err error) bool {
+	var etagErr *ETagError
+	return errors.As(err, &etagErr)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+)
+
+type flakyStore struct {
+	memStore
+	failures int
+	err      error
+	calls    int
+}
+
+func (s *flakyStore) Set(ctx context.Context, req *SetRequest) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return s.err
+	}
+	return s.memStore.Set(ctx, req)
+}
+
+func TestResilientStore(t *testing.T) {
+	props := map[string]string{
+		"resiliencyRetryMaxRetries": "3",
+		"resiliencyRetryDuration":   "1ms",
+	}
+
+	t.Run("retries failed operations", func(t *testing.T) {
+		inner := &flakyStore{memStore: *newMemStore(), failures: 2, err: errors.New("unavailable")}
+		s := NewResilientStore(inner)
+		require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		assert.Equal(t, 3, inner.calls)
+	})
+
+	t.Run("etag errors are not retried", func(t *testing.T) {
+		inner := &flakyStore{memStore: *newMemStore(), failures: 2, err: NewETagError(ETagMismatch, nil)}
+		s := NewResilientStore(inner)
+		require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+
+		err := s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")})
+		assert.True(t, isETagError(err))
+		assert.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("keeps the capabilities of the inner store", func(t *testing.T) {
+		_, ok := NewResilientStore(newMemStore()).(TransactionalStore)
+		assert.True(t, ok)
+		_, ok = NewResilientStore(newMemStore()).(Querier)
+		assert.False(t, ok)
+		_, ok = NewResilientStore(&Store1{}).(TransactionalStore)
+		assert.False(t, ok)
+	})
+}
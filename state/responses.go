@@ -13,6 +13,15 @@ limitations under the License.
 
 package state
 
+// Keys of the provider information stores can add to the metadata of a GetResponse.
+// Times are in RFC 3339 format.
+const (
+	// Time the value was last written.
+	GetResponseMetadataLastModified = "lastModified"
+	// Time the value expires; not set for values that don't expire.
+	GetResponseMetadataExpireTime = "ttlExpireTime"
+)
+
 // GetResponse is the response object for getting state.
 type GetResponse struct {
 	Data        []byte            `json:"data"`
@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+)
+
+// SessionTokenMetadataKey is the key of the request and response metadata holding a session token.
+// A session token returned by a write can be passed to later reads, possibly served by other replicas
+// or other instances of the application, to read at least what that write has written.
+const SessionTokenMetadataKey = "sessionToken"
+
+// SessionTokenStore is implemented by state stores offering read-your-writes consistency through session tokens.
+// The token passed with the SessionTokenMetadataKey metadata is honored by Get, Set, Delete and Multi,
+// and Get responses carry the session token after the read in their metadata.
+type SessionTokenStore interface {
+	// SetWithSessionToken saves an entity and returns the session token after the write.
+	SetWithSessionToken(ctx context.Context, req *SetRequest) (string, error)
+	// DeleteWithSessionToken deletes an entity and returns the session token after the delete.
+	DeleteWithSessionToken(ctx context.Context, req *DeleteRequest) (string, error)
+}
+
+// SetWithSessionToken saves an entity with store and returns the session token after the write.
+// The token is empty if store does not implement SessionTokenStore.
+func SetWithSessionToken(ctx context.Context, store Store, req *SetRequest) (string, error) {
+	if s, ok := store.(SessionTokenStore); ok {
+		return s.SetWithSessionToken(ctx, req)
+	}
+	return "", store.Set(ctx, req)
+}
+
+// DeleteWithSessionToken deletes an entity with store and returns the session token after the delete.
+// The token is empty if store does not implement SessionTokenStore.
+func DeleteWithSessionToken(ctx context.Context, store Store, req *DeleteRequest) (string, error) {
+	if s, ok := store.(SessionTokenStore); ok {
+		return s.DeleteWithSessionToken(ctx, req)
+	}
+	return "", store.Delete(ctx, req)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// example of store which returns session tokens.
+type sessionStore struct {
+	Store1
+}
+
+func (s *sessionStore) SetWithSessionToken(ctx context.Context, req *SetRequest) (string, error) {
+	return "set-" + req.Key, s.Set(ctx, req)
+}
+
+func (s *sessionStore) DeleteWithSessionToken(ctx context.Context, req *DeleteRequest) (string, error) {
+	return "delete-" + req.Key, s.Delete(ctx, req)
+}
+
+func TestSessionToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("store with session tokens", func(t *testing.T) {
+		s := &sessionStore{}
+		s.DefaultBulkStore = NewDefaultBulkStore(s)
+		token, err := SetWithSessionToken(ctx, s, &SetRequest{Key: "k"})
+		assert.NoError(t, err)
+		assert.Equal(t, "set-k", token)
+		token, err = DeleteWithSessionToken(ctx, s, &DeleteRequest{Key: "k"})
+		assert.NoError(t, err)
+		assert.Equal(t, "delete-k", token)
+		assert.Equal(t, 2, s.count)
+	})
+
+	t.Run("store without session tokens", func(t *testing.T) {
+		s := &Store1{}
+		s.DefaultBulkStore = NewDefaultBulkStore(s)
+		token, err := SetWithSessionToken(ctx, s, &SetRequest{Key: "k"})
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+		token, err = DeleteWithSessionToken(ctx, s, &DeleteRequest{Key: "k"})
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+		assert.Equal(t, 2, s.count)
+	})
+}
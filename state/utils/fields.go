@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Key used for "fields" in metadata.
+const MetadataFieldsKey = "fields"
+
+// ParseFields parses the "fields" metadata property, a comma-separated list of JSONPaths such as "$.address.city".
+// Each path is returned as its list of segments. Only child paths in dot notation are supported; the leading "$." is optional.
+func ParseFields(requestMetadata map[string]string) ([][]string, error) {
+	val, found := requestMetadata[MetadataFieldsKey]
+	if !found || strings.TrimSpace(val) == "" {
+		return nil, nil
+	}
+
+	var fields [][]string
+	for _, path := range strings.Split(val, ",") {
+		path = strings.TrimSpace(path)
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if path == "" {
+			return nil, fmt.Errorf("incorrect value for metadata '%s': empty path", MetadataFieldsKey)
+		}
+		if strings.ContainsAny(path, "[]*$@?() '\"") {
+			return nil, fmt.Errorf("incorrect value for metadata '%s': unsupported path '%s'", MetadataFieldsKey, path)
+		}
+		segments := strings.Split(path, ".")
+		for _, s := range segments {
+			if s == "" {
+				return nil, fmt.Errorf("incorrect value for metadata '%s': invalid path '%s'", MetadataFieldsKey, path)
+			}
+		}
+		fields = append(fields, segments)
+	}
+
+	return fields, nil
+}
+
+// SetField sets the value at path in doc, creating intermediate objects as needed.
+func SetField(doc map[string]interface{}, path []string, value interface{}) {
+	for _, s := range path[:len(path)-1] {
+		next, ok := doc[s].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			doc[s] = next
+		}
+		doc = next
+	}
+	doc[path[len(path)-1]] = value
+}
+
+// ProjectJSON returns a JSON object containing only the given fields of a JSON document.
+// Fields that don't exist are omitted. Documents that aren't JSON objects are returned unchanged.
+func ProjectJSON(data []byte, fields [][]string) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data, nil //nolint:nilerr
+	}
+
+	res := map[string]interface{}{}
+	for _, path := range fields {
+		var cur interface{} = doc
+		found := true
+		for _, s := range path {
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			if cur, ok = obj[s]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			SetField(res, path, cur)
+		}
+	}
+
+	return json.Marshal(res)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+	t.Run("Fields not specified", func(t *testing.T) {
+		fields, err := ParseFields(map[string]string{})
+		require.NoError(t, err)
+		assert.Nil(t, fields)
+	})
+
+	t.Run("JSONPaths and plain paths", func(t *testing.T) {
+		fields, err := ParseFields(map[string]string{
+			MetadataFieldsKey: "$.name, address.city,$.tags",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name"}, {"address", "city"}, {"tags"}}, fields)
+	})
+
+	t.Run("Unsupported paths", func(t *testing.T) {
+		for _, val := range []string{"$.items[0]", "$..name", "$.a.*", "name,,age", "$"} {
+			_, err := ParseFields(map[string]string{
+				MetadataFieldsKey: val,
+			})
+			assert.Error(t, err, val)
+		}
+	})
+}
+
+func TestProjectJSON(t *testing.T) {
+	doc := []byte(`{"name":"Luke","address":{"city":"Tatooine","zip":"00001"},"age":19}`)
+
+	t.Run("Projects nested fields", func(t *testing.T) {
+		res, err := ProjectJSON(doc, [][]string{{"name"}, {"address", "city"}, {"missing", "field"}})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"Luke","address":{"city":"Tatooine"}}`, string(res))
+	})
+
+	t.Run("Non-object documents are returned unchanged", func(t *testing.T) {
+		res, err := ProjectJSON([]byte(`"just a string"`), [][]string{{"name"}})
+		require.NoError(t, err)
+		assert.Equal(t, `"just a string"`, string(res))
+	})
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/dapr/components-contrib/health"
+)
+
+// wrapperBase is implemented by all the Store wrappers.
+// Its optional interfaces behave as the wrapped Store when it doesn't implement them.
+type wrapperBase interface {
+	Store
+	Flusher
+	io.Closer
+}
+
+// multier is the method of TransactionalStore which isn't in Store.
+type multier interface {
+	Multi(ctx context.Context, request *TransactionalStateRequest) error
+}
+
+// wrapper is implemented by the Store wrappers, which support all the optional interfaces of the wrapped Store.
+type wrapper interface {
+	wrapperBase
+	multier
+	Querier
+	health.Pinger
+}
+
+// forwarder is embedded by the wrappers to forward the optional interfaces to the wrapped Store.
+// The wrappers override the methods whose behavior they change.
+type forwarder struct {
+	Store
+}
+
+// Set and Delete return the session token of the wrapped Store, when the write runs for SetWithSessionToken or
+// DeleteWithSessionToken, so that the wrappers calling them keep the token of the write they transform.
+func (f forwarder) Set(ctx context.Context, req *SetRequest) error {
+	if token := sessionTokenFrom(ctx); token != nil {
+		if sessions, ok := f.Store.(SessionTokenStore); ok {
+			var err error
+			*token, err = sessions.SetWithSessionToken(ctx, req)
+			return err
+		}
+	}
+	return f.Store.Set(ctx, req)
+}
+
+func (f forwarder) Delete(ctx context.Context, req *DeleteRequest) error {
+	if token := sessionTokenFrom(ctx); token != nil {
+		if sessions, ok := f.Store.(SessionTokenStore); ok {
+			var err error
+			*token, err = sessions.DeleteWithSessionToken(ctx, req)
+			return err
+		}
+	}
+	return f.Store.Delete(ctx, req)
+}
+
+func (f forwarder) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	if transactional, ok := f.Store.(TransactionalStore); ok {
+		return transactional.Multi(ctx, request)
+	}
+	return errors.New("transactions are not supported by this state store")
+}
+
+func (f forwarder) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if querier, ok := f.Store.(Querier); ok {
+		return querier.Query(ctx, req)
+	}
+	return nil, errors.New("the query API is not supported by this state store")
+}
+
+func (f forwarder) Ping() error {
+	return Ping(f.Store)
+}
+
+func (f forwarder) Flush(ctx context.Context) error {
+	if flusher, ok := f.Store.(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+func (f forwarder) Close() error {
+	if closer, ok := f.Store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// sessionTokenKey is the context key of the session token of a write running for SetWithSessionToken or
+// DeleteWithSessionToken.
+type sessionTokenKey struct{}
+
+func sessionTokenFrom(ctx context.Context) *string {
+	token, _ := ctx.Value(sessionTokenKey{}).(*string)
+	return token
+}
+
+// sessionTokens implements SessionTokenStore with the Set and Delete of a wrapper, which pass the session token of
+// the wrapped Store through the context.
+type sessionTokens struct {
+	w wrapper
+}
+
+func (s sessionTokens) SetWithSessionToken(ctx context.Context, req *SetRequest) (string, error) {
+	var token string
+	err := s.w.Set(context.WithValue(ctx, sessionTokenKey{}, &token), req)
+	return token, err
+}
+
+func (s sessionTokens) DeleteWithSessionToken(ctx context.Context, req *DeleteRequest) (string, error) {
+	var token string
+	err := s.w.Delete(context.WithValue(ctx, sessionTokenKey{}, &token), req)
+	return token, err
+}
+
+// exposeOptional returns the wrapper w of inner, implementing TransactionalStore, Querier, health.Pinger and
+// SessionTokenStore only if inner does, so that the callers checking for them keep their fallbacks.
+func exposeOptional(w wrapper, inner Store) Store {
+	_, transactional := inner.(TransactionalStore)
+	_, querier := inner.(Querier)
+	_, pinger := inner.(health.Pinger)
+	_, sessions := inner.(SessionTokenStore)
+
+	switch {
+	case transactional && querier && pinger && sessions:
+		return struct {
+			wrapperBase
+			multier
+			Querier
+			health.Pinger
+			SessionTokenStore
+		}{w, w, w, w, sessionTokens{w}}
+	case transactional && querier && pinger:
+		return w
+	case transactional && querier && sessions:
+		return struct {
+			wrapperBase
+			multier
+			Querier
+			SessionTokenStore
+		}{w, w, w, sessionTokens{w}}
+	case transactional && pinger && sessions:
+		return struct {
+			wrapperBase
+			multier
+			health.Pinger
+			SessionTokenStore
+		}{w, w, w, sessionTokens{w}}
+	case querier && pinger && sessions:
+		return struct {
+			wrapperBase
+			Querier
+			health.Pinger
+			SessionTokenStore
+		}{w, w, w, sessionTokens{w}}
+	case transactional && querier:
+		return struct {
+			wrapperBase
+			multier
+			Querier
+		}{w, w, w}
+	case transactional && pinger:
+		return struct {
+			wrapperBase
+			multier
+			health.Pinger
+		}{w, w, w}
+	case transactional && sessions:
+		return struct {
+			wrapperBase
+			multier
+			SessionTokenStore
+		}{w, w, sessionTokens{w}}
+	case querier && pinger:
+		return struct {
+			wrapperBase
+			Querier
+			health.Pinger
+		}{w, w, w}
+	case querier && sessions:
+		return struct {
+			wrapperBase
+			Querier
+			SessionTokenStore
+		}{w, w, sessionTokens{w}}
+	case pinger && sessions:
+		return struct {
+			wrapperBase
+			health.Pinger
+			SessionTokenStore
+		}{w, w, sessionTokens{w}}
+	case transactional:
+		return struct {
+			wrapperBase
+			multier
+		}{w, w}
+	case querier:
+		return struct {
+			wrapperBase
+			Querier
+		}{w, w}
+	case pinger:
+		return struct {
+			wrapperBase
+			health.Pinger
+		}{w, w}
+	case sessions:
+		return struct {
+			wrapperBase
+			SessionTokenStore
+		}{w, sessionTokens{w}}
+	default:
+		return struct {
+			wrapperBase
+		}{w}
+	}
+}
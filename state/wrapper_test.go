@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/health"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// queryStore is a memStore which can be queried and pinged.
+type queryStore struct {
+	*memStore
+	pings int
+}
+
+func (s *queryStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	res := &QueryResponse{}
+	for k, v := range s.items {
+		res.Results = append(res.Results, QueryItem{Key: k, Data: v})
+	}
+	return res, nil
+}
+
+func (s *queryStore) Ping() error {
+	s.pings++
+	return nil
+}
+
+// tokenStore is a memStore returning session tokens.
+type tokenStore struct {
+	*memStore
+}
+
+func (s *tokenStore) SetWithSessionToken(ctx context.Context, req *SetRequest) (string, error) {
+	return "set-" + req.Key, s.Set(ctx, req)
+}
+
+func (s *tokenStore) DeleteWithSessionToken(ctx context.Context, req *DeleteRequest) (string, error) {
+	return "delete-" + req.Key, s.Delete(ctx, req)
+}
+
+func TestWrappers(t *testing.T) {
+	wrappers := map[string]func(Store) Store{
+		"resiliency":   NewResilientStore,
+		"write-behind": func(inner Store) Store { return NewWriteBehindStore(inner, logger.NewLogger("test")) },
+		"migration":    NewSchemaMigrationStore,
+		"bulkhead":     NewBulkheadStore,
+		"encryption":   NewEncryptedStore,
+		"offload": func(inner Store) Store {
+			return NewOffloadingStore(inner, &memOffloadStorage{objects: map[string][]byte{}})
+		},
+		"integrity": NewIntegrityStore,
+	}
+
+	for name, wrap := range wrappers {
+		wrap := wrap
+		t.Run(name+" keeps the capabilities of the inner store", func(t *testing.T) {
+			s := wrap(&queryStore{memStore: newMemStore()})
+			_, ok := s.(TransactionalStore)
+			assert.True(t, ok)
+			_, ok = s.(Querier)
+			assert.True(t, ok)
+			_, ok = s.(health.Pinger)
+			assert.True(t, ok)
+			_, ok = s.(SessionTokenStore)
+			assert.False(t, ok)
+
+			s = wrap(&Store1{})
+			_, ok = s.(TransactionalStore)
+			assert.False(t, ok)
+			_, ok = s.(Querier)
+			assert.False(t, ok)
+			_, ok = s.(health.Pinger)
+			assert.False(t, ok)
+			_, ok = s.(SessionTokenStore)
+			assert.False(t, ok)
+		})
+
+		t.Run(name+" returns the session tokens of the inner store", func(t *testing.T) {
+			inner := &tokenStore{memStore: newMemStore()}
+			s := wrap(inner)
+			require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{}}}))
+			defer s.(wrapperBase).Close()
+
+			token, err := SetWithSessionToken(context.Background(), s, &SetRequest{Key: "k", Value: []byte(`"v"`)})
+			require.NoError(t, err)
+			assert.Equal(t, "set-k", token)
+			assert.Contains(t, inner.items, "k")
+
+			token, err = DeleteWithSessionToken(context.Background(), s, &DeleteRequest{Key: "k"})
+			require.NoError(t, err)
+			assert.Equal(t, "delete-k", token)
+			assert.NotContains(t, inner.items, "k")
+		})
+
+		t.Run(name+" forwards queries and pings", func(t *testing.T) {
+			inner := &queryStore{memStore: newMemStore()}
+			inner.items["k"] = []byte(`"v"`)
+			s := wrap(inner)
+			require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{}}}))
+			defer s.(wrapperBase).Close()
+
+			res, err := s.(Querier).Query(context.Background(), &QueryRequest{})
+			require.NoError(t, err)
+			require.Len(t, res.Results, 1)
+			assert.Equal(t, "k", res.Results[0].Key)
+
+			require.NoError(t, Ping(s))
+			assert.Equal(t, 1, inner.pings)
+		})
+	}
+
+	t.Run("queries fail once values can't be queried", func(t *testing.T) {
+		for name, props := range map[string]map[string]string{
+			"encryption": {PrimaryEncryptionKey: testKey1},
+			"offload":    {OffloadThreshold: "10"},
+			"integrity":  {VerifyIntegrity: "true"},
+		} {
+			s := wrappers[name](&queryStore{memStore: newMemStore()})
+			require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}), name)
+			assert.NotContains(t, s.Features(), FeatureQueryAPI, name)
+			_, err := s.(Querier).Query(context.Background(), &QueryRequest{})
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("session writes are transformed by the wrappers", func(t *testing.T) {
+		inner := &tokenStore{memStore: newMemStore()}
+		s := NewResilientStore(NewEncryptedStore(NewWriteBehindStore(inner, logger.NewLogger("test"))))
+		require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{
+			PrimaryEncryptionKey:     testKey1,
+			WriteBehind:              "true",
+			WriteBehindFlushInterval: "1h",
+		}}}))
+		defer s.(wrapperBase).Close()
+
+		token, err := SetWithSessionToken(context.Background(), s, &SetRequest{Key: "k", Value: []byte("v")})
+		require.NoError(t, err)
+		assert.Equal(t, "set-k", token)
+		// The write isn't buffered, as its token is returned, and is encrypted.
+		require.Contains(t, inner.items, "k")
+		assert.NotEqual(t, []byte("v"), inner.items["k"])
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v"), res.Data)
+	})
+
+	t.Run("buffered writes are flushed through other wrappers", func(t *testing.T) {
+		inner := newMemStore()
+		s := NewResilientStore(NewWriteBehindStore(inner, logger.NewLogger("test")))
+		require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{
+			WriteBehind:              "true",
+			WriteBehindFlushInterval: "1h",
+		}}}))
+		defer s.(wrapperBase).Close()
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		assert.Empty(t, inner.items)
+		require.NoError(t, s.(Flusher).Flush(context.Background()))
+		assert.Equal(t, []byte("v"), inner.items["k"])
+	})
+}
@@ -0,0 +1,589 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dapr/kit/logger"
+)
+
+// Metadata keys of a store wrapped with NewWriteBehindStore.
+const (
+	// WriteBehind enables the write-behind mode.
+	WriteBehind = "writeBehind"
+	// WriteBehindFlushInterval is the maximum time a write is buffered before being flushed.
+	WriteBehindFlushInterval = "writeBehindFlushInterval"
+	// WriteBehindBatchSize is the number of buffered writes that triggers a flush before the interval elapses.
+	WriteBehindBatchSize = "writeBehindBatchSize"
+	// WriteBehindMaxBuffered is the maximum number of buffered writes; further writes are rejected until a flush succeeds.
+	WriteBehindMaxBuffered = "writeBehindMaxBuffered"
+	// WriteBehindJournal is the path of the file journaling the buffered writes, replayed on Init after a crash.
+	// The journal is synced to disk before the writes are acknowledged, once per Set or BulkSet.
+	WriteBehindJournal = "writeBehindJournal"
+)
+
+const (
+	defaultWriteBehindFlushInterval = time.Second
+	defaultWriteBehindBatchSize     = 100
+	defaultWriteBehindMaxBuffered   = 10000
+)
+
+// ErrWriteBehindBufferFull is returned when a write can't be buffered because the store can't keep up with the writes.
+var ErrWriteBehindBufferFull = errors.New("state store write-behind error: buffer is full")
+
+// Flusher is implemented by the stores which buffer writes, such as the stores wrapped with NewWriteBehindStore.
+type Flusher interface {
+	// Flush saves the buffered writes.
+	Flush(ctx context.Context) error
+}
+
+// writeBehindStore buffers the writes, and saves them in batches with BulkSet.
+type writeBehindStore struct {
+	forwarder
+
+	logger logger.Logger
+
+	enabled       bool
+	flushInterval time.Duration
+	batchSize     int
+	maxBuffered   int
+	journalPath   string
+
+	// lock protects the buffer and the journal.
+	lock     sync.Mutex
+	pending  map[string]SetRequest
+	order    []string
+	inflight map[string]struct{}
+	journal  *os.File
+	closed   bool
+
+	// flushLock serializes the flushes.
+	flushLock sync.Mutex
+	flushCh   chan struct{}
+	closeCh   chan struct{}
+	wg        sync.WaitGroup
+}
+
+// writeBehindRecord is a buffered write in the journal.
+type writeBehindRecord struct {
+	Key         string            `json:"key"`
+	Kind        string            `json:"kind"`
+	Data        json.RawMessage   `json:"data"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	ContentType *string           `json:"contentType,omitempty"`
+}
+
+// NewWriteBehindStore wraps a Store so that, when the "writeBehind" metadata is true, Set and BulkSet return once the
+// write is buffered, and buffered writes are saved with BulkSet every "writeBehindFlushInterval" or as soon as
+// "writeBehindBatchSize" writes are buffered. Writes to the same key are coalesced, and failed flushes are retried,
+// so writes are saved at least once.
+// When "writeBehindJournal" is set, buffered writes are appended and synced to that file before being acknowledged
+// and are replayed on Init, so they survive a crash of the process or of the OS.
+// Writes with an ETag, first-write concurrency, strong consistency or a session token, and every other operation on a
+// key with a buffered write, flush the buffer first and are run synchronously. Close flushes the buffer.
+func NewWriteBehindStore(inner Store, logger logger.Logger) Store {
+	s := &writeBehindStore{forwarder: forwarder{inner}, logger: logger}
+	return exposeOptional(s, inner)
+}
+
+func (s *writeBehindStore) Init(metadata Metadata) error {
+	err := s.parseMetadata(metadata.Properties)
+	// The buffer is enabled once it is set up, so that Close and the writes don't use it after a failed Init.
+	enabled := s.enabled
+	s.enabled = false
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.Init(metadata)
+	if err != nil || !enabled {
+		return err
+	}
+
+	s.pending = map[string]SetRequest{}
+	s.inflight = map[string]struct{}{}
+	if s.journalPath != "" {
+		err = s.replayJournal()
+		if err != nil {
+			return fmt.Errorf("state store write-behind error: failed to replay journal: %w", err)
+		}
+	}
+
+	s.flushCh = make(chan struct{}, 1)
+	s.closeCh = make(chan struct{})
+	s.enabled = true
+	s.wg.Add(1)
+	go s.flushLoop()
+
+	return nil
+}
+
+func (s *writeBehindStore) parseMetadata(props map[string]string) error {
+	s.enabled = false
+	s.flushInterval = defaultWriteBehindFlushInterval
+	s.batchSize = defaultWriteBehindBatchSize
+	s.maxBuffered = defaultWriteBehindMaxBuffered
+
+	if val := props[WriteBehind]; val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid metadata '%s': must be a boolean", WriteBehind)
+		}
+		s.enabled = enabled
+	}
+	if val := props[WriteBehindFlushInterval]; val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid metadata '%s': must be a positive duration", WriteBehindFlushInterval)
+		}
+		s.flushInterval = d
+	}
+	for key, target := range map[string]*int{
+		WriteBehindBatchSize:   &s.batchSize,
+		WriteBehindMaxBuffered: &s.maxBuffered,
+	} {
+		if val := props[key]; val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid metadata '%s': must be a positive number", key)
+			}
+			*target = n
+		}
+	}
+	if s.maxBuffered < s.batchSize {
+		return fmt.Errorf("invalid metadata '%s': must not be smaller than '%s'", WriteBehindMaxBuffered, WriteBehindBatchSize)
+	}
+	s.journalPath = props[WriteBehindJournal]
+
+	return nil
+}
+
+func (s *writeBehindStore) flushLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case <-ticker.C:
+		case <-s.flushCh:
+		}
+		if err := s.Flush(context.Background()); err != nil {
+			s.logger.Errorf("%v", err)
+		}
+	}
+}
+
+// Flush saves the buffered writes.
+func (s *writeBehindStore) Flush(ctx context.Context) error {
+	if !s.enabled {
+		return nil
+	}
+
+	s.flushLock.Lock()
+	defer s.flushLock.Unlock()
+
+	s.lock.Lock()
+	if len(s.order) == 0 {
+		s.lock.Unlock()
+		return nil
+	}
+	batch := make([]SetRequest, len(s.order))
+	for i, key := range s.order {
+		batch[i] = s.pending[key]
+		s.inflight[key] = struct{}{}
+	}
+	s.pending = map[string]SetRequest{}
+	s.order = nil
+	s.lock.Unlock()
+
+	err := s.Store.BulkSet(ctx, batch)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.inflight = map[string]struct{}{}
+	if err != nil {
+		// Requeue the writes ahead of the ones buffered meanwhile, unless they've been overwritten.
+		order := make([]string, 0, len(batch)+len(s.order))
+		for _, req := range batch {
+			if _, ok := s.pending[req.Key]; !ok {
+				s.pending[req.Key] = req
+				order = append(order, req.Key)
+			}
+		}
+		s.order = append(order, s.order...)
+		return fmt.Errorf("state store write-behind error: failed to flush %d writes: %w", len(batch), err)
+	}
+
+	err = s.compactJournal()
+	if err != nil {
+		return fmt.Errorf("state store write-behind error: failed to compact journal: %w", err)
+	}
+	return nil
+}
+
+// flushIfPending flushes the buffer if one of the keys has a buffered or inflight write.
+func (s *writeBehindStore) flushIfPending(ctx context.Context, keys ...string) error {
+	if !s.enabled {
+		return nil
+	}
+
+	s.lock.Lock()
+	pending := false
+	for _, key := range keys {
+		_, buffered := s.pending[key]
+		_, inflight := s.inflight[key]
+		if buffered || inflight {
+			pending = true
+			break
+		}
+	}
+	s.lock.Unlock()
+
+	if !pending {
+		return nil
+	}
+	return s.Flush(ctx)
+}
+
+func (s *writeBehindStore) buffer(ctx context.Context, reqs []SetRequest) error {
+	for attempt := 0; ; attempt++ {
+		s.lock.Lock()
+		if s.closed {
+			s.lock.Unlock()
+			return errors.New("state store write-behind error: store is closed")
+		}
+		added := 0
+		for i := range reqs {
+			if _, ok := s.pending[reqs[i].Key]; !ok {
+				added++
+			}
+		}
+		if len(s.pending)+added <= s.maxBuffered {
+			break
+		}
+		s.lock.Unlock()
+
+		if attempt > 0 {
+			return ErrWriteBehindBufferFull
+		}
+		if err := s.Flush(ctx); err != nil {
+			return fmt.Errorf("%w: %v", ErrWriteBehindBufferFull, err)
+		}
+	}
+	defer s.lock.Unlock()
+
+	if s.journal != nil {
+		for i := range reqs {
+			if err := appendWriteBehindRecord(s.journal, &reqs[i]); err != nil {
+				return fmt.Errorf("state store write-behind error: failed to journal write: %w", err)
+			}
+		}
+		if err := s.journal.Sync(); err != nil {
+			return fmt.Errorf("state store write-behind error: failed to sync journal: %w", err)
+		}
+	}
+	for i := range reqs {
+		if _, ok := s.pending[reqs[i].Key]; !ok {
+			s.order = append(s.order, reqs[i].Key)
+		}
+		s.pending[reqs[i].Key] = reqs[i]
+	}
+
+	if len(s.order) >= s.batchSize {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// synchronous returns true if the write can't be deferred, as its outcome depends on the current state.
+func (s *writeBehindStore) synchronous(req *SetRequest) bool {
+	return req.ETag != nil || req.Options.Concurrency == FirstWrite || req.Options.Consistency == Strong
+}
+
+func (s *writeBehindStore) Set(ctx context.Context, req *SetRequest) error {
+	if !s.enabled {
+		return s.forwarder.Set(ctx, req)
+	}
+
+	// The session token is only known once the write is done.
+	if s.synchronous(req) || sessionTokenFrom(ctx) != nil {
+		if err := s.flushIfPending(ctx, req.Key); err != nil {
+			return err
+		}
+		return s.forwarder.Set(ctx, req)
+	}
+	return s.buffer(ctx, []SetRequest{*req})
+}
+
+func (s *writeBehindStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	if !s.enabled {
+		return s.Store.BulkSet(ctx, req)
+	}
+
+	deferred := make([]SetRequest, 0, len(req))
+	for i := range req {
+		if s.synchronous(&req[i]) {
+			if err := s.Set(ctx, &req[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		deferred = append(deferred, req[i])
+	}
+	if len(deferred) == 0 {
+		return nil
+	}
+	return s.buffer(ctx, deferred)
+}
+
+func (s *writeBehindStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	if err := s.flushIfPending(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	return s.Store.Get(ctx, req)
+}
+
+func (s *writeBehindStore) BulkGet(ctx context.Context, req []GetRequest) (bool, []BulkGetResponse, error) {
+	keys := make([]string, len(req))
+	for i := range req {
+		keys[i] = req[i].Key
+	}
+	if err := s.flushIfPending(ctx, keys...); err != nil {
+		return false, nil, err
+	}
+	return s.Store.BulkGet(ctx, req)
+}
+
+func (s *writeBehindStore) Delete(ctx context.Context, req *DeleteRequest) error {
+	if err := s.flushIfPending(ctx, req.Key); err != nil {
+		return err
+	}
+	return s.forwarder.Delete(ctx, req)
+}
+
+func (s *writeBehindStore) BulkDelete(ctx context.Context, req []DeleteRequest) error {
+	keys := make([]string, len(req))
+	for i := range req {
+		keys[i] = req[i].Key
+	}
+	if err := s.flushIfPending(ctx, keys...); err != nil {
+		return err
+	}
+	return s.Store.BulkDelete(ctx, req)
+}
+
+// Close flushes the buffered writes and closes the wrapped store.
+// Writes that can't be flushed are kept in the journal, if any.
+func (s *writeBehindStore) Close() error {
+	var err error
+	if s.enabled {
+		s.lock.Lock()
+		closed := s.closed
+		s.closed = true
+		s.lock.Unlock()
+
+		if !closed {
+			close(s.closeCh)
+			s.wg.Wait()
+			err = s.Flush(context.Background())
+
+			s.lock.Lock()
+			if s.journal != nil {
+				if closeErr := s.journal.Close(); closeErr != nil && err == nil {
+					err = closeErr
+				}
+				s.journal = nil
+			}
+			s.lock.Unlock()
+		}
+	}
+
+	if closer, ok := s.Store.(io.Closer); ok {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (s *writeBehindStore) Multi(ctx context.Context, request *TransactionalStateRequest) error {
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+	return s.forwarder.Multi(ctx, request)
+}
+
+func (s *writeBehindStore) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return s.forwarder.Query(ctx, req)
+}
+
+// replayJournal buffers the writes left in the journal by a previous run.
+func (s *writeBehindStore) replayJournal() error {
+	f, err := os.OpenFile(s.journalPath, os.O_RDONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var rec writeBehindRecord
+		if err = json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// The last record may have been cut short by a crash.
+			s.logger.Warnf("state store write-behind: skipping invalid journal record: %v", err)
+			continue
+		}
+		req, err := rec.request()
+		if err != nil {
+			s.logger.Warnf("state store write-behind: skipping invalid journal record for key %s: %v", rec.Key, err)
+			continue
+		}
+		if _, ok := s.pending[req.Key]; !ok {
+			s.order = append(s.order, req.Key)
+		}
+		s.pending[req.Key] = req
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	if len(s.order) > 0 {
+		s.logger.Infof("state store write-behind: replaying %d writes from the journal", len(s.order))
+	}
+
+	return s.compactJournal()
+}
+
+// compactJournal rewrites the journal with the buffered writes only. It must be called with the lock held.
+func (s *writeBehindStore) compactJournal() error {
+	if s.journalPath == "" {
+		return nil
+	}
+
+	if s.journal != nil && len(s.order) == 0 {
+		if err := s.journal.Truncate(0); err != nil {
+			return err
+		}
+		_, err := s.journal.Seek(0, io.SeekStart)
+		return err
+	}
+
+	tmpPath := s.journalPath + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	for _, key := range s.order {
+		req := s.pending[key]
+		if err = appendWriteBehindRecord(tmp, &req); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, s.journalPath); err != nil {
+		return err
+	}
+
+	if s.journal != nil {
+		s.journal.Close()
+	}
+	s.journal, err = os.OpenFile(s.journalPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	return err
+}
+
+func appendWriteBehindRecord(w io.Writer, req *SetRequest) error {
+	rec := writeBehindRecord{
+		Key:         req.Key,
+		Metadata:    req.Metadata,
+		ContentType: req.ContentType,
+	}
+
+	var err error
+	switch v := req.Value.(type) {
+	case []byte:
+		rec.Kind = "bytes"
+		rec.Data, err = json.Marshal(v)
+	case string:
+		rec.Kind = "string"
+		rec.Data, err = json.Marshal(v)
+	default:
+		rec.Kind = "json"
+		rec.Data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+func (rec *writeBehindRecord) request() (SetRequest, error) {
+	req := SetRequest{
+		Key:         rec.Key,
+		Metadata:    rec.Metadata,
+		ContentType: rec.ContentType,
+	}
+
+	switch rec.Kind {
+	case "bytes":
+		var v []byte
+		if err := json.Unmarshal(rec.Data, &v); err != nil {
+			return req, err
+		}
+		req.Value = v
+	case "string":
+		var v string
+		if err := json.Unmarshal(rec.Data, &v); err != nil {
+			return req, err
+		}
+		req.Value = v
+	case "json":
+		// Stores save the JSON encoding of the values which aren't bytes or strings, which is the value saved as bytes.
+		req.Value = []byte(rec.Data)
+	default:
+		return req, fmt.Errorf("unknown kind '%s'", rec.Kind)
+	}
+	return req, nil
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2023 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// syncStore is a memStore safe for the concurrent use of the flush loop.
+type syncStore struct {
+	memStore
+	lock     sync.Mutex
+	err      error
+	bulkSets [][]SetRequest
+}
+
+func newSyncStore() *syncStore {
+	return &syncStore{memStore: memStore{items: map[string][]byte{}}}
+}
+
+func (s *syncStore) Set(ctx context.Context, req *SetRequest) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.memStore.Set(ctx, req)
+}
+
+func (s *syncStore) BulkSet(ctx context.Context, req []SetRequest) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.bulkSets = append(s.bulkSets, req)
+	for i := range req {
+		if v, ok := req[i].Value.([]byte); ok {
+			s.items[req[i].Key] = v
+		}
+	}
+	return nil
+}
+
+func (s *syncStore) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.memStore.Get(ctx, req)
+}
+
+func (s *syncStore) item(key string) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.items[key]
+}
+
+func (s *syncStore) setErr(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err
+}
+
+// failingInitStore is a syncStore whose Init fails.
+type failingInitStore struct {
+	*syncStore
+}
+
+func (s failingInitStore) Init(metadata Metadata) error {
+	return errors.New("unavailable")
+}
+
+type flushingStore interface {
+	Store
+	Flush(ctx context.Context) error
+	Close() error
+}
+
+func initWriteBehindStore(t *testing.T, inner Store, props map[string]string) flushingStore {
+	t.Helper()
+	s := NewWriteBehindStore(inner, logger.NewLogger("test")).(flushingStore)
+	require.NoError(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+	t.Cleanup(func() {
+		s.Close()
+	})
+	return s
+}
+
+func TestWriteBehindStore(t *testing.T) {
+	props := map[string]string{
+		WriteBehind:              "true",
+		WriteBehindFlushInterval: "1h",
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		inner := newSyncStore()
+		s := initWriteBehindStore(t, inner, nil)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		assert.Equal(t, "v", string(inner.item("k")))
+	})
+
+	t.Run("reads flush the buffered writes", func(t *testing.T) {
+		inner := newSyncStore()
+		s := initWriteBehindStore(t, inner, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		assert.Nil(t, inner.item("k"))
+
+		res, err := s.Get(context.Background(), &GetRequest{Key: "k"})
+		require.NoError(t, err)
+		assert.Equal(t, "v", string(res.Data))
+	})
+
+	t.Run("flushes on batch size", func(t *testing.T) {
+		inner := newSyncStore()
+		s := initWriteBehindStore(t, inner, map[string]string{
+			WriteBehind:              "true",
+			WriteBehindFlushInterval: "1h",
+			WriteBehindBatchSize:     "2",
+		})
+
+		require.NoError(t, s.BulkSet(context.Background(), []SetRequest{
+			{Key: "a", Value: []byte("1")},
+			{Key: "b", Value: []byte("2")},
+		}))
+		assert.Eventually(t, func() bool {
+			return inner.item("a") != nil && inner.item("b") != nil
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("flushes on interval", func(t *testing.T) {
+		inner := newSyncStore()
+		s := initWriteBehindStore(t, inner, map[string]string{
+			WriteBehind:              "true",
+			WriteBehindFlushInterval: "10ms",
+		})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		assert.Eventually(t, func() bool {
+			return inner.item("k") != nil
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("coalesces writes to the same key", func(t *testing.T) {
+		inner := newSyncStore()
+		s := initWriteBehindStore(t, inner, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("1")}))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("2")}))
+		require.NoError(t, s.Flush(context.Background()))
+
+		require.Len(t, inner.bulkSets, 1)
+		require.Len(t, inner.bulkSets[0], 1)
+		assert.Equal(t, "2", string(inner.item("k")))
+	})
+
+	t.Run("writes with an etag are synchronous", func(t *testing.T) {
+		inner := newSyncStore()
+		s := initWriteBehindStore(t, inner, props)
+		etag := "1"
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v"), ETag: &etag}))
+		assert.Equal(t, "v", string(inner.item("k")))
+	})
+
+	t.Run("failed flushes are retried", func(t *testing.T) {
+		inner := newSyncStore()
+		inner.setErr(errors.New("unavailable"))
+		s := initWriteBehindStore(t, inner, props)
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		require.Error(t, s.Flush(context.Background()))
+
+		inner.setErr(nil)
+		require.NoError(t, s.Flush(context.Background()))
+		assert.Equal(t, "v", string(inner.item("k")))
+	})
+
+	t.Run("rejects writes when the buffer is full", func(t *testing.T) {
+		inner := newSyncStore()
+		inner.setErr(errors.New("unavailable"))
+		s := initWriteBehindStore(t, inner, map[string]string{
+			WriteBehind:              "true",
+			WriteBehindFlushInterval: "1h",
+			WriteBehindBatchSize:     "2",
+			WriteBehindMaxBuffered:   "2",
+		})
+
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "a", Value: []byte("1")}))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "b", Value: []byte("2")}))
+		err := s.Set(context.Background(), &SetRequest{Key: "c", Value: []byte("3")})
+		assert.ErrorIs(t, err, ErrWriteBehindBufferFull)
+
+		// Overwriting a buffered key doesn't need more room.
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "a", Value: []byte("4")}))
+	})
+
+	t.Run("replays the journal after a crash", func(t *testing.T) {
+		journalProps := map[string]string{
+			WriteBehind:              "true",
+			WriteBehindFlushInterval: "1h",
+			WriteBehindJournal:       filepath.Join(t.TempDir(), "journal"),
+		}
+
+		inner := newSyncStore()
+		inner.setErr(errors.New("unavailable"))
+		s := initWriteBehindStore(t, inner, journalProps)
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "k", Value: []byte("v")}))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "s", Value: "str", Metadata: map[string]string{"ttlInSeconds": "5"}}))
+		require.NoError(t, s.Set(context.Background(), &SetRequest{Key: "j", Value: map[string]int{"n": 1}}))
+		require.Error(t, s.Close())
+
+		recovered := newSyncStore()
+		s = initWriteBehindStore(t, recovered, journalProps)
+		require.NoError(t, s.Flush(context.Background()))
+		require.Len(t, recovered.bulkSets, 1)
+		assert.Equal(t, []SetRequest{
+			{Key: "k", Value: []byte("v")},
+			{Key: "s", Value: "str", Metadata: map[string]string{"ttlInSeconds": "5"}},
+			{Key: "j", Value: []byte(`{"n":1}`)},
+		}, recovered.bulkSets[0])
+
+		// Saved writes are removed from the journal.
+		require.NoError(t, s.Close())
+		again := newSyncStore()
+		s = initWriteBehindStore(t, again, journalProps)
+		require.NoError(t, s.Flush(context.Background()))
+		assert.Empty(t, again.bulkSets)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for _, props := range []map[string]string{
+			{WriteBehind: "maybe"},
+			{WriteBehind: "true", WriteBehindFlushInterval: "0s"},
+			{WriteBehind: "true", WriteBehindBatchSize: "-1"},
+			{WriteBehind: "true", WriteBehindBatchSize: "10", WriteBehindMaxBuffered: "5"},
+		} {
+			s := NewWriteBehindStore(newSyncStore(), logger.NewLogger("test"))
+			assert.Error(t, s.Init(Metadata{Base: metadata.Base{Properties: props}}))
+		}
+	})
+
+	t.Run("failed init", func(t *testing.T) {
+		for name, inner := range map[string]Store{
+			"inner store":    failingInitStore{newSyncStore()},
+			"journal replay": newSyncStore(),
+		} {
+			s := NewWriteBehindStore(inner, logger.NewLogger("test")).(flushingStore)
+			// The journal can't be a directory.
+			err := s.Init(Metadata{Base: metadata.Base{Properties: map[string]string{
+				WriteBehind:        "true",
+				WriteBehindJournal: t.TempDir(),
+			}}})
+			require.Error(t, err, name)
+			assert.NoError(t, s.Flush(context.Background()), name)
+			assert.NoError(t, s.Close(), name)
+		}
+	})
+}
@@ -73,7 +73,7 @@ func TestPostgreSQL(t *testing.T) {
 	currentGrpcPort := ports[0]
 
 	// Update this constant if you add more migrations
-	const migrationLevel = "2"
+	const migrationLevel = "3"
 
 	// Holds a DB client as the "postgres" (ie. "root") user which we'll use to validate migrations and other changes in state
 	var dbClient *pgx.Conn